@@ -119,6 +119,18 @@ func (cl *Client) Sign(txn *types.Transaction) (*types.Transaction, error) {
 	return txn, nil
 }
 
+// SignTxs signs the transactions in order with the accounts of the client, so
+// that many transactions are signed with one keystore unlock. It stops at the
+// first transaction failed to sign, the transactions before it are signed.
+func (cl *Client) SignTxs(txns []*types.Transaction) error {
+	for i, txn := range txns {
+		if _, err := cl.Sign(txn); err != nil {
+			return fmt.Errorf("sign transaction %d failed, %s", i, err)
+		}
+	}
+	return nil
+}
+
 func (cl *Client) GetMainAccount() *Account {
 	return cl.GetAccountByCodeHash(cl.mainAccount)
 }
@@ -0,0 +1,166 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA/auxpow"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+)
+
+// maxAuxPowCacheSize is the max number of validated aux pows remembered,
+// it's larger than the headers of a headers message so that a batch checked
+// in parallel is not evicted before the headers are processed one by one.
+const maxAuxPowCacheSize = 10000
+
+// auxPowEntry is the aux pow validated against the aux block hash.
+type auxPowEntry struct {
+	parentHash   common.Uint256
+	auxBlockHash common.Uint256
+	auxPowHash   common.Uint256
+}
+
+// auxPowCache remembers the validated aux pows keyed by the hash of their
+// parent block, the same aux pow is usually checked more than once, by the
+// header sync and then by the block processing, or received from several
+// peers.  The oldest entry is evicted once the cache is full.
+type auxPowCache struct {
+	sync.Mutex
+	entries map[common.Uint256]*list.Element
+	order   *list.List
+	size    int
+}
+
+func newAuxPowCache(size int) *auxPowCache {
+	return &auxPowCache{
+		entries: make(map[common.Uint256]*list.Element),
+		order:   list.New(),
+		size:    size,
+	}
+}
+
+// auxPowHash returns the hash of the serialized aux pow, the parent block
+// hash alone does not commit to the coinbase and merkle branches.
+func auxPowHash(ap *auxpow.AuxPow) common.Uint256 {
+	buf := new(bytes.Buffer)
+	ap.Serialize(buf)
+	return common.Uint256(common.Sha256D(buf.Bytes()))
+}
+
+// exists returns if the aux pow has been validated against the aux block
+// hash.
+func (c *auxPowCache) exists(parentHash, auxBlockHash,
+	auxPowHash common.Uint256) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	elem, ok := c.entries[parentHash]
+	if !ok {
+		return false
+	}
+	entry := elem.Value.(*auxPowEntry)
+	return entry.auxBlockHash.IsEqual(auxBlockHash) &&
+		entry.auxPowHash.IsEqual(auxPowHash)
+}
+
+// add adds the aux pow validated against the aux block hash.
+func (c *auxPowCache) add(parentHash, auxBlockHash,
+	auxPowHash common.Uint256) {
+	c.Lock()
+	defer c.Unlock()
+
+	entry := &auxPowEntry{
+		parentHash:   parentHash,
+		auxBlockHash: auxBlockHash,
+		auxPowHash:   auxPowHash,
+	}
+	if elem, ok := c.entries[parentHash]; ok {
+		elem.Value = entry
+		c.order.MoveToBack(elem)
+		return
+	}
+	if c.order.Len() >= c.size {
+		oldest := c.order.Remove(c.order.Front()).(*auxPowEntry)
+		delete(c.entries, oldest.parentHash)
+	}
+	c.entries[parentHash] = c.order.PushBack(entry)
+}
+
+// len returns the number of the aux pows in the cache.
+func (c *auxPowCache) len() int {
+	c.Lock()
+	defer c.Unlock()
+	return c.order.Len()
+}
+
+// checkAuxPow checks the aux pow of the header, the result is cached so that
+// the same aux pow is validated only once.
+func (b *BlockChain) checkAuxPow(header *types.Header) bool {
+	hash := header.Hash()
+	if b.auxPowCache == nil {
+		return header.AuxPow.Check(&hash, auxpow.AuxPowChainID)
+	}
+
+	parentHash := header.AuxPow.ParBlockHeader.Hash()
+	apHash := auxPowHash(&header.AuxPow)
+	if b.auxPowCache.exists(parentHash, hash, apHash) {
+		return true
+	}
+	if !header.AuxPow.Check(&hash, auxpow.AuxPowChainID) {
+		return false
+	}
+	b.auxPowCache.add(parentHash, hash, apHash)
+	return true
+}
+
+// CheckAuxPows checks the aux pows of the headers in parallel, it's used to
+// check a batch of headers during the header sync before they are processed
+// one by one, the validated aux pows are cached so the following sanity
+// checks of the headers do not validate them again.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) CheckAuxPows(headers []*types.Header) error {
+	workers := runtime.NumCPU()
+	if workers > len(headers) {
+		workers = len(headers)
+	}
+
+	// Each index is written by one worker only.
+	failed := make([]bool, len(headers))
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range next {
+				if !b.checkAuxPow(headers[index]) {
+					failed[index] = true
+				}
+			}
+		}()
+	}
+	for i := range headers {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	// Report the first invalid header in order.
+	for i, header := range headers {
+		if failed[i] {
+			return fmt.Errorf("[CheckAuxPows] block %s check aux pow "+
+				"failed", header.Hash())
+		}
+	}
+	return nil
+}
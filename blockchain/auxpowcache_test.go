@@ -0,0 +1,66 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/auxpow"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newAuxPowHeader(height uint32) *types.Header {
+	header := &types.Header{Height: height}
+	header.AuxPow = *auxpow.GenerateAuxPow(header.Hash())
+	return header
+}
+
+func TestBlockChain_CheckAuxPows(t *testing.T) {
+	chain := &BlockChain{auxPowCache: newAuxPowCache(maxAuxPowCacheSize)}
+
+	headers := make([]*types.Header, 0, 20)
+	for i := uint32(0); i < 20; i++ {
+		headers = append(headers, newAuxPowHeader(i))
+	}
+	assert.NoError(t, chain.CheckAuxPows(headers))
+	assert.Equal(t, 20, chain.auxPowCache.len())
+
+	// validated aux pows are checked again by cache.
+	assert.NoError(t, chain.CheckAuxPows(headers))
+	assert.Equal(t, 20, chain.auxPowCache.len())
+
+	// an aux pow committing to another block is invalid.
+	headers[5].AuxPow = *auxpow.GenerateAuxPow(common.EmptyHash)
+	assert.Error(t, chain.CheckAuxPows(headers))
+
+	// a cached aux pow is invalid for another block.
+	header := &types.Header{Height: 100, AuxPow: headers[6].AuxPow}
+	assert.False(t, chain.checkAuxPow(header))
+
+	// a cached parent block with another coinbase is invalid.
+	header = newAuxPowHeader(200)
+	assert.True(t, chain.checkAuxPow(header))
+	header.AuxPow.ParCoinbaseTx.LockTime++
+	assert.False(t, chain.checkAuxPow(header))
+
+	assert.NoError(t, chain.CheckAuxPows(nil))
+}
+
+func TestAuxPowCache_Evict(t *testing.T) {
+	cache := newAuxPowCache(2)
+	hashes := []common.Uint256{{1}, {2}, {3}}
+	for _, hash := range hashes {
+		cache.add(hash, hash, hash)
+	}
+	assert.Equal(t, 2, cache.len())
+	assert.False(t, cache.exists(hashes[0], hashes[0], hashes[0]))
+	assert.True(t, cache.exists(hashes[1], hashes[1], hashes[1]))
+	assert.True(t, cache.exists(hashes[2], hashes[2], hashes[2]))
+	assert.False(t, cache.exists(hashes[2], hashes[1], hashes[2]))
+}
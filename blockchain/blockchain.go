@@ -24,6 +24,9 @@ import (
 	"github.com/elastos/Elastos.ELA/database"
 	"github.com/elastos/Elastos.ELA/dpos/state"
 	"github.com/elastos/Elastos.ELA/events"
+	"github.com/elastos/Elastos.ELA/utils/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -75,6 +78,26 @@ type BlockChain struct {
 	TimeSource     MedianTimeSource
 	MedianTimePast time.Time
 	mutex          sync.RWMutex
+
+	// verifiedHashes holds the hashes of the verified headers chain to the
+	// checkpoint starting from verifiedStart, they are protected by mutex.
+	verifiedStart  uint32
+	verifiedHashes []Uint256
+
+	// invalidBlocks holds the hashes of side chain blocks failed to
+	// reorganize to the main chain, it is protected by mutex.
+	invalidBlocks map[Uint256]struct{}
+
+	// haltHeight is the height the chain halted at, blocks higher than it
+	// are refused, zero means not halted.  It is protected by mutex.
+	haltHeight uint32
+
+	// invariantChecks indicates whether to check state invariants after each
+	// processed block, it's set before any block processed.
+	invariantChecks bool
+
+	// auxPowCache holds the recently validated aux pows.
+	auxPowCache *auxPowCache
 }
 
 func New(db IChainStore, chainParams *config.Params, state *state.State,
@@ -105,6 +128,8 @@ func New(db IChainStore, chainParams *config.Params, state *state.State,
 		confirmCache:        make(map[Uint256]*payload.Confirm),
 		orphanConfirms:      make(map[Uint256]*payload.Confirm),
 		TimeSource:          NewMedianTime(),
+		invalidBlocks:       make(map[Uint256]struct{}),
+		auxPowCache:         newAuxPowCache(maxAuxPowCacheSize),
 	}
 
 	// Initialize the chain state from the passed database.  When the db
@@ -325,10 +350,21 @@ func (b *BlockChain) GetHeight() uint32 {
 }
 
 func (b *BlockChain) ProcessBlock(block *Block, confirm *payload.Confirm) (bool, bool, error) {
+	hash := block.Hash()
+	ctx := tracing.StartBlock(hash, attribute.Int64("block.height",
+		int64(block.Height)))
+	_, span := tracing.Start(ctx, "blockchain.ProcessBlock")
+
 	b.mutex.Lock()
-	defer b.mutex.Unlock()
+	inMainChain, isOrphan, err := b.processBlock(block, confirm)
+	b.mutex.Unlock()
 
-	return b.processBlock(block, confirm)
+	tracing.End(span, err)
+	// orphan blocks are traced until processed with the parent
+	if !isOrphan || err != nil {
+		tracing.EndBlock(hash, err)
+	}
+	return inMainChain, isOrphan, err
 }
 
 func (b *BlockChain) GetHeader(hash Uint256) (*Header, error) {
@@ -456,6 +492,7 @@ func (b *BlockChain) ProcessOrphans(hash *Uint256) error {
 
 			//log.Debug("deal with orphan block %x", orphanHash.ToArrayReverse())
 			_, err := b.maybeAcceptBlock(orphan.Block, confirm)
+			tracing.EndBlock(orphanHash, err)
 			if err != nil {
 				return err
 			}
@@ -945,12 +982,15 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 		}
 
 		// update state after connected block
+		span := tracing.StartBlockSpan(*n.Hash, "state.OnBlockSaved")
 		b.chainParams.CkpManager.OnBlockSaved(&DposBlock{
 			Block:       block,
 			HaveConfirm: confirm != nil,
 			Confirm:     confirm,
 		}, nil)
+		span.End()
 		DefaultLedger.Arbitrators.DumpInfo(block.Height)
+		b.checkInvariants(block)
 
 		delete(b.blockCache, *n.Hash)
 		delete(b.confirmCache, *n.Hash)
@@ -1015,7 +1055,10 @@ func (b *BlockChain) connectBlock(node *BlockNode, block *Block, confirm *payloa
 
 	// The block must pass all of the validation rules which depend on the
 	// position of the block within the block chain.
-	if err := b.CheckBlockContext(block, node.Parent); err != nil {
+	span := tracing.StartBlockSpan(*node.Hash, "blockchain.CheckBlockContext")
+	err := b.CheckBlockContext(block, node.Parent)
+	tracing.End(span, err)
+	if err != nil {
 		log.Error("PowCheckBlockContext error!", err)
 		return err
 	}
@@ -1043,9 +1086,11 @@ func (b *BlockChain) connectBlock(node *BlockNode, block *Block, confirm *payloa
 	// expensive connection logic.  It also has some other nice properties
 	// such as making blocks that never become part of the main chain or
 	// blocks that fail to connect available for further analysis.
-	err := b.db.GetFFLDB().Update(func(dbTx database.Tx) error {
+	span = tracing.StartBlockSpan(*node.Hash, "blockchain.StoreBlock")
+	err = b.db.GetFFLDB().Update(func(dbTx database.Tx) error {
 		return dbStoreBlock(dbTx, block)
 	})
+	tracing.End(span, err)
 	if err != nil {
 		return fmt.Errorf("fflDB store block failed: %s", err)
 	}
@@ -1057,7 +1102,10 @@ func (b *BlockChain) connectBlock(node *BlockNode, block *Block, confirm *payloa
 
 	medianTime := CalcPastMedianTime(b.BestChain)
 	// Insert the block into the database which houses the main chain.
-	if err := b.db.SaveBlock(block, node, confirm, medianTime); err != nil {
+	span = tracing.StartBlockSpan(*node.Hash, "blockchain.SaveBlock")
+	err = b.db.SaveBlock(block, node, confirm, medianTime)
+	tracing.End(span, err)
+	if err != nil {
 		return err
 	}
 
@@ -1080,7 +1128,9 @@ func (b *BlockChain) connectBlock(node *BlockNode, block *Block, confirm *payloa
 	// Notify the caller that the block was connected to the main chain.
 	// The caller would typically want to react with actions such as
 	// updating wallets.
+	span = tracing.StartBlockSpan(*node.Hash, "events.BlockConnected")
 	events.Notify(events.ETBlockConnected, block)
+	span.End()
 
 	return nil
 }
@@ -1126,6 +1176,9 @@ func (b *BlockChain) maybeAcceptBlock(block *Block, confirm *payload.Confirm) (b
 	if block.Header.Height != blockHeight {
 		return false, fmt.Errorf("wrong block height!")
 	}
+	if err := b.checkHalted(blockHeight); err != nil {
+		return false, err
+	}
 
 	// Prune block nodes which are no longer needed before creating
 	// a new node.
@@ -1154,17 +1207,22 @@ func (b *BlockChain) maybeAcceptBlock(block *Block, confirm *payload.Confirm) (b
 	}
 
 	if inMainChain && !reorganized {
+		span := tracing.StartBlockSpan(blockhash, "state.OnBlockSaved")
 		b.chainParams.CkpManager.OnBlockSaved(&DposBlock{
 			Block:       block,
 			HaveConfirm: confirm != nil,
 			Confirm:     confirm,
 		}, nil)
+		span.End()
 		DefaultLedger.Arbitrators.DumpInfo(block.Height)
+		b.checkInvariants(block)
 	}
 
 	// Notify the caller that the new block was accepted into the block
 	// chain.  The caller would typically want to react by relaying the
 	// inventory to other peers.
+	span := tracing.StartBlockSpan(blockhash, "events.BlockAccepted")
+	defer span.End()
 	if block.Height >= b.chainParams.CRCOnlyDPOSHeight {
 		events.Notify(events.ETBlockConfirmAccepted, block)
 	} else if block.Height == b.chainParams.CRCOnlyDPOSHeight-1 {
@@ -1279,6 +1337,7 @@ func (b *BlockChain) connectBestChain(node *BlockNode, block *Block, confirm *pa
 	log.Infof("REORGANIZE: Block %v is causing a reorganize.", node.Hash)
 	err := b.reorganizeChain(detachNodes, attachNodes)
 	if err != nil {
+		b.invalidBlocks[*node.Hash] = struct{}{}
 		return false, false, err
 	}
 
@@ -1336,7 +1395,9 @@ func (b *BlockChain) processBlock(block *Block, confirm *payload.Confirm) (bool,
 
 	// Perform preliminary sanity checks on the block and its transactions.
 	//err = PowCheckBlockSanity(block, PowLimit, b.TimeSource)
+	span := tracing.StartBlockSpan(blockHash, "blockchain.CheckBlockSanity")
 	err := b.CheckBlockSanity(block)
+	tracing.End(span, err)
 	if err != nil {
 		log.Errorf("PowCheckBlockSanity error %s", err.Error())
 		return false, false, err
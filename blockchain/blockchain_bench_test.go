@@ -238,14 +238,14 @@ func benchBegin() *BlockChain {
 	log.NewDefault(test.NodeLogPath, 0, 0, 0)
 
 	params := config.DefaultParams.RegNet()
-	store, _ := NewChainStore(test.DataPath, params.GenesisBlock)
+	store, _ := NewChainStore(test.DataPath, LevelDBEngine, params.GenesisBlock)
 	blockChain, _ := New(store, params, nil, nil)
 	var interrupt = signal.NewInterrupt()
 	blockChain.InitFFLDBFromChainStore(interrupt.C, nil, nil, false)
 	rollbackTo(chainHeight, blockChain)
 	store.Close()
 
-	chainStore, err := NewChainStore(test.DataPath, params.GenesisBlock)
+	chainStore, err := NewChainStore(test.DataPath, LevelDBEngine, params.GenesisBlock)
 	if err != nil {
 		fmt.Println(err.Error())
 	}
@@ -13,7 +13,6 @@ import (
 	"strconv"
 	"time"
 
-	. "github.com/elastos/Elastos.ELA/auxpow"
 	. "github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/common/log"
@@ -28,13 +27,13 @@ const (
 	MaxTimeOffsetSeconds = 2 * 60 * 60
 )
 
-func (b *BlockChain) CheckBlockSanity(block *Block) error {
-	header := block.Header
-	hash := header.Hash()
-	if !header.AuxPow.Check(&hash, AuxPowChainID) {
+// CheckHeaderSanity performs the context free checks on a block header,
+// include the aux pow, proof of work and timestamp.
+func (b *BlockChain) CheckHeaderSanity(header *Header) error {
+	if !b.checkAuxPow(header) {
 		return errors.New("[PowCheckBlockSanity] block check aux pow failed")
 	}
-	if CheckProofOfWork(&header, b.chainParams.PowLimit) != nil {
+	if CheckProofOfWork(header, b.chainParams.PowLimit) != nil {
 		return errors.New("[PowCheckBlockSanity] block check proof of work failed")
 	}
 
@@ -50,6 +49,15 @@ func (b *BlockChain) CheckBlockSanity(block *Block) error {
 		return errors.New("[PowCheckBlockSanity] block timestamp of is too far in the future")
 	}
 
+	return nil
+}
+
+func (b *BlockChain) CheckBlockSanity(block *Block) error {
+	header := block.Header
+	if err := b.CheckHeaderSanity(&header); err != nil {
+		return err
+	}
+
 	// A block must have at least one transaction.
 	numTx := len(block.Transactions)
 	if numTx == 0 {
@@ -127,6 +135,9 @@ func checkDuplicateTx(block *Block) error {
 	existingProducer := make(map[string]struct{})
 	existingProducerNode := make(map[string]struct{})
 	existingCR := make(map[Uint168]struct{})
+	existingRevokedVotes := make(map[string]struct{})
+	existingCommitments := make(map[Uint256]struct{})
+	existingCRCArbiters := make(map[string]struct{})
 	for _, txn := range block.Transactions {
 		switch txn.TxType {
 		case WithdrawFromSideChain:
@@ -221,6 +232,69 @@ func checkDuplicateTx(block *Block) error {
 				return errors.New("[PowCheckBlockSanity] block contains duplicate CR")
 			}
 			existingCR[unregisterCR.CID] = struct{}{}
+		case CRNicknameCommit:
+			commit, ok := txn.Payload.(*payload.CRNicknameCommit)
+			if !ok {
+				return errors.New("[PowCheckBlockSanity] invalid CR nickname commit payload")
+			}
+			// Check for duplicate commitment in a block
+			if _, exists := existingCommitments[commit.Commitment]; exists {
+				return errors.New("[PowCheckBlockSanity] block contains duplicate CR nickname commitment")
+			}
+			existingCommitments[commit.Commitment] = struct{}{}
+		case ProducerAppeal:
+			appeal, ok := txn.Payload.(*payload.ProducerAppeal)
+			if !ok {
+				return errors.New("[PowCheckBlockSanity] invalid producer appeal payload")
+			}
+			// Check for duplicate producer in a block
+			producer := BytesToHexString(appeal.OwnerPublicKey)
+			if _, exists := existingProducer[producer]; exists {
+				return errors.New("[PowCheckBlockSanity] block contains duplicate producer")
+			}
+			existingProducer[producer] = struct{}{}
+		case RotateNodeKey:
+			rotation, ok := txn.Payload.(*payload.RotateNodeKey)
+			if !ok {
+				return errors.New("[PowCheckBlockSanity] invalid rotate node key payload")
+			}
+			// Check for duplicate producer in a block
+			producer := BytesToHexString(rotation.OwnerPublicKey)
+			if _, exists := existingProducer[producer]; exists {
+				return errors.New("[PowCheckBlockSanity] block contains duplicate producer")
+			}
+			existingProducer[producer] = struct{}{}
+
+			// Check for duplicate producer node in a block
+			producerNode := BytesToHexString(rotation.NewNodePublicKey)
+			if _, exists := existingProducerNode[producerNode]; exists {
+				return errors.New("[PowCheckBlockSanity] block contains duplicate producer node")
+			}
+			existingProducerNode[producerNode] = struct{}{}
+		case CRCRewardAddress:
+			rewardAddress, ok := txn.Payload.(*payload.CRCRewardAddress)
+			if !ok {
+				return errors.New("[PowCheckBlockSanity] invalid CRC reward address payload")
+			}
+			// Check for duplicate CRC arbiter in a block
+			arbiter := BytesToHexString(rewardAddress.NodePublicKey)
+			if _, exists := existingCRCArbiters[arbiter]; exists {
+				return errors.New("[PowCheckBlockSanity] block contains duplicate CRC arbiter")
+			}
+			existingCRCArbiters[arbiter] = struct{}{}
+		case RevokeVote:
+			revokeVote, ok := txn.Payload.(*payload.RevokeVote)
+			if !ok {
+				return errors.New("[PowCheckBlockSanity] invalid revoke vote payload")
+			}
+			// Check for duplicate revoked vote in a block
+			for _, v := range revokeVote.Votes {
+				referKey := NewOutPoint(v.TxID, v.Index).ReferKey()
+				if _, exists := existingRevokedVotes[referKey]; exists {
+					return errors.New("[PowCheckBlockSanity] block contains duplicate revoked vote")
+				}
+				existingRevokedVotes[referKey] = struct{}{}
+			}
 		}
 	}
 	return nil
@@ -229,6 +303,9 @@ func checkDuplicateTx(block *Block) error {
 func (b *BlockChain) checkTxsContext(block *Block) error {
 	var totalTxFee = Fixed64(0)
 
+	// Script validation is skipped for blocks in the verified headers
+	// chain to the checkpoint.
+	skipScripts := b.isAssumedValid(block)
 	for i := 1; i < len(block.Transactions); i++ {
 		references, err := b.UTXOCache.GetTxReference(block.Transactions[i])
 		if err != nil {
@@ -236,8 +313,8 @@ func (b *BlockChain) checkTxsContext(block *Block) error {
 			return ErrUnknownReferredTx
 		}
 
-		if errCode := b.CheckTransactionContext(block.Height,
-			block.Transactions[i], references); errCode != Success {
+		if errCode := b.checkTransactionContext(block.Height,
+			block.Transactions[i], references, skipScripts); errCode != Success {
 			return errors.New("CheckTransactionContext failed when verify block")
 		}
 
@@ -405,7 +482,8 @@ func (b *BlockChain) checkCoinbaseTransactionContext(blockHeight uint32, coinbas
 	// main version >= H2
 	if blockHeight >= b.chainParams.PublicDPOSHeight {
 		totalReward := totalTxFee + b.chainParams.RewardPerBlock
-		rewardDPOSArbiter := Fixed64(math.Ceil(float64(totalReward) * 0.35))
+		rewardDPOSArbiter := Fixed64(math.Ceil(float64(totalReward) *
+			b.chainParams.GetRewardPolicy(blockHeight).DPoSRewardRatio))
 		if totalReward-rewardDPOSArbiter+DefaultLedger.Arbitrators.
 			GetFinalRoundChange() != coinbase.Outputs[0].Value+
 			coinbase.Outputs[1].Value {
@@ -51,7 +51,7 @@ func TestCheckBlockSanity(t *testing.T) {
 	params := &config.DefaultParams
 	FoundationAddress = params.Foundation
 	chainStore, err := NewChainStore(filepath.Join(test.DataPath, "sanity"),
-		params.GenesisBlock)
+		LevelDBEngine, params.GenesisBlock)
 	if err != nil {
 		t.Error(err.Error())
 	}
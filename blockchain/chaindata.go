@@ -340,7 +340,7 @@ func (c *ChainStore) PersistTransactions(b *Block) error {
 		case WithdrawFromSideChain:
 			witPayload := txn.Payload.(*payload.WithdrawFromSideChain)
 			for _, hash := range witPayload.SideChainTransactionHashes {
-				c.PersistSidechainTx(hash)
+				c.PersistSidechainTx(hash, txn.Hash())
 			}
 		}
 	}
@@ -501,16 +501,56 @@ func (c *ChainStore) RollbackUnspend(b *Block) error {
 	return nil
 }
 
+// confirmSnapshot returns the arbiters snapshot to compact the confirm, the
+// latest snapshot is reused while the arbiters are unchanged, and the current
+// arbiters are preferred to the signers of the confirm to form a new one.
+func (c *ChainStore) confirmSnapshot(
+	confirm *payload.Confirm) *arbitersSnapshot {
+	if latest := c.snapshots.getLatest(); latest != nil &&
+		latest.containsAll(confirm) {
+		return latest
+	}
+	if DefaultLedger != nil && DefaultLedger.Arbitrators != nil {
+		snapshot := newArbitersSnapshot(
+			DefaultLedger.Arbitrators.GetArbitrators())
+		if snapshot.containsAll(confirm) {
+			return snapshot
+		}
+	}
+	return newArbitersSnapshot(confirmSigners(confirm))
+}
+
+// key: DATAArbitersSnapshot || snapshot hash
+// value: arbiters snapshot
+// key: DATACompactConfirm || block hash
+// value: compact confirm
+// A new arbiters snapshot is cached as the latest one only after the batch
+// committed, so later confirms never reference a snapshot not persisted.
 func (c *ChainStore) PersistConfirm(
 	confirm *payload.Confirm) error {
+	snapshot := c.confirmSnapshot(confirm)
+	if snapshot != c.snapshots.getLatest() {
+		key := new(bytes.Buffer)
+		key.WriteByte(byte(DATAArbitersSnapshot))
+		if err := snapshot.hash.Serialize(key); err != nil {
+			return err
+		}
+		value := new(bytes.Buffer)
+		if err := snapshot.Serialize(value); err != nil {
+			return err
+		}
+		c.BatchPut(key.Bytes(), value.Bytes())
+		c.pendingSnapshot = snapshot
+	}
+
 	key := new(bytes.Buffer)
-	key.WriteByte(byte(DATAConfirm))
+	key.WriteByte(byte(DATACompactConfirm))
 	if err := confirm.Proposal.BlockHash.Serialize(key); err != nil {
 		return err
 	}
 
 	value := new(bytes.Buffer)
-	if err := confirm.Serialize(value); err != nil {
+	if err := serializeCompactConfirm(value, confirm, snapshot); err != nil {
 		return err
 	}
 
@@ -518,15 +558,19 @@ func (c *ChainStore) PersistConfirm(
 	return nil
 }
 
+// RollbackConfirm deletes the confirm of the block in both the compact and
+// the legacy format, the arbiters snapshots are kept since they are shared.
 func (c *ChainStore) RollbackConfirm(b *Block) error {
-	key := new(bytes.Buffer)
-	key.WriteByte(byte(DATAConfirm))
 	hash := b.Hash()
-	if err := hash.Serialize(key); err != nil {
-		return err
+	for _, prefix := range []DataEntryPrefix{DATACompactConfirm,
+		DATAConfirm} {
+		key := new(bytes.Buffer)
+		key.WriteByte(byte(prefix))
+		if err := hash.Serialize(key); err != nil {
+			return err
+		}
+		c.BatchDelete(key.Bytes())
 	}
-
-	c.BatchDelete(key.Bytes())
 	return nil
 }
 
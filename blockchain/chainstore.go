@@ -58,10 +58,18 @@ type ChainStore struct {
 	blocksCache      map[Uint256]*Block
 
 	persistMutex sync.Mutex
+
+	// snapshots caches the arbiters snapshots of compact confirms.
+	snapshots *snapshotCache
+
+	// pendingSnapshot is the arbiters snapshot written to the batch being
+	// persisted, it becomes the latest snapshot after the batch committed.
+	pendingSnapshot *arbitersSnapshot
 }
 
-func NewChainStore(dataDir string, genesisBlock *Block) (IChainStore, error) {
-	db, err := NewLevelDB(filepath.Join(dataDir, "chain"))
+func NewChainStore(dataDir string, dbEngine string,
+	genesisBlock *Block) (IChainStore, error) {
+	db, err := NewStore(dbEngine, filepath.Join(dataDir, "chain"))
 	if err != nil {
 		return nil, err
 	}
@@ -76,6 +84,7 @@ func NewChainStore(dataDir string, genesisBlock *Block) (IChainStore, error) {
 		fflDB:            fdb,
 		blockHashesCache: make([]Uint256, 0, BlocksCacheSize),
 		blocksCache:      make(map[Uint256]*Block),
+		snapshots:        newSnapshotCache(),
 	}
 
 	if err := s.init(genesisBlock); err != nil {
@@ -306,12 +315,13 @@ func (c *ChainStore) GetAsset(hash Uint256) (*payload.Asset, error) {
 	return asset, nil
 }
 
-func (c *ChainStore) PersistSidechainTx(sidechainTxHash Uint256) {
+func (c *ChainStore) PersistSidechainTx(sidechainTxHash Uint256,
+	withdrawTxHash Uint256) {
 	key := []byte{byte(IXSideChainTx)}
 	key = append(key, sidechainTxHash.Bytes()...)
 
 	// PUT VALUE
-	c.BatchPut(key, []byte{0})
+	c.BatchPut(key, append([]byte{0}, withdrawTxHash.Bytes()...))
 }
 
 func (c *ChainStore) GetSidechainTx(sidechainTxHash Uint256) (byte, error) {
@@ -324,6 +334,24 @@ func (c *ChainStore) GetSidechainTx(sidechainTxHash Uint256) (byte, error) {
 	return data[0], nil
 }
 
+// GetSidechainTxWithdraw returns the hash of the withdraw transaction which
+// included the given side chain transaction. The returned hash is nil if the
+// side chain transaction is recorded without the withdraw transaction hash by
+// earlier versions.
+func (c *ChainStore) GetSidechainTxWithdraw(
+	sidechainTxHash Uint256) (*Uint256, error) {
+	key := []byte{byte(IXSideChainTx)}
+	data, err := c.Get(append(key, sidechainTxHash.Bytes()...))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 1+UINT256SIZE {
+		return nil, nil
+	}
+
+	return Uint256FromBytes(data[1 : 1+UINT256SIZE])
+}
+
 func (c *ChainStore) GetTransaction(txID Uint256) (*Transaction, uint32, error) {
 	key := append([]byte{byte(DATATransaction)}, txID.Bytes()...)
 	value, err := c.Get(key)
@@ -517,6 +545,7 @@ func (c *ChainStore) persist(b *Block, node *BlockNode,
 	defer c.persistMutex.Unlock()
 
 	c.NewBatch()
+	c.pendingSnapshot = nil
 	if err := c.PersistTransactions(b); err != nil {
 		return err
 	}
@@ -535,7 +564,14 @@ func (c *ChainStore) persist(b *Block, node *BlockNode,
 			return err
 		}
 	}
-	return c.BatchCommit()
+	if err := c.BatchCommit(); err != nil {
+		return err
+	}
+	if c.pendingSnapshot != nil {
+		c.snapshots.setLatest(c.pendingSnapshot)
+		c.pendingSnapshot = nil
+	}
+	return nil
 }
 
 func (c *ChainStore) GetFFLDB() IFFLDBChainStore {
@@ -597,7 +633,39 @@ func (c *ChainStore) persistConfirm(confirm *payload.Confirm) error {
 	return nil
 }
 
+// getArbitersSnapshot returns the arbiters snapshot of the hash.
+func (c *ChainStore) getArbitersSnapshot(
+	hash Uint256) (*arbitersSnapshot, error) {
+	if snapshot, ok := c.snapshots.get(hash); ok {
+		return snapshot, nil
+	}
+	prefix := []byte{byte(DATAArbitersSnapshot)}
+	data, err := c.Get(append(prefix, hash.Bytes()...))
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := deserializeArbitersSnapshot(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if !snapshot.hash.IsEqual(hash) {
+		return nil, errors.New("arbiters snapshot hash mismatch")
+	}
+	c.snapshots.add(snapshot)
+	return snapshot, nil
+}
+
+// GetConfirm returns the confirm of the block, confirms in the compact format
+// are expanded to the full ones, and confirms persisted in the legacy format
+// are still readable.
 func (c *ChainStore) GetConfirm(hash Uint256) (*payload.Confirm, error) {
+	compactPrefix := []byte{byte(DATACompactConfirm)}
+	data, err := c.Get(append(compactPrefix, hash.Bytes()...))
+	if err == nil {
+		return deserializeCompactConfirm(bytes.NewReader(data), hash,
+			c.getArbitersSnapshot)
+	}
+
 	var confirm = new(payload.Confirm)
 	prefix := []byte{byte(DATAConfirm)}
 	confirmBytes, err := c.Get(append(prefix, hash.Bytes()...))
@@ -19,10 +19,12 @@ import (
 
 var testChainStore *ChainStore
 var sidechainTxHash common.Uint256
+var withdrawTxHash common.Uint256
 
 func TestChainStoreInit(t *testing.T) {
 	// Get new chainstore
-	temp, err := NewChainStore(test.DataPath, config.DefaultParams.GenesisBlock)
+	temp, err := NewChainStore(test.DataPath, LevelDBEngine,
+		config.DefaultParams.GenesisBlock)
 	testChainStore = temp.(*ChainStore)
 	testChainStore.NewBatch()
 	if err != nil {
@@ -34,6 +36,7 @@ func TestChainStoreInit(t *testing.T) {
 	txHashBytes, _ := common.HexStringToBytes(txHashStr)
 	txHash, _ := common.Uint256FromBytes(txHashBytes)
 	sidechainTxHash = *txHash
+	withdrawTxHash = common.Uint256(common.Sha256D(txHash.Bytes()))
 }
 
 func TestChainStore_PersisSidechainTx(t *testing.T) {
@@ -48,7 +51,7 @@ func TestChainStore_PersisSidechainTx(t *testing.T) {
 	}
 
 	// 2. Run PersistSidechainTx
-	testChainStore.PersistSidechainTx(sidechainTxHash)
+	testChainStore.PersistSidechainTx(sidechainTxHash, withdrawTxHash)
 
 	// Need batch commit here because PersistSidechainTx use BatchPut
 	testChainStore.BatchCommit()
@@ -58,6 +61,10 @@ func TestChainStore_PersisSidechainTx(t *testing.T) {
 	if err != nil {
 		t.Error("Not found the sidechain Tx")
 	}
+	hash, err := testChainStore.GetSidechainTxWithdraw(sidechainTxHash)
+	if err != nil || hash == nil || !hash.IsEqual(withdrawTxHash) {
+		t.Error("Not found the withdraw Tx of sidechain Tx")
+	}
 }
 
 func TestChainStore_RollbackSidechainTx(t *testing.T) {
@@ -99,7 +106,7 @@ func TestChainStore_IsSidechainTxHashDuplicate(t *testing.T) {
 	}
 
 	// 2. Persist the sidechain Tx hash
-	testChainStore.PersistSidechainTx(sidechainTxHash)
+	testChainStore.PersistSidechainTx(sidechainTxHash, withdrawTxHash)
 
 	// Need batch commit here because PersistSidechainTx use BatchPut
 	testChainStore.BatchCommit()
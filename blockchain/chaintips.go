@@ -0,0 +1,135 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"math/big"
+	"sort"
+
+	. "github.com/elastos/Elastos.ELA/common"
+)
+
+// ChainTipStatus describes the state of the branch a chain tip belongs to.
+type ChainTipStatus string
+
+const (
+	// TipActive indicates the tip of the main chain.
+	TipActive ChainTipStatus = "active"
+
+	// TipValidFork indicates the branch is fully downloaded but is not the
+	// main chain, because it has less work or is beyond the irreversible
+	// height.
+	TipValidFork ChainTipStatus = "valid-fork"
+
+	// TipInvalid indicates the branch contains a block failed to reorganize
+	// to the main chain.
+	TipInvalid ChainTipStatus = "invalid"
+
+	// TipHeadersOnly indicates only the headers of the branch are known,
+	// it is the verified headers chain to the checkpoint beyond the best
+	// height.
+	TipHeadersOnly ChainTipStatus = "headers-only"
+)
+
+// ChainTip is the tip of a branch in the block index.
+type ChainTip struct {
+	Height uint32
+	Hash   Uint256
+
+	// BranchLen is the number of blocks from the fork point with the main
+	// chain, it is 0 for the main chain.
+	BranchLen uint32
+
+	// WorkSum is the cumulative work of the branch, it is nil for headers
+	// only tips.
+	WorkSum *big.Int
+
+	Status ChainTipStatus
+
+	// Confirmed indicates the tip block has a DPoS confirm.
+	Confirmed bool
+}
+
+// GetChainTips returns the tips of all branches known in the block index,
+// ordered by height from high to low.
+func (b *BlockChain) GetChainTips() []ChainTip {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	var tips []ChainTip
+	b.index.RLock()
+	for _, node := range b.index.index {
+		if len(node.Children) != 0 {
+			continue
+		}
+		if node == b.BestChain {
+			_, err := b.db.GetConfirm(*node.Hash)
+			tips = append(tips, ChainTip{
+				Height:    node.Height,
+				Hash:      *node.Hash,
+				WorkSum:   new(big.Int).Set(node.WorkSum),
+				Status:    TipActive,
+				Confirmed: err == nil,
+			})
+			continue
+		}
+		if node.InMainChain {
+			continue
+		}
+		tips = append(tips, b.sideChainTip(node))
+	}
+	b.index.RUnlock()
+
+	if tip, ok := b.headersOnlyTip(); ok {
+		tips = append(tips, tip)
+	}
+
+	sort.Slice(tips, func(i, j int) bool {
+		return tips[i].Height > tips[j].Height
+	})
+	return tips
+}
+
+// sideChainTip returns the chain tip of the side chain node.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) sideChainTip(node *BlockNode) ChainTip {
+	tip := ChainTip{
+		Height:    node.Height,
+		Hash:      *node.Hash,
+		WorkSum:   new(big.Int).Set(node.WorkSum),
+		Status:    TipValidFork,
+		Confirmed: b.confirmCache[*node.Hash] != nil,
+	}
+	for n := node; n != nil && !n.InMainChain; n = n.Parent {
+		tip.BranchLen++
+		if _, ok := b.invalidBlocks[*n.Hash]; ok {
+			tip.Status = TipInvalid
+		}
+	}
+	return tip
+}
+
+// headersOnlyTip returns the tip of the verified headers chain if it is
+// beyond the best height.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) headersOnlyTip() (ChainTip, bool) {
+	count := uint32(len(b.verifiedHashes))
+	if count == 0 || b.BestChain == nil {
+		return ChainTip{}, false
+	}
+	height := b.verifiedStart + count - 1
+	if height <= b.BestChain.Height {
+		return ChainTip{}, false
+	}
+	return ChainTip{
+		Height:    height,
+		Hash:      b.verifiedHashes[count-1],
+		BranchLen: height - b.BestChain.Height,
+		Status:    TipHeadersOnly,
+	}, true
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockChain_SideChainTip(t *testing.T) {
+	newNode := func(height uint32, parent *BlockNode,
+		inMainChain bool) *BlockNode {
+		hash := common.Uint256{byte(height), byte(len(parent.Children))}
+		node := &BlockNode{
+			Hash:        &hash,
+			Height:      height,
+			WorkSum:     big.NewInt(int64(height)),
+			InMainChain: inMainChain,
+			Parent:      parent,
+		}
+		parent.Children = append(parent.Children, node)
+		return node
+	}
+	root := &BlockNode{Hash: &common.Uint256{}, InMainChain: true,
+		WorkSum: big.NewInt(0)}
+	main1 := newNode(1, root, true)
+	newNode(2, main1, true)
+	side2 := newNode(2, main1, false)
+	side3 := newNode(3, side2, false)
+
+	b := &BlockChain{
+		confirmCache:  map[common.Uint256]*payload.Confirm{},
+		invalidBlocks: map[common.Uint256]struct{}{},
+	}
+	tip := b.sideChainTip(side3)
+	assert.Equal(t, uint32(3), tip.Height)
+	assert.Equal(t, uint32(2), tip.BranchLen)
+	assert.Equal(t, TipValidFork, tip.Status)
+	assert.False(t, tip.Confirmed)
+
+	b.confirmCache[*side3.Hash] = &payload.Confirm{}
+	b.invalidBlocks[*side2.Hash] = struct{}{}
+	tip = b.sideChainTip(side3)
+	assert.Equal(t, TipInvalid, tip.Status)
+	assert.True(t, tip.Confirmed)
+}
+
+func TestBlockChain_HeadersOnlyTip(t *testing.T) {
+	b := &BlockChain{BestChain: &BlockNode{Height: 10}}
+	_, ok := b.headersOnlyTip()
+	assert.False(t, ok)
+
+	b.verifiedStart = 5
+	b.verifiedHashes = make([]common.Uint256, 6)
+	_, ok = b.headersOnlyTip()
+	assert.False(t, ok)
+
+	b.verifiedHashes = make([]common.Uint256, 10)
+	b.verifiedHashes[9] = common.Uint256{1}
+	tip, ok := b.headersOnlyTip()
+	assert.True(t, ok)
+	assert.Equal(t, uint32(14), tip.Height)
+	assert.Equal(t, uint32(4), tip.BranchLen)
+	assert.Equal(t, common.Uint256{1}, tip.Hash)
+	assert.Equal(t, TipHeadersOnly, tip.Status)
+}
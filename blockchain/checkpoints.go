@@ -0,0 +1,101 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/common/log"
+	. "github.com/elastos/Elastos.ELA/core/types"
+)
+
+// LatestCheckpoint returns the most recent checkpoint, or nil if there are no
+// checkpoints for the active network.
+func (b *BlockChain) LatestCheckpoint() *config.Checkpoint {
+	checkpoints := b.chainParams.Checkpoints
+	if len(checkpoints) == 0 {
+		return nil
+	}
+	return &checkpoints[len(checkpoints)-1]
+}
+
+// SetVerifiedHeaders sets the hashes of the headers chain which has been
+// verified to connect to the latest checkpoint, hashes[0] is the hash of the
+// block at startHeight.  The script validation of the blocks in the verified
+// headers chain will be skipped.
+func (b *BlockChain) SetVerifiedHeaders(startHeight uint32,
+	hashes []Uint256) error {
+	checkpoint := b.LatestCheckpoint()
+	if checkpoint == nil {
+		return errors.New("no checkpoint for the active network")
+	}
+	if len(hashes) == 0 {
+		return errors.New("empty verified headers")
+	}
+	lastHeight := startHeight + uint32(len(hashes)) - 1
+	if lastHeight != checkpoint.Height ||
+		!hashes[len(hashes)-1].IsEqual(checkpoint.Hash) {
+		return fmt.Errorf("verified headers end at height %d does not"+
+			" match the checkpoint at height %d", lastHeight,
+			checkpoint.Height)
+	}
+
+	b.mutex.Lock()
+	b.verifiedStart = startHeight
+	b.verifiedHashes = hashes
+	b.mutex.Unlock()
+	return nil
+}
+
+// ResetVerifiedHeaders releases the verified headers chain.
+func (b *BlockChain) ResetVerifiedHeaders() {
+	b.mutex.Lock()
+	b.verifiedStart = 0
+	b.verifiedHashes = nil
+	b.mutex.Unlock()
+}
+
+// isAssumedValid returns if the block is in the verified headers chain to the
+// checkpoint, so that its scripts are assumed to be valid.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) isAssumedValid(block *Block) bool {
+	count := uint32(len(b.verifiedHashes))
+	if count == 0 || block.Height < b.verifiedStart ||
+		block.Height >= b.verifiedStart+count {
+		return false
+	}
+	return b.verifiedHashes[block.Height-b.verifiedStart].IsEqual(block.Hash())
+}
+
+// LocateHeaders returns the headers of the blocks after the first known block
+// in the locator until the provided stop hash is reached, or up to a max of
+// maxHeaders block headers.  The stop hash is ignored if it is unknown.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) LocateHeaders(locator []*Uint256, hashStop *Uint256,
+	maxHeaders uint32) []*Header {
+	stopHash := hashStop
+	if exist, _, err := b.db.GetFFLDB().BlockExists(hashStop); err != nil ||
+		!exist {
+		stopHash = &EmptyHash
+	}
+
+	hashes := b.LocateBlocks(locator, stopHash, maxHeaders)
+	headers := make([]*Header, 0, len(hashes))
+	for _, hash := range hashes {
+		header, err := b.GetHeader(*hash)
+		if err != nil {
+			log.Errorf("LocateHeaders error %s", err)
+			break
+		}
+		headers = append(headers, header)
+	}
+	return headers
+}
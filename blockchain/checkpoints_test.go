@@ -0,0 +1,63 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/core/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockChain_SetVerifiedHeaders(t *testing.T) {
+	blocks := make([]*types.Block, 0, 10)
+	hashes := make([]common.Uint256, 0, 10)
+	for i := uint32(0); i < 10; i++ {
+		block := &types.Block{Header: types.Header{Height: 100 + i}}
+		if i > 0 {
+			block.Previous = hashes[i-1]
+		}
+		blocks = append(blocks, block)
+		hashes = append(hashes, block.Hash())
+	}
+
+	params := config.DefaultParams
+	params.Checkpoints = nil
+	chain := &BlockChain{chainParams: &params}
+
+	// No checkpoints.
+	assert.Nil(t, chain.LatestCheckpoint())
+	assert.Error(t, chain.SetVerifiedHeaders(100, hashes))
+
+	params.Checkpoints = []config.Checkpoint{
+		{Height: 50, Hash: common.Uint256{1}},
+		{Height: 109, Hash: hashes[9]},
+	}
+	assert.Equal(t, uint32(109), chain.LatestCheckpoint().Height)
+
+	// Verified headers do not end at the checkpoint.
+	assert.Error(t, chain.SetVerifiedHeaders(100, hashes[:9]))
+	assert.Error(t, chain.SetVerifiedHeaders(101, hashes))
+
+	assert.NoError(t, chain.SetVerifiedHeaders(100, hashes))
+	for _, block := range blocks {
+		assert.True(t, chain.isAssumedValid(block))
+	}
+
+	// Blocks out of the verified headers chain.
+	assert.False(t, chain.isAssumedValid(&types.Block{
+		Header: types.Header{Height: 99}}))
+	assert.False(t, chain.isAssumedValid(&types.Block{
+		Header: types.Header{Height: 110, Previous: hashes[9]}}))
+	assert.False(t, chain.isAssumedValid(&types.Block{
+		Header: types.Header{Height: 105, Previous: hashes[3]}}))
+
+	chain.ResetVerifiedHeaders()
+	assert.False(t, chain.isAssumedValid(blocks[0]))
+}
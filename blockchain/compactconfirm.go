@@ -0,0 +1,288 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+
+	. "github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+const (
+	// maxSnapshotArbiters is the max count of arbiters of a snapshot.
+	maxSnapshotArbiters = 1024
+
+	// maxCachedSnapshots is the max count of arbiters snapshots cached for
+	// expanding confirms.
+	maxCachedSnapshots = 64
+)
+
+// arbitersSnapshot is a list of arbiters stored once and referenced by the
+// compact confirms by the hash of it, the sponsor and the signers of a
+// compact confirm are indexes of the list instead of public keys.
+type arbitersSnapshot struct {
+	hash    Uint256
+	keys    [][]byte
+	indexes map[string]uint64
+}
+
+func newArbitersSnapshot(keys [][]byte) *arbitersSnapshot {
+	s := &arbitersSnapshot{
+		keys:    keys,
+		indexes: make(map[string]uint64, len(keys)),
+	}
+	for i, key := range keys {
+		s.indexes[BytesToHexString(key)] = uint64(i)
+	}
+	buf := new(bytes.Buffer)
+	s.Serialize(buf)
+	s.hash = Uint256(Sha256D(buf.Bytes()))
+	return s
+}
+
+func (s *arbitersSnapshot) Serialize(w io.Writer) error {
+	if err := WriteVarUint(w, uint64(len(s.keys))); err != nil {
+		return err
+	}
+	for _, key := range s.keys {
+		if err := WriteVarBytes(w, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deserializeArbitersSnapshot(r io.Reader) (*arbitersSnapshot, error) {
+	count, err := ReadVarUint(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count > maxSnapshotArbiters {
+		return nil, errors.New("too many arbiters in snapshot")
+	}
+	keys := make([][]byte, 0, count)
+	for i := uint64(0); i < count; i++ {
+		key, err := ReadVarBytes(r, crypto.NegativeBigLength, "arbiter")
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return newArbitersSnapshot(keys), nil
+}
+
+// index returns the index of the public key in the snapshot.
+func (s *arbitersSnapshot) index(key []byte) (uint64, bool) {
+	index, ok := s.indexes[BytesToHexString(key)]
+	return index, ok
+}
+
+// containsAll returns if the sponsor and all the signers of the confirm are
+// in the snapshot.
+func (s *arbitersSnapshot) containsAll(confirm *payload.Confirm) bool {
+	if _, ok := s.index(confirm.Proposal.Sponsor); !ok {
+		return false
+	}
+	for _, vote := range confirm.Votes {
+		if _, ok := s.index(vote.Signer); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// confirmSigners returns the distinct sponsor and signers of the confirm.
+func confirmSigners(confirm *payload.Confirm) [][]byte {
+	keys := [][]byte{confirm.Proposal.Sponsor}
+	exists := map[string]struct{}{
+		BytesToHexString(confirm.Proposal.Sponsor): {},
+	}
+	for _, vote := range confirm.Votes {
+		key := BytesToHexString(vote.Signer)
+		if _, ok := exists[key]; ok {
+			continue
+		}
+		exists[key] = struct{}{}
+		keys = append(keys, vote.Signer)
+	}
+	return keys
+}
+
+// serializeCompactConfirm writes the confirm referencing the sponsor and the
+// signers by indexes of the snapshot, the block hash and the proposal hash of
+// votes are omitted since they are known when expanding.
+func serializeCompactConfirm(w io.Writer, confirm *payload.Confirm,
+	snapshot *arbitersSnapshot) error {
+	sponsor, ok := snapshot.index(confirm.Proposal.Sponsor)
+	if !ok {
+		return errors.New("sponsor not found in arbiters snapshot")
+	}
+	if err := snapshot.hash.Serialize(w); err != nil {
+		return err
+	}
+	if err := WriteVarUint(w, sponsor); err != nil {
+		return err
+	}
+	if err := WriteUint32(w, confirm.Proposal.ViewOffset); err != nil {
+		return err
+	}
+	if err := WriteVarBytes(w, confirm.Proposal.Sign); err != nil {
+		return err
+	}
+
+	if err := WriteVarUint(w, uint64(len(confirm.Votes))); err != nil {
+		return err
+	}
+	for _, vote := range confirm.Votes {
+		signer, ok := snapshot.index(vote.Signer)
+		if !ok {
+			return errors.New("signer not found in arbiters snapshot")
+		}
+		if err := WriteVarUint(w, signer); err != nil {
+			return err
+		}
+		var accept uint8
+		if vote.Accept {
+			accept = 1
+		}
+		if err := WriteUint8(w, accept); err != nil {
+			return err
+		}
+		if err := WriteVarBytes(w, vote.Sign); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deserializeCompactConfirm reads the compact confirm of the block and
+// expands it to the full confirm by the arbiters snapshot.
+func deserializeCompactConfirm(r io.Reader, blockHash Uint256,
+	getSnapshot func(Uint256) (*arbitersSnapshot, error)) (*payload.Confirm,
+	error) {
+	var snapshotHash Uint256
+	if err := snapshotHash.Deserialize(r); err != nil {
+		return nil, err
+	}
+	snapshot, err := getSnapshot(snapshotHash)
+	if err != nil {
+		return nil, err
+	}
+	arbiter := func(index uint64) ([]byte, error) {
+		if index >= uint64(len(snapshot.keys)) {
+			return nil, errors.New("arbiter index out of snapshot")
+		}
+		return snapshot.keys[index], nil
+	}
+
+	confirm := &payload.Confirm{}
+	sponsor, err := ReadVarUint(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if confirm.Proposal.Sponsor, err = arbiter(sponsor); err != nil {
+		return nil, err
+	}
+	confirm.Proposal.BlockHash = blockHash
+	if confirm.Proposal.ViewOffset, err = ReadUint32(r); err != nil {
+		return nil, err
+	}
+	confirm.Proposal.Sign, err = ReadVarBytes(r, crypto.SignatureLength,
+		"proposal sign")
+	if err != nil {
+		return nil, err
+	}
+	proposalHash := confirm.Proposal.Hash()
+
+	count, err := ReadVarUint(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count > maxSnapshotArbiters {
+		return nil, errors.New("too many votes in compact confirm")
+	}
+	confirm.Votes = make([]payload.DPOSProposalVote, count)
+	for i := range confirm.Votes {
+		vote := &confirm.Votes[i]
+		vote.ProposalHash = proposalHash
+		signer, err := ReadVarUint(r, 0)
+		if err != nil {
+			return nil, err
+		}
+		if vote.Signer, err = arbiter(signer); err != nil {
+			return nil, err
+		}
+		accept, err := ReadUint8(r)
+		if err != nil {
+			return nil, err
+		}
+		vote.Accept = accept == 1
+		vote.Sign, err = ReadVarBytes(r, crypto.SignatureLength, "vote sign")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return confirm, nil
+}
+
+// snapshotCache holds the latest arbiters snapshot used to compact confirms,
+// and the snapshots recently read to expand confirms.  A nil cache caches
+// nothing.
+type snapshotCache struct {
+	sync.Mutex
+	latest    *arbitersSnapshot
+	snapshots map[Uint256]*arbitersSnapshot
+}
+
+func newSnapshotCache() *snapshotCache {
+	return &snapshotCache{snapshots: make(map[Uint256]*arbitersSnapshot)}
+}
+
+func (c *snapshotCache) getLatest() *arbitersSnapshot {
+	if c == nil {
+		return nil
+	}
+	c.Lock()
+	defer c.Unlock()
+	return c.latest
+}
+
+func (c *snapshotCache) setLatest(s *arbitersSnapshot) {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	c.latest = s
+	c.Unlock()
+	c.add(s)
+}
+
+func (c *snapshotCache) get(hash Uint256) (*arbitersSnapshot, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.Lock()
+	defer c.Unlock()
+	s, ok := c.snapshots[hash]
+	return s, ok
+}
+
+func (c *snapshotCache) add(s *arbitersSnapshot) {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	if len(c.snapshots) >= maxCachedSnapshots {
+		c.snapshots = make(map[Uint256]*arbitersSnapshot)
+	}
+	c.snapshots[s.hash] = s
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestConfirm(arbiters [][]byte, blockHash common.Uint256) *payload.Confirm {
+	confirm := &payload.Confirm{
+		Proposal: payload.DPOSProposal{
+			Sponsor:    arbiters[1],
+			BlockHash:  blockHash,
+			ViewOffset: 2,
+			Sign:       randomBytes(64),
+		},
+	}
+	for i, arbiter := range arbiters {
+		confirm.Votes = append(confirm.Votes, payload.DPOSProposalVote{
+			ProposalHash: confirm.Proposal.Hash(),
+			Signer:       arbiter,
+			Accept:       i != 3,
+			Sign:         randomBytes(64),
+		})
+	}
+	return confirm
+}
+
+func TestCompactConfirm(t *testing.T) {
+	arbiters := make([][]byte, 0, 36)
+	for i := 0; i < 36; i++ {
+		arbiters = append(arbiters, randomBytes(33))
+	}
+	snapshot := newArbitersSnapshot(arbiters)
+	blockHash := common.Uint256(common.Sha256D(randomBytes(32)))
+	confirm := newTestConfirm(arbiters[:25], blockHash)
+	assert.True(t, snapshot.containsAll(confirm))
+
+	compact := new(bytes.Buffer)
+	assert.NoError(t, serializeCompactConfirm(compact, confirm, snapshot))
+	full := new(bytes.Buffer)
+	assert.NoError(t, confirm.Serialize(full))
+	assert.True(t, compact.Len()*3 < full.Len()*2)
+
+	getSnapshot := func(hash common.Uint256) (*arbitersSnapshot, error) {
+		if !hash.IsEqual(snapshot.hash) {
+			return nil, errors.New("unknown snapshot")
+		}
+		return snapshot, nil
+	}
+	expanded, err := deserializeCompactConfirm(
+		bytes.NewReader(compact.Bytes()), blockHash, getSnapshot)
+	assert.NoError(t, err)
+	expandedBuf := new(bytes.Buffer)
+	assert.NoError(t, expanded.Serialize(expandedBuf))
+	assert.Equal(t, full.Bytes(), expandedBuf.Bytes())
+
+	// the snapshot is restored with the same hash.
+	buf := new(bytes.Buffer)
+	assert.NoError(t, snapshot.Serialize(buf))
+	restored, err := deserializeArbitersSnapshot(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, snapshot.hash, restored.hash)
+
+	// signers out of the snapshot can not be compacted.
+	other := newTestConfirm([][]byte{arbiters[0], randomBytes(33)},
+		blockHash)
+	assert.False(t, snapshot.containsAll(other))
+	assert.Error(t, serializeCompactConfirm(new(bytes.Buffer), other,
+		snapshot))
+	signers := newArbitersSnapshot(confirmSigners(other))
+	assert.True(t, signers.containsAll(other))
+	assert.Equal(t, 2, len(signers.keys))
+}
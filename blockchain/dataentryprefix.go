@@ -15,6 +15,11 @@ const (
 	DATATransaction DataEntryPrefix = 0x02
 	DATAConfirm     DataEntryPrefix = 0x03
 
+	// DATACompactConfirm holds confirms referencing arbiters by indexes of
+	// the arbiters snapshots held by DATAArbitersSnapshot.
+	DATACompactConfirm   DataEntryPrefix = 0x04
+	DATAArbitersSnapshot DataEntryPrefix = 0x05
+
 	//SYSTEM
 	SYSCurrentBlock      DataEntryPrefix = 0x40
 	SYSCurrentBookKeeper DataEntryPrefix = 0x42
@@ -25,6 +30,12 @@ const (
 	IXUnspentUTXO    DataEntryPrefix = 0x91
 	IXSideChainTx    DataEntryPrefix = 0x92
 
+	// IXCandidateHistory holds information changes of CR candidates by cid.
+	IXCandidateHistory DataEntryPrefix = 0x93
+
+	// IXProducerHistory holds changes of producers by owner public key.
+	IXProducerHistory DataEntryPrefix = 0x94
+
 	// ASSET
 	STInfo DataEntryPrefix = 0xc0
 
@@ -0,0 +1,53 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/elastos/Elastos.ELA/common/log"
+)
+
+// SetHaltHeight halts the block chain at the height, blocks higher than the
+// height will be neither accepted nor generated until the chain is released.
+// It's an emergency response to critical consensus bugs, a zero height
+// releases the chain.
+func (b *BlockChain) SetHaltHeight(height uint32) {
+	b.mutex.Lock()
+	b.haltHeight = height
+	b.mutex.Unlock()
+
+	if height == 0 {
+		log.Warn("block chain released")
+	} else {
+		log.Warnf("block chain halted at height %d", height)
+	}
+}
+
+// HaltHeight returns the height the block chain halted at, zero means the
+// chain is not halted.
+func (b *BlockChain) HaltHeight() uint32 {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.haltHeight
+}
+
+// CheckHalted returns an error if the block at the height is beyond the halt
+// height.
+func (b *BlockChain) CheckHalted(height uint32) error {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.checkHalted(height)
+}
+
+// checkHalted is the same as CheckHalted, the caller must hold the mutex.
+func (b *BlockChain) checkHalted(height uint32) error {
+	if b.haltHeight > 0 && height > b.haltHeight {
+		return fmt.Errorf("block chain halted at height %d, block of height"+
+			" %d is refused", b.haltHeight, height)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockChain_SetHaltHeight(t *testing.T) {
+	chain := &BlockChain{}
+	assert.Equal(t, uint32(0), chain.HaltHeight())
+	assert.NoError(t, chain.CheckHalted(1000))
+
+	chain.SetHaltHeight(100)
+	assert.Equal(t, uint32(100), chain.HaltHeight())
+	assert.NoError(t, chain.CheckHalted(99))
+	assert.NoError(t, chain.CheckHalted(100))
+	assert.Error(t, chain.CheckHalted(101))
+
+	// a zero height releases the chain.
+	chain.SetHaltHeight(0)
+	assert.NoError(t, chain.CheckHalted(101))
+}
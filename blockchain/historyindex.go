@@ -0,0 +1,132 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/utils"
+)
+
+const (
+	// historyRecordTag tags the keys of records, which are the record key,
+	// the height and the sequence of the record on the height.
+	historyRecordTag byte = 0x00
+
+	// historyHeightTag tags the keys of the height index, which are the
+	// height and the sequence of the record on the height, the values are
+	// the record keys.
+	historyHeightTag byte = 0x01
+)
+
+// historyIndex is a utils.HistoryIndex stored in the chain store under a
+// data entry prefix.  Records of one height are written or removed in one
+// batch, so a record never survives without its height index.
+type historyIndex struct {
+	store  *ChainStore
+	prefix DataEntryPrefix
+}
+
+// recordKey returns the database key of the sequence-th record happened on
+// the height.
+func (h *historyIndex) recordKey(key []byte, height uint32,
+	sequence uint32) []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(h.prefix))
+	buf.WriteByte(historyRecordTag)
+	common.WriteVarBytes(buf, key)
+	binary.Write(buf, binary.BigEndian, height)
+	binary.Write(buf, binary.BigEndian, sequence)
+	return buf.Bytes()
+}
+
+// heightKey returns the database key of the height index, sequence is
+// omitted if it's nil.
+func (h *historyIndex) heightKey(height uint32, sequence *uint32) []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(h.prefix))
+	buf.WriteByte(historyHeightTag)
+	binary.Write(buf, binary.BigEndian, height)
+	if sequence != nil {
+		binary.Write(buf, binary.BigEndian, *sequence)
+	}
+	return buf.Bytes()
+}
+
+// batchDeleteRecords deletes records indexed by the iterator from its current
+// position, should be called with a batch created.
+func (h *historyIndex) batchDeleteRecords(iter IIterator, valid bool) {
+	for ; valid; valid = iter.Next() {
+		indexKey := iter.Key()
+		height := binary.BigEndian.Uint32(indexKey[2:6])
+		sequence := binary.BigEndian.Uint32(indexKey[6:10])
+		h.store.BatchDelete(h.recordKey(iter.Value(), height, sequence))
+		h.store.BatchDelete(append([]byte(nil), indexKey...))
+	}
+}
+
+func (h *historyIndex) PutRecords(height uint32,
+	records []utils.HistoryRecord) error {
+	h.store.persistMutex.Lock()
+	defer h.store.persistMutex.Unlock()
+
+	iter := h.store.NewIterator(h.heightKey(height, nil))
+	defer iter.Release()
+	exist := iter.Next()
+	if !exist && len(records) == 0 {
+		return nil
+	}
+
+	h.store.NewBatch()
+	h.batchDeleteRecords(iter, exist)
+	for i, r := range records {
+		sequence := uint32(i)
+		h.store.BatchPut(h.recordKey(r.Key, height, sequence), r.Value)
+		h.store.BatchPut(h.heightKey(height, &sequence), r.Key)
+	}
+	return h.store.BatchCommit()
+}
+
+func (h *historyIndex) GetRecords(key []byte) ([][]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(h.prefix))
+	buf.WriteByte(historyRecordTag)
+	if err := common.WriteVarBytes(buf, key); err != nil {
+		return nil, err
+	}
+
+	iter := h.store.NewIterator(buf.Bytes())
+	defer iter.Release()
+	var values [][]byte
+	for iter.Next() {
+		values = append(values, append([]byte(nil), iter.Value()...))
+	}
+	return values, nil
+}
+
+func (h *historyIndex) RollbackTo(height uint32) error {
+	h.store.persistMutex.Lock()
+	defer h.store.persistMutex.Unlock()
+
+	iter := h.store.NewIterator([]byte{byte(h.prefix), historyHeightTag})
+	defer iter.Release()
+	exist := iter.Seek(h.heightKey(height+1, nil))
+	if !exist {
+		return nil
+	}
+
+	h.store.NewBatch()
+	h.batchDeleteRecords(iter, exist)
+	return h.store.BatchCommit()
+}
+
+// NewHistoryIndex returns a utils.HistoryIndex storing records under the
+// prefix.
+func (c *ChainStore) NewHistoryIndex(prefix DataEntryPrefix) utils.HistoryIndex {
+	return &historyIndex{store: c, prefix: prefix}
+}
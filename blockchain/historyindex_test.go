@@ -0,0 +1,79 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "historyindex")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	db, err := NewLevelDB(filepath.Join(dir, "chain"))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := &ChainStore{IStore: db}
+	index := store.NewHistoryIndex(IXCandidateHistory)
+	other := store.NewHistoryIndex(DataEntryPrefix(0xff))
+	key1, key2 := []byte{0x01}, []byte{0x01, 0x02}
+
+	assert.NoError(t, index.PutRecords(1, []utils.HistoryRecord{
+		{Key: key1, Value: []byte("a")},
+		{Key: key2, Value: []byte("b")},
+		{Key: key1, Value: []byte("c")},
+	}))
+	assert.NoError(t, index.PutRecords(2, nil))
+	assert.NoError(t, index.PutRecords(3, []utils.HistoryRecord{
+		{Key: key1, Value: []byte("d")},
+	}))
+	assert.NoError(t, other.PutRecords(3, []utils.HistoryRecord{
+		{Key: key1, Value: []byte("e")},
+	}))
+
+	values, err := index.GetRecords(key1)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("c"), []byte("d")}, values)
+	values, err = index.GetRecords(key2)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("b")}, values)
+
+	// records written on the height again replace the old ones
+	assert.NoError(t, index.PutRecords(1, []utils.HistoryRecord{
+		{Key: key2, Value: []byte("f")},
+	}))
+	values, err = index.GetRecords(key1)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("d")}, values)
+	values, err = index.GetRecords(key2)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("f")}, values)
+
+	// rollback removes records above the height only from the index
+	assert.NoError(t, index.RollbackTo(2))
+	values, err = index.GetRecords(key1)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(values))
+	values, err = index.GetRecords(key2)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("f")}, values)
+	values, err = other.GetRecords(key1)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("e")}, values)
+
+	assert.NoError(t, index.RollbackTo(0))
+	values, err = index.GetRecords(key2)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(values))
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/elastos/Elastos.ELA/common/log"
+	. "github.com/elastos/Elastos.ELA/core/types"
+)
+
+// EnableInvariantChecks makes the block chain cross-check the invariants
+// between the DPoS and CR states after each processed block, and panic with a
+// dump of the violations if any found.  It's a debug mode to catch state bugs
+// early in test networks, it should be called before any block processed.
+func (b *BlockChain) EnableInvariantChecks() {
+	b.invariantChecks = true
+	log.Warn("state invariant checks enabled, the node panics on violations")
+}
+
+// checkInvariants checks the states processed the block if invariant checks
+// enabled.
+func (b *BlockChain) checkInvariants(block *Block) {
+	if !b.invariantChecks {
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	if err := DefaultLedger.Arbitrators.CheckInvariants(block.Height); err != nil {
+		fmt.Fprintf(buf, "DPoS state:\n%s\n", err)
+	}
+	if b.crCommittee != nil {
+		if err := b.crCommittee.GetState().CheckInvariants(); err != nil {
+			fmt.Fprintf(buf, "CR state:\n%s\n", err)
+		}
+	}
+	if buf.Len() == 0 {
+		return
+	}
+
+	arbitersHash := DefaultLedger.Arbitrators.GetKeyFrameHash()
+	dump := fmt.Sprintf("state invariants violated after block %s at "+
+		"height %d\n%sarbiters key frame: %s\n", block.Hash(), block.Height,
+		buf.String(), arbitersHash)
+	stateHash, err := DefaultLedger.Arbitrators.GetStateKeyFrameHash()
+	if err == nil {
+		dump += fmt.Sprintf("DPoS state key frame: %s\n", stateHash)
+	}
+	if b.crCommittee != nil {
+		crHeight, crHash := b.crCommittee.GetStateKeyFrameHash()
+		dump += fmt.Sprintf("CR state key frame: %s at height %d\n", crHash,
+			crHeight)
+	}
+	log.Error(dump)
+	DefaultLedger.Arbitrators.DumpInfo(block.Height)
+	panic(dump)
+}
@@ -12,6 +12,7 @@ import (
 	. "github.com/elastos/Elastos.ELA/core/types"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
 	"github.com/elastos/Elastos.ELA/database"
+	"github.com/elastos/Elastos.ELA/utils"
 )
 
 // IChainStore provides func with store package.
@@ -37,8 +38,9 @@ type IChainStore interface {
 	PersistAsset(assetid Uint256, asset payload.Asset) error
 	GetAsset(hash Uint256) (*payload.Asset, error)
 
-	PersistSidechainTx(sidechainTxHash Uint256)
+	PersistSidechainTx(sidechainTxHash Uint256, withdrawTxHash Uint256)
 	GetSidechainTx(sidechainTxHash Uint256) (byte, error)
+	GetSidechainTxWithdraw(sidechainTxHash Uint256) (*Uint256, error)
 
 	GetCurrentBlockHash() Uint256
 	SetHeight(height uint32)
@@ -54,6 +56,10 @@ type IChainStore interface {
 	IsSidechainTxHashDuplicate(sidechainTxHash Uint256) bool
 	IsBlockInStore(hash *Uint256) bool
 
+	// NewHistoryIndex returns a history index storing records under the
+	// prefix.
+	NewHistoryIndex(prefix DataEntryPrefix) utils.HistoryIndex
+
 	Close()
 }
 
@@ -0,0 +1,131 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"github.com/cockroachdb/pebble"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+type PebbleDB struct {
+	db    *pebble.DB // Pebble instance
+	batch *pebble.Batch
+}
+
+func NewPebbleDB(file string) (*PebbleDB, error) {
+	db, err := pebble.Open(file, &pebble.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PebbleDB{
+		db:    db,
+		batch: nil,
+	}, nil
+}
+
+func (pdb *PebbleDB) Put(key []byte, value []byte) error {
+	return pdb.db.Set(key, value, pebble.Sync)
+}
+
+func (pdb *PebbleDB) Get(key []byte) ([]byte, error) {
+	value, closer, err := pdb.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	// The returned value is only valid until the closer is closed, so
+	// return a copy of it.
+	return append([]byte{}, value...), nil
+}
+
+func (pdb *PebbleDB) Delete(key []byte) error {
+	return pdb.db.Delete(key, pebble.Sync)
+}
+
+func (pdb *PebbleDB) NewBatch() {
+	pdb.batch = pdb.db.NewBatch()
+}
+
+func (pdb *PebbleDB) BatchPut(key []byte, value []byte) {
+	pdb.batch.Set(key, value, nil)
+}
+
+func (pdb *PebbleDB) BatchDelete(key []byte) {
+	pdb.batch.Delete(key, nil)
+}
+
+func (pdb *PebbleDB) BatchCommit() error {
+	return pdb.batch.Commit(pebble.Sync)
+}
+
+func (pdb *PebbleDB) Close() error {
+	return pdb.db.Close()
+}
+
+func (pdb *PebbleDB) NewIterator(prefix []byte) IIterator {
+	r := util.BytesPrefix(prefix)
+	iter := pdb.db.NewIter(&pebble.IterOptions{
+		LowerBound: r.Start,
+		UpperBound: r.Limit,
+	})
+	return &PebbleIterator{iter: iter}
+}
+
+// PebbleIterator wraps the pebble iterator to behave as the LevelDB one, that
+// is the first call of Next or Prev moves to the first or last key.
+type PebbleIterator struct {
+	iter       *pebble.Iterator
+	positioned bool
+}
+
+func (it *PebbleIterator) Next() bool {
+	if !it.positioned {
+		return it.First()
+	}
+	return it.iter.Next()
+}
+
+func (it *PebbleIterator) Prev() bool {
+	if !it.positioned {
+		return it.Last()
+	}
+	return it.iter.Prev()
+}
+
+func (it *PebbleIterator) First() bool {
+	it.positioned = true
+	return it.iter.First()
+}
+
+func (it *PebbleIterator) Last() bool {
+	it.positioned = true
+	return it.iter.Last()
+}
+
+func (it *PebbleIterator) Seek(key []byte) bool {
+	it.positioned = true
+	return it.iter.SeekGE(key)
+}
+
+func (it *PebbleIterator) Key() []byte {
+	if !it.iter.Valid() {
+		return nil
+	}
+	return it.iter.Key()
+}
+
+func (it *PebbleIterator) Value() []byte {
+	if !it.iter.Valid() {
+		return nil
+	}
+	return it.iter.Value()
+}
+
+func (it *PebbleIterator) Release() {
+	it.iter.Close()
+}
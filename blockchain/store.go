@@ -5,6 +5,8 @@
 
 package blockchain
 
+import "fmt"
+
 type IIterator interface {
 	Next() bool
 	Prev() bool
@@ -27,3 +29,24 @@ type IStore interface {
 	Close() error
 	NewIterator(prefix []byte) IIterator
 }
+
+const (
+	// LevelDBEngine indicates the chain store is backed by LevelDB.
+	LevelDBEngine = "leveldb"
+
+	// PebbleEngine indicates the chain store is backed by Pebble.
+	PebbleEngine = "pebble"
+)
+
+// NewStore opens the key-value store of the specified engine at file, an
+// empty engine means the default LevelDB engine.
+func NewStore(engine string, file string) (IStore, error) {
+	switch engine {
+	case "", LevelDBEngine:
+		return NewLevelDB(file)
+	case PebbleEngine:
+		return NewPebbleDB(file)
+	default:
+		return nil, fmt.Errorf("unknown database engine %s", engine)
+	}
+}
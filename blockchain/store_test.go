@@ -0,0 +1,68 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/utils/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStore(t *testing.T) {
+	_, err := NewStore("unknown", filepath.Join(test.DataPath, "unknown"))
+	assert.Error(t, err)
+
+	for _, engine := range []string{LevelDBEngine, PebbleEngine} {
+		path := filepath.Join(test.DataPath, "store_"+engine)
+		os.RemoveAll(path)
+
+		db, err := NewStore(engine, path)
+		if !assert.NoError(t, err, engine) {
+			continue
+		}
+
+		assert.NoError(t, db.Put([]byte("a1"), []byte("v1")))
+		db.NewBatch()
+		db.BatchPut([]byte("a2"), []byte("v2"))
+		db.BatchPut([]byte("a3"), []byte("v3"))
+		db.BatchPut([]byte("b1"), []byte("v4"))
+		assert.NoError(t, db.BatchCommit())
+
+		value, err := db.Get([]byte("a2"))
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("v2"), value)
+
+		assert.NoError(t, db.Delete([]byte("a2")))
+		_, err = db.Get([]byte("a2"))
+		assert.Error(t, err, engine)
+
+		// Next on a new iterator should move to the first key of prefix.
+		iter := db.NewIterator([]byte("a"))
+		var keys []string
+		for iter.Next() {
+			keys = append(keys, string(iter.Key()))
+		}
+		iter.Release()
+		assert.Equal(t, []string{"a1", "a3"}, keys, engine)
+
+		// Prev on a new iterator should move to the last key of prefix.
+		iter = db.NewIterator([]byte("a"))
+		assert.True(t, iter.Prev())
+		assert.Equal(t, []byte("a3"), iter.Key())
+		assert.Equal(t, []byte("v3"), iter.Value())
+		assert.True(t, iter.Seek([]byte("a2")))
+		assert.Equal(t, []byte("a3"), iter.Key())
+		assert.False(t, iter.Next())
+		iter.Release()
+
+		assert.NoError(t, db.Close())
+		os.RemoveAll(path)
+	}
+}
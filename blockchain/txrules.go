@@ -0,0 +1,565 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/elastos/Elastos.ELA/common/log"
+	. "github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	. "github.com/elastos/Elastos.ELA/errors"
+)
+
+// anyPayloadVersion registers rules for all payload versions of a transaction
+// type that have no rules registered for the exact payload version.
+const anyPayloadVersion = -1
+
+// txRuleContext holds what a transaction rule checks a transaction against.
+type txRuleContext struct {
+	chain       *BlockChain
+	blockHeight uint32
+	txn         *Transaction
+	references  map[*Input]*Output
+}
+
+// txRule is a single named check of a transaction, errCode is returned by the
+// validator if the check fails.
+type txRule struct {
+	name    string
+	errCode ErrCode
+	check   func(ctx *txRuleContext) error
+
+	// activationParam is the chain parameter of the activation height the
+	// rule checks against, empty if the rule is not height gated.
+	activationParam string
+}
+
+// txRules declares the rules of a transaction type and payload version.
+type txRules struct {
+	// sanity rules run before the common sanity checks.
+	sanity []txRule
+
+	// context rules run before the common context checks.
+	context []txRule
+
+	// postDoubleSpend rules run right after the double spend check, before
+	// the other common context checks.
+	postDoubleSpend []txRule
+
+	// standalone returns true if the transaction is valid once the context
+	// rules passed, in that case the common context checks such as double
+	// spend, fee and signature are skipped.
+	standalone func(txn *Transaction) bool
+}
+
+type txRulesKey struct {
+	txType         TxType
+	payloadVersion int
+}
+
+// txRulesRegistry holds the registered rules of transaction types.
+var txRulesRegistry = make(map[txRulesKey]*txRules)
+
+// registerTxRules registers rules of the transaction type and payload version,
+// use anyPayloadVersion to register rules for all payload versions. It panics
+// if rules of the same key are registered twice, so it's expected to be called
+// from init functions only.
+func registerTxRules(txType TxType, payloadVersion int, rules *txRules) {
+	key := txRulesKey{txType: txType, payloadVersion: payloadVersion}
+	if _, ok := txRulesRegistry[key]; ok {
+		panic("duplicate rules of transaction type " + txType.Name())
+	}
+	txRulesRegistry[key] = rules
+}
+
+// getTxRules returns the rules of the transaction, an empty rules will be
+// returned if no rules registered for the transaction.
+func getTxRules(txn *Transaction) *txRules {
+	if rules, ok := txRulesRegistry[txRulesKey{txType: txn.TxType,
+		payloadVersion: int(txn.PayloadVersion)}]; ok {
+		return rules
+	}
+	if rules, ok := txRulesRegistry[txRulesKey{txType: txn.TxType,
+		payloadVersion: anyPayloadVersion}]; ok {
+		return rules
+	}
+	return &txRules{}
+}
+
+// ForkRule describes a transaction type and payload version supported since
+// the activation height of a chain parameter.
+type ForkRule struct {
+	TxType TxType
+
+	// PayloadVersion is the payload version of the transaction type, -1 means
+	// all payload versions.
+	PayloadVersion int
+
+	// Param is the name of the chain parameter of the activation height.
+	Param string
+}
+
+// ForkRules returns the transaction types and payload versions supported
+// since activation heights, sorted by chain parameter, transaction type and
+// payload version.
+func ForkRules() []ForkRule {
+	var result []ForkRule
+	for key, rules := range txRulesRegistry {
+		params := make(map[string]struct{})
+		for _, list := range [][]txRule{rules.sanity, rules.context,
+			rules.postDoubleSpend} {
+			for _, rule := range list {
+				if rule.activationParam == "" {
+					continue
+				}
+				if _, ok := params[rule.activationParam]; ok {
+					continue
+				}
+				params[rule.activationParam] = struct{}{}
+				result = append(result, ForkRule{
+					TxType:         key.txType,
+					PayloadVersion: key.payloadVersion,
+					Param:          rule.activationParam,
+				})
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Param != result[j].Param {
+			return result[i].Param < result[j].Param
+		}
+		if result[i].TxType != result[j].TxType {
+			return result[i].TxType < result[j].TxType
+		}
+		return result[i].PayloadVersion < result[j].PayloadVersion
+	})
+	return result
+}
+
+// isStandalone returns if the common context checks should be skipped after
+// the context rules passed.
+func (r *txRules) isStandalone(txn *Transaction) bool {
+	return r.standalone != nil && r.standalone(txn)
+}
+
+// IsFeeFree returns if the transaction is valid without paying any fee, the
+// common context checks including the fee check are skipped for it.
+func IsFeeFree(txn *Transaction) bool {
+	return getTxRules(txn).isStandalone(txn)
+}
+
+// checkTxRules runs the rules in order, returns the error code and the error
+// of the first failed rule.
+func checkTxRules(rules []txRule, ctx *txRuleContext) (ErrCode, error) {
+	for _, rule := range rules {
+		if err := rule.check(ctx); err != nil {
+			log.Warn("["+rule.name+"],", err)
+			return rule.errCode, err
+		}
+	}
+	return Success, nil
+}
+
+// alwaysStandalone is used by transaction types that the common context
+// checks are never applied to.
+func alwaysStandalone(*Transaction) bool {
+	return true
+}
+
+// heightRule returns a rule rejects transactions before the height returned
+// by height, name is the chain parameter used in the error message.
+func heightRule(name string, height func(ctx *txRuleContext) uint32) txRule {
+	return txRule{
+		name:    "CheckTxHeightVersion",
+		errCode: ErrTransactionHeightVersion,
+		check: func(ctx *txRuleContext) error {
+			if ctx.blockHeight < height(ctx) {
+				return errors.New("not support before " + name)
+			}
+			return nil
+		},
+		activationParam: name,
+	}
+}
+
+// payloadRule returns a rule fails with ErrTransactionPayload.
+func payloadRule(name string, check func(ctx *txRuleContext) error) txRule {
+	return txRule{name: name, errCode: ErrTransactionPayload, check: check}
+}
+
+var (
+	crVotingStartHeightRule = heightRule("CRVotingStartHeight",
+		func(ctx *txRuleContext) uint32 {
+			return ctx.chain.chainParams.CRVotingStartHeight
+		})
+
+	registerCRByDIDHeightRule = heightRule("RegisterCRByDIDHeight",
+		func(ctx *txRuleContext) uint32 {
+			return ctx.chain.chainParams.RegisterCRByDIDHeight
+		})
+
+	producerInfoStakeHeightRule = heightRule("ProducerInfoStakeHeight",
+		func(ctx *txRuleContext) uint32 {
+			return ctx.chain.chainParams.ProducerInfoStakeHeight
+		})
+
+	crCommitteeStartHeightRule = heightRule("CRCommitteeStartHeight",
+		func(ctx *txRuleContext) uint32 {
+			return ctx.chain.chainParams.CRCommitteeStartHeight
+		})
+
+	revokeVoteHeightRule = heightRule("RevokeVoteHeight",
+		func(ctx *txRuleContext) uint32 {
+			return ctx.chain.chainParams.RevokeVoteHeight
+		})
+
+	crNicknameCommitHeightRule = heightRule("CRNicknameCommitHeight",
+		func(ctx *txRuleContext) uint32 {
+			return ctx.chain.chainParams.CRNicknameCommitHeight
+		})
+
+	nodeKeyRotationHeightRule = heightRule("NodeKeyRotationHeight",
+		func(ctx *txRuleContext) uint32 {
+			return ctx.chain.chainParams.NodeKeyRotationHeight
+		})
+
+	sideChainTxProofHeightRule = heightRule("SideChainTxProofHeight",
+		func(ctx *txRuleContext) uint32 {
+			return ctx.chain.chainParams.SideChainTxProofHeight
+		})
+
+	// crRegistrationPeriodRule rejects RegisterCR transactions outside the
+	// registration window of the current election since
+	// CRRegistrationWindowHeight.
+	crRegistrationPeriodRule = txRule{
+		name:    "CheckCRRegistrationPeriod",
+		errCode: ErrCRRegistrationClosed,
+		check: func(ctx *txRuleContext) error {
+			if ctx.blockHeight <
+				ctx.chain.chainParams.CRRegistrationWindowHeight {
+				return nil
+			}
+			committee := ctx.chain.crCommittee
+			if committee.IsInRegistrationPeriod(ctx.blockHeight) {
+				return nil
+			}
+			w := committee.GetRegistrationWindow()
+			return fmt.Errorf("CR registration is closed at height %d, "+
+				"current window [%d, %d), next window [%d, %d)",
+				ctx.blockHeight, w.StartHeight, w.EndHeight,
+				w.NextStartHeight, w.NextEndHeight)
+		},
+		activationParam: "CRRegistrationWindowHeight",
+	}
+
+	// voteProducerAndCRHeightRule rejects voting CR before
+	// CRVotingStartHeight.
+	voteProducerAndCRHeightRule = txRule{
+		name:    "CheckTxHeightVersion",
+		errCode: ErrTransactionHeightVersion,
+		check: func(ctx *txRuleContext) error {
+			if ctx.blockHeight >= ctx.chain.chainParams.CRVotingStartHeight ||
+				ctx.txn.Version < TxVersion09 {
+				return nil
+			}
+			for _, output := range ctx.txn.Outputs {
+				if output.Type != OTVote {
+					continue
+				}
+				p, _ := output.Payload.(*outputpayload.VoteOutput)
+				if p.Version >= outputpayload.VoteProducerAndCRVersion {
+					return errors.New("not support " +
+						"VoteProducerAndCRVersion before CRVotingStartHeight")
+				}
+			}
+			return nil
+		},
+		activationParam: "CRVotingStartHeight",
+	}
+)
+
+func init() {
+	registerTxRules(CoinBase, anyPayloadVersion, &txRules{
+		standalone: alwaysStandalone,
+	})
+
+	registerTxRules(TransferAsset, anyPayloadVersion, &txRules{
+		sanity: []txRule{voteProducerAndCRHeightRule},
+	})
+
+	withdrawFromSideChain := txRule{
+		name:    "CheckWithdrawFromSideChainTransaction",
+		errCode: ErrSidechainTxDuplicate,
+		check: func(ctx *txRuleContext) error {
+			return ctx.chain.checkWithdrawFromSideChainTransaction(
+				ctx.txn, ctx.references)
+		},
+	}
+	registerTxRules(WithdrawFromSideChain, anyPayloadVersion, &txRules{
+		postDoubleSpend: []txRule{withdrawFromSideChain},
+	})
+	registerTxRules(WithdrawFromSideChain,
+		int(payload.WithdrawFromSideChainProofVersion), &txRules{
+			sanity: []txRule{sideChainTxProofHeightRule},
+			postDoubleSpend: []txRule{withdrawFromSideChain,
+				payloadRule("CheckSideChainTxProofs",
+					func(ctx *txRuleContext) error {
+						return checkSideChainTxProofs(ctx.txn)
+					})},
+		})
+
+	registerTxRules(TransferCrossChainAsset, anyPayloadVersion, &txRules{
+		postDoubleSpend: []txRule{{
+			name:    "CheckTransferCrossChainAssetTransaction",
+			errCode: ErrInvalidOutput,
+			check: func(ctx *txRuleContext) error {
+				return ctx.chain.checkTransferCrossChainAssetTransaction(
+					ctx.txn, ctx.references)
+			},
+		}},
+	})
+
+	registerTxRules(SideChainPow, anyPayloadVersion, &txRules{
+		context: []txRule{{
+			name:    "CheckSideChainPowConsensus",
+			errCode: ErrSideChainPowConsensus,
+			check: func(ctx *txRuleContext) error {
+				arbitrator := DefaultLedger.Arbitrators.
+					GetOnDutyCrossChainArbitrator()
+				return CheckSideChainPowConsensus(ctx.txn, arbitrator)
+			},
+		}},
+		standalone: (*Transaction).IsNewSideChainPowTx,
+	})
+
+	registerEvidenceTxRules()
+	registerProducerTxRules()
+	registerCRTxRules()
+}
+
+// registerEvidenceTxRules registers rules of the illegal evidence, inactive
+// arbitrators and update version transactions, all of them are standalone.
+func registerEvidenceTxRules() {
+	registerTxRules(IllegalProposalEvidence, anyPayloadVersion, &txRules{
+		context: []txRule{payloadRule("CheckIllegalProposalsTransaction",
+			func(ctx *txRuleContext) error {
+				return ctx.chain.checkIllegalProposalsTransaction(ctx.txn)
+			})},
+		standalone: alwaysStandalone,
+	})
+
+	registerTxRules(IllegalVoteEvidence, anyPayloadVersion, &txRules{
+		context: []txRule{payloadRule("CheckIllegalVotesTransaction",
+			func(ctx *txRuleContext) error {
+				return ctx.chain.checkIllegalVotesTransaction(ctx.txn)
+			})},
+		standalone: alwaysStandalone,
+	})
+
+	registerTxRules(IllegalBlockEvidence, anyPayloadVersion, &txRules{
+		context: []txRule{payloadRule("CheckIllegalBlocksTransaction",
+			func(ctx *txRuleContext) error {
+				return ctx.chain.checkIllegalBlocksTransaction(ctx.txn)
+			})},
+		standalone: alwaysStandalone,
+	})
+
+	registerTxRules(IllegalSidechainEvidence, anyPayloadVersion, &txRules{
+		context: []txRule{payloadRule("CheckSidechainIllegalEvidenceTransaction",
+			func(ctx *txRuleContext) error {
+				return ctx.chain.checkSidechainIllegalEvidenceTransaction(ctx.txn)
+			})},
+		standalone: alwaysStandalone,
+	})
+
+	registerTxRules(InactiveArbitrators, anyPayloadVersion, &txRules{
+		context: []txRule{payloadRule("CheckInactiveArbitrators",
+			func(ctx *txRuleContext) error {
+				return ctx.chain.checkInactiveArbitratorsTransaction(ctx.txn)
+			})},
+		standalone: alwaysStandalone,
+	})
+
+	registerTxRules(UpdateVersion, anyPayloadVersion, &txRules{
+		context: []txRule{payloadRule("CheckUpdateVersionTransaction",
+			func(ctx *txRuleContext) error {
+				return ctx.chain.checkUpdateVersionTransaction(ctx.txn)
+			})},
+		standalone: alwaysStandalone,
+	})
+}
+
+// registerProducerTxRules registers rules of the producer transactions.
+func registerProducerTxRules() {
+	registerProducer := payloadRule("CheckRegisterProducerTransaction",
+		func(ctx *txRuleContext) error {
+			return ctx.chain.checkRegisterProducerTransaction(ctx.txn,
+				ctx.blockHeight)
+		})
+	registerTxRules(RegisterProducer, int(payload.ProducerInfoVersion), &txRules{
+		context: []txRule{registerProducer},
+	})
+	registerTxRules(RegisterProducer, anyPayloadVersion, &txRules{
+		sanity:  []txRule{producerInfoStakeHeightRule},
+		context: []txRule{registerProducer},
+	})
+
+	updateProducer := payloadRule("CheckUpdateProducerTransaction",
+		func(ctx *txRuleContext) error {
+			return ctx.chain.checkUpdateProducerTransaction(ctx.txn,
+				ctx.blockHeight)
+		})
+	registerTxRules(UpdateProducer, int(payload.ProducerInfoVersion), &txRules{
+		context: []txRule{updateProducer},
+	})
+	registerTxRules(UpdateProducer, anyPayloadVersion, &txRules{
+		sanity:  []txRule{producerInfoStakeHeightRule},
+		context: []txRule{updateProducer},
+	})
+
+	registerTxRules(CancelProducer, anyPayloadVersion, &txRules{
+		context: []txRule{payloadRule("CheckCancelProducerTransaction",
+			func(ctx *txRuleContext) error {
+				return ctx.chain.checkCancelProducerTransaction(ctx.txn)
+			})},
+	})
+
+	registerTxRules(ActivateProducer, anyPayloadVersion, &txRules{
+		context: []txRule{payloadRule("CheckActivateProducerTransaction",
+			func(ctx *txRuleContext) error {
+				return ctx.chain.checkActivateProducerTransaction(ctx.txn,
+					ctx.blockHeight)
+			})},
+		standalone: alwaysStandalone,
+	})
+
+	registerTxRules(ReturnDepositCoin, anyPayloadVersion, &txRules{
+		postDoubleSpend: []txRule{{
+			name:    "CheckReturnDepositCoinTransaction",
+			errCode: ErrReturnDepositConsensus,
+			check: func(ctx *txRuleContext) error {
+				return ctx.chain.checkReturnDepositCoinTransaction(
+					ctx.txn, ctx.references, ctx.chain.GetHeight())
+			},
+		}},
+	})
+}
+
+// registerCRTxRules registers rules of the CR transactions.
+func registerCRTxRules() {
+	registerCR := payloadRule("CheckRegisterCRTransaction",
+		func(ctx *txRuleContext) error {
+			return ctx.chain.checkRegisterCRTransaction(ctx.txn,
+				ctx.blockHeight)
+		})
+	registerTxRules(RegisterCR, int(payload.CRInfoVersion), &txRules{
+		sanity:  []txRule{crVotingStartHeightRule},
+		context: []txRule{crRegistrationPeriodRule, registerCR},
+	})
+	registerTxRules(RegisterCR, anyPayloadVersion, &txRules{
+		sanity:  []txRule{crVotingStartHeightRule, registerCRByDIDHeightRule},
+		context: []txRule{crRegistrationPeriodRule, registerCR},
+	})
+	registerTxRules(RegisterCR, int(payload.CRInfoRevealVersion), &txRules{
+		sanity: []txRule{crVotingStartHeightRule, registerCRByDIDHeightRule,
+			crNicknameCommitHeightRule},
+		context: []txRule{crRegistrationPeriodRule, registerCR},
+	})
+
+	registerTxRules(CRNicknameCommit, anyPayloadVersion, &txRules{
+		sanity: []txRule{crNicknameCommitHeightRule},
+		context: []txRule{crRegistrationPeriodRule,
+			payloadRule("CheckCRNicknameCommitTransaction",
+				func(ctx *txRuleContext) error {
+					return ctx.chain.checkCRNicknameCommitTransaction(
+						ctx.txn, ctx.blockHeight)
+				})},
+	})
+
+	updateCR := payloadRule("CheckUpdateCRTransaction",
+		func(ctx *txRuleContext) error {
+			return ctx.chain.checkUpdateCRTransaction(ctx.txn, ctx.blockHeight)
+		})
+	registerTxRules(UpdateCR, int(payload.CRInfoVersion), &txRules{
+		sanity:  []txRule{crVotingStartHeightRule},
+		context: []txRule{updateCR},
+	})
+	registerTxRules(UpdateCR, anyPayloadVersion, &txRules{
+		sanity:  []txRule{crVotingStartHeightRule, registerCRByDIDHeightRule},
+		context: []txRule{updateCR},
+	})
+
+	registerTxRules(UnregisterCR, anyPayloadVersion, &txRules{
+		sanity: []txRule{crVotingStartHeightRule},
+		context: []txRule{payloadRule("CheckUnRegisterCRTransaction",
+			func(ctx *txRuleContext) error {
+				return ctx.chain.checkUnRegisterCRTransaction(ctx.txn,
+					ctx.blockHeight)
+			})},
+	})
+
+	registerTxRules(ReturnCRDepositCoin, anyPayloadVersion, &txRules{
+		sanity: []txRule{crVotingStartHeightRule},
+		postDoubleSpend: []txRule{{
+			name:    "CheckReturnCRDepositCoinTransaction",
+			errCode: ErrReturnDepositConsensus,
+			check: func(ctx *txRuleContext) error {
+				return ctx.chain.checkReturnCRDepositCoinTransaction(
+					ctx.txn, ctx.references, ctx.chain.GetHeight(),
+					ctx.chain.crCommittee.IsInVotingPeriod)
+			},
+		}},
+	})
+
+	registerTxRules(CustomIDProposal, anyPayloadVersion, &txRules{
+		sanity: []txRule{crCommitteeStartHeightRule},
+		context: []txRule{payloadRule("CheckCustomIDProposalTransaction",
+			func(ctx *txRuleContext) error {
+				return ctx.chain.checkCustomIDProposalTransaction(ctx.txn,
+					ctx.blockHeight)
+			})},
+	})
+
+	registerTxRules(CRCRewardAddress, anyPayloadVersion, &txRules{
+		sanity: []txRule{crCommitteeStartHeightRule},
+		context: []txRule{payloadRule("CheckCRCRewardAddressTransaction",
+			func(ctx *txRuleContext) error {
+				return ctx.chain.checkCRCRewardAddressTransaction(ctx.txn,
+					ctx.blockHeight)
+			})},
+	})
+
+	registerTxRules(ProducerAppeal, anyPayloadVersion, &txRules{
+		sanity: []txRule{crCommitteeStartHeightRule},
+		context: []txRule{payloadRule("CheckProducerAppealTransaction",
+			func(ctx *txRuleContext) error {
+				return ctx.chain.checkProducerAppealTransaction(ctx.txn,
+					ctx.blockHeight)
+			})},
+	})
+
+	registerTxRules(RotateNodeKey, anyPayloadVersion, &txRules{
+		sanity: []txRule{nodeKeyRotationHeightRule},
+		context: []txRule{payloadRule("CheckRotateNodeKeyTransaction",
+			func(ctx *txRuleContext) error {
+				return ctx.chain.checkRotateNodeKeyTransaction(ctx.txn,
+					ctx.blockHeight)
+			})},
+	})
+
+	registerTxRules(RevokeVote, anyPayloadVersion, &txRules{
+		sanity: []txRule{revokeVoteHeightRule},
+		context: []txRule{payloadRule("CheckRevokeVoteTransaction",
+			func(ctx *txRuleContext) error {
+				return ctx.chain.checkRevokeVoteTransaction(ctx.txn,
+					ctx.references)
+			})},
+	})
+}
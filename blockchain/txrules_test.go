@@ -0,0 +1,112 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTxRules(t *testing.T) {
+	// rules of the exact payload version are preferred.
+	registerCR := &types.Transaction{TxType: types.RegisterCR,
+		PayloadVersion: payload.CRInfoVersion}
+	assert.Len(t, getTxRules(registerCR).sanity, 1)
+
+	// fall back to rules of any payload version.
+	registerCR.PayloadVersion = payload.CRInfoDIDVersion
+	assert.Len(t, getTxRules(registerCR).sanity, 2)
+
+	// standalone transactions.
+	coinBase := &types.Transaction{TxType: types.CoinBase}
+	assert.True(t, getTxRules(coinBase).isStandalone(coinBase))
+	sideChainPow := &types.Transaction{TxType: types.SideChainPow}
+	assert.True(t, getTxRules(sideChainPow).isStandalone(sideChainPow))
+	sideChainPow.Inputs = []*types.Input{{}}
+	assert.False(t, getTxRules(sideChainPow).isStandalone(sideChainPow))
+
+	// rules checking against spent outputs run after the double spend check.
+	for _, txType := range []types.TxType{types.WithdrawFromSideChain,
+		types.TransferCrossChainAsset, types.ReturnDepositCoin,
+		types.ReturnCRDepositCoin} {
+		rules := getTxRules(&types.Transaction{TxType: txType})
+		assert.Empty(t, rules.context)
+		assert.Len(t, rules.postDoubleSpend, 1)
+	}
+
+	// transaction types without rules.
+	record := &types.Transaction{TxType: types.Record}
+	rules := getTxRules(record)
+	assert.Empty(t, rules.sanity)
+	assert.Empty(t, rules.context)
+	assert.Empty(t, rules.postDoubleSpend)
+	assert.False(t, rules.isStandalone(record))
+}
+
+func TestCheckTxRules(t *testing.T) {
+	ctx := &txRuleContext{
+		chain: &BlockChain{chainParams: &config.Params{
+			RevokeVoteHeight: 100,
+		}},
+		txn: &types.Transaction{TxType: types.RevokeVote},
+	}
+	rules := []txRule{revokeVoteHeightRule}
+
+	ctx.blockHeight = 99
+	errCode, err := checkTxRules(rules, ctx)
+	assert.Equal(t, errors.ErrTransactionHeightVersion, errCode)
+	assert.EqualError(t, err, "not support before RevokeVoteHeight")
+
+	ctx.blockHeight = 100
+	errCode, err = checkTxRules(rules, ctx)
+	assert.Equal(t, errors.Success, errCode)
+	assert.NoError(t, err)
+
+	// rules run in order and stop at the first failed one.
+	var checked []string
+	newRule := func(name string, fail bool) txRule {
+		return payloadRule(name, func(*txRuleContext) error {
+			checked = append(checked, name)
+			if fail {
+				return errors.ErrTransactionPayload
+			}
+			return nil
+		})
+	}
+	errCode, _ = checkTxRules([]txRule{newRule("a", false),
+		newRule("b", true), newRule("c", false)}, ctx)
+	assert.Equal(t, errors.ErrTransactionPayload, errCode)
+	assert.Equal(t, []string{"a", "b"}, checked)
+}
+
+func TestForkRules(t *testing.T) {
+	rules := ForkRules()
+	assert.Contains(t, rules, ForkRule{TxType: types.RevokeVote,
+		PayloadVersion: anyPayloadVersion, Param: "RevokeVoteHeight"})
+	assert.Contains(t, rules, ForkRule{TxType: types.RegisterCR,
+		PayloadVersion: int(payload.CRInfoRevealVersion),
+		Param:          "CRNicknameCommitHeight"})
+	assert.Contains(t, rules, ForkRule{TxType: types.RotateNodeKey,
+		PayloadVersion: anyPayloadVersion, Param: "NodeKeyRotationHeight"})
+	assert.Contains(t, rules, ForkRule{TxType: types.RegisterCR,
+		PayloadVersion: int(payload.CRInfoVersion),
+		Param:          "CRRegistrationWindowHeight"})
+
+	// transaction types not height gated.
+	for _, r := range rules {
+		assert.NotEqual(t, types.CoinBase, r.TxType)
+	}
+
+	for i := 1; i < len(rules); i++ {
+		assert.True(t, rules[i-1].Param <= rules[i].Param)
+	}
+}
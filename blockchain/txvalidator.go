@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/elastos/Elastos.ELA/auxpow"
 	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/common/log"
@@ -43,8 +44,9 @@ const (
 
 // CheckTransactionSanity verifies received single transaction
 func (b *BlockChain) CheckTransactionSanity(blockHeight uint32, txn *Transaction) ErrCode {
-	if err := b.checkTxHeightVersion(txn, blockHeight); err != nil {
-		return ErrTransactionHeightVersion
+	ctx := &txRuleContext{chain: b, blockHeight: blockHeight, txn: txn}
+	if errCode, err := checkTxRules(getTxRules(txn).sanity, ctx); err != nil {
+		return errCode
 	}
 
 	if err := checkTransactionSize(txn); err != nil {
@@ -88,111 +90,31 @@ func (b *BlockChain) CheckTransactionSanity(blockHeight uint32, txn *Transaction
 // CheckTransactionContext verifies a transaction with history transaction in ledger
 func (b *BlockChain) CheckTransactionContext(blockHeight uint32,
 	txn *Transaction, references map[*Input]*Output) ErrCode {
+	return b.checkTransactionContext(blockHeight, txn, references, false)
+}
+
+// checkTransactionContext verifies a transaction with history transaction in
+// ledger, the signature check is skipped if skipScripts is true.
+func (b *BlockChain) checkTransactionContext(blockHeight uint32,
+	txn *Transaction, references map[*Input]*Output, skipScripts bool) ErrCode {
 	// check if duplicated with transaction in ledger
 	if exist := b.db.IsTxHashDuplicate(txn.Hash()); exist {
 		log.Warn("[CheckTransactionContext] duplicate transaction check failed.")
 		return ErrTransactionDuplicate
 	}
 
-	switch txn.TxType {
-	case CoinBase:
-		return Success
-
-	case IllegalProposalEvidence:
-		if err := b.checkIllegalProposalsTransaction(txn); err != nil {
-			log.Warn("[CheckIllegalProposalsTransaction],", err)
-			return ErrTransactionPayload
-		} else {
-			return Success
-		}
-
-	case IllegalVoteEvidence:
-		if err := b.checkIllegalVotesTransaction(txn); err != nil {
-			log.Warn("[CheckIllegalVotesTransaction],", err)
-			return ErrTransactionPayload
-		}
-		return Success
-
-	case IllegalBlockEvidence:
-		if err := b.checkIllegalBlocksTransaction(txn); err != nil {
-			log.Warn("[CheckIllegalBlocksTransaction],", err)
-			return ErrTransactionPayload
-		}
-		return Success
-
-	case IllegalSidechainEvidence:
-		if err := b.checkSidechainIllegalEvidenceTransaction(txn); err != nil {
-			log.Warn("[CheckSidechainIllegalEvidenceTransaction],", err)
-			return ErrTransactionPayload
-		}
-		return Success
-
-	case InactiveArbitrators:
-		if err := b.checkInactiveArbitratorsTransaction(txn); err != nil {
-			log.Warn("[CheckInactiveArbitrators],", err)
-			return ErrTransactionPayload
-		}
-		return Success
-
-	case UpdateVersion:
-		if err := b.checkUpdateVersionTransaction(txn); err != nil {
-			log.Warn("[checkUpdateVersionTransaction],", err)
-			return ErrTransactionPayload
-		}
-		return Success
-
-	case SideChainPow:
-		arbitrator := DefaultLedger.Arbitrators.GetOnDutyCrossChainArbitrator()
-		if err := CheckSideChainPowConsensus(txn, arbitrator); err != nil {
-			log.Warn("[CheckSideChainPowConsensus],", err)
-			return ErrSideChainPowConsensus
-		}
-		if txn.IsNewSideChainPowTx() {
-			return Success
-		}
-
-	case RegisterProducer:
-		if err := b.checkRegisterProducerTransaction(txn); err != nil {
-			log.Warn("[CheckRegisterProducerTransaction],", err)
-			return ErrTransactionPayload
-		}
-
-	case CancelProducer:
-		if err := b.checkCancelProducerTransaction(txn); err != nil {
-			log.Warn("[CheckCancelProducerTransaction],", err)
-			return ErrTransactionPayload
-		}
-
-	case UpdateProducer:
-		if err := b.checkUpdateProducerTransaction(txn); err != nil {
-			log.Warn("[CheckUpdateProducerTransaction],", err)
-			return ErrTransactionPayload
-		}
-
-	case ActivateProducer:
-		if err := b.checkActivateProducerTransaction(txn, blockHeight); err != nil {
-			log.Warn("[CheckActivateProducerTransaction],", err)
-			return ErrTransactionPayload
-		}
+	rules := getTxRules(txn)
+	ctx := &txRuleContext{
+		chain:       b,
+		blockHeight: blockHeight,
+		txn:         txn,
+		references:  references,
+	}
+	if errCode, err := checkTxRules(rules.context, ctx); err != nil {
+		return errCode
+	}
+	if rules.isStandalone(txn) {
 		return Success
-
-	case RegisterCR:
-		if err := b.checkRegisterCRTransaction(txn, blockHeight); err != nil {
-			log.Warn("[checkRegisterCRTransaction],", err)
-			return ErrTransactionPayload
-		}
-
-	case UpdateCR:
-		if err := b.checkUpdateCRTransaction(txn, blockHeight); err != nil {
-			log.Warn("[ checkUpdateCRTransaction],", err)
-			return ErrTransactionPayload
-		}
-
-	case UnregisterCR:
-		if err := b.checkUnRegisterCRTransaction(txn, blockHeight); err != nil {
-			log.Warn("[checkUnRegisterCRTransaction],", err)
-			return ErrTransactionPayload
-		}
 	}
 
 	// check double spent transaction
@@ -201,34 +123,8 @@ func (b *BlockChain) CheckTransactionContext(blockHeight uint32,
 		return ErrDoubleSpend
 	}
 
-	if txn.IsWithdrawFromSideChainTx() {
-		if err := b.checkWithdrawFromSideChainTransaction(txn, references); err != nil {
-			log.Warn("[CheckWithdrawFromSideChainTransaction],", err)
-			return ErrSidechainTxDuplicate
-		}
-	}
-
-	if txn.IsTransferCrossChainAssetTx() {
-		if err := b.checkTransferCrossChainAssetTransaction(txn, references); err != nil {
-			log.Warn("[CheckTransferCrossChainAssetTransaction],", err)
-			return ErrInvalidOutput
-		}
-	}
-
-	if txn.IsReturnDepositCoin() {
-		if err := b.checkReturnDepositCoinTransaction(
-			txn, references, b.GetHeight()); err != nil {
-			log.Warn("[CheckReturnDepositCoinTransaction],", err)
-			return ErrReturnDepositConsensus
-		}
-	}
-
-	if txn.IsReturnCRDepositCoinTx() {
-		if err := b.checkReturnCRDepositCoinTransaction(
-			txn, references, b.GetHeight(), b.crCommittee.IsInVotingPeriod); err != nil {
-			log.Warn("[CheckReturnDepositCoinTransaction],", err)
-			return ErrReturnDepositConsensus
-		}
+	if errCode, err := checkTxRules(rules.postDoubleSpend, ctx); err != nil {
+		return errCode
 	}
 
 	if err := checkTransactionUTXOLock(txn, references); err != nil {
@@ -256,9 +152,11 @@ func (b *BlockChain) CheckTransactionContext(blockHeight uint32,
 		return ErrInvalidOutput
 	}
 
-	if err := checkTransactionSignature(txn, references); err != nil {
-		log.Warn("[CheckTransactionSignature],", err)
-		return ErrTransactionSignature
+	if !skipScripts {
+		if err := checkTransactionSignature(txn, references); err != nil {
+			log.Warn("[CheckTransactionSignature],", err)
+			return ErrTransactionSignature
+		}
 	}
 
 	if err := b.checkInvalidUTXO(txn); err != nil {
@@ -301,6 +199,12 @@ func (b *BlockChain) checkVoteOutputs(blockHeight uint32, outputs []*Output, ref
 		if !ok {
 			return errors.New("invalid vote output payload")
 		}
+		if blockHeight >= b.chainParams.VotePolicyHeight {
+			err := b.chainParams.VotePolicy.CheckVoteOutput(payload, o.Value)
+			if err != nil {
+				return err
+			}
+		}
 		for _, content := range payload.Contents {
 			switch content.VoteType {
 			case outputpayload.Delegate:
@@ -406,7 +310,8 @@ func (b *BlockChain) checkInvalidUTXO(txn *Transaction) error {
 			return err
 		}
 		if referTxn.IsCoinBaseTx() {
-			if currentHeight-referTxn.LockTime < b.chainParams.CoinbaseMaturity {
+			if currentHeight-referTxn.LockTime < b.chainParams.
+				GetRewardPolicy(currentHeight).CoinbaseMaturity {
 				return errors.New("the utxo of coinbase is locking")
 			}
 		} else if referTxn.IsNewSideChainPowTx() {
@@ -476,6 +381,7 @@ func (b *BlockChain) checkTransactionOutput(txn *Transaction,
 		}
 
 		foundationReward := txn.Outputs[0].Value
+		policy := b.chainParams.GetRewardPolicy(blockHeight)
 		var totalReward = common.Fixed64(0)
 		if blockHeight < b.chainParams.PublicDPOSHeight {
 			for _, output := range txn.Outputs {
@@ -485,15 +391,19 @@ func (b *BlockChain) checkTransactionOutput(txn *Transaction,
 				totalReward += output.Value
 			}
 
-			if foundationReward < common.Fixed64(float64(totalReward)*0.3) {
-				return errors.New("reward to foundation in coinbase < 30%")
+			if foundationReward < common.Fixed64(float64(totalReward)*
+				policy.FoundationRewardRatio) {
+				return fmt.Errorf("reward to foundation in coinbase < %.4g%%",
+					policy.FoundationRewardRatio*100)
 			}
 		} else {
 			// check the ratio of FoundationAddress reward with miner reward
 			totalReward = txn.Outputs[0].Value + txn.Outputs[1].Value
 			if len(txn.Outputs) == 2 && foundationReward <
-				common.Fixed64(float64(totalReward)*0.3/0.65) {
-				return errors.New("reward to foundation in coinbase < 30%")
+				common.Fixed64(float64(totalReward)*policy.FoundationRewardRatio/
+					(1-policy.DPoSRewardRatio)) {
+				return fmt.Errorf("reward to foundation in coinbase < %.4g%%",
+					policy.FoundationRewardRatio*100)
 			}
 		}
 
@@ -546,6 +456,9 @@ func (b *BlockChain) checkTransactionOutput(txn *Transaction,
 			if output.Type != OTNone {
 				specialOutputCount++
 			}
+			if err := checkOutputPayloadHeight(blockHeight, output); err != nil {
+				return err
+			}
 			if err := checkOutputPayload(txn.TxType, output); err != nil {
 				return err
 			}
@@ -593,30 +506,31 @@ func checkOutputProgramHash(height uint32, programHash common.Uint168) error {
 }
 
 func checkOutputPayload(txType TxType, output *Output) error {
-	// OTVote information can only be placed in TransferAsset transaction.
-	if txType == TransferAsset {
-		switch output.Type {
-		case OTVote:
-			if contract.GetPrefixType(output.ProgramHash) !=
-				contract.PrefixStandard {
-				return errors.New("output address should be standard")
-			}
-		case OTNone:
-		case OTMapping:
-		default:
-			return errors.New("transaction type dose not match the output payload type")
-		}
-	} else {
-		switch output.Type {
-		case OTNone:
-		default:
-			return errors.New("transaction type dose not match the output payload type")
+	handler, ok := GetOutputPayloadHandler(output.Type)
+	if !ok || !handler.IsTxTypeAllowed(txType) {
+		return errors.New("transaction type dose not match the output payload type")
+	}
+	if handler.Validate != nil {
+		if err := handler.Validate(output); err != nil {
+			return err
 		}
 	}
 
 	return output.Payload.Validate()
 }
 
+func checkOutputPayloadHeight(blockHeight uint32, output *Output) error {
+	handler, ok := GetOutputPayloadHandler(output.Type)
+	if !ok {
+		return errors.New("invalid transaction output type")
+	}
+	if blockHeight < handler.StartHeight {
+		return fmt.Errorf("output payload type %s is not supported before"+
+			" height %d", output.Type, handler.StartHeight)
+	}
+	return nil
+}
+
 func checkTransactionUTXOLock(txn *Transaction, references map[*Input]*Output) error {
 	if txn.IsCoinBaseTx() {
 		return nil
@@ -846,6 +760,12 @@ func checkTransactionPayload(txn *Transaction) error {
 	case *payload.InactiveArbitrators:
 	case *payload.CRInfo:
 	case *payload.UnregisterCR:
+	case *payload.CustomIDProposal:
+	case *payload.RevokeVote:
+	case *payload.CRCRewardAddress:
+	case *payload.ProducerAppeal:
+	case *payload.CRNicknameCommit:
+	case *payload.RotateNodeKey:
 
 	default:
 		return errors.New("[txValidator],invalidate transaction payload type.")
@@ -868,40 +788,6 @@ func checkDuplicateSidechainTx(txn *Transaction) error {
 	return nil
 }
 
-// validate the type of transaction is allowed or not at current height.
-func (b *BlockChain) checkTxHeightVersion(txn *Transaction, blockHeight uint32) error {
-	switch txn.TxType {
-	case RegisterCR, UpdateCR:
-		if blockHeight < b.chainParams.CRVotingStartHeight ||
-			(blockHeight < b.chainParams.RegisterCRByDIDHeight &&
-				txn.PayloadVersion != payload.CRInfoVersion) {
-			return errors.New("not support before CRVotingStartHeight")
-		}
-	case UnregisterCR, ReturnCRDepositCoin:
-		if blockHeight < b.chainParams.CRVotingStartHeight {
-			return errors.New("not support before CRVotingStartHeight")
-		}
-	case TransferAsset:
-		if blockHeight >= b.chainParams.CRVotingStartHeight {
-			return nil
-		}
-		if txn.Version >= TxVersion09 {
-			for _, output := range txn.Outputs {
-				if output.Type != OTVote {
-					continue
-				}
-				p, _ := output.Payload.(*outputpayload.VoteOutput)
-				if p.Version >= outputpayload.VoteProducerAndCRVersion {
-					return errors.New("not support " +
-						"VoteProducerAndCRVersion before CRVotingStartHeight")
-				}
-			}
-		}
-	}
-
-	return nil
-}
-
 func CheckSideChainPowConsensus(txn *Transaction, arbitrator []byte) error {
 	payloadSideChainPow, ok := txn.Payload.(*payload.SideChainPow)
 	if !ok {
@@ -958,6 +844,57 @@ func (b *BlockChain) checkWithdrawFromSideChainTransaction(txn *Transaction, ref
 	return nil
 }
 
+// checkSideChainTxProofs checks the side chain transactions of the withdraw
+// transaction are included in the side chain blocks recorded on the main
+// chain by SideChainPow transactions of the same side chain.
+func checkSideChainTxProofs(txn *Transaction) error {
+	witPayload, ok := txn.Payload.(*payload.WithdrawFromSideChain)
+	if !ok {
+		return errors.New("invalid withdraw from side chain payload type")
+	}
+	if len(witPayload.Proofs) != len(witPayload.SideChainTransactionHashes) {
+		return errors.New("side chain transaction proofs count mismatch")
+	}
+	genesisProgramHash, err := common.Uint168FromAddress(
+		witPayload.GenesisBlockAddress)
+	if err != nil {
+		return errors.New("invalid genesis block address")
+	}
+
+	for i, hash := range witPayload.SideChainTransactionHashes {
+		proof := &witPayload.Proofs[i]
+		root, err := proof.MerkleRoot()
+		if err != nil {
+			return err
+		}
+		if auxpow.GetMerkleRoot(hash, proof.MerkleBranch,
+			int(proof.Index)) != root {
+			return fmt.Errorf("invalid merkle proof of side chain "+
+				"transaction %s", hash)
+		}
+
+		powTxn, _, err := DefaultLedger.Store.GetTransaction(
+			proof.SideChainPowTxHash)
+		if err != nil || !powTxn.IsSideChainPowTx() {
+			return fmt.Errorf("side chain pow transaction %s not found",
+				proof.SideChainPowTxHash)
+		}
+		pow := powTxn.Payload.(*payload.SideChainPow)
+		if pow.SideBlockHash != proof.SideBlockHash() {
+			return fmt.Errorf("side block of transaction %s is not "+
+				"recorded by side chain pow transaction", hash)
+		}
+		code := contract.CreateCrossChainRedeemScript(pow.SideGenesisHash)
+		if !common.ToProgramHash(byte(contract.PrefixCrossChain), code).
+			IsEqual(*genesisProgramHash) {
+			return fmt.Errorf("side block of transaction %s is not of "+
+				"the side chain %s", hash, witPayload.GenesisBlockAddress)
+		}
+	}
+
+	return nil
+}
+
 func (b *BlockChain) checkCrossChainArbitrators(publicKeys [][]byte) error {
 	arbiters := DefaultLedger.Arbitrators.GetCrossChainArbiters()
 	if len(arbiters) != len(publicKeys) {
@@ -1055,18 +992,16 @@ func (b *BlockChain) checkTransferCrossChainAssetTransaction(txn *Transaction, r
 	return nil
 }
 
-func (b *BlockChain) checkRegisterProducerTransaction(txn *Transaction) error {
+func (b *BlockChain) checkRegisterProducerTransaction(txn *Transaction,
+	blockHeight uint32) error {
 	info, ok := txn.Payload.(*payload.ProducerInfo)
 	if !ok {
 		return errors.New("invalid payload")
 	}
 
-	if err := checkStringField(info.NickName, "NickName", false); err != nil {
-		return err
-	}
-
-	// check url
-	if err := checkStringField(info.Url, "Url", true); err != nil {
+	// check nick name and url
+	if err := b.checkNameFields(info.NickName, info.Url,
+		blockHeight); err != nil {
 		return err
 	}
 
@@ -1093,7 +1028,7 @@ func (b *BlockChain) checkRegisterProducerTransaction(txn *Transaction) error {
 	}
 
 	// check duplication of nickname.
-	if b.state.NicknameExists(info.NickName) {
+	if b.producerNicknameExists(info.NickName, "", blockHeight) {
 		return fmt.Errorf("nick name %s already inuse", info.NickName)
 	}
 
@@ -1111,7 +1046,8 @@ func (b *BlockChain) checkRegisterProducerTransaction(txn *Transaction) error {
 			common.BytesToHexString(info.NodePublicKey))
 	}
 
-	if err := b.additionalProducerInfoCheck(info); err != nil {
+	if err := b.additionalProducerInfoCheck(info,
+		txn.PayloadVersion); err != nil {
 		return err
 	}
 
@@ -1121,7 +1057,7 @@ func (b *BlockChain) checkRegisterProducerTransaction(txn *Transaction) error {
 		return errors.New("invalid owner public key in payload")
 	}
 	signedBuf := new(bytes.Buffer)
-	err = info.SerializeUnsigned(signedBuf, payload.ProducerInfoVersion)
+	err = info.SerializeUnsigned(signedBuf, txn.PayloadVersion)
 	if err != nil {
 		return err
 	}
@@ -1278,23 +1214,21 @@ func (b *BlockChain) checkActivateProducerTransaction(txn *Transaction,
 	return nil
 }
 
-func (b *BlockChain) checkUpdateProducerTransaction(txn *Transaction) error {
+func (b *BlockChain) checkUpdateProducerTransaction(txn *Transaction,
+	blockHeight uint32) error {
 	info, ok := txn.Payload.(*payload.ProducerInfo)
 	if !ok {
 		return errors.New("invalid payload")
 	}
 
-	// check nick name
-	if err := checkStringField(info.NickName, "NickName", false); err != nil {
-		return err
-	}
-
-	// check url
-	if err := checkStringField(info.Url, "Url", true); err != nil {
+	// check nick name and url
+	if err := b.checkNameFields(info.NickName, info.Url,
+		blockHeight); err != nil {
 		return err
 	}
 
-	if err := b.additionalProducerInfoCheck(info); err != nil {
+	if err := b.additionalProducerInfoCheck(info,
+		txn.PayloadVersion); err != nil {
 		return err
 	}
 
@@ -1304,7 +1238,7 @@ func (b *BlockChain) checkUpdateProducerTransaction(txn *Transaction) error {
 		return errors.New("invalid owner public key in payload")
 	}
 	signedBuf := new(bytes.Buffer)
-	err = info.SerializeUnsigned(signedBuf, payload.ProducerInfoVersion)
+	err = info.SerializeUnsigned(signedBuf, txn.PayloadVersion)
 	if err != nil {
 		return err
 	}
@@ -1319,8 +1253,8 @@ func (b *BlockChain) checkUpdateProducerTransaction(txn *Transaction) error {
 	}
 
 	// check nickname usage.
-	if producer.Info().NickName != info.NickName &&
-		b.state.NicknameExists(info.NickName) {
+	if b.producerNicknameExists(info.NickName, producer.Info().NickName,
+		blockHeight) {
 		return fmt.Errorf("nick name %s already exist", info.NickName)
 	}
 
@@ -1367,12 +1301,9 @@ func (b *BlockChain) checkRegisterCRTransaction(txn *Transaction,
 		return errors.New("invalid payload")
 	}
 
-	if err := checkStringField(info.NickName, "NickName", false); err != nil {
-		return err
-	}
-
-	// check url
-	if err := checkStringField(info.Url, "Url", true); err != nil {
+	// check nick name and url
+	if err := b.checkNameFields(info.NickName, info.Url,
+		blockHeight); err != nil {
 		return err
 	}
 
@@ -1380,10 +1311,17 @@ func (b *BlockChain) checkRegisterCRTransaction(txn *Transaction,
 		return errors.New("should create tx during voting period")
 	}
 
-	if b.crCommittee.GetState().ExistCandidateByNickname(info.NickName) {
+	if b.crNicknameExists(info.NickName, "", blockHeight) {
 		return fmt.Errorf("nick name %s already inuse", info.NickName)
 	}
 
+	if blockHeight >= b.chainParams.CRNicknameCommitHeight {
+		if err := b.checkCRNicknameReveal(info,
+			txn.PayloadVersion); err != nil {
+			return err
+		}
+	}
+
 	cr := b.crCommittee.GetState().GetCandidate(info.Code)
 	if cr != nil {
 		return fmt.Errorf("cid %s already exist", info.CID)
@@ -1415,7 +1353,7 @@ func (b *BlockChain) checkRegisterCRTransaction(txn *Transaction,
 	}
 
 	if blockHeight >= b.chainParams.RegisterCRByDIDHeight &&
-		txn.PayloadVersion == payload.CRInfoDIDVersion {
+		txn.PayloadVersion >= payload.CRInfoDIDVersion {
 		// get DID program hash
 		programHash = getDIDFromCode(info.Code)
 
@@ -1463,13 +1401,13 @@ func (b *BlockChain) checkUpdateCRTransaction(txn *Transaction,
 	if !ok {
 		return errors.New("invalid payload")
 	}
-
-	if err := checkStringField(info.NickName, "NickName", false); err != nil {
-		return err
+	if txn.PayloadVersion >= payload.CRInfoRevealVersion {
+		return errors.New("invalid payload version")
 	}
 
-	// check url
-	if err := checkStringField(info.Url, "Url", true); err != nil {
+	// check nick name and url
+	if err := b.checkNameFields(info.NickName, info.Url,
+		blockHeight); err != nil {
 		return err
 	}
 
@@ -1515,8 +1453,7 @@ func (b *BlockChain) checkUpdateCRTransaction(txn *Transaction,
 	}
 
 	// check nickname usage.
-	if cr.Info().NickName != info.NickName &&
-		b.crCommittee.GetState().ExistCandidateByNickname(info.NickName) {
+	if b.crNicknameExists(info.NickName, cr.Info().NickName, blockHeight) {
 		return fmt.Errorf("nick name %s already exist", info.NickName)
 	}
 
@@ -1550,6 +1487,383 @@ func (b *BlockChain) checkUnRegisterCRTransaction(txn *Transaction,
 	return checkCRTransactionSignature(info.Signature, cr.Info().Code, signedBuf.Bytes())
 }
 
+func (b *BlockChain) checkCustomIDProposalTransaction(txn *Transaction,
+	blockHeight uint32) error {
+	proposal, ok := txn.Payload.(*payload.CustomIDProposal)
+	if !ok {
+		return errors.New("invalid payload")
+	}
+
+	crState := b.crCommittee.GetState()
+	switch proposal.ProposalType {
+	case payload.ReserveCustomID, payload.BanCustomID:
+		if len(proposal.CustomIDs) == 0 {
+			return errors.New("custom IDs should not be empty")
+		}
+		ids := make(map[string]struct{})
+		for _, id := range proposal.CustomIDs {
+			if len(id) == 0 || len(id) > payload.MaxCustomIDLength {
+				return fmt.Errorf("invalid custom ID length: %d", len(id))
+			}
+			if _, ok := ids[id]; ok {
+				return fmt.Errorf("duplicated custom ID: %s", id)
+			}
+			ids[id] = struct{}{}
+
+			if proposal.ProposalType == payload.ReserveCustomID &&
+				crState.IsCustomIDReserved(id) {
+				return fmt.Errorf("custom ID %s already reserved", id)
+			}
+			if proposal.ProposalType == payload.BanCustomID &&
+				crState.IsCustomIDBanned(id) {
+				return fmt.Errorf("custom ID %s already banned", id)
+			}
+		}
+	case payload.ChangeCustomIDFee:
+		if len(proposal.CustomIDs) != 0 {
+			return errors.New("custom IDs should be empty")
+		}
+		if proposal.FeeRate <= 0 {
+			return errors.New("invalid custom ID fee rate")
+		}
+		if proposal.EffectiveHeight < blockHeight {
+			return errors.New("effective height should not be lower " +
+				"than current height")
+		}
+	default:
+		return errors.New("invalid custom ID proposal type")
+	}
+
+	signedBuf := new(bytes.Buffer)
+	err := proposal.SerializeUnsigned(signedBuf, payload.CustomIDProposalVersion)
+	if err != nil {
+		return err
+	}
+	return b.checkCRMemberSigns(proposal.Signs, signedBuf.Bytes())
+}
+
+// checkCRMemberSigns checks that more than two-thirds of current CR members
+// have signed the data.
+func (b *BlockChain) checkCRMemberSigns(signs []payload.CRMemberSign,
+	data []byte) error {
+	members := b.crCommittee.GetAllMembers()
+	if len(members) == 0 {
+		return errors.New("CR committee has no member")
+	}
+	codes := make(map[common.Uint168][]byte, len(members))
+	for _, m := range members {
+		codes[m.Info.CID] = m.Info.Code
+	}
+
+	signed := make(map[common.Uint168]struct{})
+	for _, sign := range signs {
+		code, ok := codes[sign.CID]
+		if !ok {
+			return fmt.Errorf("signer %s is not CR member",
+				sign.CID.String())
+		}
+		if _, ok := signed[sign.CID]; ok {
+			return fmt.Errorf("duplicated sign from %s", sign.CID.String())
+		}
+		if err := checkCRTransactionSignature(sign.Signature, code,
+			data); err != nil {
+			return err
+		}
+		signed[sign.CID] = struct{}{}
+	}
+
+	if len(signed)*3 <= len(members)*2 {
+		return errors.New("insufficient CR member signs count")
+	}
+	return nil
+}
+
+// checkCRCRewardAddressTransaction checks that the reward address is signed
+// by the CRC arbiter and approved by the CR committee, and the signed height
+// is higher than the last reward address change of the arbiter.
+func (b *BlockChain) checkCRCRewardAddressTransaction(txn *Transaction,
+	blockHeight uint32) error {
+	p, ok := txn.Payload.(*payload.CRCRewardAddress)
+	if !ok {
+		return errors.New("invalid payload")
+	}
+
+	if !DefaultLedger.Arbitrators.IsCRCArbitrator(p.NodePublicKey) {
+		return errors.New("node public key is not CRC arbiter")
+	}
+
+	prefix := contract.GetPrefixType(p.RewardProgramHash)
+	if prefix != contract.PrefixStandard && prefix != contract.PrefixMultiSig {
+		return errors.New("invalid reward address")
+	}
+
+	programHash, err := contract.PublicKeyToStandardProgramHash(
+		p.NodePublicKey)
+	if err != nil {
+		return err
+	}
+	rewardHash, ok := b.state.GetCRCRewardAddress(*programHash)
+	if ok && rewardHash.IsEqual(p.RewardProgramHash) {
+		return errors.New("reward address not changed")
+	}
+
+	if p.Height > blockHeight {
+		return errors.New("height should not be higher than block height")
+	}
+	height, ok := b.state.GetCRCRewardAddressHeight(*programHash)
+	if ok && p.Height <= height {
+		return fmt.Errorf("height should be higher than the last reward "+
+			"address change at %d", height)
+	}
+
+	// check signature
+	publicKey, err := DecodePoint(p.NodePublicKey)
+	if err != nil {
+		return errors.New("invalid public key in payload")
+	}
+	signedBuf := new(bytes.Buffer)
+	err = p.SerializeUnsigned(signedBuf, payload.CRCRewardAddressVersion)
+	if err != nil {
+		return err
+	}
+	err = Verify(*publicKey, signedBuf.Bytes(), p.Signature)
+	if err != nil {
+		return errors.New("invalid signature in payload")
+	}
+
+	return b.checkCRMemberSigns(p.Signs, signedBuf.Bytes())
+}
+
+// checkProducerAppealTransaction checks that the appeal is signed by the owner
+// of a penalized producer and approved by the CR committee.
+func (b *BlockChain) checkProducerAppealTransaction(txn *Transaction,
+	blockHeight uint32) error {
+	p, ok := txn.Payload.(*payload.ProducerAppeal)
+	if !ok {
+		return errors.New("invalid payload")
+	}
+
+	if len(p.Reason) > payload.MaxProducerAppealReasonLength {
+		return fmt.Errorf("reason length %d exceeds the max length %d",
+			len(p.Reason), payload.MaxProducerAppealReasonLength)
+	}
+	if p.ActivationHeight <= blockHeight {
+		return errors.New("activation height should be higher than " +
+			"block height")
+	}
+
+	producer := b.state.GetProducer(p.OwnerPublicKey)
+	if producer == nil || !bytes.Equal(producer.OwnerPublicKey(),
+		p.OwnerPublicKey) {
+		return errors.New("producer not found")
+	}
+	if producer.State() == state.Returned {
+		return errors.New("producer deposit has been returned")
+	}
+	if producer.Penalty() == 0 && producer.State() != state.Inactive &&
+		producer.State() != state.Illegal {
+		return errors.New("producer is not penalized")
+	}
+	if _, ok := b.state.GetProducerAppeal(p.OwnerPublicKey); ok {
+		return errors.New("producer already has an appeal to be activated")
+	}
+
+	// check signature
+	publicKey, err := DecodePoint(p.OwnerPublicKey)
+	if err != nil {
+		return errors.New("invalid public key in payload")
+	}
+	signedBuf := new(bytes.Buffer)
+	err = p.SerializeUnsigned(signedBuf, payload.ProducerAppealVersion)
+	if err != nil {
+		return err
+	}
+	err = Verify(*publicKey, signedBuf.Bytes(), p.Signature)
+	if err != nil {
+		return errors.New("invalid signature in payload")
+	}
+
+	return b.checkCRMemberSigns(p.Signs, signedBuf.Bytes())
+}
+
+// checkRotateNodeKeyTransaction checks that the node public key rotation is
+// signed by the owner and the new node key of a producer, the producer is not
+// in cooldown, and the new node key is not used by other producers, CRC
+// arbiters or CR candidates.
+func (b *BlockChain) checkRotateNodeKeyTransaction(txn *Transaction,
+	blockHeight uint32) error {
+	p, ok := txn.Payload.(*payload.RotateNodeKey)
+	if !ok {
+		return errors.New("invalid payload")
+	}
+
+	producer := b.state.GetProducer(p.OwnerPublicKey)
+	if producer == nil || !bytes.Equal(producer.OwnerPublicKey(),
+		p.OwnerPublicKey) {
+		return errors.New("producer not found")
+	}
+	if producer.State() == state.Canceled ||
+		producer.State() == state.Returned {
+		return errors.New("canceled producer can not rotate node key")
+	}
+	if !bytes.Equal(producer.NodePublicKey(), p.NodePublicKey) {
+		return errors.New("node public key is not the current one")
+	}
+	if bytes.Equal(p.NodePublicKey, p.NewNodePublicKey) {
+		return errors.New("new node public key is the same as the current one")
+	}
+
+	last, rotated := b.state.GetLastNodeKeyRotation(p.OwnerPublicKey)
+	if rotated &&
+		blockHeight < last+b.chainParams.NodeKeyRotationCooldown {
+		return fmt.Errorf("node key rotated at height %d, can not rotate "+
+			"again before height %d", last,
+			last+b.chainParams.NodeKeyRotationCooldown)
+	}
+	if p.LastRotationHeight != last {
+		return fmt.Errorf("last rotation height %d does not match the "+
+			"last node key rotation at %d", p.LastRotationHeight, last)
+	}
+
+	// check new node public key uniqueness
+	if DefaultLedger.Arbitrators.IsCRCArbitrator(p.NewNodePublicKey) {
+		return errors.New("node public key can't equal with CRC")
+	}
+	nodeCode := append([]byte{byte(COMPRESSEDLEN)}, p.NewNodePublicKey...)
+	nodeCode = append(nodeCode, vm.CHECKSIG)
+	if b.crCommittee.ExistCR(nodeCode) {
+		return fmt.Errorf("node public key %s already exist in cr list",
+			common.BytesToHexString(p.NewNodePublicKey))
+	}
+	if b.state.ProducerExists(p.NewNodePublicKey) {
+		return fmt.Errorf("producer %s already exist",
+			hex.EncodeToString(p.NewNodePublicKey))
+	}
+
+	// check signatures
+	ownerPublicKey, err := DecodePoint(p.OwnerPublicKey)
+	if err != nil {
+		return errors.New("invalid owner public key in payload")
+	}
+	newNodePublicKey, err := DecodePoint(p.NewNodePublicKey)
+	if err != nil {
+		return errors.New("invalid new node public key in payload")
+	}
+	signedBuf := new(bytes.Buffer)
+	err = p.SerializeUnsigned(signedBuf, payload.RotateNodeKeyVersion)
+	if err != nil {
+		return err
+	}
+	if err := Verify(*newNodePublicKey, signedBuf.Bytes(),
+		p.NewNodeSignature); err != nil {
+		return errors.New("invalid new node signature in payload")
+	}
+	if err := Verify(*ownerPublicKey, signedBuf.Bytes(),
+		p.Signature); err != nil {
+		return errors.New("invalid signature in payload")
+	}
+
+	return nil
+}
+
+// checkCRNicknameReveal checks that the nickname of RegisterCR has been
+// committed by a CRNicknameCommit transaction which is not expired.
+func (b *BlockChain) checkCRNicknameReveal(info *payload.CRInfo,
+	payloadVersion byte) error {
+	if payloadVersion < payload.CRInfoRevealVersion {
+		return errors.New("nickname should be committed and revealed " +
+			"after CRNicknameCommitHeight")
+	}
+	if len(info.Salt) < payload.MinCRNicknameSaltLength {
+		return errors.New("salt of nickname commitment is too short")
+	}
+
+	commitment := payload.CRNicknameCommitment(info.NickName, info.Salt,
+		info.CID)
+	if _, ok := b.crCommittee.GetState().GetNicknameCommitExpiry(
+		commitment); !ok {
+		return fmt.Errorf("commitment of nick name %s not found or "+
+			"expired", info.NickName)
+	}
+	return nil
+}
+
+// checkCRNicknameCommitTransaction checks that the CR nickname commitment is
+// packed in voting period and has not been committed.
+func (b *BlockChain) checkCRNicknameCommitTransaction(txn *Transaction,
+	blockHeight uint32) error {
+	p, ok := txn.Payload.(*payload.CRNicknameCommit)
+	if !ok {
+		return errors.New("invalid payload")
+	}
+
+	if !b.crCommittee.IsInVotingPeriod(blockHeight) {
+		return errors.New("should create tx during voting period")
+	}
+
+	if _, ok := b.crCommittee.GetState().GetNicknameCommitExpiry(
+		p.Commitment); ok {
+		return fmt.Errorf("commitment %s already exist", p.Commitment)
+	}
+	return nil
+}
+
+// checkRevokeVoteTransaction checks that the revoked votes are not spent or
+// revoked yet, and the transaction is signed by owners of the vote outputs.
+func (b *BlockChain) checkRevokeVoteTransaction(txn *Transaction,
+	references map[*Input]*Output) error {
+	p, ok := txn.Payload.(*payload.RevokeVote)
+	if !ok {
+		return errors.New("invalid payload")
+	}
+	if len(p.Votes) == 0 {
+		return errors.New("revoked votes should not be empty")
+	}
+	if len(p.Votes) > payload.MaxRevokedVotesCount {
+		return errors.New("too many revoked votes")
+	}
+
+	// A vote output is owned by the signers if its address is spent by an
+	// input and the code of the address is one of the programs, the
+	// programs are verified against the inputs by the signature check
+	// following the context rules.
+	codes := make(map[common.Uint160]struct{})
+	for _, program := range txn.Programs {
+		codes[*common.ToCodeHash(program.Code)] = struct{}{}
+	}
+	owners := make(map[common.Uint168]struct{})
+	for _, output := range references {
+		if _, ok := codes[output.ProgramHash.ToCodeHash()]; ok {
+			owners[output.ProgramHash] = struct{}{}
+		}
+	}
+
+	crState := b.crCommittee.GetState()
+	revoked := make(map[string]struct{})
+	for _, v := range p.Votes {
+		referKey := NewOutPoint(v.TxID, v.Index).ReferKey()
+		if _, ok := revoked[referKey]; ok {
+			return fmt.Errorf("duplicated revoked vote %s:%d",
+				v.TxID.String(), v.Index)
+		}
+		revoked[referKey] = struct{}{}
+
+		output := b.state.GetVoteOutput(referKey)
+		if output == nil {
+			output = crState.GetVoteOutput(referKey)
+		}
+		if output == nil {
+			return fmt.Errorf("vote %s:%d not exist or already revoked",
+				v.TxID.String(), v.Index)
+		}
+		if _, ok := owners[output.ProgramHash]; !ok {
+			return fmt.Errorf("vote %s:%d is not owned by the signers",
+				v.TxID.String(), v.Index)
+		}
+	}
+	return nil
+}
+
 func getParameterBySignature(signature []byte) []byte {
 	buf := new(bytes.Buffer)
 	buf.WriteByte(byte(len(signature)))
@@ -1598,7 +1912,14 @@ func (b *BlockChain) crInfoSanityCheck(info *payload.CRInfo, payloadVersion byte
 }
 
 func (b *BlockChain) additionalProducerInfoCheck(
-	info *payload.ProducerInfo) error {
+	info *payload.ProducerInfo, payloadVersion byte) error {
+	if payloadVersion >= payload.ProducerInfoStakeVersion {
+		if !info.StakeAddress.IsEqual(common.Uint168{}) &&
+			contract.GetPrefixType(info.StakeAddress) != contract.PrefixStandard {
+			return errors.New("stake address should be a standard address")
+		}
+	}
+
 	if b.GetHeight() >= b.chainParams.PublicDPOSHeight {
 		_, err := DecodePoint(info.NodePublicKey)
 		if err != nil {
@@ -2155,6 +2476,65 @@ func checkStringField(rawStr string, field string, allowEmpty bool) error {
 	return nil
 }
 
+// checkNameFields checks the nickname and url of producers and CR candidates,
+// NamePolicy will be applied since NamePolicyHeight.
+func (b *BlockChain) checkNameFields(nickname string, url string,
+	blockHeight uint32) error {
+	if blockHeight < b.chainParams.NamePolicyHeight {
+		if err := checkStringField(nickname, "NickName", false); err != nil {
+			return err
+		}
+		return checkStringField(url, "Url", true)
+	}
+
+	policy := &b.chainParams.NamePolicy
+	if err := policy.CheckNickname(nickname); err != nil {
+		return err
+	}
+	return policy.CheckURL(url)
+}
+
+// producerNicknameExists returns if the nickname is used by other producers,
+// origin is the current nickname of the producer. Nicknames will be compared
+// after normalized since NamePolicyHeight, and after folded since
+// NicknameFoldHeight.
+func (b *BlockChain) producerNicknameExists(nickname string, origin string,
+	blockHeight uint32) bool {
+	if blockHeight < b.chainParams.NamePolicyHeight {
+		return nickname != origin && b.state.NicknameExists(nickname)
+	}
+
+	policy := &b.chainParams.NamePolicy
+	if blockHeight < b.chainParams.NicknameFoldHeight {
+		return policy.NormalizeNickname(nickname) !=
+			policy.NormalizeNickname(origin) &&
+			b.state.NormalizedNicknameExists(nickname)
+	}
+	return config.FoldNickname(nickname) != config.FoldNickname(origin) &&
+		b.state.FoldedNicknameExists(nickname)
+}
+
+// crNicknameExists returns if the nickname is used by other CR candidates,
+// origin is the current nickname of the candidate. Nicknames will be compared
+// after normalized since NamePolicyHeight, and after folded since
+// NicknameFoldHeight.
+func (b *BlockChain) crNicknameExists(nickname string, origin string,
+	blockHeight uint32) bool {
+	crState := b.crCommittee.GetState()
+	if blockHeight < b.chainParams.NamePolicyHeight {
+		return nickname != origin && crState.ExistCandidateByNickname(nickname)
+	}
+
+	policy := &b.chainParams.NamePolicy
+	if blockHeight < b.chainParams.NicknameFoldHeight {
+		return policy.NormalizeNickname(nickname) !=
+			policy.NormalizeNickname(origin) &&
+			crState.ExistCandidateByNormalizedNickname(nickname)
+	}
+	return config.FoldNickname(nickname) != config.FoldNickname(origin) &&
+		crState.ExistCandidateByFoldedNickname(nickname)
+}
+
 func validateProposalEvidence(evidence *payload.ProposalEvidence) error {
 
 	header := &Header{}
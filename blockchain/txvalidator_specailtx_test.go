@@ -72,7 +72,7 @@ func (s *txValidatorSpecialTxTestSuite) SetupSuite() {
 	}
 
 	chainStore, err := NewChainStore(filepath.Join(test.DataPath, "special"),
-		config.DefaultParams.GenesisBlock)
+		LevelDBEngine, config.DefaultParams.GenesisBlock)
 	if err != nil {
 		s.Error(err)
 	}
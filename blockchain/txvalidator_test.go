@@ -50,7 +50,8 @@ func (s *txValidatorTestSuite) SetupSuite() {
 	s.foundationAddress = params.Foundation
 
 	chainStore, err := NewChainStore(
-		filepath.Join(test.DataPath, "txvalidator"), params.GenesisBlock)
+		filepath.Join(test.DataPath, "txvalidator"), LevelDBEngine,
+		params.GenesisBlock)
 	if err != nil {
 		s.Error(err)
 	}
@@ -90,40 +91,48 @@ func (s *txValidatorTestSuite) TestCheckTxHeightVersion() {
 	blockHeight1 := s.Chain.chainParams.CRVotingStartHeight - 1
 	blockHeight2 := s.Chain.chainParams.CRVotingStartHeight
 	blockHeight3 := s.Chain.chainParams.RegisterCRByDIDHeight
+	checkTxHeightVersion := func(txn *types.Transaction, height uint32) error {
+		_, err := checkTxRules(getTxRules(txn).sanity, &txRuleContext{
+			chain:       s.Chain,
+			blockHeight: height,
+			txn:         txn,
+		})
+		return err
+	}
 
 	// check height version of registerCR transaction.
 	registerCR := &types.Transaction{TxType: types.RegisterCR}
-	err := s.Chain.checkTxHeightVersion(registerCR, blockHeight1)
+	err := checkTxHeightVersion(registerCR, blockHeight1)
 	s.EqualError(err, "not support before CRVotingStartHeight")
-	err = s.Chain.checkTxHeightVersion(registerCR, blockHeight2)
+	err = checkTxHeightVersion(registerCR, blockHeight2)
 	s.NoError(err)
 
 	registerCR2 := &types.Transaction{TxType: types.RegisterCR,
 		PayloadVersion: payload.CRInfoDIDVersion}
-	err = s.Chain.checkTxHeightVersion(registerCR2, blockHeight1)
+	err = checkTxHeightVersion(registerCR2, blockHeight1)
 	s.EqualError(err, "not support before CRVotingStartHeight")
-	err = s.Chain.checkTxHeightVersion(registerCR2, blockHeight3)
+	err = checkTxHeightVersion(registerCR2, blockHeight3)
 	s.NoError(err)
 
 	// check height version of updateCR transaction.
 	updateCR := &types.Transaction{TxType: types.UpdateCR}
-	err = s.Chain.checkTxHeightVersion(updateCR, blockHeight1)
+	err = checkTxHeightVersion(updateCR, blockHeight1)
 	s.EqualError(err, "not support before CRVotingStartHeight")
-	err = s.Chain.checkTxHeightVersion(updateCR, blockHeight2)
+	err = checkTxHeightVersion(updateCR, blockHeight2)
 	s.NoError(err)
 
 	// check height version of unregister transaction.
 	unregisterCR := &types.Transaction{TxType: types.UnregisterCR}
-	err = s.Chain.checkTxHeightVersion(unregisterCR, blockHeight1)
+	err = checkTxHeightVersion(unregisterCR, blockHeight1)
 	s.EqualError(err, "not support before CRVotingStartHeight")
-	err = s.Chain.checkTxHeightVersion(unregisterCR, blockHeight2)
+	err = checkTxHeightVersion(unregisterCR, blockHeight2)
 	s.NoError(err)
 
 	// check height version of unregister transaction.
 	returnCoin := &types.Transaction{TxType: types.ReturnCRDepositCoin}
-	err = s.Chain.checkTxHeightVersion(returnCoin, blockHeight1)
+	err = checkTxHeightVersion(returnCoin, blockHeight1)
 	s.EqualError(err, "not support before CRVotingStartHeight")
-	err = s.Chain.checkTxHeightVersion(returnCoin, blockHeight2)
+	err = checkTxHeightVersion(returnCoin, blockHeight2)
 	s.NoError(err)
 
 	// check height version of vote CR.
@@ -143,10 +152,10 @@ func (s *txValidatorTestSuite) TestCheckTxHeightVersion() {
 			},
 		},
 	}
-	err = s.Chain.checkTxHeightVersion(voteCR, blockHeight1)
+	err = checkTxHeightVersion(voteCR, blockHeight1)
 	s.EqualError(err, "not support VoteProducerAndCRVersion "+
 		"before CRVotingStartHeight")
-	err = s.Chain.checkTxHeightVersion(voteCR, blockHeight2)
+	err = checkTxHeightVersion(voteCR, blockHeight2)
 	s.NoError(err)
 }
 
@@ -564,6 +573,87 @@ func (s *txValidatorTestSuite) TestCheckSideChainPowConsensus() {
 	s.Error(CheckSideChainPowConsensus(txn, arbitrator2), "TestCheckSideChainPowConsensus failed.")
 }
 
+func (s *txValidatorTestSuite) TestCheckSideChainTxProofs() {
+	// 1. Generate a side chain block with two transactions, and record it on
+	// the main chain by a side chain pow transaction.
+	sideTx1, sideTx2 := *randomUint256(), *randomUint256()
+	header := randomBlockHeader()
+	header.MerkleRoot = common.Uint256(common.Sha256D(
+		append(sideTx1.Bytes(), sideTx2.Bytes()...)))
+	headerBuf := new(bytes.Buffer)
+	s.NoError(header.SerializeNoAux(headerBuf))
+
+	genesisHash := *randomUint256()
+	powTxn := &types.Transaction{
+		TxType: types.SideChainPow,
+		Payload: &payload.SideChainPow{
+			SideBlockHash:   header.Hash(),
+			SideGenesisHash: genesisHash,
+			BlockHeight:     header.Height,
+		},
+	}
+	store := s.Chain.db.(*ChainStore)
+	store.NewBatch()
+	s.NoError(store.persistTransaction(powTxn, 1))
+	s.NoError(store.BatchCommit())
+	DefaultLedger.Store = store
+	defer func() { DefaultLedger.Store = nil }()
+
+	genesisAddress, _ := common.ToProgramHash(byte(contract.PrefixCrossChain),
+		contract.CreateCrossChainRedeemScript(genesisHash)).ToAddress()
+	witPayload := &payload.WithdrawFromSideChain{
+		BlockHeight:                100,
+		GenesisBlockAddress:        genesisAddress,
+		SideChainTransactionHashes: []common.Uint256{sideTx2},
+		Proofs: []payload.SideChainTxProof{{
+			SideChainPowTxHash: powTxn.Hash(),
+			SideBlockHeader:    headerBuf.Bytes(),
+			MerkleBranch:       []common.Uint256{sideTx1},
+			Index:              1,
+		}},
+	}
+	txn := &types.Transaction{
+		TxType:         types.WithdrawFromSideChain,
+		PayloadVersion: payload.WithdrawFromSideChainProofVersion,
+		Payload:        witPayload,
+	}
+
+	// 2. Run checkSideChainTxProofs
+	s.NoError(checkSideChainTxProofs(txn))
+
+	// invalid merkle index
+	witPayload.Proofs[0].Index = 0
+	s.EqualError(checkSideChainTxProofs(txn), "invalid merkle proof of "+
+		"side chain transaction "+sideTx2.String())
+	witPayload.Proofs[0].Index = 1
+
+	// side chain pow transaction not found
+	witPayload.Proofs[0].SideChainPowTxHash = *randomUint256()
+	s.Error(checkSideChainTxProofs(txn))
+	witPayload.Proofs[0].SideChainPowTxHash = powTxn.Hash()
+
+	// side block of another side chain
+	otherAddress, _ := common.ToProgramHash(byte(contract.PrefixCrossChain),
+		contract.CreateCrossChainRedeemScript(*randomUint256())).ToAddress()
+	witPayload.GenesisBlockAddress = otherAddress
+	s.Error(checkSideChainTxProofs(txn))
+	witPayload.GenesisBlockAddress = genesisAddress
+
+	// proofs count mismatch
+	witPayload.SideChainTransactionHashes = append(
+		witPayload.SideChainTransactionHashes, sideTx1)
+	s.EqualError(checkSideChainTxProofs(txn),
+		"side chain transaction proofs count mismatch")
+
+	// not supported before SideChainTxProofHeight
+	_, err := checkTxRules(getTxRules(txn).sanity, &txRuleContext{
+		chain:       s.Chain,
+		blockHeight: s.Chain.chainParams.SideChainTxProofHeight - 1,
+		txn:         txn,
+	})
+	s.EqualError(err, "not support before SideChainTxProofHeight")
+}
+
 func (s *txValidatorTestSuite) TestCheckDestructionAddress() {
 	destructionAddress := "ELANULLXXXXXXXXXXXXXXXXXXXXXYvs3rr"
 	txID, _ := common.Uint256FromHexString("7e8863a503e90e6464529feb1c25d98c903e01bec00ccfea2475db4e37d7328b")
@@ -620,19 +710,30 @@ func (s *txValidatorTestSuite) TestCheckRegisterProducerTransaction() {
 		ProgramHash: *publicKeyDeposit1,
 	}}
 
-	err = s.Chain.checkRegisterProducerTransaction(txn)
+	err = s.Chain.checkRegisterProducerTransaction(txn,
+		s.Chain.GetHeight()+1)
 	s.NoError(err)
 
+	// The name policy applies by the height of the block packing the
+	// transaction rather than the best height of the chain.
+	rpPayload.NickName = " nickname 1"
+	err = s.Chain.checkRegisterProducerTransaction(txn,
+		s.Chain.chainParams.NamePolicyHeight)
+	s.EqualError(err, "nickname  nickname 1 has leading or trailing spaces")
+	rpPayload.NickName = "nickname 1"
+
 	// Give an invalid owner public key in payload
 	txn.Payload.(*payload.ProducerInfo).OwnerPublicKey = errPublicKey
-	err = s.Chain.checkRegisterProducerTransaction(txn)
+	err = s.Chain.checkRegisterProducerTransaction(txn,
+		s.Chain.GetHeight()+1)
 	s.EqualError(err, "invalid owner public key in payload")
 
 	// check node public when block height is higher than h2
 	originHeight := config.DefaultParams.PublicDPOSHeight
 	txn.Payload.(*payload.ProducerInfo).NodePublicKey = errPublicKey
 	config.DefaultParams.PublicDPOSHeight = 0
-	err = s.Chain.checkRegisterProducerTransaction(txn)
+	err = s.Chain.checkRegisterProducerTransaction(txn,
+		s.Chain.GetHeight()+1)
 	config.DefaultParams.PublicDPOSHeight = originHeight
 	s.EqualError(err, "invalid node public key in payload")
 
@@ -641,7 +742,8 @@ func (s *txValidatorTestSuite) TestCheckRegisterProducerTransaction() {
 	pk, _ := common.HexStringToBytes(config.DefaultParams.CRCArbiters[0])
 	txn.Payload.(*payload.ProducerInfo).NodePublicKey = pk
 	config.DefaultParams.PublicDPOSHeight = 0
-	err = s.Chain.checkRegisterProducerTransaction(txn)
+	err = s.Chain.checkRegisterProducerTransaction(txn,
+		s.Chain.GetHeight()+1)
 	config.DefaultParams.PublicDPOSHeight = originHeight
 	s.EqualError(err, "node public key can't equal with CRC")
 
@@ -650,14 +752,16 @@ func (s *txValidatorTestSuite) TestCheckRegisterProducerTransaction() {
 	pk, _ = common.HexStringToBytes(config.DefaultParams.CRCArbiters[0])
 	txn.Payload.(*payload.ProducerInfo).OwnerPublicKey = pk
 	config.DefaultParams.PublicDPOSHeight = 0
-	err = s.Chain.checkRegisterProducerTransaction(txn)
+	err = s.Chain.checkRegisterProducerTransaction(txn,
+		s.Chain.GetHeight()+1)
 	config.DefaultParams.PublicDPOSHeight = originHeight
 	s.EqualError(err, "owner public key can't equal with CRC")
 
 	// Invalidates the signature in payload
 	txn.Payload.(*payload.ProducerInfo).OwnerPublicKey = publicKey2
 	txn.Payload.(*payload.ProducerInfo).NodePublicKey = publicKey2
-	err = s.Chain.checkRegisterProducerTransaction(txn)
+	err = s.Chain.checkRegisterProducerTransaction(txn,
+		s.Chain.GetHeight()+1)
 	s.EqualError(err, "invalid signature in payload")
 
 	// Give a mismatching deposit address
@@ -678,7 +782,8 @@ func (s *txValidatorTestSuite) TestCheckRegisterProducerTransaction() {
 		OutputLock:  0,
 		ProgramHash: *publicKeyDeposit2,
 	}}
-	err = s.Chain.checkRegisterProducerTransaction(txn)
+	err = s.Chain.checkRegisterProducerTransaction(txn,
+		s.Chain.GetHeight()+1)
 	s.EqualError(err, "deposit address does not match the public key in payload")
 
 	// Give a insufficient deposit coin
@@ -688,7 +793,8 @@ func (s *txValidatorTestSuite) TestCheckRegisterProducerTransaction() {
 		OutputLock:  0,
 		ProgramHash: *publicKeyDeposit1,
 	}}
-	err = s.Chain.checkRegisterProducerTransaction(txn)
+	err = s.Chain.checkRegisterProducerTransaction(txn,
+		s.Chain.GetHeight()+1)
 	s.EqualError(err, "producer deposit amount is insufficient")
 
 	// Multi deposit addresses
@@ -705,7 +811,8 @@ func (s *txValidatorTestSuite) TestCheckRegisterProducerTransaction() {
 			OutputLock:  0,
 			ProgramHash: *publicKeyDeposit1,
 		}}
-	err = s.Chain.checkRegisterProducerTransaction(txn)
+	err = s.Chain.checkRegisterProducerTransaction(txn,
+		s.Chain.GetHeight()+1)
 	s.EqualError(err, "there must be only one deposit address in outputs")
 }
 
@@ -1080,18 +1187,21 @@ func (s *txValidatorTestSuite) TestCheckUpdateProducerTransaction() {
 	txn.Payload = updatePayload
 	s.Chain.state.ProcessBlock(block, nil)
 
-	s.EqualError(s.Chain.checkUpdateProducerTransaction(txn), "field NickName has invalid string length")
+	s.EqualError(s.Chain.checkUpdateProducerTransaction(txn,
+		s.Chain.GetHeight()+1), "field NickName has invalid string length")
 	updatePayload.NickName = "nick name"
 
 	updatePayload.Url = "www.elastos.org"
 	updatePayload.OwnerPublicKey = errPublicKey
-	s.EqualError(s.Chain.checkUpdateProducerTransaction(txn), "invalid owner public key in payload")
+	s.EqualError(s.Chain.checkUpdateProducerTransaction(txn,
+		s.Chain.GetHeight()+1), "invalid owner public key in payload")
 
 	// check node public when block height is higher than h2
 	originHeight := config.DefaultParams.PublicDPOSHeight
 	updatePayload.NodePublicKey = errPublicKey
 	config.DefaultParams.PublicDPOSHeight = 0
-	s.EqualError(s.Chain.checkUpdateProducerTransaction(txn), "invalid node public key in payload")
+	s.EqualError(s.Chain.checkUpdateProducerTransaction(txn,
+		s.Chain.GetHeight()+1), "invalid node public key in payload")
 	config.DefaultParams.PublicDPOSHeight = originHeight
 
 	// check node public key same with CRC
@@ -1099,7 +1209,8 @@ func (s *txValidatorTestSuite) TestCheckUpdateProducerTransaction() {
 	pk, _ := common.HexStringToBytes(config.DefaultParams.CRCArbiters[0])
 	txn.Payload.(*payload.ProducerInfo).NodePublicKey = pk
 	config.DefaultParams.PublicDPOSHeight = 0
-	err := s.Chain.checkUpdateProducerTransaction(txn)
+	err := s.Chain.checkUpdateProducerTransaction(txn,
+		s.Chain.GetHeight()+1)
 	config.DefaultParams.PublicDPOSHeight = originHeight
 	s.EqualError(err, "node public key can't equal with CRC")
 
@@ -1108,13 +1219,15 @@ func (s *txValidatorTestSuite) TestCheckUpdateProducerTransaction() {
 	pk, _ = common.HexStringToBytes(config.DefaultParams.CRCArbiters[0])
 	txn.Payload.(*payload.ProducerInfo).OwnerPublicKey = pk
 	config.DefaultParams.PublicDPOSHeight = 0
-	err = s.Chain.checkUpdateProducerTransaction(txn)
+	err = s.Chain.checkUpdateProducerTransaction(txn,
+		s.Chain.GetHeight()+1)
 	config.DefaultParams.PublicDPOSHeight = originHeight
 	s.EqualError(err, "owner public key can't equal with CRC")
 
 	updatePayload.OwnerPublicKey = publicKey2
 	updatePayload.NodePublicKey = publicKey1
-	s.EqualError(s.Chain.checkUpdateProducerTransaction(txn), "invalid signature in payload")
+	s.EqualError(s.Chain.checkUpdateProducerTransaction(txn,
+		s.Chain.GetHeight()+1), "invalid signature in payload")
 
 	updatePayload.OwnerPublicKey = publicKey1
 	updateSignBuf := new(bytes.Buffer)
@@ -1123,7 +1236,8 @@ func (s *txValidatorTestSuite) TestCheckUpdateProducerTransaction() {
 	updateSig, err := crypto.Sign(privateKey1, updateSignBuf.Bytes())
 	s.NoError(err)
 	updatePayload.Signature = updateSig
-	s.NoError(s.Chain.checkUpdateProducerTransaction(txn))
+	s.NoError(s.Chain.checkUpdateProducerTransaction(txn,
+		s.Chain.GetHeight()+1))
 
 	//rest of check test will be continued in chain test
 }
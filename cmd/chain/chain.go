@@ -0,0 +1,111 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package chain
+
+import (
+	"github.com/elastos/Elastos.ELA/blockchain"
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+
+	"github.com/urfave/cli"
+)
+
+const (
+	// dataPath indicates the path storing the chain data under data dir.
+	dataPath = "data"
+
+	// checkpointPath indicates the path storing the checkpoint data under
+	// chain data path.
+	checkpointPath = "checkpoints"
+)
+
+var (
+	heightFlag = cli.IntFlag{
+		Name:  "height",
+		Usage: "the final `<height>` after rollback",
+		Value: -1,
+	}
+
+	dbEngineFlag = cli.StringFlag{
+		Name:  "dbengine",
+		Usage: "database `<engine>` of the chain store, leveldb or pebble",
+		Value: blockchain.LevelDBEngine,
+	}
+
+	fromEngineFlag = cli.StringFlag{
+		Name:  "from",
+		Usage: "database `<engine>` of the existing chain store",
+		Value: blockchain.LevelDBEngine,
+	}
+
+	toEngineFlag = cli.StringFlag{
+		Name:  "to",
+		Usage: "database `<engine>` to migrate the chain store to",
+	}
+)
+
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "chain",
+		Usage:       "Maintain local blockchain data",
+		Description: "With ela-cli chain, you could maintain the local blockchain data of a stopped node.",
+		ArgsUsage:   "[args]",
+		Subcommands: []cli.Command{
+			{
+				Name:  "rollback",
+				Usage: "Rollback block index, UTXOs, CR and DPoS states to height",
+				Flags: []cli.Flag{
+					heightFlag,
+					dbEngineFlag,
+					cmdcom.ConfigFileFlag,
+					cmdcom.ChainParamsFlag,
+					cmdcom.DataDirFlag,
+				},
+				Action: rollbackAction,
+			},
+			{
+				Name:  "migrate",
+				Usage: "Migrate the chain store to another database engine",
+				Flags: []cli.Flag{
+					fromEngineFlag,
+					toEngineFlag,
+					cmdcom.DataDirFlag,
+				},
+				Action: migrateAction,
+			},
+			{
+				Name: "forkcheck",
+				Usage: "Check the activation heights of the configuration " +
+					"against the network and the chain tip",
+				Flags: []cli.Flag{
+					cmdcom.ConfigFileFlag,
+					cmdcom.ChainParamsFlag,
+					cmdcom.DataDirFlag,
+				},
+				Action: forkCheckAction,
+			},
+		},
+	}
+}
+
+// NewRollbackCommand returns the legacy top level rollback command, it is
+// kept for compatibility and works the same as chain rollback.
+func NewRollbackCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "rollback",
+		Usage:       "Rollback blockchain data",
+		Description: "With ela-cli rollback command, you could rollback blockchain data.",
+		ArgsUsage:   "[args]",
+		Hidden:      true,
+		Flags: []cli.Flag{
+			heightFlag,
+			dbEngineFlag,
+			cmdcom.ConfigFileFlag,
+			cmdcom.ChainParamsFlag,
+			cmdcom.DataDirFlag,
+		},
+		Action: rollbackAction,
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package chain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/elastos/Elastos.ELA/blockchain"
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/common/log"
+
+	"github.com/urfave/cli"
+)
+
+// activationHeight is an activation height of the chain parameters, the
+// configured height is used by the node, and the network height is the one
+// defined by the active network and the chain parameters file.
+type activationHeight struct {
+	param      string
+	configured uint32
+	network    uint32
+	rules      []blockchain.ForkRule
+}
+
+func forkCheckAction(c *cli.Context) error {
+	conf, err := loadConfiguration(c.String(cmdcom.ConfigFileFlag.Name),
+		c.IsSet(cmdcom.ConfigFileFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	network, err := loadNetworkParams(conf,
+		c.String(cmdcom.ChainParamsFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	tip, err := chainTip(c.String(cmdcom.DataDirFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	heights := activationHeights(configuredParams(conf, network), network)
+	fmt.Println("current height:", tip)
+	if diverged := forkCheck(heights, tip); diverged > 0 {
+		return fmt.Errorf("%d activation heights diverge from the network, "+
+			"correct the configuration before the node reaches them", diverged)
+	}
+	fmt.Println("node is ready for all upcoming activation heights")
+	return nil
+}
+
+// forkCheck prints the activation heights compared with the current height,
+// and returns the count of configured heights diverging from the network.
+func forkCheck(heights []activationHeight, tip uint32) int {
+	var diverged int
+	for _, h := range heights {
+		status := "active"
+		if h.configured > tip {
+			status = fmt.Sprintf("upcoming in %d blocks", h.configured-tip)
+		}
+		fmt.Printf("%-28s %10d  %s\n", h.param, h.configured, status)
+		if len(h.rules) > 0 {
+			fmt.Println("    enables:", describeForkRules(h.rules))
+		}
+
+		if h.configured == h.network {
+			continue
+		}
+		diverged++
+		switch {
+		case h.configured <= tip || h.network <= tip:
+			cmdcom.PrintErrorMsg("    %s is %d while the network activates "+
+				"at %d, node has already diverged from the network",
+				h.param, h.configured, h.network)
+		case h.configured > h.network && len(h.rules) > 0:
+			cmdcom.PrintErrorMsg("    %s is %d while the network activates "+
+				"at %d, node will reject blocks containing the enabled "+
+				"transactions from %d to %d", h.param, h.configured,
+				h.network, h.network, h.configured-1)
+		case h.configured > h.network:
+			cmdcom.PrintErrorMsg("    %s is %d while the network activates "+
+				"at %d, node will apply the old rules and reject blocks "+
+				"from %d", h.param, h.configured, h.network, h.network)
+		default:
+			cmdcom.PrintErrorMsg("    %s is %d while the network activates "+
+				"at %d, node will apply the new rules early and reject "+
+				"blocks from %d", h.param, h.configured, h.network,
+				h.configured)
+		}
+	}
+	return diverged
+}
+
+// activationHeights returns the activation heights of the chain parameters
+// sorted by the configured height.
+func activationHeights(configured, network *config.Params) []activationHeight {
+	rules := make(map[string][]blockchain.ForkRule)
+	for _, r := range blockchain.ForkRules() {
+		rules[r.Param] = append(rules[r.Param], r)
+	}
+
+	var heights []activationHeight
+	cv := reflect.ValueOf(configured).Elem()
+	nv := reflect.ValueOf(network).Elem()
+	for i := 0; i < cv.NumField(); i++ {
+		field := cv.Type().Field(i)
+		if !isActivationHeight(field) {
+			continue
+		}
+		heights = append(heights, activationHeight{
+			param:      field.Name,
+			configured: uint32(cv.Field(i).Uint()),
+			network:    uint32(nv.Field(i).Uint()),
+			rules:      rules[field.Name],
+		})
+	}
+	sort.SliceStable(heights, func(i, j int) bool {
+		return heights[i].configured < heights[j].configured
+	})
+	return heights
+}
+
+// configuredParams returns a copy of the network parameters with the
+// activation heights overridden by the configuration, the same way the node
+// does on start.
+func configuredParams(conf *config.Configuration,
+	network *config.Params) *config.Params {
+	result := *network
+	src := reflect.ValueOf(conf).Elem()
+	dst := reflect.ValueOf(&result).Elem()
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Type().Field(i)
+		if !isActivationHeight(field) {
+			continue
+		}
+		value := src.FieldByName(field.Name)
+		if !value.IsValid() || value.Kind() != reflect.Uint32 ||
+			value.Uint() == 0 {
+			continue
+		}
+		dst.Field(i).SetUint(value.Uint())
+	}
+	return &result
+}
+
+func isActivationHeight(field reflect.StructField) bool {
+	return field.Type.Kind() == reflect.Uint32 &&
+		strings.HasSuffix(field.Name, "Height")
+}
+
+func describeForkRules(rules []blockchain.ForkRule) string {
+	names := make([]string, 0, len(rules))
+	for _, r := range rules {
+		name := r.TxType.Name()
+		if r.PayloadVersion >= 0 {
+			name += fmt.Sprintf(" (payload version %d)", r.PayloadVersion)
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// networkParams returns the built-in parameters of the active network.
+func networkParams(activeNet string) *config.Params {
+	switch strings.ToLower(activeNet) {
+	case "testnet", "test":
+		return config.DefaultParams.TestNet()
+	case "regnet", "reg":
+		return config.DefaultParams.RegNet()
+	default:
+		return &config.DefaultParams
+	}
+}
+
+// ActiveParams returns the parameters used by the node with the config file
+// and the chain parameters file, the chain parameters file overrides the one
+// set in the config file if it's not empty.
+func ActiveParams(configFile, chainParamsFile string,
+	required bool) (*config.Params, error) {
+	conf, err := loadConfiguration(configFile, required)
+	if err != nil {
+		return nil, err
+	}
+	network, err := loadNetworkParams(conf, chainParamsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	params := configuredParams(conf, network)
+	if conf.DPoSConfiguration.CheckPointRetainCount > 0 {
+		params.CheckPointRetainCount =
+			conf.DPoSConfiguration.CheckPointRetainCount
+	}
+	if conf.DPoSConfiguration.CheckPointArchiveSpan > 0 {
+		params.CheckPointArchiveSpan =
+			conf.DPoSConfiguration.CheckPointArchiveSpan
+	}
+	return params, nil
+}
+
+// loadNetworkParams returns the parameters of the active network with the
+// chain parameters file applied.
+func loadNetworkParams(conf *config.Configuration,
+	chainParamsFile string) (*config.Params, error) {
+	network := networkParams(conf.ActiveNet)
+	if chainParamsFile == "" {
+		chainParamsFile = conf.ChainParamsFile
+	}
+	if chainParamsFile == "" {
+		return network, nil
+	}
+	file, err := config.LoadChainParamsFile(chainParamsFile)
+	if err != nil {
+		return nil, err
+	}
+	network, _, err = file.Apply(network)
+	return network, err
+}
+
+// loadConfiguration reads the config file of the node, an empty configuration
+// is returned if the default config file does not exist.
+func loadConfiguration(path string, required bool) (*config.Configuration,
+	error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if required {
+			return nil, err
+		}
+		return &config.Configuration{}, nil
+	}
+	// Remove the UTF-8 Byte Order Mark
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	cfgFile := struct {
+		config.Configuration `json:"Configuration"`
+	}{}
+	if err := json.Unmarshal(data, &cfgFile); err != nil {
+		return nil, errors.New("config file parsing failed, " + err.Error())
+	}
+	return &cfgFile.Configuration, nil
+}
+
+// chainTip returns the height of the best chain of the stopped node in the
+// data dir.
+func chainTip(root string) (uint32, error) {
+	dataDir := filepath.Join(root, dataPath)
+	log.NewDefault(filepath.Join(root, "logs/node"), 0, 0, 0)
+
+	fdb, err := blockchain.NewChainStoreFFLDB(dataDir)
+	if err != nil {
+		return 0, err
+	}
+	defer fdb.Close()
+
+	nodes := getBlockNodes(fdb)
+	if len(nodes) == 0 {
+		return 0, fmt.Errorf("no blocks found in %s", dataDir)
+	}
+	return uint32(len(nodes) - 1), nil
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/elastos/Elastos.ELA/blockchain"
+
+	"github.com/urfave/cli"
+)
+
+// migrateBatchSize indicates the count of keys written in one batch when
+// migrating the chain store.
+const migrateBatchSize = 10000
+
+func migrateAction(c *cli.Context) error {
+	if c.NumFlags() == 0 {
+		cli.ShowSubcommandHelp(c)
+		return nil
+	}
+	from, to := c.String("from"), c.String("to")
+	if to == "" {
+		return errors.New("use --to to specify the target database engine")
+	}
+	if from == to {
+		return fmt.Errorf("chain store is already using %s", to)
+	}
+
+	dataDir := filepath.Join(c.String("datadir"), dataPath)
+	srcPath := filepath.Join(dataDir, "chain")
+	dstPath := filepath.Join(dataDir, "chain."+to)
+	backupPath := filepath.Join(dataDir, "chain."+from)
+	if _, err := os.Stat(dstPath); err == nil {
+		return fmt.Errorf("%s already exists, remove it before migrating",
+			dstPath)
+	}
+	if _, err := os.Stat(backupPath); err == nil {
+		return fmt.Errorf("%s already exists, remove it before migrating",
+			backupPath)
+	}
+
+	src, err := blockchain.NewStore(from, srcPath)
+	if err != nil {
+		return fmt.Errorf("connect database failed! Please check whether "+
+			"there is already a ela process running, %s", err)
+	}
+	dst, err := blockchain.NewStore(to, dstPath)
+	if err != nil {
+		src.Close()
+		return err
+	}
+
+	count, err := copyStore(src, dst)
+	src.Close()
+	dst.Close()
+	if err != nil {
+		os.RemoveAll(dstPath)
+		return fmt.Errorf("migrate chain store failed, %s", err)
+	}
+
+	// Keep the original data as backup and move the migrated data in place.
+	if err := os.Rename(srcPath, backupPath); err != nil {
+		return err
+	}
+	if err := os.Rename(dstPath, srcPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("migrated %d keys from %s to %s, original data is kept "+
+		"in %s\n", count, from, to, backupPath)
+	fmt.Printf("set \"DBEngine\": \"%s\" in config.json before next "+
+		"start\n", to)
+	return nil
+}
+
+// copyStore copies all key-value pairs from src to dst and returns the count
+// of copied keys.
+func copyStore(src, dst blockchain.IStore) (int, error) {
+	iter := src.NewIterator(nil)
+	defer iter.Release()
+
+	count := 0
+	dst.NewBatch()
+	for iter.Next() {
+		// Copy the key and value since the iterator may reuse the buffer.
+		key := append([]byte{}, iter.Key()...)
+		value := append([]byte{}, iter.Value()...)
+		dst.BatchPut(key, value)
+		count++
+
+		if count%migrateBatchSize == 0 {
+			if err := dst.BatchCommit(); err != nil {
+				return count, err
+			}
+			dst.NewBatch()
+			fmt.Println("migrated keys:", count)
+		}
+	}
+	if err := dst.BatchCommit(); err != nil {
+		return count, err
+	}
+	return count, nil
+}
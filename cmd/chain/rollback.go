@@ -0,0 +1,215 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/elastos/Elastos.ELA/blockchain"
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/common/log"
+	"github.com/elastos/Elastos.ELA/core/checkpoint"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/database"
+	"github.com/elastos/Elastos.ELA/dpos/store"
+
+	"github.com/urfave/cli"
+)
+
+func rollbackAction(c *cli.Context) error {
+	if c.NumFlags() == 0 {
+		cli.ShowSubcommandHelp(c)
+		return nil
+	}
+	targetHeight := c.Int("height")
+	if targetHeight < 0 {
+		return errors.New("invalid height, use --height to specify the " +
+			"final height after rollback")
+	}
+	params, err := ActiveParams(c.String(cmdcom.ConfigFileFlag.Name),
+		c.String(cmdcom.ChainParamsFlag.Name),
+		c.IsSet(cmdcom.ConfigFileFlag.Name))
+	if err != nil {
+		return err
+	}
+	return Rollback(c.String("datadir"), c.String("dbengine"), params,
+		targetHeight)
+}
+
+// Rollback rolls back the block index, UTXOs, CR and DPoS states of the
+// stopped node in the data dir to the target height, the params should be
+// the ones used by the node.
+func Rollback(root, dbEngine string, params *config.Params,
+	targetHeight int) error {
+	dataDir := filepath.Join(root, dataPath)
+	log.NewDefault(filepath.Join(root, "logs/node"), 0, 0, 0)
+
+	fdb, err := blockchain.NewChainStoreFFLDB(dataDir)
+	if err != nil {
+		return err
+	}
+	defer fdb.Close()
+	nodes := getBlockNodes(fdb)
+
+	db, err := blockchain.NewStore(dbEngine,
+		filepath.Join(dataDir, "chain"))
+	if err != nil {
+		return fmt.Errorf("connect database failed! Please check whether "+
+			"there is already a ela process running, %s", err)
+	}
+	defer db.Close()
+	chain := blockchain.ChainStore{IStore: db}
+
+	dposStore, err := store.NewDposStore(dataDir, params)
+	if err != nil {
+		return err
+	}
+	defer dposStore.Close()
+
+	currentHeight := len(nodes) - 1
+	if targetHeight >= currentHeight {
+		return fmt.Errorf("current height of blockchain is %d, can not "+
+			"rollback to %d", currentHeight, targetHeight)
+	}
+
+	// Load all blocks to be rolled back before touching any data, so we will
+	// not leave modules in an inconsistent state when part of the history is
+	// out of reach.
+	blocks := make([]*types.Block, 0, currentHeight-targetHeight)
+	for i := currentHeight; i > targetHeight; i-- {
+		block, err := fdb.GetBlock(*nodes[i].Hash)
+		if err != nil {
+			return fmt.Errorf("block of height %d is out of reach, %s",
+				i, err)
+		}
+		blocks = append(blocks, block)
+	}
+
+	for _, block := range blocks {
+		fmt.Println("current height is", block.Height)
+		fmt.Println("blockhash before rollback:", block.Hash())
+		if err := rollbackBlock(fdb, &chain, block); err != nil {
+			return fmt.Errorf("rollback block of height %d failed, %s",
+				block.Height, err)
+		}
+		fmt.Println("blockhash after rollback:", block.Header.Previous)
+	}
+
+	if err := dposStore.RollbackTo(uint32(targetHeight)); err != nil {
+		return fmt.Errorf("rollback DPoS checkpoints failed, %s", err)
+	}
+
+	removed, err := checkpoint.RollbackFiles(
+		filepath.Join(dataDir, checkpointPath), uint32(targetHeight))
+	if err != nil {
+		return fmt.Errorf("rollback checkpoints failed, %s", err)
+	}
+	for _, f := range removed {
+		fmt.Println("removed checkpoint:", f)
+	}
+
+	fmt.Println("rollback finished, CR and DPoS states will be recovered " +
+		"from block chain data on next start")
+	return nil
+}
+
+func rollbackBlock(fdb blockchain.IFFLDBChainStore,
+	chain *blockchain.ChainStore, block *types.Block) error {
+	if err := rollBackFFLDBBlock(fdb, &block.Header); err != nil {
+		return err
+	}
+
+	chain.NewBatch()
+	if err := chain.RollbackTrimmedBlock(block); err != nil {
+		return err
+	}
+	if err := chain.RollbackBlockHash(block); err != nil {
+		return err
+	}
+	if err := chain.RollbackTransactions(block); err != nil {
+		return err
+	}
+	if err := chain.RollbackUnspendUTXOs(block); err != nil {
+		return err
+	}
+	if err := chain.RollbackUnspend(block); err != nil {
+		return err
+	}
+	if err := chain.RollbackCurrentBlock(block); err != nil {
+		return err
+	}
+	if err := chain.RollbackConfirm(block); err != nil {
+		return err
+	}
+	return chain.BatchCommit()
+}
+
+func rollBackFFLDBBlock(fflDB blockchain.IFFLDBChainStore, header *types.Header) error {
+	err := fflDB.Update(func(dbTx database.Tx) error {
+		err := blockchain.DBRemoveBlockNode(dbTx, header)
+		if err != nil {
+			return err
+		}
+
+		// Remove the block hash and height from the block index which
+		// tracks the main chain.
+		blockHash := header.Hash()
+		err = blockchain.DBRemoveBlockIndex(dbTx, &blockHash, header.Height)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	return err
+}
+
+func getBlockNodes(fdb blockchain.IFFLDBChainStore) []*blockchain.BlockNode {
+	blockNodes := make([]*blockchain.BlockNode, 0)
+	err := fdb.View(func(dbTx database.Tx) error {
+		// Load all of the headers from the data for the known best
+		// chain and construct the block index accordingly.  Since the
+		// number of nodes are already known, perform a single alloc
+		// for them versus a whole bunch of little ones to reduce
+		// pressure on the GC.
+		log.Infof("Loading block index...")
+
+		blockIndexBucket := dbTx.Metadata().Bucket([]byte("blockheaderidx"))
+
+		// Determine how many blocks will be loaded into the index so we can
+		// allocate the right amount.
+		var blockCount int32
+		cursor := blockIndexBucket.Cursor()
+		for ok := cursor.First(); ok; ok = cursor.Next() {
+			blockCount++
+		}
+		log.Info("block count:", blockCount)
+
+		var i int32
+		cursor = blockIndexBucket.Cursor()
+		for ok := cursor.First(); ok; ok = cursor.Next() {
+			header, status, err := blockchain.DeserializeBlockRow(cursor.Value())
+			if err != nil {
+				return err
+			}
+
+			curHash := header.Hash()
+			node := blockchain.NewBlockNode(header, &curHash)
+			node.Status = status
+			blockNodes = append(blockNodes, node)
+			i++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil
+	}
+	return blockNodes
+}
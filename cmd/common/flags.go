@@ -18,7 +18,7 @@ var (
 	// Account flags
 	AccountWalletFlag = cli.StringFlag{
 		Name:  "wallet, w",
-		Usage: "wallet `<file>` path",
+		Usage: "wallet `<name>` under the wallets directory or keystore file path",
 		Value: account.KeystoreFileName,
 	}
 	AccountPasswordFlag = cli.StringFlag{
@@ -79,11 +79,108 @@ var (
 		Name:  "for",
 		Usage: "the `<file>` path that holds the list of candidates",
 	}
+	TransactionDryRunFlag = cli.BoolFlag{
+		Name:  "dryrun",
+		Usage: "print the constructed transaction without signing and sending it",
+	}
 	TransactionSAddressFlag = cli.StringFlag{
 		Name:  "saddress",
 		Usage: "the locked `<address>` on main chain represents one side chain",
 	}
 
+	// Consolidate flags
+	ConsolidateAddressFlag = cli.StringFlag{
+		Name:  "address",
+		Usage: "the `<address>` to consolidate, the main account is used if not specified",
+	}
+	ConsolidateMaxInputsFlag = cli.IntFlag{
+		Name:  "max-inputs",
+		Usage: "the max `<number>` of inputs of a consolidation transaction",
+		Value: 500,
+	}
+	ConsolidateThresholdFlag = cli.StringFlag{
+		Name:  "threshold",
+		Usage: "only consolidate utxos with amount lower than the `<amount>`, all utxos if not specified",
+	}
+	ConsolidateFeeRateFlag = cli.StringFlag{
+		Name:  "feerate",
+		Usage: "the transaction fee `<amount>` per KB",
+		Value: "0.0001",
+	}
+	ConsolidateSendFlag = cli.BoolFlag{
+		Name:  "send",
+		Usage: "send the consolidation transactions to the node after signing",
+	}
+
+	// Lock unspent flags
+	LockUnspentOutPointsFlag = cli.StringFlag{
+		Name:  "utxos",
+		Usage: "the utxos in format of `<txid:vout>`, separate utxos with comma `,`",
+	}
+	LockUnspentUnlockFlag = cli.BoolFlag{
+		Name:  "unlock",
+		Usage: "unlock the utxos instead of locking them, all utxos are unlocked if no utxos specified",
+	}
+
+	// Payment uri flags
+	PaymentURIFlag = cli.StringFlag{
+		Name:  "uri",
+		Usage: "the payment `<uri>` in format of elastos:<address>?amount=<amount>&memo=<memo>",
+	}
+	PaymentMemoFlag = cli.StringFlag{
+		Name:  "memo",
+		Usage: "the `<memo>` of the payment",
+	}
+	PaymentDepositOfFlag = cli.StringFlag{
+		Name:  "depositof",
+		Usage: "the owner `<publickey>` of the producer or CR whose deposit address is the recipient",
+	}
+
+	// Producer flags
+	ProducerOwnerPublicKeyFlag = cli.StringFlag{
+		Name:  "ownerpublickey",
+		Usage: "the owner `<public key>` of the producer, the main account is used if not specified",
+	}
+	ProducerNodePublicKeyFlag = cli.StringFlag{
+		Name:  "nodepublickey",
+		Usage: "the node `<public key>` of the producer",
+	}
+	ProducerNickNameFlag = cli.StringFlag{
+		Name:  "nickname",
+		Usage: "the `<nickname>` of the producer or CR candidate",
+	}
+	ProducerURLFlag = cli.StringFlag{
+		Name:  "url",
+		Usage: "the `<url>` of the producer or CR candidate",
+	}
+	ProducerLocationFlag = cli.StringFlag{
+		Name:  "location",
+		Usage: "the `<location code>` of the producer or CR candidate",
+	}
+	ProducerNetAddressFlag = cli.StringFlag{
+		Name:  "netaddress",
+		Usage: "the `<ip:port>` of the producer node for DPoS connections",
+	}
+	ProducerDepositFlag = cli.StringFlag{
+		Name:  "deposit",
+		Usage: "the deposit `<amount>` of the producer or CR candidate",
+	}
+	ProducerWaitFlag = cli.IntFlag{
+		Name:  "wait",
+		Usage: "`<seconds>` to wait for the state to change, 0 means do not wait",
+		Value: 600,
+	}
+
+	// CR flags
+	CRPublicKeyFlag = cli.StringFlag{
+		Name:  "publickey",
+		Usage: "the `<public key>` of the CR candidate, the main account is used if not specified",
+	}
+	CRPayloadVersionFlag = cli.StringFlag{
+		Name:  "payloadversion",
+		Usage: "the `<version>` of the CR info payload, 0 without DID and 1 with DID",
+	}
+
 	// RPC flags
 	RPCUserFlag = cli.StringFlag{
 		Name:  "rpcuser",
@@ -133,6 +230,11 @@ var (
 		Usage: "specify network type to reg test net",
 		Value: defaultConfigPath,
 	}
+	ChainParamsFlag = cli.StringFlag{
+		Name: "chainparams",
+		Usage: "specify the chain parameters file overriding the active " +
+			"network to define a private network",
+	}
 	ConfigFileFlag = cli.StringFlag{
 		Name:  "conf",
 		Usage: "config `<file>` path, ",
@@ -273,6 +375,11 @@ var (
 		Usage: "defines the duration of voting period which measured by " +
 			"block height",
 	}
+	CRNominationPeriodFlag = cli.StringFlag{
+		Name: "crnominationperiod",
+		Usage: "defines the duration from the start of voting period in " +
+			"which CR registration is accepted",
+	}
 	RegisterCRByDIDHeightFlag = cli.StringFlag{
 		Name:  "RegisterCRByDIDHeight",
 		Usage: "defines the height to support register CR by CID",
@@ -0,0 +1,23 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package common
+
+import (
+	"fmt"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// PrintQRCode renders the content as a QR code in the terminal, two modules
+// are drawn in one character so that the code fits in a normal terminal.
+func PrintQRCode(content string) error {
+	code, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return err
+	}
+	fmt.Print(code.ToSmallString(false))
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/elastos/Elastos.ELA/account"
+	"github.com/elastos/Elastos.ELA/utils"
+
+	"github.com/urfave/cli"
+)
+
+const (
+	// WalletsDir is the directory holding the named keystore files.
+	WalletsDir = "wallets"
+
+	// walletFileExt is the file extension of named keystore files.
+	walletFileExt = ".dat"
+)
+
+// GetWalletPath returns the keystore file path specified by the wallet flag.
+func GetWalletPath(c *cli.Context) string {
+	return WalletPath(c.String("wallet"))
+}
+
+// WalletPath resolves a wallet name to the keystore file path.  An existing
+// file or a value looks like a file path is used as it is, otherwise the
+// value is treated as the name of a keystore file under WalletsDir.
+func WalletPath(name string) string {
+	if name == "" {
+		return account.KeystoreFileName
+	}
+	if utils.FileExisted(name) || filepath.Ext(name) != "" ||
+		strings.ContainsRune(name, filepath.Separator) {
+		return name
+	}
+	return filepath.Join(WalletsDir, name+walletFileExt)
+}
+
+// MakeWalletDir creates the directory of the keystore file if not exist.
+func MakeWalletDir(walletPath string) error {
+	return os.MkdirAll(filepath.Dir(walletPath), 0700)
+}
+
+// ListWallets returns the names of keystore files under WalletsDir.
+func ListWallets() ([]string, error) {
+	files, err := ioutil.ReadDir(WalletsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var names []string
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != walletFileExt {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(f.Name(), walletFileExt))
+	}
+	return names, nil
+}
@@ -10,11 +10,13 @@ import (
 	"os"
 	"time"
 
+	"github.com/elastos/Elastos.ELA/cmd/chain"
 	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
 	"github.com/elastos/Elastos.ELA/cmd/info"
 	"github.com/elastos/Elastos.ELA/cmd/mine"
-	"github.com/elastos/Elastos.ELA/cmd/rollback"
+	"github.com/elastos/Elastos.ELA/cmd/producer"
 	"github.com/elastos/Elastos.ELA/cmd/script"
+	"github.com/elastos/Elastos.ELA/cmd/signer"
 	"github.com/elastos/Elastos.ELA/cmd/wallet"
 
 	"github.com/urfave/cli"
@@ -48,8 +50,12 @@ func main() {
 		*wallet.NewCommand(),
 		*info.NewCommand(),
 		*mine.NewCommand(),
+		*producer.NewCommand(),
+		*producer.NewCRCommand(),
 		*script.NewCommand(),
-		*rollback.NewCommand(),
+		*chain.NewCommand(),
+		*chain.NewRollbackCommand(),
+		*signer.NewCommand(),
 	}
 
 	//sort.Sort(cli.CommandsByName(app.Commands))
@@ -12,6 +12,7 @@ import (
 	"strconv"
 
 	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/utils/http"
 
 	"github.com/urfave/cli"
@@ -179,6 +180,50 @@ func NewCommand() *cli.Command {
 					return nil
 				},
 			},
+			{
+				Name:      "validateaddress",
+				Usage:     "Check whether an address is valid and show its type",
+				ArgsUsage: "<address>",
+				Action: func(c *cli.Context) error {
+					if c.NArg() < 1 {
+						cmdcom.PrintErrorMsg("Missing argument. Address expected.")
+						cli.ShowCommandHelpAndExit(c, "validateaddress", 1)
+					}
+					result, err := cmdcom.RPCCall("validateaddress",
+						http.Params{"address": c.Args().First()})
+					if err != nil {
+						fmt.Println("error: validate address failed,", err)
+						return err
+					}
+					printFormat(result)
+					return nil
+				},
+			},
+			{
+				Name:      "convertaddress",
+				Usage:     "Convert between an address and its program hash",
+				ArgsUsage: "<address|programhash>",
+				Action: func(c *cli.Context) error {
+					if c.NArg() < 1 {
+						cmdcom.PrintErrorMsg("Missing argument. Address or program hash expected.")
+						cli.ShowCommandHelpAndExit(c, "convertaddress", 1)
+					}
+					// A program hash is 21 bytes in hex, and an address is
+					// 34 characters in base58.
+					param := c.Args().First()
+					params := http.Params{"address": param}
+					if len(param) == 2*common.UINT168SIZE {
+						params = http.Params{"programhash": param}
+					}
+					result, err := cmdcom.RPCCall("convertaddress", params)
+					if err != nil {
+						fmt.Println("error: convert address failed,", err)
+						return err
+					}
+					printFormat(result)
+					return nil
+				},
+			},
 			{
 				Name:  "listproducers",
 				Usage: "list current producers information",
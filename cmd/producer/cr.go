@@ -0,0 +1,516 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package producer
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"github.com/elastos/Elastos.ELA/account"
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+	pg "github.com/elastos/Elastos.ELA/core/contract/program"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/servers"
+	"github.com/elastos/Elastos.ELA/utils/http"
+
+	"github.com/urfave/cli"
+)
+
+// NewCRCommand returns the cr command, which walks CR candidates through
+// registering, updating, unregistering and returning the deposit.
+func NewCRCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "cr",
+		Usage:       "Register, update or unregister a CR candidate",
+		Description: "With ela-cli cr, you could operate your CR candidate step by step, values not given by flags are asked interactively",
+		ArgsUsage:   "[args]",
+		Subcommands: []cli.Command{
+			{
+				Name:  "register",
+				Usage: "Register a CR candidate and wait until it is active",
+				Flags: []cli.Flag{
+					cmdcom.CRPublicKeyFlag,
+					cmdcom.ProducerNickNameFlag,
+					cmdcom.ProducerURLFlag,
+					cmdcom.ProducerLocationFlag,
+					cmdcom.CRPayloadVersionFlag,
+					cmdcom.ProducerDepositFlag,
+					cmdcom.TransactionFeeFlag,
+					cmdcom.ProducerWaitFlag,
+					cmdcom.TransactionDryRunFlag,
+					cmdcom.AccountWalletFlag,
+					cmdcom.AccountPasswordFlag,
+				},
+				Action: registerCR,
+			},
+			{
+				Name:  "update",
+				Usage: "Update the information of a registered CR candidate",
+				Flags: []cli.Flag{
+					cmdcom.CRPublicKeyFlag,
+					cmdcom.ProducerNickNameFlag,
+					cmdcom.ProducerURLFlag,
+					cmdcom.ProducerLocationFlag,
+					cmdcom.CRPayloadVersionFlag,
+					cmdcom.TransactionFeeFlag,
+					cmdcom.ProducerWaitFlag,
+					cmdcom.TransactionDryRunFlag,
+					cmdcom.AccountWalletFlag,
+					cmdcom.AccountPasswordFlag,
+				},
+				Action: updateCR,
+			},
+			{
+				Name:  "unregister",
+				Usage: "Unregister a CR candidate and wait until it is canceled",
+				Flags: []cli.Flag{
+					cmdcom.CRPublicKeyFlag,
+					cmdcom.TransactionFeeFlag,
+					cmdcom.ProducerWaitFlag,
+					cmdcom.TransactionDryRunFlag,
+					cmdcom.AccountWalletFlag,
+					cmdcom.AccountPasswordFlag,
+				},
+				Action: unregisterCR,
+			},
+			{
+				Name:  "returndeposit",
+				Usage: "Return the deposit of a canceled CR candidate",
+				Flags: []cli.Flag{
+					cmdcom.CRPublicKeyFlag,
+					cmdcom.TransactionFeeFlag,
+					cmdcom.ProducerWaitFlag,
+					cmdcom.TransactionDryRunFlag,
+					cmdcom.AccountWalletFlag,
+					cmdcom.AccountPasswordFlag,
+				},
+				Action: returnCRDeposit,
+			},
+		},
+	}
+}
+
+// crCandidate is the CR candidate information returned by listcrcandidates.
+type crCandidate struct {
+	Code     string `json:"code"`
+	CID      string `json:"cid"`
+	DID      string `json:"did"`
+	NickName string `json:"nickname"`
+	Url      string `json:"url"`
+	Location uint64 `json:"location"`
+	State    string `json:"state"`
+}
+
+// crInfo is the printable form of the CR info payload.
+type crInfo struct {
+	Code     string `json:"code"`
+	CID      string `json:"cid"`
+	DID      string `json:"did"`
+	NickName string `json:"nickname"`
+	Url      string `json:"url"`
+	Location uint64 `json:"location"`
+}
+
+// crIdentity holds the identities derived from the code of a CR candidate.
+type crIdentity struct {
+	code           []byte
+	cid            common.Uint168
+	did            common.Uint168
+	cidAddress     string
+	depositHash    common.Uint168
+	depositAddress string
+}
+
+func registerCR(c *cli.Context) error {
+	client, acc, err := openAccount(c, "publickey")
+	if err != nil {
+		return err
+	}
+	id, err := deriveCRIdentity(acc)
+	if err != nil {
+		return err
+	}
+	current, err := getCandidate(id.cidAddress)
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		return fmt.Errorf("CR candidate %s already registered, state %s",
+			id.cidAddress, current.State)
+	}
+
+	info, version, err := askCRInfo(c, id, &crCandidate{})
+	if err != nil {
+		return err
+	}
+	deposit, err := askAmount(c, "deposit", "Deposit amount",
+		minDepositAmount.String())
+	if err != nil {
+		return err
+	}
+	if deposit < minDepositAmount {
+		return fmt.Errorf("deposit should not be less than %s",
+			minDepositAmount)
+	}
+	fee, err := askAmount(c, "fee", "Transaction fee", defaultFee)
+	if err != nil {
+		return err
+	}
+
+	address, err := acc.ProgramHash.ToAddress()
+	if err != nil {
+		return err
+	}
+	balance, err := getBalance(address)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Address:        ", address)
+	fmt.Println("Balance:        ", balance)
+	fmt.Println("Deposit address:", id.depositAddress)
+	if balance < deposit+fee {
+		return fmt.Errorf("balance of %s is not enough, %s needed",
+			address, deposit+fee)
+	}
+
+	txn, err := createTransaction(acc, types.RegisterCR, info, fee,
+		&outputInfo{programHash: id.depositHash, amount: deposit})
+	if err != nil {
+		return err
+	}
+	txn.PayloadVersion = version
+	if err := signAndSendCRInfo(c, client, acc, txn, info); err != nil {
+		return err
+	}
+
+	return waitCandidateState(c, id.cidAddress, "Active")
+}
+
+func updateCR(c *cli.Context) error {
+	client, acc, err := openAccount(c, "publickey")
+	if err != nil {
+		return err
+	}
+	id, err := deriveCRIdentity(acc)
+	if err != nil {
+		return err
+	}
+	current, err := getCandidate(id.cidAddress)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return errors.New("CR candidate not registered")
+	}
+
+	info, version, err := askCRInfo(c, id, current)
+	if err != nil {
+		return err
+	}
+	fee, err := askAmount(c, "fee", "Transaction fee", defaultFee)
+	if err != nil {
+		return err
+	}
+
+	txn, err := createTransaction(acc, types.UpdateCR, info, fee)
+	if err != nil {
+		return err
+	}
+	txn.PayloadVersion = version
+	if err := signAndSendCRInfo(c, client, acc, txn, info); err != nil {
+		return err
+	}
+
+	return waitConfirmed(c, txn.Hash())
+}
+
+func unregisterCR(c *cli.Context) error {
+	client, acc, err := openAccount(c, "publickey")
+	if err != nil {
+		return err
+	}
+	id, err := deriveCRIdentity(acc)
+	if err != nil {
+		return err
+	}
+	current, err := getCandidate(id.cidAddress)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return errors.New("CR candidate not registered")
+	}
+	fee, err := askAmount(c, "fee", "Transaction fee", defaultFee)
+	if err != nil {
+		return err
+	}
+
+	urPayload := &payload.UnregisterCR{CID: id.cid}
+	txn, err := createTransaction(acc, types.UnregisterCR, urPayload, fee)
+	if err != nil {
+		return err
+	}
+	payloadInfo := struct {
+		CID string `json:"cid"`
+	}{CID: id.cidAddress}
+	if !c.Bool("dryrun") {
+		urPayload.Signature, err = signPayload(acc, urPayload,
+			payload.UnregisterCRVersion)
+		if err != nil {
+			return err
+		}
+	}
+	if err := confirmAndSend(c, client, txn, payloadInfo); err != nil {
+		return err
+	}
+
+	return waitCandidateState(c, id.cidAddress, "Canceled")
+}
+
+func returnCRDeposit(c *cli.Context) error {
+	client, acc, err := openAccount(c, "publickey")
+	if err != nil {
+		return err
+	}
+	id, err := deriveCRIdentity(acc)
+	if err != nil {
+		return err
+	}
+	current, err := getCandidate(id.cidAddress)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return errors.New("CR candidate not registered")
+	}
+	if current.State == "Returned" {
+		return errors.New("deposit of the CR candidate is returned before")
+	}
+	fee, err := askAmount(c, "fee", "Transaction fee", defaultFee)
+	if err != nil {
+		return err
+	}
+
+	var deposit struct {
+		Deducted string `json:"deducted"`
+	}
+	err = rpcCall("getcrdepositcoin", http.Params{"id": id.cidAddress},
+		&deposit)
+	if err != nil {
+		return err
+	}
+	penalty, err := common.StringToFixed64(deposit.Deducted)
+	if err != nil {
+		return err
+	}
+	var utxos []servers.UTXOInfo
+	err = rpcCall("listunspent", http.Params{
+		"addresses": []string{id.depositAddress},
+	}, &utxos)
+	if err != nil {
+		return err
+	}
+
+	var txInputs []*types.Input
+	var total common.Fixed64
+	for _, utxo := range utxos {
+		txIDReverse, err := common.HexStringToBytes(utxo.TxID)
+		if err != nil {
+			return err
+		}
+		txID, err := common.Uint256FromBytes(common.BytesReverse(txIDReverse))
+		if err != nil {
+			return err
+		}
+		amount, err := common.StringToFixed64(utxo.Amount)
+		if err != nil {
+			return err
+		}
+		txInputs = append(txInputs, &types.Input{
+			Previous: types.OutPoint{TxID: *txID, Index: uint16(utxo.VOut)},
+			Sequence: 0xffffffff,
+		})
+		total += *amount
+	}
+	fmt.Println("Deposit address:", id.depositAddress)
+	fmt.Println("Deposit:        ", total)
+	fmt.Println("Penalty:        ", penalty)
+	if total <= *penalty+fee {
+		return errors.New("no deposit available to return")
+	}
+
+	txAttr := types.NewAttribute(types.Nonce,
+		[]byte(strconv.FormatInt(rand.Int63(), 10)))
+	txn := &types.Transaction{
+		Version:    types.TxVersion09,
+		TxType:     types.ReturnCRDepositCoin,
+		Payload:    &payload.ReturnDepositCoin{},
+		Attributes: []*types.Attribute{&txAttr},
+		Inputs:     txInputs,
+		Outputs: []*types.Output{{
+			AssetID:     *account.SystemAssetID,
+			Value:       total - *penalty - fee,
+			ProgramHash: acc.ProgramHash,
+			Type:        types.OTNone,
+			Payload:     &outputpayload.DefaultOutput{},
+		}},
+		Programs: []*pg.Program{{Code: id.code}},
+	}
+	if err := confirmAndSend(c, client, txn, struct{}{}); err != nil {
+		return err
+	}
+
+	return waitCandidateState(c, id.cidAddress, "Returned")
+}
+
+func deriveCRIdentity(acc *account.Account) (*crIdentity, error) {
+	hashes, err := contract.DeriveProgramHashes(acc.RedeemScript)
+	if err != nil {
+		return nil, err
+	}
+	cidAddress, err := hashes.CID.ToAddress()
+	if err != nil {
+		return nil, err
+	}
+	didAddress, err := hashes.DID.ToAddress()
+	if err != nil {
+		return nil, err
+	}
+	depositAddress, err := hashes.CRDeposit.ToAddress()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("CID:", cidAddress)
+	fmt.Println("DID:", didAddress)
+
+	return &crIdentity{
+		code:           acc.RedeemScript,
+		cid:            *hashes.CID,
+		did:            *hashes.DID,
+		cidAddress:     cidAddress,
+		depositHash:    *hashes.CRDeposit,
+		depositAddress: depositAddress,
+	}, nil
+}
+
+// askCRInfo asks for the CR candidate information and the payload version,
+// values of the current candidate are used as defaults.
+func askCRInfo(c *cli.Context, id *crIdentity,
+	current *crCandidate) (*payload.CRInfo, byte, error) {
+	nickname, err := askString(c, "nickname", "Nickname", current.NickName)
+	if err != nil {
+		return nil, 0, err
+	}
+	url, err := askString(c, "url", "URL", current.Url)
+	if err != nil {
+		return nil, 0, err
+	}
+	locationStr, err := askString(c, "location", "Location code",
+		strconv.FormatUint(current.Location, 10))
+	if err != nil {
+		return nil, 0, err
+	}
+	location, err := strconv.ParseUint(locationStr, 10, 64)
+	if err != nil {
+		return nil, 0, errors.New("invalid location code")
+	}
+
+	// Payload with DID is supported since RegisterCRByDIDHeight, use the
+	// version without DID if the height is not reached.
+	versionStr, err := askString(c, "payloadversion",
+		"Payload version, 0 without DID or 1 with DID",
+		strconv.Itoa(int(payload.CRInfoDIDVersion)))
+	if err != nil {
+		return nil, 0, err
+	}
+	version, err := strconv.ParseUint(versionStr, 10, 8)
+	if err != nil || byte(version) > payload.CRInfoDIDVersion {
+		return nil, 0, errors.New("invalid payload version")
+	}
+
+	info := &payload.CRInfo{
+		Code:     id.code,
+		CID:      id.cid,
+		NickName: nickname,
+		Url:      url,
+		Location: location,
+	}
+	if byte(version) == payload.CRInfoDIDVersion {
+		info.DID = id.did
+	}
+	return info, byte(version), nil
+}
+
+// signAndSendCRInfo prints the CR info payload before signing, then signs the
+// payload and sends the transaction after the user confirms.
+func signAndSendCRInfo(c *cli.Context, client *account.Client,
+	acc *account.Account, txn *types.Transaction, info *payload.CRInfo) error {
+	cidAddress, err := info.CID.ToAddress()
+	if err != nil {
+		return err
+	}
+	// The payload is printed before signing.
+	payloadInfo := &crInfo{
+		Code:     common.BytesToHexString(info.Code),
+		CID:      cidAddress,
+		NickName: info.NickName,
+		Url:      info.Url,
+		Location: info.Location,
+	}
+	if txn.PayloadVersion == payload.CRInfoDIDVersion {
+		payloadInfo.DID, err = info.DID.ToAddress()
+		if err != nil {
+			return err
+		}
+	}
+	if !c.Bool("dryrun") {
+		info.Signature, err = signPayload(acc, info, txn.PayloadVersion)
+		if err != nil {
+			return err
+		}
+	}
+
+	return confirmAndSend(c, client, txn, payloadInfo)
+}
+
+// getCandidate returns the CR candidate of the CID, or nil if the candidate
+// is not registered.
+func getCandidate(cid string) (*crCandidate, error) {
+	var result struct {
+		Candidates []crCandidate `json:"crcandidatesinfo"`
+	}
+	err := rpcCall("listcrcandidates", http.Params{"state": "all"}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range result.Candidates {
+		if candidate.CID == cid {
+			return &candidate, nil
+		}
+	}
+	return nil, nil
+}
+
+// waitCandidateState polls the CR candidate state until it changes to the
+// given state or the wait flag times out.
+func waitCandidateState(c *cli.Context, cid string, state string) error {
+	return poll(c, "CR candidate "+state, func() (bool, error) {
+		candidate, err := getCandidate(cid)
+		if err != nil || candidate == nil {
+			// The candidate is unknown before the transaction confirmed.
+			return false, nil
+		}
+		fmt.Println("CR candidate state:", candidate.State)
+		return candidate.State == state, nil
+	})
+}
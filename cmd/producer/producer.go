@@ -0,0 +1,433 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package producer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/elastos/Elastos.ELA/account"
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+
+	"github.com/urfave/cli"
+)
+
+const (
+	// minDepositAmount is the minimum deposit as a producer, it is the same
+	// as blockchain.MinDepositAmount.
+	minDepositAmount = common.Fixed64(5000 * 100000000)
+
+	// defaultFee is the transaction fee used if the user does not specify.
+	defaultFee = "0.0001"
+)
+
+// NewCommand returns the producer command, which walks operators through
+// registering, updating, activating and canceling a producer.
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "producer",
+		Usage:       "Register, update, activate or cancel a producer",
+		Description: "With ela-cli producer, you could operate your producer step by step, values not given by flags are asked interactively",
+		ArgsUsage:   "[args]",
+		Subcommands: []cli.Command{
+			{
+				Name:  "register",
+				Usage: "Register a producer and wait until it is active",
+				Flags: []cli.Flag{
+					cmdcom.ProducerOwnerPublicKeyFlag,
+					cmdcom.ProducerNodePublicKeyFlag,
+					cmdcom.ProducerNickNameFlag,
+					cmdcom.ProducerURLFlag,
+					cmdcom.ProducerLocationFlag,
+					cmdcom.ProducerNetAddressFlag,
+					cmdcom.ProducerDepositFlag,
+					cmdcom.TransactionFeeFlag,
+					cmdcom.ProducerWaitFlag,
+					cmdcom.TransactionDryRunFlag,
+					cmdcom.AccountWalletFlag,
+					cmdcom.AccountPasswordFlag,
+				},
+				Action: registerProducer,
+			},
+			{
+				Name:  "update",
+				Usage: "Update the information of a registered producer",
+				Flags: []cli.Flag{
+					cmdcom.ProducerOwnerPublicKeyFlag,
+					cmdcom.ProducerNodePublicKeyFlag,
+					cmdcom.ProducerNickNameFlag,
+					cmdcom.ProducerURLFlag,
+					cmdcom.ProducerLocationFlag,
+					cmdcom.ProducerNetAddressFlag,
+					cmdcom.TransactionFeeFlag,
+					cmdcom.ProducerWaitFlag,
+					cmdcom.TransactionDryRunFlag,
+					cmdcom.AccountWalletFlag,
+					cmdcom.AccountPasswordFlag,
+				},
+				Action: updateProducer,
+			},
+			{
+				Name:  "activate",
+				Usage: "Activate an inactive producer and wait until it is active",
+				Flags: []cli.Flag{
+					cmdcom.ProducerOwnerPublicKeyFlag,
+					cmdcom.ProducerNodePublicKeyFlag,
+					cmdcom.ProducerWaitFlag,
+					cmdcom.TransactionDryRunFlag,
+					cmdcom.AccountWalletFlag,
+					cmdcom.AccountPasswordFlag,
+				},
+				Action: activateProducer,
+			},
+			{
+				Name:  "cancel",
+				Usage: "Cancel a producer and wait until it is canceled",
+				Flags: []cli.Flag{
+					cmdcom.ProducerOwnerPublicKeyFlag,
+					cmdcom.TransactionFeeFlag,
+					cmdcom.ProducerWaitFlag,
+					cmdcom.TransactionDryRunFlag,
+					cmdcom.AccountWalletFlag,
+					cmdcom.AccountPasswordFlag,
+				},
+				Action: cancelProducer,
+			},
+		},
+	}
+}
+
+func registerProducer(c *cli.Context) error {
+	client, owner, err := openAccount(c, "ownerpublickey")
+	if err != nil {
+		return err
+	}
+	info, err := askProducerInfo(c, owner, nil)
+	if err != nil {
+		return err
+	}
+	deposit, err := askAmount(c, "deposit", "Deposit amount",
+		minDepositAmount.String())
+	if err != nil {
+		return err
+	}
+	if deposit < minDepositAmount {
+		return fmt.Errorf("deposit should not be less than %s",
+			minDepositAmount)
+	}
+	fee, err := askAmount(c, "fee", "Transaction fee", defaultFee)
+	if err != nil {
+		return err
+	}
+
+	depositHash, err := contract.PublicKeyToDepositProgramHash(
+		info.OwnerPublicKey)
+	if err != nil {
+		return err
+	}
+	depositAddress, err := depositHash.ToAddress()
+	if err != nil {
+		return err
+	}
+	ownerAddress, err := owner.ProgramHash.ToAddress()
+	if err != nil {
+		return err
+	}
+	balance, err := getBalance(ownerAddress)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Owner address:  ", ownerAddress)
+	fmt.Println("Balance:        ", balance)
+	fmt.Println("Deposit address:", depositAddress)
+	if balance < deposit+fee {
+		return fmt.Errorf("balance of %s is not enough, %s needed",
+			ownerAddress, deposit+fee)
+	}
+
+	if err := signProducerInfo(owner, info); err != nil {
+		return err
+	}
+	txn, err := createTransaction(owner, types.RegisterProducer, info, fee,
+		&outputInfo{programHash: *depositHash, amount: deposit})
+	if err != nil {
+		return err
+	}
+	if err := confirmAndSend(c, client, txn, nil); err != nil {
+		return err
+	}
+
+	return waitState(c, info.OwnerPublicKey, "Active")
+}
+
+func updateProducer(c *cli.Context) error {
+	client, owner, err := openAccount(c, "ownerpublickey")
+	if err != nil {
+		return err
+	}
+	ownerPublicKey, err := owner.PublicKey.EncodePoint(true)
+	if err != nil {
+		return err
+	}
+	current, err := getProducer(ownerPublicKey)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return errors.New("producer not registered")
+	}
+	info, err := askProducerInfo(c, owner, current)
+	if err != nil {
+		return err
+	}
+	fee, err := askAmount(c, "fee", "Transaction fee", defaultFee)
+	if err != nil {
+		return err
+	}
+
+	if err := signProducerInfo(owner, info); err != nil {
+		return err
+	}
+	txn, err := createTransaction(owner, types.UpdateProducer, info, fee)
+	if err != nil {
+		return err
+	}
+	if err := confirmAndSend(c, client, txn, nil); err != nil {
+		return err
+	}
+
+	return waitConfirmed(c, txn.Hash())
+}
+
+func activateProducer(c *cli.Context) error {
+	client, owner, err := openAccount(c, "ownerpublickey")
+	if err != nil {
+		return err
+	}
+	ownerPublicKey, err := owner.PublicKey.EncodePoint(true)
+	if err != nil {
+		return err
+	}
+	current, err := getProducer(ownerPublicKey)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return errors.New("producer not registered")
+	}
+	if current.State != "Inactive" && current.State != "Illegal" {
+		return fmt.Errorf("producer is %s, only inactive or illegal "+
+			"producer can be activated", current.State)
+	}
+
+	nodePublicKeyStr, err := askString(c, "nodepublickey",
+		"Node public key", current.NodePublicKey)
+	if err != nil {
+		return err
+	}
+	nodePublicKey, err := common.HexStringToBytes(nodePublicKeyStr)
+	if err != nil {
+		return errors.New("invalid node public key")
+	}
+	codeHash, err := contract.PublicKeyToStandardCodeHash(nodePublicKey)
+	if err != nil {
+		return err
+	}
+	node := client.GetAccountByCodeHash(*codeHash)
+	if node == nil {
+		return errors.New("node account not found in wallet")
+	}
+
+	apPayload := &payload.ActivateProducer{NodePublicKey: nodePublicKey}
+	apPayload.Signature, err = signPayload(node, apPayload,
+		payload.ActivateProducerVersion)
+	if err != nil {
+		return err
+	}
+	txn := &types.Transaction{
+		Version: types.TxVersion09,
+		TxType:  types.ActivateProducer,
+		Payload: apPayload,
+	}
+	if err := confirmAndSend(c, client, txn, nil); err != nil {
+		return err
+	}
+
+	return waitState(c, ownerPublicKey, "Active")
+}
+
+func cancelProducer(c *cli.Context) error {
+	client, owner, err := openAccount(c, "ownerpublickey")
+	if err != nil {
+		return err
+	}
+	ownerPublicKey, err := owner.PublicKey.EncodePoint(true)
+	if err != nil {
+		return err
+	}
+	current, err := getProducer(ownerPublicKey)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return errors.New("producer not registered")
+	}
+	fee, err := askAmount(c, "fee", "Transaction fee", defaultFee)
+	if err != nil {
+		return err
+	}
+
+	ppPayload := &payload.ProcessProducer{OwnerPublicKey: ownerPublicKey}
+	ppPayload.Signature, err = signPayload(owner, ppPayload,
+		payload.ProcessProducerVersion)
+	if err != nil {
+		return err
+	}
+	txn, err := createTransaction(owner, types.CancelProducer, ppPayload, fee)
+	if err != nil {
+		return err
+	}
+	if err := confirmAndSend(c, client, txn, nil); err != nil {
+		return err
+	}
+
+	if err := waitState(c, ownerPublicKey, "Canceled"); err != nil {
+		return err
+	}
+	if c.Bool("dryrun") {
+		return nil
+	}
+	deposit, err := getDepositCoin(ownerPublicKey)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Deposit available:", deposit)
+	return nil
+}
+
+// openAccount opens the wallet and returns the standard account of the public
+// key specified by the flag, or the main account if not specified.
+func openAccount(c *cli.Context, flag string) (*account.Client,
+	*account.Account, error) {
+	walletPath := cmdcom.GetWalletPath(c)
+	password, err := cmdcom.GetFlagPassword(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := account.Open(walletPath, password)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	acc := client.GetMainAccount()
+	if publicKeyStr := c.String(flag); publicKeyStr != "" {
+		publicKey, err := common.HexStringToBytes(publicKeyStr)
+		if err != nil {
+			return nil, nil, errors.New("invalid public key")
+		}
+		codeHash, err := contract.PublicKeyToStandardCodeHash(publicKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		acc = client.GetAccountByCodeHash(*codeHash)
+		if acc == nil {
+			return nil, nil, errors.New("account not found in wallet")
+		}
+	}
+	if contract.GetPrefixType(acc.ProgramHash) != contract.PrefixStandard {
+		return nil, nil, errors.New("account is not a standard account")
+	}
+
+	return client, acc, nil
+}
+
+// askProducerInfo asks for the producer information, values of the current
+// registered producer are used as defaults if present.
+func askProducerInfo(c *cli.Context, owner *account.Account,
+	current *producerStatus) (*payload.ProducerInfo, error) {
+	ownerPublicKey, err := owner.PublicKey.EncodePoint(true)
+	if err != nil {
+		return nil, err
+	}
+	defaults := producerStatus{
+		NodePublicKey: common.BytesToHexString(ownerPublicKey),
+	}
+	if current != nil {
+		defaults = *current
+	}
+
+	nodePublicKeyStr, err := askString(c, "nodepublickey",
+		"Node public key", defaults.NodePublicKey)
+	if err != nil {
+		return nil, err
+	}
+	nodePublicKey, err := common.HexStringToBytes(nodePublicKeyStr)
+	if err != nil {
+		return nil, errors.New("invalid node public key")
+	}
+	nickname, err := askString(c, "nickname", "Nickname",
+		defaults.NickName)
+	if err != nil {
+		return nil, err
+	}
+	url, err := askString(c, "url", "URL", defaults.Url)
+	if err != nil {
+		return nil, err
+	}
+	locationStr, err := askString(c, "location", "Location code",
+		strconv.FormatUint(defaults.Location, 10))
+	if err != nil {
+		return nil, err
+	}
+	location, err := strconv.ParseUint(locationStr, 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid location code")
+	}
+	netAddress, err := askString(c, "netaddress", "Net address", "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.ProducerInfo{
+		OwnerPublicKey: ownerPublicKey,
+		NodePublicKey:  nodePublicKey,
+		NickName:       nickname,
+		Url:            url,
+		Location:       location,
+		NetAddress:     netAddress,
+	}, nil
+}
+
+func signProducerInfo(owner *account.Account,
+	info *payload.ProducerInfo) error {
+	signature, err := signPayload(owner, info, payload.ProducerInfoVersion)
+	if err != nil {
+		return err
+	}
+	info.Signature = signature
+	return nil
+}
+
+// unsignedPayload is the payload signed by the producer keys.
+type unsignedPayload interface {
+	SerializeUnsigned(w io.Writer, version byte) error
+}
+
+func signPayload(acc *account.Account, p unsignedPayload,
+	version byte) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := p.SerializeUnsigned(buf, version); err != nil {
+		return nil, err
+	}
+	return acc.Sign(buf.Bytes())
+}
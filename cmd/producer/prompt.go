@@ -0,0 +1,77 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package producer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/elastos/Elastos.ELA/common"
+
+	"github.com/urfave/cli"
+)
+
+var stdin = bufio.NewReader(os.Stdin)
+
+// readLine reads a line from user input with the leading and trailing spaces
+// removed.
+func readLine(prompt string) (string, error) {
+	fmt.Print(prompt)
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// askString returns the value of the flag, or asks the user for it if the
+// flag is not given. The default value is used if the user enters nothing.
+func askString(c *cli.Context, flag, name, defaultValue string) (string,
+	error) {
+	if value := c.String(flag); value != "" {
+		return value, nil
+	}
+
+	prompt := name + ": "
+	if defaultValue != "" {
+		prompt = fmt.Sprintf("%s [%s]: ", name, defaultValue)
+	}
+	value, err := readLine(prompt)
+	if err != nil {
+		return "", err
+	}
+	if value == "" {
+		return defaultValue, nil
+	}
+	return value, nil
+}
+
+// askAmount is like askString but parses the value as an ELA amount.
+func askAmount(c *cli.Context, flag, name, defaultValue string) (
+	common.Fixed64, error) {
+	value, err := askString(c, flag, name, defaultValue)
+	if err != nil {
+		return 0, err
+	}
+	amount, err := common.StringToFixed64(value)
+	if err != nil || *amount < 0 {
+		return 0, errors.New("invalid " + strings.ToLower(name))
+	}
+	return *amount, nil
+}
+
+// confirm asks the user a yes or no question, the answer defaults to no.
+func confirm(question string) (bool, error) {
+	answer, err := readLine(question + " [y/N]: ")
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes", nil
+}
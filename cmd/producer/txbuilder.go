@@ -0,0 +1,324 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package producer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/account"
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/common"
+	pg "github.com/elastos/Elastos.ELA/core/contract/program"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+	"github.com/elastos/Elastos.ELA/servers"
+	"github.com/elastos/Elastos.ELA/utils/http"
+
+	"github.com/urfave/cli"
+)
+
+// pollInterval is the interval to poll the node while waiting for the
+// producer state to change.
+const pollInterval = 10 * time.Second
+
+type outputInfo struct {
+	programHash common.Uint168
+	amount      common.Fixed64
+}
+
+// producerStatus is the producer information returned by listproducers.
+type producerStatus struct {
+	OwnerPublicKey string `json:"ownerpublickey"`
+	NodePublicKey  string `json:"nodepublickey"`
+	NickName       string `json:"nickname"`
+	Url            string `json:"url"`
+	Location       uint64 `json:"location"`
+	State          string `json:"state"`
+}
+
+// rpcCall calls the node and unmarshals the result into v.
+func rpcCall(method string, params http.Params, v interface{}) error {
+	result, err := cmdcom.RPCCall(method, params)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func getBalance(address string) (common.Fixed64, error) {
+	var utxos []servers.UTXOInfo
+	err := rpcCall("listunspent", http.Params{
+		"addresses": []string{address},
+	}, &utxos)
+	if err != nil {
+		return 0, err
+	}
+
+	var balance common.Fixed64
+	for _, utxo := range utxos {
+		if types.TxType(utxo.TxType) == types.CoinBase &&
+			utxo.Confirmations < 101 {
+			continue
+		}
+		amount, err := common.StringToFixed64(utxo.Amount)
+		if err != nil {
+			return 0, err
+		}
+		balance += *amount
+	}
+	return balance, nil
+}
+
+// getProducer returns the producer of the owner public key, or nil if the
+// producer is not registered.
+func getProducer(ownerPublicKey []byte) (*producerStatus, error) {
+	var result struct {
+		Producers []producerStatus `json:"producers"`
+	}
+	err := rpcCall("listproducers", http.Params{"state": "all"}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := common.BytesToHexString(ownerPublicKey)
+	for _, p := range result.Producers {
+		if p.OwnerPublicKey == owner {
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+func getDepositCoin(ownerPublicKey []byte) (string, error) {
+	var result struct {
+		Available string `json:"available"`
+	}
+	err := rpcCall("getdepositcoin", http.Params{
+		"ownerpublickey": common.BytesToHexString(ownerPublicKey),
+	}, &result)
+	return result.Available, err
+}
+
+// createTransaction creates a transaction paid by the owner account with the
+// given payload and outputs.
+func createTransaction(owner *account.Account, txType types.TxType,
+	payload types.Payload, fee common.Fixed64,
+	outputs ...*outputInfo) (*types.Transaction, error) {
+	totalAmount := fee
+	var txOutputs []*types.Output
+	for _, o := range outputs {
+		txOutputs = append(txOutputs, &types.Output{
+			AssetID:     *account.SystemAssetID,
+			Value:       o.amount,
+			ProgramHash: o.programHash,
+			Type:        types.OTNone,
+			Payload:     &outputpayload.DefaultOutput{},
+		})
+		totalAmount += o.amount
+	}
+
+	txInputs, changeOutputs, err := createInputs(owner, totalAmount)
+	if err != nil {
+		return nil, err
+	}
+	txOutputs = append(txOutputs, changeOutputs...)
+
+	txAttr := types.NewAttribute(types.Nonce,
+		[]byte(strconv.FormatInt(rand.Int63(), 10)))
+	return &types.Transaction{
+		Version:    types.TxVersion09,
+		TxType:     txType,
+		Payload:    payload,
+		Attributes: []*types.Attribute{&txAttr},
+		Inputs:     txInputs,
+		Outputs:    txOutputs,
+		Programs:   []*pg.Program{{Code: owner.RedeemScript}},
+	}, nil
+}
+
+func createInputs(owner *account.Account,
+	totalAmount common.Fixed64) ([]*types.Input, []*types.Output, error) {
+	address, err := owner.ProgramHash.ToAddress()
+	if err != nil {
+		return nil, nil, err
+	}
+	var utxos []servers.UTXOInfo
+	err = rpcCall("getutxosbyamount", http.Params{
+		"address": address,
+		"amount":  totalAmount.String(),
+	}, &utxos)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var txInputs []*types.Input
+	var changeOutputs []*types.Output
+	for _, utxo := range utxos {
+		txIDReverse, err := common.HexStringToBytes(utxo.TxID)
+		if err != nil {
+			return nil, nil, err
+		}
+		txID, err := common.Uint256FromBytes(common.BytesReverse(txIDReverse))
+		if err != nil {
+			return nil, nil, err
+		}
+		sequence := uint32(math.MaxUint32)
+		if utxo.OutputLock > 0 {
+			sequence = math.MaxUint32 - 1
+		}
+		txInputs = append(txInputs, &types.Input{
+			Previous: types.OutPoint{TxID: *txID, Index: uint16(utxo.VOut)},
+			Sequence: sequence,
+		})
+		amount, err := common.StringToFixed64(utxo.Amount)
+		if err != nil {
+			return nil, nil, err
+		}
+		if *amount < totalAmount {
+			totalAmount -= *amount
+			continue
+		}
+		if *amount > totalAmount {
+			changeOutputs = append(changeOutputs, &types.Output{
+				AssetID:     *account.SystemAssetID,
+				Value:       *amount - totalAmount,
+				ProgramHash: owner.ProgramHash,
+				Type:        types.OTNone,
+				Payload:     &outputpayload.DefaultOutput{},
+			})
+		}
+		totalAmount = 0
+		break
+	}
+	if totalAmount > 0 {
+		return nil, nil, errors.New("available token is not enough")
+	}
+
+	return txInputs, changeOutputs, nil
+}
+
+// confirmAndSend prints the constructed transaction, then signs and sends it
+// to the node after the user confirms. The payload info is printed instead of
+// the payload if not nil. Nothing is signed or sent in dry run mode.
+func confirmAndSend(c *cli.Context, client *account.Client,
+	txn *types.Transaction, payloadInfo interface{}) error {
+	if payloadInfo == nil {
+		payloadInfo = servers.GetTransactionInfo(txn).Payload
+	}
+	data, err := json.MarshalIndent(payloadInfo, "", "    ")
+	if err != nil {
+		return err
+	}
+	fmt.Println("Transaction type:", txn.TxType.Name())
+	fmt.Println("Payload version: ", txn.PayloadVersion)
+	fmt.Println("Payload:", string(data))
+	for _, output := range txn.Outputs {
+		address, err := output.ProgramHash.ToAddress()
+		if err != nil {
+			return err
+		}
+		fmt.Println("Output:", address, output.Value)
+	}
+	if c.Bool("dryrun") {
+		fmt.Println("Dry run, the transaction is not signed or sent")
+		return nil
+	}
+
+	ok, err := confirm("Send the transaction?")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("transaction canceled by user")
+	}
+
+	txn, err = client.Sign(txn)
+	if err != nil {
+		return err
+	}
+	buf := new(bytes.Buffer)
+	if err := txn.Serialize(buf); err != nil {
+		return err
+	}
+	result, err := cmdcom.RPCCall("sendrawtransaction", http.Params{
+		"data": common.BytesToHexString(buf.Bytes()),
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println("Transaction sent:", result)
+	return nil
+}
+
+// waitState polls the producer state until it changes to the given state or
+// the wait flag times out.
+func waitState(c *cli.Context, publicKey []byte, state string) error {
+	return poll(c, "producer "+state, func() (bool, error) {
+		var current string
+		err := rpcCall("producerstatus", http.Params{
+			"publickey": common.BytesToHexString(publicKey),
+		}, &current)
+		if err != nil {
+			// The producer is unknown before the transaction confirmed.
+			return false, nil
+		}
+		fmt.Println("Producer state:", current)
+		return current == state, nil
+	})
+}
+
+// waitConfirmed polls the transaction until it is packed into a block or the
+// wait flag times out.
+func waitConfirmed(c *cli.Context, txID common.Uint256) error {
+	return poll(c, "transaction confirmed", func() (bool, error) {
+		var tx struct {
+			Confirmations uint32 `json:"confirmations"`
+		}
+		err := rpcCall("getrawtransaction", http.Params{
+			"txid":    txID.String(),
+			"verbose": true,
+		}, &tx)
+		if err != nil {
+			return false, err
+		}
+		return tx.Confirmations > 0, nil
+	})
+}
+
+func poll(c *cli.Context, target string, done func() (bool, error)) error {
+	wait := c.Int("wait")
+	if wait <= 0 || c.Bool("dryrun") {
+		return nil
+	}
+
+	fmt.Println("Waiting for", target, "...")
+	deadline := time.Now().Add(time.Duration(wait) * time.Second)
+	for {
+		ok, err := done()
+		if err != nil {
+			return err
+		}
+		if ok {
+			fmt.Println("Done,", target)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for %s", target)
+		}
+		time.Sleep(pollInterval)
+	}
+}
@@ -53,6 +53,22 @@ var exports = map[string]lua.LGFunction{
 	"get_dir_all_files": getDirAllFiles,
 	"get_standard_addr": getStandardAddr,
 	"output_tx":         outputTx,
+	// node rpc
+	"get_block_count":     getBlockCount,
+	"get_raw_transaction": getRawTransaction,
+	"list_producers":      listProducers,
+	// node control
+	"start_node":    startNode,
+	"stop_node":     stopNode,
+	"mine_blocks":   mineBlocks,
+	"flush_mempool": flushMempool,
+	"rollback_node": rollbackNode,
+	// async node interactions
+	"send_tx_async":     sendTxAsync,
+	"wait_tx_async":     waitTxAsync,
+	"wait_height_async": waitHeightAsync,
+	"wait_all":          waitAll,
+	"wait_any":          waitAny,
 }
 
 func outputTx(L *lua.LState) int {
@@ -173,7 +189,8 @@ func initLedger(L *lua.LState) int {
 	dlog.Init(logLevel, 0, 0)
 
 	ledger := blockchain.Ledger{}
-	chainStore, err := blockchain.NewChainStore(test.DataPath, chainParams.GenesisBlock)
+	chainStore, err := blockchain.NewChainStore(test.DataPath,
+		blockchain.LevelDBEngine, chainParams.GenesisBlock)
 	if err != nil {
 		fmt.Printf("Init chain store error: %s \n", err.Error())
 	}
@@ -276,5 +293,12 @@ func RegisterDataType(L *lua.LState) int {
 	RegisterRegisterCRType(L)
 	RegisterUpdateCRType(L)
 	RegisterUnregisterCRType(L)
+	RegisterCustomIDProposalType(L)
+	RegisterUpdateVersionType(L)
+	RegisterCRCRewardAddressType(L)
+	RegisterProducerAppealType(L)
+	RegisterPayloadVersions(L)
+	RegisterFixturesType(L)
+	RegisterFutureType(L)
 	return 0
 }
@@ -6,7 +6,6 @@
 package api
 
 import (
-	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/dpos/state"
 
 	"github.com/yuin/gopher-lua"
@@ -18,22 +17,11 @@ const (
 )
 
 var (
-	arbitratorsPublicKeys = []string{
-		"023a133480176214f88848c6eaa684a54b316849df2b8570b57f3a917f19bbc77a",
-		"030a26f8b4ab0ea219eb461d1e454ce5f0bd0d289a6a64ffc0743dab7bd5be0be9",
-		"0288e79636e41edce04d4fa95d8f62fed73a76164f8631ccc42f5425f960e4a0c7",
-		"03e281f89d85b3a7de177c240c4961cb5b1f2106f09daa42d15874a38bbeae85dd",
-		"0393e823c2087ed30871cbea9fa5121fa932550821e9f3b17acef0e581971efab0",
-		"0382c9e7f11c01d3bde067d006dc2e7b2378499b543a790454d5603e338911ca29",
-	}
-	arbitratorsPrivateKeys = []string{
-		"e372ca1032257bb4be1ac99c4861ec542fd55c25c37f5f58ba8b177850b3fdeb",
-		"e6deed7e23406e2dce7b01e85bcb33872a47b6200ca983fcf0540dff284923b0",
-		"4441968d02a5df4dbc08ca11da2acc86c980e5fe9ff250450a80fd7421d2b0f1",
-		"0b14a04e203301809feccc61dbf4e745203a3263d29a4b4091aaa138ba5fb26d",
-		"0c11ebca60af2a09ac13dd84fd29c03b99cd086a08a69a9e5b87255fd9cf2eee",
-		"ad44a6d5a5d1f7cafa2fa82c719108e9814ff5c71078e1cafa9f734343a2f806",
-	}
+	// arbitratorsKeyPairs are key pairs of arbiters A to F in white box tests.
+	arbitratorsKeyPairs = fixtureKeyPairs([]string{
+		"arbiter A", "arbiter B", "arbiter C",
+		"arbiter D", "arbiter E", "arbiter F",
+	})
 )
 
 func RegisterArbitratorsType(L *lua.LState) {
@@ -48,8 +36,8 @@ func RegisterArbitratorsType(L *lua.LState) {
 // Constructor
 func newArbitrators(L *lua.LState) int {
 	arbitersByte := make([][]byte, 0)
-	for _, arbiter := range arbitratorsPublicKeys {
-		arbiterByte, _ := common.HexStringToBytes(arbiter)
+	for _, pair := range arbitratorsKeyPairs {
+		arbiterByte, _ := pair.publicKey.EncodePoint(true)
 		arbitersByte = append(arbitersByte, arbiterByte)
 	}
 
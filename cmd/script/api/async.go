@@ -0,0 +1,314 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package api
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/utils/http"
+
+	"github.com/yuin/gopher-lua"
+)
+
+const (
+	luaFutureTypeName = "future"
+
+	// defaultAsyncTimeout is the default seconds to wait for a confirmation
+	// or a height.
+	defaultAsyncTimeout = 60
+
+	// pollInterval is the interval to poll the node while waiting for a
+	// confirmation or a height.
+	pollInterval = time.Second
+)
+
+// errWaitTimeout is returned by waiting methods of futures if the future is
+// not done in time.
+var errWaitTimeout = errors.New("timeout")
+
+// future is the result of a node interaction running in background, the
+// lua state is never touched by the background goroutine, the result is
+// converted to lua value when read by the script.
+type future struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// newFuture runs the function in background and returns the future of its
+// result.
+func newFuture(fn func() (interface{}, error)) *future {
+	f := &future{done: make(chan struct{})}
+	go func() {
+		f.value, f.err = fn()
+		close(f.done)
+	}()
+	return f
+}
+
+// isDone returns if the future is done without blocking.
+func (f *future) isDone() bool {
+	select {
+	case <-f.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// push pushes the value and the error message of the done future, the value
+// is nil if failed and the error message is nil if succeeded.
+func (f *future) push(L *lua.LState) int {
+	if f.err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(f.err.Error()))
+		return 2
+	}
+	L.Push(toLuaValue(L, f.value))
+	L.Push(lua.LNil)
+	return 2
+}
+
+func RegisterFutureType(L *lua.LState) {
+	mt := L.NewTypeMetatable(luaFutureTypeName)
+	L.SetGlobal("future", mt)
+	// methods
+	L.SetField(mt, "__index", L.SetFuncs(L.NewTable(), futureMethods))
+}
+
+func newFutureUserData(L *lua.LState, f *future) *lua.LUserData {
+	ud := L.NewUserData()
+	ud.Value = f
+	L.SetMetatable(ud, L.GetTypeMetatable(luaFutureTypeName))
+	return ud
+}
+
+// Checks whether the lua argument is a *LUserData with *future and returns
+// this *future.
+func checkFuture(L *lua.LState, idx int) *future {
+	ud := L.CheckUserData(idx)
+	if v, ok := ud.Value.(*future); ok {
+		return v
+	}
+	L.ArgError(idx, "future expected")
+	return nil
+}
+
+// checkFutures returns the futures in the table of the lua argument.
+func checkFutures(L *lua.LState, idx int) []*future {
+	table := L.CheckTable(idx)
+	futures := make([]*future, 0, table.Len())
+	for i := 1; i <= table.Len(); i++ {
+		ud, ok := table.RawGetInt(i).(*lua.LUserData)
+		if !ok {
+			L.ArgError(idx, "future array expected")
+		}
+		f, ok := ud.Value.(*future)
+		if !ok {
+			L.ArgError(idx, "future array expected")
+		}
+		futures = append(futures, f)
+	}
+	return futures
+}
+
+// optTimeout returns the timeout in seconds of the lua argument, zero means
+// no timeout.
+func optTimeout(L *lua.LState, idx int, def int) <-chan time.Time {
+	seconds := L.OptNumber(idx, lua.LNumber(def))
+	if seconds <= 0 {
+		return nil
+	}
+	return time.After(time.Duration(float64(seconds) * float64(time.Second)))
+}
+
+var futureMethods = map[string]lua.LGFunction{
+	"ready": futureReady,
+	"wait":  futureWait,
+}
+
+// futureReady returns if the future is done without blocking, so scripts can
+// poll futures in coroutines.
+func futureReady(L *lua.LState) int {
+	f := checkFuture(L, 1)
+	L.Push(lua.LBool(f.isDone()))
+	return 1
+}
+
+// futureWait waits until the future is done and returns the value and the
+// error message.  The parameter is timeout in seconds, it's optional and
+// waits forever if not given.
+func futureWait(L *lua.LState) int {
+	f := checkFuture(L, 1)
+	select {
+	case <-f.done:
+		return f.push(L)
+	case <-optTimeout(L, 2, 0):
+		L.Push(lua.LNil)
+		L.Push(lua.LString(errWaitTimeout.Error()))
+		return 2
+	}
+}
+
+// waitAll waits until all the futures are done, and returns the table of
+// values and the table of error messages in order of the futures.  The
+// parameters are futures and timeout in seconds, timeout is optional.
+func waitAll(L *lua.LState) int {
+	futures := checkFutures(L, 1)
+	timeout := optTimeout(L, 2, 0)
+
+	values := L.NewTable()
+	errs := L.NewTable()
+	for i, f := range futures {
+		select {
+		case <-f.done:
+		case <-timeout:
+			timeout = closedTimeout
+		}
+		if !f.isDone() {
+			values.RawSetInt(i+1, lua.LNil)
+			errs.RawSetInt(i+1, lua.LString(errWaitTimeout.Error()))
+			continue
+		}
+		if f.err != nil {
+			errs.RawSetInt(i+1, lua.LString(f.err.Error()))
+			continue
+		}
+		values.RawSetInt(i+1, toLuaValue(L, f.value))
+	}
+	L.Push(values)
+	L.Push(errs)
+	return 2
+}
+
+// closedTimeout is a timeout channel already fired, it's used once the
+// timeout of waiting several futures is reached.
+var closedTimeout = func() <-chan time.Time {
+	c := make(chan time.Time)
+	close(c)
+	return c
+}()
+
+// waitAny waits until any of the futures is done, and returns the index of
+// the future, the value and the error message.  The parameters are futures
+// and timeout in seconds, timeout is optional.  Index is nil if timeout.
+func waitAny(L *lua.LState) int {
+	futures := checkFutures(L, 1)
+	if len(futures) == 0 {
+		L.ArgError(1, "no future to wait")
+	}
+
+	cases := make([]reflect.SelectCase, 0, len(futures)+1)
+	for _, f := range futures {
+		cases = append(cases, reflect.SelectCase{
+			Dir: reflect.SelectRecv, Chan: reflect.ValueOf(f.done)})
+	}
+	if timeout := optTimeout(L, 2, 0); timeout != nil {
+		cases = append(cases, reflect.SelectCase{
+			Dir: reflect.SelectRecv, Chan: reflect.ValueOf(timeout)})
+	}
+
+	chosen, _, _ := reflect.Select(cases)
+	if chosen == len(futures) {
+		L.Push(lua.LNil)
+		L.Push(lua.LNil)
+		L.Push(lua.LString(errWaitTimeout.Error()))
+		return 3
+	}
+	L.Push(lua.LNumber(chosen + 1))
+	return futures[chosen].push(L) + 1
+}
+
+// sendTxAsync sends the transaction in background and returns the future of
+// the transaction hash, transactions rejected by the node results in error
+// instead of exiting, so scripts can race transactions.
+func sendTxAsync(L *lua.LState) int {
+	txn := checkTransaction(L, 1)
+
+	var buffer bytes.Buffer
+	if err := txn.Serialize(&buffer); err != nil {
+		L.RaiseError("serialize transaction failed: %s", err)
+	}
+	txHex := hex.EncodeToString(buffer.Bytes())
+
+	L.Push(newFutureUserData(L, newFuture(func() (interface{}, error) {
+		return cmdcom.RPCCall("sendrawtransaction", http.Params{
+			"data": txHex,
+		})
+	})))
+	return 1
+}
+
+// waitTxAsync returns the future of the transaction confirmed by the node,
+// the value is the transaction in table.  The parameters are transaction
+// hash, confirmations and timeout in seconds, confirmations and timeout are
+// optional.
+func waitTxAsync(L *lua.LState) int {
+	txID := L.CheckString(1)
+	confirmations := float64(L.OptInt(2, 1))
+	timeout := optTimeout(L, 3, defaultAsyncTimeout)
+
+	L.Push(newFutureUserData(L, newFuture(func() (interface{}, error) {
+		return poll(timeout, func() (interface{}, bool) {
+			result, err := cmdcom.RPCCall("getrawtransaction", http.Params{
+				"txid":    txID,
+				"verbose": true,
+			})
+			if err != nil {
+				return nil, false
+			}
+			tx, ok := result.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			count, _ := tx["confirmations"].(float64)
+			return tx, count >= confirmations
+		})
+	})))
+	return 1
+}
+
+// waitHeightAsync returns the future of the node reaching the height, the
+// value is the current height.  The parameters are height and timeout in
+// seconds, timeout is optional.
+func waitHeightAsync(L *lua.LState) int {
+	height := float64(L.CheckInt(1))
+	timeout := optTimeout(L, 2, defaultAsyncTimeout)
+
+	L.Push(newFutureUserData(L, newFuture(func() (interface{}, error) {
+		return poll(timeout, func() (interface{}, bool) {
+			result, err := cmdcom.RPCCall("getblockcount", http.Params{})
+			if err != nil {
+				return nil, false
+			}
+			count, _ := result.(float64)
+			return count - 1, count-1 >= height
+		})
+	})))
+	return 1
+}
+
+// poll calls check every pollInterval until it returns true or timeout.
+func poll(timeout <-chan time.Time,
+	check func() (interface{}, bool)) (interface{}, error) {
+	for {
+		if value, ok := check(); ok {
+			return value, nil
+		}
+		select {
+		case <-timeout:
+			return nil, fmt.Errorf("%s waiting for the node",
+				errWaitTimeout)
+		case <-time.After(pollInterval):
+		}
+	}
+}
@@ -11,6 +11,9 @@ import (
 	"fmt"
 
 	"github.com/elastos/Elastos.ELA/account"
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	pg "github.com/elastos/Elastos.ELA/core/contract/program"
+	"github.com/elastos/Elastos.ELA/core/types"
 
 	"github.com/yuin/gopher-lua"
 )
@@ -22,6 +25,7 @@ func RegisterClientType(L *lua.LState) {
 	L.SetGlobal("client", mt)
 	// static attributes
 	L.SetField(mt, "new", L.NewFunction(newClient))
+	L.SetField(mt, "open", L.NewFunction(openClient))
 	// methods
 	L.SetField(mt, "__index", L.SetFuncs(L.NewTable(), clientMethods))
 }
@@ -46,6 +50,26 @@ func newClient(L *lua.LState) int {
 	return 1
 }
 
+// openClient opens the named wallet under the wallets directory, or the
+// keystore file if a file path is given, so scripts can hold several wallets
+// at the same time.
+func openClient(L *lua.LState) int {
+	name := L.ToString(1)
+	pwd := L.ToString(2)
+	wallet, err := account.Open(cmdcom.WalletPath(name), []byte(pwd))
+	if err != nil {
+		L.RaiseError("open wallet %s failed: %s", name, err)
+		return 0
+	}
+
+	ud := L.NewUserData()
+	ud.Value = wallet
+	L.SetMetatable(ud, L.GetTypeMetatable(luaClientTypeName))
+	L.Push(ud)
+
+	return 1
+}
+
 func checkClient(L *lua.LState, idx int) (*account.Client, error) {
 	v := L.Get(idx)
 	if ud, ok := v.(*lua.LUserData); ok {
@@ -61,6 +85,7 @@ var clientMethods = map[string]lua.LGFunction{
 	"get":           clientGet,
 	"get_address":   getWalletAddr,
 	"get_publickey": getWalletPubkey,
+	"sign_txs":      signTxs,
 }
 
 // Getter and setter for the Person#Name
@@ -74,6 +99,52 @@ func clientGet(L *lua.LState) int {
 	return 0
 }
 
+// signTxs signs a table of transactions with the wallet, the main account
+// program is used for transactions without programs. The count of signed
+// transactions is returned.
+func signTxs(L *lua.LState) int {
+	wallet, err := checkClient(L, 1)
+	if err != nil {
+		L.RaiseError(err.Error())
+		return 0
+	}
+	table := L.CheckTable(2)
+
+	var txns []*types.Transaction
+	var raiseErr error
+	table.ForEach(func(_, v lua.LValue) {
+		ud, ok := v.(*lua.LUserData)
+		if !ok {
+			raiseErr = errors.New("transaction expected")
+			return
+		}
+		txn, ok := ud.Value.(*types.Transaction)
+		if !ok {
+			raiseErr = errors.New("transaction expected")
+			return
+		}
+		if len(txn.Programs) == 0 {
+			txn.Programs = []*pg.Program{{
+				Code:      wallet.GetMainAccount().RedeemScript,
+				Parameter: []byte{},
+			}}
+		}
+		txns = append(txns, txn)
+	})
+	if raiseErr != nil {
+		L.RaiseError(raiseErr.Error())
+		return 0
+	}
+
+	if err := wallet.SignTxs(txns); err != nil {
+		L.RaiseError(err.Error())
+		return 0
+	}
+	L.Push(lua.LNumber(len(txns)))
+
+	return 1
+}
+
 func getWalletAddr(L *lua.LState) int {
 	wallet, err := checkClient(L, 1)
 	if err != nil {
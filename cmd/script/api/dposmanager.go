@@ -17,7 +17,6 @@ import (
 	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/types"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
-	"github.com/elastos/Elastos.ELA/crypto"
 	"github.com/elastos/Elastos.ELA/dpos/account"
 	"github.com/elastos/Elastos.ELA/dpos/dtime"
 	"github.com/elastos/Elastos.ELA/dpos/log"
@@ -65,17 +64,15 @@ func newDposManager(L *lua.LState) int {
 	}
 
 	medianTime := dtime.NewMedianTime()
-	pub, _ := common.HexStringToBytes(arbitratorsPublicKeys[index])
+	pub, _ := arbitratorsKeyPairs[index].publicKey.EncodePoint(true)
 	dposManager := NewManager(DPOSManagerConfig{TimeSource: medianTime, PublicKey: pub, Arbitrators: a})
 	mockManager := &manager{
 		DPOSManager: dposManager,
 	}
 
-	priKey, _ := common.HexStringToBytes(arbitratorsPrivateKeys[index])
-	pubKey, _ := crypto.DecodePoint(pub)
 	mockManager.Account = account.New(&account2.Account{
-		PrivateKey: priKey,
-		PublicKey:  pubKey,
+		PrivateKey: arbitratorsKeyPairs[index].privateKey,
+		PublicKey:  arbitratorsKeyPairs[index].publicKey,
 	})
 
 	mockManager.EventMonitor = log.NewEventMonitor()
@@ -0,0 +1,154 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package api
+
+import (
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+	"github.com/elastos/Elastos.ELA/crypto"
+
+	"github.com/yuin/gopher-lua"
+)
+
+const luaFixturesTypeName = "fixtures"
+
+// fixtureKeyPair holds a key pair generated from seed.
+type fixtureKeyPair struct {
+	privateKey []byte
+	publicKey  *crypto.PublicKey
+}
+
+func RegisterFixturesType(L *lua.LState) {
+	mt := L.NewTypeMetatable(luaFixturesTypeName)
+	L.SetGlobal("fixtures", mt)
+	// static attributes
+	L.SetField(mt, "keypair", L.NewFunction(fixturesKeyPair))
+	L.SetField(mt, "multisig", L.NewFunction(fixturesMultiSig))
+}
+
+// newFixtureKeyPair generates a key pair from the seed, the same seed always
+// results in the same key pair.
+func newFixtureKeyPair(seed string) *fixtureKeyPair {
+	digest := sha256.Sum256([]byte(seed))
+	d := new(big.Int).SetBytes(digest[:])
+	// Hash again in the rare case the digest is not a valid private key.
+	for d.Sign() == 0 || d.Cmp(crypto.DefaultParams.N) >= 0 {
+		digest = sha256.Sum256(digest[:])
+		d.SetBytes(digest[:])
+	}
+
+	publicKey := &crypto.PublicKey{}
+	publicKey.X, publicKey.Y = crypto.DefaultCurve.ScalarBaseMult(digest[:])
+	return &fixtureKeyPair{
+		privateKey: digest[:],
+		publicKey:  publicKey,
+	}
+}
+
+// fixtureKeyPairs generates key pairs of the given seeds in order.
+func fixtureKeyPairs(seeds []string) []*fixtureKeyPair {
+	pairs := make([]*fixtureKeyPair, 0, len(seeds))
+	for _, seed := range seeds {
+		pairs = append(pairs, newFixtureKeyPair(seed))
+	}
+	return pairs
+}
+
+func setProgramHash(L *lua.LState, table *lua.LTable, prefix string,
+	programHash *common.Uint168) {
+	addr, err := programHash.ToAddress()
+	if err != nil {
+		L.RaiseError("convert program hash to address failed: %s", err)
+	}
+	L.SetField(table, prefix+"address", lua.LString(addr))
+	L.SetField(table, prefix+"programhash",
+		lua.LString(common.BytesToHexString(programHash.Bytes())))
+}
+
+func newKeyPairTable(L *lua.LState, pair *fixtureKeyPair) *lua.LTable {
+	publicKey, err := pair.publicKey.EncodePoint(true)
+	if err != nil {
+		L.RaiseError("encode public key failed: %s", err)
+	}
+	code, err := contract.CreateStandardRedeemScript(pair.publicKey)
+	if err != nil {
+		L.RaiseError("create redeem script failed: %s", err)
+	}
+	hashes, err := contract.DeriveProgramHashes(code)
+	if err != nil {
+		L.RaiseError("derive program hashes failed: %s", err)
+	}
+
+	table := L.NewTable()
+	L.SetField(table, "privatekey",
+		lua.LString(common.BytesToHexString(pair.privateKey)))
+	L.SetField(table, "publickey",
+		lua.LString(common.BytesToHexString(publicKey)))
+	L.SetField(table, "code", lua.LString(common.BytesToHexString(code)))
+	programHash, err := contract.PublicKeyToStandardProgramHash(publicKey)
+	if err != nil {
+		L.RaiseError("create program hash failed: %s", err)
+	}
+	setProgramHash(L, table, "", programHash)
+	setProgramHash(L, table, "deposit", hashes.DPoSDeposit)
+	setProgramHash(L, table, "crdeposit", hashes.CRDeposit)
+	setProgramHash(L, table, "cid", hashes.CID)
+	setProgramHash(L, table, "did", hashes.DID)
+	return table
+}
+
+// fixturesKeyPair returns a table of the key pair generated from the seed,
+// with the standard, deposit, CR deposit, CID and DID addresses and program
+// hashes of it.
+func fixturesKeyPair(L *lua.LState) int {
+	seed := L.CheckString(1)
+	L.Push(newKeyPairTable(L, newFixtureKeyPair(seed)))
+
+	return 1
+}
+
+// fixturesMultiSig returns a table of the m of n multi-sign account of the key
+// pairs generated from seeds, the key pairs are listed in "keys" by the order
+// of seeds.
+func fixturesMultiSig(L *lua.LState) int {
+	seedsTable := L.CheckTable(1)
+	m := L.CheckInt(2)
+
+	var seeds []string
+	seedsTable.ForEach(func(i, v lua.LValue) {
+		seeds = append(seeds, lua.LVAsString(v))
+	})
+	if m < 1 || m > len(seeds) {
+		L.ArgError(2, "m should be between 1 and count of seeds")
+		return 0
+	}
+
+	pairs := fixtureKeyPairs(seeds)
+	keys := L.NewTable()
+	publicKeys := make([]*crypto.PublicKey, 0, len(pairs))
+	for _, pair := range pairs {
+		keys.Append(newKeyPairTable(L, pair))
+		publicKeys = append(publicKeys, pair.publicKey)
+	}
+	multiSig, err := contract.CreateMultiSigContract(m, publicKeys)
+	if err != nil || multiSig.Code == nil {
+		L.RaiseError("create multi-sign contract failed: %v", err)
+	}
+
+	table := L.NewTable()
+	L.SetField(table, "keys", keys)
+	L.SetField(table, "m", lua.LNumber(m))
+	L.SetField(table, "n", lua.LNumber(len(pairs)))
+	L.SetField(table, "code",
+		lua.LString(common.BytesToHexString(multiSig.Code)))
+	setProgramHash(L, table, "", multiSig.ToProgramHash())
+	L.Push(table)
+
+	return 1
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/blockchain"
+	"github.com/elastos/Elastos.ELA/cmd/chain"
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/utils/http"
+
+	"github.com/yuin/gopher-lua"
+)
+
+const (
+	// defaultStartTimeout is the default seconds to wait for the RPC service
+	// of a started node.
+	defaultStartTimeout = 60
+
+	// stopTimeout is the time to wait for a node to exit after interrupted,
+	// the node is killed if it's still running after the timeout.
+	stopTimeout = 30 * time.Second
+)
+
+// nodeProcess is a node process started by the script.
+type nodeProcess struct {
+	path    string
+	dataDir string
+	args    []string
+	cmd     *exec.Cmd
+	exited  chan struct{}
+}
+
+// node is the running node started by start_node, nil if not started.
+var node *nodeProcess
+
+func (n *nodeProcess) start() error {
+	args := append([]string{"--datadir", n.dataDir}, n.args...)
+	n.cmd = exec.Command(n.path, args...)
+	n.cmd.Stdout = os.Stdout
+	n.cmd.Stderr = os.Stderr
+	if err := n.cmd.Start(); err != nil {
+		return err
+	}
+	n.exited = make(chan struct{})
+	go func() {
+		n.cmd.Wait()
+		close(n.exited)
+	}()
+	return nil
+}
+
+// waitReady waits until the RPC service of the node is available.
+func (n *nodeProcess) waitReady(timeout time.Duration) error {
+	deadline := time.After(timeout)
+	for {
+		if _, err := cmdcom.RPCCall("getblockcount", http.Params{}); err == nil {
+			return nil
+		}
+		select {
+		case <-n.exited:
+			return fmt.Errorf("node exited before RPC service started")
+		case <-deadline:
+			return fmt.Errorf("RPC service not available in %s", timeout)
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// stop interrupts the node and waits for it to exit.
+func (n *nodeProcess) stop() {
+	n.cmd.Process.Signal(os.Interrupt)
+	select {
+	case <-n.exited:
+	case <-time.After(stopTimeout):
+		n.cmd.Process.Kill()
+		<-n.exited
+	}
+}
+
+// StopNode stops the node started by the script if it's running, it should
+// be called before the script exits.
+func StopNode() {
+	if node != nil {
+		node.stop()
+		node = nil
+	}
+}
+
+// flag returns the value of the flag in the arguments of the node, or the
+// default value if the flag is not set.
+func (n *nodeProcess) flag(name, value string) string {
+	for i, arg := range n.args {
+		arg = strings.TrimLeft(arg, "-")
+		if arg == name && i+1 < len(n.args) {
+			value = n.args[i+1]
+		} else if strings.HasPrefix(arg, name+"=") {
+			value = strings.TrimPrefix(arg, name+"=")
+		}
+	}
+	return value
+}
+
+// startNode starts the node binary with the data dir and extra arguments,
+// and waits until the RPC service is available.  The parameters are path,
+// datadir, args and timeout in seconds, args and timeout are optional.  The
+// RPC port of the node should match the one used by ela-cli.
+func startNode(L *lua.LState) int {
+	if node != nil {
+		fmt.Println("node already started")
+		os.Exit(1)
+	}
+	n := &nodeProcess{
+		path:    L.CheckString(1),
+		dataDir: L.CheckString(2),
+	}
+	if args := L.OptTable(3, nil); args != nil {
+		args.ForEach(func(_, v lua.LValue) {
+			n.args = append(n.args, v.String())
+		})
+	}
+	timeout := time.Duration(L.OptInt(4, defaultStartTimeout)) * time.Second
+
+	if err := n.start(); err != nil {
+		fmt.Println("start node failed,", err)
+		os.Exit(1)
+	}
+	node = n
+	if err := n.waitReady(timeout); err != nil {
+		StopNode()
+		fmt.Println("start node failed,", err)
+		os.Exit(1)
+	}
+	return 0
+}
+
+// stopNode stops the node started by start_node.
+func stopNode(L *lua.LState) int {
+	StopNode()
+	return 0
+}
+
+// mineBlocks generates count blocks instantly by the node and returns the
+// hashes of the blocks.
+func mineBlocks(L *lua.LState) int {
+	count := L.CheckInt(1)
+
+	L.Push(rpcCall(L, "discretemining", http.Params{"count": count}))
+	return 1
+}
+
+// flushMempool removes all transactions from the memory pool of the node and
+// returns the number of removed transactions.
+func flushMempool(L *lua.LState) int {
+	L.Push(rpcCall(L, "flushmempool", http.Params{}))
+	return 1
+}
+
+// rollbackNode stops the node started by start_node, rolls back the chain
+// data to the height and restarts the node with the same arguments.  The
+// parameters are height and database engine, the engine is optional.
+func rollbackNode(L *lua.LState) int {
+	height := L.CheckInt(1)
+	dbEngine := L.OptString(2, blockchain.LevelDBEngine)
+	if node == nil {
+		fmt.Println("node not started")
+		os.Exit(1)
+	}
+
+	n := node
+	StopNode()
+	params, err := chain.ActiveParams(
+		n.flag(cmdcom.ConfigFileFlag.Name, cmdcom.ConfigFileFlag.Value),
+		n.flag(cmdcom.ChainParamsFlag.Name, ""), false)
+	if err != nil {
+		fmt.Println("rollback failed,", err)
+		os.Exit(1)
+	}
+	if err := chain.Rollback(n.dataDir, dbEngine, params, height); err != nil {
+		fmt.Println("rollback failed,", err)
+		os.Exit(1)
+	}
+
+	if err := n.start(); err != nil {
+		fmt.Println("restart node failed,", err)
+		os.Exit(1)
+	}
+	node = n
+	if err := n.waitReady(defaultStartTimeout * time.Second); err != nil {
+		StopNode()
+		fmt.Println("restart node failed,", err)
+		os.Exit(1)
+	}
+	return 0
+}
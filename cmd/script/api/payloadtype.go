@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/elastos/Elastos.ELA/account"
 	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/core/contract"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
@@ -31,8 +32,53 @@ const (
 	luaRegisterCRName        = "registercr"
 	luaUpdateCRName          = "updatecr"
 	luaUnregisterCRName      = "unregistercr"
+	luaCustomIDProposalName  = "customidproposal"
+	luaUpdateVersionName     = "updateversion"
+	luaCRCRewardAddressName  = "crcrewardaddress"
+	luaProducerAppealName    = "producerappeal"
 )
 
+// payloadVersions lists the payload versions exposed to scripts by the
+// payloadversions table.
+var payloadVersions = map[string]byte{
+	"coinbase":              payload.CoinBaseVersion,
+	"producerinfo":          payload.ProducerInfoVersion,
+	"producerinfostake":     payload.ProducerInfoStakeVersion,
+	"processproducer":       payload.ProcessProducerVersion,
+	"activateproducer":      payload.ActivateProducerVersion,
+	"returndepositcoin":     payload.ReturnDepositCoinVersion,
+	"sidechainpow":          payload.SideChainPowVersion,
+	"crinfo":                payload.CRInfoVersion,
+	"crinfodid":             payload.CRInfoDIDVersion,
+	"crinforeveal":          payload.CRInfoRevealVersion,
+	"crnicknamecommit":      payload.CRNicknameCommitVersion,
+	"unregistercr":          payload.UnregisterCRVersion,
+	"customidproposal":      payload.CustomIDProposalVersion,
+	"updateversion":         payload.UpdateVersionVersion,
+	"crcrewardaddress":      payload.CRCRewardAddressVersion,
+	"producerappeal":        payload.ProducerAppealVersion,
+	"inactivearbitrators":   payload.InactiveArbitratorsVersion,
+	"revokevote":            payload.RevokeVoteVersion,
+	"withdrawfromsidechain": payload.WithdrawFromSideChainVersion,
+	"withdrawproof":         payload.WithdrawFromSideChainProofVersion,
+}
+
+// RegisterPayloadVersions registers the payloadversions table, so that
+// scripts can construct payloads of different versions for fork testing.
+func RegisterPayloadVersions(L *lua.LState) {
+	versions := L.NewTable()
+	for name, version := range payloadVersions {
+		L.SetField(versions, name, lua.LNumber(version))
+	}
+	L.SetGlobal("payloadversions", versions)
+}
+
+// optPayloadVersion returns the payload version given by the optional
+// argument at idx, or the default version if the argument is absent.
+func optPayloadVersion(L *lua.LState, idx int, defaultVersion byte) byte {
+	return byte(L.OptInt(idx, int(defaultVersion)))
+}
+
 func RegisterCoinBaseType(L *lua.LState) {
 	mt := L.NewTypeMetatable(luaCoinBaseTypeName)
 	L.SetGlobal("coinbase", mt)
@@ -144,6 +190,7 @@ func newUpdateProducer(L *lua.LState) int {
 	if err != nil {
 		needSign = false
 	}
+	payloadVersion := optPayloadVersion(L, 8, payload.ProducerInfoVersion)
 
 	ownerPublicKey, err := common.HexStringToBytes(ownerPublicKeyStr)
 	if err != nil {
@@ -166,7 +213,7 @@ func newUpdateProducer(L *lua.LState) int {
 
 	if needSign {
 		upSignBuf := new(bytes.Buffer)
-		err = updateProducer.SerializeUnsigned(upSignBuf, payload.ProducerInfoVersion)
+		err = updateProducer.SerializeUnsigned(upSignBuf, payloadVersion)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
@@ -238,6 +285,7 @@ func newRegisterProducer(L *lua.LState) int {
 	if err != nil {
 		needSign = false
 	}
+	payloadVersion := optPayloadVersion(L, 8, payload.ProducerInfoVersion)
 
 	ownerPublicKey, err := common.HexStringToBytes(ownerPublicKeyStr)
 	if err != nil {
@@ -261,7 +309,7 @@ func newRegisterProducer(L *lua.LState) int {
 
 	if needSign {
 		rpSignBuf := new(bytes.Buffer)
-		err = registerProducer.SerializeUnsigned(rpSignBuf, payload.ProducerInfoVersion)
+		err = registerProducer.SerializeUnsigned(rpSignBuf, payloadVersion)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
@@ -327,6 +375,7 @@ func newProcessProducer(L *lua.LState) int {
 	if err != nil {
 		fmt.Println(err)
 	}
+	payloadVersion := optPayloadVersion(L, 3, payload.ProcessProducerVersion)
 
 	publicKey, err := common.HexStringToBytes(publicKeyStr)
 	if err != nil {
@@ -338,7 +387,7 @@ func newProcessProducer(L *lua.LState) int {
 	}
 
 	cpSignBuf := new(bytes.Buffer)
-	err = processProducer.SerializeUnsigned(cpSignBuf, payload.ProcessProducerVersion)
+	err = processProducer.SerializeUnsigned(cpSignBuf, payloadVersion)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
@@ -447,6 +496,7 @@ func newActivateProducer(L *lua.LState) int {
 	if err != nil {
 		fmt.Println(err)
 	}
+	payloadVersion := optPayloadVersion(L, 3, payload.ActivateProducerVersion)
 
 	publicKey, err := common.HexStringToBytes(publicKeyStr)
 	if err != nil {
@@ -458,7 +508,7 @@ func newActivateProducer(L *lua.LState) int {
 	}
 
 	apSignBuf := new(bytes.Buffer)
-	err = activateProducer.SerializeUnsigned(apSignBuf, payload.ActivateProducerVersion)
+	err = activateProducer.SerializeUnsigned(apSignBuf, payloadVersion)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
@@ -530,6 +580,7 @@ func newSideChainPow(L *lua.LState) int {
 	if err != nil {
 		fmt.Println(err)
 	}
+	payloadVersion := optPayloadVersion(L, 5, payload.SideChainPowVersion)
 
 	sideBlockHash, err := common.Uint256FromHexString(sideBlockHashStr)
 	if err != nil {
@@ -549,7 +600,7 @@ func newSideChainPow(L *lua.LState) int {
 	}
 
 	spSignBuf := new(bytes.Buffer)
-	err = sideChainPow.SerializeUnsigned(spSignBuf, payload.SideChainPowVersion)
+	err = sideChainPow.SerializeUnsigned(spSignBuf, payloadVersion)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
@@ -845,6 +896,7 @@ func newUnregisterCR(L *lua.LState) int {
 	if err != nil {
 		needSign = false
 	}
+	payloadVersion := optPayloadVersion(L, 3, payload.UnregisterCRVersion)
 	publicKey, err := common.HexStringToBytes(publicKeyStr)
 	if err != nil {
 		fmt.Println("wrong cr public key")
@@ -869,7 +921,7 @@ func newUnregisterCR(L *lua.LState) int {
 
 	if needSign {
 		rpSignBuf := new(bytes.Buffer)
-		err = unregisterCR.SerializeUnsigned(rpSignBuf, payload.UnregisterCRVersion)
+		err = unregisterCR.SerializeUnsigned(rpSignBuf, payloadVersion)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
@@ -918,3 +970,337 @@ func unregisterCRGet(L *lua.LState) int {
 
 	return 0
 }
+
+func RegisterCustomIDProposalType(L *lua.LState) {
+	mt := L.NewTypeMetatable(luaCustomIDProposalName)
+	L.SetGlobal("customidproposal", mt)
+	// static attributes
+	L.SetField(mt, "new", L.NewFunction(newCustomIDProposal))
+	// methods
+	L.SetField(mt, "__index", L.SetFuncs(L.NewTable(), customIDProposalMethods))
+}
+
+// Constructor
+// All accounts of the optional client will sign the proposal as CR members.
+func newCustomIDProposal(L *lua.LState) int {
+	proposalType := payload.CustomIDProposalType(L.ToInt(1))
+	idsTable := L.ToTable(2)
+	feeRate := common.Fixed64(L.ToInt64(3))
+	effectiveHeight := uint32(L.ToInt(4))
+	needSign := true
+	client, err := checkClient(L, 5)
+	if err != nil {
+		needSign = false
+	}
+	payloadVersion := optPayloadVersion(L, 6, payload.CustomIDProposalVersion)
+
+	ids := make([]string, 0)
+	if idsTable != nil {
+		idsTable.ForEach(func(i, v lua.LValue) {
+			ids = append(ids, lua.LVAsString(v))
+		})
+	}
+
+	proposal := &payload.CustomIDProposal{
+		ProposalType:    proposalType,
+		CustomIDs:       ids,
+		FeeRate:         feeRate,
+		EffectiveHeight: effectiveHeight,
+	}
+
+	if needSign {
+		signBuf := new(bytes.Buffer)
+		err = proposal.SerializeUnsigned(signBuf, payloadVersion)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		proposal.Signs = signByCRMembers(client, signBuf.Bytes())
+	}
+
+	ud := L.NewUserData()
+	ud.Value = proposal
+	L.SetMetatable(ud, L.GetTypeMetatable(luaCustomIDProposalName))
+	L.Push(ud)
+
+	return 1
+}
+
+// Checks whether the first lua argument is a *LUserData with *CustomIDProposal
+// and returns this *CustomIDProposal.
+func checkCustomIDProposal(L *lua.LState, idx int) *payload.CustomIDProposal {
+	ud := L.CheckUserData(idx)
+	if v, ok := ud.Value.(*payload.CustomIDProposal); ok {
+		return v
+	}
+	L.ArgError(1, "CustomIDProposal expected")
+	return nil
+}
+
+var customIDProposalMethods = map[string]lua.LGFunction{
+	"get": customIDProposalGet,
+}
+
+// Getter and setter for the Person#Name
+func customIDProposalGet(L *lua.LState) int {
+	p := checkCustomIDProposal(L, 1)
+	fmt.Println(p)
+
+	return 0
+}
+
+// signByCRMembers signs the data with all accounts of the client as CR
+// members.
+func signByCRMembers(client *account.Client, data []byte) []payload.CRMemberSign {
+	signs := make([]payload.CRMemberSign, 0)
+	for _, acc := range client.GetAccounts() {
+		if acc.PrivKey() == nil {
+			continue
+		}
+		ct, err := contract.CreateStandardContract(acc.PubKey())
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		sig, err := crypto.Sign(acc.PrivKey(), data)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		signs = append(signs, payload.CRMemberSign{
+			CID:       *getIDProgramHash(ct.Code),
+			Signature: sig,
+		})
+	}
+	return signs
+}
+
+// signByPublicKey signs the data with the account of the client that owns
+// the given public key.
+func signByPublicKey(client *account.Client, publicKey []byte,
+	data []byte) []byte {
+	codeHash, err := contract.PublicKeyToStandardCodeHash(publicKey)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	acc := client.GetAccountByCodeHash(*codeHash)
+	if acc == nil {
+		fmt.Println("no available account in wallet")
+		os.Exit(1)
+	}
+	sig, err := crypto.Sign(acc.PrivKey(), data)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	return sig
+}
+
+func RegisterUpdateVersionType(L *lua.LState) {
+	mt := L.NewTypeMetatable(luaUpdateVersionName)
+	L.SetGlobal("updateversion", mt)
+	// static attributes
+	L.SetField(mt, "new", L.NewFunction(newUpdateVersion))
+	// methods
+	L.SetField(mt, "__index", L.SetFuncs(L.NewTable(), updateVersionMethods))
+}
+
+// Constructor
+// The transaction carrying the payload should be signed by the CRC
+// arbitrators, see transaction:signmulti.
+func newUpdateVersion(L *lua.LState) int {
+	startHeight := uint32(L.ToInt(1))
+	endHeight := uint32(L.ToInt(2))
+
+	updateVersion := &payload.UpdateVersion{
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+	}
+
+	ud := L.NewUserData()
+	ud.Value = updateVersion
+	L.SetMetatable(ud, L.GetTypeMetatable(luaUpdateVersionName))
+	L.Push(ud)
+
+	return 1
+}
+
+// Checks whether the first lua argument is a *LUserData with *UpdateVersion
+// and returns this *UpdateVersion.
+func checkUpdateVersion(L *lua.LState, idx int) *payload.UpdateVersion {
+	ud := L.CheckUserData(idx)
+	if v, ok := ud.Value.(*payload.UpdateVersion); ok {
+		return v
+	}
+	L.ArgError(1, "UpdateVersion expected")
+	return nil
+}
+
+var updateVersionMethods = map[string]lua.LGFunction{
+	"get": updateVersionGet,
+}
+
+func updateVersionGet(L *lua.LState) int {
+	p := checkUpdateVersion(L, 1)
+	fmt.Println(p)
+
+	return 0
+}
+
+func RegisterCRCRewardAddressType(L *lua.LState) {
+	mt := L.NewTypeMetatable(luaCRCRewardAddressName)
+	L.SetGlobal("crcrewardaddress", mt)
+	// static attributes
+	L.SetField(mt, "new", L.NewFunction(newCRCRewardAddress))
+	// methods
+	L.SetField(mt, "__index", L.SetFuncs(L.NewTable(), crcRewardAddressMethods))
+}
+
+// Constructor
+// The optional node client signs as the CRC arbiter, and all accounts of the
+// optional CR client will sign the payload as CR members.
+func newCRCRewardAddress(L *lua.LState) int {
+	publicKeyStr := L.ToString(1)
+	rewardAddr := L.ToString(2)
+	height := uint32(L.ToInt(3))
+	nodeClient, nodeErr := checkClient(L, 4)
+	crClient, crErr := checkClient(L, 5)
+	payloadVersion := optPayloadVersion(L, 6, payload.CRCRewardAddressVersion)
+
+	publicKey, err := common.HexStringToBytes(publicKeyStr)
+	if err != nil {
+		fmt.Println("wrong arbiter node public key")
+		os.Exit(1)
+	}
+	programHash, err := common.Uint168FromAddress(rewardAddr)
+	if err != nil {
+		fmt.Println("wrong reward address")
+		os.Exit(1)
+	}
+
+	rewardAddress := &payload.CRCRewardAddress{
+		NodePublicKey:     publicKey,
+		RewardProgramHash: *programHash,
+		Height:            height,
+	}
+
+	signBuf := new(bytes.Buffer)
+	err = rewardAddress.SerializeUnsigned(signBuf, payloadVersion)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if nodeErr == nil {
+		rewardAddress.Signature = signByPublicKey(nodeClient, publicKey,
+			signBuf.Bytes())
+	}
+	if crErr == nil {
+		rewardAddress.Signs = signByCRMembers(crClient, signBuf.Bytes())
+	}
+
+	ud := L.NewUserData()
+	ud.Value = rewardAddress
+	L.SetMetatable(ud, L.GetTypeMetatable(luaCRCRewardAddressName))
+	L.Push(ud)
+
+	return 1
+}
+
+// Checks whether the first lua argument is a *LUserData with
+// *CRCRewardAddress and returns this *CRCRewardAddress.
+func checkCRCRewardAddress(L *lua.LState, idx int) *payload.CRCRewardAddress {
+	ud := L.CheckUserData(idx)
+	if v, ok := ud.Value.(*payload.CRCRewardAddress); ok {
+		return v
+	}
+	L.ArgError(1, "CRCRewardAddress expected")
+	return nil
+}
+
+var crcRewardAddressMethods = map[string]lua.LGFunction{
+	"get": crcRewardAddressGet,
+}
+
+func crcRewardAddressGet(L *lua.LState) int {
+	p := checkCRCRewardAddress(L, 1)
+	fmt.Println(p)
+
+	return 0
+}
+
+func RegisterProducerAppealType(L *lua.LState) {
+	mt := L.NewTypeMetatable(luaProducerAppealName)
+	L.SetGlobal("producerappeal", mt)
+	// static attributes
+	L.SetField(mt, "new", L.NewFunction(newProducerAppeal))
+	// methods
+	L.SetField(mt, "__index", L.SetFuncs(L.NewTable(), producerAppealMethods))
+}
+
+// Constructor
+// The optional owner client signs as the producer owner, and all accounts of
+// the optional CR client will sign the appeal as CR members.
+func newProducerAppeal(L *lua.LState) int {
+	publicKeyStr := L.ToString(1)
+	reason := L.ToString(2)
+	activationHeight := uint32(L.ToInt(3))
+	ownerClient, ownerErr := checkClient(L, 4)
+	crClient, crErr := checkClient(L, 5)
+	payloadVersion := optPayloadVersion(L, 6, payload.ProducerAppealVersion)
+
+	publicKey, err := common.HexStringToBytes(publicKeyStr)
+	if err != nil {
+		fmt.Println("wrong producer owner public key")
+		os.Exit(1)
+	}
+
+	appeal := &payload.ProducerAppeal{
+		OwnerPublicKey:   publicKey,
+		Reason:           reason,
+		ActivationHeight: activationHeight,
+	}
+
+	signBuf := new(bytes.Buffer)
+	err = appeal.SerializeUnsigned(signBuf, payloadVersion)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if ownerErr == nil {
+		appeal.Signature = signByPublicKey(ownerClient, publicKey,
+			signBuf.Bytes())
+	}
+	if crErr == nil {
+		appeal.Signs = signByCRMembers(crClient, signBuf.Bytes())
+	}
+
+	ud := L.NewUserData()
+	ud.Value = appeal
+	L.SetMetatable(ud, L.GetTypeMetatable(luaProducerAppealName))
+	L.Push(ud)
+
+	return 1
+}
+
+// Checks whether the first lua argument is a *LUserData with *ProducerAppeal
+// and returns this *ProducerAppeal.
+func checkProducerAppeal(L *lua.LState, idx int) *payload.ProducerAppeal {
+	ud := L.CheckUserData(idx)
+	if v, ok := ud.Value.(*payload.ProducerAppeal); ok {
+		return v
+	}
+	L.ArgError(1, "ProducerAppeal expected")
+	return nil
+}
+
+var producerAppealMethods = map[string]lua.LGFunction{
+	"get": producerAppealGet,
+}
+
+func producerAppealGet(L *lua.LState) int {
+	p := checkProducerAppeal(L, 1)
+	fmt.Println(p)
+
+	return 0
+}
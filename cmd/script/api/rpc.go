@@ -0,0 +1,97 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/utils/http"
+
+	"github.com/yuin/gopher-lua"
+)
+
+// getBlockCount returns the block count of the node.
+func getBlockCount(L *lua.LState) int {
+	L.Push(rpcCall(L, "getblockcount", http.Params{}))
+	return 1
+}
+
+// getRawTransaction returns the transaction with specified hash, the
+// transaction is returned as table if verbose is true, otherwise the
+// serialized transaction in hex string is returned.
+func getRawTransaction(L *lua.LState) int {
+	txID := L.CheckString(1)
+	verbose := L.OptBool(2, true)
+
+	L.Push(rpcCall(L, "getrawtransaction", http.Params{
+		"txid":    txID,
+		"verbose": verbose,
+	}))
+	return 1
+}
+
+// listProducers returns producers with specified state, the parameters are
+// start, limit and state, all of them are optional.
+func listProducers(L *lua.LState) int {
+	params := http.Params{
+		"start": L.OptInt(1, 0),
+		"limit": L.OptInt(2, -1),
+	}
+	if state := L.OptString(3, ""); state != "" {
+		params["state"] = state
+	}
+
+	L.Push(rpcCall(L, "listproducers", params))
+	return 1
+}
+
+func rpcCall(L *lua.LState, method string, params http.Params) lua.LValue {
+	result, err := cmdcom.RPCCall(method, params)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	return toLuaValue(L, result)
+}
+
+// toLuaValue converts a decoded JSON value to lua value, JSON objects and
+// arrays are converted to tables.
+func toLuaValue(L *lua.LState, value interface{}) lua.LValue {
+	switch v := value.(type) {
+	case nil:
+		return lua.LNil
+	case bool:
+		return lua.LBool(v)
+	case float64:
+		return lua.LNumber(v)
+	case string:
+		return lua.LString(v)
+	case []interface{}:
+		table := L.NewTable()
+		for _, e := range v {
+			table.Append(toLuaValue(L, e))
+		}
+		return table
+	case map[string]interface{}:
+		// set fields in order of keys to keep the table iteration stable.
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		table := L.NewTable()
+		for _, k := range keys {
+			table.RawSetString(k, toLuaValue(L, v[k]))
+		}
+		return table
+	default:
+		return lua.LString(fmt.Sprint(v))
+	}
+}
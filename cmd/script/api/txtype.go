@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/elastos/Elastos.ELA/account"
 	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
 	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/core/contract"
@@ -87,6 +88,14 @@ func newTransaction(L *lua.LState) int {
 		pload, _ = ud.Value.(*payload.CRInfo)
 	case *payload.UnregisterCR:
 		pload, _ = ud.Value.(*payload.UnregisterCR)
+	case *payload.CustomIDProposal:
+		pload, _ = ud.Value.(*payload.CustomIDProposal)
+	case *payload.UpdateVersion:
+		pload, _ = ud.Value.(*payload.UpdateVersion)
+	case *payload.CRCRewardAddress:
+		pload, _ = ud.Value.(*payload.CRCRewardAddress)
+	case *payload.ProducerAppeal:
+		pload, _ = ud.Value.(*payload.ProducerAppeal)
 	default:
 		fmt.Println("error: undefined payload type")
 		os.Exit(1)
@@ -159,6 +168,7 @@ var transactionMethods = map[string]lua.LGFunction{
 	"appendenough":  appendEnough,
 	"appendprogram": appendProgram,
 	"signpayload":   signPayload,
+	"signmulti":     signMultiTx,
 }
 
 func signPayload(L *lua.LState) int {
@@ -275,6 +285,59 @@ func signTx(L *lua.LState) int {
 	return 0
 }
 
+// signMultiTx signs the transaction with a m-of-n multi-sign program built
+// from all accounts of the client, such as the CRC arbitrators signing an
+// UpdateVersion or InactiveArbitrators transaction. M defaults to n.
+func signMultiTx(L *lua.LState) int {
+	txn := checkTransaction(L, 1)
+	client, err := checkClient(L, 2)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	m := L.ToInt(3)
+
+	signers := make([]*account.Account, 0)
+	publicKeys := make([]*crypto.PublicKey, 0)
+	for _, acc := range client.GetAccounts() {
+		if acc.PrivKey() == nil {
+			continue
+		}
+		signers = append(signers, acc)
+		publicKeys = append(publicKeys, acc.PubKey())
+	}
+	if m <= 0 || m > len(signers) {
+		m = len(signers)
+	}
+
+	code, err := contract.CreateMultiSigRedeemScript(m, publicKeys)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	buf := new(bytes.Buffer)
+	if err := txn.SerializeUnsigned(buf); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	parameter := new(bytes.Buffer)
+	for _, acc := range signers[:m] {
+		sig, err := crypto.Sign(acc.PrivKey(), buf.Bytes())
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		parameter.WriteByte(byte(len(sig)))
+		parameter.Write(sig)
+	}
+	txn.Programs = []*pg.Program{{
+		Code:      code,
+		Parameter: parameter.Bytes(),
+	}}
+
+	return 0
+}
+
 func serialize(L *lua.LState) int {
 	txn := checkTransaction(L, 1)
 
@@ -144,6 +144,7 @@ func scriptAction(c *cli.Context) error {
 
 	L := lua.NewState()
 	defer L.Close()
+	defer api.StopNode()
 	L.PreloadModule("api", api.Loader)
 	api.RegisterDataType(L)
 
@@ -162,7 +163,9 @@ func scriptAction(c *cli.Context) error {
 
 	if testContent != "" {
 		fmt.Println("begin white box")
-		if err := L.DoFile(testContent); err != nil {
+		err := L.DoFile(testContent)
+		api.StopNode()
+		if err != nil {
 			println(err.Error())
 			os.Exit(1)
 		} else {
@@ -0,0 +1,91 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package signer
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/elastos/Elastos.ELA/account"
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/common"
+	dposaccount "github.com/elastos/Elastos.ELA/dpos/account"
+	"github.com/elastos/Elastos.ELA/utils/signal"
+
+	"github.com/urfave/cli"
+)
+
+func signerAction(c *cli.Context) error {
+	socket := c.String("socket")
+	if socket == "" {
+		return errors.New("use --socket to specify the unix socket path")
+	}
+	token := c.String("token")
+	if token == "" {
+		return errors.New("use --token to specify the authenticate token")
+	}
+
+	password, err := cmdcom.GetFlagPassword(c)
+	if err != nil {
+		return err
+	}
+	client, err := account.Open(cmdcom.GetWalletPath(c), password)
+	if err != nil {
+		return err
+	}
+	acc := dposaccount.New(client.GetMainAccount())
+
+	if err := os.Remove(socket); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(socket, 0600); err != nil {
+		listener.Close()
+		return err
+	}
+
+	server := dposaccount.NewSignerServer(acc, []byte(token))
+	interrupt := signal.NewInterrupt()
+	go func() {
+		<-interrupt.C
+		server.Close()
+	}()
+
+	fmt.Println("signer public key:",
+		common.BytesToHexString(acc.PublicKeyBytes()))
+	fmt.Println("signer listening on", socket)
+	server.Serve(listener)
+	return nil
+}
+
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "signer",
+		Usage: "Run a signing daemon for the arbiter",
+		Description: "With ela-cli signer, you can run a signing daemon which " +
+			"signs DPoS proposals and votes for the node, so the arbiter " +
+			"private key will not be loaded by the node.",
+		ArgsUsage: "[args]",
+		Flags: []cli.Flag{
+			cmdcom.AccountWalletFlag,
+			cmdcom.AccountPasswordFlag,
+			cli.StringFlag{
+				Name:  "socket",
+				Usage: "unix socket `<path>` the signer listens on",
+			},
+			cli.StringFlag{
+				Name:  "token",
+				Usage: "authenticate `<token>` shared with the node",
+			},
+		},
+		Action: signerAction,
+	}
+}
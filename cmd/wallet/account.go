@@ -34,6 +34,12 @@ var accountCommand = []cli.Command{
 		},
 		Action: createAccount,
 	},
+	{
+		Category: "Account",
+		Name:     "wallets",
+		Usage:    "List the named wallets under the wallets directory",
+		Action:   listWallets,
+	},
 	{
 		Category: "Account",
 		Name:     "account",
@@ -118,7 +124,17 @@ var accountCommand = []cli.Command{
 		Category: "Account",
 		Name:     "depositaddr",
 		Usage:    "Generate deposit address",
-		Action:   generateDepositAddress,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "pubkey",
+				Usage: "derive deposit addresses, CID and DID from the public key",
+			},
+			cli.StringFlag{
+				Name:  "code",
+				Usage: "derive deposit address, CID and DID from the redeem script",
+			},
+		},
+		Action: generateDepositAddress,
 	},
 	{
 		Category: "Account",
@@ -129,7 +145,7 @@ var accountCommand = []cli.Command{
 }
 
 func createAccount(c *cli.Context) error {
-	walletPath := c.String("wallet")
+	walletPath := cmdcom.GetWalletPath(c)
 	password := c.String("password")
 
 	var p []byte
@@ -143,6 +159,9 @@ func createAccount(c *cli.Context) error {
 		p = []byte(password)
 	}
 
+	if err := cmdcom.MakeWalletDir(walletPath); err != nil {
+		return err
+	}
 	client, err := account.Create(walletPath, p)
 	if err != nil {
 		return err
@@ -151,8 +170,22 @@ func createAccount(c *cli.Context) error {
 	return ShowAccountInfo(client)
 }
 
+func listWallets(c *cli.Context) error {
+	names, err := cmdcom.ListWallets()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-20s %s\n", "NAME", "PATH")
+	fmt.Println(strings.Repeat("-", 20), strings.Repeat("-", 40))
+	for _, name := range names {
+		fmt.Printf("%-20s %s\n", name, cmdcom.WalletPath(name))
+	}
+	return nil
+}
+
 func accountInfo(c *cli.Context) error {
-	walletPath := c.String("wallet")
+	walletPath := cmdcom.GetWalletPath(c)
 	if exist := utils.FileExisted(walletPath); !exist {
 		fmt.Println(fmt.Sprintf("error: %s is not found.", walletPath))
 		cli.ShowCommandHelpAndExit(c, "account", 1)
@@ -174,7 +207,7 @@ func accountInfo(c *cli.Context) error {
 }
 
 func accountBalance(c *cli.Context) error {
-	walletPath := c.String("wallet")
+	walletPath := cmdcom.GetWalletPath(c)
 	if exist := utils.FileExisted(walletPath); !exist {
 		fmt.Println(fmt.Sprintf("error: %s is not found.", walletPath))
 		cli.ShowCommandHelpAndExit(c, "account", 1)
@@ -187,7 +220,7 @@ func accountBalance(c *cli.Context) error {
 }
 
 func addAccount(c *cli.Context) error {
-	walletPath := c.String("wallet")
+	walletPath := cmdcom.GetWalletPath(c)
 	password, err := cmdcom.GetFlagPassword(c)
 	if err != nil {
 		return err
@@ -202,7 +235,7 @@ func addAccount(c *cli.Context) error {
 }
 
 func addMultiSigAccount(c *cli.Context) error {
-	walletPath := c.String("wallet")
+	walletPath := cmdcom.GetWalletPath(c)
 	password, err := cmdcom.GetFlagPassword(c)
 	if err != nil {
 		return err
@@ -237,7 +270,7 @@ func addMultiSigAccount(c *cli.Context) error {
 }
 
 func delAccount(c *cli.Context) error {
-	walletPath := c.String("wallet")
+	walletPath := cmdcom.GetWalletPath(c)
 	password, err := cmdcom.GetFlagPassword(c)
 	if err != nil {
 		return err
@@ -266,7 +299,7 @@ func delAccount(c *cli.Context) error {
 }
 
 func importAccount(c *cli.Context) error {
-	walletPath := c.String("wallet")
+	walletPath := cmdcom.GetWalletPath(c)
 	pwdHex := c.String("password")
 
 	if c.NArg() < 1 {
@@ -290,6 +323,9 @@ func importAccount(c *cli.Context) error {
 				return err
 			}
 		}
+		if err := cmdcom.MakeWalletDir(walletPath); err != nil {
+			return err
+		}
 		client = account.NewClient(walletPath, pwd, true)
 		if client == nil {
 			return errors.New("client nil")
@@ -320,7 +356,7 @@ func importAccount(c *cli.Context) error {
 }
 
 func exportAccount(c *cli.Context) error {
-	walletPath := c.String("wallet")
+	walletPath := cmdcom.GetWalletPath(c)
 	password, err := cmdcom.GetFlagPassword(c)
 	if err != nil {
 		return err
@@ -347,6 +383,10 @@ func exportAccount(c *cli.Context) error {
 }
 
 func generateDepositAddress(c *cli.Context) error {
+	if c.IsSet("pubkey") || c.IsSet("code") {
+		return showDerivedAddresses(c)
+	}
+
 	if c.NArg() < 1 {
 		cmdcom.PrintErrorMsg("Missing argument. Standard address expected.")
 		cli.ShowCommandHelpAndExit(c, "depositaddress", 1)
@@ -390,6 +430,58 @@ func generateDepositAddress(c *cli.Context) error {
 	return nil
 }
 
+func showDerivedAddresses(c *cli.Context) error {
+	var code []byte
+	if pubKeyHex := c.String("pubkey"); pubKeyHex != "" {
+		pubKey, err := common.HexStringToBytes(pubKeyHex)
+		if err != nil {
+			return errors.New("invalid public key hex")
+		}
+		pk, err := crypto.DecodePoint(pubKey)
+		if err != nil {
+			return errors.New("invalid public key")
+		}
+		if code, err = contract.CreateStandardRedeemScript(pk); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if code, err = common.HexStringToBytes(c.String("code")); err != nil {
+			return errors.New("invalid code hex")
+		}
+	}
+
+	hashes, err := contract.DeriveProgramHashes(code)
+	if err != nil {
+		return err
+	}
+
+	if hashes.DPoSDeposit != nil {
+		addr, err := hashes.DPoSDeposit.ToAddress()
+		if err != nil {
+			return err
+		}
+		fmt.Println("DPoS deposit address:", addr)
+	}
+	crDeposit, err := hashes.CRDeposit.ToAddress()
+	if err != nil {
+		return err
+	}
+	cid, err := hashes.CID.ToAddress()
+	if err != nil {
+		return err
+	}
+	did, err := hashes.DID.ToAddress()
+	if err != nil {
+		return err
+	}
+	fmt.Println("CR deposit address:  ", crDeposit)
+	fmt.Println("CID:                 ", cid)
+	fmt.Println("DID:                 ", did)
+
+	return nil
+}
+
 func generateCrossChainAddress(c *cli.Context) error {
 	if c.NArg() < 1 {
 		cmdcom.PrintErrorMsg("Missing argument. The side chain genesis block hash expected.")
@@ -105,11 +105,16 @@ func ShowAccountBalance(walletPath string) error {
 	return nil
 }
 
-func getUTXOsByAmount(address string, amount common.Fixed64) ([]servers.UTXOInfo, error) {
-	result, err := cmdcom.RPCCall("getutxosbyamount", http.Params{
+func getUTXOsByAmount(address string, amount common.Fixed64,
+	excludes []string) ([]servers.UTXOInfo, error) {
+	params := http.Params{
 		"address": address,
 		"amount":  amount.String(),
-	})
+	}
+	if len(excludes) > 0 {
+		params["excludes"] = excludes
+	}
+	result, err := cmdcom.RPCCall("getutxosbyamount", params)
 	if err != nil {
 		return nil, err
 	}
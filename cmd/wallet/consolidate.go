@@ -0,0 +1,305 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package wallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+
+	"github.com/elastos/Elastos.ELA/account"
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/common"
+	pg "github.com/elastos/Elastos.ELA/core/contract/program"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/crypto"
+	"github.com/elastos/Elastos.ELA/servers"
+	"github.com/elastos/Elastos.ELA/utils/http"
+
+	"github.com/urfave/cli"
+)
+
+const (
+	// inputSize is the serialized size of a transaction input in bytes.
+	inputSize = 38
+
+	// coinbaseMaturity is the confirmations needed to spend a coinbase output.
+	coinbaseMaturity = 101
+)
+
+var consolidateCommand = cli.Command{
+	Category:    "Transaction",
+	Name:        "consolidate",
+	Usage:       "Consolidate small utxos of an address",
+	Description: "use --address --max-inputs --feerate to merge the utxos of an address into fewer utxos, utxos not worth the fee to spend are skipped",
+	Flags: []cli.Flag{
+		cmdcom.ConsolidateAddressFlag,
+		cmdcom.ConsolidateMaxInputsFlag,
+		cmdcom.ConsolidateThresholdFlag,
+		cmdcom.ConsolidateFeeRateFlag,
+		cmdcom.ConsolidateSendFlag,
+		cmdcom.AccountWalletFlag,
+		cmdcom.AccountPasswordFlag,
+	},
+	Action: consolidate,
+}
+
+// consolidationBatch is the utxos spent by a consolidation transaction.
+type consolidationBatch struct {
+	inputs []*types.Input
+	amount common.Fixed64
+}
+
+// calcFee returns the fee of the transaction size by the fee rate per KB.
+func calcFee(size int, feeRate common.Fixed64) common.Fixed64 {
+	return common.Fixed64(math.Ceil(float64(feeRate) * float64(size) / 1000))
+}
+
+// selectConsolidationUTXOs returns the spendable utxos in ascending order of
+// amount, utxos not lower than the threshold and utxos costing more fee than
+// the amount to spend are skipped.
+func selectConsolidationUTXOs(utxos []servers.UTXOInfo,
+	threshold common.Fixed64, feeRate common.Fixed64) ([]servers.UTXOInfo,
+	[]common.Fixed64, error) {
+	inputFee := calcFee(inputSize+crypto.SignatureScriptLength, feeRate)
+
+	var selected []servers.UTXOInfo
+	var amounts []common.Fixed64
+	for _, utxo := range utxos {
+		if types.TxType(utxo.TxType) == types.CoinBase &&
+			utxo.Confirmations < coinbaseMaturity {
+			continue
+		}
+		if utxo.OutputLock > 0 {
+			continue
+		}
+		amount, err := common.StringToFixed64(utxo.Amount)
+		if err != nil {
+			return nil, nil, err
+		}
+		if threshold > 0 && *amount >= threshold {
+			continue
+		}
+		if *amount <= inputFee {
+			continue
+		}
+		selected = append(selected, utxo)
+		amounts = append(amounts, *amount)
+	}
+
+	sort.Sort(utxosByAmount{selected, amounts})
+	return selected, amounts, nil
+}
+
+type utxosByAmount struct {
+	utxos   []servers.UTXOInfo
+	amounts []common.Fixed64
+}
+
+func (u utxosByAmount) Len() int { return len(u.utxos) }
+
+func (u utxosByAmount) Less(i, j int) bool { return u.amounts[i] < u.amounts[j] }
+
+func (u utxosByAmount) Swap(i, j int) {
+	u.utxos[i], u.utxos[j] = u.utxos[j], u.utxos[i]
+	u.amounts[i], u.amounts[j] = u.amounts[j], u.amounts[i]
+}
+
+// batchUTXOs splits the utxos into batches with at most maxInputs inputs.
+func batchUTXOs(utxos []servers.UTXOInfo, amounts []common.Fixed64,
+	maxInputs int) ([]*consolidationBatch, error) {
+	var batches []*consolidationBatch
+	for start := 0; start < len(utxos); start += maxInputs {
+		end := start + maxInputs
+		if end > len(utxos) {
+			end = len(utxos)
+		}
+		batch := &consolidationBatch{}
+		for i := start; i < end; i++ {
+			txIDReverse, err := common.HexStringToBytes(utxos[i].TxID)
+			if err != nil {
+				return nil, err
+			}
+			txID, err := common.Uint256FromBytes(
+				common.BytesReverse(txIDReverse))
+			if err != nil {
+				return nil, err
+			}
+			batch.inputs = append(batch.inputs, &types.Input{
+				Previous: types.OutPoint{
+					TxID:  *txID,
+					Index: uint16(utxos[i].VOut),
+				},
+				Sequence: math.MaxUint32,
+			})
+			batch.amount += amounts[i]
+		}
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
+
+// createConsolidationTransaction creates a transaction spending the batch to
+// the program hash, the fee is calculated by the size of the signed
+// transaction.
+func createConsolidationTransaction(batch *consolidationBatch,
+	programHash common.Uint168, redeemScript []byte,
+	feeRate common.Fixed64) (*types.Transaction, common.Fixed64, error) {
+	_, needSign, err := crypto.GetSignStatus(redeemScript, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	txAttr := types.NewAttribute(types.Nonce,
+		[]byte(strconv.FormatInt(rand.Int63(), 10)))
+	txn := &types.Transaction{
+		Version:    types.TxVersion09,
+		TxType:     types.TransferAsset,
+		Payload:    &payload.TransferAsset{},
+		Attributes: []*types.Attribute{&txAttr},
+		Inputs:     batch.inputs,
+		Outputs: []*types.Output{{
+			AssetID:     *account.SystemAssetID,
+			Value:       batch.amount,
+			ProgramHash: programHash,
+			Type:        types.OTNone,
+			Payload:     &outputpayload.DefaultOutput{},
+		}},
+		Programs: []*pg.Program{{Code: redeemScript}},
+	}
+
+	fee := calcFee(txn.GetSize()+needSign*crypto.SignatureScriptLength,
+		feeRate)
+	if batch.amount <= fee {
+		return nil, 0, fmt.Errorf("amount %s of %d utxos is not enough "+
+			"to pay the fee %s", batch.amount, len(batch.inputs), fee)
+	}
+	txn.Outputs[0].Value = batch.amount - fee
+	return txn, fee, nil
+}
+
+func consolidate(c *cli.Context) error {
+	walletPath := cmdcom.GetWalletPath(c)
+
+	maxInputs := c.Int("max-inputs")
+	if maxInputs <= 0 {
+		return errors.New("max inputs should be positive")
+	}
+	feeRate, err := common.StringToFixed64(c.String("feerate"))
+	if err != nil {
+		return errors.New("invalid fee rate")
+	}
+	threshold := common.Fixed64(0)
+	if thresholdStr := c.String("threshold"); thresholdStr != "" {
+		t, err := common.StringToFixed64(thresholdStr)
+		if err != nil {
+			return errors.New("invalid threshold")
+		}
+		threshold = *t
+	}
+
+	sender, err := getSender(walletPath, c.String("address"))
+	if err != nil {
+		return err
+	}
+	programHash, err := common.Uint168FromAddress(sender.Address)
+	if err != nil {
+		return err
+	}
+	redeemScript, err := common.HexStringToBytes(sender.RedeemScript)
+	if err != nil {
+		return err
+	}
+
+	// vote outputs are not consolidated to keep the votes
+	result, err := cmdcom.RPCCall("listunspent", http.Params{
+		"addresses": []string{sender.Address},
+		"utxotype":  "normal",
+	})
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	var utxos []servers.UTXOInfo
+	if err := json.Unmarshal(data, &utxos); err != nil {
+		return err
+	}
+	utxos, err = removeLockedUTXOs(walletPath, utxos)
+	if err != nil {
+		return err
+	}
+
+	utxos, amounts, err := selectConsolidationUTXOs(utxos, threshold, *feeRate)
+	if err != nil {
+		return err
+	}
+	if len(utxos) < 2 {
+		fmt.Println("No utxos need to be consolidated")
+		return nil
+	}
+	batches, err := batchUTXOs(utxos, amounts, maxInputs)
+	if err != nil {
+		return err
+	}
+
+	password, err := cmdcom.GetFlagPassword(c)
+	if err != nil {
+		return err
+	}
+	client, err := account.Open(walletPath, password)
+	if err != nil {
+		return err
+	}
+
+	send := c.Bool("send")
+	for i, batch := range batches {
+		if len(batch.inputs) < 2 {
+			continue
+		}
+		txn, fee, err := createConsolidationTransaction(batch, *programHash,
+			redeemScript, *feeRate)
+		if err != nil {
+			fmt.Println("skip batch", i, "error:", err)
+			continue
+		}
+		txn, err = client.Sign(txn)
+		if err != nil {
+			return err
+		}
+		buf := new(bytes.Buffer)
+		if err := txn.Serialize(buf); err != nil {
+			return err
+		}
+		txHex := common.BytesToHexString(buf.Bytes())
+		fmt.Printf("Batch %d: inputs %d, amount %s, fee %s, size %d\n", i,
+			len(batch.inputs), batch.amount, fee, buf.Len())
+		haveSign, needSign, _ := crypto.GetSignStatus(txn.Programs[0].Code,
+			txn.Programs[0].Parameter)
+		if !send || haveSign < needSign {
+			fmt.Println("[", haveSign, "/", needSign, "] Hex:", txHex)
+			continue
+		}
+
+		result, err := cmdcom.RPCCall("sendrawtransaction",
+			http.Params{"data": txHex})
+		if err != nil {
+			return err
+		}
+		fmt.Println("Transaction sent:", result)
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/servers"
+
+	"github.com/urfave/cli"
+)
+
+// lockedUnspentExt is the file extension of the locked utxos file, which is
+// saved next to the keystore file.
+const lockedUnspentExt = ".locked.json"
+
+var lockUnspentCommand = []cli.Command{
+	{
+		Category:    "Transaction",
+		Name:        "lockunspent",
+		Usage:       "Lock utxos to exclude them from coin selection",
+		Description: "use --utxos to lock utxos, such as the utxos backing votes, so they are not spent by the transactions built by this wallet, use --unlock to unlock them",
+		Flags: []cli.Flag{
+			cmdcom.LockUnspentOutPointsFlag,
+			cmdcom.LockUnspentUnlockFlag,
+			cmdcom.AccountWalletFlag,
+		},
+		Action: lockUnspent,
+	},
+	{
+		Category: "Transaction",
+		Name:     "listlockunspent",
+		Usage:    "List the locked utxos",
+		Flags: []cli.Flag{
+			cmdcom.AccountWalletFlag,
+		},
+		Action: listLockUnspent,
+	},
+}
+
+// lockedUnspentPath returns the path of the locked utxos file of the wallet.
+func lockedUnspentPath(walletPath string) string {
+	return strings.TrimSuffix(walletPath, filepath.Ext(walletPath)) +
+		lockedUnspentExt
+}
+
+// parseOutPoint parses the utxo in format of "txid:vout", the result is
+// normalized so that the same utxo is always in the same format.
+func parseOutPoint(str string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(str), ":")
+	if len(parts) != 2 {
+		return "", errors.New("invalid utxo " + str +
+			", should be txid:vout")
+	}
+	txID, err := common.HexStringToBytes(parts[0])
+	if err != nil || len(txID) != common.UINT256SIZE {
+		return "", errors.New("invalid txid of utxo " + str)
+	}
+	vout, err := strconv.ParseUint(parts[1], 10, 16)
+	if err != nil {
+		return "", errors.New("invalid vout of utxo " + str)
+	}
+	return fmt.Sprintf("%s:%d", common.BytesToHexString(txID), vout), nil
+}
+
+// loadLockedUnspent returns the locked utxos of the wallet, it's empty if the
+// locked utxos file does not exist.
+func loadLockedUnspent(walletPath string) ([]string, error) {
+	data, err := ioutil.ReadFile(lockedUnspentPath(walletPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var outPoints []string
+	if err := json.Unmarshal(data, &outPoints); err != nil {
+		return nil, errors.New("invalid locked utxos file: " + err.Error())
+	}
+	return outPoints, nil
+}
+
+// saveLockedUnspent saves the locked utxos of the wallet in order.
+func saveLockedUnspent(walletPath string, outPoints []string) error {
+	sort.Strings(outPoints)
+	data, err := json.MarshalIndent(outPoints, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(lockedUnspentPath(walletPath), data, 0600)
+}
+
+// removeLockedUTXOs returns the utxos not locked by the wallet.
+func removeLockedUTXOs(walletPath string,
+	utxos []servers.UTXOInfo) ([]servers.UTXOInfo, error) {
+	locked, err := loadLockedUnspent(walletPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(locked) == 0 {
+		return utxos, nil
+	}
+	set := make(map[string]struct{}, len(locked))
+	for _, outPoint := range locked {
+		set[outPoint] = struct{}{}
+	}
+
+	result := make([]servers.UTXOInfo, 0, len(utxos))
+	for _, utxo := range utxos {
+		outPoint, err := parseOutPoint(fmt.Sprintf("%s:%d", utxo.TxID,
+			utxo.VOut))
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := set[outPoint]; ok {
+			continue
+		}
+		result = append(result, utxo)
+	}
+	return result, nil
+}
+
+func lockUnspent(c *cli.Context) error {
+	walletPath := cmdcom.GetWalletPath(c)
+	unlock := c.Bool("unlock")
+
+	var outPoints []string
+	if utxos := c.String("utxos"); utxos != "" {
+		for _, str := range strings.Split(utxos, ",") {
+			outPoint, err := parseOutPoint(str)
+			if err != nil {
+				return err
+			}
+			outPoints = append(outPoints, outPoint)
+		}
+	}
+	if len(outPoints) == 0 && !unlock {
+		return errors.New("use --utxos to specify the utxos to lock")
+	}
+
+	locked, err := loadLockedUnspent(walletPath)
+	if err != nil {
+		return err
+	}
+	set := make(map[string]struct{}, len(locked))
+	for _, outPoint := range locked {
+		set[outPoint] = struct{}{}
+	}
+	switch {
+	case unlock && len(outPoints) == 0:
+		set = make(map[string]struct{})
+	case unlock:
+		for _, outPoint := range outPoints {
+			delete(set, outPoint)
+		}
+	default:
+		for _, outPoint := range outPoints {
+			set[outPoint] = struct{}{}
+		}
+	}
+
+	result := make([]string, 0, len(set))
+	for outPoint := range set {
+		result = append(result, outPoint)
+	}
+	if err := saveLockedUnspent(walletPath, result); err != nil {
+		return err
+	}
+	fmt.Println("Locked utxos:", len(result))
+	return nil
+}
+
+func listLockUnspent(c *cli.Context) error {
+	locked, err := loadLockedUnspent(cmdcom.GetWalletPath(c))
+	if err != nil {
+		return err
+	}
+	for _, outPoint := range locked {
+		fmt.Println(outPoint)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/elastos/Elastos.ELA/account"
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+
+	"github.com/urfave/cli"
+)
+
+// paymentURIScheme is the scheme of the payment URI, a payment URI is in
+// format of "elastos:<address>?amount=<ELA>&memo=<memo>", the amount and the
+// memo are optional.
+const paymentURIScheme = "elastos"
+
+var paymentURICommand = []cli.Command{
+	{
+		Category:    "Transaction",
+		Name:        "paymenturi",
+		Usage:       "Generate a payment URI and show it as a QR code",
+		Description: "use --to or --depositof to specify the recipient, the main account of the wallet is used if neither is specified, the URI can be scanned by mobile wallets",
+		Flags: []cli.Flag{
+			cmdcom.TransactionToFlag,
+			cmdcom.PaymentDepositOfFlag,
+			cmdcom.TransactionAmountFlag,
+			cmdcom.PaymentMemoFlag,
+			cmdcom.AccountWalletFlag,
+		},
+		Action: paymentURI,
+	},
+	{
+		Category:  "Transaction",
+		Name:      "parseuri",
+		Usage:     "Parse a payment URI",
+		ArgsUsage: "<uri>",
+		Action:    parsePaymentURI,
+	},
+}
+
+// PaymentRequest is the payment described by a payment URI.
+type PaymentRequest struct {
+	Address string
+	Amount  *common.Fixed64
+	Memo    string
+}
+
+// URI returns the payment URI of the payment request.
+func (r *PaymentRequest) URI() string {
+	values := url.Values{}
+	if r.Amount != nil {
+		values.Set("amount", r.Amount.String())
+	}
+	if r.Memo != "" {
+		values.Set("memo", r.Memo)
+	}
+	uri := url.URL{
+		Scheme:   paymentURIScheme,
+		Opaque:   r.Address,
+		RawQuery: values.Encode(),
+	}
+	return uri.String()
+}
+
+// ParsePaymentURI parses the payment request of the payment URI, the
+// "elastos://<address>" form used by some wallets is also accepted.
+func ParsePaymentURI(str string) (*PaymentRequest, error) {
+	uri, err := url.Parse(strings.TrimSpace(str))
+	if err != nil {
+		return nil, errors.New("invalid payment uri: " + err.Error())
+	}
+	if !strings.EqualFold(uri.Scheme, paymentURIScheme) {
+		return nil, errors.New("invalid payment uri scheme " + uri.Scheme)
+	}
+	address := uri.Opaque
+	if address == "" {
+		address = uri.Host
+	}
+	if _, err := common.Uint168FromAddress(address); err != nil {
+		return nil, errors.New("invalid address of payment uri " + address)
+	}
+
+	request := &PaymentRequest{Address: address}
+	values := uri.Query()
+	if amountStr := values.Get("amount"); amountStr != "" {
+		amount, err := common.StringToFixed64(amountStr)
+		if err != nil || *amount <= 0 {
+			return nil, errors.New("invalid amount of payment uri " +
+				amountStr)
+		}
+		request.Amount = amount
+	}
+	request.Memo = values.Get("memo")
+	return request, nil
+}
+
+// paymentAddress returns the recipient address of the payment uri command.
+func paymentAddress(c *cli.Context) (string, error) {
+	to := c.String("to")
+	depositOf := c.String("depositof")
+	switch {
+	case to != "" && depositOf != "":
+		return "", errors.New("'--to' cannot be specified when specify" +
+			" '--depositof' option")
+	case to != "":
+		if _, err := common.Uint168FromAddress(to); err != nil {
+			return "", errors.New("invalid recipient address " + to)
+		}
+		return to, nil
+	case depositOf != "":
+		publicKey, err := common.HexStringToBytes(depositOf)
+		if err != nil {
+			return "", errors.New("invalid public key " + depositOf)
+		}
+		programHash, err := contract.PublicKeyToDepositProgramHash(publicKey)
+		if err != nil {
+			return "", err
+		}
+		return programHash.ToAddress()
+	default:
+		mainAccount, err := account.GetWalletMainAccountData(
+			cmdcom.GetWalletPath(c))
+		if err != nil {
+			return "", err
+		}
+		return mainAccount.Address, nil
+	}
+}
+
+func paymentURI(c *cli.Context) error {
+	address, err := paymentAddress(c)
+	if err != nil {
+		return err
+	}
+	request := &PaymentRequest{Address: address, Memo: c.String("memo")}
+	if amountStr := c.String("amount"); amountStr != "" {
+		amount, err := common.StringToFixed64(amountStr)
+		if err != nil || *amount <= 0 {
+			return errors.New("invalid amount " + amountStr)
+		}
+		request.Amount = amount
+	}
+
+	uri := request.URI()
+	if err := cmdcom.PrintQRCode(uri); err != nil {
+		return err
+	}
+	fmt.Println(uri)
+	return nil
+}
+
+func parsePaymentURI(c *cli.Context) error {
+	if c.NArg() < 1 {
+		cli.ShowCommandHelp(c, "parseuri")
+		return errors.New("missing argument, payment uri expected")
+	}
+	request, err := ParsePaymentURI(c.Args().First())
+	if err != nil {
+		return err
+	}
+	fmt.Println("Address:", request.Address)
+	if request.Amount != nil {
+		fmt.Println("Amount: ", request.Amount.String())
+	}
+	if request.Memo != "" {
+		fmt.Println("Memo:   ", request.Memo)
+	}
+	return nil
+}
@@ -27,12 +27,14 @@ var txCommand = []cli.Command{
 		Category:    "Transaction",
 		Name:        "buildtx",
 		Usage:       "Build a transaction",
-		Description: "use --to --amount --fee to create a transaction",
+		Description: "use --to --amount --fee or --uri --fee to create a transaction",
 		Flags: []cli.Flag{
 			cmdcom.TransactionFromFlag,
 			cmdcom.TransactionToFlag,
 			cmdcom.TransactionToManyFlag,
 			cmdcom.TransactionAmountFlag,
+			cmdcom.PaymentURIFlag,
+			cmdcom.PaymentMemoFlag,
 			cmdcom.TransactionFeeFlag,
 			cmdcom.TransactionOutputLockFlag,
 			cmdcom.TransactionTxLockFlag,
@@ -172,7 +174,7 @@ func signTx(c *cli.Context) error {
 		cli.ShowSubcommandHelp(c)
 		return nil
 	}
-	walletPath := c.String("wallet")
+	walletPath := cmdcom.GetWalletPath(c)
 	password, err := cmdcom.GetFlagPassword(c)
 	if err != nil {
 		return err
@@ -39,7 +39,7 @@ type CrossChainOutput struct {
 }
 
 func CreateTransaction(c *cli.Context) error {
-	walletPath := c.String("wallet")
+	walletPath := cmdcom.GetWalletPath(c)
 
 	feeStr := c.String("fee")
 	if feeStr == "" {
@@ -57,6 +57,26 @@ func CreateTransaction(c *cli.Context) error {
 	to := c.String("to")
 	amountStr := c.String("amount")
 	toMany := c.String("tomany")
+	memo := c.String("memo")
+	if uri := c.String("uri"); uri != "" {
+		if to != "" || toMany != "" {
+			return errors.New("'--to' or '--tomany' cannot be specified when specify '--uri' option")
+		}
+		request, err := ParsePaymentURI(uri)
+		if err != nil {
+			return err
+		}
+		to = request.Address
+		if request.Amount != nil {
+			if amountStr != "" {
+				return errors.New("'--amount' cannot be specified when the payment uri has an amount")
+			}
+			amountStr = request.Amount.String()
+		}
+		if memo == "" {
+			memo = request.Memo
+		}
+	}
 	if toMany != "" {
 		if to != "" {
 			return errors.New("'--to' cannot be specified when specify '--tomany' option")
@@ -106,6 +126,10 @@ func CreateTransaction(c *cli.Context) error {
 	if err != nil {
 		return errors.New("create transaction failed: " + err.Error())
 	}
+	if memo != "" {
+		memoAttr := types.NewAttribute(types.Memo, []byte(memo))
+		txn.Attributes = append(txn.Attributes, &memoAttr)
+	}
 
 	OutputTx(0, 1, txn)
 
@@ -141,8 +165,13 @@ func getSender(walletPath string, from string) (*account.AccountData, error) {
 	return sender, nil
 }
 
-func createInputs(sender *account.AccountData, totalAmount common.Fixed64) ([]*types.Input, []*types.Output, error) {
-	UTXOs, err := getUTXOsByAmount(sender.Address, totalAmount)
+func createInputs(walletPath string, sender *account.AccountData, totalAmount common.Fixed64) ([]*types.Input, []*types.Output, error) {
+	// the locked utxos of the wallet are never selected
+	locked, err := loadLockedUnspent(walletPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	UTXOs, err := getUTXOsByAmount(sender.Address, totalAmount, locked)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -286,7 +315,7 @@ func createTransaction(walletPath string, from string, fee common.Fixed64, outpu
 	}
 
 	// create inputs
-	txInputs, changeOutputs, err := createInputs(sender, totalAmount)
+	txInputs, changeOutputs, err := createInputs(walletPath, sender, totalAmount)
 	if err != nil {
 		return nil, err
 	}
@@ -320,7 +349,7 @@ func createTransaction(walletPath string, from string, fee common.Fixed64, outpu
 }
 
 func CreateActivateProducerTransaction(c *cli.Context) error {
-	walletPath := c.String("wallet")
+	walletPath := cmdcom.GetWalletPath(c)
 	password, err := cmdcom.GetFlagPassword(c)
 	if err != nil {
 		return err
@@ -389,7 +418,7 @@ func CreateActivateProducerTransaction(c *cli.Context) error {
 }
 
 func CreateVoteTransaction(c *cli.Context) error {
-	walletPath := c.String("wallet")
+	walletPath := cmdcom.GetWalletPath(c)
 
 	feeStr := c.String("fee")
 	if feeStr == "" {
@@ -435,7 +464,7 @@ func CreateVoteTransaction(c *cli.Context) error {
 	}
 
 	// create inputs
-	txInputs, changeOutputs, err := createInputs(sender, totalAmount)
+	txInputs, changeOutputs, err := createInputs(walletPath, sender, totalAmount)
 	if err != nil {
 		return err
 	}
@@ -474,7 +503,7 @@ func CreateVoteTransaction(c *cli.Context) error {
 }
 
 func CreateCrossChainTransaction(c *cli.Context) error {
-	walletPath := c.String("wallet")
+	walletPath := cmdcom.GetWalletPath(c)
 
 	from := c.String("from")
 	to := c.String("to")
@@ -554,7 +583,7 @@ func createCrossChainTransaction(walletPath string, from string, fee common.Fixe
 	}
 
 	// create inputs
-	txInputs, changeOutputs, err := createInputs(sender, totalAmount)
+	txInputs, changeOutputs, err := createInputs(walletPath, sender, totalAmount)
 	if err != nil {
 		return nil, err
 	}
@@ -12,6 +12,9 @@ import (
 func NewCommand() *cli.Command {
 	var subCommands []cli.Command
 	subCommands = append(subCommands, txCommand...)
+	subCommands = append(subCommands, consolidateCommand)
+	subCommands = append(subCommands, lockUnspentCommand...)
+	subCommands = append(subCommands, paymentURICommand...)
 	subCommands = append(subCommands, accountCommand...)
 
 	return &cli.Command{
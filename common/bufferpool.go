@@ -0,0 +1,54 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package common
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBufferSize is the max capacity of a buffer put back to the pool,
+// larger buffers are left to the garbage collector so the pool will not hold
+// memory of occasional large objects such as blocks.
+const maxPooledBufferSize = 1024 * 1024
+
+// bufferPool defines a concurrent safe free list of buffers used to serialize
+// objects temporarily, such as calculating hashes of transactions during block
+// validation and relay, in order to reduce allocations and GC pressure.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// BorrowBuffer returns an empty buffer from the free list.  A new buffer is
+// allocated if there are not any available on the free list.  The buffer
+// should be put back by ReturnBuffer when the caller is done with it, and
+// must not be used after that.
+func BorrowBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// ReturnBuffer resets the buffer obtained via BorrowBuffer and puts it back
+// on the free list.
+func ReturnBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// SizeWriter is an io.Writer counts the bytes written without storing them,
+// it's used to calculate the serialized size of objects.
+type SizeWriter struct {
+	Size int
+}
+
+func (w *SizeWriter) Write(p []byte) (int, error) {
+	w.Size += len(p)
+	return len(p), nil
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package common
+
+import (
+	"testing"
+)
+
+func TestBorrowBuffer(t *testing.T) {
+	buf := BorrowBuffer()
+	buf.Write([]byte("data"))
+	ReturnBuffer(buf)
+
+	// buffers are always empty when borrowed.
+	buf = BorrowBuffer()
+	if buf.Len() != 0 {
+		t.Errorf("borrowed buffer is not empty, length %d", buf.Len())
+	}
+	ReturnBuffer(buf)
+}
+
+func TestSizeWriter(t *testing.T) {
+	var w SizeWriter
+	f := Fixed64(100)
+	if err := f.Serialize(&w); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteVarBytes(&w, make([]byte, 300)); err != nil {
+		t.Fatal(err)
+	}
+	if w.Size != 8+3+300 {
+		t.Errorf("size %d, expect %d", w.Size, 8+3+300)
+	}
+}
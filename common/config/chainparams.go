@@ -0,0 +1,234 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+// ChainParamsFile defines a private network by overriding the chain
+// parameters of the active network without recompilation, parameters not set
+// in the file keep the values of the active network.  Fields other than
+// FoundationAddress, CRCAddress and GenesisTimestamp are named after the
+// fields of Params they override.
+type ChainParamsFile struct {
+	Magic                       *uint32         `json:"Magic"`
+	DefaultPort                 *uint16         `json:"DefaultPort"`
+	DNSSeeds                    []string        `json:"DNSSeeds"`
+	FoundationAddress           *string         `json:"FoundationAddress"`
+	CRCAddress                  *string         `json:"CRCAddress"`
+	GenesisTimestamp            *uint32         `json:"GenesisTimestamp"`
+	DPoSMagic                   *uint32         `json:"DPoSMagic"`
+	DPoSDefaultPort             *uint16         `json:"DPoSDefaultPort"`
+	OriginArbiters              []string        `json:"OriginArbiters"`
+	CRCArbiters                 []string        `json:"CRCArbiters"`
+	GeneralArbiters             *int            `json:"GeneralArbiters"`
+	CandidateArbiters           *int            `json:"CandidateArbiters"`
+	PowLimitBits                *uint32         `json:"PowLimitBits"`
+	RewardPerBlock              *common.Fixed64 `json:"RewardPerBlock"`
+	CoinbaseMaturity            *uint32         `json:"CoinbaseMaturity"`
+	FoundationRewardRatio       *float64        `json:"FoundationRewardRatio"`
+	DPoSRewardRatio             *float64        `json:"DPoSRewardRatio"`
+	RewardPolicies              []RewardPolicy  `json:"RewardPolicies"`
+	CheckAddressHeight          *uint32         `json:"CheckAddressHeight"`
+	VoteStartHeight             *uint32         `json:"VoteStartHeight"`
+	CRCOnlyDPOSHeight           *uint32         `json:"CRCOnlyDPOSHeight"`
+	PublicDPOSHeight            *uint32         `json:"PublicDPOSHeight"`
+	EnableActivateIllegalHeight *uint32         `json:"EnableActivateIllegalHeight"`
+	CRVotingStartHeight         *uint32         `json:"CRVotingStartHeight"`
+	CRCommitteeStartHeight      *uint32         `json:"CRCommitteeStartHeight"`
+	CheckRewardHeight           *uint32         `json:"CheckRewardHeight"`
+	VoteStatisticsHeight        *uint32         `json:"VoteStatisticsHeight"`
+	RegisterCRByDIDHeight       *uint32         `json:"RegisterCRByDIDHeight"`
+	NamePolicyHeight            *uint32         `json:"NamePolicyHeight"`
+	NicknameFoldHeight          *uint32         `json:"NicknameFoldHeight"`
+	ProducerInfoStakeHeight     *uint32         `json:"ProducerInfoStakeHeight"`
+	RevokeVoteHeight            *uint32         `json:"RevokeVoteHeight"`
+	UnderstaffedRecoveryHeight  *uint32         `json:"UnderstaffedRecoveryHeight"`
+	SideChainTxProofHeight      *uint32         `json:"SideChainTxProofHeight"`
+	VotePolicyHeight            *uint32         `json:"VotePolicyHeight"`
+	VoteDecayHeight             *uint32         `json:"VoteDecayHeight"`
+	NodeKeyRotationHeight       *uint32         `json:"NodeKeyRotationHeight"`
+	CRCandidateTieBreakHeight   *uint32         `json:"CRCandidateTieBreakHeight"`
+	CRRegistrationWindowHeight  *uint32         `json:"CRRegistrationWindowHeight"`
+	NodeKeyRotationCooldown     *uint32         `json:"NodeKeyRotationCooldown"`
+	CRMemberCount               *uint32         `json:"CRMemberCount"`
+	CRVotingPeriod              *uint32         `json:"CRVotingPeriod"`
+	CRDutyPeriod                *uint32         `json:"CRDutyPeriod"`
+	CRNicknameCommitHeight      *uint32         `json:"CRNicknameCommitHeight"`
+	CRNicknameCommitExpiry      *uint32         `json:"CRNicknameCommitExpiry"`
+}
+
+// LoadChainParamsFile reads the chain parameters file, unknown fields are
+// rejected so that misspelled parameters do not pass silently.
+func LoadChainParamsFile(path string) (*ChainParamsFile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// Remove the UTF-8 Byte Order Mark
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	var file ChainParamsFile
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, errors.New("chain params file parsing failed, " +
+			err.Error())
+	}
+	return &file, nil
+}
+
+// Apply returns a copy of params overridden by the file, and the differences
+// against params in "Name: old -> new" format.
+func (f *ChainParamsFile) Apply(params *Params) (*Params, []string, error) {
+	result := *params
+	var diffs []string
+	diff := func(name string, old, new interface{}) {
+		if !reflect.DeepEqual(old, new) {
+			diffs = append(diffs, fmt.Sprintf("%s: %v -> %v", name, old, new))
+		}
+	}
+
+	src := reflect.ValueOf(f).Elem()
+	dst := reflect.ValueOf(&result).Elem()
+	for i := 0; i < src.NumField(); i++ {
+		name := src.Type().Field(i).Name
+		value := src.Field(i)
+		if value.IsNil() {
+			continue
+		}
+		if value.Kind() == reflect.Ptr {
+			value = value.Elem()
+		}
+		field := dst.FieldByName(name)
+		if !field.IsValid() || field.Type() != value.Type() {
+			continue
+		}
+		diff(name, field.Interface(), value.Interface())
+		field.Set(value)
+	}
+
+	if f.FoundationAddress != nil {
+		foundation, err := common.Uint168FromAddress(*f.FoundationAddress)
+		if err != nil {
+			return nil, nil, errors.New("invalid FoundationAddress")
+		}
+		result.Foundation = *foundation
+	}
+	if f.CRCAddress != nil {
+		crcAddress, err := common.Uint168FromAddress(*f.CRCAddress)
+		if err != nil {
+			return nil, nil, errors.New("invalid CRCAddress")
+		}
+		result.CRCAddress = *crcAddress
+	}
+	diff("Foundation", addressString(params.Foundation),
+		addressString(result.Foundation))
+	diff("CRCAddress", addressString(params.CRCAddress),
+		addressString(result.CRCAddress))
+
+	if f.FoundationAddress != nil || f.GenesisTimestamp != nil {
+		result.GenesisBlock = GenesisBlock(&result.Foundation)
+		if f.GenesisTimestamp != nil {
+			result.GenesisBlock.Header.Timestamp = *f.GenesisTimestamp
+		}
+		diff("GenesisBlock", params.GenesisBlock.Hash().String(),
+			result.GenesisBlock.Hash().String())
+	}
+
+	return &result, diffs, nil
+}
+
+// ValidatePrivateNet checks the parameters of a private network defined by a
+// chain parameters file.
+func ValidatePrivateNet(params *Params) error {
+	for _, magic := range []uint32{DefaultParams.Magic,
+		DefaultParams.TestNet().Magic, DefaultParams.RegNet().Magic} {
+		if params.Magic == magic {
+			return fmt.Errorf("Magic %d is used by a public network", magic)
+		}
+	}
+
+	if len(params.CRCArbiters) == 0 {
+		return errors.New("CRCArbiters can not be empty")
+	}
+	keys := make(map[string]struct{})
+	for _, arbiters := range [][]string{params.OriginArbiters,
+		params.CRCArbiters} {
+		for _, a := range arbiters {
+			pk, err := common.HexStringToBytes(a)
+			if err != nil {
+				return fmt.Errorf("invalid arbiter public key %s", a)
+			}
+			if _, err := crypto.DecodePoint(pk); err != nil {
+				return fmt.Errorf("invalid arbiter public key %s", a)
+			}
+			if _, ok := keys[a]; ok {
+				return fmt.Errorf("duplicated arbiter public key %s", a)
+			}
+			keys[a] = struct{}{}
+		}
+	}
+	if params.GeneralArbiters < 0 || params.CandidateArbiters < 0 {
+		return errors.New("arbiters count can not be negative")
+	}
+
+	if params.VoteStartHeight > params.CRCOnlyDPOSHeight ||
+		params.CRCOnlyDPOSHeight > params.PublicDPOSHeight {
+		return errors.New("heights should be VoteStartHeight <= " +
+			"CRCOnlyDPOSHeight <= PublicDPOSHeight")
+	}
+	if params.CRVotingStartHeight > params.CRCommitteeStartHeight {
+		return errors.New("CRVotingStartHeight should not be higher than " +
+			"CRCommitteeStartHeight")
+	}
+	if params.CRVotingPeriod > params.CRDutyPeriod {
+		return errors.New("CRVotingPeriod should not be longer than " +
+			"CRDutyPeriod")
+	}
+
+	if params.RewardPerBlock < 0 {
+		return errors.New("RewardPerBlock can not be negative")
+	}
+	if err := checkRewardRatios(params.FoundationRewardRatio,
+		params.DPoSRewardRatio); err != nil {
+		return err
+	}
+	for _, p := range params.RewardPolicies {
+		if err := checkRewardRatios(p.FoundationRewardRatio,
+			p.DPoSRewardRatio); err != nil {
+			return fmt.Errorf("reward policy at height %d, %s",
+				p.Height, err)
+		}
+	}
+
+	return nil
+}
+
+func checkRewardRatios(foundation, dpos float64) error {
+	if foundation < 0 || dpos < 0 || foundation+dpos > 1 {
+		return errors.New("reward ratios should not be negative or " +
+			"exceed 1 in total")
+	}
+	return nil
+}
+
+func addressString(programHash common.Uint168) string {
+	address, err := programHash.ToAddress()
+	if err != nil {
+		return programHash.String()
+	}
+	return address
+}
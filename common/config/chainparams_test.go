@@ -0,0 +1,118 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeChainParamsFile(t *testing.T, content string) string {
+	file, err := ioutil.TempFile("", "chainparams")
+	assert.NoError(t, err)
+	_, err = file.WriteString(content)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+	return file.Name()
+}
+
+func TestChainParamsFile_Apply(t *testing.T) {
+	path := writeChainParamsFile(t, `{
+		"Magic": 2020001,
+		"FoundationAddress": "8VYXVxKKSAxkmRrfmGpQR2Kc66XhG6m3ta",
+		"CRCArbiters": [
+			"0306e3deefee78e0e25f88e98f1f3290ccea98f08dd3a890616755f1a066c4b9b8"
+		],
+		"CRVotingStartHeight": 292000,
+		"CoinbaseMaturity": 10
+	}`)
+	defer os.Remove(path)
+
+	file, err := LoadChainParamsFile(path)
+	assert.NoError(t, err)
+
+	base := DefaultParams.RegNet()
+	params, diffs, err := file.Apply(base)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2020001), params.Magic)
+	assert.Equal(t, mainNetFoundation, params.Foundation)
+	assert.Equal(t, 1, len(params.CRCArbiters))
+	assert.Equal(t, uint32(10), params.CoinbaseMaturity)
+	assert.Equal(t, GenesisBlock(&mainNetFoundation).Hash(),
+		params.GenesisBlock.Hash())
+	assert.Equal(t, base.OriginArbiters, params.OriginArbiters)
+
+	// base params should not be changed
+	assert.Equal(t, uint32(2018201), base.Magic)
+
+	// CRVotingStartHeight is not changed
+	assert.Equal(t, []string{
+		"Magic: 2018201 -> 2020001",
+		"CRCArbiters: " + "[" + strings.Join(base.CRCArbiters, " ") + "] -> " +
+			"[0306e3deefee78e0e25f88e98f1f3290ccea98f08dd3a890616755f1a066c4b9b8]",
+		"CoinbaseMaturity: 100 -> 10",
+		"Foundation: 8ZNizBf4KhhPjeJRGpox6rPcHE5Np6tFx3 -> " +
+			"8VYXVxKKSAxkmRrfmGpQR2Kc66XhG6m3ta",
+		"GenesisBlock: " + base.GenesisBlock.Hash().String() + " -> " +
+			params.GenesisBlock.Hash().String(),
+	}, diffs)
+
+	assert.NoError(t, ValidatePrivateNet(params))
+}
+
+func TestChainParamsFile_Invalid(t *testing.T) {
+	// unknown field
+	path := writeChainParamsFile(t, `{"Magics": 2020001}`)
+	defer os.Remove(path)
+	_, err := LoadChainParamsFile(path)
+	assert.Error(t, err)
+
+	// invalid address
+	address := "invalid"
+	file := &ChainParamsFile{CRCAddress: &address}
+	_, _, err = file.Apply(DefaultParams.RegNet())
+	assert.EqualError(t, err, "invalid CRCAddress")
+
+	// public network magic
+	params, _, err := (&ChainParamsFile{}).Apply(DefaultParams.RegNet())
+	assert.NoError(t, err)
+	assert.EqualError(t, ValidatePrivateNet(params),
+		"Magic 2018201 is used by a public network")
+
+	// invalid arbiter
+	magic := uint32(2020001)
+	params, _, err = (&ChainParamsFile{
+		Magic:       &magic,
+		CRCArbiters: []string{"02"},
+	}).Apply(DefaultParams.RegNet())
+	assert.NoError(t, err)
+	assert.EqualError(t, ValidatePrivateNet(params),
+		"invalid arbiter public key 02")
+
+	// heights out of order
+	height := uint32(0)
+	params, _, err = (&ChainParamsFile{
+		Magic:            &magic,
+		PublicDPOSHeight: &height,
+	}).Apply(DefaultParams.RegNet())
+	assert.NoError(t, err)
+	assert.EqualError(t, ValidatePrivateNet(params), "heights should be "+
+		"VoteStartHeight <= CRCOnlyDPOSHeight <= PublicDPOSHeight")
+
+	// reward ratios
+	ratio := 0.8
+	params, _, err = (&ChainParamsFile{
+		Magic:           &magic,
+		DPoSRewardRatio: &ratio,
+	}).Apply(DefaultParams.RegNet())
+	assert.NoError(t, err)
+	assert.EqualError(t, ValidatePrivateNet(params), "reward ratios should "+
+		"not be negative or exceed 1 in total")
+}
@@ -27,53 +27,113 @@ type PowConfiguration struct {
 
 // RpcConfiguration defines the JSON-RPC authenticate parameters.
 type RpcConfiguration struct {
-	User        string   `json:"User"`
-	Pass        string   `json:"Pass"`
-	WhiteIPList []string `json:"WhiteIPList"`
+	User           string      `json:"User"`
+	Pass           string      `json:"Pass"`
+	WhiteIPList    []string    `json:"WhiteIPList"`
+	MaxBlocksRange uint32      `json:"MaxBlocksRange"`
+	AuditLog       RPCAuditLog `json:"AuditLog"`
+}
+
+// RPCAuditLog defines the parameters of the audit log recording
+// state-affecting RPC calls, sizes are measured in MB.
+type RPCAuditLog struct {
+	Enable        bool  `json:"Enable"`
+	MaxPerLogSize int64 `json:"MaxPerLogSize"`
+	MaxLogsSize   int64 `json:"MaxLogsSize"`
+}
+
+// HttpTLS defines the TLS parameters of the JSON-RPC, RESTful and websocket
+// servers.  Clients are required to present a certificate signed by the CA
+// in ClientCAPath if it is set.
+type HttpTLS struct {
+	EnableRPC    bool     `json:"EnableRPC"`
+	EnableRest   bool     `json:"EnableRest"`
+	EnableWs     bool     `json:"EnableWs"`
+	CertPath     string   `json:"CertPath"`
+	KeyPath      string   `json:"KeyPath"`
+	ClientCAPath string   `json:"ClientCAPath"`
+	MinVersion   string   `json:"MinVersion"`
+	CipherSuites []string `json:"CipherSuites"`
 }
 
 // Configuration defines the configurable parameters to run a ELA node.
 type Configuration struct {
-	ActiveNet                   string            `json:"ActiveNet"`
-	Magic                       uint32            `json:"Magic"`
-	DNSSeeds                    []string          `json:"DNSSeeds"`
-	DisableDNS                  bool              `json:"DisableDNS"`
-	PermanentPeers              []string          `json:"PermanentPeers"`
-	HttpInfoPort                uint16            `json:"HttpInfoPort"`
-	HttpInfoStart               bool              `json:"HttpInfoStart"`
-	HttpRestPort                int               `json:"HttpRestPort"`
-	HttpRestStart               bool              `json:"HttpRestStart"`
-	HttpWsPort                  int               `json:"HttpWsPort"`
-	HttpWsStart                 bool              `json:"HttpWsStart"`
-	HttpJsonPort                int               `json:"HttpJsonPort"`
-	EnableRPC                   bool              `json:"EnableRPC"`
-	NodePort                    uint16            `json:"NodePort"`
-	PrintLevel                  elalog.Level      `json:"PrintLevel"`
-	MaxLogsSize                 int64             `json:"MaxLogsSize"`
-	MaxPerLogSize               int64             `json:"MaxPerLogSize"`
-	RestCertPath                string            `json:"RestCertPath"`
-	RestKeyPath                 string            `json:"RestKeyPath"`
-	MinCrossChainTxFee          common.Fixed64    `json:"MinCrossChainTxFee"`
-	FoundationAddress           string            `json:"FoundationAddress"`
-	CRCAddress                  string            `json:"CRCAddress"`
-	PowConfiguration            PowConfiguration  `json:"PowConfiguration"`
-	RpcConfiguration            RpcConfiguration  `json:"RpcConfiguration"`
-	DPoSConfiguration           DPoSConfiguration `json:"DPoSConfiguration"`
-	CRConfiguration             CRConfiguration   `json:"CRConfiguration"`
-	CheckAddressHeight          uint32            `json:"CheckAddressHeight"`
-	VoteStartHeight             uint32            `json:"VoteStartHeight"`
-	CRCOnlyDPOSHeight           uint32            `json:"CRCOnlyDPOSHeight"`
-	PublicDPOSHeight            uint32            `json:"PublicDPOSHeight"`
-	EnableActivateIllegalHeight uint32            `json:"EnableActivateIllegalHeight"`
-	CRVotingStartHeight         uint32            `json:"CRVotingStartHeight"`
-	CRCommitteeStartHeight      uint32            `json:"CRCommitteeStartHeight"`
-	CheckRewardHeight           uint32            `json:"CheckRewardHeight"`
-	VoteStatisticsHeight        uint32            `json:"VoteStatisticsHeight"`
-	ProfilePort                 uint32            `json:"ProfilePort"`
-	MaxBlockSize                uint32            `json:"MaxBlockSize"`
-	EnableHistory               bool              `json:"EnableHistory"`
-	HistoryStartHeight          uint32            `json:"HistoryStartHeight"`
-	EnableUtxoDB                bool              `json:"EnableUtxoDB"`
+	ActiveNet                   string             `json:"ActiveNet"`
+	ChainParamsFile             string             `json:"ChainParamsFile"`
+	Magic                       uint32             `json:"Magic"`
+	DNSSeeds                    []string           `json:"DNSSeeds"`
+	DisableDNS                  bool               `json:"DisableDNS"`
+	PermanentPeers              []string           `json:"PermanentPeers"`
+	MaxPeers                    int                `json:"MaxPeers"`
+	PeerAllowList               []string           `json:"PeerAllowList"`
+	NAT                         NATConfig          `json:"NAT"`
+	PartitionMonitor            PartitionMonitor   `json:"PartitionMonitor"`
+	DraftData                   DraftData          `json:"DraftData"`
+	RankHistory                 RankHistory        `json:"RankHistory"`
+	RoundReward                 RoundReward        `json:"RoundReward"`
+	ElectionHistory             ElectionHistory    `json:"ElectionHistory"`
+	NodeIdentity                NodeIdentity       `json:"NodeIdentity"`
+	HttpInfoPort                uint16             `json:"HttpInfoPort"`
+	HttpInfoStart               bool               `json:"HttpInfoStart"`
+	HttpRestPort                int                `json:"HttpRestPort"`
+	HttpRestStart               bool               `json:"HttpRestStart"`
+	HttpWsPort                  int                `json:"HttpWsPort"`
+	HttpWsStart                 bool               `json:"HttpWsStart"`
+	HttpJsonPort                int                `json:"HttpJsonPort"`
+	EnableRPC                   bool               `json:"EnableRPC"`
+	NodePort                    uint16             `json:"NodePort"`
+	PrintLevel                  elalog.Level       `json:"PrintLevel"`
+	MaxLogsSize                 int64              `json:"MaxLogsSize"`
+	MaxPerLogSize               int64              `json:"MaxPerLogSize"`
+	RestCertPath                string             `json:"RestCertPath"`
+	RestKeyPath                 string             `json:"RestKeyPath"`
+	HttpTLS                     HttpTLS            `json:"HttpTLS"`
+	MinCrossChainTxFee          common.Fixed64     `json:"MinCrossChainTxFee"`
+	CoinbaseMaturity            uint32             `json:"CoinbaseMaturity"`
+	FoundationRewardRatio       float64            `json:"FoundationRewardRatio"`
+	DPoSRewardRatio             float64            `json:"DPoSRewardRatio"`
+	RewardPolicies              []RewardPolicy     `json:"RewardPolicies"`
+	FoundationAddress           string             `json:"FoundationAddress"`
+	CRCAddress                  string             `json:"CRCAddress"`
+	PowConfiguration            PowConfiguration   `json:"PowConfiguration"`
+	RpcConfiguration            RpcConfiguration   `json:"RpcConfiguration"`
+	DPoSConfiguration           DPoSConfiguration  `json:"DPoSConfiguration"`
+	CRConfiguration             CRConfiguration    `json:"CRConfiguration"`
+	CheckAddressHeight          uint32             `json:"CheckAddressHeight"`
+	VoteStartHeight             uint32             `json:"VoteStartHeight"`
+	CRCOnlyDPOSHeight           uint32             `json:"CRCOnlyDPOSHeight"`
+	PublicDPOSHeight            uint32             `json:"PublicDPOSHeight"`
+	EnableActivateIllegalHeight uint32             `json:"EnableActivateIllegalHeight"`
+	CRVotingStartHeight         uint32             `json:"CRVotingStartHeight"`
+	CRCommitteeStartHeight      uint32             `json:"CRCommitteeStartHeight"`
+	NamePolicyHeight            uint32             `json:"NamePolicyHeight"`
+	NicknameFoldHeight          uint32             `json:"NicknameFoldHeight"`
+	NamePolicy                  NamePolicyConfig   `json:"NamePolicy"`
+	TxPolicy                    TxPolicyConfig     `json:"TxPolicy"`
+	VotePolicyHeight            uint32             `json:"VotePolicyHeight"`
+	VotePolicy                  VotePolicyConfig   `json:"VotePolicy"`
+	VoteDecayHeight             uint32             `json:"VoteDecayHeight"`
+	NodeKeyRotationHeight       uint32             `json:"NodeKeyRotationHeight"`
+	CRCandidateTieBreakHeight   uint32             `json:"CRCandidateTieBreakHeight"`
+	CRRegistrationWindowHeight  uint32             `json:"CRRegistrationWindowHeight"`
+	ProducerInfoStakeHeight     uint32             `json:"ProducerInfoStakeHeight"`
+	RevokeVoteHeight            uint32             `json:"RevokeVoteHeight"`
+	UnderstaffedRecoveryHeight  uint32             `json:"UnderstaffedRecoveryHeight"`
+	SideChainTxProofHeight      uint32             `json:"SideChainTxProofHeight"`
+	CheckRewardHeight           uint32             `json:"CheckRewardHeight"`
+	VoteStatisticsHeight        uint32             `json:"VoteStatisticsHeight"`
+	ProfilePort                 uint32             `json:"ProfilePort"`
+	MaxBlockSize                uint32             `json:"MaxBlockSize"`
+	EnableHistory               bool               `json:"EnableHistory"`
+	HistoryStartHeight          uint32             `json:"HistoryStartHeight"`
+	EnableUtxoDB                bool               `json:"EnableUtxoDB"`
+	DBEngine                    string             `json:"DBEngine"`
+	HeadersFirst                bool               `json:"HeadersFirst"`
+	Checkpoints                 []CheckpointConfig `json:"Checkpoints"`
+	HaltHeight                  uint32             `json:"HaltHeight"`
+	CheckInvariants             bool               `json:"CheckInvariants"`
+	RewardWhitelist             []string           `json:"RewardWhitelist"`
+	Tracing                     Tracing            `json:"Tracing"`
 }
 
 // DPoSConfiguration defines the DPoS consensus parameters.
@@ -89,13 +149,132 @@ type DPoSConfiguration struct {
 	CandidatesCount          int            `json:"CandidatesCount"`
 	EmergencyInactivePenalty common.Fixed64 `json:"EmergencyInactivePenalty"`
 	MaxInactiveRounds        uint32         `json:"MaxInactiveRounds"`
+	VoteDecayInactiveRounds  uint32         `json:"VoteDecayInactiveRounds"`
+	VoteDecayRatio           float64        `json:"VoteDecayRatio"`
+	NodeKeyRotationCooldown  uint32         `json:"NodeKeyRotationCooldown"`
 	InactivePenalty          common.Fixed64 `json:"InactivePenalty"`
 	PreConnectOffset         uint32         `json:"PreConnectOffset"`
+	CheckPointRetainCount    uint32         `json:"CheckPointRetainCount"`
+	CheckPointArchiveSpan    uint32         `json:"CheckPointArchiveSpan"`
+	RemoteSigner             RemoteSigner   `json:"RemoteSigner"`
+	ProducerAlert            ProducerAlert  `json:"ProducerAlert"`
+}
+
+// RemoteSigner defines the parameters to request DPoS signatures from external
+// signing daemons instead of loading the arbiter private key in-process.
+type RemoteSigner struct {
+	Enable    bool          `json:"Enable"`
+	Sockets   []string      `json:"Sockets"`
+	PublicKey string        `json:"PublicKey"`
+	AuthToken string        `json:"AuthToken"`
+	Timeout   time.Duration `json:"Timeout"`
+}
+
+// ProducerAlert defines the hooks to notify operators when their producers
+// become inactive or illegal, or miss consecutive duty slots.
+type ProducerAlert struct {
+	Enable      bool     `json:"Enable"`
+	PublicKeys  []string `json:"PublicKeys"`
+	MissedSlots uint32   `json:"MissedSlots"`
+	WebHooks    []string `json:"WebHooks"`
+	Commands    []string `json:"Commands"`
+}
+
+// PartitionMonitor defines the parameters to detect the node falling behind
+// peers or peers splitting into clusters.
+type PartitionMonitor struct {
+	MaxBlocksBehind uint32 `json:"MaxBlocksBehind"`
+	MinClusterPeers int    `json:"MinClusterPeers"`
+	CheckInterval   uint32 `json:"CheckInterval"`
+}
+
+// DraftData defines the parameters of the proposal draft data service.
+type DraftData struct {
+	Enable  bool   `json:"Enable"`
+	MaxSize uint32 `json:"MaxSize"`
+}
+
+// RankHistory defines the parameters of the producer and CR candidate rank
+// history service.
+type RankHistory struct {
+	Enable   bool   `json:"Enable"`
+	Interval uint32 `json:"Interval"`
+}
+
+// RoundReward defines the parameters of the arbiters round reward service.
+type RoundReward struct {
+	Enable bool `json:"Enable"`
+}
+
+// ElectionHistory defines the parameters of the arbiters election history
+// service.
+type ElectionHistory struct {
+	Enable bool `json:"Enable"`
+}
+
+// NodeIdentity defines the key to sign the getnodestate attestations.
+type NodeIdentity struct {
+	Enable   bool   `json:"Enable"`
+	Keystore string `json:"Keystore"`
+}
+
+// Tracing defines the exporter of the OpenTelemetry spans of block
+// processing.
+type Tracing struct {
+	Enable      bool    `json:"Enable"`
+	Exporter    string  `json:"Exporter"`
+	Endpoint    string  `json:"Endpoint"`
+	Insecure    bool    `json:"Insecure"`
+	SampleRatio float64 `json:"SampleRatio"`
+}
+
+// NATConfig defines the NAT traversal methods to map the P2P and DPoS ports
+// on the NAT gateway, so that nodes behind routers can accept inbound
+// connections.
+type NATConfig struct {
+	UPnP    bool   `json:"UPnP"`
+	NATPMP  bool   `json:"NATPMP"`
+	Gateway string `json:"Gateway"`
+}
+
+// NamePolicyConfig defines the rules of nicknames and URLs of producers and
+// CR candidates.
+type NamePolicyConfig struct {
+	MaxNicknameLength int      `json:"MaxNicknameLength"`
+	MaxURLLength      int      `json:"MaxURLLength"`
+	URLSchemes        []string `json:"URLSchemes"`
+}
+
+// VotePolicyConfig defines the granularity rules of vote outputs.
+type VotePolicyConfig struct {
+	MinVoteAmount           common.Fixed64 `json:"MinVoteAmount"`
+	MaxCandidatesPerContent int            `json:"MaxCandidatesPerContent"`
+}
+
+// TxPolicyConfig defines the standardness rules of transactions accepted into
+// the transaction pool.
+type TxPolicyConfig struct {
+	MaxTxSize               int `json:"MaxTxSize"`
+	MaxInputs               int `json:"MaxInputs"`
+	MaxOutputs              int `json:"MaxOutputs"`
+	MaxProgramCodeSize      int `json:"MaxProgramCodeSize"`
+	MaxProgramParameterSize int `json:"MaxProgramParameterSize"`
+	MaxMemoSize             int `json:"MaxMemoSize"`
+}
+
+// CheckpointConfig defines a known good block by height and hash.
+type CheckpointConfig struct {
+	Height uint32 `json:"Height"`
+	Hash   string `json:"Hash"`
 }
 
 type CRConfiguration struct {
 	MemberCount           uint32 `json:"MemberCount"`
 	VotingPeriod          uint32 `json:"VotingPeriod"`
 	DutyPeriod            uint32 `json:"DutyPeriod"`
+	NominationPeriod      uint32 `json:"NominationPeriod"`
+	VotesCacheSize        int    `json:"VotesCacheSize"`
 	RegisterCRByDIDHeight uint32 `json:"RegisterCRByDIDHeight"`
+	NicknameCommitHeight  uint32 `json:"NicknameCommitHeight"`
+	NicknameCommitExpiry  uint32 `json:"NicknameCommitExpiry"`
 }
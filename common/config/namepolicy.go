@@ -0,0 +1,123 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NamePolicy defines the rules of nicknames and URLs of producers and CR
+// candidates, it is applied since NamePolicyHeight.
+type NamePolicy struct {
+	// MaxNicknameLength defines the maximum length of a nickname in bytes.
+	MaxNicknameLength int
+
+	// MaxURLLength defines the maximum length of a URL in bytes.
+	MaxURLLength int
+
+	// URLSchemes defines the allowed schemes of a URL.
+	URLSchemes []string
+}
+
+// CheckNickname checks if the nickname is allowed by the policy.
+func (p *NamePolicy) CheckNickname(nickname string) error {
+	if len(nickname) == 0 || len(nickname) > p.MaxNicknameLength {
+		return fmt.Errorf("nickname length should between 1 and %d",
+			p.MaxNicknameLength)
+	}
+
+	if !utf8.ValidString(nickname) {
+		return fmt.Errorf("nickname %s is not valid UTF-8", nickname)
+	}
+
+	if strings.TrimSpace(nickname) != nickname {
+		return fmt.Errorf("nickname %s has leading or trailing spaces",
+			nickname)
+	}
+
+	for _, r := range nickname {
+		if r != ' ' && !unicode.IsPrint(r) {
+			return fmt.Errorf("nickname %s has invalid character %q",
+				nickname, r)
+		}
+	}
+
+	return nil
+}
+
+// CheckURL checks if the URL is allowed by the policy, empty URL is allowed.
+func (p *NamePolicy) CheckURL(rawURL string) error {
+	if len(rawURL) == 0 {
+		return nil
+	}
+
+	if len(rawURL) > p.MaxURLLength {
+		return fmt.Errorf("url length should not be greater than %d",
+			p.MaxURLLength)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url %s", rawURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("url %s has no host", rawURL)
+	}
+
+	for _, s := range p.URLSchemes {
+		if strings.EqualFold(u.Scheme, s) {
+			return nil
+		}
+	}
+	return fmt.Errorf("url scheme %s is not allowed", u.Scheme)
+}
+
+// NormalizeNickname returns the normalized form of a nickname, nicknames with
+// the same normalized form are treated as duplicated.
+func (p *NamePolicy) NormalizeNickname(nickname string) string {
+	return strings.ToLower(strings.Join(strings.Fields(nickname), " "))
+}
+
+// FoldNickname returns the form of a nickname after Unicode compatibility
+// normalized and case folded, nicknames with the same folded form are treated
+// as duplicated since NicknameFoldHeight.  The folded form depends on the
+// Unicode tables of golang.org/x/text, so the version is pinned in glide.yaml.
+func FoldNickname(nickname string) string {
+	folded := norm.NFKC.String(cases.Fold().String(
+		norm.NFKD.String(nickname)))
+	return strings.Join(strings.Fields(folded), " ")
+}
+
+// FoldedNicknameCollisions returns the groups of nicknames with the same
+// folded form, both groups and nicknames in a group are sorted.
+func FoldedNicknameCollisions(nicknames map[string]struct{}) [][]string {
+	groups := make(map[string][]string)
+	for n := range nicknames {
+		folded := FoldNickname(n)
+		groups[folded] = append(groups[folded], n)
+	}
+
+	var collisions [][]string
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		sort.Strings(group)
+		collisions = append(collisions, group)
+	}
+	sort.Slice(collisions, func(i, j int) bool {
+		return collisions[i][0] < collisions[j][0]
+	})
+	return collisions
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamePolicy_CheckNickname(t *testing.T) {
+	policy := &DefaultParams.NamePolicy
+
+	assert.NoError(t, policy.CheckNickname("Producer 1"))
+	assert.NoError(t, policy.CheckNickname("节点一"))
+
+	assert.Error(t, policy.CheckNickname(""))
+	assert.Error(t, policy.CheckNickname(
+		strings.Repeat("a", policy.MaxNicknameLength+1)))
+	assert.Error(t, policy.CheckNickname(" Producer"))
+	assert.Error(t, policy.CheckNickname("Producer\n"))
+	assert.Error(t, policy.CheckNickname("Pro\tducer"))
+	assert.Error(t, policy.CheckNickname(string([]byte{0xff, 0xfe})))
+}
+
+func TestNamePolicy_CheckURL(t *testing.T) {
+	policy := &DefaultParams.NamePolicy
+
+	assert.NoError(t, policy.CheckURL(""))
+	assert.NoError(t, policy.CheckURL("https://www.elastos.org"))
+	assert.NoError(t, policy.CheckURL("HTTP://www.elastos.org/node"))
+
+	assert.Error(t, policy.CheckURL("ftp://www.elastos.org"))
+	assert.Error(t, policy.CheckURL("javascript:alert(1)"))
+	assert.Error(t, policy.CheckURL("www.elastos.org"))
+	assert.Error(t, policy.CheckURL("https://www.elastos.org/"+
+		strings.Repeat("a", policy.MaxURLLength)))
+}
+
+func TestNamePolicy_NormalizeNickname(t *testing.T) {
+	policy := &DefaultParams.NamePolicy
+
+	assert.Equal(t, policy.NormalizeNickname("Producer 1"),
+		policy.NormalizeNickname("producer   1"))
+	assert.Equal(t, policy.NormalizeNickname("PRODUCER 1"),
+		policy.NormalizeNickname(" producer 1 "))
+	assert.NotEqual(t, policy.NormalizeNickname("producer 1"),
+		policy.NormalizeNickname("producer1"))
+}
+
+func TestFoldNickname(t *testing.T) {
+	assert.Equal(t, FoldNickname("Producer 1"),
+		FoldNickname("PRODUCER   1"))
+	// full width and compatibility characters
+	assert.Equal(t, FoldNickname("producer 1"),
+		FoldNickname("ｐｒｏｄｕｃｅｒ　1"))
+	// composed and decomposed characters
+	assert.Equal(t, FoldNickname("Caf\u00e9"),
+		FoldNickname("cafe\u0301"))
+	// special case folding
+	assert.Equal(t, FoldNickname("Straße"),
+		FoldNickname("STRASSE"))
+	assert.NotEqual(t, FoldNickname("producer 1"),
+		FoldNickname("producer1"))
+}
+
+func TestFoldedNicknameCollisions(t *testing.T) {
+	collisions := FoldedNicknameCollisions(map[string]struct{}{
+		"Node":    {},
+		"NODE":    {},
+		"ｎｏｄｅ":    {},
+		"Other":   {},
+		"Straße":  {},
+		"strasse": {},
+	})
+	assert.Equal(t, [][]string{
+		{"NODE", "Node", "ｎｏｄｅ"},
+		{"Straße", "strasse"},
+	}, collisions)
+}
@@ -151,6 +151,8 @@ var DefaultParams = Params{
 	AdjustmentFactor:            4,               // 25% less, 400% more
 	RewardPerBlock:              rewardPerBlock(2 * time.Minute),
 	CoinbaseMaturity:            100,
+	FoundationRewardRatio:       0.3,
+	DPoSRewardRatio:             0.35,
 	MinTransactionFee:           100,
 	MinCrossChainTxFee:          10000,
 	CheckAddressHeight:          88812,
@@ -163,8 +165,22 @@ var DefaultParams = Params{
 	CheckRewardHeight:           436812,
 	VoteStatisticsHeight:        512881,
 	RegisterCRByDIDHeight:       598000,
+	NamePolicyHeight:            2000000, // todo correct me when height has been confirmed
+	NicknameFoldHeight:          2000000, // todo correct me when height has been confirmed
+	ProducerInfoStakeHeight:     2000000, // todo correct me when height has been confirmed
+	RevokeVoteHeight:            2000000, // todo correct me when height has been confirmed
+	UnderstaffedRecoveryHeight:  2000000, // todo correct me when height has been confirmed
+	SideChainTxProofHeight:      2000000, // todo correct me when height has been confirmed
+	VotePolicyHeight:            2000000, // todo correct me when height has been confirmed
+	VoteDecayHeight:             2000000, // todo correct me when height has been confirmed
+	NodeKeyRotationHeight:       2000000, // todo correct me when height has been confirmed
+	CRCandidateTieBreakHeight:   2000000, // todo correct me when height has been confirmed
+	CRRegistrationWindowHeight:  2000000, // todo correct me when height has been confirmed
 	ToleranceDuration:           5 * time.Second,
 	MaxInactiveRounds:           720 * 2,
+	VoteDecayInactiveRounds:     720 * 30,
+	VoteDecayRatio:              0.5,
+	NodeKeyRotationCooldown:     720 * 7,
 	InactivePenalty:             0, //there will be no penalty in this version
 	EmergencyInactivePenalty:    0, //there will be no penalty in this version
 	GeneralArbiters:             24,
@@ -173,13 +189,71 @@ var DefaultParams = Params{
 	CRMemberCount:               12,
 	CRVotingPeriod:              30 * 720,
 	CRDutyPeriod:                365 * 720,
+	CRNicknameCommitHeight:      2000000, // todo correct me when height has been confirmed
+	CRNicknameCommitExpiry:      3 * 720,
 	EnableUtxoDB:                true,
+	DBEngine:                    "leveldb",
+	NamePolicy: NamePolicy{
+		MaxNicknameLength: 64,
+		MaxURLLength:      100,
+		URLSchemes:        []string{"http", "https"},
+	},
+	TxPolicy: TxPolicy{
+		MaxTxSize:               500000,
+		MaxInputs:               2000,
+		MaxOutputs:              2000,
+		MaxProgramCodeSize:      3400,
+		MaxProgramParameterSize: 6600,
+		MaxMemoSize:             256,
+	},
+	VotePolicy: VotePolicy{
+		MinVoteAmount:           1000000,
+		MaxCandidatesPerContent: 36,
+	},
 	CkpManager: checkpoint.NewManager(&checkpoint.Config{
 		EnableHistory:      false,
 		HistoryStartHeight: uint32(0),
 	}),
 }
 
+// RewardPolicy defines the reward ratios and coinbase maturity from a height.
+type RewardPolicy struct {
+	Height                uint32  `json:"Height"`
+	FoundationRewardRatio float64 `json:"FoundationRewardRatio"`
+	DPoSRewardRatio       float64 `json:"DPoSRewardRatio"`
+	CoinbaseMaturity      uint32  `json:"CoinbaseMaturity"`
+}
+
+// GetRewardPolicy returns the reward ratios and coinbase maturity applied at
+// the height.
+func (p *Params) GetRewardPolicy(height uint32) RewardPolicy {
+	policy := RewardPolicy{
+		FoundationRewardRatio: p.FoundationRewardRatio,
+		DPoSRewardRatio:       p.DPoSRewardRatio,
+		CoinbaseMaturity:      p.CoinbaseMaturity,
+	}
+	for _, change := range p.RewardPolicies {
+		if change.Height <= height && change.Height >= policy.Height {
+			policy = change
+		}
+	}
+	return policy
+}
+
+// IsRewardAddressAllowed returns if the program hash is allowed to receive
+// the rewards of this node by RewardWhitelist.
+func (p *Params) IsRewardAddressAllowed(programHash common.Uint168) bool {
+	if len(p.RewardWhitelist) == 0 {
+		return true
+	}
+	for _, hash := range p.RewardWhitelist {
+		if hash.IsEqual(programHash) {
+			return true
+		}
+	}
+	return false
+}
+
 // TestNet returns the network parameters for the test network.
 func (p *Params) TestNet() *Params {
 	copy := *p
@@ -228,6 +302,18 @@ func (p *Params) TestNet() *Params {
 	copy.CheckRewardHeight = 100
 	copy.VoteStatisticsHeight = 0
 	copy.RegisterCRByDIDHeight = 483500
+	copy.NamePolicyHeight = 1000000           // todo correct me when height has been confirmed
+	copy.NicknameFoldHeight = 1000000         // todo correct me when height has been confirmed
+	copy.ProducerInfoStakeHeight = 1000000    // todo correct me when height has been confirmed
+	copy.RevokeVoteHeight = 1000000           // todo correct me when height has been confirmed
+	copy.UnderstaffedRecoveryHeight = 1000000 // todo correct me when height has been confirmed
+	copy.CRNicknameCommitHeight = 1000000     // todo correct me when height has been confirmed
+	copy.SideChainTxProofHeight = 1000000     // todo correct me when height has been confirmed
+	copy.VotePolicyHeight = 1000000           // todo correct me when height has been confirmed
+	copy.VoteDecayHeight = 1000000            // todo correct me when height has been confirmed
+	copy.NodeKeyRotationHeight = 1000000      // todo correct me when height has been confirmed
+	copy.CRCandidateTieBreakHeight = 1000000  // todo correct me when height has been confirmed
+	copy.CRRegistrationWindowHeight = 1000000 // todo correct me when height has been confirmed
 	copy.EnableUtxoDB = true
 	return &copy
 }
@@ -281,6 +367,18 @@ func (p *Params) RegNet() *Params {
 	copy.CheckRewardHeight = 280000
 	copy.VoteStatisticsHeight = 0
 	copy.RegisterCRByDIDHeight = 393000
+	copy.NamePolicyHeight = 1000000           // todo correct me when height has been confirmed
+	copy.NicknameFoldHeight = 1000000         // todo correct me when height has been confirmed
+	copy.ProducerInfoStakeHeight = 1000000    // todo correct me when height has been confirmed
+	copy.RevokeVoteHeight = 1000000           // todo correct me when height has been confirmed
+	copy.UnderstaffedRecoveryHeight = 1000000 // todo correct me when height has been confirmed
+	copy.CRNicknameCommitHeight = 1000000     // todo correct me when height has been confirmed
+	copy.SideChainTxProofHeight = 1000000     // todo correct me when height has been confirmed
+	copy.VotePolicyHeight = 1000000           // todo correct me when height has been confirmed
+	copy.VoteDecayHeight = 1000000            // todo correct me when height has been confirmed
+	copy.NodeKeyRotationHeight = 1000000      // todo correct me when height has been confirmed
+	copy.CRCandidateTieBreakHeight = 1000000  // todo correct me when height has been confirmed
+	copy.CRRegistrationWindowHeight = 1000000 // todo correct me when height has been confirmed
 	copy.EnableUtxoDB = true
 	return &copy
 }
@@ -294,6 +392,12 @@ func (p *Params) InstantBlock() *Params {
 	return &copy
 }
 
+// Checkpoint identifies a known good block in the block chain.
+type Checkpoint struct {
+	Height uint32
+	Hash   common.Uint256
+}
+
 type Params struct {
 	// Magic defines the magic number of the peer-to-peer network.
 	Magic uint32
@@ -310,6 +414,18 @@ type Params struct {
 	// PermanentPeers defines peers seeds for node to initialize p2p connection.
 	PermanentPeers []string
 
+	// MaxPeers defines the max number of inbound and outbound peers, zero
+	// means the default value of p2p server.
+	MaxPeers int
+
+	// PeerAllowList defines the IPs or IP networks in CIDR notation the p2p
+	// connections are restricted to, no restriction if empty.
+	PeerAllowList []string
+
+	// NAT defines the NAT traversal methods to map the P2P and DPoS ports on
+	// the NAT gateway.
+	NAT NATConfig
+
 	// Foundation defines the foundation address which receiving mining
 	// rewards.
 	Foundation common.Uint168
@@ -350,6 +466,17 @@ type Params struct {
 	// coins (coinbase transactions) can be spent.
 	CoinbaseMaturity uint32
 
+	// FoundationRewardRatio is the ratio of block rewards to the foundation.
+	FoundationRewardRatio float64
+
+	// DPoSRewardRatio is the ratio of block rewards to DPoS arbiters, the
+	// rest of block rewards goes to merge miners.
+	DPoSRewardRatio float64
+
+	// RewardPolicies defines the changes of reward ratios and coinbase
+	// maturity from the specified heights.
+	RewardPolicies []RewardPolicy
+
 	// Disable transaction filter supports, include bloom filter tx type filter
 	// etc.
 	DisableTxFilters bool
@@ -400,6 +527,49 @@ type Params struct {
 	// CR by CID and DID.
 	RegisterCRByDIDHeight uint32
 
+	// NamePolicyHeight defines the height to apply NamePolicy on nicknames
+	// and URLs of producers and CR candidates.
+	NamePolicyHeight uint32
+
+	// NicknameFoldHeight defines the height to compare nicknames of producers
+	// and CR candidates after Unicode normalized and case folded by
+	// NamePolicy, nicknames duplicated in this way before the height are kept.
+	NicknameFoldHeight uint32
+
+	// NamePolicy defines the rules of nicknames and URLs of producers and CR
+	// candidates.
+	NamePolicy NamePolicy
+
+	// TxPolicy defines the standardness rules of transactions accepted into
+	// the transaction pool, it is not a part of the consensus rules.
+	TxPolicy TxPolicy
+
+	// VotePolicyHeight defines the height to apply VotePolicy on vote outputs
+	// in blocks.
+	VotePolicyHeight uint32
+
+	// VotePolicy defines the granularity rules of vote outputs, it is always
+	// applied to transactions accepted into the transaction pool.
+	VotePolicy VotePolicy
+
+	// ProducerInfoStakeHeight defines the height to support register and
+	// update producer with ProducerInfoStakeVersion payload.
+	ProducerInfoStakeHeight uint32
+
+	// RevokeVoteHeight defines the height to support revoking votes by the
+	// revoke vote transaction without spending the vote outputs.
+	RevokeVoteHeight uint32
+
+	// UnderstaffedRecoveryHeight defines the height to change arbiters
+	// immediately when enough producers are active again in understaffed
+	// mode, instead of waiting for the end of the current round.
+	UnderstaffedRecoveryHeight uint32
+
+	// SideChainTxProofHeight defines the height to support withdraw from
+	// side chain transactions with merkle proofs of the side chain
+	// transactions against the side chain blocks recorded on the main chain.
+	SideChainTxProofHeight uint32
+
 	// CRCArbiters defines the fixed CRC arbiters producing the block.
 	CRCArbiters []string
 
@@ -416,6 +586,16 @@ type Params struct {
 	// producers.
 	PreConnectOffset uint32
 
+	// CheckPointRetainCount defines the count of latest arbiters checkpoints
+	// to keep, the older ones are removed from the DPoS store unless archived.
+	// Zero means all checkpoints are kept.
+	CheckPointRetainCount uint32
+
+	// CheckPointArchiveSpan defines the span of checkpoints to archive one,
+	// the checkpoint of every CheckPointArchiveSpan-th interval is kept after
+	// the latest CheckPointRetainCount ones.  Zero means no archive.
+	CheckPointArchiveSpan uint32
+
 	// GeneralArbiters defines the number of general(no-CRC) arbiters.
 	GeneralArbiters int
 
@@ -429,6 +609,35 @@ type Params struct {
 	// takes penalty.
 	MaxInactiveRounds uint32
 
+	// VoteDecayHeight defines the height to discount the votes of producers
+	// activated after long inactivity in the next arbiters election.
+	VoteDecayHeight uint32
+
+	// VoteDecayInactiveRounds defines the inactive rounds more than which the
+	// votes of the producer are discounted after activated, the votes are
+	// discounted for as long as the producer has been inactive.  Zero means
+	// no discount.
+	VoteDecayInactiveRounds uint32
+
+	// VoteDecayRatio defines the ratio of the discounted votes to the votes.
+	VoteDecayRatio float64
+
+	// NodeKeyRotationHeight defines the height to support rotating the node
+	// public key of a producer in the middle of a term.
+	NodeKeyRotationHeight uint32
+
+	// CRCandidateTieBreakHeight defines the height to elect CR candidates
+	// with the same votes by register height before code hash.
+	CRCandidateTieBreakHeight uint32
+
+	// CRRegistrationWindowHeight defines the height to reject RegisterCR
+	// transactions outside the registration window of the current election.
+	CRRegistrationWindowHeight uint32
+
+	// NodeKeyRotationCooldown defines the blocks a producer should wait to
+	// rotate its node public key again after the last rotation.
+	NodeKeyRotationCooldown uint32
+
 	// InactivePenalty defines the penalty amount the producer takes.
 	InactivePenalty common.Fixed64
 
@@ -447,11 +656,49 @@ type Params struct {
 	// measured by block height
 	CRDutyPeriod uint32
 
+	// CRNominationPeriod defines the duration from the start of a voting
+	// period in which RegisterCR transactions are accepted, measured by block
+	// height. Zero means the whole voting period.
+	CRNominationPeriod uint32
+
+	// CRVotesCacheSize defines the maximum number of canceled vote outputs
+	// cached by CR state to process them again after rollback, it is an upper
+	// bound besides the expiry by height, outputs within the rollback window
+	// are kept even if the bound is exceeded.
+	CRVotesCacheSize int
+
+	// CRNicknameCommitHeight defines the height since which the nickname of
+	// RegisterCR should be committed by a CRNicknameCommit transaction first.
+	CRNicknameCommitHeight uint32
+
+	// CRNicknameCommitExpiry defines the blocks a committed nickname can be
+	// revealed by RegisterCR after the commitment packed.
+	CRNicknameCommitExpiry uint32
+
 	// CkpManager holds checkpoints save automatically.
 	CkpManager *checkpoint.Manager
 
 	// EnableUtxoDB indicate whether to enable utxo database.
 	EnableUtxoDB bool
+
+	// DBEngine indicates the key-value database engine of the chain store,
+	// can be "leveldb" or "pebble".
+	DBEngine string
+
+	// HeadersFirst indicates whether to download and verify the headers up to
+	// the last checkpoint before fetching the blocks in initial block
+	// download.
+	HeadersFirst bool
+
+	// Checkpoints defines the known good blocks ordered by height, the script
+	// validation of blocks not higher than the last checkpoint is skipped when
+	// syncing in headers-first mode.
+	Checkpoints []Checkpoint
+
+	// RewardWhitelist defines the program hashes allowed to receive the
+	// rewards of this node, blocks paying the rewards of this node elsewhere
+	// will be neither generated nor signed.  Empty means no restriction.
+	RewardWhitelist []common.Uint168
 }
 
 // rewardPerBlock calculates the reward for each block by a specified time
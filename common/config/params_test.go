@@ -9,6 +9,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/elastos/Elastos.ELA/common"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -40,3 +42,34 @@ func TestFoundation(t *testing.T) {
 	address, _ = testNetFoundation.ToAddress()
 	assert.Equal(t, "8ZNizBf4KhhPjeJRGpox6rPcHE5Np6tFx3", address)
 }
+
+func TestParams_GetRewardPolicy(t *testing.T) {
+	params := DefaultParams
+	policy := params.GetRewardPolicy(0)
+	assert.Equal(t, 0.3, policy.FoundationRewardRatio)
+	assert.Equal(t, 0.35, policy.DPoSRewardRatio)
+	assert.Equal(t, uint32(100), policy.CoinbaseMaturity)
+
+	// the policy of the highest height not above the given height applies.
+	params.RewardPolicies = []RewardPolicy{
+		{Height: 200, FoundationRewardRatio: 0.1, DPoSRewardRatio: 0.5,
+			CoinbaseMaturity: 20},
+		{Height: 100, FoundationRewardRatio: 0.2, DPoSRewardRatio: 0.4,
+			CoinbaseMaturity: 10},
+	}
+	assert.Equal(t, uint32(100), params.GetRewardPolicy(99).CoinbaseMaturity)
+	assert.Equal(t, params.RewardPolicies[1], params.GetRewardPolicy(100))
+	assert.Equal(t, params.RewardPolicies[1], params.GetRewardPolicy(199))
+	assert.Equal(t, params.RewardPolicies[0], params.GetRewardPolicy(200))
+}
+
+func TestParams_IsRewardAddressAllowed(t *testing.T) {
+	params := DefaultParams
+	hash1 := common.Uint168{1}
+	hash2 := common.Uint168{2}
+	assert.True(t, params.IsRewardAddressAllowed(hash1))
+
+	params.RewardWhitelist = []common.Uint168{hash1}
+	assert.True(t, params.IsRewardAddressAllowed(hash1))
+	assert.False(t, params.IsRewardAddressAllowed(hash2))
+}
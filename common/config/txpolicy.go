@@ -0,0 +1,31 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package config
+
+// TxPolicy defines the standardness rules of transactions, transactions
+// violating the rules are still valid in blocks but will not be accepted into
+// the transaction pool or relayed. Zero value of a limit means no limit.
+type TxPolicy struct {
+	// MaxTxSize defines the maximum serialized size of a transaction in bytes.
+	MaxTxSize int
+
+	// MaxInputs defines the maximum count of inputs of a transaction.
+	MaxInputs int
+
+	// MaxOutputs defines the maximum count of outputs of a transaction.
+	MaxOutputs int
+
+	// MaxProgramCodeSize defines the maximum size of a program code in bytes.
+	MaxProgramCodeSize int
+
+	// MaxProgramParameterSize defines the maximum size of a program parameter
+	// in bytes.
+	MaxProgramParameterSize int
+
+	// MaxMemoSize defines the maximum size of the memo attribute data in
+	// bytes.
+	MaxMemoSize int
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+)
+
+// VotePolicy defines the granularity rules of vote outputs to prevent the
+// state bloat from tiny votes, it is applied to blocks since VotePolicyHeight
+// and to transactions accepted into the transaction pool at any height. Zero
+// value of a limit means no limit.
+type VotePolicy struct {
+	// MinVoteAmount defines the minimum votes for each candidate of a vote
+	// content.
+	MinVoteAmount common.Fixed64
+
+	// MaxCandidatesPerContent defines the maximum count of candidates in a
+	// vote content.
+	MaxCandidatesPerContent int
+}
+
+// CheckVoteOutput checks if the vote output payload of an output with the
+// given amount is allowed by the policy.  Each candidate of a vote output
+// before VoteProducerAndCRVersion receives the whole amount of the output.
+func (p *VotePolicy) CheckVoteOutput(vote *outputpayload.VoteOutput,
+	amount common.Fixed64) error {
+	for _, content := range vote.Contents {
+		if p.MaxCandidatesPerContent > 0 &&
+			len(content.CandidateVotes) > p.MaxCandidatesPerContent {
+			return fmt.Errorf("vote content has %d candidates exceeds the"+
+				" limit %d", len(content.CandidateVotes),
+				p.MaxCandidatesPerContent)
+		}
+
+		for _, cv := range content.CandidateVotes {
+			votes := amount
+			if vote.Version >= outputpayload.VoteProducerAndCRVersion {
+				votes = cv.Votes
+			}
+			if votes < p.MinVoteAmount {
+				return fmt.Errorf("votes %s of candidate %s is less than the"+
+					" minimum %s", votes, common.BytesToHexString(
+					cv.Candidate), p.MinVoteAmount)
+			}
+		}
+	}
+	return nil
+}
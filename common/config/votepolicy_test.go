@@ -0,0 +1,48 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package config
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVotePolicy_CheckVoteOutput(t *testing.T) {
+	policy := &VotePolicy{MinVoteAmount: 100, MaxCandidatesPerContent: 2}
+	newVote := func(version byte, votes ...common.Fixed64) *outputpayload.VoteOutput {
+		content := outputpayload.VoteContent{VoteType: outputpayload.Delegate}
+		for i, v := range votes {
+			content.CandidateVotes = append(content.CandidateVotes,
+				outputpayload.CandidateVotes{Candidate: []byte{byte(i)}, Votes: v})
+		}
+		return &outputpayload.VoteOutput{
+			Version:  version,
+			Contents: []outputpayload.VoteContent{content},
+		}
+	}
+
+	// Candidates of the VoteProducerVersion payload receive the amount of
+	// the output.
+	assert.NoError(t, policy.CheckVoteOutput(
+		newVote(outputpayload.VoteProducerVersion, 0, 0), 100))
+	assert.Error(t, policy.CheckVoteOutput(
+		newVote(outputpayload.VoteProducerVersion, 0, 0), 99))
+
+	assert.NoError(t, policy.CheckVoteOutput(
+		newVote(outputpayload.VoteProducerAndCRVersion, 100, 200), 300))
+	assert.Error(t, policy.CheckVoteOutput(
+		newVote(outputpayload.VoteProducerAndCRVersion, 100, 1), 300))
+	assert.Error(t, policy.CheckVoteOutput(
+		newVote(outputpayload.VoteProducerAndCRVersion, 100, 100, 100), 300))
+
+	// Zero value of limits means no limit.
+	assert.NoError(t, (&VotePolicy{}).CheckVoteOutput(
+		newVote(outputpayload.VoteProducerAndCRVersion, 1, 1, 1), 300))
+}
@@ -7,34 +7,58 @@ package common
 
 import (
 	"bytes"
-	"encoding/binary"
 	"errors"
 	"io"
 	"strconv"
 	"strings"
 )
 
+var (
+	// ErrFixed64Overflow indicates the result of a Fixed64 arithmetic is
+	// greater than the max value of int64.
+	ErrFixed64Overflow = errors.New("fixed64 overflow")
+
+	// ErrFixed64Underflow indicates the result of a Fixed64 arithmetic is
+	// less than the min value of int64.
+	ErrFixed64Underflow = errors.New("fixed64 underflow")
+)
+
 //the 64 bit fixed-point number, precise 10^-8
 type Fixed64 int64
 
-func (f *Fixed64) Serialize(w io.Writer) error {
-	err := binary.Write(w, binary.LittleEndian, int64(*f))
-	if err != nil {
-		return err
+// CheckedAdd returns f + v, ErrFixed64Overflow or ErrFixed64Underflow is
+// returned instead of wrapping around if the sum is out of range of int64.
+func (f Fixed64) CheckedAdd(v Fixed64) (Fixed64, error) {
+	sum := f + v
+	if v > 0 && sum < f {
+		return 0, ErrFixed64Overflow
+	}
+	if v < 0 && sum > f {
+		return 0, ErrFixed64Underflow
+	}
+	return sum, nil
+}
+
+// CheckedSub returns f - v, ErrFixed64Overflow or ErrFixed64Underflow is
+// returned instead of wrapping around if the difference is out of range of
+// int64.
+func (f Fixed64) CheckedSub(v Fixed64) (Fixed64, error) {
+	diff := f - v
+	if v < 0 && diff < f {
+		return 0, ErrFixed64Overflow
 	}
+	if v > 0 && diff > f {
+		return 0, ErrFixed64Underflow
+	}
+	return diff, nil
+}
 
-	return nil
+func (f *Fixed64) Serialize(w io.Writer) error {
+	return binarySerializer.PutUint64(w, littleEndian, uint64(*f))
 }
 
 func (f *Fixed64) Deserialize(r io.Reader) error {
-	p := make([]byte, 8)
-	n, err := r.Read(p)
-	if n <= 0 || err != nil {
-		return err
-	}
-	buf := bytes.NewBuffer(p)
-	var x int64
-	err = binary.Read(buf, binary.LittleEndian, &x)
+	x, err := binarySerializer.Uint64(r, littleEndian)
 	if err != nil {
 		return err
 	}
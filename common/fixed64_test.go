@@ -0,0 +1,72 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package common
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// checkedResult computes the result of the Fixed64 arithmetic by big.Int and
+// returns the expected error if the result is out of range of int64.
+func checkedResult(result *big.Int) (Fixed64, error) {
+	if result.Cmp(big.NewInt(math.MaxInt64)) > 0 {
+		return 0, ErrFixed64Overflow
+	}
+	if result.Cmp(big.NewInt(math.MinInt64)) < 0 {
+		return 0, ErrFixed64Underflow
+	}
+	return Fixed64(result.Int64()), nil
+}
+
+func testCheckedArithmetic(t *testing.T, a, b Fixed64) {
+	x, y := big.NewInt(int64(a)), big.NewInt(int64(b))
+
+	want, wantErr := checkedResult(new(big.Int).Add(x, y))
+	got, err := a.CheckedAdd(b)
+	if got != want || err != wantErr {
+		t.Fatalf("%d.CheckedAdd(%d): got (%d, %v), want (%d, %v)",
+			a, b, got, err, want, wantErr)
+	}
+
+	want, wantErr = checkedResult(new(big.Int).Sub(x, y))
+	got, err = a.CheckedSub(b)
+	if got != want || err != wantErr {
+		t.Fatalf("%d.CheckedSub(%d): got (%d, %v), want (%d, %v)",
+			a, b, got, err, want, wantErr)
+	}
+}
+
+func TestFixed64_CheckedArithmetic(t *testing.T) {
+	extremes := []Fixed64{math.MinInt64, math.MinInt64 + 1, -100000000, -1,
+		0, 1, 100000000, 3300000000000000, math.MaxInt64 - 1, math.MaxInt64}
+	for _, a := range extremes {
+		for _, b := range extremes {
+			testCheckedArithmetic(t, a, b)
+		}
+	}
+
+	// Fuzz with random values near the extremes and in the whole range.
+	seed := time.Now().UnixNano()
+	r := rand.New(rand.NewSource(seed))
+	t.Logf("seed %d", seed)
+	random := func() Fixed64 {
+		switch r.Intn(3) {
+		case 0:
+			return Fixed64(math.MaxInt64 - r.Int63n(1000000))
+		case 1:
+			return Fixed64(math.MinInt64 + r.Int63n(1000000))
+		default:
+			return Fixed64(r.Uint64())
+		}
+	}
+	for i := 0; i < 100000; i++ {
+		testCheckedArithmetic(t, random(), random())
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/elastos/Elastos.ELA/common"
@@ -343,6 +344,59 @@ func (m *Manager) constructCheckpoint(proto ICheckPoint, path string) (
 	return proto.Generator()(data), true
 }
 
+// RollbackFiles removes checkpoint files under root which are saved after
+// the given height, default checkpoint files are removed as well because they
+// may hold state of higher heights. Removed files will be regenerated when the
+// node replays block chain data on next start.
+func RollbackFiles(root string, height uint32) (removed []string, err error) {
+	if !utils.FileExisted(root) {
+		return
+	}
+
+	var dirs []os.FileInfo
+	if dirs, err = ioutil.ReadDir(root); err != nil {
+		return
+	}
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+
+		dir := filepath.Join(root, d.Name())
+		var files []os.FileInfo
+		if files, err = ioutil.ReadDir(dir); err != nil {
+			return
+		}
+		for _, f := range files {
+			if f.IsDir() || !needRollbackFile(f.Name(), height) {
+				continue
+			}
+			path := filepath.Join(dir, f.Name())
+			if err = os.Remove(path); err != nil {
+				return
+			}
+			removed = append(removed, path)
+		}
+	}
+	return
+}
+
+func needRollbackFile(name string, height uint32) bool {
+	prefix := name
+	if i := strings.Index(name, "."); i >= 0 {
+		prefix = name[:i]
+	}
+	if prefix == DefaultCheckpoint {
+		return true
+	}
+
+	fileHeight, err := strconv.ParseUint(prefix, 10, 32)
+	if err != nil {
+		return false
+	}
+	return uint32(fileHeight) > height
+}
+
 func getFilePath(root string, checkpoint ICheckPoint) string {
 	return getFilePathByHeight(root, checkpoint, checkpoint.GetHeight())
 }
@@ -299,6 +299,33 @@ func TestManager_getOrderedCheckpoints(t *testing.T) {
 	}
 }
 
+func TestRollbackFiles(t *testing.T) {
+	root, err := ioutil.TempDir("", "checkpoint")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+	dir := filepath.Join(root, "rollback")
+	assert.NoError(t, os.MkdirAll(dir, 0700))
+
+	names := []string{"7.pt", "10.pt", "13.pt", "16.pt", "default.pt",
+		"unknown.pt"}
+	for _, v := range names {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, v),
+			[]byte{}, 0600))
+	}
+
+	removed, err := RollbackFiles(root, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(removed))
+
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	remains := make([]string, 0, len(files))
+	for _, f := range files {
+		remains = append(remains, f.Name())
+	}
+	assert.Equal(t, []string{"10.pt", "7.pt", "unknown.pt"}, remains)
+}
+
 func cleanCheckpoints() {
 	var err error
 	var files []os.FileInfo
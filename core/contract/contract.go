@@ -19,6 +19,25 @@ const (
 	PrefixCRDID      PrefixType = 0x67
 )
 
+// Name returns the name of the address type of the prefix. CID and DID share
+// the same prefix, so they are both named "crdid".
+func (p PrefixType) Name() string {
+	switch p {
+	case PrefixStandard:
+		return "standard"
+	case PrefixMultiSig:
+		return "multisig"
+	case PrefixCrossChain:
+		return "crosschain"
+	case PrefixDeposit:
+		return "deposit"
+	case PrefixCRDID:
+		return "crdid"
+	default:
+		return "unknown"
+	}
+}
+
 // Contract include the redeem script and hash prefix
 type Contract struct {
 	Code   []byte
@@ -29,3 +29,43 @@ func TestToProgramHash(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestPrefixType_Name(t *testing.T) {
+	assert.Equal(t, "standard", PrefixStandard.Name())
+	assert.Equal(t, "multisig", PrefixMultiSig.Name())
+	assert.Equal(t, "crosschain", PrefixCrossChain.Name())
+	assert.Equal(t, "deposit", PrefixDeposit.Name())
+	assert.Equal(t, "crdid", PrefixCRDID.Name())
+	assert.Equal(t, "unknown", PrefixType(0x00).Name())
+}
+
+func TestDeriveProgramHashes(t *testing.T) {
+	publicKeyHex := "022c9652d3ad5cc065aa9147dc2ad022f80001e8ed233de20f352950d351d472b7"
+	publicKey, _ := hex.DecodeString(publicKeyHex)
+	pub, _ := crypto.DecodePoint(publicKey)
+	code, err := CreateStandardRedeemScript(pub)
+	assert.NoError(t, err)
+
+	hashes, err := DeriveProgramHashes(code)
+	assert.NoError(t, err)
+
+	depositHash, _ := PublicKeyToDepositProgramHash(publicKey)
+	assert.True(t, depositHash.IsEqual(*hashes.DPoSDeposit))
+	assert.True(t, depositHash.IsEqual(*hashes.CRDeposit))
+	assert.Equal(t, PrefixDeposit, GetPrefixType(*hashes.CRDeposit))
+
+	cid, _ := CreateCRIDContractByCode(code)
+	assert.True(t, cid.ToProgramHash().IsEqual(*hashes.CID))
+	assert.Equal(t, PrefixCRDID, GetPrefixType(*hashes.CID))
+	assert.False(t, hashes.CID.IsEqual(*hashes.DID))
+
+	// deposit program hash of producer is only available for standard code.
+	multiCode, err := CreateMultiSigRedeemScript(1, []*crypto.PublicKey{pub})
+	assert.NoError(t, err)
+	hashes, err = DeriveProgramHashes(multiCode)
+	assert.NoError(t, err)
+	assert.Nil(t, hashes.DPoSDeposit)
+
+	_, err = DeriveProgramHashes(nil)
+	assert.Error(t, err)
+}
@@ -5,7 +5,11 @@
 
 package contract
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
 
 func CreateCRIDContractByCode(code []byte) (*Contract, error) {
 	if len(code) == 0 {
@@ -16,3 +20,15 @@ func CreateCRIDContractByCode(code []byte) (*Contract, error) {
 		Prefix: PrefixCRDID,
 	}, nil
 }
+
+// CreateDIDContractByCode creates the DID contract of a CR code, the last
+// byte of code is replaced by DID flag to generate the DID.
+func CreateDIDContractByCode(code []byte) (*Contract, error) {
+	if len(code) == 0 {
+		return nil, errors.New("code is nil")
+	}
+	didCode := make([]byte, len(code))
+	copy(didCode, code)
+	didCode[len(didCode)-1] = common.DID
+	return CreateCRIDContractByCode(didCode)
+}
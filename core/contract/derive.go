@@ -0,0 +1,56 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package contract
+
+import (
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+// DerivedProgramHashes holds program hashes derived from a redeem script,
+// which are used when registering producers and CR candidates.
+type DerivedProgramHashes struct {
+	// DPoSDeposit is the deposit program hash of a producer whose owner
+	// public key is in the code, it is nil if the code is not standard.
+	DPoSDeposit *common.Uint168
+
+	// CRDeposit is the deposit program hash of a CR candidate.
+	CRDeposit *common.Uint168
+
+	// CID is the CR identity of a CR candidate.
+	CID *common.Uint168
+
+	// DID is the decentralized identity corresponding to the code.
+	DID *common.Uint168
+}
+
+// DeriveProgramHashes derives deposit program hashes, CID and DID from code.
+func DeriveProgramHashes(code []byte) (*DerivedProgramHashes, error) {
+	cid, err := CreateCRIDContractByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	did, err := CreateDIDContractByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	deposit, err := CreateDepositContractByCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := &DerivedProgramHashes{
+		CRDeposit: deposit.ToProgramHash(),
+		CID:       cid.ToProgramHash(),
+		DID:       did.ToProgramHash(),
+	}
+	if IsStandard(code) {
+		hashes.DPoSDeposit, err = PublicKeyToDepositProgramHash(code[1:34])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
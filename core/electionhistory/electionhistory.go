@@ -0,0 +1,271 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package electionhistory
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/elastos/Elastos.ELA/blockchain"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/log"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/dpos/state"
+	"github.com/elastos/Elastos.ELA/events"
+)
+
+// maxPendingEvents is the maximum number of elections or disconnected blocks
+// waiting to be recorded, events exceed the limit will be ignored.
+const maxPendingEvents = 100
+
+const (
+	// electionPrefix is the key prefix of elections by height.
+	electionPrefix byte = 0x01
+
+	// roundPrefix is the key prefix of the election heights by round.
+	roundPrefix byte = 0x02
+)
+
+// ErrNotFound is returned if no election is recorded for the height or the
+// round.
+var ErrNotFound = errors.New("election not found")
+
+// Election is an election result recorded with the round of it, rounds are
+// numbered from 1 by the elections recorded by the node.
+type Election struct {
+	Round uint32
+	state.ElectionResult
+}
+
+// Config defines the parameters to create a Store.
+type Config struct {
+	// Path is the path of the database.
+	Path string
+
+	// Listen sets the function called with the result of each election of
+	// the next arbiters, nil unsets it.
+	Listen func(listener func(*state.ElectionResult))
+}
+
+// event is an election to record, or the height of a disconnected block if
+// election is nil.
+type event struct {
+	height   uint32
+	election *state.ElectionResult
+}
+
+// Store records the result of each election of the next arbiters, including
+// the elected arbiters, the candidates and the votes of all producers they
+// were ranked by, so that the node can tell why a producer was or was not
+// elected in a round.  Elections are stored in the format:
+//
+//	key: <0x01><height uint32 big endian>
+//	value: <round uint32><election result>
+//	key: <0x02><round uint32 big endian>
+//	value: <height uint32>
+type Store struct {
+	cfg    Config
+	db     *blockchain.LevelDB
+	events chan event
+	quit   chan struct{}
+	done   chan struct{}
+}
+
+func electionKey(height uint32) []byte {
+	key := make([]byte, 5)
+	key[0] = electionPrefix
+	binary.BigEndian.PutUint32(key[1:], height)
+	return key
+}
+
+func roundKey(round uint32) []byte {
+	key := make([]byte, 5)
+	key[0] = roundPrefix
+	binary.BigEndian.PutUint32(key[1:], round)
+	return key
+}
+
+func deserializeElection(data []byte) (*Election, error) {
+	r := bytes.NewReader(data)
+	election := &Election{}
+	var err error
+	if election.Round, err = common.ReadUint32(r); err != nil {
+		return nil, err
+	}
+	if err = election.Deserialize(r); err != nil {
+		return nil, err
+	}
+	return election, nil
+}
+
+// Record stores the election result as the round after the latest election
+// below its height, the elections recorded at the same or higher heights are
+// removed since they were on a chain which has been rolled back.
+func (s *Store) Record(result *state.ElectionResult) error {
+	round := uint32(1)
+	if result.Height > 0 {
+		previous, err := s.GetByHeight(result.Height - 1)
+		if err == nil {
+			round = previous.Round + 1
+		} else if err != ErrNotFound {
+			return err
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := common.WriteUint32(buf, round); err != nil {
+		return err
+	}
+	if err := result.Serialize(buf); err != nil {
+		return err
+	}
+	height := make([]byte, 4)
+	binary.BigEndian.PutUint32(height, result.Height)
+
+	s.db.NewBatch()
+	if err := s.batchRemoveFrom(result.Height); err != nil {
+		return err
+	}
+	s.db.BatchPut(electionKey(result.Height), buf.Bytes())
+	s.db.BatchPut(roundKey(round), height)
+	return s.db.BatchCommit()
+}
+
+// batchRemoveFrom adds the deletions of the elections at or above the height
+// to the batch.
+func (s *Store) batchRemoveFrom(height uint32) error {
+	iter := s.db.NewIterator([]byte{electionPrefix})
+	defer iter.Release()
+	for ok := iter.Seek(electionKey(height)); ok; ok = iter.Next() {
+		election, err := deserializeElection(iter.Value())
+		if err != nil {
+			return err
+		}
+		s.db.BatchDelete(electionKey(election.Height))
+		s.db.BatchDelete(roundKey(election.Round))
+	}
+	return nil
+}
+
+// GetByHeight returns the latest election at or below the height, the
+// arbiters elected by it are on duty at the height or from the next round.
+func (s *Store) GetByHeight(height uint32) (*Election, error) {
+	iter := s.db.NewIterator([]byte{electionPrefix})
+	defer iter.Release()
+
+	var ok bool
+	if height == ^uint32(0) || !iter.Seek(electionKey(height+1)) {
+		ok = iter.Last()
+	} else {
+		ok = iter.Prev()
+	}
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return deserializeElection(iter.Value())
+}
+
+// GetByRound returns the election of the round.
+func (s *Store) GetByRound(round uint32) (*Election, error) {
+	height, err := s.db.Get(roundKey(round))
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	data, err := s.db.Get(electionKey(binary.BigEndian.Uint32(height)))
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	return deserializeElection(data)
+}
+
+// remove removes the elections above the height of the disconnected block.
+func (s *Store) remove(height uint32) {
+	s.db.NewBatch()
+	if err := s.batchRemoveFrom(height + 1); err != nil {
+		log.Warnf("remove elections above height %d failed, %s", height, err)
+		return
+	}
+	if err := s.db.BatchCommit(); err != nil {
+		log.Warnf("remove elections above height %d failed, %s", height, err)
+	}
+}
+
+// Start listens to the elections and block events and starts to record
+// elections.
+func (s *Store) Start() {
+	events.Subscribe(s.handleEvent)
+	s.cfg.Listen(s.handleElection)
+	go s.recordHandler()
+}
+
+// Stop stops recording elections and closes the database.
+func (s *Store) Stop() error {
+	s.cfg.Listen(nil)
+	close(s.quit)
+	<-s.done
+	return s.db.Close()
+}
+
+func (s *Store) handleElection(result *state.ElectionResult) {
+	select {
+	case s.events <- event{height: result.Height, election: result}:
+	default:
+		log.Warn("too many pending events, ignore election at height ",
+			result.Height)
+	}
+}
+
+func (s *Store) handleEvent(e *events.Event) {
+	if e.Type != events.ETBlockDisconnected {
+		return
+	}
+	block, ok := e.Data.(*types.Block)
+	if !ok {
+		return
+	}
+
+	select {
+	case s.events <- event{height: block.Height}:
+	default:
+		log.Warn("too many pending events, ignore disconnected block at"+
+			" height ", block.Height)
+	}
+}
+
+func (s *Store) recordHandler() {
+	defer close(s.done)
+	for {
+		select {
+		case e := <-s.events:
+			if e.election == nil {
+				s.remove(e.height)
+				continue
+			}
+			if err := s.Record(e.election); err != nil {
+				log.Warnf("record election at height %d failed, %s",
+					e.height, err)
+			}
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// New opens or creates an election history store with the config.
+func New(cfg *Config) (*Store, error) {
+	db, err := blockchain.NewLevelDB(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{
+		cfg:    *cfg,
+		db:     db,
+		events: make(chan event, maxPendingEvents),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}, nil
+}
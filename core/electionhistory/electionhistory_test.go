@@ -0,0 +1,85 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package electionhistory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/dpos/state"
+	"github.com/elastos/Elastos.ELA/utils/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newElection(height uint32) *state.ElectionResult {
+	return &state.ElectionResult{
+		Height:      height,
+		Mode:        state.ElectionNormal,
+		CRCArbiters: [][]byte{{0x01}, {0x02}},
+		Arbiters:    [][]byte{{0x0a}},
+		Candidates:  [][]byte{{0x0b}},
+		Producers: []state.ElectedProducer{
+			{OwnerPublicKey: []byte{0x1a}, NodePublicKey: []byte{0x0a},
+				Votes: 300, EffectiveVotes: 300},
+			{OwnerPublicKey: []byte{0x1b}, NodePublicKey: []byte{0x0b},
+				Votes: 200, EffectiveVotes: 100},
+		},
+	}
+}
+
+func TestStore_Record(t *testing.T) {
+	path := filepath.Join(test.DataPath, "electionhistory")
+	os.RemoveAll(path)
+	defer os.RemoveAll(path)
+
+	store, err := New(&Config{Path: path})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer store.db.Close()
+
+	_, err = store.GetByHeight(100)
+	assert.Equal(t, ErrNotFound, err)
+
+	for _, height := range []uint32{100, 136, 172} {
+		assert.NoError(t, store.Record(newElection(height)))
+	}
+	election, err := store.GetByRound(2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(136), election.Height)
+	assert.Equal(t, newElection(136), &election.ElectionResult)
+
+	// the latest election at or below the height.
+	election, err = store.GetByHeight(171)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), election.Round)
+	election, err = store.GetByHeight(1000)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(3), election.Round)
+	_, err = store.GetByHeight(99)
+	assert.Equal(t, ErrNotFound, err)
+
+	// elections above the disconnected block are removed.
+	store.remove(140)
+	_, err = store.GetByRound(3)
+	assert.Equal(t, ErrNotFound, err)
+
+	// an election of another chain replaces the ones at or above it.
+	election136 := newElection(136)
+	election136.Mode = state.ElectionInsufficient
+	election136.Arbiters = nil
+	election136.Candidates = nil
+	assert.NoError(t, store.Record(election136))
+	election, err = store.GetByRound(2)
+	assert.NoError(t, err)
+	assert.Equal(t, state.ElectionInsufficient, election.Mode)
+	assert.NoError(t, store.Record(newElection(170)))
+	election, err = store.GetByHeight(170)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(3), election.Round)
+}
@@ -0,0 +1,313 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package rankhistory
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/elastos/Elastos.ELA/blockchain"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/log"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/events"
+)
+
+const (
+	// DefaultInterval is the default number of blocks between two rankings.
+	DefaultInterval = 720
+
+	// maxPendingBlocks is the maximum number of connected or disconnected
+	// blocks waiting to be recorded, blocks exceed the limit will be ignored.
+	maxPendingBlocks = 100
+
+	// maxKeyLength is the max length of the key of a ranked item.
+	maxKeyLength = 64
+)
+
+// Kind defines the kind of ranked items, it's also the key prefix of rankings
+// in database.
+type Kind byte
+
+const (
+	// Producer indicates the rankings of active producers, the key of an
+	// item is the owner public key.
+	Producer Kind = 0x01
+
+	// Candidate indicates the rankings of active CR candidates, the key of
+	// an item is the CID.
+	Candidate Kind = 0x02
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Producer:
+		return "Producer"
+	case Candidate:
+		return "Candidate"
+	}
+	return fmt.Sprintf("Kind-%d", k)
+}
+
+// Item is a ranked producer or candidate with the votes.
+type Item struct {
+	Key   []byte
+	Votes common.Fixed64
+}
+
+// Record is the rank of an item at a height, rank starts from 1 and zero
+// means the item is not ranked at the height.
+type Record struct {
+	Height uint32
+	Rank   uint32
+	Votes  common.Fixed64
+	Total  uint32
+}
+
+// Config defines the parameters to create a Store.
+type Config struct {
+	// Path is the path of the database.
+	Path string
+
+	// Interval is the number of blocks between two rankings, DefaultInterval
+	// will be used if it's 0.
+	Interval uint32
+
+	// Producers returns the active producers with votes.
+	Producers func() []Item
+
+	// Candidates returns the active CR candidates with votes.
+	Candidates func() []Item
+}
+
+// blockEvent is a connected or disconnected block height.
+type blockEvent struct {
+	height    uint32
+	connected bool
+}
+
+// Store records the rankings of producers and CR candidates by votes every
+// Interval blocks, so that the rank history of a producer or candidate can
+// be retrieved without external indexers.  Rankings are stored in the
+// format:
+//   key: <kind byte><height uint32 big endian>
+//   value: <count varuint>[<key varbytes><votes int64>]
+type Store struct {
+	cfg    Config
+	db     *blockchain.LevelDB
+	blocks chan blockEvent
+	quit   chan struct{}
+	done   chan struct{}
+}
+
+// Interval returns the number of blocks between two rankings.
+func (s *Store) Interval() uint32 {
+	return s.cfg.Interval
+}
+
+func rankingKey(kind Kind, height uint32) []byte {
+	key := make([]byte, 5)
+	key[0] = byte(kind)
+	binary.BigEndian.PutUint32(key[1:], height)
+	return key
+}
+
+// sortItems sorts items by votes in descending order, items with the same
+// votes are sorted by key.
+func sortItems(items []Item) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Votes != items[j].Votes {
+			return items[i].Votes > items[j].Votes
+		}
+		return bytes.Compare(items[i].Key, items[j].Key) < 0
+	})
+}
+
+func serializeItems(w io.Writer, items []Item) error {
+	if err := common.WriteVarUint(w, uint64(len(items))); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := common.WriteVarBytes(w, item.Key); err != nil {
+			return err
+		}
+		if err := common.WriteUint64(w, uint64(item.Votes)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deserializeItems(r io.Reader) ([]Item, error) {
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Item, 0, count)
+	for i := uint64(0); i < count; i++ {
+		key, err := common.ReadVarBytes(r, maxKeyLength, "key")
+		if err != nil {
+			return nil, err
+		}
+		votes, err := common.ReadUint64(r)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, Item{Key: key, Votes: common.Fixed64(votes)})
+	}
+	return items, nil
+}
+
+// Record sorts the items by votes and stores them as the ranking of the kind
+// at the height, the ranking recorded before at the height is replaced.
+func (s *Store) Record(kind Kind, height uint32, items []Item) error {
+	sorted := make([]Item, len(items))
+	copy(sorted, items)
+	sortItems(sorted)
+
+	buf := new(bytes.Buffer)
+	if err := serializeItems(buf, sorted); err != nil {
+		return err
+	}
+	return s.db.Put(rankingKey(kind, height), buf.Bytes())
+}
+
+// Ranking returns the ranked items of the kind recorded at the height.
+func (s *Store) Ranking(kind Kind, height uint32) ([]Item, error) {
+	data, err := s.db.Get(rankingKey(kind, height))
+	if err != nil {
+		return nil, err
+	}
+	return deserializeItems(bytes.NewReader(data))
+}
+
+// History returns the ranks of the item with the key in the rankings of the
+// kind recorded from the start height to the end height inclusively.
+func (s *Store) History(kind Kind, key []byte, start,
+	end uint32) ([]Record, error) {
+	iter := s.db.NewIterator([]byte{byte(kind)})
+	defer iter.Release()
+
+	var records []Record
+	for ok := iter.Seek(rankingKey(kind, start)); ok; ok = iter.Next() {
+		height := binary.BigEndian.Uint32(iter.Key()[1:])
+		if height > end {
+			break
+		}
+		items, err := deserializeItems(bytes.NewReader(iter.Value()))
+		if err != nil {
+			return nil, err
+		}
+		record := Record{Height: height, Total: uint32(len(items))}
+		for i, item := range items {
+			if bytes.Equal(item.Key, key) {
+				record.Rank = uint32(i + 1)
+				record.Votes = item.Votes
+				break
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// remove removes the rankings recorded at the height.
+func (s *Store) remove(height uint32) {
+	s.db.NewBatch()
+	s.db.BatchDelete(rankingKey(Producer, height))
+	s.db.BatchDelete(rankingKey(Candidate, height))
+	if err := s.db.BatchCommit(); err != nil {
+		log.Warnf("remove rankings at height %d failed, %s", height, err)
+	}
+}
+
+func (s *Store) record(height uint32) {
+	if s.cfg.Producers != nil {
+		if err := s.Record(Producer, height, s.cfg.Producers()); err != nil {
+			log.Warnf("record producer ranking at height %d failed, %s",
+				height, err)
+		}
+	}
+	if s.cfg.Candidates != nil {
+		if err := s.Record(Candidate, height, s.cfg.Candidates()); err != nil {
+			log.Warnf("record candidate ranking at height %d failed, %s",
+				height, err)
+		}
+	}
+}
+
+// Start subscribes block events and starts to record rankings.
+func (s *Store) Start() {
+	events.Subscribe(s.handleEvent)
+	go s.recordHandler()
+}
+
+// Stop stops recording rankings and closes the database.
+func (s *Store) Stop() error {
+	close(s.quit)
+	<-s.done
+	return s.db.Close()
+}
+
+func (s *Store) handleEvent(e *events.Event) {
+	var connected bool
+	switch e.Type {
+	case events.ETBlockConnected:
+		connected = true
+	case events.ETBlockDisconnected:
+	default:
+		return
+	}
+	block, ok := e.Data.(*types.Block)
+	if !ok || block.Height%s.cfg.Interval != 0 {
+		return
+	}
+
+	select {
+	case s.blocks <- blockEvent{height: block.Height, connected: connected}:
+	default:
+		log.Warn("too many pending blocks, ignore ranking at height ",
+			block.Height)
+	}
+}
+
+func (s *Store) recordHandler() {
+	defer close(s.done)
+	for {
+		select {
+		case b := <-s.blocks:
+			if b.connected {
+				s.record(b.height)
+			} else {
+				s.remove(b.height)
+			}
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// New opens or creates a rank history store with the config.
+func New(cfg *Config) (*Store, error) {
+	db, err := blockchain.NewLevelDB(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{
+		cfg:    *cfg,
+		db:     db,
+		blocks: make(chan blockEvent, maxPendingBlocks),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if s.cfg.Interval == 0 {
+		s.cfg.Interval = DefaultInterval
+	}
+	return s, nil
+}
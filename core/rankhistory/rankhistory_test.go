@@ -0,0 +1,72 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package rankhistory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/utils/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore_History(t *testing.T) {
+	path := filepath.Join(test.DataPath, "rankhistory")
+	os.RemoveAll(path)
+	defer os.RemoveAll(path)
+
+	store, err := New(&Config{Path: path})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer store.db.Close()
+	assert.Equal(t, uint32(DefaultInterval), store.Interval())
+
+	a, b, c := []byte{0x0a}, []byte{0x0b}, []byte{0x0c}
+	assert.NoError(t, store.Record(Producer, 720, []Item{
+		{Key: a, Votes: 100}, {Key: b, Votes: 300}, {Key: c, Votes: 200}}))
+	assert.NoError(t, store.Record(Producer, 1440, []Item{
+		{Key: a, Votes: 400}, {Key: c, Votes: 400}}))
+	assert.NoError(t, store.Record(Producer, 2160, []Item{{Key: b, Votes: 1}}))
+	assert.NoError(t, store.Record(Candidate, 1440, []Item{{Key: a, Votes: 1}}))
+
+	items, err := store.Ranking(Producer, 720)
+	assert.NoError(t, err)
+	assert.Equal(t, []Item{{Key: b, Votes: 300}, {Key: c, Votes: 200},
+		{Key: a, Votes: 100}}, items)
+	_, err = store.Ranking(Candidate, 720)
+	assert.Error(t, err)
+
+	// items with the same votes are ranked by key.
+	records, err := store.History(Producer, c, 0, 2160)
+	assert.NoError(t, err)
+	assert.Equal(t, []Record{
+		{Height: 720, Rank: 2, Votes: 200, Total: 3},
+		{Height: 1440, Rank: 2, Votes: 400, Total: 2},
+		{Height: 2160, Rank: 0, Votes: 0, Total: 1},
+	}, records)
+
+	records, err = store.History(Producer, a, 721, 1440)
+	assert.NoError(t, err)
+	assert.Equal(t, []Record{{Height: 1440, Rank: 1, Votes: 400, Total: 2}},
+		records)
+
+	records, err = store.History(Candidate, a, 0, 2160)
+	assert.NoError(t, err)
+	assert.Equal(t, []Record{{Height: 1440, Rank: 1, Votes: 1, Total: 1}},
+		records)
+
+	// rankings of disconnected blocks are removed.
+	store.remove(1440)
+	records, err = store.History(Producer, a, 0, 2160)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(records))
+	records, err = store.History(Candidate, a, 0, 2160)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(records))
+}
@@ -0,0 +1,258 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package roundreward
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"sort"
+
+	"github.com/elastos/Elastos.ELA/blockchain"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/log"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/events"
+)
+
+const (
+	// maxPendingBlocks is the maximum number of connected or disconnected
+	// blocks waiting to be recorded, blocks exceed the limit will be ignored.
+	maxPendingBlocks = 100
+
+	// maxRewardCount is the max count of reward receivers in a round.
+	maxRewardCount = 1 << 16
+)
+
+// Reward is the amount distributed to a program hash in a round.
+type Reward struct {
+	ProgramHash common.Uint168
+	Amount      common.Fixed64
+}
+
+// RoundReward is the split of the DPoS reward distributed by the coinbase of
+// the block at Height.
+type RoundReward struct {
+	Height  uint32
+	Rewards []Reward
+	Change  common.Fixed64
+}
+
+// Total returns the total amount distributed in the round including the
+// change.
+func (r *RoundReward) Total() common.Fixed64 {
+	total := r.Change
+	for _, reward := range r.Rewards {
+		total += reward.Amount
+	}
+	return total
+}
+
+func (r *RoundReward) Serialize(w io.Writer) error {
+	if err := common.WriteVarUint(w, uint64(len(r.Rewards))); err != nil {
+		return err
+	}
+	for _, reward := range r.Rewards {
+		if err := reward.ProgramHash.Serialize(w); err != nil {
+			return err
+		}
+		if err := reward.Amount.Serialize(w); err != nil {
+			return err
+		}
+	}
+	return r.Change.Serialize(w)
+}
+
+func (r *RoundReward) Deserialize(rd io.Reader) error {
+	count, err := common.ReadVarUint(rd, 0)
+	if err != nil {
+		return err
+	}
+	if count > maxRewardCount {
+		return io.ErrUnexpectedEOF
+	}
+	r.Rewards = make([]Reward, 0, count)
+	for i := uint64(0); i < count; i++ {
+		var reward Reward
+		if err := reward.ProgramHash.Deserialize(rd); err != nil {
+			return err
+		}
+		if err := reward.Amount.Deserialize(rd); err != nil {
+			return err
+		}
+		r.Rewards = append(r.Rewards, reward)
+	}
+	return r.Change.Deserialize(rd)
+}
+
+// Config defines the parameters to create a Store.
+type Config struct {
+	// Path is the path of the database.
+	Path string
+
+	// RoundReward returns the round reward of arbiters and the final round
+	// change to be distributed by the coinbase of the next block.
+	RoundReward func() (map[common.Uint168]common.Fixed64, common.Fixed64)
+}
+
+// blockEvent is the round reward snapshot of a connected block, or the
+// height of a disconnected block if reward is nil.
+type blockEvent struct {
+	height uint32
+	reward *RoundReward
+}
+
+// Store records the round rewards of arbiters keyed by the height of the
+// block whose coinbase distributes them, so that explorers can display how
+// each coinbase was split without recomputing consensus state.  Round
+// rewards are stored in the format:
+//
+//	key: <height uint32 big endian>
+//	value: <count varuint>[<program hash uint168><amount int64>]<change int64>
+type Store struct {
+	cfg    Config
+	db     *blockchain.LevelDB
+	blocks chan blockEvent
+	quit   chan struct{}
+	done   chan struct{}
+}
+
+func rewardKey(height uint32) []byte {
+	key := make([]byte, 4)
+	binary.BigEndian.PutUint32(key, height)
+	return key
+}
+
+// Record stores the round reward, the round reward recorded before at the
+// same height is replaced.
+func (s *Store) Record(reward *RoundReward) error {
+	buf := new(bytes.Buffer)
+	if err := reward.Serialize(buf); err != nil {
+		return err
+	}
+	return s.db.Put(rewardKey(reward.Height), buf.Bytes())
+}
+
+// Get returns the round reward distributed by the coinbase of the block at
+// the height.
+func (s *Store) Get(height uint32) (*RoundReward, error) {
+	data, err := s.db.Get(rewardKey(height))
+	if err != nil {
+		return nil, err
+	}
+	reward := &RoundReward{Height: height}
+	if err := reward.Deserialize(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return reward, nil
+}
+
+// remove removes the round reward recorded at the height.
+func (s *Store) remove(height uint32) {
+	if err := s.db.Delete(rewardKey(height)); err != nil {
+		log.Warnf("remove round reward at height %d failed, %s", height, err)
+	}
+}
+
+// snapshot returns the current round reward, returns nil if the coinbase of
+// the next block does not distribute DPoS rewards.
+func (s *Store) snapshot(height uint32) *RoundReward {
+	rewards, change := s.cfg.RoundReward()
+	if len(rewards) == 0 {
+		return nil
+	}
+	reward := &RoundReward{
+		Height:  height,
+		Rewards: make([]Reward, 0, len(rewards)),
+		Change:  change,
+	}
+	for hash, amount := range rewards {
+		reward.Rewards = append(reward.Rewards, Reward{
+			ProgramHash: hash,
+			Amount:      amount,
+		})
+	}
+	sort.Slice(reward.Rewards, func(i, j int) bool {
+		return bytes.Compare(reward.Rewards[i].ProgramHash.Bytes(),
+			reward.Rewards[j].ProgramHash.Bytes()) < 0
+	})
+	return reward
+}
+
+// Start subscribes block events and starts to record round rewards.
+func (s *Store) Start() {
+	events.Subscribe(s.handleEvent)
+	go s.recordHandler()
+}
+
+// Stop stops recording round rewards and closes the database.
+func (s *Store) Stop() error {
+	close(s.quit)
+	<-s.done
+	return s.db.Close()
+}
+
+// handleEvent takes the snapshot of the round reward when a block connected.
+// Blocks are connected before the arbiters state processes them, so the
+// round reward is the one checked against the coinbase of the block.
+func (s *Store) handleEvent(e *events.Event) {
+	block, ok := e.Data.(*types.Block)
+	if !ok {
+		return
+	}
+
+	event := blockEvent{height: block.Height}
+	switch e.Type {
+	case events.ETBlockConnected:
+		if event.reward = s.snapshot(block.Height); event.reward == nil {
+			return
+		}
+	case events.ETBlockDisconnected:
+	default:
+		return
+	}
+
+	select {
+	case s.blocks <- event:
+	default:
+		log.Warn("too many pending blocks, ignore round reward at height ",
+			block.Height)
+	}
+}
+
+func (s *Store) recordHandler() {
+	defer close(s.done)
+	for {
+		select {
+		case b := <-s.blocks:
+			if b.reward == nil {
+				s.remove(b.height)
+				continue
+			}
+			if err := s.Record(b.reward); err != nil {
+				log.Warnf("record round reward at height %d failed, %s",
+					b.height, err)
+			}
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// New opens or creates a round reward store with the config.
+func New(cfg *Config) (*Store, error) {
+	db, err := blockchain.NewLevelDB(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{
+		cfg:    *cfg,
+		db:     db,
+		blocks: make(chan blockEvent, maxPendingBlocks),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}, nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package roundreward
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/utils/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore_Get(t *testing.T) {
+	path := filepath.Join(test.DataPath, "roundreward")
+	os.RemoveAll(path)
+	defer os.RemoveAll(path)
+
+	a, b := common.Uint168{0x0a}, common.Uint168{0x0b}
+	var rewards map[common.Uint168]common.Fixed64
+	store, err := New(&Config{
+		Path: path,
+		RoundReward: func() (map[common.Uint168]common.Fixed64,
+			common.Fixed64) {
+			return rewards, 3
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer store.db.Close()
+
+	// no round reward distributed by the next block.
+	assert.Nil(t, store.snapshot(100))
+
+	rewards = map[common.Uint168]common.Fixed64{b: 200, a: 100}
+	reward := store.snapshot(101)
+	assert.Equal(t, &RoundReward{
+		Height:  101,
+		Rewards: []Reward{{ProgramHash: a, Amount: 100}, {ProgramHash: b, Amount: 200}},
+		Change:  3,
+	}, reward)
+	assert.Equal(t, common.Fixed64(303), reward.Total())
+
+	assert.NoError(t, store.Record(reward))
+	stored, err := store.Get(101)
+	assert.NoError(t, err)
+	assert.Equal(t, reward, stored)
+	_, err = store.Get(100)
+	assert.Error(t, err)
+
+	// round rewards of disconnected blocks are removed.
+	store.remove(101)
+	_, err = store.Get(101)
+	assert.Error(t, err)
+}
@@ -80,11 +80,11 @@ func (attr *Attribute) Serialize(w io.Writer) error {
 }
 
 func (attr *Attribute) Deserialize(r io.Reader) error {
-	val, err := common.ReadBytes(r, 1)
+	val, err := common.ReadUint8(r)
 	if err != nil {
 		return errors.New("Transaction attribute Usage deserialization error.")
 	}
-	attr.Usage = AttributeUsage(val[0])
+	attr.Usage = AttributeUsage(val)
 	if !IsValidAttributeType(attr.Usage) {
 		return errors.New("[Attribute error] Unsupported attribute Description.")
 	}
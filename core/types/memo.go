@@ -0,0 +1,107 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MemoType is the type of a standard memo.
+type MemoType string
+
+const (
+	// MemoText indicates a free text memo.
+	MemoText MemoType = "text"
+
+	// MemoID indicates a payment reference memo, such as the deposit ID
+	// assigned by an exchange to a user, so deposits to a shared address can
+	// be credited by the memo.
+	MemoID MemoType = "id"
+)
+
+const (
+	// memoTypePrefix and memoMsgSeparator wrap the type of a memo in the
+	// format "type:<type>,msg:<message>".
+	memoTypePrefix   = "type:"
+	memoMsgSeparator = ",msg:"
+
+	// maxMemoIDLength is the max length of the message of an ID memo.
+	maxMemoIDLength = 64
+)
+
+// TxMemo is a standard memo carried by the Memo attribute of a transaction.
+// The data of a standard memo is UTF-8 encoded in the format
+// "type:<type>,msg:<message>", data without the type prefix is taken as a
+// text memo.
+type TxMemo struct {
+	Type    MemoType
+	Message string
+}
+
+// Data returns the attribute data of the memo.
+func (m *TxMemo) Data() []byte {
+	return []byte(memoTypePrefix + string(m.Type) + memoMsgSeparator +
+		m.Message)
+}
+
+// ParseMemo parses the data of a Memo attribute, an error is returned if the
+// data is not a standard memo.
+func ParseMemo(data []byte) (*TxMemo, error) {
+	if !utf8.Valid(data) {
+		return nil, errors.New("memo is not UTF-8 encoded")
+	}
+	text := string(data)
+	if !strings.HasPrefix(text, memoTypePrefix) {
+		return &TxMemo{Type: MemoText, Message: text}, nil
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(text, memoTypePrefix),
+		memoMsgSeparator, 2)
+	if len(parts) != 2 {
+		return nil, errors.New("memo message not found")
+	}
+	memo := &TxMemo{Type: MemoType(parts[0]), Message: parts[1]}
+	switch memo.Type {
+	case MemoText:
+	case MemoID:
+		if err := checkMemoID(memo.Message); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown memo type %q", memo.Type)
+	}
+	return memo, nil
+}
+
+// checkMemoID checks if the ID consists of 1 to maxMemoIDLength letters,
+// digits, '-' or '_'.
+func checkMemoID(id string) error {
+	if len(id) == 0 || len(id) > maxMemoIDLength {
+		return fmt.Errorf("memo ID length should be 1 to %d",
+			maxMemoIDLength)
+	}
+	for _, c := range id {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') &&
+			(c < '0' || c > '9') && c != '-' && c != '_' {
+			return fmt.Errorf("invalid character %q in memo ID", c)
+		}
+	}
+	return nil
+}
+
+// GetMemo returns the data of the Memo attribute of the transaction, false
+// if the transaction has no memo.
+func (tx *Transaction) GetMemo() ([]byte, bool) {
+	for _, attr := range tx.Attributes {
+		if attr.Usage == Memo {
+			return attr.Data, true
+		}
+	}
+	return nil, false
+}
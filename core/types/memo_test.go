@@ -0,0 +1,54 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package types
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMemo(t *testing.T) {
+	memo, err := ParseMemo([]byte("type:id,msg:user_1024"))
+	assert.NoError(t, err)
+	assert.Equal(t, &TxMemo{Type: MemoID, Message: "user_1024"}, memo)
+	assert.Equal(t, []byte("type:id,msg:user_1024"), memo.Data())
+
+	// the message of a text memo may contain the separator.
+	memo, err = ParseMemo([]byte("type:text,msg:a,msg:b"))
+	assert.NoError(t, err)
+	assert.Equal(t, &TxMemo{Type: MemoText, Message: "a,msg:b"}, memo)
+
+	// data without the type prefix is a text memo.
+	memo, err = ParseMemo([]byte("hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, &TxMemo{Type: MemoText, Message: "hello"}, memo)
+
+	for _, data := range []string{
+		"\xff\xfe",
+		"type:id",
+		"type:url,msg:https://elastos.org",
+		"type:id,msg:",
+		"type:id,msg:user 1024",
+		"type:id,msg:" + strings.Repeat("a", 65),
+	} {
+		_, err := ParseMemo([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestTransaction_GetMemo(t *testing.T) {
+	tx := &Transaction{Attributes: []*Attribute{{Usage: Nonce, Data: []byte{1}}}}
+	_, ok := tx.GetMemo()
+	assert.False(t, ok)
+
+	tx.Attributes = append(tx.Attributes, &Attribute{Usage: Memo,
+		Data: []byte("memo")})
+	data, ok := tx.GetMemo()
+	assert.True(t, ok)
+	assert.Equal(t, []byte("memo"), data)
+}
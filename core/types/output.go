@@ -6,12 +6,10 @@
 package types
 
 import (
-	"errors"
 	"fmt"
 	"io"
 
 	"github.com/elastos/Elastos.ELA/common"
-	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
 )
 
 // OutputType represents the type of a output payload.
@@ -126,18 +124,3 @@ func (o *Output) String() string {
 
 	return outputStr
 }
-
-func getOutputPayload(outputType OutputType) (OutputPayload, error) {
-	var op OutputPayload
-	switch outputType {
-	case OTNone:
-		op = new(outputpayload.DefaultOutput)
-	case OTVote:
-		op = new(outputpayload.VoteOutput)
-	case OTMapping:
-		op = new(outputpayload.Mapping)
-	default:
-		return nil, errors.New("invalid transaction output type")
-	}
-	return op, nil
-}
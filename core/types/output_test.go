@@ -74,3 +74,114 @@ func TestOutput_Deserialize(t *testing.T) {
 		t.Error("output deserialize failed")
 	}
 }
+
+func TestRegisterOutputPayload(t *testing.T) {
+	// Built-in output types are registered.
+	for _, ot := range []OutputType{OTNone, OTVote, OTMapping} {
+		if _, ok := GetOutputPayloadHandler(ot); !ok {
+			t.Errorf("output type %d is not registered", ot)
+		}
+	}
+
+	// Register an output type which has been registered.
+	err := RegisterOutputPayload(OutputPayloadHandler{
+		Type: OTVote,
+		Name: "OTVote",
+		New:  func() OutputPayload { return new(outputpayload.VoteOutput) },
+	})
+	if err == nil {
+		t.Error("register duplicated output type should fail")
+	}
+
+	// Register a new output type.
+	const otTest = OutputType(0xff)
+	err = RegisterOutputPayload(OutputPayloadHandler{
+		Type:        otTest,
+		Name:        "OTTest",
+		New:         func() OutputPayload { return new(outputpayload.DefaultOutput) },
+		TxTypes:     []TxType{TransferAsset},
+		StartHeight: 100,
+	})
+	if err != nil {
+		t.Error("register output type failed:", err)
+	}
+	defer delete(outputPayloadHandlers, otTest)
+
+	handler, ok := GetOutputPayloadHandler(otTest)
+	if !ok {
+		t.Fatal("output type is not registered")
+	}
+	if !handler.IsTxTypeAllowed(TransferAsset) ||
+		handler.IsTxTypeAllowed(RegisterProducer) {
+		t.Error("output type allowed transaction types mismatch")
+	}
+	if otTest.String() != "OTTest" {
+		t.Error("output type name mismatch")
+	}
+
+	// Deserialize output with the new output type.
+	output := Output{
+		AssetID:     *assetID,
+		Value:       100000,
+		ProgramHash: *recipient,
+		Type:        otTest,
+		Payload:     &outputpayload.DefaultOutput{},
+	}
+	buf := new(bytes.Buffer)
+	if err := output.Serialize(buf, TxVersion09); err != nil {
+		t.Fatal("output serialize failed")
+	}
+	var output2 Output
+	if err := output2.Deserialize(buf, TxVersion09); err != nil {
+		t.Error("output deserialize failed:", err)
+	}
+	if output2.Type != otTest {
+		t.Error("output type mismatch")
+	}
+}
+
+func TestRegisterOutputStateHook(t *testing.T) {
+	const module = "Test"
+	defer delete(outputStateHooks, module)
+
+	// Register hook of an output type not registered.
+	hook := OutputStateHook{
+		Match: func(output *Output) bool { return output.Value > 0 },
+		Process: func(state interface{}, tx *Transaction, index int,
+			height uint32) {
+			*state.(*int) += index
+		},
+	}
+	if err := RegisterOutputStateHook(module, OutputType(0xfe),
+		hook); err == nil {
+		t.Error("register hook of unknown output type should fail")
+	}
+
+	if err := RegisterOutputStateHook(module, OTMapping, hook); err != nil {
+		t.Fatal("register output state hook failed:", err)
+	}
+	if err := RegisterOutputStateHook(module, OTMapping,
+		hook); err == nil {
+		t.Error("register duplicated output state hook should fail")
+	}
+
+	output := &Output{Type: OTMapping, Value: 1}
+	matched, ok := MatchOutputStateHook(module, output)
+	if !ok {
+		t.Fatal("output state hook should match")
+	}
+	state := 0
+	matched.Process(&state, nil, 2, 0)
+	if state != 2 {
+		t.Error("output state hook process mismatch")
+	}
+
+	// Hooks of other modules and outputs not matched are ignored.
+	if _, ok := MatchOutputStateHook("Other", output); ok {
+		t.Error("output state hook of other module should not match")
+	}
+	output.Value = 0
+	if _, ok := MatchOutputStateHook(module, output); ok {
+		t.Error("output state hook should not match")
+	}
+}
@@ -7,6 +7,7 @@ package outputpayload
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"io"
 
@@ -103,3 +104,45 @@ func (m *Mapping) Validate() error {
 
 	return nil
 }
+
+type mappingJSON struct {
+	Version        byte   `json:"version"`
+	OwnerPublicKey string `json:"ownerpublickey"`
+	SideProducerID string `json:"sideproducerid"`
+	Signature      string `json:"signature"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m Mapping) MarshalJSON() ([]byte, error) {
+	return json.Marshal(mappingJSON{
+		Version:        m.Version,
+		OwnerPublicKey: common.BytesToHexString(m.OwnerPublicKey),
+		SideProducerID: common.BytesToHexString(m.SideProducerID),
+		Signature:      common.BytesToHexString(m.Signature),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (m *Mapping) UnmarshalJSON(data []byte) error {
+	var j mappingJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	ownerPublicKey, err := common.HexStringToBytes(j.OwnerPublicKey)
+	if err != nil {
+		return errors.New("invalid ownerpublickey, " + err.Error())
+	}
+	sideProducerID, err := common.HexStringToBytes(j.SideProducerID)
+	if err != nil {
+		return errors.New("invalid sideproducerid, " + err.Error())
+	}
+	signature, err := common.HexStringToBytes(j.Signature)
+	if err != nil {
+		return errors.New("invalid signature, " + err.Error())
+	}
+	m.Version = j.Version
+	m.OwnerPublicKey = ownerPublicKey
+	m.SideProducerID = sideProducerID
+	m.Signature = signature
+	return nil
+}
@@ -8,6 +8,7 @@ package outputpayload
 import (
 	"bytes"
 	"crypto/rand"
+	"encoding/json"
 	"testing"
 
 	"github.com/elastos/Elastos.ELA/crypto"
@@ -85,3 +86,20 @@ func TestMapping_Validate(t *testing.T) {
 	err := m.Validate()
 	assert.Error(t, err)
 }
+
+func TestMapping_JSON(t *testing.T) {
+	m := &Mapping{
+		Version:        0,
+		OwnerPublicKey: []byte{0x01, 0x02},
+		SideProducerID: []byte{0x03},
+		Signature:      []byte{0x04, 0x05},
+	}
+	data, err := json.Marshal(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"version":0,"ownerpublickey":"0102",`+
+		`"sideproducerid":"03","signature":"0405"}`, string(data))
+
+	var m2 Mapping
+	assert.NoError(t, json.Unmarshal(data, &m2))
+	assert.Equal(t, m, &m2)
+}
@@ -6,6 +6,7 @@
 package outputpayload
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -16,6 +17,10 @@ import (
 
 const (
 	MaxVoteProducersPerTransaction = 36
+
+	// maxPreallocCandidates is the max number of candidate votes preallocated
+	// when deserializing a vote content.
+	maxPreallocCandidates = 128
 )
 
 const (
@@ -84,7 +89,7 @@ type VoteContent struct {
 }
 
 func (vc *VoteContent) Serialize(w io.Writer, version byte) error {
-	if _, err := w.Write([]byte{byte(vc.VoteType)}); err != nil {
+	if err := common.WriteUint8(w, byte(vc.VoteType)); err != nil {
 		return err
 	}
 	if err := common.WriteVarUint(w, uint64(len(vc.CandidateVotes))); err != nil {
@@ -100,17 +105,20 @@ func (vc *VoteContent) Serialize(w io.Writer, version byte) error {
 }
 
 func (vc *VoteContent) Deserialize(r io.Reader, version byte) error {
-	voteType, err := common.ReadBytes(r, 1)
+	voteType, err := common.ReadUint8(r)
 	if err != nil {
 		return err
 	}
-	vc.VoteType = VoteType(voteType[0])
+	vc.VoteType = VoteType(voteType)
 
 	candidatesCount, err := common.ReadVarUint(r, 0)
 	if err != nil {
 		return err
 	}
 
+	if candidatesCount > 0 && candidatesCount <= maxPreallocCandidates {
+		vc.CandidateVotes = make([]CandidateVotes, 0, candidatesCount)
+	}
 	for i := uint64(0); i < candidatesCount; i++ {
 		var cv CandidateVotes
 		if cv.Deserialize(r, version); err != nil {
@@ -150,7 +158,7 @@ func (o *VoteOutput) Data() []byte {
 }
 
 func (o *VoteOutput) Serialize(w io.Writer) error {
-	if _, err := w.Write([]byte{byte(o.Version)}); err != nil {
+	if err := common.WriteUint8(w, o.Version); err != nil {
 		return err
 	}
 	if err := common.WriteVarUint(w, uint64(len(o.Contents))); err != nil {
@@ -165,11 +173,11 @@ func (o *VoteOutput) Serialize(w io.Writer) error {
 }
 
 func (o *VoteOutput) Deserialize(r io.Reader) error {
-	version, err := common.ReadBytes(r, 1)
+	version, err := common.ReadUint8(r)
 	if err != nil {
 		return err
 	}
-	o.Version = version[0]
+	o.Version = version
 
 	contentsCount, err := common.ReadVarUint(r, 0)
 	if err != nil {
@@ -236,3 +244,96 @@ func (o VoteOutput) String() string {
 		"Version: ", o.Version, "\n\t\t\t",
 		"Contents: ", o.Contents, "\n\t\t\t}")
 }
+
+type candidateVotesJSON struct {
+	Candidate string `json:"candidate"`
+	Votes     string `json:"votes"`
+}
+
+type voteContentJSON struct {
+	VoteType   VoteType             `json:"votetype"`
+	Candidates []candidateVotesJSON `json:"candidates"`
+}
+
+// MarshalJSON implements the json.Marshaler interface, the candidates of CRC
+// vote type are encoded as addresses and the others as hex strings.
+func (vc VoteContent) MarshalJSON() ([]byte, error) {
+	j := voteContentJSON{
+		VoteType:   vc.VoteType,
+		Candidates: make([]candidateVotesJSON, 0, len(vc.CandidateVotes)),
+	}
+	for _, cv := range vc.CandidateVotes {
+		candidate := common.BytesToHexString(cv.Candidate)
+		if vc.VoteType == CRC {
+			if programHash, err := common.Uint168FromBytes(
+				cv.Candidate); err == nil {
+				candidate, _ = programHash.ToAddress()
+			}
+		}
+		j.Candidates = append(j.Candidates, candidateVotesJSON{
+			Candidate: candidate,
+			Votes:     cv.Votes.String(),
+		})
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (vc *VoteContent) UnmarshalJSON(data []byte) error {
+	var j voteContentJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	candidateVotes := make([]CandidateVotes, 0, len(j.Candidates))
+	for _, c := range j.Candidates {
+		var candidate []byte
+		if j.VoteType == CRC {
+			programHash, err := common.Uint168FromAddress(c.Candidate)
+			if err != nil {
+				return fmt.Errorf("invalid candidate, %s", err)
+			}
+			candidate = programHash.Bytes()
+		} else {
+			var err error
+			candidate, err = common.HexStringToBytes(c.Candidate)
+			if err != nil {
+				return fmt.Errorf("invalid candidate, %s", err)
+			}
+		}
+		votes, err := common.StringToFixed64(c.Votes)
+		if err != nil {
+			return fmt.Errorf("invalid votes, %s", err)
+		}
+		candidateVotes = append(candidateVotes, CandidateVotes{
+			Candidate: candidate,
+			Votes:     *votes,
+		})
+	}
+	vc.VoteType = j.VoteType
+	vc.CandidateVotes = candidateVotes
+	return nil
+}
+
+type voteOutputJSON struct {
+	Version  byte          `json:"version"`
+	Contents []VoteContent `json:"contents"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (o VoteOutput) MarshalJSON() ([]byte, error) {
+	return json.Marshal(voteOutputJSON{
+		Version:  o.Version,
+		Contents: o.Contents,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (o *VoteOutput) UnmarshalJSON(data []byte) error {
+	var j voteOutputJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	o.Version = j.Version
+	o.Contents = j.Contents
+	return nil
+}
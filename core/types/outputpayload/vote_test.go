@@ -7,6 +7,7 @@ package outputpayload
 
 import (
 	"bytes"
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"testing"
 
@@ -174,3 +175,37 @@ func TestVoteOutput_Validate(t *testing.T) {
 	err = vo4.Validate()
 	assert.NoError(t, err)
 }
+
+func TestVoteOutput_JSON(t *testing.T) {
+	cid, _ := common.Uint168FromAddress("iY82cT1BnjSiaC7qbt7MN7mcayDVHESyqB")
+	vo := &VoteOutput{
+		Version: VoteProducerAndCRVersion,
+		Contents: []VoteContent{
+			{
+				VoteType:       Delegate,
+				CandidateVotes: []CandidateVotes{{candidate1, 100000000}},
+			},
+			{
+				VoteType:       CRC,
+				CandidateVotes: []CandidateVotes{{cid.Bytes(), 150000000}},
+			},
+		},
+	}
+
+	data, err := json.Marshal(vo)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"version":1,"contents":[`+
+		`{"votetype":0,"candidates":[{"candidate":"`+PUBLICKEY1+
+		`","votes":"1"}]},`+
+		`{"votetype":1,"candidates":[{"candidate":`+
+		`"iY82cT1BnjSiaC7qbt7MN7mcayDVHESyqB","votes":"1.50000000"}]}]}`,
+		string(data))
+
+	var vo2 VoteOutput
+	assert.NoError(t, json.Unmarshal(data, &vo2))
+	assert.Equal(t, vo, &vo2)
+
+	// CRC candidates must be addresses.
+	assert.Error(t, json.Unmarshal([]byte(`{"votetype":1,"candidates":`+
+		`[{"candidate":"`+PUBLICKEY1+`","votes":"1"}]}`), &VoteContent{}))
+}
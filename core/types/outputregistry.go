@@ -0,0 +1,178 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/elastos/Elastos.ELA/core/contract"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+)
+
+// OutputPayloadHandler defines a structure for output payload types to use
+// when they registered themselves, so that a new output payload type can be
+// added in one place.
+type OutputPayloadHandler struct {
+	// Type is the identifier of the output payload type.  There can be only
+	// one handler with the same type.
+	Type OutputType
+
+	// Name is the readable name of the output payload type.
+	Name string
+
+	// New creates an empty payload of the output type to deserialize into.
+	New func() OutputPayload
+
+	// TxTypes is the transaction types that can hold outputs with the
+	// payload type, nil means outputs are allowed in any transaction type.
+	TxTypes []TxType
+
+	// Validate is an optional function to do the extra validation of the
+	// output, besides the payload self validation.
+	Validate func(output *Output) error
+
+	// StartHeight is the block height since outputs with the payload type
+	// can be packed into blocks.
+	StartHeight uint32
+}
+
+// outputPayloadHandlers holds all of the registered output payload types.
+var outputPayloadHandlers = make(map[OutputType]*OutputPayloadHandler)
+
+// RegisterOutputPayload adds an output payload type to available types, it
+// returns error if the output type has already been registered.
+func RegisterOutputPayload(handler OutputPayloadHandler) error {
+	if handler.New == nil {
+		return fmt.Errorf("output payload %q has no constructor",
+			handler.Name)
+	}
+	if _, exists := outputPayloadHandlers[handler.Type]; exists {
+		return fmt.Errorf("output payload type %d is already registered",
+			handler.Type)
+	}
+
+	outputPayloadHandlers[handler.Type] = &handler
+	return nil
+}
+
+// GetOutputPayloadHandler returns the registered handler of the output type.
+func GetOutputPayloadHandler(outputType OutputType) (
+	*OutputPayloadHandler, bool) {
+	handler, ok := outputPayloadHandlers[outputType]
+	return handler, ok
+}
+
+// IsTxTypeAllowed returns if outputs with the payload type can be placed in
+// the given transaction type.
+func (h *OutputPayloadHandler) IsTxTypeAllowed(txType TxType) bool {
+	if h.TxTypes == nil {
+		return true
+	}
+	for _, t := range h.TxTypes {
+		if t == txType {
+			return true
+		}
+	}
+	return false
+}
+
+// OutputStateHook defines how a state module processes outputs of a payload
+// type, so that state modules share one registry of output payload types.
+type OutputStateHook struct {
+	// Match returns if the output will change the state.
+	Match func(output *Output) bool
+
+	// Process updates the state by the output with the given index of the
+	// transaction packed at the height, state is the state of the module the
+	// hook registered to.
+	Process func(state interface{}, tx *Transaction, index int, height uint32)
+}
+
+// outputStateHooks holds the registered state hooks of each state module.
+var outputStateHooks = make(map[string]map[OutputType]*OutputStateHook)
+
+// RegisterOutputStateHook adds the state-processing hook of a registered
+// output payload type to the state module, it returns error if the output
+// type is not registered or the module has already registered a hook of it.
+func RegisterOutputStateHook(module string, outputType OutputType,
+	hook OutputStateHook) error {
+	if _, ok := outputPayloadHandlers[outputType]; !ok {
+		return fmt.Errorf("output type %d is not registered", outputType)
+	}
+	hooks, ok := outputStateHooks[module]
+	if !ok {
+		hooks = make(map[OutputType]*OutputStateHook)
+		outputStateHooks[module] = hooks
+	}
+	if _, exists := hooks[outputType]; exists {
+		return fmt.Errorf("%s state hook of %s is already registered",
+			module, outputType)
+	}
+
+	hooks[outputType] = &hook
+	return nil
+}
+
+// MatchOutputStateHook returns the hook of the state module if the output will
+// change the state of the module.
+func MatchOutputStateHook(module string, output *Output) (*OutputStateHook,
+	bool) {
+	hook, ok := outputStateHooks[module][output.Type]
+	if !ok || !hook.Match(output) {
+		return nil, false
+	}
+	return hook, true
+}
+
+func (ot OutputType) String() string {
+	if handler, ok := outputPayloadHandlers[ot]; ok {
+		return handler.Name
+	}
+	return fmt.Sprintf("OutputType-%d", byte(ot))
+}
+
+func getOutputPayload(outputType OutputType) (OutputPayload, error) {
+	handler, ok := outputPayloadHandlers[outputType]
+	if !ok {
+		return nil, errors.New("invalid transaction output type")
+	}
+	return handler.New(), nil
+}
+
+func init() {
+	handlers := []OutputPayloadHandler{
+		{
+			Type: OTNone,
+			Name: "OTNone",
+			New:  func() OutputPayload { return new(outputpayload.DefaultOutput) },
+		},
+		{
+			Type:    OTVote,
+			Name:    "OTVote",
+			New:     func() OutputPayload { return new(outputpayload.VoteOutput) },
+			TxTypes: []TxType{TransferAsset},
+			Validate: func(output *Output) error {
+				if contract.GetPrefixType(output.ProgramHash) !=
+					contract.PrefixStandard {
+					return errors.New("output address should be standard")
+				}
+				return nil
+			},
+		},
+		{
+			Type:    OTMapping,
+			Name:    "OTMapping",
+			New:     func() OutputPayload { return new(outputpayload.Mapping) },
+			TxTypes: []TxType{TransferAsset},
+		},
+	}
+	for _, h := range handlers {
+		if err := RegisterOutputPayload(h); err != nil {
+			panic(err)
+		}
+	}
+}
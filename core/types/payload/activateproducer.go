@@ -7,6 +7,7 @@ package payload
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"io"
 
@@ -77,3 +78,35 @@ func (a *ActivateProducer) DeserializeUnsigned(r io.Reader, version byte) error
 
 	return err
 }
+
+type activateProducerJSON struct {
+	NodePublicKey string `json:"nodepublickey"`
+	Signature     string `json:"signature"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (a ActivateProducer) MarshalJSON() ([]byte, error) {
+	return json.Marshal(activateProducerJSON{
+		NodePublicKey: common.BytesToHexString(a.NodePublicKey),
+		Signature:     common.BytesToHexString(a.Signature),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (a *ActivateProducer) UnmarshalJSON(data []byte) error {
+	var j activateProducerJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	nodePublicKey, err := decodeHex("nodepublickey", j.NodePublicKey)
+	if err != nil {
+		return err
+	}
+	signature, err := decodeHex("signature", j.Signature)
+	if err != nil {
+		return err
+	}
+	a.NodePublicKey = nodePublicKey
+	a.Signature = signature
+	return nil
+}
@@ -6,6 +6,7 @@
 package payload
 
 import (
+	"encoding/json"
 	"io"
 
 	"github.com/elastos/Elastos.ELA/common"
@@ -36,3 +37,22 @@ func (a *CoinBase) Deserialize(r io.Reader, version byte) error {
 	a.Content = temp
 	return err
 }
+
+type coinBaseJSON struct {
+	CoinbaseData string `json:"coinbasedata"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (a CoinBase) MarshalJSON() ([]byte, error) {
+	return json.Marshal(coinBaseJSON{CoinbaseData: string(a.Content)})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (a *CoinBase) UnmarshalJSON(data []byte) error {
+	var j coinBaseJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	a.Content = []byte(j.CoinbaseData)
+	return nil
+}
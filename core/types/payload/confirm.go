@@ -6,6 +6,7 @@
 package payload
 
 import (
+	"encoding/json"
 	"io"
 
 	"github.com/elastos/Elastos.ELA/common"
@@ -62,3 +63,27 @@ func (p *Confirm) Deserialize(r io.Reader) error {
 
 	return nil
 }
+
+type confirmJSON struct {
+	Proposal DPOSProposal       `json:"proposal"`
+	Votes    []DPOSProposalVote `json:"votes"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p Confirm) MarshalJSON() ([]byte, error) {
+	return json.Marshal(confirmJSON{
+		Proposal: p.Proposal,
+		Votes:    p.Votes,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *Confirm) UnmarshalJSON(data []byte) error {
+	var j confirmJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	p.Proposal = j.Proposal
+	p.Votes = j.Votes
+	return nil
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package payload
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+const CRCRewardAddressVersion byte = 0x00
+
+// CRCRewardAddress directs the round rewards of a CRC arbiter to the reward
+// address approved by the CR committee, instead of the CRC address.  The
+// height is signed with the reward address, it should be higher than the
+// height of the last reward address change of the arbiter, so an approved
+// payload can not be replayed to switch the reward address back.
+type CRCRewardAddress struct {
+	NodePublicKey     []byte
+	RewardProgramHash common.Uint168
+	Height            uint32
+	Signature         []byte
+	Signs             []CRMemberSign
+}
+
+func (p *CRCRewardAddress) Data(version byte) []byte {
+	buf := new(bytes.Buffer)
+	if err := p.Serialize(buf, version); err != nil {
+		return []byte{0}
+	}
+	return buf.Bytes()
+}
+
+func (p *CRCRewardAddress) Serialize(w io.Writer, version byte) error {
+	if err := p.SerializeUnsigned(w, version); err != nil {
+		return err
+	}
+
+	if err := common.WriteVarBytes(w, p.Signature); err != nil {
+		return errors.New("[CRCRewardAddress], signature serialize failed")
+	}
+
+	if err := common.WriteVarUint(w, uint64(len(p.Signs))); err != nil {
+		return errors.New("[CRCRewardAddress], signs count serialize failed")
+	}
+	for _, s := range p.Signs {
+		if err := s.CID.Serialize(w); err != nil {
+			return errors.New("[CRCRewardAddress], sign CID serialize failed")
+		}
+		if err := common.WriteVarBytes(w, s.Signature); err != nil {
+			return errors.New("[CRCRewardAddress], sign signature serialize failed")
+		}
+	}
+
+	return nil
+}
+
+func (p *CRCRewardAddress) SerializeUnsigned(w io.Writer, version byte) error {
+	if err := common.WriteVarBytes(w, p.NodePublicKey); err != nil {
+		return errors.New("[CRCRewardAddress], node public key serialize failed")
+	}
+
+	if err := p.RewardProgramHash.Serialize(w); err != nil {
+		return errors.New("[CRCRewardAddress], reward program hash serialize failed")
+	}
+
+	if err := common.WriteUint32(w, p.Height); err != nil {
+		return errors.New("[CRCRewardAddress], height serialize failed")
+	}
+
+	return nil
+}
+
+func (p *CRCRewardAddress) Deserialize(r io.Reader, version byte) error {
+	if err := p.DeserializeUnsigned(r, version); err != nil {
+		return err
+	}
+
+	var err error
+	p.Signature, err = common.ReadVarBytes(r, crypto.SignatureLength,
+		"signature")
+	if err != nil {
+		return errors.New("[CRCRewardAddress], signature deserialize failed")
+	}
+
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return errors.New("[CRCRewardAddress], signs count deserialize failed")
+	}
+	if count > MaxCRMemberSignsCount {
+		return errors.New("[CRCRewardAddress], too many signs")
+	}
+	if count > MaxCRMemberSignsCount {
+		return errors.New("[CRCRewardAddress], too many signs")
+	}
+	p.Signs = make([]CRMemberSign, 0, count)
+	for i := uint64(0); i < count; i++ {
+		var s CRMemberSign
+		if err := s.CID.Deserialize(r); err != nil {
+			return errors.New("[CRCRewardAddress], sign CID deserialize failed")
+		}
+		s.Signature, err = common.ReadVarBytes(r,
+			crypto.MaxSignatureScriptLength, "signature")
+		if err != nil {
+			return errors.New("[CRCRewardAddress], sign signature deserialize failed")
+		}
+		p.Signs = append(p.Signs, s)
+	}
+
+	return nil
+}
+
+func (p *CRCRewardAddress) DeserializeUnsigned(r io.Reader, version byte) error {
+	var err error
+	p.NodePublicKey, err = common.ReadVarBytes(r, crypto.NegativeBigLength,
+		"node public key")
+	if err != nil {
+		return errors.New("[CRCRewardAddress], node public key deserialize failed")
+	}
+
+	if err := p.RewardProgramHash.Deserialize(r); err != nil {
+		return errors.New("[CRCRewardAddress], reward program hash deserialize failed")
+	}
+
+	if p.Height, err = common.ReadUint32(r); err != nil {
+		return errors.New("[CRCRewardAddress], height deserialize failed")
+	}
+
+	return nil
+}
+
+type crcRewardAddressJSON struct {
+	NodePublicKey string         `json:"nodepublickey"`
+	RewardAddress string         `json:"rewardaddress"`
+	Height        uint32         `json:"height"`
+	Signature     string         `json:"signature"`
+	Signs         []CRMemberSign `json:"signs"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p CRCRewardAddress) MarshalJSON() ([]byte, error) {
+	return json.Marshal(crcRewardAddressJSON{
+		NodePublicKey: common.BytesToHexString(p.NodePublicKey),
+		RewardAddress: toAddress(p.RewardProgramHash),
+		Height:        p.Height,
+		Signature:     common.BytesToHexString(p.Signature),
+		Signs:         p.Signs,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *CRCRewardAddress) UnmarshalJSON(data []byte) error {
+	var j crcRewardAddressJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	nodePublicKey, err := decodeHex("nodepublickey", j.NodePublicKey)
+	if err != nil {
+		return err
+	}
+	rewardProgramHash, err := decodeAddress("rewardaddress", j.RewardAddress)
+	if err != nil {
+		return err
+	}
+	signature, err := decodeHex("signature", j.Signature)
+	if err != nil {
+		return err
+	}
+	p.NodePublicKey = nodePublicKey
+	p.RewardProgramHash = rewardProgramHash
+	p.Height = j.Height
+	p.Signature = signature
+	p.Signs = j.Signs
+	return nil
+}
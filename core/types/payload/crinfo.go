@@ -7,6 +7,7 @@ package payload
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"io"
 
@@ -17,6 +18,10 @@ import (
 const CRInfoVersion byte = 0x00
 const CRInfoDIDVersion byte = 0x01
 
+// CRInfoRevealVersion is the version of RegisterCR revealing the nickname
+// committed by a CRNicknameCommit transaction with the salt.
+const CRInfoRevealVersion byte = 0x02
+
 // CRInfo defines the information of CR.
 type CRInfo struct {
 	Code      []byte
@@ -25,6 +30,7 @@ type CRInfo struct {
 	NickName  string
 	Url       string
 	Location  uint64
+	Salt      []byte
 	Signature []byte
 }
 
@@ -81,6 +87,12 @@ func (a *CRInfo) SerializeUnsigned(w io.Writer, version byte) error {
 		return errors.New("[CRInfo], location serialize failed")
 	}
 
+	if version >= CRInfoRevealVersion {
+		if err = common.WriteVarBytes(w, a.Salt); err != nil {
+			return errors.New("[CRInfo], salt serialize failed")
+		}
+	}
+
 	return nil
 }
 
@@ -129,8 +141,78 @@ func (a *CRInfo) DeserializeUnsigned(r io.Reader, version byte) error {
 		return errors.New("[CRInfo], location deserialize failed")
 	}
 
+	if version >= CRInfoRevealVersion {
+		a.Salt, err = common.ReadVarBytes(r, MaxCRNicknameSaltLength, "salt")
+		if err != nil {
+			return errors.New("[CRInfo], salt deserialize failed")
+		}
+	}
+
 	return nil
 }
 func (a *CRInfo) GetCodeHash() common.Uint160 {
 	return *common.ToCodeHash(a.Code)
 }
+
+type crInfoJSON struct {
+	Code      string `json:"code"`
+	CID       string `json:"cid"`
+	DID       string `json:"did,omitempty"`
+	NickName  string `json:"nickname"`
+	Url       string `json:"url"`
+	Location  uint64 `json:"location"`
+	Salt      string `json:"salt,omitempty"`
+	Signature string `json:"signature"`
+}
+
+// MarshalJSON implements the json.Marshaler interface, the DID and salt are
+// omitted if not set.
+func (a CRInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(crInfoJSON{
+		Code:      common.BytesToHexString(a.Code),
+		CID:       toAddress(a.CID),
+		DID:       toAddress(a.DID),
+		NickName:  a.NickName,
+		Url:       a.Url,
+		Location:  a.Location,
+		Salt:      common.BytesToHexString(a.Salt),
+		Signature: common.BytesToHexString(a.Signature),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (a *CRInfo) UnmarshalJSON(data []byte) error {
+	var j crInfoJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	code, err := decodeHex("code", j.Code)
+	if err != nil {
+		return err
+	}
+	cid, err := decodeAddress("cid", j.CID)
+	if err != nil {
+		return err
+	}
+	did, err := decodeAddress("did", j.DID)
+	if err != nil {
+		return err
+	}
+	salt, err := decodeHex("salt", j.Salt)
+	if err != nil {
+		return err
+	}
+	signature, err := decodeHex("signature", j.Signature)
+	if err != nil {
+		return err
+	}
+	a.Code = code
+	a.CID = cid
+	a.DID = did
+	a.NickName = j.NickName
+	a.Url = j.Url
+	a.Location = j.Location
+	a.Salt = salt
+	a.Signature = signature
+	return nil
+}
@@ -26,6 +26,15 @@ func TestCRInfo_Deserialize(t *testing.T) {
 	crPayload2.Deserialize(buf, CRInfoVersion)
 
 	assert.True(t, payloadEqual(crPayload1, crPayload2))
+
+	crPayload1.Salt = randomBytes(MaxCRNicknameSaltLength)
+	buf = new(bytes.Buffer)
+	assert.NoError(t, crPayload1.Serialize(buf, CRInfoRevealVersion))
+
+	crPayload2 = &CRInfo{}
+	assert.NoError(t, crPayload2.Deserialize(buf, CRInfoRevealVersion))
+	assert.True(t, payloadEqual(crPayload1, crPayload2))
+	assert.Equal(t, crPayload1.Salt, crPayload2.Salt)
 }
 
 func payloadEqual(crPayload1 *CRInfo, crPayload2 *CRInfo) bool {
@@ -71,3 +80,11 @@ func randomUint168() *common.Uint168 {
 
 	return result
 }
+
+func randomUint256() *common.Uint256 {
+	randBytes := make([]byte, 32)
+	rand.Read(randBytes)
+	result, _ := common.Uint256FromBytes(randBytes)
+
+	return result
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package payload
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+const CRNicknameCommitVersion byte = 0x00
+
+const (
+	// MinCRNicknameSaltLength indicates the min length of the salt to commit
+	// a CR nickname, so that the nickname can not be guessed from the
+	// commitment.
+	MinCRNicknameSaltLength = 16
+
+	// MaxCRNicknameSaltLength indicates the max length of the salt to commit
+	// a CR nickname.
+	MaxCRNicknameSaltLength = 32
+)
+
+// CRNicknameCommit commits the hash of a nickname before registering it by
+// RegisterCR, so that the nickname can not be taken by others watching the
+// transaction pool.
+type CRNicknameCommit struct {
+	Commitment common.Uint256
+}
+
+func (p *CRNicknameCommit) Data(version byte) []byte {
+	buf := new(bytes.Buffer)
+	if err := p.Serialize(buf, version); err != nil {
+		return []byte{0}
+	}
+	return buf.Bytes()
+}
+
+func (p *CRNicknameCommit) Serialize(w io.Writer, version byte) error {
+	if err := p.Commitment.Serialize(w); err != nil {
+		return errors.New("[CRNicknameCommit], commitment serialize failed")
+	}
+	return nil
+}
+
+func (p *CRNicknameCommit) Deserialize(r io.Reader, version byte) error {
+	if err := p.Commitment.Deserialize(r); err != nil {
+		return errors.New("[CRNicknameCommit], commitment deserialize failed")
+	}
+	return nil
+}
+
+// CRNicknameCommitment returns the commitment of a nickname, it binds the CID
+// of the candidate so that the commitment can not be used by others.
+func CRNicknameCommitment(nickname string, salt []byte,
+	cid common.Uint168) common.Uint256 {
+	buf := new(bytes.Buffer)
+	common.WriteVarString(buf, nickname)
+	common.WriteVarBytes(buf, salt)
+	cid.Serialize(buf)
+	return common.Sha256D(buf.Bytes())
+}
+
+type crNicknameCommitJSON struct {
+	Commitment string `json:"commitment"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p CRNicknameCommit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(crNicknameCommitJSON{
+		Commitment: p.Commitment.String(),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *CRNicknameCommit) UnmarshalJSON(data []byte) error {
+	var j crNicknameCommitJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	commitment, err := decodeHash("commitment", j.Commitment)
+	if err != nil {
+		return err
+	}
+	p.Commitment = commitment
+	return nil
+}
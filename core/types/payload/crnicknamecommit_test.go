@@ -0,0 +1,36 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package payload
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCRNicknameCommit_Deserialize(t *testing.T) {
+	salt := randomBytes(MinCRNicknameSaltLength)
+	cid := *randomUint168()
+	p1 := &CRNicknameCommit{
+		Commitment: CRNicknameCommitment("nickname", salt, cid),
+	}
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, p1.Serialize(buf, CRNicknameCommitVersion))
+
+	p2 := &CRNicknameCommit{}
+	assert.NoError(t, p2.Deserialize(buf, CRNicknameCommitVersion))
+	assert.Equal(t, p1, p2)
+
+	// The commitment changes with any of nickname, salt and CID.
+	assert.NotEqual(t, p1.Commitment,
+		CRNicknameCommitment("nickname2", salt, cid))
+	assert.NotEqual(t, p1.Commitment, CRNicknameCommitment("nickname",
+		randomBytes(MinCRNicknameSaltLength), cid))
+	assert.NotEqual(t, p1.Commitment,
+		CRNicknameCommitment("nickname", salt, *randomUint168()))
+}
@@ -0,0 +1,257 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package payload
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+const CustomIDProposalVersion byte = 0x00
+
+// MaxCustomIDLength indicates the max length of a custom ID.
+const MaxCustomIDLength = 100
+
+// MaxCustomIDsCount indicates the max count of custom IDs in one proposal.
+const MaxCustomIDsCount = 1000
+
+// MaxCRMemberSignsCount indicates the max count of CR member signatures in
+// one payload, it's larger than the member count of any CR committee.
+const MaxCRMemberSignsCount = 100
+
+// CustomIDProposalType defines the type of custom ID proposal.
+type CustomIDProposalType byte
+
+const (
+	// ReserveCustomID indicates a proposal to reserve custom IDs so that no
+	// one can register them on the DID sidechain.
+	ReserveCustomID CustomIDProposalType = 0x00
+
+	// BanCustomID indicates a proposal to ban custom IDs that have been
+	// registered on the DID sidechain.
+	BanCustomID CustomIDProposalType = 0x01
+
+	// ChangeCustomIDFee indicates a proposal to change the fee rate of
+	// registering custom IDs on the DID sidechain.
+	ChangeCustomIDFee CustomIDProposalType = 0x02
+)
+
+func (t CustomIDProposalType) Name() string {
+	switch t {
+	case ReserveCustomID:
+		return "ReserveCustomID"
+	case BanCustomID:
+		return "BanCustomID"
+	case ChangeCustomIDFee:
+		return "ChangeCustomIDFee"
+	default:
+		return "Unknown"
+	}
+}
+
+// CRMemberSign is the signature of a CR committee member on a proposal.
+type CRMemberSign struct {
+	CID       common.Uint168
+	Signature []byte
+}
+
+type CustomIDProposal struct {
+	ProposalType    CustomIDProposalType
+	CustomIDs       []string
+	FeeRate         common.Fixed64
+	EffectiveHeight uint32
+	Signs           []CRMemberSign
+}
+
+func (p *CustomIDProposal) Data(version byte) []byte {
+	buf := new(bytes.Buffer)
+	if err := p.Serialize(buf, version); err != nil {
+		return []byte{0}
+	}
+	return buf.Bytes()
+}
+
+func (p *CustomIDProposal) Serialize(w io.Writer, version byte) error {
+	if err := p.SerializeUnsigned(w, version); err != nil {
+		return err
+	}
+
+	if err := common.WriteVarUint(w, uint64(len(p.Signs))); err != nil {
+		return errors.New("[CustomIDProposal], signs count serialize failed")
+	}
+	for _, s := range p.Signs {
+		if err := s.CID.Serialize(w); err != nil {
+			return errors.New("[CustomIDProposal], sign CID serialize failed")
+		}
+		if err := common.WriteVarBytes(w, s.Signature); err != nil {
+			return errors.New("[CustomIDProposal], signature serialize failed")
+		}
+	}
+
+	return nil
+}
+
+func (p *CustomIDProposal) SerializeUnsigned(w io.Writer, version byte) error {
+	if err := common.WriteUint8(w, byte(p.ProposalType)); err != nil {
+		return errors.New("[CustomIDProposal], proposal type serialize failed")
+	}
+
+	if err := common.WriteVarUint(w, uint64(len(p.CustomIDs))); err != nil {
+		return errors.New("[CustomIDProposal], custom IDs count serialize failed")
+	}
+	for _, id := range p.CustomIDs {
+		if err := common.WriteVarString(w, id); err != nil {
+			return errors.New("[CustomIDProposal], custom ID serialize failed")
+		}
+	}
+
+	if err := p.FeeRate.Serialize(w); err != nil {
+		return errors.New("[CustomIDProposal], fee rate serialize failed")
+	}
+
+	if err := common.WriteUint32(w, p.EffectiveHeight); err != nil {
+		return errors.New("[CustomIDProposal], effective height serialize failed")
+	}
+
+	return nil
+}
+
+func (p *CustomIDProposal) Deserialize(r io.Reader, version byte) error {
+	if err := p.DeserializeUnsigned(r, version); err != nil {
+		return err
+	}
+
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return errors.New("[CustomIDProposal], signs count deserialize failed")
+	}
+	if count > MaxCRMemberSignsCount {
+		return errors.New("[CustomIDProposal], too many signs")
+	}
+	p.Signs = make([]CRMemberSign, 0, count)
+	for i := uint64(0); i < count; i++ {
+		var s CRMemberSign
+		if err := s.CID.Deserialize(r); err != nil {
+			return errors.New("[CustomIDProposal], sign CID deserialize failed")
+		}
+		s.Signature, err = common.ReadVarBytes(r,
+			crypto.MaxSignatureScriptLength, "signature")
+		if err != nil {
+			return errors.New("[CustomIDProposal], signature deserialize failed")
+		}
+		p.Signs = append(p.Signs, s)
+	}
+
+	return nil
+}
+
+func (p *CustomIDProposal) DeserializeUnsigned(r io.Reader, version byte) error {
+	proposalType, err := common.ReadUint8(r)
+	if err != nil {
+		return errors.New("[CustomIDProposal], proposal type deserialize failed")
+	}
+	p.ProposalType = CustomIDProposalType(proposalType)
+
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return errors.New("[CustomIDProposal], custom IDs count deserialize failed")
+	}
+	if count > MaxCustomIDsCount {
+		return errors.New("[CustomIDProposal], too many custom IDs")
+	}
+	p.CustomIDs = make([]string, 0, count)
+	for i := uint64(0); i < count; i++ {
+		id, err := common.ReadVarString(r)
+		if err != nil {
+			return errors.New("[CustomIDProposal], custom ID deserialize failed")
+		}
+		p.CustomIDs = append(p.CustomIDs, id)
+	}
+
+	if err := p.FeeRate.Deserialize(r); err != nil {
+		return errors.New("[CustomIDProposal], fee rate deserialize failed")
+	}
+
+	if p.EffectiveHeight, err = common.ReadUint32(r); err != nil {
+		return errors.New("[CustomIDProposal], effective height deserialize failed")
+	}
+
+	return nil
+}
+
+type crMemberSignJSON struct {
+	CID       string `json:"cid"`
+	Signature string `json:"signature"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s CRMemberSign) MarshalJSON() ([]byte, error) {
+	return json.Marshal(crMemberSignJSON{
+		CID:       toAddress(s.CID),
+		Signature: common.BytesToHexString(s.Signature),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *CRMemberSign) UnmarshalJSON(data []byte) error {
+	var j crMemberSignJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	cid, err := decodeAddress("cid", j.CID)
+	if err != nil {
+		return err
+	}
+	signature, err := decodeHex("signature", j.Signature)
+	if err != nil {
+		return err
+	}
+	s.CID = cid
+	s.Signature = signature
+	return nil
+}
+
+type customIDProposalJSON struct {
+	ProposalType    CustomIDProposalType `json:"proposaltype"`
+	CustomIDs       []string             `json:"customids"`
+	FeeRate         string               `json:"feerate"`
+	EffectiveHeight uint32               `json:"effectiveheight"`
+	Signs           []CRMemberSign       `json:"signs"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p CustomIDProposal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(customIDProposalJSON{
+		ProposalType:    p.ProposalType,
+		CustomIDs:       p.CustomIDs,
+		FeeRate:         p.FeeRate.String(),
+		EffectiveHeight: p.EffectiveHeight,
+		Signs:           p.Signs,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *CustomIDProposal) UnmarshalJSON(data []byte) error {
+	var j customIDProposalJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	feeRate, err := common.StringToFixed64(j.FeeRate)
+	if err != nil {
+		return errors.New("invalid feerate, " + err.Error())
+	}
+	p.ProposalType = j.ProposalType
+	p.CustomIDs = j.CustomIDs
+	p.FeeRate = *feeRate
+	p.EffectiveHeight = j.EffectiveHeight
+	p.Signs = j.Signs
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package payload
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomIDProposal_Deserialize(t *testing.T) {
+	proposal1 := randomCustomIDProposalPayload()
+
+	buf := new(bytes.Buffer)
+	proposal1.Serialize(buf, CustomIDProposalVersion)
+
+	proposal2 := &CustomIDProposal{}
+	proposal2.Deserialize(buf, CustomIDProposalVersion)
+
+	assert.True(t, customIDProposalPayloadEqual(proposal1, proposal2))
+
+	// Huge count of custom IDs.
+	buf = new(bytes.Buffer)
+	common.WriteUint8(buf, byte(ReserveCustomID))
+	common.WriteVarUint(buf, math.MaxUint64)
+	assert.EqualError(t, proposal2.Deserialize(buf, CustomIDProposalVersion),
+		"[CustomIDProposal], too many custom IDs")
+
+	// Huge count of signs.
+	buf = new(bytes.Buffer)
+	proposal1.SerializeUnsigned(buf, CustomIDProposalVersion)
+	common.WriteVarUint(buf, math.MaxUint64)
+	assert.EqualError(t, proposal2.Deserialize(buf, CustomIDProposalVersion),
+		"[CustomIDProposal], too many signs")
+}
+
+func customIDProposalPayloadEqual(payload1 *CustomIDProposal,
+	payload2 *CustomIDProposal) bool {
+	if payload1.ProposalType != payload2.ProposalType ||
+		payload1.FeeRate != payload2.FeeRate ||
+		payload1.EffectiveHeight != payload2.EffectiveHeight ||
+		len(payload1.CustomIDs) != len(payload2.CustomIDs) ||
+		len(payload1.Signs) != len(payload2.Signs) {
+		return false
+	}
+
+	for i := range payload1.CustomIDs {
+		if payload1.CustomIDs[i] != payload2.CustomIDs[i] {
+			return false
+		}
+	}
+
+	for i := range payload1.Signs {
+		if !payload1.Signs[i].CID.IsEqual(payload2.Signs[i].CID) ||
+			!bytes.Equal(payload1.Signs[i].Signature,
+				payload2.Signs[i].Signature) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func randomCustomIDProposalPayload() *CustomIDProposal {
+	return &CustomIDProposal{
+		ProposalType:    ChangeCustomIDFee,
+		CustomIDs:       []string{randomString(), randomString()},
+		FeeRate:         common.Fixed64(100),
+		EffectiveHeight: 1000,
+		Signs: []CRMemberSign{
+			{CID: *randomUint168(), Signature: randomBytes(65)},
+			{CID: *randomUint168(), Signature: randomBytes(65)},
+		},
+	}
+}
@@ -7,6 +7,7 @@ package payload
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 
 	"github.com/elastos/Elastos.ELA/common"
@@ -231,3 +232,74 @@ func (d *DPOSIllegalBlocks) GetBlockHeight() uint32 {
 func (d *DPOSIllegalBlocks) Type() IllegalDataType {
 	return IllegalBlock
 }
+
+type blockEvidenceJSON struct {
+	Header       string   `json:"header"`
+	BlockConfirm string   `json:"blockconfirm"`
+	Signers      []string `json:"signers"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (b BlockEvidence) MarshalJSON() ([]byte, error) {
+	return json.Marshal(blockEvidenceJSON{
+		Header:       common.BytesToHexString(b.Header),
+		BlockConfirm: common.BytesToHexString(b.BlockConfirm),
+		Signers:      hexList(b.Signers),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (b *BlockEvidence) UnmarshalJSON(data []byte) error {
+	var j blockEvidenceJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	header, err := decodeHex("header", j.Header)
+	if err != nil {
+		return err
+	}
+	confirm, err := decodeHex("blockconfirm", j.BlockConfirm)
+	if err != nil {
+		return err
+	}
+	signers, err := decodeHexList("signers", j.Signers)
+	if err != nil {
+		return err
+	}
+	b.Header = header
+	b.BlockConfirm = confirm
+	b.Signers = signers
+	b.hash = nil
+	return nil
+}
+
+type dposIllegalBlocksJSON struct {
+	CoinType        CoinType      `json:"cointype"`
+	BlockHeight     uint32        `json:"blockheight"`
+	Evidence        BlockEvidence `json:"evidence"`
+	CompareEvidence BlockEvidence `json:"compareevidence"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d DPOSIllegalBlocks) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dposIllegalBlocksJSON{
+		CoinType:        d.CoinType,
+		BlockHeight:     d.BlockHeight,
+		Evidence:        d.Evidence,
+		CompareEvidence: d.CompareEvidence,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (d *DPOSIllegalBlocks) UnmarshalJSON(data []byte) error {
+	var j dposIllegalBlocksJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	d.CoinType = j.CoinType
+	d.BlockHeight = j.BlockHeight
+	d.Evidence = j.Evidence
+	d.CompareEvidence = j.CompareEvidence
+	d.hash = nil
+	return nil
+}
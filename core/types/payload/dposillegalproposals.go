@@ -7,6 +7,7 @@ package payload
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 
 	"github.com/elastos/Elastos.ELA/common"
@@ -112,3 +113,59 @@ func (d *DPOSIllegalProposals) GetBlockHeight() uint32 {
 func (d *DPOSIllegalProposals) Type() IllegalDataType {
 	return IllegalProposal
 }
+
+type proposalEvidenceJSON struct {
+	Proposal    DPOSProposal `json:"proposal"`
+	BlockHeader string       `json:"blockheader"`
+	BlockHeight uint32       `json:"blockheight"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d ProposalEvidence) MarshalJSON() ([]byte, error) {
+	return json.Marshal(proposalEvidenceJSON{
+		Proposal:    d.Proposal,
+		BlockHeader: common.BytesToHexString(d.BlockHeader),
+		BlockHeight: d.BlockHeight,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (d *ProposalEvidence) UnmarshalJSON(data []byte) error {
+	var j proposalEvidenceJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	header, err := decodeHex("blockheader", j.BlockHeader)
+	if err != nil {
+		return err
+	}
+	d.Proposal = j.Proposal
+	d.BlockHeader = header
+	d.BlockHeight = j.BlockHeight
+	return nil
+}
+
+type dposIllegalProposalsJSON struct {
+	Evidence        ProposalEvidence `json:"evidence"`
+	CompareEvidence ProposalEvidence `json:"compareevidence"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d DPOSIllegalProposals) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dposIllegalProposalsJSON{
+		Evidence:        d.Evidence,
+		CompareEvidence: d.CompareEvidence,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (d *DPOSIllegalProposals) UnmarshalJSON(data []byte) error {
+	var j dposIllegalProposalsJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	d.Evidence = j.Evidence
+	d.CompareEvidence = j.CompareEvidence
+	d.hash = nil
+	return nil
+}
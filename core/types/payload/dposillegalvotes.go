@@ -7,6 +7,7 @@ package payload
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 
 	"github.com/elastos/Elastos.ELA/common"
@@ -101,3 +102,63 @@ func (d *DPOSIllegalVotes) GetBlockHeight() uint32 {
 func (d *DPOSIllegalVotes) Type() IllegalDataType {
 	return IllegalVote
 }
+
+type voteEvidenceJSON struct {
+	Proposal    DPOSProposal     `json:"proposal"`
+	BlockHeader string           `json:"blockheader"`
+	BlockHeight uint32           `json:"blockheight"`
+	Vote        DPOSProposalVote `json:"vote"`
+}
+
+// MarshalJSON implements the json.Marshaler interface, the fields of the
+// embedded ProposalEvidence are flattened as in the serialized form.
+func (d VoteEvidence) MarshalJSON() ([]byte, error) {
+	return json.Marshal(voteEvidenceJSON{
+		Proposal:    d.Proposal,
+		BlockHeader: common.BytesToHexString(d.BlockHeader),
+		BlockHeight: d.BlockHeight,
+		Vote:        d.Vote,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (d *VoteEvidence) UnmarshalJSON(data []byte) error {
+	var j voteEvidenceJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	header, err := decodeHex("blockheader", j.BlockHeader)
+	if err != nil {
+		return err
+	}
+	d.Proposal = j.Proposal
+	d.BlockHeader = header
+	d.BlockHeight = j.BlockHeight
+	d.Vote = j.Vote
+	return nil
+}
+
+type dposIllegalVotesJSON struct {
+	Evidence        VoteEvidence `json:"evidence"`
+	CompareEvidence VoteEvidence `json:"compareevidence"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d DPOSIllegalVotes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dposIllegalVotesJSON{
+		Evidence:        d.Evidence,
+		CompareEvidence: d.CompareEvidence,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (d *DPOSIllegalVotes) UnmarshalJSON(data []byte) error {
+	var j dposIllegalVotesJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	d.Evidence = j.Evidence
+	d.CompareEvidence = j.CompareEvidence
+	d.hash = nil
+	return nil
+}
@@ -7,6 +7,7 @@ package payload
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 
 	"github.com/elastos/Elastos.ELA/common"
@@ -82,3 +83,46 @@ func (p *DPOSProposal) Hash() common.Uint256 {
 	}
 	return *p.hash
 }
+
+type dposProposalJSON struct {
+	Sponsor    string `json:"sponsor"`
+	BlockHash  string `json:"blockhash"`
+	ViewOffset uint32 `json:"viewoffset"`
+	Sign       string `json:"sign"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p DPOSProposal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dposProposalJSON{
+		Sponsor:    common.BytesToHexString(p.Sponsor),
+		BlockHash:  toReversedHash(p.BlockHash),
+		ViewOffset: p.ViewOffset,
+		Sign:       common.BytesToHexString(p.Sign),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *DPOSProposal) UnmarshalJSON(data []byte) error {
+	var j dposProposalJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	sponsor, err := decodeHex("sponsor", j.Sponsor)
+	if err != nil {
+		return err
+	}
+	blockHash, err := decodeReversedHash("blockhash", j.BlockHash)
+	if err != nil {
+		return err
+	}
+	sign, err := decodeHex("sign", j.Sign)
+	if err != nil {
+		return err
+	}
+	p.Sponsor = sponsor
+	p.BlockHash = blockHash
+	p.ViewOffset = j.ViewOffset
+	p.Sign = sign
+	p.hash = nil
+	return nil
+}
@@ -7,6 +7,7 @@ package payload
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 
 	"github.com/elastos/Elastos.ELA/common"
@@ -109,3 +110,46 @@ func (v *DPOSProposalVote) Hash() common.Uint256 {
 	}
 	return *v.hash
 }
+
+type dposProposalVoteJSON struct {
+	ProposalHash string `json:"proposalhash"`
+	Signer       string `json:"signer"`
+	Accept       bool   `json:"accept"`
+	Sign         string `json:"sign"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v DPOSProposalVote) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dposProposalVoteJSON{
+		ProposalHash: v.ProposalHash.String(),
+		Signer:       common.BytesToHexString(v.Signer),
+		Accept:       v.Accept,
+		Sign:         common.BytesToHexString(v.Sign),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *DPOSProposalVote) UnmarshalJSON(data []byte) error {
+	var j dposProposalVoteJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	proposalHash, err := decodeHash("proposalhash", j.ProposalHash)
+	if err != nil {
+		return err
+	}
+	signer, err := decodeHex("signer", j.Signer)
+	if err != nil {
+		return err
+	}
+	sign, err := decodeHex("sign", j.Sign)
+	if err != nil {
+		return err
+	}
+	v.ProposalHash = proposalHash
+	v.Signer = signer
+	v.Accept = j.Accept
+	v.Sign = sign
+	v.hash = nil
+	return nil
+}
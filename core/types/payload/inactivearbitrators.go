@@ -7,6 +7,7 @@ package payload
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 
 	"github.com/elastos/Elastos.ELA/common"
@@ -112,3 +113,39 @@ func (i *InactiveArbitrators) Hash() common.Uint256 {
 	}
 	return *i.hash
 }
+
+type inactiveArbitratorsJSON struct {
+	Sponsor     string   `json:"sponsor"`
+	Arbitrators []string `json:"arbitrators"`
+	BlockHeight uint32   `json:"blockheight"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (i InactiveArbitrators) MarshalJSON() ([]byte, error) {
+	return json.Marshal(inactiveArbitratorsJSON{
+		Sponsor:     common.BytesToHexString(i.Sponsor),
+		Arbitrators: hexList(i.Arbitrators),
+		BlockHeight: i.BlockHeight,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (i *InactiveArbitrators) UnmarshalJSON(data []byte) error {
+	var j inactiveArbitratorsJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	sponsor, err := decodeHex("sponsor", j.Sponsor)
+	if err != nil {
+		return err
+	}
+	arbitrators, err := decodeHexList("arbitrators", j.Arbitrators)
+	if err != nil {
+		return err
+	}
+	i.Sponsor = sponsor
+	i.Arbitrators = arbitrators
+	i.BlockHeight = j.BlockHeight
+	i.hash = nil
+	return nil
+}
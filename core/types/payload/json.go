@@ -0,0 +1,122 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package payload
+
+import (
+	"fmt"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+// The JSON form of payloads follows the conventions of the RPC service, byte
+// slices such as public keys and signatures are hex strings, hashes are the
+// hex strings of Uint256 and program hashes are addresses.  Transaction and
+// block hashes of the main chain are reversed hex strings as returned by
+// getrawtransaction and getblock.
+
+// decodeHex decodes the hex string of the JSON field.
+func decodeHex(field, s string) ([]byte, error) {
+	data, err := common.HexStringToBytes(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s, %s", field, err)
+	}
+	return data, nil
+}
+
+// hexList encodes the byte slices to hex strings.
+func hexList(data [][]byte) []string {
+	list := make([]string, 0, len(data))
+	for _, d := range data {
+		list = append(list, common.BytesToHexString(d))
+	}
+	return list
+}
+
+// decodeHexList decodes the hex strings of the JSON field.
+func decodeHexList(field string, list []string) ([][]byte, error) {
+	data := make([][]byte, 0, len(list))
+	for _, s := range list {
+		d, err := decodeHex(field, s)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, d)
+	}
+	return data, nil
+}
+
+// decodeHash decodes the hex string of the hash in the JSON field.
+func decodeHash(field, s string) (common.Uint256, error) {
+	hash, err := common.Uint256FromHexString(s)
+	if err != nil {
+		return common.Uint256{}, fmt.Errorf("invalid %s, %s", field, err)
+	}
+	return *hash, nil
+}
+
+// toReversedHash encodes the hash to the reversed hex string.
+func toReversedHash(hash common.Uint256) string {
+	return common.BytesToHexString(common.BytesReverse(hash.Bytes()))
+}
+
+// decodeReversedHash decodes the reversed hex string of the hash in the JSON
+// field.
+func decodeReversedHash(field, s string) (common.Uint256, error) {
+	data, err := decodeHex(field, s)
+	if err != nil {
+		return common.Uint256{}, err
+	}
+	hash, err := common.Uint256FromBytes(common.BytesReverse(data))
+	if err != nil {
+		return common.Uint256{}, fmt.Errorf("invalid %s, %s", field, err)
+	}
+	return *hash, nil
+}
+
+// hashList encodes the hashes to hex strings.
+func hashList(hashes []common.Uint256) []string {
+	list := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		list = append(list, hash.String())
+	}
+	return list
+}
+
+// decodeHashList decodes the hex strings of the hashes in the JSON field.
+func decodeHashList(field string, list []string) ([]common.Uint256, error) {
+	hashes := make([]common.Uint256, 0, len(list))
+	for _, s := range list {
+		hash, err := decodeHash(field, s)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
+// toAddress encodes the program hash to address, an empty program hash is
+// encoded to an empty string.
+func toAddress(programHash common.Uint168) string {
+	if programHash == (common.Uint168{}) {
+		return ""
+	}
+	address, _ := programHash.ToAddress()
+	return address
+}
+
+// decodeAddress decodes the address in the JSON field to program hash, an
+// empty string is decoded to an empty program hash.
+func decodeAddress(field, address string) (common.Uint168, error) {
+	if address == "" {
+		return common.Uint168{}, nil
+	}
+	programHash, err := common.Uint168FromAddress(address)
+	if err != nil {
+		return common.Uint168{}, fmt.Errorf("invalid %s, %s", field, err)
+	}
+	return *programHash, nil
+}
@@ -0,0 +1,392 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package payload
+
+import (
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// jsonFields returns the paths of all fields in the JSON value, the elements
+// of arrays share the path of the array.
+func jsonFields(prefix string, v interface{}, fields map[string]struct{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			path := k
+			if prefix != "" {
+				path = prefix + "." + k
+			}
+			fields[path] = struct{}{}
+			jsonFields(path, e, fields)
+		}
+	case []interface{}:
+		for _, e := range v {
+			jsonFields(prefix, e, fields)
+		}
+	}
+}
+
+func randomJSONBytes(n int) []byte {
+	b := make([]byte, n)
+	rand.Read(b)
+	return b
+}
+
+func randomJSONHash() common.Uint256 {
+	var hash common.Uint256
+	rand.Read(hash[:])
+	return hash
+}
+
+func jsonProgramHash(address string) common.Uint168 {
+	programHash, _ := common.Uint168FromAddress(address)
+	return *programHash
+}
+
+func TestPayload_JSON(t *testing.T) {
+	standard := jsonProgramHash("EJMzC16Eorq9CuFCGtyMrq4Jmgw9jYCHQR")
+	cid := jsonProgramHash("iY82cT1BnjSiaC7qbt7MN7mcayDVHESyqB")
+	signs := []CRMemberSign{{CID: cid, Signature: randomJSONBytes(64)}}
+	proposal := DPOSProposal{
+		Sponsor:    randomJSONBytes(33),
+		BlockHash:  randomJSONHash(),
+		ViewOffset: 1,
+		Sign:       randomJSONBytes(64),
+	}
+	vote := DPOSProposalVote{
+		ProposalHash: randomJSONHash(),
+		Signer:       randomJSONBytes(33),
+		Accept:       true,
+		Sign:         randomJSONBytes(64),
+	}
+	blockEvidence := BlockEvidence{
+		Header:       randomJSONBytes(80),
+		BlockConfirm: randomJSONBytes(100),
+		Signers:      [][]byte{randomJSONBytes(33)},
+	}
+	proposalEvidence := ProposalEvidence{
+		Proposal:    proposal,
+		BlockHeader: randomJSONBytes(80),
+		BlockHeight: 100,
+	}
+	voteEvidence := VoteEvidence{
+		ProposalEvidence: proposalEvidence,
+		Vote:             vote,
+	}
+
+	tests := []struct {
+		payload interface{}
+		fields  []string
+	}{
+		{
+			&CoinBase{Content: []byte("coinbase")},
+			[]string{"coinbasedata"},
+		},
+		{
+			&RegisterAsset{
+				Asset:      Asset{Name: "ELA", Precision: 8},
+				Amount:     100,
+				Controller: standard,
+			},
+			[]string{"amount", "asset", "asset.AssetType", "asset.Description",
+				"asset.Name", "asset.Precision", "asset.RecordType", "controller"},
+		},
+		{
+			&SideChainPow{
+				SideBlockHash:   randomJSONHash(),
+				SideGenesisHash: randomJSONHash(),
+				BlockHeight:     100,
+				Signature:       randomJSONBytes(64),
+			},
+			[]string{"blockheight", "sideblockhash", "sidegenesishash",
+				"signature"},
+		},
+		{
+			&WithdrawFromSideChain{
+				BlockHeight:                100,
+				GenesisBlockAddress:        "XKUh4GLhFJiqAMTF6HyWQrV9pK9HcGUdfJ",
+				SideChainTransactionHashes: []common.Uint256{randomJSONHash()},
+				Proofs: []SideChainTxProof{{
+					SideChainPowTxHash: randomJSONHash(),
+					SideBlockHeader:    randomJSONBytes(80),
+					MerkleBranch:       []common.Uint256{randomJSONHash()},
+					Index:              1,
+				}},
+			},
+			[]string{"blockheight", "genesisblockaddress", "proofs",
+				"proofs.index", "proofs.merklebranch", "proofs.sideblockheader",
+				"proofs.sidechainpowtxhash", "sidechaintransactionhashes"},
+		},
+		{
+			&TransferCrossChainAsset{
+				CrossChainAddresses: []string{"EJMzC16Eorq9CuFCGtyMrq4Jmgw9jYCHQR"},
+				OutputIndexes:       []uint64{0},
+				CrossChainAmounts:   []common.Fixed64{100},
+			},
+			[]string{"crosschainaddresses", "crosschainamounts", "outputindexes"},
+		},
+		{
+			&Record{Type: "record", Content: randomJSONBytes(10)},
+			[]string{"content", "type"},
+		},
+		{
+			&ProducerInfo{
+				OwnerPublicKey: randomJSONBytes(33),
+				NodePublicKey:  randomJSONBytes(33),
+				NickName:       "producer",
+				Url:            "https://elastos.org",
+				Location:       86,
+				NetAddress:     "127.0.0.1:20338",
+				StakeAddress:   standard,
+				NodeVersion:    "v0.1.0",
+				Signature:      randomJSONBytes(64),
+			},
+			[]string{"location", "netaddress", "nickname", "nodepublickey",
+				"nodeversion", "ownerpublickey", "signature", "stakeaddress",
+				"url"},
+		},
+		{
+			&ProcessProducer{
+				OwnerPublicKey: randomJSONBytes(33),
+				Signature:      randomJSONBytes(64),
+			},
+			[]string{"ownerpublickey", "signature"},
+		},
+		{
+			&ActivateProducer{
+				NodePublicKey: randomJSONBytes(33),
+				Signature:     randomJSONBytes(64),
+			},
+			[]string{"nodepublickey", "signature"},
+		},
+		{
+			&UpdateVersion{StartHeight: 100, EndHeight: 200},
+			[]string{"endheight", "startheight"},
+		},
+		{
+			&CRInfo{
+				Code:      randomJSONBytes(35),
+				CID:       cid,
+				DID:       cid,
+				NickName:  "cr",
+				Url:       "https://elastos.org",
+				Location:  86,
+				Salt:      randomJSONBytes(32),
+				Signature: randomJSONBytes(64),
+			},
+			[]string{"cid", "code", "did", "location", "nickname", "salt",
+				"signature", "url"},
+		},
+		{
+			&UnregisterCR{CID: cid, Signature: randomJSONBytes(64)},
+			[]string{"cid", "signature"},
+		},
+		{
+			&CRNicknameCommit{Commitment: randomJSONHash()},
+			[]string{"commitment"},
+		},
+		{
+			&CustomIDProposal{
+				ProposalType:    ChangeCustomIDFee,
+				CustomIDs:       []string{"elastos"},
+				FeeRate:         100,
+				EffectiveHeight: 100,
+				Signs:           signs,
+			},
+			[]string{"customids", "effectiveheight", "feerate", "proposaltype",
+				"signs", "signs.cid", "signs.signature"},
+		},
+		{
+			&CRCRewardAddress{
+				NodePublicKey:     randomJSONBytes(33),
+				RewardProgramHash: standard,
+				Height:            100,
+				Signature:         randomJSONBytes(64),
+				Signs:             signs,
+			},
+			[]string{"height", "nodepublickey", "rewardaddress", "signature",
+				"signs", "signs.cid", "signs.signature"},
+		},
+		{
+			&ProducerAppeal{
+				OwnerPublicKey:   randomJSONBytes(33),
+				Reason:           "reason",
+				ActivationHeight: 100,
+				Signature:        randomJSONBytes(64),
+				Signs:            signs,
+			},
+			[]string{"activationheight", "ownerpublickey", "reason",
+				"signature", "signs", "signs.cid", "signs.signature"},
+		},
+		{
+			&RotateNodeKey{
+				OwnerPublicKey:     randomJSONBytes(33),
+				NodePublicKey:      randomJSONBytes(33),
+				NewNodePublicKey:   randomJSONBytes(33),
+				LastRotationHeight: 100,
+				NewNodeSignature:   randomJSONBytes(64),
+				Signature:          randomJSONBytes(64),
+			},
+			[]string{"lastrotationheight", "newnodepublickey",
+				"newnodesignature", "nodepublickey", "ownerpublickey",
+				"signature"},
+		},
+		{
+			&RevokeVote{Votes: []RevokedVote{{TxID: randomJSONHash(), Index: 1}}},
+			[]string{"votes", "votes.index", "votes.txid"},
+		},
+		{
+			&proposal,
+			[]string{"blockhash", "sign", "sponsor", "viewoffset"},
+		},
+		{
+			&vote,
+			[]string{"accept", "proposalhash", "sign", "signer"},
+		},
+		{
+			&Confirm{Proposal: proposal, Votes: []DPOSProposalVote{vote}},
+			[]string{"proposal", "proposal.blockhash", "proposal.sign",
+				"proposal.sponsor", "proposal.viewoffset", "votes",
+				"votes.accept", "votes.proposalhash", "votes.sign",
+				"votes.signer"},
+		},
+		{
+			&DPOSIllegalBlocks{
+				CoinType:        ELACoin,
+				BlockHeight:     100,
+				Evidence:        blockEvidence,
+				CompareEvidence: blockEvidence,
+			},
+			[]string{"blockheight", "cointype", "compareevidence",
+				"compareevidence.blockconfirm", "compareevidence.header",
+				"compareevidence.signers", "evidence", "evidence.blockconfirm",
+				"evidence.header", "evidence.signers"},
+		},
+		{
+			&DPOSIllegalProposals{
+				Evidence:        proposalEvidence,
+				CompareEvidence: proposalEvidence,
+			},
+			[]string{"compareevidence", "compareevidence.blockheader",
+				"compareevidence.blockheight", "compareevidence.proposal",
+				"compareevidence.proposal.blockhash",
+				"compareevidence.proposal.sign",
+				"compareevidence.proposal.sponsor",
+				"compareevidence.proposal.viewoffset", "evidence",
+				"evidence.blockheader", "evidence.blockheight",
+				"evidence.proposal", "evidence.proposal.blockhash",
+				"evidence.proposal.sign", "evidence.proposal.sponsor",
+				"evidence.proposal.viewoffset"},
+		},
+		{
+			&DPOSIllegalVotes{
+				Evidence:        voteEvidence,
+				CompareEvidence: voteEvidence,
+			},
+			[]string{"compareevidence", "compareevidence.blockheader",
+				"compareevidence.blockheight", "compareevidence.proposal",
+				"compareevidence.proposal.blockhash",
+				"compareevidence.proposal.sign",
+				"compareevidence.proposal.sponsor",
+				"compareevidence.proposal.viewoffset", "compareevidence.vote",
+				"compareevidence.vote.accept",
+				"compareevidence.vote.proposalhash",
+				"compareevidence.vote.sign", "compareevidence.vote.signer",
+				"evidence", "evidence.blockheader", "evidence.blockheight",
+				"evidence.proposal", "evidence.proposal.blockhash",
+				"evidence.proposal.sign", "evidence.proposal.sponsor",
+				"evidence.proposal.viewoffset", "evidence.vote",
+				"evidence.vote.accept", "evidence.vote.proposalhash",
+				"evidence.vote.sign", "evidence.vote.signer"},
+		},
+		{
+			&InactiveArbitrators{
+				Sponsor:     randomJSONBytes(33),
+				Arbitrators: [][]byte{randomJSONBytes(33)},
+				BlockHeight: 100,
+			},
+			[]string{"arbitrators", "blockheight", "sponsor"},
+		},
+		{
+			&SidechainIllegalData{
+				IllegalType:         SidechainIllegalProposal,
+				Height:              100,
+				IllegalSigner:       randomJSONBytes(33),
+				Evidence:            SidechainIllegalEvidence{randomJSONHash()},
+				CompareEvidence:     SidechainIllegalEvidence{randomJSONHash()},
+				GenesisBlockAddress: "XKUh4GLhFJiqAMTF6HyWQrV9pK9HcGUdfJ",
+				Signs:               [][]byte{randomJSONBytes(64)},
+			},
+			[]string{"compareevidence", "evidence", "genesisblockaddress",
+				"height", "illegalsigner", "illegaltype", "signs"},
+		},
+		{
+			&TransferAsset{},
+			[]string{},
+		},
+		{
+			&ReturnDepositCoin{},
+			[]string{},
+		},
+	}
+
+	for _, test := range tests {
+		name := reflect.TypeOf(test.payload).Elem().Name()
+		data, err := json.Marshal(test.payload)
+		if !assert.NoError(t, err, name) {
+			continue
+		}
+
+		var value interface{}
+		assert.NoError(t, json.Unmarshal(data, &value), name)
+		fields := make(map[string]struct{})
+		jsonFields("", value, fields)
+		names := make([]string, 0, len(fields))
+		for field := range fields {
+			names = append(names, field)
+		}
+		sort.Strings(names)
+		assert.Equal(t, test.fields, names, name)
+
+		decoded := reflect.New(reflect.TypeOf(test.payload).Elem()).Interface()
+		assert.NoError(t, json.Unmarshal(data, decoded), name)
+		assert.Equal(t, test.payload, decoded, name)
+	}
+}
+
+func TestPayload_JSONConventions(t *testing.T) {
+	txID := randomJSONHash()
+	data, err := json.Marshal(&RevokeVote{
+		Votes: []RevokedVote{{TxID: txID, Index: 1}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"votes":[{"txid":"`+
+		common.BytesToHexString(common.BytesReverse(txID.Bytes()))+
+		`","index":1}]}`, string(data))
+
+	// Stake address and node version are omitted if not set.
+	data, err = json.Marshal(&ProducerInfo{NickName: "producer"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"ownerpublickey":"","nodepublickey":"",`+
+		`"nickname":"producer","url":"","location":0,"netaddress":"",`+
+		`"signature":""}`, string(data))
+
+	// Invalid hex strings are rejected.
+	var p ProcessProducer
+	assert.Error(t, json.Unmarshal(
+		[]byte(`{"ownerpublickey":"xx","signature":""}`), &p))
+	var u UnregisterCR
+	assert.Error(t, json.Unmarshal(
+		[]byte(`{"cid":"invalid","signature":""}`), &u))
+}
@@ -7,6 +7,7 @@ package payload
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"io"
 
@@ -80,3 +81,35 @@ func (a *ProcessProducer) DeserializeUnsigned(r io.Reader, version byte) error {
 
 	return err
 }
+
+type processProducerJSON struct {
+	OwnerPublicKey string `json:"ownerpublickey"`
+	Signature      string `json:"signature"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (a ProcessProducer) MarshalJSON() ([]byte, error) {
+	return json.Marshal(processProducerJSON{
+		OwnerPublicKey: common.BytesToHexString(a.OwnerPublicKey),
+		Signature:      common.BytesToHexString(a.Signature),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (a *ProcessProducer) UnmarshalJSON(data []byte) error {
+	var j processProducerJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	ownerPublicKey, err := decodeHex("ownerpublickey", j.OwnerPublicKey)
+	if err != nil {
+		return err
+	}
+	signature, err := decodeHex("signature", j.Signature)
+	if err != nil {
+		return err
+	}
+	a.OwnerPublicKey = ownerPublicKey
+	a.Signature = signature
+	return nil
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package payload
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+const ProducerAppealVersion byte = 0x00
+
+// MaxProducerAppealReasonLength indicates the max length of the reason of a
+// producer appeal.
+const MaxProducerAppealReasonLength = 512
+
+// ProducerAppeal is the appeal of a penalized producer, the penalty will be
+// lifted at the activation height if the appeal is approved by the CR
+// committee.
+type ProducerAppeal struct {
+	OwnerPublicKey   []byte
+	Reason           string
+	ActivationHeight uint32
+	Signature        []byte
+	Signs            []CRMemberSign
+}
+
+func (p *ProducerAppeal) Data(version byte) []byte {
+	buf := new(bytes.Buffer)
+	if err := p.Serialize(buf, version); err != nil {
+		return []byte{0}
+	}
+	return buf.Bytes()
+}
+
+func (p *ProducerAppeal) Serialize(w io.Writer, version byte) error {
+	if err := p.SerializeUnsigned(w, version); err != nil {
+		return err
+	}
+
+	if err := common.WriteVarBytes(w, p.Signature); err != nil {
+		return errors.New("[ProducerAppeal], signature serialize failed")
+	}
+
+	if err := common.WriteVarUint(w, uint64(len(p.Signs))); err != nil {
+		return errors.New("[ProducerAppeal], signs count serialize failed")
+	}
+	for _, s := range p.Signs {
+		if err := s.CID.Serialize(w); err != nil {
+			return errors.New("[ProducerAppeal], sign CID serialize failed")
+		}
+		if err := common.WriteVarBytes(w, s.Signature); err != nil {
+			return errors.New("[ProducerAppeal], sign signature serialize failed")
+		}
+	}
+
+	return nil
+}
+
+func (p *ProducerAppeal) SerializeUnsigned(w io.Writer, version byte) error {
+	if err := common.WriteVarBytes(w, p.OwnerPublicKey); err != nil {
+		return errors.New("[ProducerAppeal], owner public key serialize failed")
+	}
+
+	if err := common.WriteVarString(w, p.Reason); err != nil {
+		return errors.New("[ProducerAppeal], reason serialize failed")
+	}
+
+	if err := common.WriteUint32(w, p.ActivationHeight); err != nil {
+		return errors.New("[ProducerAppeal], activation height serialize failed")
+	}
+
+	return nil
+}
+
+func (p *ProducerAppeal) Deserialize(r io.Reader, version byte) error {
+	if err := p.DeserializeUnsigned(r, version); err != nil {
+		return err
+	}
+
+	var err error
+	p.Signature, err = common.ReadVarBytes(r, crypto.SignatureLength,
+		"signature")
+	if err != nil {
+		return errors.New("[ProducerAppeal], signature deserialize failed")
+	}
+
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return errors.New("[ProducerAppeal], signs count deserialize failed")
+	}
+	if count > MaxCRMemberSignsCount {
+		return errors.New("[ProducerAppeal], too many signs")
+	}
+	p.Signs = make([]CRMemberSign, 0, count)
+	for i := uint64(0); i < count; i++ {
+		var s CRMemberSign
+		if err := s.CID.Deserialize(r); err != nil {
+			return errors.New("[ProducerAppeal], sign CID deserialize failed")
+		}
+		s.Signature, err = common.ReadVarBytes(r,
+			crypto.MaxSignatureScriptLength, "signature")
+		if err != nil {
+			return errors.New("[ProducerAppeal], sign signature deserialize failed")
+		}
+		p.Signs = append(p.Signs, s)
+	}
+
+	return nil
+}
+
+func (p *ProducerAppeal) DeserializeUnsigned(r io.Reader, version byte) error {
+	var err error
+	p.OwnerPublicKey, err = common.ReadVarBytes(r, crypto.NegativeBigLength,
+		"owner public key")
+	if err != nil {
+		return errors.New("[ProducerAppeal], owner public key deserialize failed")
+	}
+
+	p.Reason, err = common.ReadVarString(r)
+	if err != nil {
+		return errors.New("[ProducerAppeal], reason deserialize failed")
+	}
+
+	if p.ActivationHeight, err = common.ReadUint32(r); err != nil {
+		return errors.New("[ProducerAppeal], activation height deserialize failed")
+	}
+
+	return nil
+}
+
+type producerAppealJSON struct {
+	OwnerPublicKey   string         `json:"ownerpublickey"`
+	Reason           string         `json:"reason"`
+	ActivationHeight uint32         `json:"activationheight"`
+	Signature        string         `json:"signature"`
+	Signs            []CRMemberSign `json:"signs"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p ProducerAppeal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(producerAppealJSON{
+		OwnerPublicKey:   common.BytesToHexString(p.OwnerPublicKey),
+		Reason:           p.Reason,
+		ActivationHeight: p.ActivationHeight,
+		Signature:        common.BytesToHexString(p.Signature),
+		Signs:            p.Signs,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *ProducerAppeal) UnmarshalJSON(data []byte) error {
+	var j producerAppealJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	ownerPublicKey, err := decodeHex("ownerpublickey", j.OwnerPublicKey)
+	if err != nil {
+		return err
+	}
+	signature, err := decodeHex("signature", j.Signature)
+	if err != nil {
+		return err
+	}
+	p.OwnerPublicKey = ownerPublicKey
+	p.Reason = j.Reason
+	p.ActivationHeight = j.ActivationHeight
+	p.Signature = signature
+	p.Signs = j.Signs
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package payload
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProducerAppeal_Deserialize(t *testing.T) {
+	payload1 := randomProducerAppealPayload()
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, payload1.Serialize(buf, ProducerAppealVersion))
+
+	payload2 := &ProducerAppeal{}
+	assert.NoError(t, payload2.Deserialize(buf, ProducerAppealVersion))
+
+	assert.Equal(t, payload1, payload2)
+
+	// Huge count of signs.
+	buf = new(bytes.Buffer)
+	payload1.SerializeUnsigned(buf, ProducerAppealVersion)
+	common.WriteVarBytes(buf, payload1.Signature)
+	common.WriteVarUint(buf, math.MaxUint64)
+	assert.EqualError(t, payload2.Deserialize(buf, ProducerAppealVersion),
+		"[ProducerAppeal], too many signs")
+}
+
+func randomProducerAppealPayload() *ProducerAppeal {
+	return &ProducerAppeal{
+		OwnerPublicKey:   randomBytes(33),
+		Reason:           "inactive due to network partition",
+		ActivationHeight: rand.Uint32(),
+		Signature:        randomBytes(64),
+		Signs: []CRMemberSign{
+			{CID: *randomUint168(), Signature: randomBytes(65)},
+			{CID: *randomUint168(), Signature: randomBytes(65)},
+		},
+	}
+}
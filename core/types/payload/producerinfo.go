@@ -7,6 +7,7 @@ package payload
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"io"
 
@@ -15,6 +16,13 @@ import (
 )
 
 const ProducerInfoVersion byte = 0x00
+const ProducerInfoStakeVersion byte = 0x01
+
+// ProducerInfoLatestVersion is the latest supported version of ProducerInfo.
+const ProducerInfoLatestVersion = ProducerInfoStakeVersion
+
+// MaxNodeVersionLength indicates the max length of node version.
+const MaxNodeVersionLength = 32
 
 type ProducerInfo struct {
 	OwnerPublicKey []byte
@@ -23,7 +31,50 @@ type ProducerInfo struct {
 	Url            string
 	Location       uint64
 	NetAddress     string
-	Signature      []byte
+
+	// StakeAddress and NodeVersion are added in ProducerInfoStakeVersion.
+	StakeAddress common.Uint168
+	NodeVersion  string
+
+	Signature []byte
+}
+
+// producerInfoExtension defines fields appended to ProducerInfo since the
+// payload version, fields of all extensions not greater than the payload
+// version are serialized in order after the fields of ProducerInfoVersion.
+type producerInfoExtension struct {
+	version     byte
+	serialize   func(a *ProducerInfo, w io.Writer) error
+	deserialize func(a *ProducerInfo, r io.Reader) error
+}
+
+var producerInfoExtensions = []producerInfoExtension{
+	{
+		version: ProducerInfoStakeVersion,
+		serialize: func(a *ProducerInfo, w io.Writer) error {
+			if err := a.StakeAddress.Serialize(w); err != nil {
+				return errors.New("[ProducerInfo], stake address serialize failed")
+			}
+			if err := common.WriteVarString(w, a.NodeVersion); err != nil {
+				return errors.New("[ProducerInfo], node version serialize failed")
+			}
+			return nil
+		},
+		deserialize: func(a *ProducerInfo, r io.Reader) error {
+			if err := a.StakeAddress.Deserialize(r); err != nil {
+				return errors.New("[ProducerInfo], stake address deserialize failed")
+			}
+			nodeVersion, err := common.ReadVarString(r)
+			if err != nil {
+				return errors.New("[ProducerInfo], node version deserialize failed")
+			}
+			if len(nodeVersion) > MaxNodeVersionLength {
+				return errors.New("[ProducerInfo], node version is too long")
+			}
+			a.NodeVersion = nodeVersion
+			return nil
+		},
+	},
 }
 
 func (a *ProducerInfo) Data(version byte) []byte {
@@ -49,6 +100,10 @@ func (a *ProducerInfo) Serialize(w io.Writer, version byte) error {
 }
 
 func (a *ProducerInfo) SerializeUnsigned(w io.Writer, version byte) error {
+	if version > ProducerInfoLatestVersion {
+		return errors.New("[ProducerInfo], unsupported payload version")
+	}
+
 	err := common.WriteVarBytes(w, a.OwnerPublicKey)
 	if err != nil {
 		return errors.New("[ProducerInfo], owner publicKey serialize failed")
@@ -79,6 +134,15 @@ func (a *ProducerInfo) SerializeUnsigned(w io.Writer, version byte) error {
 		return errors.New("[ProducerInfo], address serialize failed")
 	}
 
+	for _, ext := range producerInfoExtensions {
+		if version < ext.version {
+			break
+		}
+		if err := ext.serialize(a, w); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -96,6 +160,10 @@ func (a *ProducerInfo) Deserialize(r io.Reader, version byte) error {
 }
 
 func (a *ProducerInfo) DeserializeUnsigned(r io.Reader, version byte) error {
+	if version > ProducerInfoLatestVersion {
+		return errors.New("[ProducerInfo], unsupported payload version")
+	}
+
 	var err error
 	a.OwnerPublicKey, err = common.ReadVarBytes(r, crypto.NegativeBigLength, "own public key")
 	if err != nil {
@@ -127,5 +195,76 @@ func (a *ProducerInfo) DeserializeUnsigned(r io.Reader, version byte) error {
 		return errors.New("[ProducerInfo], address deserialize failed")
 	}
 
+	for _, ext := range producerInfoExtensions {
+		if version < ext.version {
+			break
+		}
+		if err := ext.deserialize(a, r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type producerInfoJSON struct {
+	OwnerPublicKey string `json:"ownerpublickey"`
+	NodePublicKey  string `json:"nodepublickey"`
+	NickName       string `json:"nickname"`
+	Url            string `json:"url"`
+	Location       uint64 `json:"location"`
+	NetAddress     string `json:"netaddress"`
+	StakeAddress   string `json:"stakeaddress,omitempty"`
+	NodeVersion    string `json:"nodeversion,omitempty"`
+	Signature      string `json:"signature"`
+}
+
+// MarshalJSON implements the json.Marshaler interface, the stake address and
+// node version are omitted if not set.
+func (a ProducerInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(producerInfoJSON{
+		OwnerPublicKey: common.BytesToHexString(a.OwnerPublicKey),
+		NodePublicKey:  common.BytesToHexString(a.NodePublicKey),
+		NickName:       a.NickName,
+		Url:            a.Url,
+		Location:       a.Location,
+		NetAddress:     a.NetAddress,
+		StakeAddress:   toAddress(a.StakeAddress),
+		NodeVersion:    a.NodeVersion,
+		Signature:      common.BytesToHexString(a.Signature),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (a *ProducerInfo) UnmarshalJSON(data []byte) error {
+	var j producerInfoJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	ownerPublicKey, err := decodeHex("ownerpublickey", j.OwnerPublicKey)
+	if err != nil {
+		return err
+	}
+	nodePublicKey, err := decodeHex("nodepublickey", j.NodePublicKey)
+	if err != nil {
+		return err
+	}
+	stakeAddress, err := decodeAddress("stakeaddress", j.StakeAddress)
+	if err != nil {
+		return err
+	}
+	signature, err := decodeHex("signature", j.Signature)
+	if err != nil {
+		return err
+	}
+	a.OwnerPublicKey = ownerPublicKey
+	a.NodePublicKey = nodePublicKey
+	a.NickName = j.NickName
+	a.Url = j.Url
+	a.Location = j.Location
+	a.NetAddress = j.NetAddress
+	a.StakeAddress = stakeAddress
+	a.NodeVersion = j.NodeVersion
+	a.Signature = signature
 	return nil
 }
@@ -0,0 +1,79 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package payload
+
+import (
+	"bytes"
+	rand2 "math/rand"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProducerInfo_Deserialize(t *testing.T) {
+	info1 := randomProducerInfoPayload()
+
+	// fields added in ProducerInfoStakeVersion should be ignored in
+	// ProducerInfoVersion.
+	buf := new(bytes.Buffer)
+	assert.NoError(t, info1.Serialize(buf, ProducerInfoVersion))
+	info2 := &ProducerInfo{}
+	assert.NoError(t, info2.Deserialize(buf, ProducerInfoVersion))
+	assert.True(t, producerInfoEqual(info1, info2, ProducerInfoVersion))
+	assert.Equal(t, common.Uint168{}, info2.StakeAddress)
+	assert.Equal(t, "", info2.NodeVersion)
+
+	buf = new(bytes.Buffer)
+	assert.NoError(t, info1.Serialize(buf, ProducerInfoStakeVersion))
+	info3 := &ProducerInfo{}
+	assert.NoError(t, info3.Deserialize(buf, ProducerInfoStakeVersion))
+	assert.True(t, producerInfoEqual(info1, info3, ProducerInfoStakeVersion))
+
+	// unsupported version
+	buf = new(bytes.Buffer)
+	assert.Error(t, info1.Serialize(buf, ProducerInfoLatestVersion+1))
+	assert.Error(t, info3.Deserialize(buf, ProducerInfoLatestVersion+1))
+
+	// node version too long
+	info1.NodeVersion = string(randomBytes(MaxNodeVersionLength + 1))
+	buf = new(bytes.Buffer)
+	assert.NoError(t, info1.Serialize(buf, ProducerInfoStakeVersion))
+	assert.Error(t, info3.Deserialize(buf, ProducerInfoStakeVersion))
+}
+
+func producerInfoEqual(info1 *ProducerInfo, info2 *ProducerInfo,
+	version byte) bool {
+	if !bytes.Equal(info1.OwnerPublicKey, info2.OwnerPublicKey) ||
+		!bytes.Equal(info1.NodePublicKey, info2.NodePublicKey) ||
+		info1.NickName != info2.NickName ||
+		info1.Url != info2.Url ||
+		info1.Location != info2.Location ||
+		info1.NetAddress != info2.NetAddress ||
+		!bytes.Equal(info1.Signature, info2.Signature) {
+		return false
+	}
+
+	if version >= ProducerInfoStakeVersion {
+		return info1.StakeAddress.IsEqual(info2.StakeAddress) &&
+			info1.NodeVersion == info2.NodeVersion
+	}
+	return true
+}
+
+func randomProducerInfoPayload() *ProducerInfo {
+	return &ProducerInfo{
+		OwnerPublicKey: randomBytes(33),
+		NodePublicKey:  randomBytes(33),
+		NickName:       randomString(),
+		Url:            randomString(),
+		Location:       rand2.Uint64(),
+		NetAddress:     randomString(),
+		StakeAddress:   *randomUint168(),
+		NodeVersion:    "v0.4.0",
+		Signature:      randomBytes(65),
+	}
+}
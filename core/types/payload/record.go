@@ -6,6 +6,7 @@
 package payload
 
 import (
+	"encoding/json"
 	"errors"
 	"io"
 
@@ -51,3 +52,31 @@ func (a *Record) Deserialize(r io.Reader, version byte) error {
 	}
 	return nil
 }
+
+type recordJSON struct {
+	Type    string `json:"type"`
+	Content string `json:"content"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (a Record) MarshalJSON() ([]byte, error) {
+	return json.Marshal(recordJSON{
+		Type:    a.Type,
+		Content: common.BytesToHexString(a.Content),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (a *Record) UnmarshalJSON(data []byte) error {
+	var j recordJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	content, err := decodeHex("content", j.Content)
+	if err != nil {
+		return err
+	}
+	a.Type = j.Type
+	a.Content = content
+	return nil
+}
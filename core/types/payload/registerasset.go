@@ -6,6 +6,7 @@
 package payload
 
 import (
+	"encoding/json"
 	"errors"
 	"io"
 
@@ -60,3 +61,43 @@ func (a *RegisterAsset) Deserialize(r io.Reader, version byte) error {
 	}
 	return nil
 }
+
+type registerAssetJSON struct {
+	Asset      Asset  `json:"asset"`
+	Amount     string `json:"amount"`
+	Controller string `json:"controller"`
+}
+
+// MarshalJSON implements the json.Marshaler interface, the controller is
+// encoded as the reversed hex string.
+func (a RegisterAsset) MarshalJSON() ([]byte, error) {
+	return json.Marshal(registerAssetJSON{
+		Asset:      a.Asset,
+		Amount:     a.Amount.String(),
+		Controller: common.BytesToHexString(common.BytesReverse(a.Controller.Bytes())),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (a *RegisterAsset) UnmarshalJSON(data []byte) error {
+	var j registerAssetJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	amount, err := common.StringToFixed64(j.Amount)
+	if err != nil {
+		return errors.New("invalid amount, " + err.Error())
+	}
+	controller, err := decodeHex("controller", j.Controller)
+	if err != nil {
+		return err
+	}
+	programHash, err := common.Uint168FromBytes(common.BytesReverse(controller))
+	if err != nil {
+		return errors.New("invalid controller, " + err.Error())
+	}
+	a.Asset = j.Asset
+	a.Amount = *amount
+	a.Controller = *programHash
+	return nil
+}
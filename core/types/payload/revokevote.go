@@ -0,0 +1,122 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package payload
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+const RevokeVoteVersion byte = 0x00
+
+// MaxRevokedVotesCount indicates the max count of vote outputs can be revoked
+// by one revoke vote transaction.
+const MaxRevokedVotesCount = 100
+
+// RevokedVote indicates a previous vote output by the transaction ID and
+// the output index.
+type RevokedVote struct {
+	TxID  common.Uint256
+	Index uint16
+}
+
+// RevokeVote revokes the votes of previous vote outputs without spending
+// them, the funds of the vote outputs will not be moved.
+type RevokeVote struct {
+	Votes []RevokedVote
+}
+
+func (p *RevokeVote) Data(version byte) []byte {
+	buf := new(bytes.Buffer)
+	if err := p.Serialize(buf, version); err != nil {
+		return []byte{0}
+	}
+	return buf.Bytes()
+}
+
+func (p *RevokeVote) Serialize(w io.Writer, version byte) error {
+	if err := common.WriteVarUint(w, uint64(len(p.Votes))); err != nil {
+		return errors.New("[RevokeVote], votes count serialize failed")
+	}
+
+	for _, v := range p.Votes {
+		if err := common.WriteElements(w, &v.TxID, v.Index); err != nil {
+			return errors.New("[RevokeVote], vote serialize failed")
+		}
+	}
+	return nil
+}
+
+func (p *RevokeVote) Deserialize(r io.Reader, version byte) error {
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return errors.New("[RevokeVote], votes count deserialize failed")
+	}
+	if count > MaxRevokedVotesCount {
+		return errors.New("[RevokeVote], too many revoked votes")
+	}
+
+	p.Votes = make([]RevokedVote, 0, count)
+	for i := uint64(0); i < count; i++ {
+		var v RevokedVote
+		if err := common.ReadElements(r, &v.TxID, &v.Index); err != nil {
+			return errors.New("[RevokeVote], vote deserialize failed")
+		}
+		p.Votes = append(p.Votes, v)
+	}
+	return nil
+}
+
+type revokedVoteJSON struct {
+	TxID  string `json:"txid"`
+	Index uint16 `json:"index"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v RevokedVote) MarshalJSON() ([]byte, error) {
+	return json.Marshal(revokedVoteJSON{
+		TxID:  toReversedHash(v.TxID),
+		Index: v.Index,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *RevokedVote) UnmarshalJSON(data []byte) error {
+	var j revokedVoteJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	txID, err := decodeReversedHash("txid", j.TxID)
+	if err != nil {
+		return err
+	}
+	v.TxID = txID
+	v.Index = j.Index
+	return nil
+}
+
+type revokeVoteJSON struct {
+	Votes []RevokedVote `json:"votes"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p RevokeVote) MarshalJSON() ([]byte, error) {
+	return json.Marshal(revokeVoteJSON{Votes: p.Votes})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *RevokeVote) UnmarshalJSON(data []byte) error {
+	var j revokeVoteJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	p.Votes = j.Votes
+	return nil
+}
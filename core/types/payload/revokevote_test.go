@@ -0,0 +1,47 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package payload
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevokeVote_Deserialize(t *testing.T) {
+	revokeVotePayload1 := randomRevokeVotePayload()
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, revokeVotePayload1.Serialize(buf, RevokeVoteVersion))
+
+	revokeVotePayload2 := &RevokeVote{}
+	assert.NoError(t, revokeVotePayload2.Deserialize(buf, RevokeVoteVersion))
+
+	assert.Equal(t, revokeVotePayload1, revokeVotePayload2)
+
+	// Too many revoked votes.
+	revokeVotePayload1.Votes = make([]RevokedVote, MaxRevokedVotesCount+1)
+	buf = new(bytes.Buffer)
+	assert.NoError(t, revokeVotePayload1.Serialize(buf, RevokeVoteVersion))
+	assert.Error(t, revokeVotePayload2.Deserialize(buf, RevokeVoteVersion))
+}
+
+func randomRevokeVotePayload() *RevokeVote {
+	p := &RevokeVote{}
+	for i := 0; i < 5; i++ {
+		var txID common.Uint256
+		rand.Read(txID[:])
+		p.Votes = append(p.Votes, RevokedVote{
+			TxID:  txID,
+			Index: uint16(rand.Uint32()),
+		})
+	}
+	return p
+}
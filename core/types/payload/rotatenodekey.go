@@ -0,0 +1,184 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package payload
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+const RotateNodeKeyVersion byte = 0x00
+
+// RotateNodeKey replaces the node public key of a producer, the new node
+// public key takes effect from the next block even if the producer is an
+// arbiter of the current term.  The payload is signed by the owner key, and
+// by the new node key to prove the possession of it.  The last rotation
+// height is signed with the keys, it should be the height of the last node
+// key rotation of the producer, or zero if never rotated, so a payload can
+// not be replayed to rotate back to a previous node key.
+type RotateNodeKey struct {
+	OwnerPublicKey     []byte
+	NodePublicKey      []byte
+	NewNodePublicKey   []byte
+	LastRotationHeight uint32
+	NewNodeSignature   []byte
+	Signature          []byte
+}
+
+func (p *RotateNodeKey) Data(version byte) []byte {
+	buf := new(bytes.Buffer)
+	if err := p.Serialize(buf, version); err != nil {
+		return []byte{0}
+	}
+	return buf.Bytes()
+}
+
+func (p *RotateNodeKey) Serialize(w io.Writer, version byte) error {
+	if err := p.SerializeUnsigned(w, version); err != nil {
+		return err
+	}
+
+	if err := common.WriteVarBytes(w, p.NewNodeSignature); err != nil {
+		return errors.New("[RotateNodeKey], new node signature serialize failed")
+	}
+
+	if err := common.WriteVarBytes(w, p.Signature); err != nil {
+		return errors.New("[RotateNodeKey], signature serialize failed")
+	}
+
+	return nil
+}
+
+func (p *RotateNodeKey) SerializeUnsigned(w io.Writer, version byte) error {
+	if err := common.WriteVarBytes(w, p.OwnerPublicKey); err != nil {
+		return errors.New("[RotateNodeKey], owner public key serialize failed")
+	}
+
+	if err := common.WriteVarBytes(w, p.NodePublicKey); err != nil {
+		return errors.New("[RotateNodeKey], node public key serialize failed")
+	}
+
+	if err := common.WriteVarBytes(w, p.NewNodePublicKey); err != nil {
+		return errors.New("[RotateNodeKey], new node public key serialize failed")
+	}
+
+	if err := common.WriteUint32(w, p.LastRotationHeight); err != nil {
+		return errors.New("[RotateNodeKey], last rotation height serialize failed")
+	}
+
+	return nil
+}
+
+func (p *RotateNodeKey) Deserialize(r io.Reader, version byte) error {
+	if err := p.DeserializeUnsigned(r, version); err != nil {
+		return err
+	}
+
+	var err error
+	p.NewNodeSignature, err = common.ReadVarBytes(r, crypto.SignatureLength,
+		"new node signature")
+	if err != nil {
+		return errors.New("[RotateNodeKey], new node signature deserialize failed")
+	}
+
+	p.Signature, err = common.ReadVarBytes(r, crypto.SignatureLength,
+		"signature")
+	if err != nil {
+		return errors.New("[RotateNodeKey], signature deserialize failed")
+	}
+
+	return nil
+}
+
+func (p *RotateNodeKey) DeserializeUnsigned(r io.Reader, version byte) error {
+	var err error
+	p.OwnerPublicKey, err = common.ReadVarBytes(r, crypto.NegativeBigLength,
+		"owner public key")
+	if err != nil {
+		return errors.New("[RotateNodeKey], owner public key deserialize failed")
+	}
+
+	p.NodePublicKey, err = common.ReadVarBytes(r, crypto.NegativeBigLength,
+		"node public key")
+	if err != nil {
+		return errors.New("[RotateNodeKey], node public key deserialize failed")
+	}
+
+	p.NewNodePublicKey, err = common.ReadVarBytes(r, crypto.NegativeBigLength,
+		"new node public key")
+	if err != nil {
+		return errors.New("[RotateNodeKey], new node public key deserialize failed")
+	}
+
+	if p.LastRotationHeight, err = common.ReadUint32(r); err != nil {
+		return errors.New("[RotateNodeKey], last rotation height deserialize failed")
+	}
+
+	return nil
+}
+
+type rotateNodeKeyJSON struct {
+	OwnerPublicKey     string `json:"ownerpublickey"`
+	NodePublicKey      string `json:"nodepublickey"`
+	NewNodePublicKey   string `json:"newnodepublickey"`
+	LastRotationHeight uint32 `json:"lastrotationheight"`
+	NewNodeSignature   string `json:"newnodesignature"`
+	Signature          string `json:"signature"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p RotateNodeKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rotateNodeKeyJSON{
+		OwnerPublicKey:     common.BytesToHexString(p.OwnerPublicKey),
+		NodePublicKey:      common.BytesToHexString(p.NodePublicKey),
+		NewNodePublicKey:   common.BytesToHexString(p.NewNodePublicKey),
+		LastRotationHeight: p.LastRotationHeight,
+		NewNodeSignature:   common.BytesToHexString(p.NewNodeSignature),
+		Signature:          common.BytesToHexString(p.Signature),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *RotateNodeKey) UnmarshalJSON(data []byte) error {
+	var j rotateNodeKeyJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	ownerPublicKey, err := decodeHex("ownerpublickey", j.OwnerPublicKey)
+	if err != nil {
+		return err
+	}
+	nodePublicKey, err := decodeHex("nodepublickey", j.NodePublicKey)
+	if err != nil {
+		return err
+	}
+	newNodePublicKey, err := decodeHex("newnodepublickey",
+		j.NewNodePublicKey)
+	if err != nil {
+		return err
+	}
+	newNodeSignature, err := decodeHex("newnodesignature",
+		j.NewNodeSignature)
+	if err != nil {
+		return err
+	}
+	signature, err := decodeHex("signature", j.Signature)
+	if err != nil {
+		return err
+	}
+	p.OwnerPublicKey = ownerPublicKey
+	p.NodePublicKey = nodePublicKey
+	p.NewNodePublicKey = newNodePublicKey
+	p.LastRotationHeight = j.LastRotationHeight
+	p.NewNodeSignature = newNodeSignature
+	p.Signature = signature
+	return nil
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package payload
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotateNodeKey_Deserialize(t *testing.T) {
+	payload1 := randomRotateNodeKeyPayload()
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, payload1.Serialize(buf, RotateNodeKeyVersion))
+
+	payload2 := &RotateNodeKey{}
+	assert.NoError(t, payload2.Deserialize(buf, RotateNodeKeyVersion))
+
+	assert.Equal(t, payload1, payload2)
+}
+
+func randomRotateNodeKeyPayload() *RotateNodeKey {
+	return &RotateNodeKey{
+		OwnerPublicKey:     randomBytes(33),
+		NodePublicKey:      randomBytes(33),
+		NewNodePublicKey:   randomBytes(33),
+		LastRotationHeight: rand.Uint32(),
+		NewNodeSignature:   randomBytes(64),
+		Signature:          randomBytes(64),
+	}
+}
@@ -7,6 +7,7 @@ package payload
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 
 	"github.com/elastos/Elastos.ELA/common"
@@ -172,3 +173,60 @@ func (s *SidechainIllegalData) Hash() common.Uint256 {
 	}
 	return *s.hash
 }
+
+type sidechainIllegalDataJSON struct {
+	IllegalType         IllegalDataType `json:"illegaltype"`
+	Height              uint32          `json:"height"`
+	IllegalSigner       string          `json:"illegalsigner"`
+	Evidence            string          `json:"evidence"`
+	CompareEvidence     string          `json:"compareevidence"`
+	GenesisBlockAddress string          `json:"genesisblockaddress"`
+	Signs               []string        `json:"signs"`
+}
+
+// MarshalJSON implements the json.Marshaler interface, the evidences are
+// encoded as the hex strings of the data hashes.
+func (s SidechainIllegalData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sidechainIllegalDataJSON{
+		IllegalType:         s.IllegalType,
+		Height:              s.Height,
+		IllegalSigner:       common.BytesToHexString(s.IllegalSigner),
+		Evidence:            s.Evidence.DataHash.String(),
+		CompareEvidence:     s.CompareEvidence.DataHash.String(),
+		GenesisBlockAddress: s.GenesisBlockAddress,
+		Signs:               hexList(s.Signs),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *SidechainIllegalData) UnmarshalJSON(data []byte) error {
+	var j sidechainIllegalDataJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	signer, err := decodeHex("illegalsigner", j.IllegalSigner)
+	if err != nil {
+		return err
+	}
+	evidence, err := decodeHash("evidence", j.Evidence)
+	if err != nil {
+		return err
+	}
+	compareEvidence, err := decodeHash("compareevidence", j.CompareEvidence)
+	if err != nil {
+		return err
+	}
+	signs, err := decodeHexList("signs", j.Signs)
+	if err != nil {
+		return err
+	}
+	s.IllegalType = j.IllegalType
+	s.Height = j.Height
+	s.IllegalSigner = signer
+	s.Evidence.DataHash = evidence
+	s.CompareEvidence.DataHash = compareEvidence
+	s.GenesisBlockAddress = j.GenesisBlockAddress
+	s.Signs = signs
+	s.hash = nil
+	return nil
+}
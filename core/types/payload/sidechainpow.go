@@ -7,6 +7,7 @@ package payload
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"io"
 
@@ -80,3 +81,45 @@ func (a *SideChainPow) Deserialize(r io.Reader, version byte) error {
 	}
 	return nil
 }
+
+type sideChainPowJSON struct {
+	BlockHeight     uint32 `json:"blockheight"`
+	SideBlockHash   string `json:"sideblockhash"`
+	SideGenesisHash string `json:"sidegenesishash"`
+	Signature       string `json:"signature"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (a SideChainPow) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sideChainPowJSON{
+		BlockHeight:     a.BlockHeight,
+		SideBlockHash:   a.SideBlockHash.String(),
+		SideGenesisHash: a.SideGenesisHash.String(),
+		Signature:       common.BytesToHexString(a.Signature),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (a *SideChainPow) UnmarshalJSON(data []byte) error {
+	var j sideChainPowJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	sideBlockHash, err := decodeHash("sideblockhash", j.SideBlockHash)
+	if err != nil {
+		return err
+	}
+	sideGenesisHash, err := decodeHash("sidegenesishash", j.SideGenesisHash)
+	if err != nil {
+		return err
+	}
+	signature, err := decodeHex("signature", j.Signature)
+	if err != nil {
+		return err
+	}
+	a.BlockHeight = j.BlockHeight
+	a.SideBlockHash = sideBlockHash
+	a.SideGenesisHash = sideGenesisHash
+	a.Signature = signature
+	return nil
+}
@@ -7,6 +7,7 @@ package payload
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"io"
 
@@ -85,3 +86,30 @@ func (a *TransferCrossChainAsset) Deserialize(r io.Reader, version byte) error {
 
 	return nil
 }
+
+type transferCrossChainAssetJSON struct {
+	CrossChainAddresses []string         `json:"crosschainaddresses"`
+	OutputIndexes       []uint64         `json:"outputindexes"`
+	CrossChainAmounts   []common.Fixed64 `json:"crosschainamounts"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (a TransferCrossChainAsset) MarshalJSON() ([]byte, error) {
+	return json.Marshal(transferCrossChainAssetJSON{
+		CrossChainAddresses: a.CrossChainAddresses,
+		OutputIndexes:       a.OutputIndexes,
+		CrossChainAmounts:   a.CrossChainAmounts,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (a *TransferCrossChainAsset) UnmarshalJSON(data []byte) error {
+	var j transferCrossChainAssetJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	a.CrossChainAddresses = j.CrossChainAddresses
+	a.OutputIndexes = j.OutputIndexes
+	a.CrossChainAmounts = j.CrossChainAmounts
+	return nil
+}
@@ -7,6 +7,7 @@ package payload
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"io"
 
@@ -69,3 +70,35 @@ func (a *UnregisterCR) DeserializeUnsigned(r io.Reader, version byte) error {
 	}
 	return nil
 }
+
+type unregisterCRJSON struct {
+	CID       string `json:"cid"`
+	Signature string `json:"signature"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (a UnregisterCR) MarshalJSON() ([]byte, error) {
+	return json.Marshal(unregisterCRJSON{
+		CID:       toAddress(a.CID),
+		Signature: common.BytesToHexString(a.Signature),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (a *UnregisterCR) UnmarshalJSON(data []byte) error {
+	var j unregisterCRJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	cid, err := decodeAddress("cid", j.CID)
+	if err != nil {
+		return err
+	}
+	signature, err := decodeHex("signature", j.Signature)
+	if err != nil {
+		return err
+	}
+	a.CID = cid
+	a.Signature = signature
+	return nil
+}
@@ -7,6 +7,7 @@ package payload
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 
 	"github.com/elastos/Elastos.ELA/common"
@@ -50,3 +51,27 @@ func (u *UpdateVersion) Deserialize(r io.Reader, version byte) (err error) {
 
 	return nil
 }
+
+type updateVersionJSON struct {
+	StartHeight uint32 `json:"startheight"`
+	EndHeight   uint32 `json:"endheight"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (u UpdateVersion) MarshalJSON() ([]byte, error) {
+	return json.Marshal(updateVersionJSON{
+		StartHeight: u.StartHeight,
+		EndHeight:   u.EndHeight,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (u *UpdateVersion) UnmarshalJSON(data []byte) error {
+	var j updateVersionJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	u.StartHeight = j.StartHeight
+	u.EndHeight = j.EndHeight
+	return nil
+}
@@ -7,18 +7,123 @@ package payload
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"io"
 
 	"github.com/elastos/Elastos.ELA/common"
 )
 
-const WithdrawFromSideChainVersion byte = 0x00
+const (
+	WithdrawFromSideChainVersion byte = 0x00
+
+	// WithdrawFromSideChainProofVersion is the payload version carrying the
+	// merkle proofs of the side chain transactions.
+	WithdrawFromSideChainProofVersion byte = 0x01
+)
+
+const (
+	// SideChainHeaderSize is the size of a side chain block header without
+	// aux pow, which is hashed to the side chain block hash.
+	SideChainHeaderSize = 84
+
+	// MaxSideChainMerkleBranch is the max length of the merkle branch of a
+	// side chain transaction.
+	MaxSideChainMerkleBranch = 32
+)
 
 type WithdrawFromSideChain struct {
 	BlockHeight                uint32
 	GenesisBlockAddress        string
 	SideChainTransactionHashes []common.Uint256
+
+	// Proofs are the merkle proofs of SideChainTransactionHashes in order,
+	// available since WithdrawFromSideChainProofVersion.
+	Proofs []SideChainTxProof
+}
+
+// SideChainTxProof proves a side chain transaction is included in a side
+// chain block, the hash of which is recorded on the main chain by a
+// SideChainPow transaction.
+type SideChainTxProof struct {
+	// SideChainPowTxHash is the hash of the SideChainPow transaction
+	// recording the side chain block.
+	SideChainPowTxHash common.Uint256
+
+	// SideBlockHeader is the serialized side chain block header without aux
+	// pow.
+	SideBlockHeader []byte
+
+	// MerkleBranch and Index prove the side chain transaction against the
+	// merkle root of the side chain block header.
+	MerkleBranch []common.Uint256
+	Index        uint32
+}
+
+// SideBlockHash returns the hash of the side chain block header.
+func (p *SideChainTxProof) SideBlockHash() common.Uint256 {
+	return common.Uint256(common.Sha256D(p.SideBlockHeader))
+}
+
+// MerkleRoot returns the merkle root in the side chain block header, which
+// follows the version and the previous block hash.
+func (p *SideChainTxProof) MerkleRoot() (common.Uint256, error) {
+	if len(p.SideBlockHeader) != SideChainHeaderSize {
+		return common.Uint256{}, errors.New("invalid side block header size")
+	}
+	var root common.Uint256
+	copy(root[:], p.SideBlockHeader[4+common.UINT256SIZE:])
+	return root, nil
+}
+
+func (p *SideChainTxProof) Serialize(w io.Writer) error {
+	if err := p.SideChainPowTxHash.Serialize(w); err != nil {
+		return errors.New("[SideChainTxProof], SideChainPowTxHash serialize failed")
+	}
+	if err := common.WriteVarBytes(w, p.SideBlockHeader); err != nil {
+		return errors.New("[SideChainTxProof], SideBlockHeader serialize failed")
+	}
+	if err := common.WriteVarUint(w, uint64(len(p.MerkleBranch))); err != nil {
+		return errors.New("[SideChainTxProof], MerkleBranch count serialize failed")
+	}
+	for _, hash := range p.MerkleBranch {
+		if err := hash.Serialize(w); err != nil {
+			return errors.New("[SideChainTxProof], MerkleBranch serialize failed")
+		}
+	}
+	if err := common.WriteUint32(w, p.Index); err != nil {
+		return errors.New("[SideChainTxProof], Index serialize failed")
+	}
+	return nil
+}
+
+func (p *SideChainTxProof) Deserialize(r io.Reader) error {
+	if err := p.SideChainPowTxHash.Deserialize(r); err != nil {
+		return errors.New("[SideChainTxProof], SideChainPowTxHash deserialize failed")
+	}
+	header, err := common.ReadVarBytes(r, SideChainHeaderSize,
+		"side block header")
+	if err != nil {
+		return errors.New("[SideChainTxProof], SideBlockHeader deserialize failed")
+	}
+	p.SideBlockHeader = header
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return errors.New("[SideChainTxProof], MerkleBranch count deserialize failed")
+	}
+	if count > MaxSideChainMerkleBranch {
+		return errors.New("[SideChainTxProof], MerkleBranch too long")
+	}
+	p.MerkleBranch = make([]common.Uint256, count)
+	for i := range p.MerkleBranch {
+		if err := p.MerkleBranch[i].Deserialize(r); err != nil {
+			return errors.New("[SideChainTxProof], MerkleBranch deserialize failed")
+		}
+	}
+	if p.Index, err = common.ReadUint32(r); err != nil {
+		return errors.New("[SideChainTxProof], Index deserialize failed")
+	}
+	return nil
 }
 
 func (t *WithdrawFromSideChain) Data(version byte) []byte {
@@ -48,6 +153,17 @@ func (t *WithdrawFromSideChain) Serialize(w io.Writer, version byte) error {
 			return errors.New("[WithdrawFromSideChain], SideChainTransactionHashes serialize failed")
 		}
 	}
+
+	if version >= WithdrawFromSideChainProofVersion {
+		if err := common.WriteVarUint(w, uint64(len(t.Proofs))); err != nil {
+			return errors.New("[WithdrawFromSideChain], Proofs count serialize failed")
+		}
+		for _, proof := range t.Proofs {
+			if err := proof.Serialize(w); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -76,8 +192,101 @@ func (t *WithdrawFromSideChain) Deserialize(r io.Reader, version byte) error {
 		t.SideChainTransactionHashes = append(t.SideChainTransactionHashes, hash)
 	}
 
+	if version >= WithdrawFromSideChainProofVersion {
+		count, err := common.ReadVarUint(r, 0)
+		if err != nil {
+			return errors.New("[WithdrawFromSideChain], Proofs count deserialize failed")
+		}
+		if count != uint64(len(t.SideChainTransactionHashes)) {
+			return errors.New("[WithdrawFromSideChain], Proofs count mismatch")
+		}
+		t.Proofs = make([]SideChainTxProof, count)
+		for i := range t.Proofs {
+			if err := t.Proofs[i].Deserialize(r); err != nil {
+				return err
+			}
+		}
+	}
+
 	t.BlockHeight = height
 	t.GenesisBlockAddress = address
 
 	return nil
 }
+
+type withdrawFromSideChainJSON struct {
+	BlockHeight                uint32             `json:"blockheight"`
+	GenesisBlockAddress        string             `json:"genesisblockaddress"`
+	SideChainTransactionHashes []string           `json:"sidechaintransactionhashes"`
+	Proofs                     []SideChainTxProof `json:"proofs,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (t WithdrawFromSideChain) MarshalJSON() ([]byte, error) {
+	return json.Marshal(withdrawFromSideChainJSON{
+		BlockHeight:                t.BlockHeight,
+		GenesisBlockAddress:        t.GenesisBlockAddress,
+		SideChainTransactionHashes: hashList(t.SideChainTransactionHashes),
+		Proofs:                     t.Proofs,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (t *WithdrawFromSideChain) UnmarshalJSON(data []byte) error {
+	var j withdrawFromSideChainJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	hashes, err := decodeHashList("sidechaintransactionhashes",
+		j.SideChainTransactionHashes)
+	if err != nil {
+		return err
+	}
+	t.BlockHeight = j.BlockHeight
+	t.GenesisBlockAddress = j.GenesisBlockAddress
+	t.SideChainTransactionHashes = hashes
+	t.Proofs = j.Proofs
+	return nil
+}
+
+type sideChainTxProofJSON struct {
+	SideChainPowTxHash string   `json:"sidechainpowtxhash"`
+	SideBlockHeader    string   `json:"sideblockheader"`
+	MerkleBranch       []string `json:"merklebranch"`
+	Index              uint32   `json:"index"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p SideChainTxProof) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sideChainTxProofJSON{
+		SideChainPowTxHash: p.SideChainPowTxHash.String(),
+		SideBlockHeader:    common.BytesToHexString(p.SideBlockHeader),
+		MerkleBranch:       hashList(p.MerkleBranch),
+		Index:              p.Index,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *SideChainTxProof) UnmarshalJSON(data []byte) error {
+	var j sideChainTxProofJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	powTxHash, err := decodeHash("sidechainpowtxhash", j.SideChainPowTxHash)
+	if err != nil {
+		return err
+	}
+	header, err := decodeHex("sideblockheader", j.SideBlockHeader)
+	if err != nil {
+		return err
+	}
+	branch, err := decodeHashList("merklebranch", j.MerkleBranch)
+	if err != nil {
+		return err
+	}
+	p.SideChainPowTxHash = powTxHash
+	p.SideBlockHeader = header
+	p.MerkleBranch = branch
+	p.Index = j.Index
+	return nil
+}
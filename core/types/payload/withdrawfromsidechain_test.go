@@ -0,0 +1,60 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package payload
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithdrawFromSideChain_Deserialize(t *testing.T) {
+	p1 := &WithdrawFromSideChain{
+		BlockHeight:         100,
+		GenesisBlockAddress: randomString(),
+		SideChainTransactionHashes: []common.Uint256{
+			*randomUint256(),
+			*randomUint256(),
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, p1.Serialize(buf, WithdrawFromSideChainVersion))
+	p2 := &WithdrawFromSideChain{}
+	assert.NoError(t, p2.Deserialize(buf, WithdrawFromSideChainVersion))
+	assert.Equal(t, p1, p2)
+
+	for range p1.SideChainTransactionHashes {
+		p1.Proofs = append(p1.Proofs, SideChainTxProof{
+			SideChainPowTxHash: *randomUint256(),
+			SideBlockHeader:    randomBytes(SideChainHeaderSize),
+			MerkleBranch: []common.Uint256{
+				*randomUint256(),
+				*randomUint256(),
+			},
+			Index: 2,
+		})
+	}
+	buf = new(bytes.Buffer)
+	assert.NoError(t, p1.Serialize(buf, WithdrawFromSideChainProofVersion))
+	p2 = &WithdrawFromSideChain{}
+	assert.NoError(t, p2.Deserialize(buf, WithdrawFromSideChainProofVersion))
+	assert.Equal(t, p1, p2)
+
+	root, err := p2.Proofs[0].MerkleRoot()
+	assert.NoError(t, err)
+	assert.Equal(t, p1.Proofs[0].SideBlockHeader[36:68], root.Bytes())
+
+	// proofs count should match the side chain transactions
+	p1.Proofs = p1.Proofs[:1]
+	buf = new(bytes.Buffer)
+	assert.NoError(t, p1.Serialize(buf, WithdrawFromSideChainProofVersion))
+	p2 = &WithdrawFromSideChain{}
+	assert.Error(t, p2.Deserialize(buf, WithdrawFromSideChainProofVersion))
+}
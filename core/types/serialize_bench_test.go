@@ -0,0 +1,131 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract/program"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newVoteTransaction returns a transaction voting the max number of
+// producers, which is the most common large transaction in blocks.
+func newVoteTransaction() *Transaction {
+	votes := make([]outputpayload.CandidateVotes, 0,
+		outputpayload.MaxVoteProducersPerTransaction)
+	for i := 0; i < outputpayload.MaxVoteProducersPerTransaction; i++ {
+		votes = append(votes, outputpayload.CandidateVotes{
+			Candidate: randomPublicKey(),
+			Votes:     common.Fixed64(i + 1),
+		})
+	}
+	return &Transaction{
+		Version: TxVersion09,
+		TxType:  TransferAsset,
+		Payload: &payload.TransferAsset{},
+		Attributes: []*Attribute{{
+			Usage: Nonce,
+			Data:  []byte("vote"),
+		}},
+		Inputs: []*Input{
+			{Previous: OutPoint{TxID: *randomUint256(), Index: 1}},
+			{Previous: OutPoint{TxID: *randomUint256(), Index: 0}},
+		},
+		Outputs: []*Output{
+			{
+				AssetID:     *randomUint256(),
+				Value:       100,
+				ProgramHash: *randomUint168(),
+				Type:        OTVote,
+				Payload: &outputpayload.VoteOutput{
+					Version: outputpayload.VoteProducerAndCRVersion,
+					Contents: []outputpayload.VoteContent{{
+						VoteType:       outputpayload.Delegate,
+						CandidateVotes: votes,
+					}},
+				},
+			},
+			{
+				AssetID:     *randomUint256(),
+				Value:       10,
+				ProgramHash: *randomUint168(),
+				Type:        OTNone,
+				Payload:     &outputpayload.DefaultOutput{},
+			},
+		},
+		Programs: []*program.Program{{
+			Code:      randomPublicKey(),
+			Parameter: randomSignature(),
+		}},
+	}
+}
+
+func TestTransaction_SizeAndHash(t *testing.T) {
+	tx := newVoteTransaction()
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, tx.Serialize(buf))
+	assert.Equal(t, buf.Len(), tx.GetSize())
+
+	unsigned := new(bytes.Buffer)
+	assert.NoError(t, tx.SerializeUnsigned(unsigned))
+	assert.Equal(t, common.Uint256(common.Sha256D(unsigned.Bytes())),
+		tx.Hash())
+
+	var tx2 Transaction
+	assert.NoError(t, tx2.Deserialize(bytes.NewReader(buf.Bytes())))
+	assert.Equal(t, tx.Hash(), tx2.Hash())
+	buf2 := new(bytes.Buffer)
+	assert.NoError(t, tx2.Serialize(buf2))
+	assert.Equal(t, buf.Bytes(), buf2.Bytes())
+}
+
+func BenchmarkTransaction_Serialize(b *testing.B) {
+	tx := newVoteTransaction()
+	buf := new(bytes.Buffer)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		tx.Serialize(buf)
+	}
+}
+
+func BenchmarkTransaction_Deserialize(b *testing.B) {
+	buf := new(bytes.Buffer)
+	newVoteTransaction().Serialize(buf)
+	data := buf.Bytes()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var tx Transaction
+		tx.Deserialize(bytes.NewReader(data))
+	}
+}
+
+func BenchmarkTransaction_Hash(b *testing.B) {
+	tx := newVoteTransaction()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tx.hash()
+	}
+}
+
+func BenchmarkTransaction_GetSize(b *testing.B) {
+	tx := newVoteTransaction()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tx.GetSize()
+	}
+}
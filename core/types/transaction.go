@@ -6,7 +6,6 @@
 package types
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -18,6 +17,10 @@ import (
 
 const (
 	InvalidTransactionSize = -1
+
+	// maxPreallocItems is the max number of attributes, inputs or outputs
+	// preallocated when deserializing a transaction.
+	maxPreallocItems = 1024
 )
 
 // TxType represents different transaction types with different payload format.
@@ -53,6 +56,13 @@ const (
 	UnregisterCR        TxType = 0x22
 	UpdateCR            TxType = 0x23
 	ReturnCRDepositCoin TxType = 0x24
+
+	CustomIDProposal TxType = 0x25
+	RevokeVote       TxType = 0x26
+	CRCRewardAddress TxType = 0x27
+	ProducerAppeal   TxType = 0x28
+	CRNicknameCommit TxType = 0x29
+	RotateNodeKey    TxType = 0x2a
 )
 
 func (self TxType) Name() string {
@@ -105,6 +115,18 @@ func (self TxType) Name() string {
 		return "UpdateCR"
 	case ReturnCRDepositCoin:
 		return "ReturnCRDepositCoin"
+	case CustomIDProposal:
+		return "CustomIDProposal"
+	case RevokeVote:
+		return "RevokeVote"
+	case CRCRewardAddress:
+		return "CRCRewardAddress"
+	case ProducerAppeal:
+		return "ProducerAppeal"
+	case CRNicknameCommit:
+		return "CRNicknameCommit"
+	case RotateNodeKey:
+		return "RotateNodeKey"
 	default:
 		return "Unknown"
 	}
@@ -169,16 +191,16 @@ func (tx *Transaction) Serialize(w io.Writer) error {
 func (tx *Transaction) SerializeUnsigned(w io.Writer) error {
 	// Version
 	if tx.Version >= TxVersion09 {
-		if _, err := w.Write([]byte{byte(tx.Version)}); err != nil {
+		if err := common.WriteUint8(w, byte(tx.Version)); err != nil {
 			return err
 		}
 	}
 	// TxType
-	if _, err := w.Write([]byte{byte(tx.TxType)}); err != nil {
+	if err := common.WriteUint8(w, byte(tx.TxType)); err != nil {
 		return err
 	}
 	// PayloadVersion
-	if _, err := w.Write([]byte{tx.PayloadVersion}); err != nil {
+	if err := common.WriteUint8(w, tx.PayloadVersion); err != nil {
 		return err
 	}
 	// Payload
@@ -245,28 +267,27 @@ func (tx *Transaction) Deserialize(r io.Reader) error {
 }
 
 func (tx *Transaction) DeserializeUnsigned(r io.Reader) error {
-	flagByte, err := common.ReadBytes(r, 1)
+	flagByte, err := common.ReadUint8(r)
 	if err != nil {
 		return err
 	}
 
-	if TransactionVersion(flagByte[0]) >= TxVersion09 {
-		tx.Version = TransactionVersion(flagByte[0])
-		txType, err := common.ReadBytes(r, 1)
+	if TransactionVersion(flagByte) >= TxVersion09 {
+		tx.Version = TransactionVersion(flagByte)
+		txType, err := common.ReadUint8(r)
 		if err != nil {
 			return err
 		}
-		tx.TxType = TxType(txType[0])
+		tx.TxType = TxType(txType)
 	} else {
 		tx.Version = TxVersionDefault
-		tx.TxType = TxType(flagByte[0])
+		tx.TxType = TxType(flagByte)
 	}
 
-	payloadVersion, err := common.ReadBytes(r, 1)
+	tx.PayloadVersion, err = common.ReadUint8(r)
 	if err != nil {
 		return err
 	}
-	tx.PayloadVersion = payloadVersion[0]
 
 	tx.Payload, err = GetPayload(tx.TxType)
 	if err != nil {
@@ -282,36 +303,55 @@ func (tx *Transaction) DeserializeUnsigned(r io.Reader) error {
 	if err != nil {
 		return err
 	}
-	for i := uint64(0); i < count; i++ {
-		var attr Attribute
-		if err := attr.Deserialize(r); err != nil {
-			return err
+	if count > 0 {
+		// allocate the attributes in one slice instead of one by one.
+		attrs := make([]Attribute, boundedCount(count))
+		for i := uint64(0); i < count; i++ {
+			attr := &Attribute{}
+			if i < uint64(len(attrs)) {
+				attr = &attrs[i]
+			}
+			if err := attr.Deserialize(r); err != nil {
+				return err
+			}
+			tx.Attributes = append(tx.Attributes, attr)
 		}
-		tx.Attributes = append(tx.Attributes, &attr)
 	}
 	// inputs
 	count, err = common.ReadVarUint(r, 0)
 	if err != nil {
 		return err
 	}
-	for i := uint64(0); i < count; i++ {
-		var input Input
-		if err := input.Deserialize(r); err != nil {
-			return err
+	if count > 0 {
+		inputs := make([]Input, boundedCount(count))
+		for i := uint64(0); i < count; i++ {
+			input := &Input{}
+			if i < uint64(len(inputs)) {
+				input = &inputs[i]
+			}
+			if err := input.Deserialize(r); err != nil {
+				return err
+			}
+			tx.Inputs = append(tx.Inputs, input)
 		}
-		tx.Inputs = append(tx.Inputs, &input)
 	}
 	// outputs
 	count, err = common.ReadVarUint(r, 0)
 	if err != nil {
 		return err
 	}
-	for i := uint64(0); i < count; i++ {
-		var output Output
-		if err := output.Deserialize(r, tx.Version); err != nil {
-			return err
+	if count > 0 {
+		outputs := make([]Output, boundedCount(count))
+		for i := uint64(0); i < count; i++ {
+			output := &Output{}
+			if i < uint64(len(outputs)) {
+				output = &outputs[i]
+			}
+			if err := output.Deserialize(r, tx.Version); err != nil {
+				return err
+			}
+			tx.Outputs = append(tx.Outputs, output)
 		}
-		tx.Outputs = append(tx.Outputs, &output)
 	}
 
 	tx.LockTime, err = common.ReadUint32(r)
@@ -322,18 +362,29 @@ func (tx *Transaction) DeserializeUnsigned(r io.Reader) error {
 	return nil
 }
 
+// boundedCount limits the count of items read from a message before
+// preallocating them, so a malformed count will not cause a huge allocation.
+func boundedCount(count uint64) int {
+	if count > maxPreallocItems {
+		return maxPreallocItems
+	}
+	return int(count)
+}
+
 func (tx *Transaction) GetSize() int {
-	buf := new(bytes.Buffer)
-	if err := tx.Serialize(buf); err != nil {
+	var w common.SizeWriter
+	if err := tx.Serialize(&w); err != nil {
 		return InvalidTransactionSize
 	}
-	return buf.Len()
+	return w.Size
 }
 
 func (tx *Transaction) hash() common.Uint256 {
-	buf := new(bytes.Buffer)
+	buf := common.BorrowBuffer()
 	tx.SerializeUnsigned(buf)
-	return common.Uint256(common.Sha256D(buf.Bytes()))
+	hash := common.Uint256(common.Sha256D(buf.Bytes()))
+	common.ReturnBuffer(buf)
+	return hash
 }
 
 func (tx *Transaction) Hash() common.Uint256 {
@@ -344,6 +395,30 @@ func (tx *Transaction) Hash() common.Uint256 {
 	return *tx.txHash
 }
 
+func (tx *Transaction) IsCustomIDProposalTx() bool {
+	return tx.TxType == CustomIDProposal
+}
+
+func (tx *Transaction) IsCRCRewardAddressTx() bool {
+	return tx.TxType == CRCRewardAddress
+}
+
+func (tx *Transaction) IsProducerAppealTx() bool {
+	return tx.TxType == ProducerAppeal
+}
+
+func (tx *Transaction) IsCRNicknameCommitTx() bool {
+	return tx.TxType == CRNicknameCommit
+}
+
+func (tx *Transaction) IsRotateNodeKeyTx() bool {
+	return tx.TxType == RotateNodeKey
+}
+
+func (tx *Transaction) IsRevokeVoteTx() bool {
+	return tx.TxType == RevokeVote
+}
+
 func (tx *Transaction) IsUpdateCRTx() bool {
 	return tx.TxType == UpdateCR
 }
@@ -506,6 +581,18 @@ func GetPayload(txType TxType) (Payload, error) {
 		p = new(payload.UnregisterCR)
 	case ReturnCRDepositCoin:
 		p = new(payload.ReturnDepositCoin)
+	case CustomIDProposal:
+		p = new(payload.CustomIDProposal)
+	case RevokeVote:
+		p = new(payload.RevokeVote)
+	case CRCRewardAddress:
+		p = new(payload.CRCRewardAddress)
+	case ProducerAppeal:
+		p = new(payload.ProducerAppeal)
+	case CRNicknameCommit:
+		p = new(payload.CRNicknameCommit)
+	case RotateNodeKey:
+		p = new(payload.RotateNodeKey)
 	default:
 		return nil, errors.New("[Transaction], invalid transaction type.")
 	}
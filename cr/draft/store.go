@@ -0,0 +1,91 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package draft
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/elastos/Elastos.ELA/blockchain"
+	"github.com/elastos/Elastos.ELA/common"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// DefaultMaxSize is the default max size of a proposal draft in bytes.
+const DefaultMaxSize = 1024 * 1024
+
+// draftPrefix is the key prefix of draft data in database.
+var draftPrefix = []byte{0x01}
+
+var (
+	// ErrNotFound indicates no draft data of the hash in the store.
+	ErrNotFound = errors.New("draft data not found")
+
+	// ErrHashMismatch indicates the draft data does not match the draft
+	// hash.
+	ErrHashMismatch = errors.New("draft data does not match draft hash")
+)
+
+// Store stores the off-chain content of proposals by the draft hash, so that
+// committee members can retrieve proposal content from any node.
+type Store struct {
+	db      *blockchain.LevelDB
+	maxSize uint32
+}
+
+// Hash returns the draft hash of the draft data.
+func Hash(data []byte) common.Uint256 {
+	return common.Sha256D(data)
+}
+
+func draftKey(hash common.Uint256) []byte {
+	return append(append([]byte{}, draftPrefix...), hash[:]...)
+}
+
+// Put verifies the draft data matches the draft hash and stores it, storing
+// the same draft again is a no-op.
+func (s *Store) Put(hash common.Uint256, data []byte) error {
+	if len(data) == 0 {
+		return errors.New("empty draft data")
+	}
+	if uint32(len(data)) > s.maxSize {
+		return fmt.Errorf("draft data size %d exceeds the max size %d",
+			len(data), s.maxSize)
+	}
+	if !Hash(data).IsEqual(hash) {
+		return ErrHashMismatch
+	}
+	return s.db.Put(draftKey(hash), data)
+}
+
+// Get returns the draft data of the draft hash, ErrNotFound will be returned
+// if it has not been stored.
+func (s *Store) Get(hash common.Uint256) ([]byte, error) {
+	data, err := s.db.Get(draftKey(hash))
+	if err == leveldb.ErrNotFound {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// New opens or creates a draft data store under the path, maxSize is the max
+// size of a draft in bytes, DefaultMaxSize will be used if it's 0.
+func New(path string, maxSize uint32) (*Store, error) {
+	db, err := blockchain.NewLevelDB(path)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize == 0 {
+		maxSize = DefaultMaxSize
+	}
+	return &Store{db: db, maxSize: maxSize}, nil
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package draft
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/utils/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(test.DataPath, "draft")
+	os.RemoveAll(path)
+	defer os.RemoveAll(path)
+
+	store, err := New(path, 16)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	data := []byte("proposal draft")
+	hash := Hash(data)
+
+	_, err = store.Get(hash)
+	assert.Equal(t, ErrNotFound, err)
+
+	assert.Equal(t, ErrHashMismatch, store.Put(common.Uint256{}, data))
+	assert.Error(t, store.Put(Hash(nil), nil))
+	large := []byte("proposal draft too large")
+	assert.Error(t, store.Put(Hash(large), large))
+
+	assert.NoError(t, store.Put(hash, data))
+	assert.NoError(t, store.Put(hash, data))
+	assert.NoError(t, store.Close())
+
+	// drafts are persisted.
+	store, err = New(path, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer store.Close()
+	stored, err := store.Get(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, data, stored)
+}
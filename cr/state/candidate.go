@@ -150,3 +150,8 @@ func (c *Candidate) Penalty() common.Fixed64 {
 func (c *Candidate) DepositAmount() common.Fixed64 {
 	return c.depositAmount
 }
+
+// DepositHash returns the deposit program hash of the CR.
+func (c *Candidate) DepositHash() common.Uint168 {
+	return c.depositHash
+}
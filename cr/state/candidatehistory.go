@@ -0,0 +1,89 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package state
+
+import (
+	"io"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+// CandidateInfoChange records the candidate information changed by an update
+// CR transaction.
+type CandidateInfoChange struct {
+	OldNickName string
+	NewNickName string
+	OldUrl      string
+	NewUrl      string
+	OldLocation uint64
+	NewLocation uint64
+	Height      uint32
+	TxHash      common.Uint256
+}
+
+func (c *CandidateInfoChange) Serialize(w io.Writer) (err error) {
+	if err = common.WriteVarString(w, c.OldNickName); err != nil {
+		return
+	}
+
+	if err = common.WriteVarString(w, c.NewNickName); err != nil {
+		return
+	}
+
+	if err = common.WriteVarString(w, c.OldUrl); err != nil {
+		return
+	}
+
+	if err = common.WriteVarString(w, c.NewUrl); err != nil {
+		return
+	}
+
+	if err = common.WriteUint64(w, c.OldLocation); err != nil {
+		return
+	}
+
+	if err = common.WriteUint64(w, c.NewLocation); err != nil {
+		return
+	}
+
+	if err = common.WriteUint32(w, c.Height); err != nil {
+		return
+	}
+
+	return c.TxHash.Serialize(w)
+}
+
+func (c *CandidateInfoChange) Deserialize(r io.Reader) (err error) {
+	if c.OldNickName, err = common.ReadVarString(r); err != nil {
+		return
+	}
+
+	if c.NewNickName, err = common.ReadVarString(r); err != nil {
+		return
+	}
+
+	if c.OldUrl, err = common.ReadVarString(r); err != nil {
+		return
+	}
+
+	if c.NewUrl, err = common.ReadVarString(r); err != nil {
+		return
+	}
+
+	if c.OldLocation, err = common.ReadUint64(r); err != nil {
+		return
+	}
+
+	if c.NewLocation, err = common.ReadUint64(r); err != nil {
+		return
+	}
+
+	if c.Height, err = common.ReadUint32(r); err != nil {
+		return
+	}
+
+	return c.TxHash.Deserialize(r)
+}
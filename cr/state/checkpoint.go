@@ -51,6 +51,7 @@ func (c *Checkpoint) OnRollbackTo(height uint32) error {
 		}
 		c.initFromCommittee(committee)
 		c.committee.Recover(c)
+		c.committee.state.removeCandidateHistories(height)
 		return nil
 	}
 	return c.committee.RollbackTo(height)
@@ -71,6 +72,9 @@ func (c *Checkpoint) Snapshot() checkpoint.ICheckPoint {
 		c.LogError(err)
 		return nil
 	}
+	height, hash := c.committee.GetStateKeyFrameHash()
+	log.Infof("[Checkpoint] state key frame hash at height %d: %s",
+		height, hash)
 	return result
 }
 
@@ -18,6 +18,31 @@ import (
 	"github.com/elastos/Elastos.ELA/core/types/payload"
 )
 
+// CommitteeSession describes a term of CR committee.
+type CommitteeSession struct {
+	// Index is the sequence number of the session, starts from 1.
+	Index uint32
+
+	// StartHeight is the height at which the committee was elected.
+	StartHeight uint32
+
+	// EndHeight is the height at which the next committee will be elected.
+	EndHeight uint32
+
+	// Members are the committee members of the session.
+	Members []*CRMember
+}
+
+// RegistrationWindow describes the heights range [StartHeight, EndHeight) in
+// which RegisterCR transactions are accepted in the current election, and the
+// range of the election after it.
+type RegistrationWindow struct {
+	StartHeight     uint32
+	EndHeight       uint32
+	NextStartHeight uint32
+	NextEndHeight   uint32
+}
+
 type Committee struct {
 	KeyFrame
 	mtx    sync.RWMutex
@@ -25,6 +50,9 @@ type Committee struct {
 	params *config.Params
 
 	getCheckpoint func(height uint32) *Checkpoint
+
+	stateHash       common.Uint256
+	stateHashHeight uint32
 }
 
 func (c *Committee) GetState() *State {
@@ -53,6 +81,31 @@ func (c *Committee) IsInVotingPeriod(height uint32) bool {
 	return c.isInVotingPeriod(height)
 }
 
+// IsInRegistrationPeriod returns if RegisterCR transactions are accepted at
+// the given height.
+func (c *Committee) IsInRegistrationPeriod(height uint32) bool {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.isInRegistrationPeriod(height)
+}
+
+// GetRegistrationWindow returns the registration window of current election
+// and the next one.
+func (c *Committee) GetRegistrationWindow() *RegistrationWindow {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	start, end := c.getVotingPeriod()
+	nextEnd := end + c.params.CRDutyPeriod
+	nextStart := nextEnd - c.params.CRVotingPeriod
+	return &RegistrationWindow{
+		StartHeight:     start,
+		EndHeight:       c.getRegistrationEndHeight(start, end),
+		NextStartHeight: nextStart,
+		NextEndHeight:   c.getRegistrationEndHeight(nextStart, nextEnd),
+	}
+}
+
 func (c *Committee) GetMembersCIDs() []common.Uint168 {
 	c.mtx.RLock()
 	defer c.mtx.RUnlock()
@@ -83,9 +136,44 @@ func (c *Committee) GetMembersCodes() [][]byte {
 	return result
 }
 
+// GetCurrentSession returns the current session of CR committee, it will
+// return nil if the first committee has not been elected.
+func (c *Committee) GetCurrentSession() *CommitteeSession {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	if c.LastCommitteeHeight < c.params.CRCommitteeStartHeight {
+		return nil
+	}
+	return &CommitteeSession{
+		Index: (c.LastCommitteeHeight-c.params.CRCommitteeStartHeight)/
+			c.params.CRDutyPeriod + 1,
+		StartHeight: c.LastCommitteeHeight,
+		EndHeight:   c.getNextElectionHeight(),
+		Members:     copyCRMembers(c.Members),
+	}
+}
+
+// GetNextElectionHeight returns the height at which the next committee will
+// be elected.
+func (c *Committee) GetNextElectionHeight() uint32 {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.getNextElectionHeight()
+}
+
+// GetStateKeyFrameHash returns the hash of CR state key frame and the height
+// at which it is calculated.
+func (c *Committee) GetStateKeyFrameHash() (uint32, common.Uint256) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.stateHashHeight, c.stateHash
+}
+
 func (c *Committee) ProcessBlock(block *types.Block, confirm *payload.Confirm) {
 	c.mtx.Lock()
 	defer c.mtx.Unlock()
+	defer c.updateStateHash(block.Height)
 	isVoting := c.isInVotingPeriod(block.Height)
 
 	if isVoting {
@@ -95,6 +183,7 @@ func (c *Committee) ProcessBlock(block *types.Block, confirm *payload.Confirm) {
 	}
 
 	if c.shouldChange(block) {
+		outgoing := c.Members
 		committeeDIDs, err := c.changeCommitteeMembers(block.Height)
 		if err != nil {
 			log.Error("[ProcessBlock] change committee members error: ", err)
@@ -104,7 +193,8 @@ func (c *Committee) ProcessBlock(block *types.Block, confirm *payload.Confirm) {
 		checkpoint := Checkpoint{
 			KeyFrame: c.KeyFrame,
 		}
-		checkpoint.StateKeyFrame = *c.state.FinishVoting(committeeDIDs)
+		checkpoint.StateKeyFrame = *c.state.FinishVoting(committeeDIDs,
+			outgoing, block.Height)
 	}
 }
 
@@ -112,6 +202,7 @@ func (c *Committee) RollbackTo(height uint32) error {
 	c.mtx.Lock()
 	defer c.mtx.Unlock()
 	lastCommitHeight := c.LastCommitteeHeight
+	defer c.updateStateHash(height)
 
 	if height >= lastCommitHeight {
 		if height > c.state.history.Height() {
@@ -128,6 +219,8 @@ func (c *Committee) RollbackTo(height uint32) error {
 		}
 
 		c.state.StateKeyFrame = point.StateKeyFrame
+		c.state.rebuildFoldedNicknames()
+		c.state.removeCandidateHistories(height)
 		c.KeyFrame = point.KeyFrame
 	}
 	return nil
@@ -137,14 +230,35 @@ func (c *Committee) Recover(checkpoint *Checkpoint) {
 	c.mtx.Lock()
 	defer c.mtx.Unlock()
 	c.state.StateKeyFrame = checkpoint.StateKeyFrame
+	c.state.rebuildFoldedNicknames()
 	c.KeyFrame = checkpoint.KeyFrame
+	c.updateStateHash(checkpoint.GetHeight())
+}
+
+// updateStateHash calculates the hash of CR state key frame at the given
+// height, should be called with committee mutex held.
+func (c *Committee) updateStateHash(height uint32) {
+	c.state.mtx.RLock()
+	hash, err := c.state.StateKeyFrame.Hash()
+	c.state.mtx.RUnlock()
+	if err != nil {
+		log.Warn("[updateStateHash] calculate state hash error: ", err)
+		return
+	}
+	c.stateHash = hash
+	c.stateHashHeight = height
 }
 
 func (c *Committee) shouldChange(block *types.Block) bool {
 	//todo judge by change cr committee tx later
-	return block.Height >= c.params.CRCommitteeStartHeight &&
-		(c.LastCommitteeHeight == 0 || block.Height >=
-			c.LastCommitteeHeight+c.params.CRDutyPeriod)
+	return block.Height >= c.getNextElectionHeight()
+}
+
+func (c *Committee) getNextElectionHeight() uint32 {
+	if c.LastCommitteeHeight < c.params.CRCommitteeStartHeight {
+		return c.params.CRCommitteeStartHeight
+	}
+	return c.LastCommitteeHeight + c.params.CRDutyPeriod
 }
 
 func (c *Committee) isInVotingPeriod(height uint32) bool {
@@ -162,9 +276,38 @@ func (c *Committee) isInVotingPeriod(height uint32) bool {
 	}
 }
 
+// getVotingPeriod returns the heights range [start, end) of current voting
+// period.
+func (c *Committee) getVotingPeriod() (uint32, uint32) {
+	if c.LastCommitteeHeight < c.params.CRCommitteeStartHeight {
+		return c.params.CRVotingStartHeight, c.params.CRCommitteeStartHeight
+	}
+	end := c.LastCommitteeHeight + c.params.CRDutyPeriod
+	return end - c.params.CRVotingPeriod, end
+}
+
+// getRegistrationEndHeight returns the height at which the registration of
+// the voting period [start, end) closes, registration is open during the
+// whole voting period if CRNominationPeriod is not set.
+func (c *Committee) getRegistrationEndHeight(start, end uint32) uint32 {
+	if c.params.CRNominationPeriod == 0 ||
+		start+c.params.CRNominationPeriod >= end {
+		return end
+	}
+	return start + c.params.CRNominationPeriod
+}
+
+func (c *Committee) isInRegistrationPeriod(height uint32) bool {
+	if !c.isInVotingPeriod(height) {
+		return false
+	}
+	start, end := c.getVotingPeriod()
+	return height < c.getRegistrationEndHeight(start, end)
+}
+
 func (c *Committee) changeCommitteeMembers(height uint32) (
 	[]common.Uint168, error) {
-	candidates, err := c.getActiveCRCandidatesDesc()
+	candidates, err := c.getActiveCRCandidatesDesc(height)
 	if err != nil {
 		return nil, err
 	}
@@ -190,19 +333,29 @@ func (c *Committee) generateMember(candidate *Candidate) *CRMember {
 	}
 }
 
-func (c *Committee) getActiveCRCandidatesDesc() ([]*Candidate, error) {
+func (c *Committee) getActiveCRCandidatesDesc(height uint32) ([]*Candidate,
+	error) {
 	candidates := c.state.GetCandidates(Active)
 	if uint32(len(candidates)) < c.params.CRMemberCount {
 		return nil, errors.New("candidates count less than required count")
 	}
 
+	// Candidates are sorted by votes descending, candidates with same votes
+	// are sorted by register height ascending since CRCandidateTieBreakHeight
+	// and then by code hash, so that all nodes will get the same committee
+	// members.
+	tieBreak := height >= c.params.CRCandidateTieBreakHeight
 	sort.Slice(candidates, func(i, j int) bool {
-		if candidates[i].votes == candidates[j].votes {
-			iCRInfo := candidates[i].Info()
-			jCRInfo := candidates[j].Info()
-			return iCRInfo.GetCodeHash().Compare(jCRInfo.GetCodeHash()) < 0
+		if candidates[i].votes != candidates[j].votes {
+			return candidates[i].votes > candidates[j].votes
+		}
+		if tieBreak &&
+			candidates[i].registerHeight != candidates[j].registerHeight {
+			return candidates[i].registerHeight < candidates[j].registerHeight
 		}
-		return candidates[i].votes > candidates[j].votes
+		iCRInfo := candidates[i].Info()
+		jCRInfo := candidates[j].Info()
+		return iCRInfo.GetCodeHash().Compare(jCRInfo.GetCodeHash()) < 0
 	})
 	return candidates, nil
 }
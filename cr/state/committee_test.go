@@ -177,6 +177,56 @@ func TestCommittee_IsInVotingPeriod(t *testing.T) {
 			config.DefaultParams.CRDutyPeriod*2))
 }
 
+func TestCommittee_RegistrationWindow(t *testing.T) {
+	params := config.DefaultParams
+	committee := NewCommittee(&params)
+
+	// registration is open during the whole voting period by default
+	w := committee.GetRegistrationWindow()
+	assert.Equal(t, params.CRVotingStartHeight, w.StartHeight)
+	assert.Equal(t, params.CRCommitteeStartHeight, w.EndHeight)
+	assert.Equal(t, params.CRCommitteeStartHeight+params.CRDutyPeriod-
+		params.CRVotingPeriod, w.NextStartHeight)
+	assert.Equal(t, params.CRCommitteeStartHeight+params.CRDutyPeriod,
+		w.NextEndHeight)
+	assert.False(t, committee.IsInRegistrationPeriod(
+		params.CRVotingStartHeight-1))
+	assert.True(t, committee.IsInRegistrationPeriod(
+		params.CRVotingStartHeight))
+	assert.True(t, committee.IsInRegistrationPeriod(
+		params.CRCommitteeStartHeight-1))
+	assert.False(t, committee.IsInRegistrationPeriod(
+		params.CRCommitteeStartHeight))
+
+	// registration closes after the nomination period
+	params.CRNominationPeriod = 100
+	w = committee.GetRegistrationWindow()
+	assert.Equal(t, params.CRVotingStartHeight+100, w.EndHeight)
+	assert.Equal(t, w.NextStartHeight+100, w.NextEndHeight)
+	assert.True(t, committee.IsInRegistrationPeriod(
+		params.CRVotingStartHeight+99))
+	assert.False(t, committee.IsInRegistrationPeriod(
+		params.CRVotingStartHeight+100))
+	assert.True(t, committee.IsInVotingPeriod(
+		params.CRVotingStartHeight+100))
+
+	// change to first committee
+	committee.LastCommitteeHeight = params.CRCommitteeStartHeight
+	w = committee.GetRegistrationWindow()
+	votingStart := params.CRCommitteeStartHeight + params.CRDutyPeriod -
+		params.CRVotingPeriod
+	assert.Equal(t, votingStart, w.StartHeight)
+	assert.Equal(t, votingStart+100, w.EndHeight)
+	assert.False(t, committee.IsInRegistrationPeriod(votingStart-1))
+	assert.True(t, committee.IsInRegistrationPeriod(votingStart))
+	assert.False(t, committee.IsInRegistrationPeriod(votingStart+100))
+
+	// nomination period longer than voting period
+	params.CRNominationPeriod = params.CRVotingPeriod + 1
+	w = committee.GetRegistrationWindow()
+	assert.Equal(t, votingStart+params.CRVotingPeriod, w.EndHeight)
+}
+
 func TestCommittee_RollbackTo_SameCommittee_VotingPeriod(t *testing.T) {
 	committee := NewCommittee(&config.DefaultParams)
 
@@ -334,3 +384,81 @@ func existCID(cid common.Uint168, cidArray []common.Uint168) bool {
 	}
 	return false
 }
+
+func TestCommittee_ChangeCommittee(t *testing.T) {
+	params := config.DefaultParams
+	params.CRCandidateTieBreakHeight = params.CRCommitteeStartHeight
+	committee := NewCommittee(&params)
+	assert.Nil(t, committee.GetCurrentSession())
+	assert.Equal(t, params.CRCommitteeStartHeight,
+		committee.GetNextElectionHeight())
+
+	// all candidates have same votes, candidates registered earlier should be
+	// elected.
+	count := int(params.CRMemberCount) + 1
+	candidates := make([]*Candidate, 0, count)
+	for i := 0; i < count; i++ {
+		candidate := randomCandidate()
+		candidate.state = Active
+		candidate.votes = 100
+		candidate.registerHeight = uint32(count - i)
+		cid := candidate.info.CID
+		committee.state.CodeCIDMap[common.BytesToHexString(
+			candidate.info.Code)] = cid
+		committee.state.ActivityCandidates[cid] = candidate
+		candidates = append(candidates, candidate)
+	}
+	loser := candidates[0]
+
+	committee.ProcessBlock(&types.Block{
+		Header: types.Header{
+			Height: params.CRCommitteeStartHeight,
+		},
+	}, nil)
+	cids := committee.GetMembersCIDs()
+	assert.Equal(t, int(params.CRMemberCount), len(cids))
+	assert.False(t, existCID(loser.info.CID, cids))
+	for i := 1; i < count; i++ {
+		assert.True(t, existCID(candidates[i].info.CID, cids))
+	}
+
+	// candidates not elected should be active with votes reset.
+	assert.Equal(t, Active, loser.state)
+	assert.Equal(t, common.Fixed64(0), loser.votes)
+	assert.Equal(t, 1, len(committee.state.ActivityCandidates))
+
+	session := committee.GetCurrentSession()
+	assert.Equal(t, uint32(1), session.Index)
+	assert.Equal(t, params.CRCommitteeStartHeight, session.StartHeight)
+	assert.Equal(t, params.CRCommitteeStartHeight+params.CRDutyPeriod,
+		session.EndHeight)
+	assert.Equal(t, session.EndHeight, committee.GetNextElectionHeight())
+	assert.Equal(t, int(params.CRMemberCount), len(session.Members))
+
+	// prepare candidates of the next election.
+	round2, expectCandidates2 := generateCandidateSuite()
+	committee.state.StateKeyFrame = *round2
+	committee.ProcessBlock(&types.Block{
+		Header: types.Header{
+			Height: session.EndHeight,
+		},
+	}, nil)
+	for _, v := range expectCandidates2 {
+		assert.True(t, existCID(v.info.CID, committee.GetMembersCIDs()))
+	}
+
+	// deposits of outgoing members should be carried over.
+	for _, m := range session.Members {
+		candidate := committee.state.GetCandidateByCID(m.Info.CID)
+		assert.NotNil(t, candidate)
+		assert.Equal(t, Canceled, candidate.state)
+		assert.Equal(t, session.EndHeight, candidate.cancelHeight)
+		assert.Equal(t, m.DepositAmount, candidate.depositAmount)
+		assert.True(t, m.DepositHash.IsEqual(candidate.depositHash))
+		assert.True(t, committee.state.ExistCandidate(m.Info.Code))
+	}
+
+	session2 := committee.GetCurrentSession()
+	assert.Equal(t, uint32(2), session2.Index)
+	assert.Equal(t, session.EndHeight, session2.StartHeight)
+}
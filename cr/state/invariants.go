@@ -0,0 +1,88 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+)
+
+// CheckInvariants cross-checks the votes and deposit amount of candidates
+// against the vote outputs and deposit outputs tracked by state, returns an
+// error describing all violations found.  It is only used in debug mode since
+// it walks through all vote and deposit outputs.
+func (s *State) CheckInvariants() error {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	votes := make(map[common.Uint168]common.Fixed64)
+	for _, output := range s.Votes {
+		// canceled vote outputs are kept as nil
+		if output == nil {
+			continue
+		}
+		p := output.Payload.(*outputpayload.VoteOutput)
+		for _, content := range p.Contents {
+			if content.VoteType != outputpayload.CRC {
+				continue
+			}
+			for _, cv := range content.CandidateVotes {
+				cid, err := common.Uint168FromBytes(cv.Candidate)
+				if err != nil {
+					continue
+				}
+				votes[*cid] += cv.Votes
+			}
+		}
+	}
+
+	deposits := make(map[common.Uint168]common.Fixed64)
+	for _, output := range s.DepositOutputs {
+		deposits[output.ProgramHash] += output.Value
+	}
+
+	var violations []string
+	// votes of canceled candidates are kept across voting periods while the
+	// vote outputs are cleared, so only pending and active candidates are
+	// checked.
+	for _, candidates := range []map[common.Uint168]*Candidate{
+		s.PendingCandidates, s.ActivityCandidates} {
+		for cid, c := range candidates {
+			if c.votes != votes[cid] {
+				violations = append(violations, fmt.Sprintf("candidate %s "+
+					"votes %s, vote outputs %s", cid, c.votes, votes[cid]))
+			}
+		}
+	}
+
+	for _, state := range []CandidateState{Pending, Active, Canceled} {
+		for _, c := range s.getCandidates(state) {
+			violations = append(violations, checkDeposit(c, deposits)...)
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(violations, "\n"))
+}
+
+// checkDeposit checks the deposit amount of the candidate against the
+// deposit outputs, deposit outputs are not removed after returned so that
+// returned candidates are not checked.
+func checkDeposit(c *Candidate,
+	deposits map[common.Uint168]common.Fixed64) []string {
+	if c.depositAmount != deposits[c.depositHash] {
+		return []string{fmt.Sprintf("candidate %s deposit amount %s, "+
+			"deposit outputs %s", c.info.CID, c.depositAmount,
+			deposits[c.depositHash])}
+	}
+	return nil
+}
@@ -6,11 +6,13 @@
 package state
 
 import (
+	"bytes"
 	"io"
 
 	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/core/types"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/crypto"
 	"github.com/elastos/Elastos.ELA/utils"
 )
 
@@ -29,6 +31,16 @@ type KeyFrame struct {
 	LastCommitteeHeight uint32
 }
 
+const (
+	// StateKeyFrameVersion is the version of state key frames written before
+	// custom ID and nickname commitment states were added.
+	StateKeyFrameVersion byte = 0x00
+
+	// StateKeyFrameLatestVersion is the version of state key frames carrying
+	// custom ID and nickname commitment states.
+	StateKeyFrameLatestVersion byte = 0x01
+)
+
 // StateKeyFrame holds necessary state about CR state.
 type StateKeyFrame struct {
 	CodeCIDMap         map[string]common.Uint168
@@ -39,6 +51,13 @@ type StateKeyFrame struct {
 	Nicknames          map[string]struct{}
 	Votes              map[string]*types.Output
 	DepositOutputs     map[string]*types.Output
+	ReservedCustomIDs  map[string]struct{}
+	BannedCustomIDs    map[string]struct{}
+	CustomIDFeeRates   map[uint32]common.Fixed64
+
+	// NicknameCommitments holds the expiry heights of CR nickname
+	// commitments not revealed or expired yet.
+	NicknameCommitments map[common.Uint256]uint32
 }
 
 func (c *CRMember) Serialize(w io.Writer) (err error) {
@@ -135,6 +154,11 @@ func NewKeyFrame() *KeyFrame {
 }
 
 func (k *StateKeyFrame) Serialize(w io.Writer) (err error) {
+	if err = utils.WriteKeyFrameVersion(w,
+		StateKeyFrameLatestVersion); err != nil {
+		return
+	}
+
 	if err = k.serializeCodeAddressMap(w, k.CodeCIDMap); err != nil {
 		return
 	}
@@ -163,10 +187,31 @@ func (k *StateKeyFrame) Serialize(w io.Writer) (err error) {
 		return
 	}
 
-	return k.serializeOutputsMap(w, k.DepositOutputs)
+	if err = k.serializeOutputsMap(w, k.DepositOutputs); err != nil {
+		return
+	}
+
+	if err = utils.SerializeStringSet(w, k.ReservedCustomIDs); err != nil {
+		return
+	}
+
+	if err = utils.SerializeStringSet(w, k.BannedCustomIDs); err != nil {
+		return
+	}
+
+	if err = k.serializeFeeRatesMap(w, k.CustomIDFeeRates); err != nil {
+		return
+	}
+
+	return k.serializeCommitmentsMap(w, k.NicknameCommitments)
 }
 
 func (k *StateKeyFrame) Deserialize(r io.Reader) (err error) {
+	var version byte
+	if version, r, err = utils.ReadKeyFrameVersion(r); err != nil {
+		return
+	}
+
 	if k.CodeCIDMap, err = k.deserializeCodeAddressMap(r); err != nil {
 		return
 	}
@@ -198,9 +243,92 @@ func (k *StateKeyFrame) Deserialize(r io.Reader) (err error) {
 	if k.DepositOutputs, err = k.deserializeOutputsMap(r); err != nil {
 		return
 	}
+
+	if version < StateKeyFrameLatestVersion {
+		k.ReservedCustomIDs = make(map[string]struct{})
+		k.BannedCustomIDs = make(map[string]struct{})
+		k.CustomIDFeeRates = make(map[uint32]common.Fixed64)
+		k.NicknameCommitments = make(map[common.Uint256]uint32)
+		return
+	}
+
+	if k.ReservedCustomIDs, err = utils.DeserializeStringSet(r); err != nil {
+		return
+	}
+
+	if k.BannedCustomIDs, err = utils.DeserializeStringSet(r); err != nil {
+		return
+	}
+
+	if k.CustomIDFeeRates, err = k.deserializeFeeRatesMap(r); err != nil {
+		return
+	}
+
+	if k.NicknameCommitments, err = k.deserializeCommitmentsMap(r); err != nil {
+		return
+	}
 	return
 }
 
+// Hash returns the canonical hash of the state key frame. Entries of each map
+// are hashed one by one and combined as the merkle root of the sorted entry
+// hashes, so the result does not depend on the iteration order of maps, then
+// the roots of all fields are combined by the order of serialization.
+func (k *StateKeyFrame) Hash() (hash common.Uint256, err error) {
+	roots := make([]common.Uint256, 0, 13)
+	var root common.Uint256
+	if root, err = hashCodeAddressMap(k.CodeCIDMap); err != nil {
+		return
+	}
+	roots = append(roots, root)
+
+	if root, err = hashDepositDIDMap(k.DepositHashMap); err != nil {
+		return
+	}
+	roots = append(roots, root)
+
+	for _, cmap := range []map[common.Uint168]*Candidate{k.PendingCandidates,
+		k.ActivityCandidates, k.CanceledCandidates} {
+		if root, err = hashCandidateMap(cmap); err != nil {
+			return
+		}
+		roots = append(roots, root)
+	}
+
+	if root, err = hashStringSet(k.Nicknames); err != nil {
+		return
+	}
+	roots = append(roots, root)
+
+	for _, vmap := range []map[string]*types.Output{k.Votes,
+		k.DepositOutputs} {
+		if root, err = hashOutputsMap(vmap); err != nil {
+			return
+		}
+		roots = append(roots, root)
+	}
+
+	for _, set := range []map[string]struct{}{k.ReservedCustomIDs,
+		k.BannedCustomIDs} {
+		if root, err = hashStringSet(set); err != nil {
+			return
+		}
+		roots = append(roots, root)
+	}
+
+	if root, err = hashFeeRatesMap(k.CustomIDFeeRates); err != nil {
+		return
+	}
+	roots = append(roots, root)
+
+	if root, err = hashCommitmentsMap(k.NicknameCommitments); err != nil {
+		return
+	}
+	roots = append(roots, root)
+
+	return crypto.ComputeRoot(roots)
+}
+
 func (k *StateKeyFrame) serializeCodeAddressMap(w io.Writer,
 	cmap map[string]common.Uint168) (err error) {
 	if err = common.WriteVarUint(w, uint64(len(cmap))); err != nil {
@@ -367,6 +495,82 @@ func (s *StateKeyFrame) deserializeOutputsMap(
 	return
 }
 
+func (k *StateKeyFrame) serializeFeeRatesMap(w io.Writer,
+	rmap map[uint32]common.Fixed64) (err error) {
+	if err = common.WriteVarUint(w, uint64(len(rmap))); err != nil {
+		return
+	}
+	for k, v := range rmap {
+		if err = common.WriteUint32(w, k); err != nil {
+			return
+		}
+
+		if err = v.Serialize(w); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (k *StateKeyFrame) deserializeFeeRatesMap(r io.Reader) (
+	rmap map[uint32]common.Fixed64, err error) {
+	var count uint64
+	if count, err = common.ReadVarUint(r, 0); err != nil {
+		return
+	}
+	rmap = make(map[uint32]common.Fixed64)
+	for i := uint64(0); i < count; i++ {
+		var k uint32
+		if k, err = common.ReadUint32(r); err != nil {
+			return
+		}
+		var v common.Fixed64
+		if err = v.Deserialize(r); err != nil {
+			return
+		}
+		rmap[k] = v
+	}
+	return
+}
+
+func (k *StateKeyFrame) serializeCommitmentsMap(w io.Writer,
+	cmap map[common.Uint256]uint32) (err error) {
+	if err = common.WriteVarUint(w, uint64(len(cmap))); err != nil {
+		return
+	}
+	for k, v := range cmap {
+		if err = k.Serialize(w); err != nil {
+			return
+		}
+
+		if err = common.WriteUint32(w, v); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (k *StateKeyFrame) deserializeCommitmentsMap(r io.Reader) (
+	cmap map[common.Uint256]uint32, err error) {
+	var count uint64
+	if count, err = common.ReadVarUint(r, 0); err != nil {
+		return
+	}
+	cmap = make(map[common.Uint256]uint32)
+	for i := uint64(0); i < count; i++ {
+		var k common.Uint256
+		if err = k.Deserialize(r); err != nil {
+			return
+		}
+		var v uint32
+		if v, err = common.ReadUint32(r); err != nil {
+			return
+		}
+		cmap[k] = v
+	}
+	return
+}
+
 // Snapshot will create a new StateKeyFrame object and deep copy all related data.
 func (k *StateKeyFrame) Snapshot() *StateKeyFrame {
 	state := NewStateKeyFrame()
@@ -377,6 +581,10 @@ func (k *StateKeyFrame) Snapshot() *StateKeyFrame {
 	state.Nicknames = utils.CopyStringSet(k.Nicknames)
 	state.Votes = copyOutputsMap(k.Votes)
 	state.DepositOutputs = copyOutputsMap(k.DepositOutputs)
+	state.ReservedCustomIDs = utils.CopyStringSet(k.ReservedCustomIDs)
+	state.BannedCustomIDs = utils.CopyStringSet(k.BannedCustomIDs)
+	state.CustomIDFeeRates = copyFeeRatesMap(k.CustomIDFeeRates)
+	state.NicknameCommitments = copyCommitmentsMap(k.NicknameCommitments)
 
 	return state
 }
@@ -391,6 +599,11 @@ func NewStateKeyFrame() *StateKeyFrame {
 		Nicknames:          make(map[string]struct{}),
 		Votes:              make(map[string]*types.Output),
 		DepositOutputs:     make(map[string]*types.Output),
+		ReservedCustomIDs:  make(map[string]struct{}),
+		BannedCustomIDs:    make(map[string]struct{}),
+		CustomIDFeeRates:   make(map[uint32]common.Fixed64),
+
+		NicknameCommitments: make(map[common.Uint256]uint32),
 	}
 }
 
@@ -428,6 +641,26 @@ func copyOutputsMap(src map[string]*types.Output) (dst map[string]*types.Output)
 	return
 }
 
+// copyFeeRatesMap copy the map's key and value, and return the dst map.
+func copyFeeRatesMap(src map[uint32]common.Fixed64) (
+	dst map[uint32]common.Fixed64) {
+	dst = map[uint32]common.Fixed64{}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return
+}
+
+// copyCommitmentsMap copy the map's key and value, and return the dst map.
+func copyCommitmentsMap(src map[common.Uint256]uint32) (
+	dst map[common.Uint256]uint32) {
+	dst = map[common.Uint256]uint32{}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return
+}
+
 func copyCRMembers(src []*CRMember) []*CRMember {
 	dst := make([]*CRMember, 0, len(src))
 	for _, v := range src {
@@ -436,3 +669,133 @@ func copyCRMembers(src []*CRMember) []*CRMember {
 	}
 	return dst
 }
+
+// hashEntry returns the double sha256 hash of the data written by serialize.
+func hashEntry(serialize func(w io.Writer) error) (common.Uint256, error) {
+	buf := new(bytes.Buffer)
+	if err := serialize(buf); err != nil {
+		return common.Uint256{}, err
+	}
+	return common.Uint256(common.Sha256D(buf.Bytes())), nil
+}
+
+func hashCodeAddressMap(cmap map[string]common.Uint168) (
+	common.Uint256, error) {
+	hashes := make([]common.Uint256, 0, len(cmap))
+	for k, v := range cmap {
+		hash, err := hashEntry(func(w io.Writer) error {
+			if err := common.WriteVarString(w, k); err != nil {
+				return err
+			}
+			return v.Serialize(w)
+		})
+		if err != nil {
+			return common.Uint256{}, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return crypto.ComputeSortedRoot(hashes), nil
+}
+
+func hashDepositDIDMap(cmap map[common.Uint168]struct{}) (
+	common.Uint256, error) {
+	hashes := make([]common.Uint256, 0, len(cmap))
+	for k := range cmap {
+		hash, err := hashEntry(k.Serialize)
+		if err != nil {
+			return common.Uint256{}, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return crypto.ComputeSortedRoot(hashes), nil
+}
+
+func hashCandidateMap(cmap map[common.Uint168]*Candidate) (
+	common.Uint256, error) {
+	hashes := make([]common.Uint256, 0, len(cmap))
+	for k, v := range cmap {
+		hash, err := hashEntry(func(w io.Writer) error {
+			if err := k.Serialize(w); err != nil {
+				return err
+			}
+			return v.Serialize(w)
+		})
+		if err != nil {
+			return common.Uint256{}, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return crypto.ComputeSortedRoot(hashes), nil
+}
+
+func hashStringSet(vmap map[string]struct{}) (common.Uint256, error) {
+	hashes := make([]common.Uint256, 0, len(vmap))
+	for k := range vmap {
+		hash, err := hashEntry(func(w io.Writer) error {
+			return common.WriteVarString(w, k)
+		})
+		if err != nil {
+			return common.Uint256{}, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return crypto.ComputeSortedRoot(hashes), nil
+}
+
+func hashOutputsMap(vmap map[string]*types.Output) (common.Uint256, error) {
+	hashes := make([]common.Uint256, 0, len(vmap))
+	for k, v := range vmap {
+		hash, err := hashEntry(func(w io.Writer) error {
+			if err := common.WriteVarString(w, k); err != nil {
+				return err
+			}
+			if v == nil {
+				return common.WriteUint8(w, 0)
+			}
+			if err := common.WriteUint8(w, 1); err != nil {
+				return err
+			}
+			return v.Serialize(w, types.TxVersion09)
+		})
+		if err != nil {
+			return common.Uint256{}, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return crypto.ComputeSortedRoot(hashes), nil
+}
+
+func hashFeeRatesMap(rmap map[uint32]common.Fixed64) (common.Uint256, error) {
+	hashes := make([]common.Uint256, 0, len(rmap))
+	for k, v := range rmap {
+		hash, err := hashEntry(func(w io.Writer) error {
+			if err := common.WriteUint32(w, k); err != nil {
+				return err
+			}
+			return v.Serialize(w)
+		})
+		if err != nil {
+			return common.Uint256{}, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return crypto.ComputeSortedRoot(hashes), nil
+}
+
+func hashCommitmentsMap(cmap map[common.Uint256]uint32) (common.Uint256,
+	error) {
+	hashes := make([]common.Uint256, 0, len(cmap))
+	for k, v := range cmap {
+		hash, err := hashEntry(func(w io.Writer) error {
+			if err := k.Serialize(w); err != nil {
+				return err
+			}
+			return common.WriteUint32(w, v)
+		})
+		if err != nil {
+			return common.Uint256{}, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return crypto.ComputeSortedRoot(hashes), nil
+}
@@ -47,16 +47,79 @@ func TestStateKeyFrame_Deserialize(t *testing.T) {
 	assert.True(t, stateKeyframeEqual(frame, frame2))
 }
 
+func TestStateKeyFrame_DeserializeLegacy(t *testing.T) {
+	frame := randomStateKeyFrame(5, true)
+	frame.ReservedCustomIDs = make(map[string]struct{})
+	frame.BannedCustomIDs = make(map[string]struct{})
+	frame.CustomIDFeeRates = make(map[uint32]common.Fixed64)
+	frame.NicknameCommitments = make(map[common.Uint256]uint32)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, frame.Serialize(buf))
+
+	// legacy key frames have no version header and end with deposit outputs,
+	// here strip the header and the four empty maps appended after them
+	data := buf.Bytes()
+	legacy := bytes.NewReader(data[2 : len(data)-4])
+
+	frame2 := &StateKeyFrame{}
+	assert.NoError(t, frame2.Deserialize(legacy))
+	assert.Equal(t, 0, legacy.Len())
+	assert.True(t, stateKeyframeEqual(frame, frame2))
+	assert.NotNil(t, frame2.ReservedCustomIDs)
+	assert.NotNil(t, frame2.BannedCustomIDs)
+	assert.NotNil(t, frame2.CustomIDFeeRates)
+	assert.NotNil(t, frame2.NicknameCommitments)
+}
+
 func TestStateKeyFrame_Snapshot(t *testing.T) {
 	frame := randomStateKeyFrame(5, true)
 	frame2 := frame.Snapshot()
 	assert.True(t, stateKeyframeEqual(frame, frame2))
 }
 
+func TestStateKeyFrame_Hash(t *testing.T) {
+	frame := randomStateKeyFrame(20, true)
+	hash, err := frame.Hash()
+	assert.NoError(t, err)
+
+	// maps rebuilt in another insertion order should have the same hash
+	buf := new(bytes.Buffer)
+	assert.NoError(t, frame.Serialize(buf))
+	frame2 := &StateKeyFrame{}
+	assert.NoError(t, frame2.Deserialize(buf))
+	hash2, err := frame2.Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, hash, hash2)
+
+	frame3 := frame.Snapshot()
+	hash3, err := frame3.Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, hash, hash3)
+
+	// any change of entries should change the hash
+	frame3.BannedCustomIDs[randomString()] = struct{}{}
+	hash3, err = frame3.Hash()
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, hash3)
+
+	for k := range frame2.Nicknames {
+		delete(frame2.Nicknames, k)
+		break
+	}
+	hash2, err = frame2.Hash()
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, hash2)
+}
+
 func stateKeyframeEqual(first *StateKeyFrame, second *StateKeyFrame) bool {
 	if len(first.Nicknames) != len(second.Nicknames) ||
 		len(first.CodeCIDMap) != len(second.CodeCIDMap) ||
-		len(first.Votes) != len(second.Votes) {
+		len(first.Votes) != len(second.Votes) ||
+		len(first.ReservedCustomIDs) != len(second.ReservedCustomIDs) ||
+		len(first.BannedCustomIDs) != len(second.BannedCustomIDs) ||
+		len(first.CustomIDFeeRates) != len(second.CustomIDFeeRates) ||
+		len(first.NicknameCommitments) != len(second.NicknameCommitments) {
 		return false
 	}
 
@@ -66,6 +129,30 @@ func stateKeyframeEqual(first *StateKeyFrame, second *StateKeyFrame) bool {
 		}
 	}
 
+	for k := range first.ReservedCustomIDs {
+		if _, ok := second.ReservedCustomIDs[k]; !ok {
+			return false
+		}
+	}
+
+	for k := range first.BannedCustomIDs {
+		if _, ok := second.BannedCustomIDs[k]; !ok {
+			return false
+		}
+	}
+
+	for k, v := range first.CustomIDFeeRates {
+		if v2, ok := second.CustomIDFeeRates[k]; !ok || v != v2 {
+			return false
+		}
+	}
+
+	for k, v := range first.NicknameCommitments {
+		if v2, ok := second.NicknameCommitments[k]; !ok || v != v2 {
+			return false
+		}
+	}
+
 	for k, v := range first.CodeCIDMap {
 		v2, ok := second.CodeCIDMap[k]
 		if !ok {
@@ -195,6 +282,12 @@ func randomStateKeyFrame(size int, hasPending bool) *StateKeyFrame {
 	for i := 0; i < size; i++ {
 		frame.Votes[randomString()] = randomOutputs()
 	}
+	for i := 0; i < size; i++ {
+		frame.ReservedCustomIDs[randomString()] = struct{}{}
+		frame.BannedCustomIDs[randomString()] = struct{}{}
+		frame.CustomIDFeeRates[rand.Uint32()] = common.Fixed64(rand.Int63())
+		frame.NicknameCommitments[*randomUint256()] = rand.Uint32()
+	}
 	return frame
 }
 
@@ -0,0 +1,245 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+)
+
+// FieldChange records the old and new value of a field changed between two
+// key frames.
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// CandidateDiff records the changed fields of a candidate or a member
+// existing in both key frames.
+type CandidateDiff struct {
+	CID     common.Uint168
+	Changes []FieldChange
+}
+
+// KeyFrameDiff records the changes of CR state between two key frames,
+// candidates and members are identified by CID and votes by the referenced
+// output key.
+type KeyFrameDiff struct {
+	FromHeight uint32
+	ToHeight   uint32
+
+	AddedCandidates   []common.Uint168
+	RemovedCandidates []common.Uint168
+	ChangedCandidates []CandidateDiff
+
+	AddedMembers   []common.Uint168
+	RemovedMembers []common.Uint168
+	ChangedMembers []CandidateDiff
+
+	AddedVotes   []string
+	RemovedVotes []string
+}
+
+// IsEmpty returns if nothing changed between the two key frames.
+func (d *KeyFrameDiff) IsEmpty() bool {
+	return len(d.AddedCandidates) == 0 && len(d.RemovedCandidates) == 0 &&
+		len(d.ChangedCandidates) == 0 && len(d.AddedMembers) == 0 &&
+		len(d.RemovedMembers) == 0 && len(d.ChangedMembers) == 0 &&
+		len(d.AddedVotes) == 0 && len(d.RemovedVotes) == 0
+}
+
+// DiffCheckpoints returns the changes from the from checkpoint to the to
+// checkpoint.
+func DiffCheckpoints(from, to *Checkpoint) *KeyFrameDiff {
+	diff := &KeyFrameDiff{
+		FromHeight: from.GetHeight(),
+		ToHeight:   to.GetHeight(),
+	}
+
+	fromCandidates := allCandidates(&from.StateKeyFrame)
+	toCandidates := allCandidates(&to.StateKeyFrame)
+	for cid, c := range toCandidates {
+		origin, ok := fromCandidates[cid]
+		if !ok {
+			diff.AddedCandidates = append(diff.AddedCandidates, cid)
+			continue
+		}
+		if changes := diffCandidate(origin, c); len(changes) > 0 {
+			diff.ChangedCandidates = append(diff.ChangedCandidates,
+				CandidateDiff{CID: cid, Changes: changes})
+		}
+	}
+	for cid := range fromCandidates {
+		if _, ok := toCandidates[cid]; !ok {
+			diff.RemovedCandidates = append(diff.RemovedCandidates, cid)
+		}
+	}
+
+	fromMembers := membersMap(from.Members)
+	toMembers := membersMap(to.Members)
+	for cid, m := range toMembers {
+		origin, ok := fromMembers[cid]
+		if !ok {
+			diff.AddedMembers = append(diff.AddedMembers, cid)
+			continue
+		}
+		if changes := diffMember(origin, m); len(changes) > 0 {
+			diff.ChangedMembers = append(diff.ChangedMembers,
+				CandidateDiff{CID: cid, Changes: changes})
+		}
+	}
+	for cid := range fromMembers {
+		if _, ok := toMembers[cid]; !ok {
+			diff.RemovedMembers = append(diff.RemovedMembers, cid)
+		}
+	}
+
+	for k := range to.Votes {
+		if _, ok := from.Votes[k]; !ok {
+			diff.AddedVotes = append(diff.AddedVotes, k)
+		}
+	}
+	for k := range from.Votes {
+		if _, ok := to.Votes[k]; !ok {
+			diff.RemovedVotes = append(diff.RemovedVotes, k)
+		}
+	}
+
+	sortCIDs(diff.AddedCandidates)
+	sortCIDs(diff.RemovedCandidates)
+	sortCandidateDiffs(diff.ChangedCandidates)
+	sortCIDs(diff.AddedMembers)
+	sortCIDs(diff.RemovedMembers)
+	sortCandidateDiffs(diff.ChangedMembers)
+	sort.Strings(diff.AddedVotes)
+	sort.Strings(diff.RemovedVotes)
+	return diff
+}
+
+// DiffKeyFrames returns the changes of CR state from the checkpoint of height
+// h1 to the checkpoint of height h2.  Heights not lower than the latest saved
+// checkpoint are resolved to the current state.
+func (c *Committee) DiffKeyFrames(h1, h2 uint32) (*KeyFrameDiff, error) {
+	from, err := c.getKeyFrameCheckpoint(h1)
+	if err != nil {
+		return nil, err
+	}
+	to, err := c.getKeyFrameCheckpoint(h2)
+	if err != nil {
+		return nil, err
+	}
+	return DiffCheckpoints(from, to), nil
+}
+
+func (c *Committee) getKeyFrameCheckpoint(height uint32) (*Checkpoint,
+	error) {
+	if c.params == nil || c.params.CkpManager == nil {
+		return nil, errors.New("checkpoint manager not initialized")
+	}
+	point, ok := c.params.CkpManager.GetCheckpoint(checkpointKey, height)
+	if !ok || point == nil {
+		return nil, fmt.Errorf("no CR checkpoint found at height %d", height)
+	}
+	cp, ok := point.(*Checkpoint)
+	if !ok {
+		return nil, errors.New("invalid CR checkpoint type")
+	}
+	if cp.committee == nil {
+		return cp, nil
+	}
+
+	// the registered checkpoint shares state with committee, so take a
+	// snapshot of the current state instead.
+	c.mtx.RLock()
+	c.state.mtx.RLock()
+	snapshot := &Checkpoint{
+		KeyFrame:      *c.KeyFrame.Snapshot(),
+		StateKeyFrame: *c.state.StateKeyFrame.Snapshot(),
+		height:        c.stateHashHeight,
+	}
+	c.state.mtx.RUnlock()
+	c.mtx.RUnlock()
+	return snapshot, nil
+}
+
+// allCandidates returns candidates of all states with CID as key.
+func allCandidates(frame *StateKeyFrame) map[common.Uint168]*Candidate {
+	candidates := make(map[common.Uint168]*Candidate)
+	for _, m := range []map[common.Uint168]*Candidate{
+		frame.PendingCandidates,
+		frame.ActivityCandidates,
+		frame.CanceledCandidates,
+	} {
+		for k, v := range m {
+			candidates[k] = v
+		}
+	}
+	return candidates
+}
+
+func membersMap(members []*CRMember) map[common.Uint168]*CRMember {
+	result := make(map[common.Uint168]*CRMember, len(members))
+	for _, m := range members {
+		result[m.Info.CID] = m
+	}
+	return result
+}
+
+type fieldChecker []FieldChange
+
+func (f *fieldChecker) check(field string, origin, current interface{}) {
+	o, n := fmt.Sprint(origin), fmt.Sprint(current)
+	if o != n {
+		*f = append(*f, FieldChange{Field: field, Old: o, New: n})
+	}
+}
+
+func (f *fieldChecker) checkInfo(from, to *payload.CRInfo) {
+	f.check("did", from.DID, to.DID)
+	f.check("code", common.BytesToHexString(from.Code),
+		common.BytesToHexString(to.Code))
+	f.check("nickname", from.NickName, to.NickName)
+	f.check("url", from.Url, to.Url)
+	f.check("location", from.Location, to.Location)
+}
+
+func diffCandidate(from, to *Candidate) []FieldChange {
+	var f fieldChecker
+	f.check("state", from.state, to.state)
+	f.checkInfo(&from.info, &to.info)
+	f.check("votes", from.votes, to.votes)
+	f.check("penalty", from.penalty, to.penalty)
+	f.check("depositamount", from.depositAmount, to.depositAmount)
+	f.check("registerheight", from.registerHeight, to.registerHeight)
+	f.check("cancelheight", from.cancelHeight, to.cancelHeight)
+	return f
+}
+
+func diffMember(from, to *CRMember) []FieldChange {
+	var f fieldChecker
+	f.checkInfo(&from.Info, &to.Info)
+	f.check("impeachmentvotes", from.ImpeachmentVotes, to.ImpeachmentVotes)
+	f.check("penalty", from.Penalty, to.Penalty)
+	f.check("depositamount", from.DepositAmount, to.DepositAmount)
+	return f
+}
+
+func sortCIDs(cids []common.Uint168) {
+	sort.Slice(cids, func(i, j int) bool {
+		return cids[i].Compare(cids[j]) < 0
+	})
+}
+
+func sortCandidateDiffs(diffs []CandidateDiff) {
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].CID.Compare(diffs[j].CID) < 0
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package state
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffCheckpoints(t *testing.T) {
+	from := &Checkpoint{
+		KeyFrame:      *randomKeyFrame(5, 100),
+		StateKeyFrame: *randomStateKeyFrame(5, true),
+		height:        720,
+	}
+	to := &Checkpoint{
+		KeyFrame:      *from.KeyFrame.Snapshot(),
+		StateKeyFrame: *from.StateKeyFrame.Snapshot(),
+		height:        1440,
+	}
+
+	// nothing changed
+	diff := DiffCheckpoints(from, to)
+	assert.Equal(t, uint32(720), diff.FromHeight)
+	assert.Equal(t, uint32(1440), diff.ToHeight)
+	assert.True(t, diff.IsEmpty())
+
+	// add, remove and change candidates
+	added := randomCandidate()
+	to.PendingCandidates[added.info.CID] = added
+	var removed, changed common.Uint168
+	for k := range to.CanceledCandidates {
+		removed = k
+		break
+	}
+	delete(to.CanceledCandidates, removed)
+	for k := range to.PendingCandidates {
+		if k != added.info.CID {
+			changed = k
+			break
+		}
+	}
+	candidate := to.PendingCandidates[changed]
+	delete(to.PendingCandidates, changed)
+	candidate.state = Active
+	candidate.votes += 100
+	to.ActivityCandidates[changed] = candidate
+
+	// replace a member and change another one
+	removedMember := to.Members[0].Info.CID
+	to.Members[0] = randomCRMember()
+	addedMember := to.Members[0].Info.CID
+	changedMember := to.Members[1].Info.CID
+	to.Members[1].ImpeachmentVotes += 10
+
+	// add and remove votes
+	addedVote := randomString()
+	to.Votes[addedVote] = randomOutputs()
+	var removedVote string
+	for k := range to.Votes {
+		if k != addedVote {
+			removedVote = k
+			break
+		}
+	}
+	delete(to.Votes, removedVote)
+
+	diff = DiffCheckpoints(from, to)
+	assert.False(t, diff.IsEmpty())
+	assert.Equal(t, []common.Uint168{added.info.CID}, diff.AddedCandidates)
+	assert.Equal(t, []common.Uint168{removed}, diff.RemovedCandidates)
+	assert.Equal(t, 1, len(diff.ChangedCandidates))
+	assert.Equal(t, changed, diff.ChangedCandidates[0].CID)
+	fields := make(map[string]FieldChange)
+	for _, c := range diff.ChangedCandidates[0].Changes {
+		fields[c.Field] = c
+	}
+	assert.Equal(t, 2, len(fields))
+	assert.Equal(t, from.PendingCandidates[changed].state.String(),
+		fields["state"].Old)
+	assert.Equal(t, Active.String(), fields["state"].New)
+	assert.Equal(t, candidate.votes.String(), fields["votes"].New)
+
+	assert.Equal(t, []common.Uint168{addedMember}, diff.AddedMembers)
+	assert.Equal(t, []common.Uint168{removedMember}, diff.RemovedMembers)
+	assert.Equal(t, 1, len(diff.ChangedMembers))
+	assert.Equal(t, changedMember, diff.ChangedMembers[0].CID)
+	assert.Equal(t, "impeachmentvotes",
+		diff.ChangedMembers[0].Changes[0].Field)
+
+	assert.Equal(t, []string{addedVote}, diff.AddedVotes)
+	assert.Equal(t, []string{removedVote}, diff.RemovedVotes)
+}
@@ -6,6 +6,7 @@
 package state
 
 import (
+	"bytes"
 	"sync"
 
 	"github.com/elastos/Elastos.ELA/common"
@@ -25,9 +26,6 @@ const (
 	// ActivateDuration is about how long we should activate from pending or
 	// inactive state.
 	ActivateDuration = 6
-
-	// CacheCRVotesSize indicate the size to cache votes information.
-	CacheCRVotesSize = 6
 )
 
 // State hold all CR candidates related information, and process block by block
@@ -39,8 +37,19 @@ type State struct {
 	params  *config.Params
 	history *utils.History
 
-	votesCacheKeys map[uint32][]string
-	votesCache     map[string]*types.Output
+	votesCache *votesCache
+
+	// foldedNicknames counts the nicknames in use by their folded form, so
+	// duplicated nicknames after folded can be found without folding all of
+	// them.
+	foldedNicknames map[string]int
+
+	// candidateHistories records information changes of candidates by cid.
+	// It is an audit log growing with the chain, so it is kept out of the key
+	// frame and stored by the index, changes of a block are collected by
+	// pendingCandidateChanges and written after the block processed.
+	candidateHistories      utils.HistoryIndex
+	pendingCandidateChanges []utils.HistoryRecord
 }
 
 // GetCandidate returns candidate with specified program code, it will return
@@ -51,6 +60,14 @@ func (s *State) GetCandidate(programCode []byte) *Candidate {
 	return s.getCandidate(programCode)
 }
 
+// GetVotesCacheStats returns the statistics of the cache keeping canceled
+// vote outputs.
+func (s *State) GetVotesCacheStats() VotesCacheStats {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.votesCache.stats()
+}
+
 // GetCandidateByID returns candidate with specified cid or did, it will return
 // nil if not found.
 func (s *State) GetCandidateByID(id common.Uint168) *Candidate {
@@ -98,6 +115,71 @@ func (s *State) GetCandidates(state CandidateState) []*Candidate {
 	return s.getCandidates(state)
 }
 
+// GetTotalDeposits returns the sum of deposit amount of candidates with
+// specified candidate state.
+func (s *State) GetTotalDeposits(state CandidateState) common.Fixed64 {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	var total common.Fixed64
+	for _, c := range s.getCandidates(state) {
+		total += c.depositAmount
+	}
+	return total
+}
+
+// GetDeposits returns the deposit amount of candidates with specified
+// candidate state, keyed by the deposit program hash.
+func (s *State) GetDeposits(state CandidateState) map[common.Uint168]common.Fixed64 {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	candidates := s.getCandidates(state)
+	result := make(map[common.Uint168]common.Fixed64, len(candidates))
+	for _, c := range candidates {
+		result[c.depositHash] = c.depositAmount
+	}
+	return result
+}
+
+// GetCandidateByDepositHash returns candidate with specified deposit program
+// hash, it will return nil if not found.
+func (s *State) GetCandidateByDepositHash(hash common.Uint168) *Candidate {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.getCandidateByDepositHash(hash)
+}
+
+// GetCandidateHistory returns the information changes of candidate with
+// specified cid, ordered by the height they happened.
+func (s *State) GetCandidateHistory(
+	cid common.Uint168) ([]*CandidateInfoChange, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	values, err := s.candidateHistories.GetRecords(cid.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*CandidateInfoChange, 0, len(values))
+	for _, v := range values {
+		var change CandidateInfoChange
+		if err := change.Deserialize(bytes.NewReader(v)); err != nil {
+			return nil, err
+		}
+		result = append(result, &change)
+	}
+	return result, nil
+}
+
+// SetCandidateHistoryIndex sets the index storing information changes of
+// candidates, changes are kept in memory if not set.
+func (s *State) SetCandidateHistoryIndex(index utils.HistoryIndex) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.candidateHistories = index
+}
+
 // ExistCandidate judges if there is a candidate with specified program code.
 func (s *State) ExistCandidate(programCode []byte) bool {
 	s.mtx.RLock()
@@ -143,29 +225,137 @@ func (s *State) ExistCandidateByNickname(nickname string) bool {
 	return ok
 }
 
+// ExistCandidateByNormalizedNickname judges if there is a candidate with a
+// nickname equals to the given one after normalized by NamePolicy.
+func (s *State) ExistCandidateByNormalizedNickname(nickname string) bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	policy := &s.params.NamePolicy
+	normalized := policy.NormalizeNickname(nickname)
+	for n := range s.Nicknames {
+		if policy.NormalizeNickname(n) == normalized {
+			return true
+		}
+	}
+	return false
+}
+
+// ExistCandidateByFoldedNickname judges if there is a candidate with a
+// nickname equals to the given one after folded by NamePolicy.
+func (s *State) ExistCandidateByFoldedNickname(nickname string) bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	_, ok := s.foldedNicknames[config.FoldNickname(nickname)]
+	return ok
+}
+
+// addNickname adds the nickname into the nicknames in use and the folded
+// nicknames index.
+func (s *State) addNickname(nickname string) {
+	if _, ok := s.Nicknames[nickname]; ok {
+		return
+	}
+	s.Nicknames[nickname] = struct{}{}
+	s.foldedNicknames[config.FoldNickname(nickname)]++
+}
+
+// removeNickname removes the nickname from the nicknames in use and the
+// folded nicknames index.
+func (s *State) removeNickname(nickname string) {
+	if _, ok := s.Nicknames[nickname]; !ok {
+		return
+	}
+	delete(s.Nicknames, nickname)
+	folded := config.FoldNickname(nickname)
+	if s.foldedNicknames[folded] <= 1 {
+		delete(s.foldedNicknames, folded)
+		return
+	}
+	s.foldedNicknames[folded]--
+}
+
+// rebuildFoldedNicknames builds the folded nicknames index from the nicknames
+// in use, it should be called after the key frame is replaced.
+func (s *State) rebuildFoldedNicknames() {
+	s.foldedNicknames = make(map[string]int, len(s.Nicknames))
+	for n := range s.Nicknames {
+		s.foldedNicknames[config.FoldNickname(n)]++
+	}
+}
+
+// IsCustomIDReserved returns if the custom ID has been reserved by CR
+// committee.
+func (s *State) IsCustomIDReserved(id string) bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	_, ok := s.ReservedCustomIDs[id]
+	return ok
+}
+
+// IsCustomIDBanned returns if the custom ID has been banned by CR committee.
+func (s *State) IsCustomIDBanned(id string) bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	_, ok := s.BannedCustomIDs[id]
+	return ok
+}
+
+// GetCustomIDFeeRate returns the custom ID fee rate which is effective at the
+// given height, it will return zero if no fee rate has been proposed.
+func (s *State) GetCustomIDFeeRate(height uint32) common.Fixed64 {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	var rate common.Fixed64
+	var effectiveHeight uint32
+	for h, r := range s.CustomIDFeeRates {
+		if h <= height && h >= effectiveHeight {
+			rate = r
+			effectiveHeight = h
+		}
+	}
+	return rate
+}
+
+// GetVoteOutput returns the vote output referenced by the refer key if the
+// output is voting to CR candidates and has not been spent or revoked.
+func (s *State) GetVoteOutput(referKey string) *types.Output {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.Votes[referKey]
+}
+
+// GetNicknameCommitExpiry returns the last height the CR nickname commitment
+// can be revealed, false will be returned if the commitment not exist,
+// revealed or expired.
+func (s *State) GetNicknameCommitExpiry(
+	commitment common.Uint256) (uint32, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	expiry, ok := s.NicknameCommitments[commitment]
+	return expiry, ok
+}
+
 // IsCRTransaction returns if a transaction will change the CR and votes state.
 func (s *State) IsCRTransaction(tx *types.Transaction) bool {
 	switch tx.TxType {
 	// Transactions will changes the producers state.
 	case types.RegisterCR, types.UpdateCR,
-		types.UnregisterCR, types.ReturnCRDepositCoin:
+		types.UnregisterCR, types.ReturnCRDepositCoin,
+		types.CustomIDProposal, types.RevokeVote, types.CRNicknameCommit:
 		return true
 
 	// Transactions will change the producer votes state.
 	case types.TransferAsset:
 		if tx.Version >= types.TxVersion09 {
 			for _, output := range tx.Outputs {
-				if output.Type != types.OTVote {
-					continue
-				}
-				p, _ := output.Payload.(*outputpayload.VoteOutput)
-				if p.Version < outputpayload.VoteProducerAndCRVersion {
-					continue
-				}
-				for _, content := range p.Contents {
-					if content.VoteType == outputpayload.CRC {
-						return true
-					}
+				if _, ok := types.MatchOutputStateHook(
+					outputHookModule, output); ok {
+					return true
 				}
 			}
 		}
@@ -190,12 +380,47 @@ func (s *State) ProcessBlock(block *types.Block, confirm *payload.Confirm) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 
+	if s.params != nil && block.Height == s.params.NicknameFoldHeight {
+		s.reportNicknameCollisions()
+	}
 	s.processTransactions(block.Transactions, block.Height)
 	s.history.Commit(block.Height)
+	s.writeCandidateHistories(block.Height)
 }
 
-// ProcessBlock takes a block and it's confirm to update CR state and
-// votes accordingly.
+// writeCandidateHistories writes the candidate information changes collected
+// on the height into the index.
+func (s *State) writeCandidateHistories(height uint32) {
+	err := s.candidateHistories.PutRecords(height, s.pendingCandidateChanges)
+	if err != nil {
+		log.Error("[writeCandidateHistories] write candidate histories "+
+			"error: ", err)
+	}
+	s.pendingCandidateChanges = nil
+}
+
+// removeCandidateHistories removes the candidate information changes happened
+// above the height from the index.
+func (s *State) removeCandidateHistories(height uint32) {
+	if err := s.candidateHistories.RollbackTo(height); err != nil {
+		log.Error("[removeCandidateHistories] remove candidate histories "+
+			"error: ", err)
+	}
+}
+
+// reportNicknameCollisions logs nicknames of CR candidates duplicated after
+// folded, they are kept since registered before NicknameFoldHeight, while
+// new nicknames duplicated with them will be rejected.
+func (s *State) reportNicknameCollisions() {
+	collisions := config.FoldedNicknameCollisions(s.Nicknames)
+	for _, group := range collisions {
+		log.Warnf("CR candidate nicknames %q are duplicated after folded, "+
+			"kept since registered before NicknameFoldHeight", group)
+	}
+}
+
+// ProcessReturnDepositTxs takes a block out of voting period to process return
+// deposit and custom ID proposal transactions.
 func (s *State) ProcessReturnDepositTxs(block *types.Block) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
@@ -204,6 +429,9 @@ func (s *State) ProcessReturnDepositTxs(block *types.Block) {
 		switch tx.TxType {
 		case types.ReturnCRDepositCoin:
 			s.returnDeposit(tx, block.Height)
+		case types.CustomIDProposal:
+			s.processCustomIDProposal(
+				tx.Payload.(*payload.CustomIDProposal), block.Height)
 		}
 	}
 	s.history.Commit(block.Height)
@@ -214,41 +442,75 @@ func (s *State) ProcessReturnDepositTxs(block *types.Block) {
 func (s *State) RollbackTo(height uint32) error {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
+	s.removeCandidateHistories(height)
 	return s.history.RollbackTo(height)
 }
 
-// FinishVoting will close all voting util next voting period
-func (s *State) FinishVoting(dids []common.Uint168) *StateKeyFrame {
+// FinishVoting will close all voting util next voting period. The elected
+// candidates are removed from candidates, votes of the other candidates are
+// reset so that they can take part in the next election, and deposits of the
+// outgoing committee members are carried over as canceled candidates so that
+// they can be returned later.
+func (s *State) FinishVoting(elected []common.Uint168, outgoing []*CRMember,
+	height uint32) *StateKeyFrame {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
-	for _, v := range dids {
+	for _, v := range elected {
 		if _, ok := s.ActivityCandidates[v]; !ok {
 			log.Warnf("not found active candidate %s when finish voting",
 				v.String())
 		}
 		delete(s.ActivityCandidates, v)
 	}
+
+	for _, v := range s.PendingCandidates {
+		v.votes = 0
+	}
+	for _, v := range s.ActivityCandidates {
+		v.votes = 0
+	}
+	s.Votes = make(map[string]*types.Output)
+	s.NicknameCommitments = make(map[common.Uint256]uint32)
+	s.votesCache.reset()
+
+	for _, m := range outgoing {
+		s.carryOverMemberDeposit(m, height)
+	}
 	s.history = utils.NewHistory(maxHistoryCapacity)
 
 	result := s.StateKeyFrame.Snapshot()
 	return result
 }
 
+// carryOverMemberDeposit keeps deposit of an outgoing committee member in
+// state, if the member has registered as candidate again the deposit and
+// penalty will be merged into the candidate, otherwise the member will be
+// recorded as a canceled candidate.
+func (s *State) carryOverMemberDeposit(member *CRMember, height uint32) {
+	if c := s.getCandidateByCID(member.Info.CID); c != nil {
+		c.depositAmount += member.DepositAmount
+		c.penalty += member.Penalty
+		return
+	}
+
+	s.CodeCIDMap[common.BytesToHexString(member.Info.Code)] = member.Info.CID
+	s.DepositHashMap[member.DepositHash] = struct{}{}
+	s.CanceledCandidates[member.Info.CID] = &Candidate{
+		info:          member.Info,
+		state:         Canceled,
+		cancelHeight:  height,
+		depositAmount: member.DepositAmount,
+		depositHash:   member.DepositHash,
+		penalty:       member.Penalty,
+	}
+}
+
 // processTransactions takes the transactions and the height when they have been
 // packed into a block.  Then loop through the transactions to update CR
 // state and votes according to transactions content.
 func (s *State) processTransactions(txs []*types.Transaction, height uint32) {
-	// Remove cached votes
-	if len(s.votesCacheKeys) >= CacheCRVotesSize {
-		for k, v := range s.votesCacheKeys {
-			if k <= height-CacheCRVotesSize {
-				for _, referKey := range v {
-					delete(s.votesCache, referKey)
-				}
-				delete(s.votesCacheKeys, k)
-			}
-		}
-	}
+	// Remove expired cached votes
+	s.votesCache.expire(height)
 
 	for _, tx := range txs {
 		s.processTransaction(tx, height)
@@ -275,6 +537,41 @@ func (s *State) processTransactions(txs []*types.Transaction, height uint32) {
 			}
 		}
 	}
+
+	s.removeExpiredNicknameCommitments(height)
+}
+
+// removeExpiredNicknameCommitments removes the CR nickname commitments which
+// can not be revealed after the height.
+func (s *State) removeExpiredNicknameCommitments(height uint32) {
+	for commitment, expiry := range s.NicknameCommitments {
+		if height >= expiry {
+			s.deleteNicknameCommitment(commitment, expiry, height)
+		}
+	}
+}
+
+// deleteNicknameCommitment removes a revealed or expired CR nickname
+// commitment.
+func (s *State) deleteNicknameCommitment(commitment common.Uint256,
+	expiry uint32, height uint32) {
+	s.history.Append(height, func() {
+		delete(s.NicknameCommitments, commitment)
+	}, func() {
+		s.NicknameCommitments[commitment] = expiry
+	})
+}
+
+// commitNickname handles the CR nickname commit transaction, the commitment
+// can be revealed in CRNicknameCommitExpiry blocks.
+func (s *State) commitNickname(p *payload.CRNicknameCommit, height uint32) {
+	commitment := p.Commitment
+	expiry := height + s.params.CRNicknameCommitExpiry
+	s.history.Append(height, func() {
+		s.NicknameCommitments[commitment] = expiry
+	}, func() {
+		delete(s.NicknameCommitments, commitment)
+	})
 }
 
 // processTransaction take a transaction and the height it has been packed into
@@ -286,7 +583,7 @@ func (s *State) processTransaction(tx *types.Transaction, height uint32) {
 		s.registerCR(tx, height)
 
 	case types.UpdateCR:
-		s.updateCR(tx.Payload.(*payload.CRInfo), height)
+		s.updateCR(tx, height)
 
 	case types.UnregisterCR:
 		s.unregisterCR(tx.Payload.(*payload.UnregisterCR), height)
@@ -298,6 +595,15 @@ func (s *State) processTransaction(tx *types.Transaction, height uint32) {
 	case types.ReturnCRDepositCoin:
 		s.returnDeposit(tx, height)
 		s.processDeposit(tx, height)
+
+	case types.CustomIDProposal:
+		s.processCustomIDProposal(tx.Payload.(*payload.CustomIDProposal), height)
+
+	case types.RevokeVote:
+		s.processRevokeVotes(tx, height)
+
+	case types.CRNicknameCommit:
+		s.commitNickname(tx.Payload.(*payload.CRNicknameCommit), height)
 	}
 
 	s.processCancelVotes(tx, height)
@@ -328,15 +634,23 @@ func (s *State) registerCR(tx *types.Transaction, height uint32) {
 	}
 	candidate.depositAmount = amount
 
+	if tx.PayloadVersion >= payload.CRInfoRevealVersion {
+		commitment := payload.CRNicknameCommitment(nickname, info.Salt,
+			info.CID)
+		if expiry, ok := s.NicknameCommitments[commitment]; ok {
+			s.deleteNicknameCommitment(commitment, expiry, height)
+		}
+	}
+
 	c := s.getCandidateByCID(info.CID)
 	if c == nil {
 		s.history.Append(height, func() {
-			s.Nicknames[nickname] = struct{}{}
+			s.addNickname(nickname)
 			s.CodeCIDMap[code] = info.CID
 			s.DepositHashMap[candidate.depositHash] = struct{}{}
 			s.PendingCandidates[info.CID] = &candidate
 		}, func() {
-			delete(s.Nicknames, nickname)
+			s.removeNickname(nickname)
 			delete(s.CodeCIDMap, code)
 			delete(s.DepositHashMap, candidate.depositHash)
 			delete(s.PendingCandidates, info.CID)
@@ -345,11 +659,11 @@ func (s *State) registerCR(tx *types.Transaction, height uint32) {
 		candidate.votes = c.votes
 		s.history.Append(height, func() {
 			delete(s.CanceledCandidates, c.Info().CID)
-			s.Nicknames[nickname] = struct{}{}
+			s.addNickname(nickname)
 			s.PendingCandidates[info.CID] = &candidate
 		}, func() {
 			delete(s.PendingCandidates, info.CID)
-			delete(s.Nicknames, nickname)
+			s.removeNickname(nickname)
 			s.CanceledCandidates[c.Info().CID] = c
 		})
 	}
@@ -357,9 +671,27 @@ func (s *State) registerCR(tx *types.Transaction, height uint32) {
 }
 
 // updateCR handles the update CR transaction.
-func (s *State) updateCR(info *payload.CRInfo, height uint32) {
+func (s *State) updateCR(tx *types.Transaction, height uint32) {
+	info := tx.Payload.(*payload.CRInfo)
 	candidate := s.getCandidateByCID(info.CID)
 	crInfo := candidate.info
+	change := &CandidateInfoChange{
+		OldNickName: crInfo.NickName,
+		NewNickName: info.NickName,
+		OldUrl:      crInfo.Url,
+		NewUrl:      info.Url,
+		OldLocation: crInfo.Location,
+		NewLocation: info.Location,
+		Height:      height,
+		TxHash:      tx.Hash(),
+	}
+	buf := new(bytes.Buffer)
+	if err := change.Serialize(buf); err != nil {
+		log.Error("[updateCR] serialize candidate info change error: ", err)
+	} else {
+		s.pendingCandidateChanges = append(s.pendingCandidateChanges,
+			utils.HistoryRecord{Key: info.CID.Bytes(), Value: buf.Bytes()})
+	}
 	s.history.Append(height, func() {
 		s.updateCandidateInfo(&crInfo, info)
 	}, func() {
@@ -384,7 +716,7 @@ func (s *State) unregisterCR(info *payload.UnregisterCR, height uint32) {
 		} else {
 			delete(s.ActivityCandidates, key)
 		}
-		delete(s.Nicknames, candidate.info.NickName)
+		s.removeNickname(candidate.info.NickName)
 	}, func() {
 		candidate.cancelHeight = 0
 		delete(s.CanceledCandidates, key)
@@ -395,10 +727,56 @@ func (s *State) unregisterCR(info *payload.UnregisterCR, height uint32) {
 			candidate.state = Active
 			s.ActivityCandidates[key] = candidate
 		}
-		s.Nicknames[candidate.info.NickName] = struct{}{}
+		s.addNickname(candidate.info.NickName)
 	})
 }
 
+// processCustomIDProposal handles the custom ID proposal transaction.
+func (s *State) processCustomIDProposal(p *payload.CustomIDProposal,
+	height uint32) {
+	switch p.ProposalType {
+	case payload.ReserveCustomID:
+		for _, v := range p.CustomIDs {
+			if _, ok := s.ReservedCustomIDs[v]; ok {
+				continue
+			}
+			id := v
+			s.history.Append(height, func() {
+				s.ReservedCustomIDs[id] = struct{}{}
+			}, func() {
+				delete(s.ReservedCustomIDs, id)
+			})
+		}
+
+	case payload.BanCustomID:
+		for _, v := range p.CustomIDs {
+			if _, ok := s.BannedCustomIDs[v]; ok {
+				continue
+			}
+			id := v
+			s.history.Append(height, func() {
+				s.BannedCustomIDs[id] = struct{}{}
+			}, func() {
+				delete(s.BannedCustomIDs, id)
+			})
+		}
+
+	case payload.ChangeCustomIDFee:
+		effectiveHeight := p.EffectiveHeight
+		rate := p.FeeRate
+		origin, exist := s.CustomIDFeeRates[effectiveHeight]
+		s.history.Append(height, func() {
+			s.CustomIDFeeRates[effectiveHeight] = rate
+		}, func() {
+			if exist {
+				s.CustomIDFeeRates[effectiveHeight] = origin
+			} else {
+				delete(s.CustomIDFeeRates, effectiveHeight)
+			}
+		})
+	}
+}
+
 // updateCandidateInfo updates the candidate's info with value compare,
 // any change will be updated.
 func (s *State) updateCandidateInfo(origin *payload.CRInfo, update *payload.CRInfo) {
@@ -406,8 +784,8 @@ func (s *State) updateCandidateInfo(origin *payload.CRInfo, update *payload.CRIn
 
 	// compare and update node nickname.
 	if origin.NickName != update.NickName {
-		delete(s.Nicknames, origin.NickName)
-		s.Nicknames[update.NickName] = struct{}{}
+		s.removeNickname(origin.NickName)
+		s.addNickname(update.NickName)
 	}
 
 	candidate.info = *update
@@ -418,24 +796,9 @@ func (s *State) updateCandidateInfo(origin *payload.CRInfo, update *payload.CRIn
 func (s *State) processVotes(tx *types.Transaction, height uint32) {
 	if tx.Version >= types.TxVersion09 {
 		for i, output := range tx.Outputs {
-			if output.Type != types.OTVote {
-				continue
-			}
-			p, _ := output.Payload.(*outputpayload.VoteOutput)
-			if p.Version < outputpayload.VoteProducerAndCRVersion {
-				continue
-			}
-			var exist bool
-			for _, content := range p.Contents {
-				if content.VoteType == outputpayload.CRC {
-					exist = true
-					break
-				}
-			}
-			if exist {
-				op := types.NewOutPoint(tx.Hash(), uint16(i))
-				s.Votes[op.ReferKey()] = output
-				s.processVoteOutput(output, height)
+			if hook, ok := types.MatchOutputStateHook(
+				outputHookModule, output); ok {
+				hook.Process(s, tx, i, height)
 			}
 		}
 	}
@@ -461,14 +824,14 @@ func (s *State) returnDeposit(tx *types.Transaction, height uint32) {
 	}
 
 	returnAction := func(candidate *Candidate, originState CandidateState) {
+		s.appendAmountChange(height, candidate, &candidate.depositAmount,
+			-inputValue, "deposit amount")
 		s.history.Append(height, func() {
-			candidate.depositAmount -= inputValue
 			candidate.state = Returned
-			delete(s.Nicknames, candidate.info.NickName)
+			s.removeNickname(candidate.info.NickName)
 		}, func() {
-			candidate.depositAmount += inputValue
 			candidate.state = originState
-			s.Nicknames[candidate.info.NickName] = struct{}{}
+			s.addNickname(candidate.info.NickName)
 		})
 	}
 
@@ -483,16 +846,23 @@ func (s *State) returnDeposit(tx *types.Transaction, height uint32) {
 // program hash of transaction output.
 func (s *State) addCandidateAssert(output *types.Output, height uint32) bool {
 	if candidate := s.getCandidateByDepositHash(output.ProgramHash); candidate != nil {
-		s.history.Append(height, func() {
-			candidate.depositAmount += output.Value
-		}, func() {
-			candidate.depositAmount -= output.Value
-		})
+		s.appendAmountChange(height, candidate, &candidate.depositAmount,
+			output.Value, "deposit amount")
 		return true
 	}
 	return false
 }
 
+// appendAmountChange adds delta to the votes or deposit amount of the
+// candidate, the change is refused and logged if the result overflows.
+func (s *State) appendAmountChange(height uint32, candidate *Candidate,
+	amount *common.Fixed64, delta common.Fixed64, name string) {
+	s.history.AppendAmountChange(height, amount, delta, func(err error) {
+		log.Errorf("refused to add %s to %s %s of CR candidate %s, %s",
+			delta, name, *amount, candidate.info.CID, err)
+	})
+}
+
 // getCandidateByDepositHash will try to get candidate with specified program
 // hash.
 func (s *State) getCandidateByDepositHash(hash common.Uint168) *Candidate {
@@ -530,12 +900,8 @@ func (s *State) processVoteOutput(output *types.Output, height uint32) {
 
 			switch vote.VoteType {
 			case outputpayload.CRC:
-				v := cv.Votes
-				s.history.Append(height, func() {
-					candidate.votes += v
-				}, func() {
-					candidate.votes -= v
-				})
+				s.appendAmountChange(height, candidate, &candidate.votes,
+					cv.Votes, "votes")
 			}
 		}
 	}
@@ -549,24 +915,40 @@ func (s *State) processCancelVotes(tx *types.Transaction, height uint32) {
 		output, ok := s.Votes[referKey]
 		if ok {
 			if output == nil {
-				output, ok = s.votesCache[referKey]
+				output, ok = s.votesCache.get(referKey)
 				if !ok {
 					log.Errorf("invalid votes output")
 					return
 				}
 			}
 			s.processVoteCancel(output, height)
-			if _, exist := s.votesCacheKeys[height]; !exist {
-				s.votesCacheKeys[height] = make([]string, 0)
-			}
-			s.votesCacheKeys[height] = append(s.votesCacheKeys[height], referKey)
-			s.votesCache[referKey] = output
+			s.votesCache.add(referKey, output, height)
 
 			s.Votes[referKey] = nil
 		}
 	}
 }
 
+// processRevokeVotes takes a revoke vote transaction and subtracts the votes
+// of revoked vote outputs, the outputs are removed from votes so that they
+// will not be canceled again when spent.
+func (s *State) processRevokeVotes(tx *types.Transaction, height uint32) {
+	p := tx.Payload.(*payload.RevokeVote)
+	for _, v := range p.Votes {
+		referKey := types.NewOutPoint(v.TxID, v.Index).ReferKey()
+		output := s.Votes[referKey]
+		if output == nil {
+			continue
+		}
+		s.processVoteCancel(output, height)
+		s.history.Append(height, func() {
+			delete(s.Votes, referKey)
+		}, func() {
+			s.Votes[referKey] = output
+		})
+	}
+}
+
 // processVoteCancel takes a previous vote output and decrease CR votes.
 func (s *State) processVoteCancel(output *types.Output, height uint32) {
 	p := output.Payload.(*outputpayload.VoteOutput)
@@ -582,12 +964,8 @@ func (s *State) processVoteCancel(output *types.Output, height uint32) {
 			}
 			switch vote.VoteType {
 			case outputpayload.CRC:
-				v := cv.Votes
-				s.history.Append(height, func() {
-					candidate.votes -= v
-				}, func() {
-					candidate.votes += v
-				})
+				s.appendAmountChange(height, candidate, &candidate.votes,
+					-cv.Votes, "votes")
 			}
 		}
 	}
@@ -599,10 +977,10 @@ func (s *State) getCandidateByID(id common.Uint168) *Candidate {
 			return s.getCandidateByCID(v)
 		}
 		code, _ := common.HexStringToBytes(k)
-		newCode := make([]byte, len(code))
-		copy(newCode, code)
-		didCode := append(newCode[:len(newCode)-1], common.DID)
-		ct, _ := contract.CreateCRIDContractByCode(didCode)
+		ct, err := contract.CreateDIDContractByCode(code)
+		if err != nil {
+			continue
+		}
 		did := ct.ToProgramHash()
 		if did.IsEqual(id) {
 			return s.getCandidateByCID(v)
@@ -692,11 +1070,17 @@ func (s *State) getCandidateFromMap(cmap map[common.Uint168]*Candidate,
 }
 
 func NewState(chainParams *config.Params) *State {
+	var cacheSize int
+	if chainParams != nil {
+		cacheSize = chainParams.CRVotesCacheSize
+	}
 	return &State{
-		StateKeyFrame:  *NewStateKeyFrame(),
-		params:         chainParams,
-		history:        utils.NewHistory(maxHistoryCapacity),
-		votesCacheKeys: make(map[uint32][]string),
-		votesCache:     make(map[string]*types.Output),
+		StateKeyFrame: *NewStateKeyFrame(),
+		params:        chainParams,
+		history:       utils.NewHistory(maxHistoryCapacity),
+		votesCache:    newVotesCache(cacheSize),
+
+		foldedNicknames:    make(map[string]int),
+		candidateHistories: utils.NewMemHistoryIndex(),
 	}
 }
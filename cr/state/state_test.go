@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/contract"
 	"github.com/elastos/Elastos.ELA/core/contract/program"
 	"github.com/elastos/Elastos.ELA/core/types"
@@ -95,6 +96,30 @@ func TestState_ExistCandidateRelated(t *testing.T) {
 	}
 }
 
+func TestState_GetDepositsRelated(t *testing.T) {
+	keyFrame := *randomStateKeyFrame(5, true)
+	state := State{
+		StateKeyFrame: keyFrame,
+	}
+	for _, c := range state.GetAllCandidates() {
+		c.depositAmount = 5000 * 1e8
+	}
+
+	assert.Equal(t, common.Fixed64(5*5000*1e8), state.GetTotalDeposits(Pending))
+	assert.Equal(t, common.Fixed64(5*5000*1e8), state.GetTotalDeposits(Active))
+	assert.Equal(t, common.Fixed64(3*5000*1e8), state.GetTotalDeposits(Canceled))
+	assert.Equal(t, common.Fixed64(2*5000*1e8), state.GetTotalDeposits(Returned))
+
+	deposits := state.GetDeposits(Active)
+	assert.Equal(t, 5, len(deposits))
+	for _, c := range keyFrame.ActivityCandidates {
+		assert.Equal(t, c.depositAmount, deposits[c.depositHash])
+		assert.True(t, candidateEqual(c,
+			state.GetCandidateByDepositHash(c.depositHash)))
+	}
+	assert.Nil(t, state.GetCandidateByDepositHash(*randomUint168()))
+}
+
 func getCode(publicKey string) []byte {
 	pkBytes, _ := common.HexStringToBytes(publicKey)
 	pk, _ := crypto.DecodePoint(pkBytes)
@@ -342,6 +367,78 @@ func TestState_ProcessBlock_VotingAndCancel(t *testing.T) {
 	}
 }
 
+func TestState_ProcessBlock_RevokeVote(t *testing.T) {
+	keyframe := randomStateKeyFrame(5, true)
+	state := NewState(nil)
+	state.StateKeyFrame = *keyframe
+	state.history = utils.NewHistory(maxHistoryCapacity)
+
+	activeCodes := make([][]byte, 0, 5)
+	for _, v := range keyframe.ActivityCandidates {
+		v.votes = 0
+		activeCodes = append(activeCodes, v.info.Code)
+	}
+
+	// vote for the active candidates
+	voteTx := mockNewVoteTx(activeCodes)
+	state.ProcessBlock(&types.Block{
+		Header: types.Header{
+			Height: 1,
+		},
+		Transactions: []*types.Transaction{voteTx},
+	}, nil)
+	referKey := types.NewOutPoint(voteTx.Hash(), 0).ReferKey()
+	assert.NotNil(t, state.GetVoteOutput(referKey))
+
+	// revoke the votes without spending the vote output
+	state.ProcessBlock(&types.Block{
+		Header: types.Header{
+			Height: 2,
+		},
+		Transactions: []*types.Transaction{
+			{
+				TxType: types.RevokeVote,
+				Payload: &payload.RevokeVote{
+					Votes: []payload.RevokedVote{
+						{TxID: voteTx.Hash(), Index: 0},
+					},
+				},
+			},
+		},
+	}, nil)
+	assert.Nil(t, state.GetVoteOutput(referKey))
+	for _, v := range activeCodes {
+		assert.Equal(t, common.Fixed64(0), state.GetCandidate(v).votes)
+	}
+
+	// spend the revoked vote output will not cancel the votes again
+	state.ProcessBlock(&types.Block{
+		Header: types.Header{
+			Height: 3,
+		},
+		Transactions: []*types.Transaction{
+			{
+				Inputs: []*types.Input{
+					{
+						Previous: *types.NewOutPoint(voteTx.Hash(), 0),
+					},
+				},
+			},
+		},
+	}, nil)
+	for _, v := range activeCodes {
+		assert.Equal(t, common.Fixed64(0), state.GetCandidate(v).votes)
+	}
+
+	// rollback the revoke vote transaction
+	assert.NoError(t, state.RollbackTo(1))
+	assert.NotNil(t, state.GetVoteOutput(referKey))
+	for i, v := range activeCodes {
+		assert.Equal(t, common.Fixed64((i+1)*10),
+			state.GetCandidate(v).votes)
+	}
+}
+
 func TestState_ProcessBlock_DepositAndReturnDeposit(t *testing.T) {
 	state := NewState(nil)
 	height := uint32(1)
@@ -493,6 +590,210 @@ func generateRegisterCR(code []byte, cid common.Uint168,
 	}
 }
 
+func TestState_ProcessBlock_CustomIDProposal(t *testing.T) {
+	state := NewState(nil)
+	reserved := randomString()
+	banned := randomString()
+
+	// reserve and ban custom IDs
+	state.ProcessBlock(&types.Block{
+		Header: types.Header{
+			Height: 1,
+		},
+		Transactions: []*types.Transaction{
+			generateCustomIDProposal(payload.ReserveCustomID,
+				[]string{reserved}, 0, 0),
+			generateCustomIDProposal(payload.BanCustomID,
+				[]string{banned}, 0, 0),
+		},
+	}, nil)
+	assert.True(t, state.IsCustomIDReserved(reserved))
+	assert.False(t, state.IsCustomIDBanned(reserved))
+	assert.True(t, state.IsCustomIDBanned(banned))
+	assert.False(t, state.IsCustomIDReserved(banned))
+
+	// change custom ID fee rate
+	state.ProcessBlock(&types.Block{
+		Header: types.Header{
+			Height: 2,
+		},
+		Transactions: []*types.Transaction{
+			generateCustomIDProposal(payload.ChangeCustomIDFee,
+				nil, 100, 10),
+		},
+	}, nil)
+	assert.Equal(t, common.Fixed64(0), state.GetCustomIDFeeRate(9))
+	assert.Equal(t, common.Fixed64(100), state.GetCustomIDFeeRate(10))
+
+	state.ProcessBlock(&types.Block{
+		Header: types.Header{
+			Height: 3,
+		},
+		Transactions: []*types.Transaction{
+			generateCustomIDProposal(payload.ChangeCustomIDFee,
+				nil, 200, 20),
+		},
+	}, nil)
+	assert.Equal(t, common.Fixed64(100), state.GetCustomIDFeeRate(19))
+	assert.Equal(t, common.Fixed64(200), state.GetCustomIDFeeRate(20))
+
+	// rollback
+	assert.NoError(t, state.RollbackTo(2))
+	assert.Equal(t, common.Fixed64(100), state.GetCustomIDFeeRate(20))
+
+	assert.NoError(t, state.RollbackTo(0))
+	assert.False(t, state.IsCustomIDReserved(reserved))
+	assert.False(t, state.IsCustomIDBanned(banned))
+	assert.Equal(t, common.Fixed64(0), state.GetCustomIDFeeRate(20))
+}
+
+func TestState_ProcessBlock_UpdateCRHistory(t *testing.T) {
+	state := NewState(nil)
+	publicKeyStr1 := "03c77af162438d4b7140f8544ad6523b9734cca9c7a62476d54ed5d1bddc7a39c3"
+	code := getCode(publicKeyStr1)
+	cid := *getCID(code)
+	nickname := randomString()
+
+	state.ProcessBlock(&types.Block{
+		Header: types.Header{
+			Height: 1,
+		},
+		Transactions: []*types.Transaction{
+			generateRegisterCR(code, cid, nickname),
+		},
+	}, nil)
+	history, err := state.GetCandidateHistory(cid)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(history))
+
+	// update CR twice
+	nickname2 := randomString()
+	update1 := generateUpdateCR(code, cid, nickname2)
+	state.ProcessBlock(&types.Block{
+		Header: types.Header{
+			Height: 2,
+		},
+		Transactions: []*types.Transaction{update1},
+	}, nil)
+
+	nickname3 := randomString()
+	update2 := generateUpdateCR(code, cid, nickname3)
+	state.ProcessBlock(&types.Block{
+		Header: types.Header{
+			Height: 3,
+		},
+		Transactions: []*types.Transaction{update2},
+	}, nil)
+
+	history, err = state.GetCandidateHistory(cid)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(history))
+	assert.Equal(t, nickname, history[0].OldNickName)
+	assert.Equal(t, nickname2, history[0].NewNickName)
+	assert.Equal(t, uint32(2), history[0].Height)
+	assert.Equal(t, update1.Hash(), history[0].TxHash)
+	assert.Equal(t, nickname2, history[1].OldNickName)
+	assert.Equal(t, nickname3, history[1].NewNickName)
+	assert.Equal(t, uint32(3), history[1].Height)
+	assert.Equal(t, update2.Hash(), history[1].TxHash)
+
+	// rollback
+	assert.NoError(t, state.RollbackTo(2))
+	history, err = state.GetCandidateHistory(cid)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(history))
+	assert.Equal(t, nickname2, history[0].NewNickName)
+
+	assert.NoError(t, state.RollbackTo(1))
+	history, err = state.GetCandidateHistory(cid)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(history))
+}
+
+func TestState_ProcessBlock_NicknameCommitment(t *testing.T) {
+	params := config.DefaultParams
+	params.CRNicknameCommitExpiry = 5
+	state := NewState(&params)
+	code := getCode("03c77af162438d4b7140f8544ad6523b9734cca9c7a62476d54ed5d1bddc7a39c3")
+	cid := *getCID(code)
+	nickname := randomString()
+	salt := randomBytes(payload.MinCRNicknameSaltLength)
+	commitment := payload.CRNicknameCommitment(nickname, salt, cid)
+	expiring := *randomUint256()
+
+	// commit the nicknames
+	state.ProcessBlock(&types.Block{
+		Header: types.Header{
+			Height: 1,
+		},
+		Transactions: []*types.Transaction{
+			generateNicknameCommit(commitment),
+			generateNicknameCommit(expiring),
+		},
+	}, nil)
+	expiry, ok := state.GetNicknameCommitExpiry(commitment)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(6), expiry)
+
+	// reveal the nickname by register CR
+	register := generateRegisterCR(code, cid, nickname)
+	register.PayloadVersion = payload.CRInfoRevealVersion
+	register.Payload.(*payload.CRInfo).Salt = salt
+	state.ProcessBlock(&types.Block{
+		Header: types.Header{
+			Height: 2,
+		},
+		Transactions: []*types.Transaction{register},
+	}, nil)
+	_, ok = state.GetNicknameCommitExpiry(commitment)
+	assert.False(t, ok)
+	assert.True(t, state.ExistCandidateByNickname(nickname))
+
+	// the commitment not revealed expires
+	for height := uint32(3); height <= 6; height++ {
+		_, ok = state.GetNicknameCommitExpiry(expiring)
+		assert.True(t, ok)
+		state.ProcessBlock(&types.Block{
+			Header: types.Header{
+				Height: height,
+			},
+		}, nil)
+	}
+	_, ok = state.GetNicknameCommitExpiry(expiring)
+	assert.False(t, ok)
+
+	// rollback
+	assert.NoError(t, state.RollbackTo(5))
+	_, ok = state.GetNicknameCommitExpiry(expiring)
+	assert.True(t, ok)
+
+	assert.NoError(t, state.RollbackTo(1))
+	_, ok = state.GetNicknameCommitExpiry(commitment)
+	assert.True(t, ok)
+	assert.False(t, state.ExistCandidateByNickname(nickname))
+}
+
+func generateNicknameCommit(commitment common.Uint256) *types.Transaction {
+	return &types.Transaction{
+		TxType:  types.CRNicknameCommit,
+		Payload: &payload.CRNicknameCommit{Commitment: commitment},
+	}
+}
+
+func generateCustomIDProposal(proposalType payload.CustomIDProposalType,
+	ids []string, rate common.Fixed64,
+	effectiveHeight uint32) *types.Transaction {
+	return &types.Transaction{
+		TxType: types.CustomIDProposal,
+		Payload: &payload.CustomIDProposal{
+			ProposalType:    proposalType,
+			CustomIDs:       ids,
+			FeeRate:         rate,
+			EffectiveHeight: effectiveHeight,
+		},
+	}
+}
+
 func generateUpdateCR(code []byte, cid common.Uint168,
 	nickname string) *types.Transaction {
 	return &types.Transaction{
@@ -530,3 +831,42 @@ func generateReturnCRDeposit(code []byte) *types.Transaction {
 		},
 	}
 }
+
+func TestState_CheckInvariants(t *testing.T) {
+	state := NewState(nil)
+
+	_, pk, _ := crypto.GenerateKeyPair()
+	cont, _ := contract.CreateStandardContract(pk)
+	code := cont.Code
+	depositCont, _ := contract.CreateDepositContractByPubKey(pk)
+
+	registerCRTx := &types.Transaction{
+		TxType: types.RegisterCR,
+		Payload: &payload.CRInfo{
+			Code:     code,
+			CID:      *getCID(code),
+			NickName: randomString(),
+		},
+		Outputs: []*types.Output{
+			{
+				ProgramHash: *depositCont.ToProgramHash(),
+				Value:       common.Fixed64(100),
+			},
+		},
+	}
+	state.ProcessBlock(&types.Block{
+		Header:       types.Header{Height: 1},
+		Transactions: []*types.Transaction{registerCRTx},
+	}, nil)
+	assert.NoError(t, state.CheckInvariants())
+
+	// deposit amount not matching deposit outputs
+	candidate := state.GetCandidate(code)
+	candidate.depositAmount += 1
+	assert.Error(t, state.CheckInvariants())
+	candidate.depositAmount -= 1
+
+	// votes not matching vote outputs
+	candidate.votes = 1
+	assert.Error(t, state.CheckInvariants())
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package state
+
+import (
+	"container/list"
+
+	"github.com/elastos/Elastos.ELA/core/types"
+)
+
+const (
+	// CacheCRVotesSize indicates the number of heights a canceled vote output
+	// is kept in the votes cache, it should cover the blocks can be rolled
+	// back.
+	CacheCRVotesSize = 6
+
+	// defaultVotesCacheSize is the maximum number of vote outputs kept in the
+	// votes cache if CRVotesCacheSize is not set.
+	defaultVotesCacheSize = 100000
+
+	// minVotesCacheSize is the minimum number of vote outputs kept in the
+	// votes cache, a smaller CRVotesCacheSize is raised to it.
+	minVotesCacheSize = 10000
+)
+
+// VotesCacheStats is the statistics of the votes cache.
+type VotesCacheStats struct {
+	Size        int
+	Limit       int
+	Hits        uint64
+	Misses      uint64
+	Expirations uint64
+	Evictions   uint64
+}
+
+// votesCacheEntry is a vote output in the votes cache.
+type votesCacheEntry struct {
+	referKey string
+	output   *types.Output
+	height   uint32
+}
+
+// votesCache keeps the canceled vote outputs so that they can be canceled
+// again when the spending block is rolled back and reconnected.  Outputs are
+// expired CacheCRVotesSize heights after they are canceled.  The number of
+// outputs is limited with eviction for the earliest canceled output when the
+// limit is exceeded, but outputs within the rollback window are never evicted
+// since the CR votes depend on them, so the limit may be exceeded until they
+// are expired.
+//
+// This is not safe for concurrent access, it should be used with state mutex
+// held.
+type votesCache struct {
+	outputs map[string]*list.Element // nearly O(1) lookups
+	entries *list.List               // ordered by canceled height, newest first
+	limit   int
+
+	hits        uint64
+	misses      uint64
+	expirations uint64
+	evictions   uint64
+}
+
+// get returns the vote output of the refer key.
+func (c *votesCache) get(referKey string) (*types.Output, bool) {
+	node, ok := c.outputs[referKey]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	return node.Value.(*votesCacheEntry).output, true
+}
+
+// add adds the vote output of the refer key canceled at the height and handles
+// eviction of the earliest canceled output if adding it would exceed the
+// limit and the output is out of the rollback window.
+func (c *votesCache) add(referKey string, output *types.Output,
+	height uint32) {
+	if node, ok := c.outputs[referKey]; ok {
+		entry := node.Value.(*votesCacheEntry)
+		entry.output, entry.height = output, height
+		c.entries.MoveToFront(node)
+		return
+	}
+
+	if node := c.entries.Back(); node != nil && len(c.outputs)+1 > c.limit &&
+		node.Value.(*votesCacheEntry).height+CacheCRVotesSize <= height {
+		delete(c.outputs, node.Value.(*votesCacheEntry).referKey)
+		c.evictions++
+
+		// Reuse the list node of the evicted output.
+		node.Value = &votesCacheEntry{referKey: referKey, output: output,
+			height: height}
+		c.entries.MoveToFront(node)
+		c.outputs[referKey] = node
+		return
+	}
+
+	c.outputs[referKey] = c.entries.PushFront(&votesCacheEntry{
+		referKey: referKey, output: output, height: height})
+}
+
+// expire removes the outputs canceled CacheCRVotesSize or more heights before
+// the given height.
+func (c *votesCache) expire(height uint32) {
+	if height < CacheCRVotesSize {
+		return
+	}
+	for node := c.entries.Back(); node != nil; node = c.entries.Back() {
+		entry := node.Value.(*votesCacheEntry)
+		if entry.height > height-CacheCRVotesSize {
+			return
+		}
+		delete(c.outputs, entry.referKey)
+		c.entries.Remove(node)
+		c.expirations++
+	}
+}
+
+// reset removes all outputs, the statistics are kept.
+func (c *votesCache) reset() {
+	c.outputs = make(map[string]*list.Element)
+	c.entries.Init()
+}
+
+func (c *votesCache) stats() VotesCacheStats {
+	return VotesCacheStats{
+		Size:        len(c.outputs),
+		Limit:       c.limit,
+		Hits:        c.hits,
+		Misses:      c.misses,
+		Expirations: c.expirations,
+		Evictions:   c.evictions,
+	}
+}
+
+// newVotesCache returns a votes cache limited to the number of outputs
+// specified by limit, defaultVotesCacheSize will be used if limit is not
+// positive, and minVotesCacheSize will be used if limit is less than it.
+func newVotesCache(limit int) *votesCache {
+	if limit <= 0 {
+		limit = defaultVotesCacheSize
+	} else if limit < minVotesCacheSize {
+		log.Warnf("CR votes cache size %d is less than %d, use %d instead",
+			limit, minVotesCacheSize, minVotesCacheSize)
+		limit = minVotesCacheSize
+	}
+	return &votesCache{
+		outputs: make(map[string]*list.Element),
+		entries: list.New(),
+		limit:   limit,
+	}
+}
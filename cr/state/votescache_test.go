@@ -0,0 +1,81 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package state
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVotesCache(t *testing.T) {
+	cache := newVotesCache(minVotesCacheSize)
+	cache.limit = 2
+	output1 := &types.Output{Value: common.Fixed64(1)}
+	output2 := &types.Output{Value: common.Fixed64(2)}
+	output3 := &types.Output{Value: common.Fixed64(3)}
+
+	cache.add("key1", output1, 10)
+	cache.add("key2", output2, 11)
+	output, ok := cache.get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, output1, output)
+
+	// key1 is within the rollback window and should not be evicted
+	cache.add("key3", output3, 12)
+	output, ok = cache.get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, output1, output)
+	output, ok = cache.get("key3")
+	assert.True(t, ok)
+	assert.Equal(t, output3, output)
+
+	// adding an existing key should not evict
+	cache.add("key3", output2, 12)
+	output, ok = cache.get("key3")
+	assert.True(t, ok)
+	assert.Equal(t, output2, output)
+
+	// key1 is out of the rollback window and should be evicted
+	cache.add("key4", output3, 10+CacheCRVotesSize)
+	_, ok = cache.get("key1")
+	assert.False(t, ok)
+
+	// outputs are kept until CacheCRVotesSize heights passed
+	cache.expire(11 + CacheCRVotesSize - 1)
+	_, ok = cache.get("key2")
+	assert.True(t, ok)
+	cache.expire(11 + CacheCRVotesSize)
+	_, ok = cache.get("key2")
+	assert.False(t, ok)
+	_, ok = cache.get("key3")
+	assert.True(t, ok)
+
+	assert.Equal(t, VotesCacheStats{
+		Size:        2,
+		Limit:       2,
+		Hits:        6,
+		Misses:      2,
+		Expirations: 1,
+		Evictions:   1,
+	}, cache.stats())
+
+	// reset keeps the statistics
+	cache.reset()
+	_, ok = cache.get("key3")
+	assert.False(t, ok)
+	stats := cache.stats()
+	assert.Equal(t, 0, stats.Size)
+	assert.Equal(t, uint64(3), stats.Misses)
+	assert.Equal(t, uint64(1), stats.Evictions)
+
+	// default and minimum limit
+	assert.Equal(t, defaultVotesCacheSize, newVotesCache(0).limit)
+	assert.Equal(t, minVotesCacheSize, newVotesCache(2).limit)
+}
@@ -7,6 +7,7 @@ package crypto
 
 import (
 	"errors"
+	"sort"
 
 	"github.com/elastos/Elastos.ELA/common"
 )
@@ -99,3 +100,19 @@ func ComputeParent(left common.Uint256, right common.Uint256) common.Uint256 {
 	copy(sha[32:], right[:])
 	return common.Uint256(common.Sha256D(sha[:]))
 }
+
+// ComputeSortedRoot sorts the hashes and calculates the merkle root of them,
+// so the result does not depend on the order of the input. An empty input
+// results in an empty hash.
+func ComputeSortedRoot(hashes []common.Uint256) common.Uint256 {
+	if len(hashes) == 0 {
+		return common.Uint256{}
+	}
+	sorted := make([]common.Uint256, len(hashes))
+	copy(sorted, hashes)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Compare(sorted[j]) < 0
+	})
+	root, _ := ComputeRoot(sorted)
+	return root
+}
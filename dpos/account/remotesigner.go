@@ -0,0 +1,394 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package account
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+const (
+	// signMethod requests the signer to sign the request data.
+	signMethod byte = 0x01
+
+	// decryptMethod requests the signer to decrypt the request data.
+	decryptMethod byte = 0x02
+
+	// responseOK indicates the request has been handled successfully.
+	responseOK byte = 0x00
+
+	// responseError indicates the request has been rejected by signer, the
+	// response data is the error message.
+	responseError byte = 0x01
+
+	// maxRequestAge is the max age of a request accepted by signer, older
+	// requests will be rejected to prevent replay.
+	maxRequestAge = 30 * time.Second
+
+	// maxSignerDataSize is the max size of request or response data.
+	maxSignerDataSize = 8 * 1024 * 1024
+
+	// DefaultSignerTimeout is the default timeout of a remote signer request.
+	DefaultSignerTimeout = 3 * time.Second
+)
+
+// signerRequest is the request sent from node to the remote signer.
+type signerRequest struct {
+	Method    byte
+	Timestamp int64
+	Data      []byte
+	Auth      []byte
+}
+
+// authCode returns the HMAC of the request content with the given token.
+func (r *signerRequest) authCode(token []byte) []byte {
+	mac := hmac.New(sha256.New, token)
+	mac.Write([]byte{r.Method})
+	common.WriteUint64(mac, uint64(r.Timestamp))
+	mac.Write(r.Data)
+	return mac.Sum(nil)
+}
+
+func (r *signerRequest) Serialize(w io.Writer) error {
+	if err := common.WriteUint8(w, r.Method); err != nil {
+		return err
+	}
+	if err := common.WriteUint64(w, uint64(r.Timestamp)); err != nil {
+		return err
+	}
+	if err := common.WriteVarBytes(w, r.Data); err != nil {
+		return err
+	}
+	return common.WriteVarBytes(w, r.Auth)
+}
+
+func (r *signerRequest) Deserialize(reader io.Reader) (err error) {
+	if r.Method, err = common.ReadUint8(reader); err != nil {
+		return
+	}
+	var timestamp uint64
+	if timestamp, err = common.ReadUint64(reader); err != nil {
+		return
+	}
+	r.Timestamp = int64(timestamp)
+	if r.Data, err = common.ReadVarBytes(reader, maxSignerDataSize,
+		"data"); err != nil {
+		return
+	}
+	r.Auth, err = common.ReadVarBytes(reader, sha256.Size, "auth")
+	return
+}
+
+// signerResponse is the response sent from the remote signer to node.
+type signerResponse struct {
+	Code byte
+	Data []byte
+}
+
+func (r *signerResponse) Serialize(w io.Writer) error {
+	if err := common.WriteUint8(w, r.Code); err != nil {
+		return err
+	}
+	return common.WriteVarBytes(w, r.Data)
+}
+
+func (r *signerResponse) Deserialize(reader io.Reader) (err error) {
+	if r.Code, err = common.ReadUint8(reader); err != nil {
+		return
+	}
+	r.Data, err = common.ReadVarBytes(reader, maxSignerDataSize, "data")
+	return
+}
+
+// RemoteSignerConfig defines the parameters to create a remote signer account.
+type RemoteSignerConfig struct {
+	// Sockets are the unix socket paths of signing daemons, the first one is
+	// preferred and the others are used for failover.
+	Sockets []string
+
+	// PublicKey is the public key of the arbiter.
+	PublicKey []byte
+
+	// AuthToken is the secret shared with signing daemons to authenticate
+	// requests.
+	AuthToken []byte
+
+	// Timeout is the timeout of each request.
+	Timeout time.Duration
+}
+
+// SignerMetrics holds the request statistics of a signing daemon.
+type SignerMetrics struct {
+	Socket       string
+	Requests     uint64
+	Failures     uint64
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+	LastError    string
+}
+
+// RemoteAccount implements Account by requesting signatures from external
+// signing daemons, so the arbiter private key is never loaded in-process.
+type RemoteAccount struct {
+	cfg       RemoteSignerConfig
+	publicKey *crypto.PublicKey
+
+	mtx     sync.Mutex
+	current int
+	metrics []SignerMetrics
+}
+
+func (a *RemoteAccount) PublicKey() *crypto.PublicKey {
+	return a.publicKey
+}
+
+func (a *RemoteAccount) PublicKeyBytes() []byte {
+	return a.cfg.PublicKey
+}
+
+func (a *RemoteAccount) SignProposal(proposal *payload.DPOSProposal) ([]byte,
+	error) {
+	return a.sign(proposal.Data())
+}
+
+func (a *RemoteAccount) SignVote(vote *payload.DPOSProposalVote) ([]byte,
+	error) {
+	return a.sign(vote.Data())
+}
+
+func (a *RemoteAccount) Sign(data []byte) []byte {
+	sign, err := a.sign(data)
+	if err != nil {
+		return nil
+	}
+	return sign
+}
+
+func (a *RemoteAccount) SignTx(tx *types.Transaction) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := tx.SerializeUnsigned(buf); err != nil {
+		return nil, err
+	}
+	return a.sign(buf.Bytes())
+}
+
+func (a *RemoteAccount) DecryptAddr(cipher []byte) (addr string, err error) {
+	data, err := a.request(decryptMethod, cipher, nil)
+	return string(data), err
+}
+
+// Metrics returns the request statistics of all signing daemons.
+func (a *RemoteAccount) Metrics() []SignerMetrics {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	metrics := make([]SignerMetrics, len(a.metrics))
+	copy(metrics, a.metrics)
+	return metrics
+}
+
+// sign requests a signature of data and verifies it with the arbiter public
+// key, an invalid signature will be treated as a failure of the signer.
+func (a *RemoteAccount) sign(data []byte) ([]byte, error) {
+	return a.request(signMethod, data, func(sign []byte) error {
+		return crypto.Verify(*a.publicKey, data, sign)
+	})
+}
+
+// request sends the request to signing daemons one by one from the last
+// available one, until one of them returns a valid result.
+func (a *RemoteAccount) request(method byte, data []byte,
+	verify func([]byte) error) ([]byte, error) {
+	a.mtx.Lock()
+	start := a.current
+	a.mtx.Unlock()
+
+	count := len(a.cfg.Sockets)
+	for i := 0; i < count; i++ {
+		index := (start + i) % count
+		begin := time.Now()
+		result, err := a.call(a.cfg.Sockets[index], method, data)
+		if err == nil && verify != nil {
+			err = verify(result)
+		}
+		a.record(index, time.Now().Sub(begin), err)
+		if err == nil {
+			return result, nil
+		}
+	}
+	return nil, errors.New("no remote signer available")
+}
+
+// record updates the metrics of signer with specified index.
+func (a *RemoteAccount) record(index int, latency time.Duration, err error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	m := &a.metrics[index]
+	m.Requests++
+	m.TotalLatency += latency
+	if latency > m.MaxLatency {
+		m.MaxLatency = latency
+	}
+	if err != nil {
+		m.Failures++
+		m.LastError = err.Error()
+		return
+	}
+	a.current = index
+}
+
+func (a *RemoteAccount) call(socket string, method byte,
+	data []byte) ([]byte, error) {
+	conn, err := net.DialTimeout("unix", socket, a.cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(a.cfg.Timeout)); err != nil {
+		return nil, err
+	}
+
+	req := signerRequest{
+		Method:    method,
+		Timestamp: time.Now().Unix(),
+		Data:      data,
+	}
+	req.Auth = req.authCode(a.cfg.AuthToken)
+	if err := req.Serialize(conn); err != nil {
+		return nil, err
+	}
+
+	var resp signerResponse
+	if err := resp.Deserialize(conn); err != nil {
+		return nil, err
+	}
+	if resp.Code != responseOK {
+		return nil, fmt.Errorf("remote signer rejected: %s", resp.Data)
+	}
+	return resp.Data, nil
+}
+
+// NewRemote creates an Account which requests signatures from signing daemons.
+func NewRemote(cfg RemoteSignerConfig) (*RemoteAccount, error) {
+	if len(cfg.Sockets) == 0 {
+		return nil, errors.New("no remote signer socket specified")
+	}
+	if len(cfg.AuthToken) == 0 {
+		return nil, errors.New("remote signer auth token not specified")
+	}
+	publicKey, err := crypto.DecodePoint(cfg.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultSignerTimeout
+	}
+
+	metrics := make([]SignerMetrics, 0, len(cfg.Sockets))
+	for _, s := range cfg.Sockets {
+		metrics = append(metrics, SignerMetrics{Socket: s})
+	}
+	return &RemoteAccount{
+		cfg:       cfg,
+		publicKey: publicKey,
+		metrics:   metrics,
+	}, nil
+}
+
+// SignerServer serves requests from nodes with a local account, it is used to
+// run the external signing daemon.
+type SignerServer struct {
+	account  Account
+	token    []byte
+	listener net.Listener
+}
+
+// Serve accepts connections on the listener and handles requests until the
+// listener is closed.
+func (s *SignerServer) Serve(listener net.Listener) error {
+	s.listener = listener
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+// Close stops the server from accepting new connections.
+func (s *SignerServer) Close() error {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Close()
+}
+
+func (s *SignerServer) handleConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(DefaultSignerTimeout))
+
+	var req signerRequest
+	if err := req.Deserialize(conn); err != nil {
+		return
+	}
+
+	resp := signerResponse{Code: responseOK}
+	data, err := s.handleRequest(&req)
+	if err != nil {
+		resp.Code = responseError
+		resp.Data = []byte(err.Error())
+	} else {
+		resp.Data = data
+	}
+	resp.Serialize(conn)
+}
+
+func (s *SignerServer) handleRequest(req *signerRequest) ([]byte, error) {
+	if !hmac.Equal(req.Auth, req.authCode(s.token)) {
+		return nil, errors.New("authenticate failed")
+	}
+	age := time.Now().Sub(time.Unix(req.Timestamp, 0))
+	if age > maxRequestAge || age < -maxRequestAge {
+		return nil, errors.New("request expired")
+	}
+
+	switch req.Method {
+	case signMethod:
+		sign := s.account.Sign(req.Data)
+		if sign == nil {
+			return nil, errors.New("sign failed")
+		}
+		return sign, nil
+	case decryptMethod:
+		addr, err := s.account.DecryptAddr(req.Data)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(addr), nil
+	default:
+		return nil, errors.New("unknown method")
+	}
+}
+
+// NewSignerServer creates a signing daemon server with the given account and
+// authenticate token.
+func NewSignerServer(account Account, token []byte) *SignerServer {
+	return &SignerServer{account: account, token: token}
+}
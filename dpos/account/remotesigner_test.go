@@ -0,0 +1,74 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package account
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/account"
+	"github.com/elastos/Elastos.ELA/crypto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteAccount_Sign(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signer")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	acc, err := account.NewAccount()
+	assert.NoError(t, err)
+	local := New(acc)
+	token := []byte("token")
+
+	socket := filepath.Join(dir, "signer.sock")
+	listener, err := net.Listen("unix", socket)
+	assert.NoError(t, err)
+	server := NewSignerServer(local, token)
+	go server.Serve(listener)
+	defer server.Close()
+
+	// the first socket is unavailable, request should fail over to the
+	// second one.
+	unavailable := filepath.Join(dir, "unavailable.sock")
+	remote, err := NewRemote(RemoteSignerConfig{
+		Sockets:   []string{unavailable, socket},
+		PublicKey: local.PublicKeyBytes(),
+		AuthToken: token,
+	})
+	assert.NoError(t, err)
+
+	data := []byte("data to sign")
+	sign := remote.Sign(data)
+	assert.NotNil(t, sign)
+	assert.NoError(t, crypto.Verify(*local.PublicKey(), data, sign))
+
+	metrics := remote.Metrics()
+	assert.Equal(t, uint64(1), metrics[0].Failures)
+	assert.Equal(t, uint64(1), metrics[1].Requests)
+	assert.Equal(t, uint64(0), metrics[1].Failures)
+
+	// the available signer should be preferred by later requests.
+	assert.NotNil(t, remote.Sign(data))
+	metrics = remote.Metrics()
+	assert.Equal(t, uint64(1), metrics[0].Requests)
+	assert.Equal(t, uint64(2), metrics[1].Requests)
+
+	// requests with wrong token should be rejected.
+	remote2, err := NewRemote(RemoteSignerConfig{
+		Sockets:   []string{socket},
+		PublicKey: local.PublicKeyBytes(),
+		AuthToken: []byte("wrong token"),
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, remote2.Sign(data))
+	assert.Equal(t, "remote signer rejected: authenticate failed",
+		remote2.Metrics()[0].LastError)
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package alert
+
+import (
+	"fmt"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/dpos/state"
+	"github.com/elastos/Elastos.ELA/events"
+)
+
+// maxPendingBlocks is the maximum number of connected blocks waiting to be
+// checked, blocks exceed the limit will be ignored.
+const maxPendingBlocks = 100
+
+// AlertType defines the type of alert.
+type AlertType byte
+
+const (
+	// ProducerInactive indicates the producer became inactive.
+	ProducerInactive AlertType = iota
+
+	// ProducerIllegal indicates the producer became illegal.
+	ProducerIllegal
+
+	// MissedDutySlots indicates the producer has missed the configured
+	// number of consecutive duty slots.
+	MissedDutySlots
+)
+
+var alertTypeStrings = []string{"ProducerInactive", "ProducerIllegal",
+	"MissedDutySlots"}
+
+func (t AlertType) String() string {
+	if int(t) < len(alertTypeStrings) {
+		return alertTypeStrings[t]
+	}
+	return fmt.Sprintf("AlertType-%d", t)
+}
+
+func (t AlertType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// Alert is the notification sent to hooks.
+type Alert struct {
+	Type        AlertType `json:"type"`
+	PublicKey   string    `json:"publickey"`
+	Height      uint32    `json:"height"`
+	MissedSlots uint32    `json:"missedslots"`
+}
+
+// ProducerStatus holds the producer status used to detect alerts.
+type ProducerStatus struct {
+	State state.ProducerState
+
+	// InactiveCountingHeight is the height since which the producer has not
+	// sponsored any block as an arbiter, zero means not counting.
+	InactiveCountingHeight uint32
+}
+
+// Config defines the parameters to create a Monitor.
+type Config struct {
+	// PublicKeys are the owner or node public keys of producers to watch.
+	PublicKeys [][]byte
+
+	// MaxMissedSlots is the number of consecutive missed duty slots to fire
+	// a MissedDutySlots alert, zero means disabled.
+	MaxMissedSlots uint32
+
+	// Hooks are the hooks to be fired when alerts happen.
+	Hooks []Hook
+
+	// GetProducerStatus returns status of the producer with specified public
+	// key, false will be returned if producer not found.
+	GetProducerStatus func(publicKey []byte) (*ProducerStatus, bool)
+
+	// GetArbitersCount returns the count of current arbiters, which is the
+	// number of blocks of a duty round.
+	GetArbitersCount func() int
+
+	// IsCurrent returns if the node is synced, alerts will not be fired
+	// until the node is synced.
+	IsCurrent func() bool
+}
+
+// Monitor watches the configured producers on connected blocks and fires
+// hooks when they become inactive or illegal, or have missed duty slots.
+type Monitor struct {
+	cfg     Config
+	heights chan uint32
+	quit    chan struct{}
+
+	states      map[string]state.ProducerState
+	missedFired map[string]bool
+}
+
+// Start subscribes block connected events and starts to check producers.
+func (m *Monitor) Start() {
+	events.Subscribe(m.handleEvent)
+	go m.checkHandler()
+}
+
+// Stop stops checking producers.
+func (m *Monitor) Stop() {
+	close(m.quit)
+}
+
+func (m *Monitor) handleEvent(e *events.Event) {
+	if e.Type != events.ETBlockConnected {
+		return
+	}
+	block, ok := e.Data.(*types.Block)
+	if !ok {
+		return
+	}
+
+	select {
+	case m.heights <- block.Height:
+	default:
+		log.Warn("too many pending blocks, ignore block ", block.Height)
+	}
+}
+
+func (m *Monitor) checkHandler() {
+	for {
+		select {
+		case height := <-m.heights:
+			for _, alert := range m.check(height) {
+				m.fire(alert)
+			}
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// check returns the alerts of watched producers at the given height.
+func (m *Monitor) check(height uint32) []*Alert {
+	fire := m.cfg.IsCurrent == nil || m.cfg.IsCurrent()
+
+	var alerts []*Alert
+	for _, pk := range m.cfg.PublicKeys {
+		status, ok := m.cfg.GetProducerStatus(pk)
+		if !ok {
+			continue
+		}
+		key := common.BytesToHexString(pk)
+
+		last, seen := m.states[key]
+		m.states[key] = status.State
+		if seen && last != status.State && fire {
+			switch status.State {
+			case state.Inactive:
+				alerts = append(alerts, &Alert{Type: ProducerInactive,
+					PublicKey: key, Height: height})
+			case state.Illegal:
+				alerts = append(alerts, &Alert{Type: ProducerIllegal,
+					PublicKey: key, Height: height})
+			}
+		}
+
+		missed := m.missedSlots(status, height)
+		if m.cfg.MaxMissedSlots == 0 || missed < m.cfg.MaxMissedSlots {
+			m.missedFired[key] = false
+			continue
+		}
+		if !m.missedFired[key] && fire {
+			m.missedFired[key] = true
+			alerts = append(alerts, &Alert{Type: MissedDutySlots,
+				PublicKey: key, Height: height, MissedSlots: missed})
+		}
+	}
+	return alerts
+}
+
+// missedSlots returns the number of consecutive duty slots missed by the
+// producer, each arbiter has one duty slot in a round.
+func (m *Monitor) missedSlots(status *ProducerStatus, height uint32) uint32 {
+	if status.State != state.Active || status.InactiveCountingHeight == 0 ||
+		height < status.InactiveCountingHeight {
+		return 0
+	}
+	count := m.cfg.GetArbitersCount()
+	if count <= 0 {
+		return 0
+	}
+	return (height - status.InactiveCountingHeight) / uint32(count)
+}
+
+func (m *Monitor) fire(alert *Alert) {
+	log.Warnf("producer alert %s of %s at height %d", alert.Type,
+		alert.PublicKey, alert.Height)
+	for _, hook := range m.cfg.Hooks {
+		if err := hook.Fire(alert); err != nil {
+			log.Error("fire producer alert error: ", err)
+		}
+	}
+}
+
+// New creates a Monitor with the given configuration.
+func New(cfg *Config) *Monitor {
+	return &Monitor{
+		cfg:         *cfg,
+		heights:     make(chan uint32, maxPendingBlocks),
+		quit:        make(chan struct{}),
+		states:      make(map[string]state.ProducerState),
+		missedFired: make(map[string]bool),
+	}
+}
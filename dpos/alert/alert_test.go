@@ -0,0 +1,79 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package alert
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/dpos/state"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonitor_Check(t *testing.T) {
+	pk1 := []byte{1}
+	pk2 := []byte{2}
+	statuses := map[string]*ProducerStatus{
+		"01": {State: state.Active},
+		"02": {State: state.Active},
+	}
+	current := false
+	m := New(&Config{
+		PublicKeys:     [][]byte{pk1, pk2},
+		MaxMissedSlots: 3,
+		GetProducerStatus: func(pk []byte) (*ProducerStatus, bool) {
+			s, ok := statuses[string([]byte{'0', '0' + pk[0]})]
+			return s, ok
+		},
+		GetArbitersCount: func() int { return 10 },
+		IsCurrent:        func() bool { return current },
+	})
+
+	// no alerts before synced.
+	statuses["01"].State = state.Inactive
+	assert.Equal(t, 0, len(m.check(100)))
+	statuses["01"].State = state.Active
+	assert.Equal(t, 0, len(m.check(101)))
+
+	// state transitions.
+	current = true
+	statuses["01"].State = state.Inactive
+	statuses["02"].State = state.Illegal
+	alerts := m.check(102)
+	assert.Equal(t, 2, len(alerts))
+	assert.Equal(t, ProducerInactive, alerts[0].Type)
+	assert.Equal(t, "01", alerts[0].PublicKey)
+	assert.Equal(t, ProducerIllegal, alerts[1].Type)
+	assert.Equal(t, "02", alerts[1].PublicKey)
+	assert.Equal(t, 0, len(m.check(103)))
+
+	// missed duty slots should be fired once until recovered.
+	statuses["01"].State = state.Active
+	statuses["01"].InactiveCountingHeight = 100
+	assert.Equal(t, 0, len(m.check(129)))
+	alerts = m.check(130)
+	assert.Equal(t, 1, len(alerts))
+	assert.Equal(t, MissedDutySlots, alerts[0].Type)
+	assert.Equal(t, uint32(3), alerts[0].MissedSlots)
+	assert.Equal(t, 0, len(m.check(140)))
+
+	statuses["01"].InactiveCountingHeight = 0
+	assert.Equal(t, 0, len(m.check(141)))
+	statuses["01"].InactiveCountingHeight = 141
+	assert.Equal(t, 1, len(m.check(171)))
+}
+
+func TestAlert_MarshalJSON(t *testing.T) {
+	data, err := json.Marshal(&Alert{
+		Type:      ProducerInactive,
+		PublicKey: "01",
+		Height:    1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"ProducerInactive","publickey":"01",`+
+		`"height":1,"missedslots":0}`, string(data))
+}
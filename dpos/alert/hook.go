@@ -0,0 +1,104 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// hookTimeout is the maximum duration for a hook to handle an alert.
+const hookTimeout = 10 * time.Second
+
+// Hook is the interface to notify operators about alerts.
+type Hook interface {
+	// Fire notifies the alert, it returns error if the alert can not be
+	// delivered.
+	Fire(alert *Alert) error
+}
+
+// webHook posts alerts in JSON format to an URL.
+type webHook struct {
+	url    string
+	client *http.Client
+}
+
+func (h *webHook) Fire(alert *Alert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	resp, err := h.client.Post(h.url, "application/json",
+		bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("web hook %s responded %s", h.url, resp.Status)
+	}
+	return nil
+}
+
+// NewWebHook creates a Hook which posts alerts in JSON format to the URL.
+func NewWebHook(url string) Hook {
+	return &webHook{url: url, client: &http.Client{Timeout: hookTimeout}}
+}
+
+// commandHook executes a command for alerts, the alert is passed by
+// environment variables and standard input in JSON format.
+type commandHook struct {
+	name string
+	args []string
+}
+
+func (h *commandHook) Fire(alert *Alert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(h.name, h.args...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Env = append(os.Environ(),
+		"ELA_ALERT_TYPE="+alert.Type.String(),
+		"ELA_ALERT_PUBLICKEY="+alert.PublicKey,
+		fmt.Sprintf("ELA_ALERT_HEIGHT=%d", alert.Height),
+		fmt.Sprintf("ELA_ALERT_MISSEDSLOTS=%d", alert.MissedSlots),
+	)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(hookTimeout):
+		cmd.Process.Kill()
+		return fmt.Errorf("command %s timeout", h.name)
+	}
+}
+
+// NewCommandHook creates a Hook which executes the command for alerts, the
+// command line is split by spaces into command name and arguments.
+func NewCommandHook(command string) (Hook, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, errors.New("empty alert command")
+	}
+	return &commandHook{name: fields[0], args: fields[1:]}, nil
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/elastos/Elastos.ELA/events"
 	"github.com/elastos/Elastos.ELA/mempool"
 	"github.com/elastos/Elastos.ELA/p2p"
+	"github.com/elastos/Elastos.ELA/p2p/nat"
 )
 
 type Config struct {
@@ -80,6 +81,12 @@ func (a *Arbitrator) GetArbiterPeersInfo() []*dp2p.PeerInfo {
 	return a.network.p2pServer.DumpPeersInfo()
 }
 
+// NATStatus returns the state of the DPoS port mapping on the NAT gateway,
+// nil if the NAT traversal is not enabled.
+func (a *Arbitrator) NATStatus() *nat.Status {
+	return a.network.p2pServer.NATStatus()
+}
+
 func (a *Arbitrator) OnIllegalBlockTxReceived(p *payload.DPOSIllegalBlocks) {
 	log.Info("[OnIllegalBlockTxReceived] listener received illegal block tx")
 	if p.CoinType != payload.ELACoin {
@@ -132,6 +139,14 @@ func (a *Arbitrator) OnBlockReceived(b *types.Block, confirmed bool) {
 	a.network.PostBlockReceivedTask(b, confirmed)
 }
 
+func (a *Arbitrator) OnPipelinedBlockReceived(b *types.Block) {
+	if !a.cfg.Server.IsCurrent() {
+		return
+	}
+	log.Info("[OnPipelinedBlockReceived] listener received pipelined block")
+	a.network.PostPipelinedBlockTask(b)
+}
+
 func (a *Arbitrator) OnConfirmReceived(p *mempool.ConfirmInfo) {
 	if !a.cfg.Server.IsCurrent() {
 		return
@@ -182,6 +197,7 @@ func NewArbitrator(account account.Account, cfg Config) (*Arbitrator, error) {
 		log.Error("Init p2p network error")
 		return nil, err
 	}
+	dposNetwork := manager.WithFaults(network, dposManager, account)
 
 	eventMonitor := log.NewEventMonitor()
 
@@ -197,7 +213,7 @@ func NewArbitrator(account account.Account, cfg Config) (*Arbitrator, error) {
 	}
 
 	dposHandlerSwitch := manager.NewHandler(manager.DPOSHandlerConfig{
-		Network:     network,
+		Network:     dposNetwork,
 		Manager:     dposManager,
 		Monitor:     eventMonitor,
 		Arbitrators: cfg.Arbitrators,
@@ -209,7 +225,7 @@ func NewArbitrator(account account.Account, cfg Config) (*Arbitrator, error) {
 		manager.ProposalDispatcherConfig{
 			EventMonitor: eventMonitor,
 			Consensus:    consensus,
-			Network:      network,
+			Network:      dposNetwork,
 			Manager:      dposManager,
 			Account:      account,
 			ChainParams:  cfg.ChainParams,
@@ -222,7 +238,7 @@ func NewArbitrator(account account.Account, cfg Config) (*Arbitrator, error) {
 	dposHandlerSwitch.Initialize(proposalDispatcher, consensus)
 
 	dposManager.Initialize(dposHandlerSwitch, proposalDispatcher, consensus,
-		network, illegalMonitor, cfg.BlockMemPool, cfg.TxMemPool, cfg.Broadcast)
+		dposNetwork, illegalMonitor, cfg.BlockMemPool, cfg.TxMemPool, cfg.Broadcast)
 	network.Initialize(manager.DPOSNetworkConfig{
 		ProposalDispatcher: proposalDispatcher,
 		Store:              cfg.Store,
@@ -246,6 +262,9 @@ func NewArbitrator(account account.Account, cfg Config) (*Arbitrator, error) {
 			block := e.Data.(*types.DposBlock)
 			go a.OnBlockReceived(block.Block, block.HaveConfirm)
 
+		case events.ETPipelinedBlockReceived:
+			go a.OnPipelinedBlockReceived(e.Data.(*types.Block))
+
 		case events.ETConfirmAccepted:
 			go a.OnConfirmReceived(e.Data.(*mempool.ConfirmInfo))
 
@@ -7,6 +7,7 @@ package manager
 
 import (
 	"bytes"
+	"time"
 
 	"github.com/elastos/Elastos.ELA/blockchain"
 	"github.com/elastos/Elastos.ELA/common"
@@ -17,8 +18,13 @@ import (
 	"github.com/elastos/Elastos.ELA/dpos/p2p/msg"
 	"github.com/elastos/Elastos.ELA/dpos/p2p/peer"
 	"github.com/elastos/Elastos.ELA/dpos/state"
+	"github.com/elastos/Elastos.ELA/dpos/store"
 )
 
+// consensusPersistInterval is the minimum interval to save the consensus
+// round on proposals and votes.
+const consensusPersistInterval = time.Second
+
 type DPOSEventConditionHandler interface {
 	TryStartNewConsensus(b *types.Block) bool
 
@@ -47,6 +53,9 @@ type DPOSHandlerSwitch struct {
 	currentHandler DPOSEventConditionHandler
 
 	isAbnormal bool
+
+	// lastPersisted is the time the consensus round was saved last time.
+	lastPersisted time.Time
 }
 
 func NewHandler(cfg DPOSHandlerConfig) *DPOSHandlerSwitch {
@@ -93,6 +102,7 @@ func (h *DPOSHandlerSwitch) SwitchTo(onDuty bool) {
 
 func (h *DPOSHandlerSwitch) FinishConsensus(height uint32, blockHash common.Uint256) {
 	h.proposalDispatcher.FinishConsensus(height, blockHash)
+	h.clearConsensus()
 }
 
 func (h *DPOSHandlerSwitch) ProcessProposal(id peer.PID, p *payload.DPOSProposal) (handled bool) {
@@ -107,6 +117,7 @@ func (h *DPOSHandlerSwitch) ProcessProposal(id peer.PID, p *payload.DPOSProposal
 		Result:       false,
 	}
 	h.cfg.Monitor.OnProposalArrived(&proposalEvent)
+	h.debouncePersistConsensus()
 
 	return handled
 }
@@ -123,6 +134,7 @@ func (h *DPOSHandlerSwitch) ChangeView(firstBlockHash *common.Uint256) {
 		Height:           blockchain.DefaultLedger.Blockchain.GetHeight() + 1,
 	}
 	h.cfg.Monitor.OnViewStarted(&viewEvent)
+	h.persistConsensus()
 }
 
 func (h *DPOSHandlerSwitch) TryStartNewConsensus(b *types.Block) bool {
@@ -136,6 +148,7 @@ func (h *DPOSHandlerSwitch) TryStartNewConsensus(b *types.Block) bool {
 		c := log.ConsensusEvent{StartTime: h.cfg.TimeSource.AdjustedTime(), Height: b.Height,
 			RawData: &b.Header}
 		h.cfg.Monitor.OnConsensusStarted(&c)
+		h.persistConsensus()
 		return true
 	}
 
@@ -149,6 +162,7 @@ func (h *DPOSHandlerSwitch) ProcessAcceptVote(id peer.PID, p *payload.DPOSPropos
 		ReceivedTime: h.cfg.TimeSource.AdjustedTime(), Result: true, RawData: p}
 	h.cfg.Monitor.OnVoteArrived(&voteEvent)
 	h.proposalDispatcher.eventAnalyzer.AppendConsensusVote(p)
+	h.debouncePersistConsensus()
 
 	return succeed, finished
 }
@@ -160,6 +174,7 @@ func (h *DPOSHandlerSwitch) ProcessRejectVote(id peer.PID, p *payload.DPOSPropos
 		ReceivedTime: h.cfg.TimeSource.AdjustedTime(), Result: false, RawData: p}
 	h.cfg.Monitor.OnVoteArrived(&voteEvent)
 	h.proposalDispatcher.eventAnalyzer.AppendConsensusVote(p)
+	h.debouncePersistConsensus()
 
 	return succeed, finished
 }
@@ -234,6 +249,112 @@ func (h *DPOSHandlerSwitch) RecoverAbnormal(status *msg.ConsensusStatus) {
 	h.isAbnormal = false
 }
 
+// debouncePersistConsensus saves the consensus round at most once every
+// consensusPersistInterval, changes within the interval are saved with the
+// next proposal or vote after it, or when the view changes.
+func (h *DPOSHandlerSwitch) debouncePersistConsensus() {
+	if time.Since(h.lastPersisted) < consensusPersistInterval {
+		return
+	}
+	h.persistConsensus()
+}
+
+// clearConsensus clears the saved consensus round.
+func (h *DPOSHandlerSwitch) clearConsensus() {
+	s := h.proposalDispatcher.cfg.Store
+	if s == nil {
+		return
+	}
+
+	if err := s.ClearConsensusRound(); err != nil {
+		log.Warn("[clearConsensus] clear consensus round error: ", err)
+	}
+}
+
+// persistConsensus saves the in-flight consensus round to store, or clears
+// the saved round if consensus is not running. The processing proposal is
+// saved as a pending proposal, so it will be processed again after restart.
+func (h *DPOSHandlerSwitch) persistConsensus() {
+	s := h.proposalDispatcher.cfg.Store
+	if s == nil {
+		return
+	}
+
+	if !h.consensus.IsRunning() {
+		h.clearConsensus()
+		return
+	}
+	h.lastPersisted = time.Now()
+
+	round := &store.ConsensusRound{
+		Height: blockchain.DefaultLedger.Blockchain.GetHeight() + 1,
+	}
+	if err := h.consensus.CollectConsensusStatus(&round.Status); err != nil {
+		log.Warn("[persistConsensus] collect consensus status error: ", err)
+		return
+	}
+	if err := h.proposalDispatcher.CollectConsensusStatus(
+		&round.Status); err != nil {
+		log.Warn("[persistConsensus] collect proposal status error: ", err)
+		return
+	}
+	if p := h.proposalDispatcher.GetProcessingProposal(); p != nil {
+		round.Status.PendingProposals = append(
+			round.Status.PendingProposals, *p)
+	}
+
+	if err := s.SaveConsensusRound(round); err != nil {
+		log.Warn("[persistConsensus] save consensus round error: ", err)
+	}
+}
+
+// RecoverFromStore restores the consensus round saved before restart if it
+// is still the round of the next block, and requests blocks of the pending
+// proposals from their sponsors. It returns if the round has been restored.
+func (h *DPOSHandlerSwitch) RecoverFromStore() bool {
+	s := h.proposalDispatcher.cfg.Store
+	if s == nil {
+		return false
+	}
+
+	round, err := s.GetConsensusRound()
+	if err != nil {
+		return false
+	}
+	height := blockchain.DefaultLedger.Blockchain.GetHeight() + 1
+	if round.Height != height ||
+		round.Status.ConsensusStatus != consensusRunning {
+		log.Info("[RecoverFromStore] discard consensus round at height ",
+			round.Height)
+		if err := s.ClearConsensusRound(); err != nil {
+			log.Warn("[RecoverFromStore] clear consensus round error: ", err)
+		}
+		return false
+	}
+
+	if err := h.proposalDispatcher.RecoverFromConsensusStatus(
+		&round.Status); err != nil {
+		log.Error("[RecoverFromStore] recover proposal dispatcher error: ", err)
+		return false
+	}
+	if err := h.consensus.RecoverFromConsensusStatus(
+		&round.Status); err != nil {
+		log.Error("[RecoverFromStore] recover consensus error: ", err)
+		return false
+	}
+	h.SwitchTo(h.consensus.IsArbitratorOnDuty(h.cfg.Manager.GetPublicKey()))
+
+	for _, p := range round.Status.PendingProposals {
+		var pid peer.PID
+		copy(pid[:], p.Sponsor)
+		h.cfg.Manager.OnInv(pid, p.BlockHash)
+	}
+
+	log.Infof("[RecoverFromStore] recovered consensus round at height %d"+
+		" view offset %d", height, round.Status.ViewOffset)
+	return true
+}
+
 func (h *DPOSHandlerSwitch) OnViewChanged(isOnDuty bool) {
 	h.SwitchTo(isOnDuty)
 
@@ -83,6 +83,7 @@ type NetworkEventListener interface {
 	OnRecoverTimeout()
 
 	OnBlockReceived(b *types.Block, confirmed bool)
+	OnPipelinedBlockReceived(b *types.Block)
 	OnConfirmReceived(p *payload.Confirm, height uint32)
 	OnIllegalBlocksTxReceived(i *payload.DPOSIllegalBlocks)
 	OnSidechainIllegalEvidenceReceived(s *payload.SidechainIllegalData)
@@ -372,6 +373,9 @@ func (d *DPOSManager) OnRecover() {
 		return
 	}
 	d.changeHeight()
+	// restore the consensus round saved before restart first, the status
+	// collected from peers will take place of it if received later.
+	d.handler.RecoverFromStore()
 	d.recoverAbnormalState()
 }
 
@@ -508,6 +512,20 @@ func (d *DPOSManager) OnBlockReceived(b *types.Block, confirmed bool) {
 	}
 }
 
+func (d *DPOSManager) OnPipelinedBlockReceived(b *types.Block) {
+	log.Info("[OnPipelinedBlockReceived] start")
+	defer log.Info("[OnPipelinedBlockReceived] end")
+
+	if !d.isCurrentArbiter() {
+		return
+	}
+	if !d.illegalMonitor.IsBlockValid(b) {
+		log.Info("[OnPipelinedBlockReceived] received block do not contains illegal evidence, block hash: ", b.Hash())
+		return
+	}
+	d.dispatcher.PrepareProposal(b)
+}
+
 func (d *DPOSManager) OnConfirmReceived(p *payload.Confirm, height uint32) {
 	log.Info("[OnConfirmReceived] started, hash:", p.Proposal.BlockHash)
 	defer log.Info("[OnConfirmReceived] end")
@@ -0,0 +1,308 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+// +build faultinjection
+
+package manager
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/auxpow"
+	"github.com/elastos/Elastos.ELA/blockchain"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/dpos/account"
+	"github.com/elastos/Elastos.ELA/dpos/log"
+	dmsg "github.com/elastos/Elastos.ELA/dpos/p2p/msg"
+	dpeer "github.com/elastos/Elastos.ELA/dpos/p2p/peer"
+	"github.com/elastos/Elastos.ELA/p2p"
+)
+
+// FaultRulesEnv is the environment variable of the path of the JSON file
+// which contains the fault rules loaded on start.
+const FaultRulesEnv = "ELA_DPOS_FAULTS"
+
+// maxFaultNonce is the max nonce tried to solve the aux pow of a faulty
+// block, fault injection is meant for test networks with a trivial
+// difficulty.
+const maxFaultNonce = 1 << 20
+
+// FaultType is the kind of the misbehavior injected by a fault rule.
+type FaultType string
+
+const (
+	// FaultEquivocate broadcasts a conflicting proposal for another block of
+	// the same height along with each proposal, and a conflicting vote for
+	// the same proposal along with each vote.
+	FaultEquivocate FaultType = "equivocate"
+
+	// FaultWithholdVotes drops the votes instead of broadcasting them.
+	FaultWithholdVotes FaultType = "withholdvotes"
+
+	// FaultInvalidProposal proposes a block with an invalid merkle root
+	// instead of the block to be proposed.
+	FaultInvalidProposal FaultType = "invalidproposal"
+
+	// FaultDelay delays the consensus messages sent to the peers.
+	FaultDelay FaultType = "delay"
+)
+
+// FaultRule describes a misbehavior injected on the heights in range of
+// [StartHeight, EndHeight] and the view offsets listed, an EndHeight of 0
+// means no upper bound and empty ViewOffsets means all views.
+type FaultRule struct {
+	Type        FaultType `json:"type"`
+	StartHeight uint32    `json:"startheight"`
+	EndHeight   uint32    `json:"endheight"`
+	ViewOffsets []uint32  `json:"viewoffsets"`
+	// Delay is the milliseconds to delay a message by a FaultDelay rule.
+	Delay uint32 `json:"delay"`
+}
+
+// match returns if the rule is active on the height and view offset.
+func (r *FaultRule) match(height, viewOffset uint32) bool {
+	if height < r.StartHeight || (r.EndHeight != 0 && height > r.EndHeight) {
+		return false
+	}
+	if len(r.ViewOffsets) == 0 {
+		return true
+	}
+	for _, offset := range r.ViewOffsets {
+		if offset == viewOffset {
+			return true
+		}
+	}
+	return false
+}
+
+var faultRules struct {
+	sync.RWMutex
+	rules []FaultRule
+}
+
+// SetFaultRules replaces the fault rules injected by all the fault networks.
+func SetFaultRules(rules []FaultRule) {
+	faultRules.Lock()
+	faultRules.rules = rules
+	faultRules.Unlock()
+}
+
+// LoadFaultRules loads the fault rules from the JSON file.
+func LoadFaultRules(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var rules []FaultRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return err
+	}
+	SetFaultRules(rules)
+	return nil
+}
+
+// activeFault returns the first rule of the fault type active on the height
+// and view offset.
+func activeFault(faultType FaultType, height,
+	viewOffset uint32) (*FaultRule, bool) {
+	faultRules.RLock()
+	defer faultRules.RUnlock()
+	for i := range faultRules.rules {
+		rule := &faultRules.rules[i]
+		if rule.Type == faultType && rule.match(height, viewOffset) {
+			return rule, true
+		}
+	}
+	return nil, false
+}
+
+// faultNetwork is a DPOSNetwork injecting the misbehaviors of the fault rules
+// into the consensus messages sent by the arbiter, it's used to test the view
+// change and illegal evidence logic of the other arbiters.
+type faultNetwork struct {
+	DPOSNetwork
+	manager *DPOSManager
+	account account.Account
+}
+
+// WithFaults returns the network injecting the fault rules, the rules are
+// loaded from the file of FaultRulesEnv if it's set.
+func WithFaults(network DPOSNetwork, manager *DPOSManager,
+	account account.Account) DPOSNetwork {
+	if path := os.Getenv(FaultRulesEnv); path != "" {
+		if err := LoadFaultRules(path); err != nil {
+			log.Error("[WithFaults] load fault rules failed:", err)
+		}
+	}
+	log.Warn("[WithFaults] fault injection is enabled")
+	return &faultNetwork{
+		DPOSNetwork: network,
+		manager:     manager,
+		account:     account,
+	}
+}
+
+// status returns the consensus height and view offset.
+func (n *faultNetwork) status() (uint32, uint32) {
+	height := blockchain.DefaultLedger.Blockchain.GetHeight() + 1
+	var viewOffset uint32
+	if n.manager.consensus != nil {
+		viewOffset = n.manager.consensus.GetViewOffset()
+	}
+	return height, viewOffset
+}
+
+func (n *faultNetwork) SendMessageToPeer(id dpeer.PID, msg p2p.Message) error {
+	height, viewOffset := n.status()
+	if rule, ok := activeFault(FaultDelay, height, viewOffset); ok {
+		go func() {
+			time.Sleep(time.Duration(rule.Delay) * time.Millisecond)
+			n.DPOSNetwork.SendMessageToPeer(id, msg)
+		}()
+		return nil
+	}
+	return n.DPOSNetwork.SendMessageToPeer(id, msg)
+}
+
+func (n *faultNetwork) BroadcastMessage(msg p2p.Message) {
+	height, viewOffset := n.status()
+	var msgs []p2p.Message
+	switch m := msg.(type) {
+	case *dmsg.Proposal:
+		msgs = n.proposalFaults(m, height, viewOffset)
+	case *dmsg.Vote:
+		msgs = n.voteFaults(m, height, viewOffset)
+	default:
+		msgs = []p2p.Message{msg}
+	}
+
+	if rule, ok := activeFault(FaultDelay, height, viewOffset); ok {
+		go func() {
+			time.Sleep(time.Duration(rule.Delay) * time.Millisecond)
+			for _, m := range msgs {
+				n.DPOSNetwork.BroadcastMessage(m)
+			}
+		}()
+		return
+	}
+	for _, m := range msgs {
+		n.DPOSNetwork.BroadcastMessage(m)
+	}
+}
+
+// proposalFaults returns the proposal messages to broadcast instead of the
+// proposal.
+func (n *faultNetwork) proposalFaults(m *dmsg.Proposal, height,
+	viewOffset uint32) []p2p.Message {
+	block, ok := n.manager.GetBlockCache().TryGetValue(m.Proposal.BlockHash)
+	if !ok {
+		return []p2p.Message{m}
+	}
+
+	if _, ok := activeFault(FaultInvalidProposal, height, viewOffset); ok {
+		invalid := n.faultyBlock(block, func(header *types.Header) {
+			header.MerkleRoot = common.Uint256(common.Sha256D(
+				header.MerkleRoot.Bytes()))
+		})
+		if p, err := n.signProposal(&m.Proposal, invalid); err == nil {
+			log.Warn("[FaultInjection] propose invalid block",
+				invalid.Hash(), "at height", height)
+			return []p2p.Message{p}
+		}
+	}
+
+	msgs := []p2p.Message{m}
+	if _, ok := activeFault(FaultEquivocate, height, viewOffset); ok {
+		conflict := n.faultyBlock(block, func(header *types.Header) {
+			header.Timestamp++
+		})
+		if p, err := n.signProposal(&m.Proposal, conflict); err == nil {
+			log.Warn("[FaultInjection] propose conflicting block",
+				conflict.Hash(), "at height", height)
+			msgs = append(msgs, p)
+		}
+	}
+	return msgs
+}
+
+// voteFaults returns the vote messages to broadcast instead of the vote.
+func (n *faultNetwork) voteFaults(m *dmsg.Vote, height,
+	viewOffset uint32) []p2p.Message {
+	if _, ok := activeFault(FaultWithholdVotes, height, viewOffset); ok {
+		log.Warn("[FaultInjection] withhold vote of proposal",
+			m.Vote.ProposalHash, "at height", height)
+		return nil
+	}
+
+	msgs := []p2p.Message{m}
+	if _, ok := activeFault(FaultEquivocate, height, viewOffset); ok {
+		vote := payload.DPOSProposalVote{
+			ProposalHash: m.Vote.ProposalHash,
+			Signer:       m.Vote.Signer,
+			Accept:       !m.Vote.Accept,
+		}
+		sign, err := n.account.SignVote(&vote)
+		if err != nil {
+			return msgs
+		}
+		vote.Sign = sign
+		command := dmsg.CmdAcceptVote
+		if !vote.Accept {
+			command = dmsg.CmdRejectVote
+		}
+		log.Warn("[FaultInjection] vote conflicting on proposal",
+			m.Vote.ProposalHash, "at height", height)
+		msgs = append(msgs, &dmsg.Vote{Command: command, Vote: vote})
+	}
+	return msgs
+}
+
+// faultyBlock returns a copy of the block with the header modified, the aux
+// pow is solved again and the block is served to the peers requesting it.
+func (n *faultNetwork) faultyBlock(block *types.Block,
+	modify func(header *types.Header)) *types.Block {
+	faulty := &types.Block{
+		Header:       block.Header,
+		Transactions: block.Transactions,
+	}
+	modify(&faulty.Header)
+
+	ap := auxpow.GenerateAuxPow(faulty.Hash())
+	target := blockchain.CompactToBig(faulty.Header.Bits)
+	for i := uint32(0); i < maxFaultNonce; i++ {
+		ap.ParBlockHeader.Nonce = i
+		hash := ap.ParBlockHeader.Hash()
+		if blockchain.HashToBig(&hash).Cmp(target) <= 0 {
+			break
+		}
+	}
+	faulty.Header.AuxPow = *ap
+	n.manager.blockPool.AddToBlockMap(faulty)
+	return faulty
+}
+
+// signProposal returns the proposal message of the block signed by the
+// arbiter, the sponsor and the view offset are taken from the proposal.
+func (n *faultNetwork) signProposal(proposal *payload.DPOSProposal,
+	block *types.Block) (*dmsg.Proposal, error) {
+	p := payload.DPOSProposal{
+		Sponsor:    proposal.Sponsor,
+		BlockHash:  block.Hash(),
+		ViewOffset: proposal.ViewOffset,
+	}
+	sign, err := n.account.SignProposal(&p)
+	if err != nil {
+		log.Error("[FaultInjection] sign proposal failed:", err)
+		return nil, err
+	}
+	p.Sign = sign
+	return &dmsg.Proposal{Proposal: p}, nil
+}
@@ -0,0 +1,19 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+// +build !faultinjection
+
+package manager
+
+import (
+	"github.com/elastos/Elastos.ELA/dpos/account"
+)
+
+// WithFaults returns the network as is, faults are injected only by the
+// builds with the faultinjection tag.
+func WithFaults(network DPOSNetwork, manager *DPOSManager,
+	account account.Account) DPOSNetwork {
+	return network
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+// +build faultinjection
+
+package manager
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFaultRules(t *testing.T) {
+	file, err := ioutil.TempFile("", "faults")
+	assert.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(`[
+		{"type": "withholdvotes", "startheight": 10, "endheight": 20},
+		{"type": "delay", "startheight": 15, "viewoffsets": [1, 2],
+			"delay": 500}
+	]`)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	assert.NoError(t, LoadFaultRules(file.Name()))
+	defer SetFaultRules(nil)
+
+	_, ok := activeFault(FaultWithholdVotes, 9, 0)
+	assert.False(t, ok)
+	_, ok = activeFault(FaultWithholdVotes, 10, 3)
+	assert.True(t, ok)
+	_, ok = activeFault(FaultWithholdVotes, 21, 0)
+	assert.False(t, ok)
+
+	// the delay rule has no upper bound of height but limits the views.
+	_, ok = activeFault(FaultDelay, 1000, 0)
+	assert.False(t, ok)
+	rule, ok := activeFault(FaultDelay, 1000, 2)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(500), rule.Delay)
+
+	_, ok = activeFault(FaultEquivocate, 15, 1)
+	assert.False(t, ok)
+	assert.Error(t, LoadFaultRules(file.Name()+".notexist"))
+}
@@ -8,6 +8,7 @@ package manager
 import (
 	"bytes"
 	"errors"
+	"fmt"
 
 	"github.com/elastos/Elastos.ELA/blockchain"
 	"github.com/elastos/Elastos.ELA/common"
@@ -50,6 +51,10 @@ type ProposalDispatcher struct {
 	precociousProposals map[common.Uint256]*payload.DPOSProposal
 	pendingVotes        map[common.Uint256]*payload.DPOSProposalVote
 
+	// preparedProposal is the proposal signed in advance for the block on top
+	// of the processing block, it is isolated from the processing states.
+	preparedProposal *payload.DPOSProposal
+
 	proposalProcessFinished bool
 	crcBadNetwork           bool
 	firstBadNetworkRecover  bool
@@ -124,16 +129,25 @@ func (p *ProposalDispatcher) StartProposal(b *types.Block) {
 		log.Info("[StartProposal] start proposal failed")
 		return
 	}
+	if err := p.checkRewardWhitelist(b); err != nil {
+		log.Warn("[StartProposal] refuse to propose:", err.Error())
+		return
+	}
 	p.processingBlock = b
 
 	//p.cfg.Network.BroadcastMessage(dmsg.NewInventory(b.Hash()))
-	proposal := &payload.DPOSProposal{Sponsor: p.cfg.Manager.GetPublicKey(),
-		BlockHash: b.Hash(), ViewOffset: p.cfg.Consensus.GetViewOffset()}
-	var err error
-	proposal.Sign, err = p.cfg.Account.SignProposal(proposal)
-	if err != nil {
-		log.Error("[StartProposal] start proposal failed:", err.Error())
-		return
+	proposal := p.takePreparedProposal(b)
+	if proposal == nil {
+		proposal = &payload.DPOSProposal{Sponsor: p.cfg.Manager.GetPublicKey(),
+			BlockHash: b.Hash(), ViewOffset: p.cfg.Consensus.GetViewOffset()}
+		var err error
+		proposal.Sign, err = p.cfg.Account.SignProposal(proposal)
+		if err != nil {
+			log.Error("[StartProposal] start proposal failed:", err.Error())
+			return
+		}
+	} else {
+		log.Info("[StartProposal] use prepared proposal")
 	}
 
 	log.Info("[StartProposal] sponsor:", p.cfg.Manager.GetPublicKey())
@@ -157,6 +171,53 @@ func (p *ProposalDispatcher) StartProposal(b *types.Block) {
 	p.acceptProposal(proposal)
 }
 
+// PrepareProposal signs the proposal of the block on top of the processing
+// block in advance if this arbiter is expected to be on duty at the next
+// height, so that the proposal can be sent as soon as the consensus of the
+// next height starts. The processing states will not be changed.
+func (p *ProposalDispatcher) PrepareProposal(b *types.Block) {
+	if !p.cfg.Consensus.IsRunning() || p.processingBlock == nil ||
+		!b.Header.Previous.IsEqual(p.processingBlock.Hash()) {
+		return
+	}
+
+	// The duty index moves forward by one each block, the arbiters of the
+	// next height are checked again when the prepared proposal is used.
+	nextArbiter := p.cfg.Manager.GetArbitrators().GetNextOnDutyArbitrator(1)
+	if !bytes.Equal(nextArbiter, p.cfg.Manager.GetPublicKey()) {
+		return
+	}
+	if err := p.checkRewardWhitelist(b); err != nil {
+		log.Warn("[PrepareProposal] refuse to prepare proposal:", err.Error())
+		return
+	}
+
+	proposal := &payload.DPOSProposal{Sponsor: p.cfg.Manager.GetPublicKey(),
+		BlockHash: b.Hash(), ViewOffset: 0}
+	sign, err := p.cfg.Account.SignProposal(proposal)
+	if err != nil {
+		log.Warn("[PrepareProposal] sign proposal failed:", err.Error())
+		return
+	}
+	proposal.Sign = sign
+	p.preparedProposal = proposal
+	log.Info("[PrepareProposal] prepared proposal of block ", b.Hash())
+}
+
+// takePreparedProposal returns the prepared proposal if it matches the block
+// and the current view, the prepared proposal will be cleared anyway.
+func (p *ProposalDispatcher) takePreparedProposal(
+	b *types.Block) *payload.DPOSProposal {
+	proposal := p.preparedProposal
+	p.preparedProposal = nil
+	if proposal == nil || !proposal.BlockHash.IsEqual(b.Hash()) ||
+		proposal.ViewOffset != p.cfg.Consensus.GetViewOffset() ||
+		!bytes.Equal(proposal.Sponsor, p.cfg.Manager.GetPublicKey()) {
+		return nil
+	}
+	return proposal
+}
+
 func (p *ProposalDispatcher) TryStartSpeculatingProposal(b *types.Block) {
 	log.Info("[TryStartSpeculatingProposal] start")
 	defer log.Info("[TryStartSpeculatingProposal] end")
@@ -233,6 +294,7 @@ func (p *ProposalDispatcher) ResetByCurrentView() {
 
 	p.processingBlock = nil
 	p.processingProposal = nil
+	p.preparedProposal = nil
 	p.acceptVotes = make(map[common.Uint256]*payload.DPOSProposalVote)
 	p.rejectedVotes = make(map[common.Uint256]*payload.DPOSProposalVote)
 	p.pendingVotes = make(map[common.Uint256]*payload.DPOSProposalVote)
@@ -329,6 +391,12 @@ func (p *ProposalDispatcher) ProcessProposal(id peer.PID, d *payload.DPOSProposa
 		return true, !self
 	}
 
+	if err := p.checkRewardWhitelist(p.processingBlock); err != nil {
+		p.rejectProposal(d)
+		log.Warn("reject:", err.Error())
+		return true, !self
+	}
+
 	if !p.proposalProcessFinished {
 		p.acceptProposal(d)
 	}
@@ -336,6 +404,28 @@ func (p *ProposalDispatcher) ProcessProposal(id peer.PID, d *payload.DPOSProposa
 	return true, true
 }
 
+// checkRewardWhitelist returns an error if the coinbase of the block pays the
+// DPoS rewards of this arbiter to an address not in the reward whitelist.
+func (p *ProposalDispatcher) checkRewardWhitelist(b *types.Block) error {
+	if len(p.cfg.ChainParams.RewardWhitelist) == 0 ||
+		len(b.Transactions) == 0 {
+		return nil
+	}
+	rewardHash, ok := p.cfg.Manager.GetArbitrators().GetArbiterRewardHash(
+		p.cfg.Manager.GetPublicKey())
+	if !ok || p.cfg.ChainParams.IsRewardAddressAllowed(rewardHash) {
+		return nil
+	}
+	for _, output := range b.Transactions[0].Outputs {
+		if output.ProgramHash.IsEqual(rewardHash) {
+			addr, _ := rewardHash.ToAddress()
+			return fmt.Errorf("block %s pays rewards to %s which is not in "+
+				"the reward whitelist", b.Hash(), addr)
+		}
+	}
+	return nil
+}
+
 func (p *ProposalDispatcher) AppendConfirm() {
 	currentVoteSlot := &payload.Confirm{
 		Proposal: *p.processingProposal,
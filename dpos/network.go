@@ -63,6 +63,7 @@ type network struct {
 	recoverChan              chan bool
 	recoverTimeoutChan       chan bool
 	blockReceivedChan        chan blockItem
+	pipelinedBlockChan       chan *types.Block
 	confirmReceivedChan      chan *mempool.ConfirmInfo
 	illegalBlocksEvidence    chan *payload.DPOSIllegalBlocks
 	sidechainIllegalEvidence chan *payload.SidechainIllegalData
@@ -95,6 +96,8 @@ func (n *network) Start() {
 				n.recoverTimeout()
 			case blockItem := <-n.blockReceivedChan:
 				n.blockReceived(blockItem.Block, blockItem.Confirmed)
+			case block := <-n.pipelinedBlockChan:
+				n.pipelinedBlockReceived(block)
 			case confirmInfo := <-n.confirmReceivedChan:
 				n.confirmReceived(confirmInfo.Confirm, confirmInfo.Height)
 			case evidence := <-n.illegalBlocksEvidence:
@@ -153,6 +156,10 @@ func (n *network) PostBlockReceivedTask(b *types.Block, confirmed bool) {
 	n.blockReceivedChan <- blockItem{b, confirmed}
 }
 
+func (n *network) PostPipelinedBlockTask(b *types.Block) {
+	n.pipelinedBlockChan <- b
+}
+
 func (n *network) PostIllegalBlocksTask(p *payload.DPOSIllegalBlocks) {
 	n.illegalBlocksEvidence <- p
 }
@@ -303,6 +310,10 @@ func (n *network) blockReceived(b *types.Block, confirmed bool) {
 	n.listener.OnBlockReceived(b, confirmed)
 }
 
+func (n *network) pipelinedBlockReceived(b *types.Block) {
+	n.listener.OnPipelinedBlockReceived(b)
+}
+
 func (n *network) confirmReceived(p *payload.Confirm, height uint32) {
 	n.listener.OnConfirmReceived(p, height)
 }
@@ -339,6 +350,7 @@ func NewDposNetwork(cfg NetworkConfig) (*network, error) {
 		illegalBlocksEvidence:    make(chan *payload.DPOSIllegalBlocks),
 		sidechainIllegalEvidence: make(chan *payload.SidechainIllegalData),
 		inactiveArbiters:         make(chan *payload.InactiveArbitrators),
+		pipelinedBlockChan:       make(chan *types.Block, 10),
 	}
 
 	notifier := p2p.NewNotifier(p2p.NFNetStabled|p2p.NFBadNetwork, network.notifyFlag)
@@ -352,6 +364,9 @@ func NewDposNetwork(cfg NetworkConfig) (*network, error) {
 		Localhost:        cfg.ChainParams.DPoSIPAddress,
 		MagicNumber:      cfg.ChainParams.DPoSMagic,
 		DefaultPort:      cfg.ChainParams.DPoSDefaultPort,
+		Upnp:             cfg.ChainParams.NAT.UPnP,
+		NATPMP:           cfg.ChainParams.NAT.NATPMP,
+		NATGateway:       cfg.ChainParams.NAT.Gateway,
 		TimeSource:       cfg.MedianTime,
 		MakeEmptyMessage: makeEmptyMessage,
 		HandleMessage:    network.handleMessage,
@@ -43,6 +43,15 @@ type Config struct {
 	// DefaultPort defines the default peer-to-peer port for the network.
 	DefaultPort uint16
 
+	// Upnp and NATPMP indicate whether or not to map the DefaultPort on the
+	// NAT gateway by UPnP or NAT-PMP.
+	Upnp   bool
+	NATPMP bool
+
+	// NATGateway is the NAT-PMP gateway, the default gateway of the system is
+	// used if empty.
+	NATGateway string
+
 	// TimeSource defines the median time source to use for things such as
 	// view changing.
 	TimeSource dtime.MedianTimeSource
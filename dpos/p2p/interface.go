@@ -10,6 +10,7 @@ import (
 
 	"github.com/elastos/Elastos.ELA/dpos/p2p/peer"
 	"github.com/elastos/Elastos.ELA/p2p"
+	"github.com/elastos/Elastos.ELA/p2p/nat"
 )
 
 // ConnState indicates the peer connection state.
@@ -122,4 +123,8 @@ type Server interface {
 	// DumpPeersInfo returns a list of connect peers information.  This is a
 	// high cost method, should not be called frequently.
 	DumpPeersInfo() []*PeerInfo
+
+	// NATStatus returns the state of the port mapping on the NAT gateway,
+	// nil if the NAT traversal is not enabled.
+	NATStatus() *nat.Status
 }
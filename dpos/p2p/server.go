@@ -25,6 +25,7 @@ import (
 	"github.com/elastos/Elastos.ELA/dpos/p2p/peer"
 
 	"github.com/elastos/Elastos.ELA/p2p"
+	"github.com/elastos/Elastos.ELA/p2p/nat"
 )
 
 const (
@@ -144,6 +145,7 @@ type server struct {
 	broadcast   chan broadcastMsg
 	wg          sync.WaitGroup
 	quit        chan struct{}
+	portMapper  *nat.PortMapper
 }
 
 // IPeer extends the peer to maintain state shared by the server.
@@ -718,6 +720,33 @@ func (s *server) DumpPeersInfo() []*PeerInfo {
 	return <-replyChan
 }
 
+// NATStatus returns the state of the port mapping on the NAT gateway, nil if
+// the NAT traversal is not enabled.
+//
+// This function is safe for concurrent access and is part of the
+// IServer interface implementation.
+func (s *server) NATStatus() *nat.Status {
+	if s.portMapper == nil {
+		return nil
+	}
+	status := s.portMapper.Status()
+	return &status
+}
+
+// onPortMapped warns if the mapped external address differs from the address
+// announced to other arbiters, inbound connections from them can not reach
+// this server in that case.
+func (s *server) onPortMapped(ip net.IP, port uint16) {
+	if port != s.cfg.DefaultPort {
+		log.Warnf("DPoS port %d is mapped to a different external port %d",
+			s.cfg.DefaultPort, port)
+	}
+	if host := net.ParseIP(s.cfg.Localhost); host != nil && !host.Equal(ip) {
+		log.Warnf("DPoS IP address %s differs from the external address %s",
+			s.cfg.Localhost, ip)
+	}
+}
+
 // Start begins accepting connections from peers.
 func (s *server) Start() {
 	// Already started?
@@ -732,6 +761,10 @@ func (s *server) Start() {
 	// managers.
 	s.wg.Add(1)
 	go s.peerHandler()
+
+	if s.portMapper != nil {
+		s.portMapper.Start()
+	}
 }
 
 // Stop gracefully shuts down the server by stopping and disconnecting all
@@ -745,6 +778,11 @@ func (s *server) Stop() error {
 
 	log.Warnf("server shutting down")
 
+	// Remove the port mapping on the NAT gateway.
+	if s.portMapper != nil {
+		s.portMapper.Stop()
+	}
+
 	// Signal the remaining goroutines to quit.
 	close(s.quit)
 	return nil
@@ -856,6 +894,16 @@ func NewServer(origCfg *Config) (*server, error) {
 		broadcast:   make(chan broadcastMsg, maxPeers),
 		quit:        make(chan struct{}),
 	}
+	if cfg.Upnp || cfg.NATPMP {
+		s.portMapper = nat.NewPortMapper(&nat.Config{
+			UPnP:        cfg.Upnp,
+			NATPMP:      cfg.NATPMP,
+			Gateway:     cfg.NATGateway,
+			Port:        cfg.DefaultPort,
+			Description: "dpos port",
+			OnMapped:    s.onPortMapped,
+		})
+	}
 
 	cmgr, err := connmgr.New(&connmgr.Config{
 		Listeners:     listeners,
@@ -42,15 +42,24 @@ const (
 	// MaxSnapshotLength defines the max length the snapshot map should take
 	MaxSnapshotLength = 20
 
-	none         = ChangeType(0x00)
-	updateNext   = ChangeType(0x01)
-	normalChange = ChangeType(0x02)
+	none                 = ChangeType(0x00)
+	updateNext           = ChangeType(0x01)
+	normalChange         = ChangeType(0x02)
+	understaffedRecovery = ChangeType(0x03)
 )
 
 var (
 	ErrInsufficientProducer = errors.New("producers count less than min arbitrators count")
 )
 
+// nodeKeyRotation records a node key replaced in the arbiter and candidate
+// lists, so the replacement can be reverted when the block is rolled back.
+type nodeKeyRotation struct {
+	height uint32
+	oldKey []byte
+	newKey []byte
+}
+
 type arbitrators struct {
 	*State
 	*degradation
@@ -81,8 +90,10 @@ type arbitrators struct {
 	snapshots            map[uint32][]*CheckPoint
 	snapshotKeysDesc     []uint32
 	lastCheckPointHeight uint32
+	nodeKeyRotations     []nodeKeyRotation
 
-	forceChanged bool
+	forceChanged     bool
+	electionListener func(*ElectionResult)
 }
 
 func (a *arbitrators) Start() {
@@ -112,6 +123,7 @@ func (a *arbitrators) recoverFromCheckPoints(point *CheckPoint) {
 	a.CurrentReward = point.CurrentReward
 	a.NextReward = point.NextReward
 	a.StateKeyFrame = &point.StateKeyFrame
+	a.rebuildFoldedNicknames()
 	a.accumulativeReward = point.accumulativeReward
 	a.finalRoundChange = point.finalRoundChange
 	a.clearingHeight = point.clearingHeight
@@ -259,13 +271,44 @@ func (a *arbitrators) ForceChange(height uint32) error {
 func (a *arbitrators) tryHandleError(height uint32, err error) error {
 	if err == ErrInsufficientProducer {
 		log.Warn("found error: ", err, ", degrade to CRC only state")
-		a.TrySetUnderstaffed(height)
+		if a.TrySetUnderstaffed(height) {
+			a.notifyUnderstaffedModeChanged()
+		}
 		return nil
 	} else {
 		return err
 	}
 }
 
+// notifyUnderstaffedModeChanged notifies the latest understaffed period after
+// the understaffed mode was entered or left.
+func (a *arbitrators) notifyUnderstaffedModeChanged() {
+	periods := a.GetUnderstaffedPeriods()
+	if !a.started || len(periods) == 0 {
+		return
+	}
+	go events.Notify(events.ETUnderstaffedModeChanged,
+		&periods[len(periods)-1])
+}
+
+// understaffedRecoveryChange changes the arbiters immediately to the newly
+// elected ones when leaving the understaffed mode.
+func (a *arbitrators) understaffedRecoveryChange(height uint32) error {
+	if err := a.updateNextArbitrators(height + 1); err != nil {
+		log.Warn("[UnderstaffedRecovery] update next arbiters error: ", err)
+		return err
+	}
+
+	if err := a.changeCurrentArbitrators(); err != nil {
+		log.Warn("[UnderstaffedRecovery] change current arbiters error: ", err)
+		return err
+	}
+
+	log.Info("[UnderstaffedRecovery] left understaffed mode at height: ",
+		height)
+	return nil
+}
+
 func (a *arbitrators) normalChange(height uint32) error {
 	if err := a.changeCurrentArbitrators(); err != nil {
 		log.Warn("[NormalChange] change current arbiters error: ", err)
@@ -285,6 +328,7 @@ func (a *arbitrators) IncreaseChainHeight(block *types.Block) {
 
 	a.mtx.Lock()
 
+	rotated := a.rotateNodeKeys(block)
 	changeType, versionHeight := a.getChangeType(block.Height + 1)
 	switch changeType {
 	case updateNext:
@@ -300,10 +344,19 @@ func (a *arbitrators) IncreaseChainHeight(block *types.Block) {
 			panic(fmt.Sprintf("normal change fail at height: %d, error: %s",
 				block.Height, err))
 		}
+	case understaffedRecovery:
+		if err := a.clearingDPOSReward(block, true); err != nil {
+			panic(fmt.Sprintf("understaffed recovery fail when clear DPOS "+
+				"reward, height: %d, error: %s", block.Height, err))
+		}
+		if err := a.understaffedRecoveryChange(block.Height); err != nil {
+			panic(fmt.Sprintf("understaffed recovery fail at height: %d, "+
+				"error: %s", block.Height, err))
+		}
 	case none:
 		a.accumulateReward(block)
 		a.dutyIndex++
-		notify = false
+		notify = rotated
 	}
 	a.illegalBlocksPayloadHashes = make(map[common.Uint256]interface{})
 
@@ -318,6 +371,86 @@ func (a *arbitrators) IncreaseChainHeight(block *types.Block) {
 	}
 }
 
+// rotateNodeKeys replaces the node public keys of current and next arbiters
+// and candidates rotated by the transactions of the block, so the new keys
+// take effect from the next block.  Returns if any key is replaced.
+func (a *arbitrators) rotateNodeKeys(block *types.Block) bool {
+	a.pruneNodeKeyRotations(block.Height)
+
+	var rotated bool
+	for _, tx := range block.Transactions {
+		if !tx.IsRotateNodeKeyTx() {
+			continue
+		}
+		p, ok := tx.Payload.(*payload.RotateNodeKey)
+		if !ok {
+			continue
+		}
+		var replaced bool
+		for _, keys := range a.nodeKeyLists() {
+			if replaceNodeKey(keys, p.NodePublicKey, p.NewNodePublicKey) {
+				replaced = true
+			}
+		}
+		if replaced {
+			a.nodeKeyRotations = append(a.nodeKeyRotations, nodeKeyRotation{
+				height: block.Height,
+				oldKey: p.NodePublicKey,
+				newKey: p.NewNodePublicKey,
+			})
+			rotated = true
+		}
+	}
+	return rotated
+}
+
+// revertNodeKeyRotations puts the old node keys back into the arbiter and
+// candidate lists for rotations happened above the given height.  Snapshots
+// may not cover the rotated heights, and replacing a key that is not in the
+// lists does nothing, so it is safe to revert after recovering a snapshot.
+func (a *arbitrators) revertNodeKeyRotations(height uint32) {
+	i := len(a.nodeKeyRotations) - 1
+	for ; i >= 0 && a.nodeKeyRotations[i].height > height; i-- {
+		r := a.nodeKeyRotations[i]
+		for _, keys := range a.nodeKeyLists() {
+			replaceNodeKey(keys, r.newKey, r.oldKey)
+		}
+	}
+	a.nodeKeyRotations = a.nodeKeyRotations[:i+1]
+}
+
+// pruneNodeKeyRotations removes rotations too old to be rolled back.
+func (a *arbitrators) pruneNodeKeyRotations(height uint32) {
+	var i int
+	for ; i < len(a.nodeKeyRotations); i++ {
+		if a.nodeKeyRotations[i].height+maxHistoryCapacity >= height {
+			break
+		}
+	}
+	a.nodeKeyRotations = a.nodeKeyRotations[i:]
+}
+
+// nodeKeyLists returns the lists holding node public keys of arbiters and
+// candidates.
+func (a *arbitrators) nodeKeyLists() []*[][]byte {
+	return []*[][]byte{&a.CurrentArbitrators, &a.currentCandidates,
+		&a.nextArbitrators, &a.nextCandidates}
+}
+
+// replaceNodeKey replaces the old key in the list with the new key, the list
+// is copied before changed since it may be shared with snapshots.
+func replaceNodeKey(keys *[][]byte, oldKey, newKey []byte) bool {
+	for i, k := range *keys {
+		if bytes.Equal(k, oldKey) {
+			list := copyByteList(*keys)
+			list[i] = newKey
+			*keys = list
+			return true
+		}
+	}
+	return false
+}
+
 func (a *arbitrators) accumulateReward(block *types.Block) {
 	if block.Height < a.State.chainParams.PublicDPOSHeight {
 		return
@@ -387,7 +520,7 @@ func (a *arbitrators) distributeWithNormalArbitrators(
 		math.Floor(totalBlockConfirmReward / float64(len(ownerHashes))))
 	totalVotesInRound := a.CurrentReward.TotalVotesInRound
 	if len(a.chainParams.CRCArbiters) == len(a.CurrentArbitrators) {
-		a.arbitersRoundReward[a.chainParams.CRCAddress] = reward
+		a.distributeWithCRCArbitrators(reward, ownerHashes)
 		return reward, nil
 	}
 	rewardPerVote := totalTopProducersReward / float64(totalVotesInRound)
@@ -400,7 +533,7 @@ func (a *arbitrators) distributeWithNormalArbitrators(
 		r := individualBlockConfirmReward + individualProducerReward
 		if _, ok := a.crcArbitratorsProgramHashes[*ownerHash]; ok {
 			r = individualBlockConfirmReward
-			a.arbitersRoundReward[a.chainParams.CRCAddress] += r
+			a.arbitersRoundReward[a.getCRCRewardHash(*ownerHash)] += r
 		} else {
 			a.arbitersRoundReward[*ownerHash] = r
 		}
@@ -419,6 +552,34 @@ func (a *arbitrators) distributeWithNormalArbitrators(
 	return realDPOSReward, nil
 }
 
+// distributeWithCRCArbitrators distributes the reward when all arbiters are CRC
+// arbiters, rewards of CRC arbiters with reward address are directed to their
+// reward addresses and the rest goes to the CRC address.
+func (a *arbitrators) distributeWithCRCArbitrators(reward common.Fixed64,
+	ownerHashes []*common.Uint168) {
+	individualReward := common.Fixed64(math.Floor(
+		float64(reward) / float64(len(ownerHashes))))
+	crcReward := reward
+	for _, ownerHash := range ownerHashes {
+		rewardHash, ok := a.State.GetCRCRewardAddress(*ownerHash)
+		if !ok {
+			continue
+		}
+		a.arbitersRoundReward[rewardHash] += individualReward
+		crcReward -= individualReward
+	}
+	a.arbitersRoundReward[a.chainParams.CRCAddress] += crcReward
+}
+
+// getCRCRewardHash returns the program hash to receive the rewards of the CRC
+// arbiter, it is the CRC address if no reward address has been set.
+func (a *arbitrators) getCRCRewardHash(ownerHash common.Uint168) common.Uint168 {
+	if rewardHash, ok := a.State.GetCRCRewardAddress(ownerHash); ok {
+		return rewardHash
+	}
+	return a.chainParams.CRCAddress
+}
+
 func (a *arbitrators) DecreaseChainHeight(height uint32) error {
 	a.mtx.Lock()
 	a.degradation.RollbackTo(height)
@@ -432,6 +593,7 @@ func (a *arbitrators) DecreaseChainHeight(height uint32) error {
 			break
 		}
 	}
+	a.revertNodeKeyRotations(height)
 	a.mtx.Unlock()
 
 	return nil
@@ -498,6 +660,16 @@ func (a *arbitrators) GetArbitrators() [][]byte {
 	return result
 }
 
+// GetKeyFrameHash returns the hash of current key frame, it's used to compare
+// arbiters state between nodes.
+func (a *arbitrators) GetKeyFrameHash() common.Uint256 {
+	a.mtx.Lock()
+	hash := a.KeyFrame.Hash()
+	a.mtx.Unlock()
+
+	return hash
+}
+
 func (a *arbitrators) GetCandidates() [][]byte {
 	a.mtx.Lock()
 	result := a.currentCandidates
@@ -553,6 +725,31 @@ func (a *arbitrators) IsCRCArbitrator(pk []byte) bool {
 	return ok
 }
 
+// GetArbiterRewardHash returns the program hash to receive the DPoS rewards of
+// the arbiter with the node public key.
+func (a *arbitrators) GetArbiterRewardHash(nodePublicKey []byte) (
+	common.Uint168, bool) {
+	if a.IsCRCArbitrator(nodePublicKey) {
+		// crc node public key is its owner public key for now
+		ownerHash, err := contract.PublicKeyToStandardProgramHash(nodePublicKey)
+		if err != nil {
+			return common.Uint168{}, false
+		}
+		return a.getCRCRewardHash(*ownerHash), true
+	}
+
+	producer := a.GetProducer(nodePublicKey)
+	if producer == nil {
+		return common.Uint168{}, false
+	}
+	ownerHash, err := contract.PublicKeyToStandardProgramHash(
+		producer.OwnerPublicKey())
+	if err != nil {
+		return common.Uint168{}, false
+	}
+	return *ownerHash, true
+}
+
 func (a *arbitrators) IsActiveProducer(pk []byte) bool {
 	return a.State.IsActiveProducer(pk)
 }
@@ -622,6 +819,42 @@ func (a *arbitrators) GetCrossChainArbitersMajorityCount() int {
 	return minSignCount
 }
 
+// GetCrossChainDutySchedule returns the on-duty cross-chain arbiters of the
+// next rounds slots in order, the first one is the current on-duty arbiter
+// and each of the following is on duty after one more block.
+func (a *arbitrators) GetCrossChainDutySchedule(rounds int) [][]byte {
+	if rounds <= 0 {
+		return nil
+	}
+
+	height := a.bestHeight()
+	schedule := make([][]byte, 0, rounds)
+	var crcArbiters [][]byte
+	for i := 0; i < rounds; i++ {
+		h := height + uint32(i)
+		if h < a.chainParams.CRCOnlyDPOSHeight-1 {
+			schedule = append(schedule,
+				a.GetNextOnDutyArbitratorV(height+1, uint32(i)))
+			continue
+		}
+
+		if crcArbiters == nil {
+			// Copy before sorting to leave the CRC arbiters untouched.
+			crcArbiters = append([][]byte{}, a.GetCRCArbiters()...)
+			if len(crcArbiters) == 0 {
+				break
+			}
+			sort.Slice(crcArbiters, func(i, j int) bool {
+				return bytes.Compare(crcArbiters[i], crcArbiters[j]) < 0
+			})
+		}
+		index := int(h-a.chainParams.CRCOnlyDPOSHeight+1) % len(crcArbiters)
+		schedule = append(schedule, crcArbiters[index])
+	}
+
+	return schedule
+}
+
 func (a *arbitrators) GetNextOnDutyArbitratorV(height, offset uint32) []byte {
 	// main version is >= H1
 	if height >= a.State.chainParams.CRCOnlyDPOSHeight {
@@ -691,6 +924,15 @@ func (a *arbitrators) getChangeType(height uint32) (ChangeType, uint32) {
 		return normalChange, a.State.chainParams.PublicDPOSHeight
 	}
 
+	// leave the understaffed mode without waiting for the end of the round
+	// when enough producers are voted again
+	if height >= a.State.chainParams.UnderstaffedRecoveryHeight &&
+		height > a.State.chainParams.PublicDPOSHeight &&
+		a.IsUnderstaffedMode() && len(a.State.GetVotedProducers()) >=
+		a.chainParams.GeneralArbiters {
+		return understaffedRecovery, height
+	}
+
 	// main version >= H2
 	if height > a.State.chainParams.PublicDPOSHeight &&
 		a.dutyIndex == len(a.CurrentArbitrators)-1 {
@@ -717,25 +959,45 @@ func (a *arbitrators) updateNextArbitrators(height uint32) error {
 	_, recover := a.InactiveModeSwitch(height, a.IsAbleToRecoverFromInactiveMode)
 	if recover {
 		a.LeaveEmergency()
-	} else {
-		a.TryLeaveUnderStaffed(a.IsAbleToRecoverFromUnderstaffedState)
+	} else if a.TryLeaveUnderStaffed(height,
+		a.IsAbleToRecoverFromUnderstaffedState) {
+		a.notifyUnderstaffedModeChanged()
 	}
 
 	a.nextArbitrators = make([][]byte, 0)
 	for _, v := range a.crcArbitratorsNodePublicKey {
 		a.nextArbitrators = append(a.nextArbitrators, v.info.NodePublicKey)
 	}
+	election := &ElectionResult{
+		Height:      height,
+		CRCArbiters: copyByteList(a.nextArbitrators),
+	}
 
 	if !a.IsInactiveMode() && !a.IsUnderstaffedMode() {
 		count := a.chainParams.GeneralArbiters
 		votedProducers := a.State.GetVotedProducers()
+		votes := make(map[*Producer]common.Fixed64, len(votedProducers))
+		for _, p := range votedProducers {
+			votes[p] = a.State.getEffectiveVotes(p, height)
+		}
 		sort.Slice(votedProducers, func(i, j int) bool {
-			if votedProducers[i].votes == votedProducers[j].votes {
+			if votes[votedProducers[i]] == votes[votedProducers[j]] {
 				return bytes.Compare(votedProducers[i].info.NodePublicKey,
 					votedProducers[j].NodePublicKey()) < 0
 			}
-			return votedProducers[i].Votes() > votedProducers[j].Votes()
+			return votes[votedProducers[i]] > votes[votedProducers[j]]
 		})
+		if a.electionListener != nil {
+			election.Producers = make([]ElectedProducer, 0, len(votedProducers))
+			for _, p := range votedProducers {
+				election.Producers = append(election.Producers, ElectedProducer{
+					OwnerPublicKey: p.OwnerPublicKey(),
+					NodePublicKey:  p.NodePublicKey(),
+					Votes:          p.Votes(),
+					EffectiveVotes: votes[p],
+				})
+			}
+		}
 
 		producers, err := a.GetNormalArbitratorsDesc(height, count,
 			votedProducers)
@@ -744,6 +1006,7 @@ func (a *arbitrators) updateNextArbitrators(height uint32) error {
 				return err
 			}
 			a.nextCandidates = make([][]byte, 0)
+			election.Mode = ElectionInsufficient
 		} else {
 			a.nextArbitrators = append(a.nextArbitrators, producers...)
 
@@ -753,10 +1016,17 @@ func (a *arbitrators) updateNextArbitrators(height uint32) error {
 				return err
 			}
 			a.nextCandidates = candidates
+			election.Arbiters = producers
+			election.Candidates = candidates
 		}
 	} else {
 		a.nextCandidates = make([][]byte, 0)
+		election.Mode = ElectionUnderstaffed
+		if a.IsInactiveMode() {
+			election.Mode = ElectionInactive
+		}
 	}
+	a.notifyElection(election)
 
 	if err := a.snapshotVotesStates(); err != nil {
 		return err
@@ -907,9 +1177,7 @@ func (a *arbitrators) dumpInfo(height uint32) {
 	var printer func(string, ...interface{})
 	changeType, _ := a.getChangeType(height + 1)
 	switch changeType {
-	case updateNext:
-		fallthrough
-	case normalChange:
+	case updateNext, normalChange, understaffedRecovery:
 		printer = log.Infof
 	case none:
 		printer = log.Debugf
@@ -936,7 +1204,8 @@ func (a *arbitrators) getBlockDPOSReward(block *types.Block) common.Fixed64 {
 	}
 
 	return common.Fixed64(math.Ceil(float64(totalTxFx+
-		a.chainParams.RewardPerBlock) * 0.35))
+		a.chainParams.RewardPerBlock) *
+		a.chainParams.GetRewardPolicy(block.Height).DPoSRewardRatio))
 }
 
 func (a *arbitrators) newCheckPoint(height uint32) *CheckPoint {
@@ -10,7 +10,11 @@ import (
 	"crypto/rand"
 	"testing"
 
+	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/core/contract"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -129,3 +133,178 @@ func randomFakePK() []byte {
 	rand.Read(pk)
 	return pk
 }
+
+func TestArbitrators_DistributeWithCRCRewardAddress(t *testing.T) {
+	arbitrators, _ := NewArbitrators(&config.DefaultParams, nil)
+	arbitrators.CurrentArbitrators = arbitrators.crcArbiters
+	arbitrators.CurrentReward.OwnerProgramHashes = nil
+	for _, pk := range arbitrators.crcArbiters {
+		hash, _ := contract.PublicKeyToStandardProgramHash(pk)
+		arbitrators.CurrentReward.OwnerProgramHashes = append(
+			arbitrators.CurrentReward.OwnerProgramHashes, hash)
+	}
+	crcAddress := arbitrators.chainParams.CRCAddress
+	reward := common.Fixed64(1200)
+
+	// All rewards go to the CRC address by default.
+	assert.NoError(t, arbitrators.distributeDPOSReward(reward))
+	assert.Equal(t, reward, arbitrators.arbitersRoundReward[crcAddress])
+
+	// Direct the rewards of the first CRC arbiter to the reward address.
+	rewardHash := *randomProgramHash()
+	arbitrators.State.ProcessBlock(mockBlock(1, &types.Transaction{
+		TxType: types.CRCRewardAddress,
+		Payload: &payload.CRCRewardAddress{
+			NodePublicKey:     arbitrators.crcArbiters[0],
+			RewardProgramHash: rewardHash,
+		},
+	}), nil)
+	ownerHash := arbitrators.CurrentReward.OwnerProgramHashes[0]
+	hash, ok := arbitrators.State.GetCRCRewardAddress(*ownerHash)
+	assert.True(t, ok)
+	assert.Equal(t, rewardHash, hash)
+	height, ok := arbitrators.State.GetCRCRewardAddressHeight(*ownerHash)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(1), height)
+
+	assert.NoError(t, arbitrators.distributeDPOSReward(reward))
+	individualReward := reward / common.Fixed64(len(arbitrators.crcArbiters))
+	assert.Equal(t, individualReward, arbitrators.arbitersRoundReward[rewardHash])
+	assert.Equal(t, reward-individualReward,
+		arbitrators.arbitersRoundReward[crcAddress])
+	assert.Equal(t, common.Fixed64(0), arbitrators.finalRoundChange)
+
+	// Rollback the reward address.
+	assert.NoError(t, arbitrators.State.RollbackTo(0))
+	_, ok = arbitrators.State.GetCRCRewardAddress(*ownerHash)
+	assert.False(t, ok)
+	_, ok = arbitrators.State.GetCRCRewardAddressHeight(*ownerHash)
+	assert.False(t, ok)
+	assert.NoError(t, arbitrators.distributeDPOSReward(reward))
+	assert.Equal(t, reward, arbitrators.arbitersRoundReward[crcAddress])
+}
+
+func TestArbitrators_GetCrossChainDutySchedule(t *testing.T) {
+	var bestHeight uint32
+
+	params := config.DefaultParams
+	arbitrators, _ := NewArbitrators(&params, nil)
+	arbitrators.RegisterFunction(func() uint32 { return bestHeight },
+		nil)
+	arbitrators.crcArbiters = [][]byte{randomFakePK(), randomFakePK(),
+		randomFakePK()}
+	crcArbiters := append([][]byte{}, arbitrators.crcArbiters...)
+
+	assert.Nil(t, arbitrators.GetCrossChainDutySchedule(0))
+
+	// The schedule should match the on-duty arbiter of each height.
+	bestHeight = params.CRCOnlyDPOSHeight + 10
+	schedule := arbitrators.GetCrossChainDutySchedule(7)
+	assert.Equal(t, 7, len(schedule))
+	for i, arbiter := range schedule {
+		bestHeight = params.CRCOnlyDPOSHeight + 10 + uint32(i)
+		assert.Equal(t, arbitrators.GetOnDutyCrossChainArbitrator(), arbiter)
+	}
+	assert.Equal(t, schedule[0], schedule[3])
+
+	// The CRC arbiters should not be reordered by the query.
+	bestHeight = params.CRCOnlyDPOSHeight + 10
+	arbitrators.crcArbiters = append([][]byte{}, crcArbiters...)
+	arbitrators.GetCrossChainDutySchedule(3)
+	assert.Equal(t, crcArbiters, arbitrators.crcArbiters)
+}
+
+func TestArbitrators_UnderstaffedPeriods(t *testing.T) {
+	arbitrators, _ := NewArbitrators(&config.DefaultParams, nil)
+	recoverable := false
+	isAbleToRecover := func() bool { return recoverable }
+
+	assert.True(t, arbitrators.TrySetUnderstaffed(100))
+	assert.False(t, arbitrators.TrySetUnderstaffed(101))
+	assert.False(t, arbitrators.TryLeaveUnderStaffed(110, isAbleToRecover))
+	assert.True(t, arbitrators.IsUnderstaffedMode())
+
+	recoverable = true
+	assert.True(t, arbitrators.TryLeaveUnderStaffed(120, isAbleToRecover))
+	assert.False(t, arbitrators.IsUnderstaffedMode())
+	assert.False(t, arbitrators.TryLeaveUnderStaffed(121, isAbleToRecover))
+
+	assert.True(t, arbitrators.TrySetUnderstaffed(200))
+	assert.Equal(t, []UnderstaffedPeriod{
+		{EnterHeight: 100, ExitHeight: 120},
+		{EnterHeight: 200},
+	}, arbitrators.GetUnderstaffedPeriods())
+
+	// rollback forgets the changes above the height
+	arbitrators.RollbackTo(150)
+	assert.False(t, arbitrators.IsUnderstaffedMode())
+	assert.Equal(t, []UnderstaffedPeriod{
+		{EnterHeight: 100, ExitHeight: 120},
+	}, arbitrators.GetUnderstaffedPeriods())
+	arbitrators.RollbackTo(110)
+	assert.Equal(t, []UnderstaffedPeriod{
+		{EnterHeight: 100},
+	}, arbitrators.GetUnderstaffedPeriods())
+
+	// only the recent periods are kept
+	arbitrators.Reset()
+	for i := uint32(0); i < MaxUnderstaffedPeriods+5; i++ {
+		arbitrators.TrySetUnderstaffed(1000 + i*10)
+		arbitrators.TryLeaveUnderStaffed(1000+i*10+5, isAbleToRecover)
+	}
+	periods := arbitrators.GetUnderstaffedPeriods()
+	assert.Equal(t, MaxUnderstaffedPeriods, len(periods))
+	assert.Equal(t, uint32(1050), periods[0].EnterHeight)
+}
+
+func TestArbitrators_RotateNodeKeys(t *testing.T) {
+	params := config.DefaultParams
+	params.CRCOnlyDPOSHeight = 0
+	params.PreConnectOffset = 0
+	arbitrators, _ := NewArbitrators(&params, nil)
+
+	oldKey, newKey := randomFakePK(), randomFakePK()
+	current := [][]byte{randomFakePK(), oldKey}
+	arbitrators.CurrentArbitrators = current
+	arbitrators.nextArbitrators = [][]byte{oldKey, randomFakePK()}
+	arbitrators.nextCandidates = [][]byte{randomFakePK()}
+
+	rotateTx := &types.Transaction{
+		TxType: types.RotateNodeKey,
+		Payload: &payload.RotateNodeKey{
+			OwnerPublicKey:   randomFakePK(),
+			NodePublicKey:    oldKey,
+			NewNodePublicKey: newKey,
+		},
+	}
+	assert.False(t, arbitrators.rotateNodeKeys(mockBlock(1)))
+	assert.True(t, arbitrators.rotateNodeKeys(mockBlock(1, rotateTx)))
+
+	assert.Equal(t, newKey, arbitrators.CurrentArbitrators[1])
+	assert.Equal(t, newKey, arbitrators.nextArbitrators[0])
+	// The original list may be shared with snapshots, it should not change.
+	assert.Equal(t, oldKey, current[1])
+
+	// The new node key should be connected instead of the old one.
+	var connectNew, connectOld bool
+	for _, pid := range arbitrators.GetNeedConnectArbiters() {
+		connectNew = connectNew || bytes.Equal(pid[:], newKey)
+		connectOld = connectOld || bytes.Equal(pid[:], oldKey)
+	}
+	assert.True(t, connectNew)
+	assert.False(t, connectOld)
+
+	// Rolling back the block should put the old key back, even though the
+	// only snapshot is taken after the rotation.
+	arbitrators.snapshot(1)
+	assert.NoError(t, arbitrators.DecreaseChainHeight(0))
+	assert.Equal(t, oldKey, arbitrators.CurrentArbitrators[1])
+	assert.Equal(t, oldKey, arbitrators.nextArbitrators[0])
+	assert.Equal(t, 0, len(arbitrators.nodeKeyRotations))
+
+	// Rotations out of the rollback range are not kept.
+	assert.True(t, arbitrators.rotateNodeKeys(mockBlock(1, rotateTx)))
+	assert.False(t, arbitrators.rotateNodeKeys(
+		mockBlock(maxHistoryCapacity+2)))
+	assert.Equal(t, 0, len(arbitrators.nodeKeyRotations))
+}
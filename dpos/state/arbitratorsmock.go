@@ -85,6 +85,20 @@ func (a *ArbitratorsMock) GetSnapshot(height uint32) []*KeyFrame {
 	return a.Snapshot
 }
 
+func (a *ArbitratorsMock) DiffKeyFrames(h1, h2 uint32) (*KeyFrameDiff,
+	error) {
+	return &KeyFrameDiff{FromHeight: h1, ToHeight: h2}, nil
+}
+
+func (a *ArbitratorsMock) GetKeyFrameHash() common.Uint256 {
+	keyFrame := KeyFrame{CurrentArbitrators: a.CurrentArbitrators}
+	return keyFrame.Hash()
+}
+
+func (a *ArbitratorsMock) GetStateKeyFrameHash() (common.Uint256, error) {
+	return NewStateKeyFrame().Hash()
+}
+
 func (a *ArbitratorsMock) IsActiveProducer(pk []byte) bool {
 	for _, v := range a.ActiveProducer {
 		if bytes.Equal(v, pk) {
@@ -94,10 +108,19 @@ func (a *ArbitratorsMock) IsActiveProducer(pk []byte) bool {
 	return false
 }
 
+func (a *ArbitratorsMock) GetArbiterRewardHash(nodePublicKey []byte) (
+	common.Uint168, bool) {
+	return common.Uint168{}, false
+}
+
 func (a *ArbitratorsMock) IsUnderstaffedMode() bool {
 	return false
 }
 
+func (a *ArbitratorsMock) GetUnderstaffedPeriods() []UnderstaffedPeriod {
+	return nil
+}
+
 func (a *ArbitratorsMock) IsInactiveMode() bool {
 	return a.InactiveMode
 }
@@ -208,6 +231,14 @@ func (a *ArbitratorsMock) GetCrossChainArbiters() [][]byte {
 	return a.CurrentArbitrators
 }
 
+func (a *ArbitratorsMock) GetCrossChainDutySchedule(rounds int) [][]byte {
+	schedule := make([][]byte, 0, rounds)
+	for i := 0; i < rounds; i++ {
+		schedule = append(schedule, a.GetNextOnDutyArbitrator(uint32(i)))
+	}
+	return schedule
+}
+
 func (a *ArbitratorsMock) GetDutyChangeCount() int {
 	return a.DutyChangedCount
 }
@@ -286,3 +317,7 @@ func (a *ArbitratorsMock) HasArbitersMinorityCount(num int) bool {
 
 func (a *ArbitratorsMock) DumpInfo(height uint32) {
 }
+
+func (a *ArbitratorsMock) CheckInvariants(height uint32) error {
+	return nil
+}
@@ -73,6 +73,7 @@ func (c *CheckPoint) OnRollbackTo(height uint32) error {
 		}
 		c.initFromArbitrators(ar)
 		c.arbitrators.RecoverFromCheckPoints(c)
+		c.arbitrators.State.removeProducerHistories(height)
 		return nil
 	}
 	return c.arbitrators.RollbackTo(height)
@@ -106,6 +107,14 @@ func (c *CheckPoint) Snapshot() checkpoint.ICheckPoint {
 	point.NextCandidates = copyByteList(c.arbitrators.nextCandidates)
 	point.CurrentReward = *copyReward(&c.arbitrators.CurrentReward)
 	point.NextReward = *copyReward(&c.arbitrators.NextReward)
+	log.Infof("[CheckPoint] key frame hash at height %d: %s", c.Height,
+		point.KeyFrame.Hash())
+	if hash, err := point.StateKeyFrame.Hash(); err != nil {
+		c.LogError(err)
+	} else {
+		log.Infof("[CheckPoint] state key frame hash at height %d: %s",
+			c.Height, hash)
+	}
 	return point
 }
 
@@ -20,6 +20,17 @@ const (
 	DSInactive     degradationState = 0x02
 )
 
+// MaxUnderstaffedPeriods defines the max count of recent understaffed periods
+// kept for monitoring.
+const MaxUnderstaffedPeriods = 20
+
+// UnderstaffedPeriod records the heights the understaffed mode was entered and
+// left, ExitHeight is zero if the mode has not been left yet.
+type UnderstaffedPeriod struct {
+	EnterHeight uint32
+	ExitHeight  uint32
+}
+
 // degradation maintains states which will take effect during
 // degradation period.
 type degradation struct {
@@ -29,6 +40,8 @@ type degradation struct {
 	understaffedSince uint32
 	inactivateHeight  uint32
 	inactiveTxs       map[common.Uint256]interface{}
+
+	understaffedPeriods []UnderstaffedPeriod
 }
 
 func (d *degradation) IsUnderstaffedMode() bool {
@@ -47,11 +60,34 @@ func (d *degradation) IsInactiveMode() bool {
 	return result
 }
 
+// GetUnderstaffedPeriods returns the recent understaffed periods in ascending
+// order of height.
+func (d *degradation) GetUnderstaffedPeriods() []UnderstaffedPeriod {
+	d.mtx.Lock()
+	result := make([]UnderstaffedPeriod, len(d.understaffedPeriods))
+	copy(result, d.understaffedPeriods)
+	d.mtx.Unlock()
+
+	return result
+}
+
 func (d *degradation) RollbackTo(height uint32) {
 	d.mtx.Lock()
 	// if rollback to the height before abnormal mode was set,
 	// then reset inactive related state
 	needReset := height < d.inactivateHeight || height < d.understaffedSince
+
+	// forget the understaffed mode changes above the height
+	for i := len(d.understaffedPeriods) - 1; i >= 0; i-- {
+		period := &d.understaffedPeriods[i]
+		if period.EnterHeight > height {
+			d.understaffedPeriods = d.understaffedPeriods[:i]
+			continue
+		}
+		if period.ExitHeight > height {
+			period.ExitHeight = 0
+		}
+	}
 	d.mtx.Unlock()
 
 	if needReset {
@@ -93,16 +129,34 @@ func (d *degradation) TrySetUnderstaffed(height uint32) bool {
 	}
 	d.understaffedSince = height
 	d.state = DSUnderstaffed
+	d.understaffedPeriods = append(d.understaffedPeriods,
+		UnderstaffedPeriod{EnterHeight: height})
+	if len(d.understaffedPeriods) > MaxUnderstaffedPeriods {
+		d.understaffedPeriods = d.understaffedPeriods[1:]
+	}
 	d.mtx.Unlock()
 	return true
 }
 
-func (d *degradation) TryLeaveUnderStaffed(isAbleToRecover func() bool) bool {
-	if isAbleToRecover() {
-		d.Reset()
-		return true
+// TryLeaveUnderStaffed resets the degradation state if it is able to recover,
+// and returns true if the understaffed mode has been left at the height.
+func (d *degradation) TryLeaveUnderStaffed(height uint32,
+	isAbleToRecover func() bool) bool {
+	if !isAbleToRecover() {
+		return false
 	}
-	return false
+
+	d.mtx.Lock()
+	left := d.state == DSUnderstaffed
+	if left {
+		if last := len(d.understaffedPeriods) - 1; last >= 0 {
+			d.understaffedPeriods[last].ExitHeight = height
+		}
+	}
+	d.mtx.Unlock()
+
+	d.Reset()
+	return left
 }
 
 // Reset method reset all
@@ -0,0 +1,207 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+// maxElectionProducers is the max count of producers of an election result.
+const maxElectionProducers = 1 << 16
+
+// ElectionMode is the mode the next arbiters are elected in.
+type ElectionMode byte
+
+const (
+	// ElectionNormal indicates the normal arbiters are elected by votes.
+	ElectionNormal ElectionMode = iota
+
+	// ElectionUnderstaffed indicates no normal arbiters are elected since
+	// the arbiters are in the understaffed mode.
+	ElectionUnderstaffed
+
+	// ElectionInactive indicates no normal arbiters are elected since the
+	// arbiters are in the inactive mode.
+	ElectionInactive
+
+	// ElectionInsufficient indicates no normal arbiters are elected since
+	// the voted producers are not enough.
+	ElectionInsufficient
+)
+
+// electionModeStrings is a array of election modes back to their constant
+// names for pretty printing.
+var electionModeStrings = []string{"Normal", "Understaffed", "Inactive",
+	"Insufficient"}
+
+func (m ElectionMode) String() string {
+	if int(m) < len(electionModeStrings) {
+		return electionModeStrings[m]
+	}
+	return fmt.Sprintf("ElectionMode-%d", m)
+}
+
+// ElectedProducer is a producer taking part in an election, with the votes
+// it has and the effective votes it is ranked by.
+type ElectedProducer struct {
+	OwnerPublicKey []byte
+	NodePublicKey  []byte
+	Votes          common.Fixed64
+	EffectiveVotes common.Fixed64
+}
+
+// ElectionResult is the result of an election of the next arbiters at the
+// height, the producers are in the order they are ranked.
+type ElectionResult struct {
+	Height      uint32
+	Mode        ElectionMode
+	CRCArbiters [][]byte
+	Arbiters    [][]byte
+	Candidates  [][]byte
+	Producers   []ElectedProducer
+}
+
+func serializeKeys(w io.Writer, keys [][]byte) error {
+	if err := common.WriteVarUint(w, uint64(len(keys))); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := common.WriteVarBytes(w, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deserializeKeys(r io.Reader) ([][]byte, error) {
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count > maxElectionProducers {
+		return nil, io.ErrUnexpectedEOF
+	}
+	keys := make([][]byte, 0, count)
+	for i := uint64(0); i < count; i++ {
+		key, err := common.ReadVarBytes(r, crypto.NegativeBigLength,
+			"public key")
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (e *ElectionResult) Serialize(w io.Writer) error {
+	if err := common.WriteUint32(w, e.Height); err != nil {
+		return err
+	}
+	if err := common.WriteUint8(w, uint8(e.Mode)); err != nil {
+		return err
+	}
+	if err := serializeKeys(w, e.CRCArbiters); err != nil {
+		return err
+	}
+	if err := serializeKeys(w, e.Arbiters); err != nil {
+		return err
+	}
+	if err := serializeKeys(w, e.Candidates); err != nil {
+		return err
+	}
+
+	if err := common.WriteVarUint(w, uint64(len(e.Producers))); err != nil {
+		return err
+	}
+	for _, p := range e.Producers {
+		if err := common.WriteVarBytes(w, p.OwnerPublicKey); err != nil {
+			return err
+		}
+		if err := common.WriteVarBytes(w, p.NodePublicKey); err != nil {
+			return err
+		}
+		if err := p.Votes.Serialize(w); err != nil {
+			return err
+		}
+		if err := p.EffectiveVotes.Serialize(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *ElectionResult) Deserialize(r io.Reader) (err error) {
+	if e.Height, err = common.ReadUint32(r); err != nil {
+		return
+	}
+	var mode uint8
+	if mode, err = common.ReadUint8(r); err != nil {
+		return
+	}
+	e.Mode = ElectionMode(mode)
+	if e.CRCArbiters, err = deserializeKeys(r); err != nil {
+		return
+	}
+	if e.Arbiters, err = deserializeKeys(r); err != nil {
+		return
+	}
+	if e.Candidates, err = deserializeKeys(r); err != nil {
+		return
+	}
+
+	var count uint64
+	if count, err = common.ReadVarUint(r, 0); err != nil {
+		return
+	}
+	if count > maxElectionProducers {
+		return io.ErrUnexpectedEOF
+	}
+	e.Producers = make([]ElectedProducer, count)
+	for i := range e.Producers {
+		p := &e.Producers[i]
+		if p.OwnerPublicKey, err = common.ReadVarBytes(r,
+			crypto.NegativeBigLength, "owner public key"); err != nil {
+			return
+		}
+		if p.NodePublicKey, err = common.ReadVarBytes(r,
+			crypto.NegativeBigLength, "node public key"); err != nil {
+			return
+		}
+		if err = p.Votes.Deserialize(r); err != nil {
+			return
+		}
+		if err = p.EffectiveVotes.Deserialize(r); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// SetElectionListener sets the function called with the result of each
+// election of the next arbiters, the listener is called within the arbiters
+// lock so it should not block.
+func (a *arbitrators) SetElectionListener(listener func(*ElectionResult)) {
+	a.mtx.Lock()
+	a.electionListener = listener
+	a.mtx.Unlock()
+}
+
+// notifyElection calls the election listener with the election result.
+func (a *arbitrators) notifyElection(result *ElectionResult) {
+	if a.electionListener == nil {
+		return
+	}
+	sort.Slice(result.CRCArbiters, func(i, j int) bool {
+		return bytes.Compare(result.CRCArbiters[i], result.CRCArbiters[j]) < 0
+	})
+	a.electionListener(result)
+}
@@ -257,3 +257,25 @@ func TestArbitrators_GetNextOnDutyArbitrator(t *testing.T) {
 	currentArbitrator = arbiters.GetNextOnDutyArbitrator(0)
 	assert.Equal(t, sortedArbiters[0], common.BytesToHexString(currentArbitrator))
 }
+
+func TestArbitrators_SetElectionListener(t *testing.T) {
+	var election *ElectionResult
+	arbiters.SetElectionListener(func(result *ElectionResult) {
+		election = result
+	})
+	defer arbiters.SetElectionListener(nil)
+
+	height := arbiters.State.chainParams.CRCOnlyDPOSHeight
+	assert.NoError(t, arbiters.updateNextArbitrators(height))
+	if !assert.NotNil(t, election) {
+		return
+	}
+	assert.Equal(t, height, election.Height)
+	assert.Equal(t, ElectionNormal, election.Mode)
+	assert.Equal(t, len(arbiters.State.chainParams.CRCArbiters),
+		len(election.CRCArbiters))
+	for i := 1; i < len(election.CRCArbiters); i++ {
+		assert.True(t, bytes.Compare(election.CRCArbiters[i-1],
+			election.CRCArbiters[i]) < 0)
+	}
+}
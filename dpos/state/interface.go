@@ -31,11 +31,13 @@ type Arbitrators interface {
 	GetFinalRoundChange() common.Fixed64
 	IsInactiveMode() bool
 	IsUnderstaffedMode() bool
+	GetUnderstaffedPeriods() []UnderstaffedPeriod
 
 	GetCRCArbiters() [][]byte
 	GetCRCProducer(publicKey []byte) *Producer
 	GetCRCArbitrators() map[string]*Producer
 	IsCRCArbitrator(pk []byte) bool
+	GetArbiterRewardHash(nodePublicKey []byte) (common.Uint168, bool)
 	IsActiveProducer(pk []byte) bool
 	IsDisabledProducer(pk []byte) bool
 
@@ -46,6 +48,7 @@ type Arbitrators interface {
 	GetCrossChainArbiters() [][]byte
 	GetCrossChainArbitersCount() int
 	GetCrossChainArbitersMajorityCount() int
+	GetCrossChainDutySchedule(rounds int) [][]byte
 
 	GetArbitersCount() int
 	GetCRCArbitersCount() int
@@ -54,11 +57,17 @@ type Arbitrators interface {
 	HasArbitersMinorityCount(num int) bool
 
 	GetSnapshot(height uint32) []*KeyFrame
+	GetKeyFrameHash() common.Uint256
+	GetStateKeyFrameHash() (common.Uint256, error)
+	DiffKeyFrames(h1, h2 uint32) (*KeyFrameDiff, error)
 	DumpInfo(height uint32)
+	CheckInvariants(height uint32) error
 }
 
 type IArbitratorsRecord interface {
 	GetHeightsDesc() ([]uint32, error)
 	GetCheckPoint(height uint32) (*CheckPoint, error)
+	GetCheckPointRange() (uint32, uint32, error)
 	SaveArbitersState(point *CheckPoint) error
+	CompactCheckPoints() error
 }
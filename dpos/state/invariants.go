@@ -0,0 +1,123 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package state
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+)
+
+// CheckInvariants cross-checks the arbiters and producers state processed to
+// the height, returns an error describing all violations found.  It is only
+// used in debug mode since it walks through all vote and deposit outputs.
+func (a *arbitrators) CheckInvariants(height uint32) error {
+	a.mtx.Lock()
+	violations := a.checkArbitersCount(height)
+	a.mtx.Unlock()
+
+	violations = append(violations, a.State.checkInvariants()...)
+	if len(violations) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(violations, "\n"))
+}
+
+// checkArbitersCount checks the count of current arbiters against the
+// config, there are only CRC arbiters in CRC only DPoS stage, inactive mode
+// and understaffed mode.
+func (a *arbitrators) checkArbitersCount(height uint32) []string {
+	if height < a.chainParams.CRCOnlyDPOSHeight {
+		return nil
+	}
+
+	var violations []string
+	crcCount := len(a.chainParams.CRCArbiters)
+	count := len(a.CurrentArbitrators)
+	if count != crcCount && count != crcCount+a.chainParams.GeneralArbiters {
+		violations = append(violations, fmt.Sprintf("arbiters count %d, "+
+			"expect %d or %d", count, crcCount,
+			crcCount+a.chainParams.GeneralArbiters))
+	}
+
+	arbiters := make(map[string]struct{}, count)
+	for _, v := range a.CurrentArbitrators {
+		key := hex.EncodeToString(v)
+		if _, ok := arbiters[key]; ok {
+			violations = append(violations, fmt.Sprintf("duplicated "+
+				"arbiter %s", key))
+		}
+		arbiters[key] = struct{}{}
+	}
+	for key := range a.crcArbitratorsNodePublicKey {
+		if _, ok := arbiters[key]; !ok {
+			violations = append(violations, fmt.Sprintf("CRC arbiter %s "+
+				"not in current arbiters", key))
+		}
+	}
+	return violations
+}
+
+// checkInvariants checks the votes and deposit amount of producers against
+// the vote outputs and deposit outputs tracked by state.
+func (s *State) checkInvariants() []string {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	votes := make(map[*Producer]common.Fixed64)
+	for _, output := range s.Votes {
+		// canceled vote outputs are kept as nil
+		if output == nil {
+			continue
+		}
+		p := output.Payload.(*outputpayload.VoteOutput)
+		for _, content := range p.Contents {
+			if content.VoteType != outputpayload.Delegate {
+				continue
+			}
+			for _, cv := range content.CandidateVotes {
+				producer := s.getProducer(cv.Candidate)
+				if producer == nil {
+					continue
+				}
+				if p.Version == outputpayload.VoteProducerVersion {
+					votes[producer] += output.Value
+				} else {
+					votes[producer] += cv.Votes
+				}
+			}
+		}
+	}
+
+	deposits := make(map[common.Uint168]common.Fixed64)
+	for _, output := range s.DepositOutputs {
+		deposits[output.ProgramHash] += output.Value
+	}
+
+	var violations []string
+	for _, producer := range s.getAllProducers() {
+		key := hex.EncodeToString(producer.info.OwnerPublicKey)
+		if producer.votes != votes[producer] {
+			violations = append(violations, fmt.Sprintf("producer %s votes "+
+				"%s, vote outputs %s", key, producer.votes, votes[producer]))
+		}
+
+		// deposit outputs are not removed after returned
+		if producer.state == Returned {
+			continue
+		}
+		if producer.depositAmount != deposits[producer.depositHash] {
+			violations = append(violations, fmt.Sprintf("producer %s "+
+				"deposit amount %s, deposit outputs %s", key,
+				producer.depositAmount, deposits[producer.depositHash]))
+		}
+	}
+	return violations
+}
@@ -6,10 +6,24 @@
 package state
 
 import (
+	"bytes"
 	"io"
 
 	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/crypto"
+	"github.com/elastos/Elastos.ELA/utils"
+)
+
+const (
+	// StateKeyFrameVersion is the version of state key frames written before
+	// stake info, vote decay, node key rotation, CRC reward addresses and
+	// producer appeals were added.
+	StateKeyFrameVersion byte = 0x00
+
+	// StateKeyFrameLatestVersion is the version of state key frames carrying
+	// all producer states.
+	StateKeyFrameLatestVersion byte = 0x01
 )
 
 // KeyFrame holds necessary state about arbitrators
@@ -36,6 +50,9 @@ type StateKeyFrame struct {
 	EmergencyInactiveArbiters map[string]struct{}
 	VersionStartHeight        uint32
 	VersionEndHeight          uint32
+	CRCRewardAddresses        map[common.Uint168]common.Uint168 // CRC arbiter program hash as key, reward program hash as value
+	CRCRewardAddressHeights   map[common.Uint168]uint32         // CRC arbiter program hash as key, height of the last reward address change as value
+	ProducerAppeals           map[string]uint32                 // producer owner public key as key, activation height as value
 }
 
 // RewardData defines variables to calculate reward of a round
@@ -46,6 +63,101 @@ type RewardData struct {
 	TotalVotesInRound           common.Fixed64
 }
 
+// Hash returns the merkle root of current arbiters, the arbiters keep their
+// duty order since the order is part of the consensus state.
+func (k *KeyFrame) Hash() common.Uint256 {
+	hashes := make([]common.Uint256, 0, len(k.CurrentArbitrators))
+	for _, a := range k.CurrentArbitrators {
+		hashes = append(hashes, common.Uint256(common.Sha256D(a)))
+	}
+	root, _ := crypto.ComputeRoot(hashes)
+	return root
+}
+
+// Hash returns the canonical hash of the state key frame. Entries of each map
+// are hashed one by one and combined as the merkle root of the sorted entry
+// hashes, so the result does not depend on the iteration order of maps, then
+// the roots of all fields are combined by the order of serialization.
+func (s *StateKeyFrame) Hash() (hash common.Uint256, err error) {
+	roots := make([]common.Uint256, 0, 20)
+	var root common.Uint256
+	if root, err = hashStringMap(s.NodeOwnerKeys); err != nil {
+		return
+	}
+	roots = append(roots, root)
+
+	for _, pmap := range []map[string]*Producer{s.PendingProducers,
+		s.ActivityProducers, s.InactiveProducers, s.CanceledProducers,
+		s.IllegalProducers, s.PendingCanceledProducers} {
+		if root, err = hashProducerMap(pmap); err != nil {
+			return
+		}
+		roots = append(roots, root)
+	}
+
+	for _, vmap := range []map[string]*types.Output{s.Votes,
+		s.DepositOutputs} {
+		if root, err = hashOutputsMap(vmap); err != nil {
+			return
+		}
+		roots = append(roots, root)
+	}
+
+	if root, err = hashStringSet(s.Nicknames); err != nil {
+		return
+	}
+	roots = append(roots, root)
+
+	if root, err = hashHashSet(s.SpecialTxHashes); err != nil {
+		return
+	}
+	roots = append(roots, root)
+
+	if root, err = hashStringSet(s.PreBlockArbiters); err != nil {
+		return
+	}
+	roots = append(roots, root)
+
+	if root, err = hashDIDSet(s.ProducerDepositMap); err != nil {
+		return
+	}
+	roots = append(roots, root)
+
+	if root, err = hashStringSet(s.EmergencyInactiveArbiters); err != nil {
+		return
+	}
+	roots = append(roots, root)
+
+	for _, height := range []uint32{s.VersionStartHeight,
+		s.VersionEndHeight} {
+		h := height
+		if root, err = hashEntry(func(w io.Writer) error {
+			return common.WriteUint32(w, h)
+		}); err != nil {
+			return
+		}
+		roots = append(roots, root)
+	}
+
+	if root, err = hashProgramHashMap(s.CRCRewardAddresses); err != nil {
+		return
+	}
+	roots = append(roots, root)
+
+	if root, err = hashProgramHashHeightMap(
+		s.CRCRewardAddressHeights); err != nil {
+		return
+	}
+	roots = append(roots, root)
+
+	if root, err = hashStringHeightMap(s.ProducerAppeals); err != nil {
+		return
+	}
+	roots = append(roots, root)
+
+	return crypto.ComputeRoot(roots)
+}
+
 // snapshot takes a snapshot of current state and returns the copy.
 func (s *StateKeyFrame) snapshot() *StateKeyFrame {
 	state := StateKeyFrame{
@@ -62,6 +174,9 @@ func (s *StateKeyFrame) snapshot() *StateKeyFrame {
 		SpecialTxHashes:          make(map[common.Uint256]struct{}),
 		PreBlockArbiters:         make(map[string]struct{}),
 		ProducerDepositMap:       make(map[common.Uint168]struct{}),
+		CRCRewardAddresses:       make(map[common.Uint168]common.Uint168),
+		CRCRewardAddressHeights:  make(map[common.Uint168]uint32),
+		ProducerAppeals:          make(map[string]uint32),
 	}
 	state.NodeOwnerKeys = copyStringMap(s.NodeOwnerKeys)
 	state.PendingProducers = copyProducerMap(s.PendingProducers)
@@ -76,36 +191,47 @@ func (s *StateKeyFrame) snapshot() *StateKeyFrame {
 	state.SpecialTxHashes = copyHashSet(s.SpecialTxHashes)
 	state.PreBlockArbiters = copyStringSet(s.PreBlockArbiters)
 	state.ProducerDepositMap = copyDIDSet(s.ProducerDepositMap)
+	state.EmergencyInactiveArbiters = copyStringSet(s.EmergencyInactiveArbiters)
+	state.VersionStartHeight = s.VersionStartHeight
+	state.VersionEndHeight = s.VersionEndHeight
+	state.CRCRewardAddresses = copyProgramHashMap(s.CRCRewardAddresses)
+	state.CRCRewardAddressHeights = copyProgramHashHeightMap(
+		s.CRCRewardAddressHeights)
+	state.ProducerAppeals = copyStringHeightMap(s.ProducerAppeals)
 	return &state
 }
 
 func (s *StateKeyFrame) Serialize(w io.Writer) (err error) {
+	version := StateKeyFrameLatestVersion
+	if err = utils.WriteKeyFrameVersion(w, version); err != nil {
+		return
+	}
 
 	if err = s.SerializeStringMap(s.NodeOwnerKeys, w); err != nil {
 		return
 	}
 
-	if err = s.SerializeProducerMap(s.PendingProducers, w); err != nil {
+	if err = s.SerializeProducerMap(s.PendingProducers, w, version); err != nil {
 		return
 	}
 
-	if err = s.SerializeProducerMap(s.ActivityProducers, w); err != nil {
+	if err = s.SerializeProducerMap(s.ActivityProducers, w, version); err != nil {
 		return
 	}
 
-	if err = s.SerializeProducerMap(s.InactiveProducers, w); err != nil {
+	if err = s.SerializeProducerMap(s.InactiveProducers, w, version); err != nil {
 		return
 	}
 
-	if err = s.SerializeProducerMap(s.CanceledProducers, w); err != nil {
+	if err = s.SerializeProducerMap(s.CanceledProducers, w, version); err != nil {
 		return
 	}
 
-	if err = s.SerializeProducerMap(s.IllegalProducers, w); err != nil {
+	if err = s.SerializeProducerMap(s.IllegalProducers, w, version); err != nil {
 		return
 	}
 
-	if err = s.SerializeProducerMap(s.PendingCanceledProducers, w); err != nil {
+	if err = s.SerializeProducerMap(s.PendingCanceledProducers, w, version); err != nil {
 		return
 	}
 
@@ -141,35 +267,53 @@ func (s *StateKeyFrame) Serialize(w io.Writer) (err error) {
 		return
 	}
 
-	return common.WriteUint32(w, s.VersionEndHeight)
+	if err = common.WriteUint32(w, s.VersionEndHeight); err != nil {
+		return
+	}
+
+	if err = s.SerializeProgramHashMap(s.CRCRewardAddresses, w); err != nil {
+		return
+	}
+
+	if err = s.SerializeProgramHashHeightMap(s.CRCRewardAddressHeights,
+		w); err != nil {
+		return
+	}
+
+	return s.SerializeStringHeightMap(s.ProducerAppeals, w)
 }
 
 func (s *StateKeyFrame) Deserialize(r io.Reader) (err error) {
+	var version byte
+	if version, r, err = utils.ReadKeyFrameVersion(r); err != nil {
+		return
+	}
+
 	if s.NodeOwnerKeys, err = s.DeserializeStringMap(r); err != nil {
 		return
 	}
 
-	if s.PendingProducers, err = s.DeserializeProducerMap(r); err != nil {
+	if s.PendingProducers, err = s.DeserializeProducerMap(r, version); err != nil {
 		return
 	}
 
-	if s.ActivityProducers, err = s.DeserializeProducerMap(r); err != nil {
+	if s.ActivityProducers, err = s.DeserializeProducerMap(r, version); err != nil {
 		return
 	}
 
-	if s.InactiveProducers, err = s.DeserializeProducerMap(r); err != nil {
+	if s.InactiveProducers, err = s.DeserializeProducerMap(r, version); err != nil {
 		return
 	}
 
-	if s.CanceledProducers, err = s.DeserializeProducerMap(r); err != nil {
+	if s.CanceledProducers, err = s.DeserializeProducerMap(r, version); err != nil {
 		return
 	}
 
-	if s.IllegalProducers, err = s.DeserializeProducerMap(r); err != nil {
+	if s.IllegalProducers, err = s.DeserializeProducerMap(r, version); err != nil {
 		return
 	}
 
-	if s.PendingCanceledProducers, err = s.DeserializeProducerMap(r); err != nil {
+	if s.PendingCanceledProducers, err = s.DeserializeProducerMap(r, version); err != nil {
 		return
 	}
 
@@ -208,6 +352,26 @@ func (s *StateKeyFrame) Deserialize(r io.Reader) (err error) {
 	if s.VersionEndHeight, err = common.ReadUint32(r); err != nil {
 		return
 	}
+
+	if version < StateKeyFrameLatestVersion {
+		s.CRCRewardAddresses = make(map[common.Uint168]common.Uint168)
+		s.CRCRewardAddressHeights = make(map[common.Uint168]uint32)
+		s.ProducerAppeals = make(map[string]uint32)
+		return
+	}
+
+	if s.CRCRewardAddresses, err = s.DeserializeProgramHashMap(r); err != nil {
+		return
+	}
+
+	if s.CRCRewardAddressHeights, err = s.DeserializeProgramHashHeightMap(
+		r); err != nil {
+		return
+	}
+
+	if s.ProducerAppeals, err = s.DeserializeStringHeightMap(r); err != nil {
+		return
+	}
 	return
 }
 
@@ -398,7 +562,7 @@ func (s *StateKeyFrame) DeserializeStringMap(
 }
 
 func (s *StateKeyFrame) SerializeProducerMap(pmap map[string]*Producer,
-	w io.Writer) (err error) {
+	w io.Writer, version byte) (err error) {
 	if err = common.WriteVarUint(w, uint64(len(pmap))); err != nil {
 		return
 	}
@@ -407,15 +571,15 @@ func (s *StateKeyFrame) SerializeProducerMap(pmap map[string]*Producer,
 			return
 		}
 
-		if err = v.Serialize(w); err != nil {
+		if err = v.Serialize(w, version); err != nil {
 			return
 		}
 	}
 	return
 }
 
-func (s *StateKeyFrame) DeserializeProducerMap(
-	r io.Reader) (pmap map[string]*Producer, err error) {
+func (s *StateKeyFrame) DeserializeProducerMap(r io.Reader,
+	version byte) (pmap map[string]*Producer, err error) {
 	var count uint64
 	if count, err = common.ReadVarUint(r, 0); err != nil {
 		return
@@ -427,7 +591,7 @@ func (s *StateKeyFrame) DeserializeProducerMap(
 			return
 		}
 		producer := &Producer{}
-		if err = producer.Deserialize(r); err != nil {
+		if err = producer.Deserialize(r, version); err != nil {
 			return
 		}
 		pmap[k] = producer
@@ -435,6 +599,122 @@ func (s *StateKeyFrame) DeserializeProducerMap(
 	return
 }
 
+func (s *StateKeyFrame) SerializeProgramHashMap(
+	hmap map[common.Uint168]common.Uint168, w io.Writer) (err error) {
+	if err = common.WriteVarUint(w, uint64(len(hmap))); err != nil {
+		return
+	}
+	for k, v := range hmap {
+		if err = k.Serialize(w); err != nil {
+			return
+		}
+
+		if err = v.Serialize(w); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (s *StateKeyFrame) DeserializeProgramHashMap(
+	r io.Reader) (hmap map[common.Uint168]common.Uint168, err error) {
+	var count uint64
+	if count, err = common.ReadVarUint(r, 0); err != nil {
+		return
+	}
+	hmap = make(map[common.Uint168]common.Uint168)
+	for i := uint64(0); i < count; i++ {
+		var k, v common.Uint168
+		if err = k.Deserialize(r); err != nil {
+			return
+		}
+
+		if err = v.Deserialize(r); err != nil {
+			return
+		}
+		hmap[k] = v
+	}
+	return
+}
+
+func (s *StateKeyFrame) SerializeProgramHashHeightMap(
+	hmap map[common.Uint168]uint32, w io.Writer) (err error) {
+	if err = common.WriteVarUint(w, uint64(len(hmap))); err != nil {
+		return
+	}
+	for k, v := range hmap {
+		if err = k.Serialize(w); err != nil {
+			return
+		}
+
+		if err = common.WriteUint32(w, v); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (s *StateKeyFrame) DeserializeProgramHashHeightMap(
+	r io.Reader) (hmap map[common.Uint168]uint32, err error) {
+	var count uint64
+	if count, err = common.ReadVarUint(r, 0); err != nil {
+		return
+	}
+	hmap = make(map[common.Uint168]uint32)
+	for i := uint64(0); i < count; i++ {
+		var k common.Uint168
+		if err = k.Deserialize(r); err != nil {
+			return
+		}
+
+		var v uint32
+		if v, err = common.ReadUint32(r); err != nil {
+			return
+		}
+		hmap[k] = v
+	}
+	return
+}
+
+func (s *StateKeyFrame) SerializeStringHeightMap(hmap map[string]uint32,
+	w io.Writer) (err error) {
+	if err = common.WriteVarUint(w, uint64(len(hmap))); err != nil {
+		return
+	}
+	for k, v := range hmap {
+		if err = common.WriteVarString(w, k); err != nil {
+			return
+		}
+
+		if err = common.WriteUint32(w, v); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (s *StateKeyFrame) DeserializeStringHeightMap(
+	r io.Reader) (hmap map[string]uint32, err error) {
+	var count uint64
+	if count, err = common.ReadVarUint(r, 0); err != nil {
+		return
+	}
+	hmap = make(map[string]uint32)
+	for i := uint64(0); i < count; i++ {
+		var k string
+		if k, err = common.ReadVarString(r); err != nil {
+			return
+		}
+
+		var v uint32
+		if v, err = common.ReadUint32(r); err != nil {
+			return
+		}
+		hmap[k] = v
+	}
+	return
+}
+
 func NewStateKeyFrame() *StateKeyFrame {
 	return &StateKeyFrame{
 		NodeOwnerKeys:             make(map[string]string),
@@ -453,6 +733,9 @@ func NewStateKeyFrame() *StateKeyFrame {
 		ProducerDepositMap:        make(map[common.Uint168]struct{}),
 		VersionStartHeight:        0,
 		VersionEndHeight:          0,
+		CRCRewardAddresses:        make(map[common.Uint168]common.Uint168),
+		CRCRewardAddressHeights:   make(map[common.Uint168]uint32),
+		ProducerAppeals:           make(map[string]uint32),
 	}
 }
 
@@ -564,6 +847,33 @@ func copyProducerMap(src map[string]*Producer) (dst map[string]*Producer) {
 	return
 }
 
+// copyProgramHashMap copy the src map's key, value pairs into dst map.
+func copyProgramHashMap(src map[common.Uint168]common.Uint168) (
+	dst map[common.Uint168]common.Uint168) {
+	dst = map[common.Uint168]common.Uint168{}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return
+}
+
+func copyProgramHashHeightMap(src map[common.Uint168]uint32) (
+	dst map[common.Uint168]uint32) {
+	dst = map[common.Uint168]uint32{}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return
+}
+
+func copyStringHeightMap(src map[string]uint32) (dst map[string]uint32) {
+	dst = map[string]uint32{}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return
+}
+
 func copyStringMap(src map[string]string) (dst map[string]string) {
 	dst = map[string]string{}
 	for k, v := range src {
@@ -641,3 +951,159 @@ func copyReward(src *RewardData) (dst *RewardData) {
 	}
 	return
 }
+
+func hashEntry(serialize func(w io.Writer) error) (common.Uint256, error) {
+	buf := new(bytes.Buffer)
+	if err := serialize(buf); err != nil {
+		return common.Uint256{}, err
+	}
+	return common.Uint256(common.Sha256D(buf.Bytes())), nil
+}
+
+func hashStringMap(smap map[string]string) (common.Uint256, error) {
+	hashes := make([]common.Uint256, 0, len(smap))
+	for k, v := range smap {
+		hash, err := hashEntry(func(w io.Writer) error {
+			if err := common.WriteVarString(w, k); err != nil {
+				return err
+			}
+			return common.WriteVarString(w, v)
+		})
+		if err != nil {
+			return common.Uint256{}, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return crypto.ComputeSortedRoot(hashes), nil
+}
+
+func hashProducerMap(pmap map[string]*Producer) (common.Uint256, error) {
+	hashes := make([]common.Uint256, 0, len(pmap))
+	for k, v := range pmap {
+		hash, err := hashEntry(func(w io.Writer) error {
+			if err := common.WriteVarString(w, k); err != nil {
+				return err
+			}
+			return v.Serialize(w, StateKeyFrameLatestVersion)
+		})
+		if err != nil {
+			return common.Uint256{}, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return crypto.ComputeSortedRoot(hashes), nil
+}
+
+func hashOutputsMap(vmap map[string]*types.Output) (common.Uint256, error) {
+	hashes := make([]common.Uint256, 0, len(vmap))
+	for k, v := range vmap {
+		hash, err := hashEntry(func(w io.Writer) error {
+			if err := common.WriteVarString(w, k); err != nil {
+				return err
+			}
+			if v == nil {
+				return common.WriteUint8(w, 0)
+			}
+			if err := common.WriteUint8(w, 1); err != nil {
+				return err
+			}
+			return v.Serialize(w, types.TxVersion09)
+		})
+		if err != nil {
+			return common.Uint256{}, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return crypto.ComputeSortedRoot(hashes), nil
+}
+
+func hashStringSet(vmap map[string]struct{}) (common.Uint256, error) {
+	hashes := make([]common.Uint256, 0, len(vmap))
+	for k := range vmap {
+		hash, err := hashEntry(func(w io.Writer) error {
+			return common.WriteVarString(w, k)
+		})
+		if err != nil {
+			return common.Uint256{}, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return crypto.ComputeSortedRoot(hashes), nil
+}
+
+func hashHashSet(vmap map[common.Uint256]struct{}) (common.Uint256, error) {
+	hashes := make([]common.Uint256, 0, len(vmap))
+	for k := range vmap {
+		hash, err := hashEntry(k.Serialize)
+		if err != nil {
+			return common.Uint256{}, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return crypto.ComputeSortedRoot(hashes), nil
+}
+
+func hashDIDSet(vmap map[common.Uint168]struct{}) (common.Uint256, error) {
+	hashes := make([]common.Uint256, 0, len(vmap))
+	for k := range vmap {
+		hash, err := hashEntry(k.Serialize)
+		if err != nil {
+			return common.Uint256{}, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return crypto.ComputeSortedRoot(hashes), nil
+}
+
+func hashProgramHashMap(pmap map[common.Uint168]common.Uint168) (
+	common.Uint256, error) {
+	hashes := make([]common.Uint256, 0, len(pmap))
+	for k, v := range pmap {
+		hash, err := hashEntry(func(w io.Writer) error {
+			if err := k.Serialize(w); err != nil {
+				return err
+			}
+			return v.Serialize(w)
+		})
+		if err != nil {
+			return common.Uint256{}, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return crypto.ComputeSortedRoot(hashes), nil
+}
+
+func hashProgramHashHeightMap(hmap map[common.Uint168]uint32) (
+	common.Uint256, error) {
+	hashes := make([]common.Uint256, 0, len(hmap))
+	for k, v := range hmap {
+		hash, err := hashEntry(func(w io.Writer) error {
+			if err := k.Serialize(w); err != nil {
+				return err
+			}
+			return common.WriteUint32(w, v)
+		})
+		if err != nil {
+			return common.Uint256{}, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return crypto.ComputeSortedRoot(hashes), nil
+}
+
+func hashStringHeightMap(hmap map[string]uint32) (common.Uint256, error) {
+	hashes := make([]common.Uint256, 0, len(hmap))
+	for k, v := range hmap {
+		hash, err := hashEntry(func(w io.Writer) error {
+			if err := common.WriteVarString(w, k); err != nil {
+				return err
+			}
+			return common.WriteUint32(w, v)
+		})
+		if err != nil {
+			return common.Uint256{}, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return crypto.ComputeSortedRoot(hashes), nil
+}
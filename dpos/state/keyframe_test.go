@@ -42,6 +42,84 @@ func TestStateKeyFrame_Deserialize(t *testing.T) {
 	assert.True(t, stateKeyFrameEqual(originFrame, cmpData))
 }
 
+func TestStateKeyFrame_DeserializeLegacy(t *testing.T) {
+	frame := randomStateKeyFrame()
+	frame.CRCRewardAddresses = make(map[common.Uint168]common.Uint168)
+	frame.CRCRewardAddressHeights = make(map[common.Uint168]uint32)
+	frame.ProducerAppeals = make(map[string]uint32)
+	for _, m := range []map[string]*Producer{frame.PendingProducers,
+		frame.ActivityProducers, frame.InactiveProducers,
+		frame.CanceledProducers, frame.IllegalProducers,
+		frame.PendingCanceledProducers} {
+		for _, p := range m {
+			p.voteDecayEndHeight = 0
+			p.nodeKeyRotationHeight = 0
+		}
+	}
+
+	// write the key frame in the layout used before versioning
+	version := StateKeyFrameVersion
+	buf := new(bytes.Buffer)
+	assert.NoError(t, frame.SerializeStringMap(frame.NodeOwnerKeys, buf))
+	assert.NoError(t, frame.SerializeProducerMap(frame.PendingProducers, buf, version))
+	assert.NoError(t, frame.SerializeProducerMap(frame.ActivityProducers, buf, version))
+	assert.NoError(t, frame.SerializeProducerMap(frame.InactiveProducers, buf, version))
+	assert.NoError(t, frame.SerializeProducerMap(frame.CanceledProducers, buf, version))
+	assert.NoError(t, frame.SerializeProducerMap(frame.IllegalProducers, buf, version))
+	assert.NoError(t, frame.SerializeProducerMap(frame.PendingCanceledProducers, buf, version))
+	assert.NoError(t, frame.SerializeOutputsMap(frame.Votes, buf))
+	assert.NoError(t, frame.SerializeOutputsMap(frame.DepositOutputs, buf))
+	assert.NoError(t, frame.SerializeStringSet(frame.Nicknames, buf))
+	assert.NoError(t, frame.SerializeHashSet(frame.SpecialTxHashes, buf))
+	assert.NoError(t, frame.SerializeStringSet(frame.PreBlockArbiters, buf))
+	assert.NoError(t, frame.SerializeDIDSet(frame.ProducerDepositMap, buf))
+	assert.NoError(t, frame.SerializeStringSet(frame.EmergencyInactiveArbiters, buf))
+	assert.NoError(t, common.WriteUint32(buf, frame.VersionStartHeight))
+	assert.NoError(t, common.WriteUint32(buf, frame.VersionEndHeight))
+
+	cmpData := &StateKeyFrame{}
+	assert.NoError(t, cmpData.Deserialize(buf))
+	assert.Equal(t, 0, buf.Len())
+	assert.True(t, stateKeyFrameEqual(frame, cmpData))
+	assert.NotNil(t, cmpData.CRCRewardAddresses)
+	assert.NotNil(t, cmpData.CRCRewardAddressHeights)
+	assert.NotNil(t, cmpData.ProducerAppeals)
+}
+
+func TestStateKeyFrame_Hash(t *testing.T) {
+	frame := randomStateKeyFrame()
+	hash, err := frame.Hash()
+	assert.NoError(t, err)
+
+	// maps rebuilt in another insertion order should have the same hash
+	buf := new(bytes.Buffer)
+	assert.NoError(t, frame.Serialize(buf))
+	frame2 := &StateKeyFrame{}
+	assert.NoError(t, frame2.Deserialize(buf))
+	hash2, err := frame2.Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, hash, hash2)
+
+	frame3 := frame.snapshot()
+	hash3, err := frame3.Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, hash, hash3)
+
+	// any change of entries should change the hash
+	for _, p := range frame3.ActivityProducers {
+		p.votes++
+		break
+	}
+	hash3, err = frame3.Hash()
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, hash3)
+
+	frame2.VersionEndHeight++
+	hash2, err = frame2.Hash()
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, hash2)
+}
+
 func TestCheckPoint_Deserialize(t *testing.T) {
 	originCheckPoint := generateCheckPoint(rand.Uint32())
 
@@ -247,6 +325,27 @@ func stateKeyFrameEqual(first *StateKeyFrame, second *StateKeyFrame) bool {
 		}
 	}
 
+	for k, vf := range first.CRCRewardAddresses {
+		vs, ok := second.CRCRewardAddresses[k]
+		if !ok || !vf.IsEqual(vs) {
+			return false
+		}
+	}
+
+	for k, vf := range first.CRCRewardAddressHeights {
+		vs, ok := second.CRCRewardAddressHeights[k]
+		if !ok || vf != vs {
+			return false
+		}
+	}
+
+	for k, vf := range first.ProducerAppeals {
+		vs, ok := second.ProducerAppeals[k]
+		if !ok || vf != vs {
+			return false
+		}
+	}
+
 	return first.VersionStartHeight == second.VersionStartHeight &&
 		first.VersionEndHeight == second.VersionEndHeight
 }
@@ -268,6 +367,9 @@ func randomStateKeyFrame() *StateKeyFrame {
 		EmergencyInactiveArbiters: make(map[string]struct{}),
 		VersionStartHeight:        rand.Uint32(),
 		VersionEndHeight:          rand.Uint32(),
+		CRCRewardAddresses:        make(map[common.Uint168]common.Uint168),
+		CRCRewardAddressHeights:   make(map[common.Uint168]uint32),
+		ProducerAppeals:           make(map[string]uint32),
 	}
 
 	for i := 0; i < 5; i++ {
@@ -284,6 +386,9 @@ func randomStateKeyFrame() *StateKeyFrame {
 		result.SpecialTxHashes[*randomHash()] = struct{}{}
 		result.PreBlockArbiters[randomString()] = struct{}{}
 		result.EmergencyInactiveArbiters[randomString()] = struct{}{}
+		result.CRCRewardAddresses[*randomProgramHash()] = *randomProgramHash()
+		result.CRCRewardAddressHeights[*randomProgramHash()] = rand.Uint32()
+		result.ProducerAppeals[randomString()] = rand.Uint32()
 	}
 	return result
 }
@@ -297,7 +402,9 @@ func producerEqual(first *Producer, second *Producer) bool {
 		first.activateRequestHeight != second.activateRequestHeight ||
 		first.illegalHeight != second.illegalHeight ||
 		first.penalty != second.penalty ||
-		first.votes != second.votes {
+		first.votes != second.votes ||
+		first.voteDecayEndHeight != second.voteDecayEndHeight ||
+		first.nodeKeyRotationHeight != second.nodeKeyRotationHeight {
 		return false
 	}
 
@@ -413,6 +520,8 @@ func randomProducer() *Producer {
 		illegalHeight:          rand.Uint32(),
 		penalty:                common.Fixed64(rand.Uint64()),
 		votes:                  common.Fixed64(rand.Uint64()),
+		voteDecayEndHeight:     rand.Uint32(),
+		nodeKeyRotationHeight:  rand.Uint32(),
 	}
 }
 
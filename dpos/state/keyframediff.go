@@ -0,0 +1,248 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+// FieldChange records the old and new value of a field changed between two
+// key frames.
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// ProducerDiff records the changed fields of a producer existing in both key
+// frames.
+type ProducerDiff struct {
+	OwnerPublicKey string
+	Changes        []FieldChange
+}
+
+// RewardDiff records the change of the votes in round of an owner.
+type RewardDiff struct {
+	ProgramHash common.Uint168
+	Old         common.Fixed64
+	New         common.Fixed64
+}
+
+// KeyFrameDiff records the changes of DPoS state between two key frames,
+// producers are identified by owner public key in hex string and votes by
+// the referenced output key.
+type KeyFrameDiff struct {
+	FromHeight uint32
+	ToHeight   uint32
+
+	AddedProducers   []string
+	RemovedProducers []string
+	ChangedProducers []ProducerDiff
+
+	AddedArbiters   []string
+	RemovedArbiters []string
+
+	AddedVotes   []string
+	RemovedVotes []string
+
+	RewardChanges        []RewardDiff
+	OldTotalVotesInRound common.Fixed64
+	NewTotalVotesInRound common.Fixed64
+}
+
+// IsEmpty returns if nothing changed between the two key frames.
+func (d *KeyFrameDiff) IsEmpty() bool {
+	return len(d.AddedProducers) == 0 && len(d.RemovedProducers) == 0 &&
+		len(d.ChangedProducers) == 0 && len(d.AddedArbiters) == 0 &&
+		len(d.RemovedArbiters) == 0 && len(d.AddedVotes) == 0 &&
+		len(d.RemovedVotes) == 0 && len(d.RewardChanges) == 0 &&
+		d.OldTotalVotesInRound == d.NewTotalVotesInRound
+}
+
+// DiffCheckPoints returns the changes from the from checkpoint to the to
+// checkpoint.
+func DiffCheckPoints(from, to *CheckPoint) *KeyFrameDiff {
+	diff := &KeyFrameDiff{
+		FromHeight:           from.Height,
+		ToHeight:             to.Height,
+		OldTotalVotesInRound: from.CurrentReward.TotalVotesInRound,
+		NewTotalVotesInRound: to.CurrentReward.TotalVotesInRound,
+	}
+
+	fromProducers := allProducers(&from.StateKeyFrame)
+	toProducers := allProducers(&to.StateKeyFrame)
+	for key, p := range toProducers {
+		origin, ok := fromProducers[key]
+		if !ok {
+			diff.AddedProducers = append(diff.AddedProducers, key)
+			continue
+		}
+		if changes := diffProducer(origin, p); len(changes) > 0 {
+			diff.ChangedProducers = append(diff.ChangedProducers,
+				ProducerDiff{OwnerPublicKey: key, Changes: changes})
+		}
+	}
+	for key := range fromProducers {
+		if _, ok := toProducers[key]; !ok {
+			diff.RemovedProducers = append(diff.RemovedProducers, key)
+		}
+	}
+	sort.Strings(diff.AddedProducers)
+	sort.Strings(diff.RemovedProducers)
+	sort.Slice(diff.ChangedProducers, func(i, j int) bool {
+		return diff.ChangedProducers[i].OwnerPublicKey <
+			diff.ChangedProducers[j].OwnerPublicKey
+	})
+
+	diff.AddedArbiters, diff.RemovedArbiters = diffStringSets(
+		bytesListSet(from.CurrentArbitrators),
+		bytesListSet(to.CurrentArbitrators))
+
+	fromVotes := make(map[string]struct{}, len(from.Votes))
+	for k := range from.Votes {
+		fromVotes[k] = struct{}{}
+	}
+	toVotes := make(map[string]struct{}, len(to.Votes))
+	for k := range to.Votes {
+		toVotes[k] = struct{}{}
+	}
+	diff.AddedVotes, diff.RemovedVotes = diffStringSets(fromVotes, toVotes)
+
+	fromRewards := from.CurrentReward.OwnerVotesInRound
+	toRewards := to.CurrentReward.OwnerVotesInRound
+	for hash, votes := range toRewards {
+		if old, ok := fromRewards[hash]; !ok || old != votes {
+			diff.RewardChanges = append(diff.RewardChanges,
+				RewardDiff{ProgramHash: hash, Old: old, New: votes})
+		}
+	}
+	for hash, votes := range fromRewards {
+		if _, ok := toRewards[hash]; !ok {
+			diff.RewardChanges = append(diff.RewardChanges,
+				RewardDiff{ProgramHash: hash, Old: votes})
+		}
+	}
+	sort.Slice(diff.RewardChanges, func(i, j int) bool {
+		return diff.RewardChanges[i].ProgramHash.Compare(
+			diff.RewardChanges[j].ProgramHash) < 0
+	})
+
+	return diff
+}
+
+// DiffKeyFrames returns the changes of DPoS state from the checkpoint of
+// height h1 to the checkpoint of height h2.  Heights not lower than the
+// latest saved checkpoint are resolved to the current state.
+func (a *arbitrators) DiffKeyFrames(h1, h2 uint32) (*KeyFrameDiff, error) {
+	from, err := a.getKeyFrameCheckPoint(h1)
+	if err != nil {
+		return nil, err
+	}
+	to, err := a.getKeyFrameCheckPoint(h2)
+	if err != nil {
+		return nil, err
+	}
+	return DiffCheckPoints(from, to), nil
+}
+
+func (a *arbitrators) getKeyFrameCheckPoint(height uint32) (*CheckPoint,
+	error) {
+	if a.chainParams == nil || a.chainParams.CkpManager == nil {
+		return nil, errors.New("checkpoint manager not initialized")
+	}
+	point, ok := a.chainParams.CkpManager.GetCheckpoint(checkpointKey, height)
+	if !ok || point == nil {
+		return nil, fmt.Errorf("no DPoS checkpoint found at height %d",
+			height)
+	}
+	cp, ok := point.(*CheckPoint)
+	if !ok {
+		return nil, errors.New("invalid DPoS checkpoint type")
+	}
+	if cp.arbitrators == nil {
+		return cp, nil
+	}
+
+	// the registered checkpoint shares state with arbitrators, so take a
+	// snapshot of the current state instead.
+	a.mtx.Lock()
+	snapshot := a.newCheckPoint(a.bestHeight())
+	a.mtx.Unlock()
+	return snapshot, nil
+}
+
+// allProducers returns producers of all states with owner public key as key.
+func allProducers(frame *StateKeyFrame) map[string]*Producer {
+	producers := make(map[string]*Producer)
+	for _, m := range []map[string]*Producer{
+		frame.PendingProducers,
+		frame.ActivityProducers,
+		frame.InactiveProducers,
+		frame.CanceledProducers,
+		frame.IllegalProducers,
+		frame.PendingCanceledProducers,
+	} {
+		for k, v := range m {
+			producers[k] = v
+		}
+	}
+	return producers
+}
+
+func diffProducer(from, to *Producer) []FieldChange {
+	var changes []FieldChange
+	check := func(field string, origin, current interface{}) {
+		o, n := fmt.Sprint(origin), fmt.Sprint(current)
+		if o != n {
+			changes = append(changes, FieldChange{Field: field, Old: o, New: n})
+		}
+	}
+	check("state", from.state, to.state)
+	check("nodepublickey", common.BytesToHexString(from.info.NodePublicKey),
+		common.BytesToHexString(to.info.NodePublicKey))
+	check("nickname", from.info.NickName, to.info.NickName)
+	check("url", from.info.Url, to.info.Url)
+	check("location", from.info.Location, to.info.Location)
+	check("netaddress", from.info.NetAddress, to.info.NetAddress)
+	check("votes", from.votes, to.votes)
+	check("penalty", from.penalty, to.penalty)
+	check("depositamount", from.depositAmount, to.depositAmount)
+	check("registerheight", from.registerHeight, to.registerHeight)
+	check("cancelheight", from.cancelHeight, to.cancelHeight)
+	check("inactivesince", from.inactiveSince, to.inactiveSince)
+	check("illegalheight", from.illegalHeight, to.illegalHeight)
+	return changes
+}
+
+func bytesListSet(list [][]byte) map[string]struct{} {
+	set := make(map[string]struct{}, len(list))
+	for _, v := range list {
+		set[common.BytesToHexString(v)] = struct{}{}
+	}
+	return set
+}
+
+// diffStringSets returns the sorted keys added to and removed from the from
+// set.
+func diffStringSets(from, to map[string]struct{}) (added, removed []string) {
+	for k := range to {
+		if _, ok := from[k]; !ok {
+			added = append(added, k)
+		}
+	}
+	for k := range from {
+		if _, ok := to[k]; !ok {
+			removed = append(removed, k)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package state
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffCheckPoints(t *testing.T) {
+	from := generateCheckPoint(720)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, from.Serialize(buf))
+	to := &CheckPoint{}
+	assert.NoError(t, to.Deserialize(buf))
+	to.Height = 1440
+
+	// nothing changed
+	diff := DiffCheckPoints(from, to)
+	assert.Equal(t, uint32(720), diff.FromHeight)
+	assert.Equal(t, uint32(1440), diff.ToHeight)
+	assert.True(t, diff.IsEmpty())
+
+	// add, remove and change producers
+	added := randomString()
+	to.PendingProducers[added] = randomProducer()
+	var removed, changed string
+	for k := range to.CanceledProducers {
+		removed = k
+		break
+	}
+	delete(to.CanceledProducers, removed)
+	for k := range to.ActivityProducers {
+		changed = k
+		break
+	}
+	producer := to.ActivityProducers[changed]
+	oldVotes := producer.votes
+	producer.votes += 100
+	delete(to.ActivityProducers, changed)
+	to.InactiveProducers[changed] = producer
+	producer.state = Inactive
+
+	// add and remove votes
+	addedVote := randomString()
+	to.Votes[addedVote] = randomVotes()
+	var removedVote string
+	for k := range to.Votes {
+		if k != addedVote {
+			removedVote = k
+			break
+		}
+	}
+	delete(to.Votes, removedVote)
+
+	// change arbiters and rewards
+	removedArbiter := to.CurrentArbitrators[0]
+	addedArbiter := randomFakePK()
+	to.CurrentArbitrators[0] = addedArbiter
+	rewardHash := *randomProgramHash()
+	to.CurrentReward.OwnerVotesInRound[rewardHash] = 10
+	to.CurrentReward.TotalVotesInRound += 10
+
+	diff = DiffCheckPoints(from, to)
+	assert.False(t, diff.IsEmpty())
+	assert.Equal(t, []string{added}, diff.AddedProducers)
+	assert.Equal(t, []string{removed}, diff.RemovedProducers)
+	assert.Equal(t, 1, len(diff.ChangedProducers))
+	assert.Equal(t, changed, diff.ChangedProducers[0].OwnerPublicKey)
+	fields := make(map[string]FieldChange)
+	for _, c := range diff.ChangedProducers[0].Changes {
+		fields[c.Field] = c
+	}
+	assert.Equal(t, 2, len(fields))
+	assert.Equal(t, Inactive.String(), fields["state"].New)
+	assert.Equal(t, oldVotes.String(), fields["votes"].Old)
+	assert.Equal(t, producer.votes.String(), fields["votes"].New)
+
+	assert.Equal(t, []string{addedVote}, diff.AddedVotes)
+	assert.Equal(t, []string{removedVote}, diff.RemovedVotes)
+	assert.Equal(t, []string{common.BytesToHexString(addedArbiter)},
+		diff.AddedArbiters)
+	assert.Equal(t, []string{common.BytesToHexString(removedArbiter)},
+		diff.RemovedArbiters)
+	assert.Equal(t, []RewardDiff{{ProgramHash: rewardHash, New: 10}},
+		diff.RewardChanges)
+	assert.Equal(t, from.CurrentReward.TotalVotesInRound+10,
+		diff.NewTotalVotesInRound)
+
+	// the reverse diff swaps additions and removals
+	reverse := DiffCheckPoints(to, from)
+	assert.Equal(t, diff.AddedProducers, reverse.RemovedProducers)
+	assert.Equal(t, diff.RemovedProducers, reverse.AddedProducers)
+	assert.Equal(t, diff.AddedVotes, reverse.RemovedVotes)
+	assert.Equal(t, []RewardDiff{{ProgramHash: rewardHash, Old: 10}},
+		reverse.RewardChanges)
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package state
+
+import (
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+)
+
+// outputHookModule is the state module name of producers state output hooks.
+const outputHookModule = "DPoS"
+
+// isDelegateVote returns if the vote output including votes to producers.
+func isDelegateVote(output *types.Output) bool {
+	p, _ := output.Payload.(*outputpayload.VoteOutput)
+	if p.Version == outputpayload.VoteProducerVersion {
+		return true
+	}
+	for _, content := range p.Contents {
+		if content.VoteType == outputpayload.Delegate {
+			return true
+		}
+	}
+	return false
+}
+
+// processDelegateVote records the vote output and updates producers votes.
+func processDelegateVote(state interface{}, tx *types.Transaction, index int,
+	height uint32) {
+	s := state.(*State)
+	output := tx.Outputs[index]
+	op := types.NewOutPoint(tx.Hash(), uint16(index))
+	s.Votes[op.ReferKey()] = output
+	s.processVoteOutput(output, height)
+}
+
+func init() {
+	if err := types.RegisterOutputStateHook(outputHookModule, types.OTVote,
+		types.OutputStateHook{
+			Match:   isDelegateVote,
+			Process: processDelegateVote,
+		}); err != nil {
+		panic(err)
+	}
+}
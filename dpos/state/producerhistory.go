@@ -0,0 +1,356 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package state
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/crypto"
+	"github.com/elastos/Elastos.ELA/utils"
+)
+
+// ProducerChangeCause represents the cause of a producer change.
+type ProducerChangeCause byte
+
+const (
+	// CauseRegister indicates the producer is registered by a register
+	// producer transaction.
+	CauseRegister ProducerChangeCause = iota
+
+	// CauseUpdate indicates the producer information is updated by an update
+	// producer transaction.
+	CauseUpdate
+
+	// CauseConfirmed indicates the pending producer has got enough
+	// confirmations and become active.
+	CauseConfirmed
+
+	// CauseActivate indicates the inactive or illegal producer become active
+	// by an activate producer transaction.
+	CauseActivate
+
+	// CauseCancel indicates the producer is canceled by a cancel producer
+	// transaction.
+	CauseCancel
+
+	// CauseInactivity indicates the producer has been inactive for more than
+	// MaxInactiveRounds.
+	CauseInactivity
+
+	// CauseEmergencyInactive indicates the producer is set inactive by an
+	// inactive arbitrators transaction.
+	CauseEmergencyInactive
+
+	// CauseIllegalEvidence indicates the producer is found illegal by an
+	// illegal evidence.
+	CauseIllegalEvidence
+
+	// CauseReturnDeposit indicates the producer deposit is returned by a
+	// return deposit coin transaction.
+	CauseReturnDeposit
+
+	// CauseAppeal indicates the penalty of the producer is lifted by a
+	// producer appeal transaction approved by the CR committee.
+	CauseAppeal
+
+	// CauseRotateNodeKey indicates the node public key of the producer is
+	// replaced by a rotate node key transaction.
+	CauseRotateNodeKey
+)
+
+// producerChangeCauseStrings is a array of producer change causes back to
+// their constant names for pretty printing.
+var producerChangeCauseStrings = []string{"Register", "Update", "Confirmed",
+	"Activate", "Cancel", "Inactivity", "EmergencyInactive",
+	"IllegalEvidence", "ReturnDeposit", "Appeal", "RotateNodeKey"}
+
+func (c ProducerChangeCause) String() string {
+	if int(c) < len(producerChangeCauseStrings) {
+		return producerChangeCauseStrings[c]
+	}
+	return fmt.Sprintf("ProducerChangeCause-%d", c)
+}
+
+// pendingProducerChange is a producer change not written into the history
+// index yet.
+type pendingProducerChange struct {
+	key    string
+	change *ProducerChange
+}
+
+// ProducerChange records a state transition or an information update of a
+// producer.  Information fields are empty if the information is not changed.
+type ProducerChange struct {
+	Cause            ProducerChangeCause
+	OldState         ProducerState
+	NewState         ProducerState
+	OldNodePublicKey []byte
+	NewNodePublicKey []byte
+	OldNickName      string
+	NewNickName      string
+	OldUrl           string
+	NewUrl           string
+	OldLocation      uint64
+	NewLocation      uint64
+	OldNetAddress    string
+	NewNetAddress    string
+	Height           uint32
+	TxHash           common.Uint256
+}
+
+// GetProducerHistory returns the changes of producer with specified node
+// public key or owner public key, ordered by the height they happened.
+func (s *State) GetProducerHistory(
+	publicKey []byte) ([]*ProducerChange, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	ownerPublicKey, err := hex.DecodeString(s.getProducerKey(publicKey))
+	if err != nil {
+		return nil, err
+	}
+	values, err := s.producerHistories.GetRecords(ownerPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*ProducerChange, 0, len(values))
+	for _, v := range values {
+		var change ProducerChange
+		if err := change.Deserialize(bytes.NewReader(v)); err != nil {
+			return nil, err
+		}
+		result = append(result, &change)
+	}
+	return result, nil
+}
+
+// SetProducerHistoryIndex sets the index storing changes of producers,
+// changes are kept in memory if not set.
+func (s *State) SetProducerHistoryIndex(index utils.HistoryIndex) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.producerHistories = index
+}
+
+// addProducerChange records a change of producer with specified owner public
+// key, it is written into the history index after the block processed.
+// Changes at height 0 are temporary changes and not recorded, the payload
+// will be processed again when it's packed into a block.
+func (s *State) addProducerChange(key string, change *ProducerChange) {
+	if change.Height == 0 {
+		return
+	}
+	s.pendingProducerChanges = append(s.pendingProducerChanges,
+		pendingProducerChange{key: key, change: change})
+}
+
+// writeProducerHistories writes the producer changes recorded on the height
+// into the history index.  Changes recorded by re-executing history of
+// lower heights are dropped, they have been written before.
+func (s *State) writeProducerHistories(height uint32) {
+	records := make([]utils.HistoryRecord, 0, len(s.pendingProducerChanges))
+	for _, c := range s.pendingProducerChanges {
+		if c.change.Height != height {
+			continue
+		}
+		ownerPublicKey, err := hex.DecodeString(c.key)
+		if err != nil {
+			log.Error("[writeProducerHistories] invalid owner public key: ",
+				c.key)
+			continue
+		}
+		buf := new(bytes.Buffer)
+		if err := c.change.Serialize(buf); err != nil {
+			log.Error("[writeProducerHistories] serialize producer change "+
+				"error: ", err)
+			continue
+		}
+		records = append(records, utils.HistoryRecord{
+			Key:   ownerPublicKey,
+			Value: buf.Bytes(),
+		})
+	}
+	s.pendingProducerChanges = nil
+
+	if err := s.producerHistories.PutRecords(height, records); err != nil {
+		log.Error("[writeProducerHistories] write producer histories "+
+			"error: ", err)
+	}
+}
+
+// removeProducerHistories removes the producer changes happened above the
+// height from the history index.
+func (s *State) removeProducerHistories(height uint32) {
+	if err := s.producerHistories.RollbackTo(height); err != nil {
+		log.Error("[removeProducerHistories] remove producer histories "+
+			"error: ", err)
+	}
+}
+
+// newProducerStateChange creates a change about producer state transition.
+func newProducerStateChange(cause ProducerChangeCause, from,
+	to ProducerState, height uint32) *ProducerChange {
+	return &ProducerChange{
+		Cause:    cause,
+		OldState: from,
+		NewState: to,
+		Height:   height,
+	}
+}
+
+// newProducerInfoChange creates a change about producer information update.
+func newProducerInfoChange(state ProducerState, origin,
+	update *payload.ProducerInfo, height uint32,
+	txHash common.Uint256) *ProducerChange {
+	return &ProducerChange{
+		Cause:            CauseUpdate,
+		OldState:         state,
+		NewState:         state,
+		OldNodePublicKey: origin.NodePublicKey,
+		NewNodePublicKey: update.NodePublicKey,
+		OldNickName:      origin.NickName,
+		NewNickName:      update.NickName,
+		OldUrl:           origin.Url,
+		NewUrl:           update.Url,
+		OldLocation:      origin.Location,
+		NewLocation:      update.Location,
+		OldNetAddress:    origin.NetAddress,
+		NewNetAddress:    update.NetAddress,
+		Height:           height,
+		TxHash:           txHash,
+	}
+}
+
+func (c *ProducerChange) Serialize(w io.Writer) (err error) {
+	if err = common.WriteUint8(w, uint8(c.Cause)); err != nil {
+		return
+	}
+
+	if err = common.WriteUint8(w, uint8(c.OldState)); err != nil {
+		return
+	}
+
+	if err = common.WriteUint8(w, uint8(c.NewState)); err != nil {
+		return
+	}
+
+	if err = common.WriteVarBytes(w, c.OldNodePublicKey); err != nil {
+		return
+	}
+
+	if err = common.WriteVarBytes(w, c.NewNodePublicKey); err != nil {
+		return
+	}
+
+	if err = common.WriteVarString(w, c.OldNickName); err != nil {
+		return
+	}
+
+	if err = common.WriteVarString(w, c.NewNickName); err != nil {
+		return
+	}
+
+	if err = common.WriteVarString(w, c.OldUrl); err != nil {
+		return
+	}
+
+	if err = common.WriteVarString(w, c.NewUrl); err != nil {
+		return
+	}
+
+	if err = common.WriteUint64(w, c.OldLocation); err != nil {
+		return
+	}
+
+	if err = common.WriteUint64(w, c.NewLocation); err != nil {
+		return
+	}
+
+	if err = common.WriteVarString(w, c.OldNetAddress); err != nil {
+		return
+	}
+
+	if err = common.WriteVarString(w, c.NewNetAddress); err != nil {
+		return
+	}
+
+	if err = common.WriteUint32(w, c.Height); err != nil {
+		return
+	}
+
+	return c.TxHash.Serialize(w)
+}
+
+func (c *ProducerChange) Deserialize(r io.Reader) (err error) {
+	var cause, oldState, newState uint8
+	if cause, err = common.ReadUint8(r); err != nil {
+		return
+	}
+	c.Cause = ProducerChangeCause(cause)
+
+	if oldState, err = common.ReadUint8(r); err != nil {
+		return
+	}
+	c.OldState = ProducerState(oldState)
+
+	if newState, err = common.ReadUint8(r); err != nil {
+		return
+	}
+	c.NewState = ProducerState(newState)
+
+	if c.OldNodePublicKey, err = common.ReadVarBytes(r,
+		crypto.NegativeBigLength, "old node public key"); err != nil {
+		return
+	}
+
+	if c.NewNodePublicKey, err = common.ReadVarBytes(r,
+		crypto.NegativeBigLength, "new node public key"); err != nil {
+		return
+	}
+
+	if c.OldNickName, err = common.ReadVarString(r); err != nil {
+		return
+	}
+
+	if c.NewNickName, err = common.ReadVarString(r); err != nil {
+		return
+	}
+
+	if c.OldUrl, err = common.ReadVarString(r); err != nil {
+		return
+	}
+
+	if c.NewUrl, err = common.ReadVarString(r); err != nil {
+		return
+	}
+
+	if c.OldLocation, err = common.ReadUint64(r); err != nil {
+		return
+	}
+
+	if c.NewLocation, err = common.ReadUint64(r); err != nil {
+		return
+	}
+
+	if c.OldNetAddress, err = common.ReadVarString(r); err != nil {
+		return
+	}
+
+	if c.NewNetAddress, err = common.ReadVarString(r); err != nil {
+		return
+	}
+
+	if c.Height, err = common.ReadUint32(r); err != nil {
+		return
+	}
+
+	return c.TxHash.Deserialize(r)
+}
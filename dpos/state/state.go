@@ -78,6 +78,8 @@ type Producer struct {
 	votes                  common.Fixed64
 	depositAmount          common.Fixed64
 	depositHash            common.Uint168
+	voteDecayEndHeight     uint32
+	nodeKeyRotationHeight  uint32
 }
 
 // Info returns a copy of the origin registered producer info.
@@ -121,6 +123,12 @@ func (p *Producer) InactiveSince() uint32 {
 	return p.inactiveSince
 }
 
+// InactiveCountingHeight returns the height since which the producer has not
+// sponsored any block as an arbiter, zero means the counting has not started.
+func (p *Producer) InactiveCountingHeight() uint32 {
+	return p.inactiveCountingHeight
+}
+
 func (p *Producer) IllegalHeight() uint32 {
 	return p.illegalHeight
 }
@@ -129,12 +137,21 @@ func (p *Producer) ActivateRequestHeight() uint32 {
 	return p.activateRequestHeight
 }
 
+// VoteDecayEndHeight returns the height until which the votes of the producer
+// are discounted in the next arbiters election, since the producer has been
+// inactive for too long.
+func (p *Producer) VoteDecayEndHeight() uint32 {
+	return p.voteDecayEndHeight
+}
+
 func (p *Producer) DepositAmount() common.Fixed64 {
 	return p.depositAmount
 }
 
-func (p *Producer) Serialize(w io.Writer) error {
-	if err := p.info.Serialize(w, payload.ProducerInfoVersion); err != nil {
+// Serialize writes the producer in the encoding of the given state key frame
+// version.
+func (p *Producer) Serialize(w io.Writer, version byte) error {
+	if err := p.info.Serialize(w, producerInfoVersion(version)); err != nil {
 		return err
 	}
 
@@ -174,11 +191,25 @@ func (p *Producer) Serialize(w io.Writer) error {
 		return err
 	}
 
-	return p.depositHash.Serialize(w)
+	if err := p.depositHash.Serialize(w); err != nil {
+		return err
+	}
+
+	if version < StateKeyFrameLatestVersion {
+		return nil
+	}
+
+	if err := common.WriteUint32(w, p.voteDecayEndHeight); err != nil {
+		return err
+	}
+
+	return common.WriteUint32(w, p.nodeKeyRotationHeight)
 }
 
-func (p *Producer) Deserialize(r io.Reader) (err error) {
-	if err = p.info.Deserialize(r, payload.ProducerInfoVersion); err != nil {
+// Deserialize reads the producer in the encoding of the given state key frame
+// version.
+func (p *Producer) Deserialize(r io.Reader, version byte) (err error) {
+	if err = p.info.Deserialize(r, producerInfoVersion(version)); err != nil {
 		return
 	}
 
@@ -224,7 +255,29 @@ func (p *Producer) Deserialize(r io.Reader) (err error) {
 	}
 	p.votes = common.Fixed64(votes)
 
-	return p.depositHash.Deserialize(r)
+	if err = p.depositHash.Deserialize(r); err != nil {
+		return
+	}
+
+	if version < StateKeyFrameLatestVersion {
+		return
+	}
+
+	if p.voteDecayEndHeight, err = common.ReadUint32(r); err != nil {
+		return
+	}
+
+	p.nodeKeyRotationHeight, err = common.ReadUint32(r)
+	return
+}
+
+// producerInfoVersion returns the producer info version used by the given
+// state key frame version.
+func producerInfoVersion(version byte) byte {
+	if version < StateKeyFrameLatestVersion {
+		return payload.ProducerInfoVersion
+	}
+	return payload.ProducerInfoLatestVersion
 }
 
 const (
@@ -253,6 +306,18 @@ type State struct {
 	votesCacheKeys map[uint32][]string
 	votesCache     map[string]*types.Output
 
+	// foldedNicknames counts the nicknames in use by their folded form, so
+	// duplicated nicknames after folded can be found without folding all of
+	// them.
+	foldedNicknames map[string]int
+
+	// producerHistories records changes of producers by owner public key.  It
+	// is an audit log growing with the chain, so it is kept out of the key
+	// frame and stored by the index, changes of a block are collected by
+	// pendingProducerChanges and written after the block processed.
+	producerHistories      utils.HistoryIndex
+	pendingProducerChanges []pendingProducerChange
+
 	cursor int
 }
 
@@ -305,8 +370,8 @@ func (s *State) updateProducerInfo(origin *payload.ProducerInfo, update *payload
 
 	// compare and update node nickname.
 	if origin.NickName != update.NickName {
-		delete(s.Nicknames, origin.NickName)
-		s.Nicknames[update.NickName] = struct{}{}
+		s.removeNickname(origin.NickName)
+		s.addNickname(update.NickName)
 	}
 
 	// compare and update node public key, we only query pending and active node
@@ -565,6 +630,65 @@ func (s *State) NicknameExists(nickname string) bool {
 	return ok
 }
 
+// NormalizedNicknameExists returns if a nickname equals to the given one after
+// normalized by NamePolicy is in use.
+func (s *State) NormalizedNicknameExists(nickname string) bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	policy := &s.chainParams.NamePolicy
+	normalized := policy.NormalizeNickname(nickname)
+	for n := range s.Nicknames {
+		if policy.NormalizeNickname(n) == normalized {
+			return true
+		}
+	}
+	return false
+}
+
+// FoldedNicknameExists returns if a nickname equals to the given one after
+// folded by NamePolicy is in use.
+func (s *State) FoldedNicknameExists(nickname string) bool {
+	s.mtx.RLock()
+	_, ok := s.foldedNicknames[config.FoldNickname(nickname)]
+	s.mtx.RUnlock()
+	return ok
+}
+
+// addNickname adds the nickname into the nicknames in use and the folded
+// nicknames index.
+func (s *State) addNickname(nickname string) {
+	if _, ok := s.Nicknames[nickname]; ok {
+		return
+	}
+	s.Nicknames[nickname] = struct{}{}
+	s.foldedNicknames[config.FoldNickname(nickname)]++
+}
+
+// removeNickname removes the nickname from the nicknames in use and the
+// folded nicknames index.
+func (s *State) removeNickname(nickname string) {
+	if _, ok := s.Nicknames[nickname]; !ok {
+		return
+	}
+	delete(s.Nicknames, nickname)
+	folded := config.FoldNickname(nickname)
+	if s.foldedNicknames[folded] <= 1 {
+		delete(s.foldedNicknames, folded)
+		return
+	}
+	s.foldedNicknames[folded]--
+}
+
+// rebuildFoldedNicknames builds the folded nicknames index from the nicknames
+// in use, it should be called after the key frame is replaced.
+func (s *State) rebuildFoldedNicknames() {
+	s.foldedNicknames = make(map[string]int, len(s.Nicknames))
+	for n := range s.Nicknames {
+		s.foldedNicknames[config.FoldNickname(n)]++
+	}
+}
+
 // ProducerExists returns if a producer is exists by it's node public key or
 // owner public key.
 func (s *State) ProducerExists(publicKey []byte) bool {
@@ -608,6 +732,22 @@ func (s *State) SpecialTxExists(tx *types.Transaction) bool {
 	return ok
 }
 
+// GetVoteOutput returns the vote output referenced by the refer key if the
+// output is voting to producers and has not been spent or revoked.
+func (s *State) GetVoteOutput(referKey string) *types.Output {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.Votes[referKey]
+}
+
+// GetStateKeyFrameHash returns the canonical hash of the state key frame,
+// it's used to compare producers and votes state between nodes.
+func (s *State) GetStateKeyFrameHash() (common.Uint256, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.StateKeyFrame.Hash()
+}
+
 // IsDPOSTransaction returns if a transaction will change the producers and
 // votes state.
 func (s *State) IsDPOSTransaction(tx *types.Transaction) bool {
@@ -617,7 +757,8 @@ func (s *State) IsDPOSTransaction(tx *types.Transaction) bool {
 		types.ActivateProducer, types.IllegalProposalEvidence,
 		types.IllegalVoteEvidence, types.IllegalBlockEvidence,
 		types.IllegalSidechainEvidence, types.InactiveArbitrators,
-		types.ReturnDepositCoin:
+		types.ReturnDepositCoin, types.RevokeVote, types.CRCRewardAddress,
+		types.ProducerAppeal, types.RotateNodeKey:
 		return true
 
 	// Transactions will change the producer votes state.
@@ -625,18 +766,9 @@ func (s *State) IsDPOSTransaction(tx *types.Transaction) bool {
 		if tx.Version >= types.TxVersion09 {
 			// Votes to producers.
 			for _, output := range tx.Outputs {
-				if output.Type != types.OTVote {
-					continue
-				}
-				p, _ := output.Payload.(*outputpayload.VoteOutput)
-				if p.Version == outputpayload.VoteProducerVersion {
+				if _, ok := types.MatchOutputStateHook(
+					outputHookModule, output); ok {
 					return true
-				} else {
-					for _, content := range p.Contents {
-						if content.VoteType == outputpayload.Delegate {
-							return true
-						}
-					}
 				}
 			}
 		}
@@ -666,6 +798,9 @@ func (s *State) ProcessBlock(block *types.Block, confirm *payload.Confirm) {
 	defer s.mtx.Unlock()
 
 	s.tryInitProducerAssetAmounts(block.Height)
+	if block.Height == s.chainParams.NicknameFoldHeight {
+		s.reportNicknameCollisions()
+	}
 	s.processTransactions(block.Transactions, block.Height)
 	s.ProcessVoteStatisticsBlock(block)
 
@@ -675,6 +810,18 @@ func (s *State) ProcessBlock(block *types.Block, confirm *payload.Confirm) {
 
 	// Commit changes here if no errors found.
 	s.history.Commit(block.Height)
+	s.writeProducerHistories(block.Height)
+}
+
+// reportNicknameCollisions logs nicknames of producers duplicated after
+// folded, they are kept since registered before NicknameFoldHeight, while
+// new nicknames duplicated with them will be rejected.
+func (s *State) reportNicknameCollisions() {
+	collisions := config.FoldedNicknameCollisions(s.Nicknames)
+	for _, group := range collisions {
+		log.Warnf("producer nicknames %q are duplicated after folded, "+
+			"kept since registered before NicknameFoldHeight", group)
+	}
 }
 
 // ProcessVoteStatisticsBlock deal with block with vote statistics error.
@@ -711,6 +858,8 @@ func (s *State) processTransactions(txs []*types.Transaction, height uint32) {
 			producer.state = Active
 			s.ActivityProducers[key] = producer
 			delete(s.PendingProducers, key)
+			s.addProducerChange(key, newProducerStateChange(
+				CauseConfirmed, Pending, Active, height))
 		}, func() {
 			producer.state = Pending
 			s.PendingProducers[key] = producer
@@ -721,12 +870,18 @@ func (s *State) processTransactions(txs []*types.Transaction, height uint32) {
 	// Check if any pending inactive producers has got 6 confirms,
 	// then set them to activate.
 	activateProducerFromInactive := func(key string, producer *Producer) {
+		oriDecayEndHeight := producer.voteDecayEndHeight
+		decayEndHeight := s.getVoteDecayEndHeight(producer, height)
 		s.history.Append(height, func() {
 			producer.state = Active
+			producer.voteDecayEndHeight = decayEndHeight
 			s.ActivityProducers[key] = producer
 			delete(s.InactiveProducers, key)
+			s.addProducerChange(key, newProducerStateChange(
+				CauseActivate, Inactive, Active, height))
 		}, func() {
 			producer.state = Inactive
+			producer.voteDecayEndHeight = oriDecayEndHeight
 			s.InactiveProducers[key] = producer
 			delete(s.ActivityProducers, key)
 		})
@@ -739,6 +894,8 @@ func (s *State) processTransactions(txs []*types.Transaction, height uint32) {
 			producer.state = Active
 			s.ActivityProducers[key] = producer
 			delete(s.IllegalProducers, key)
+			s.addProducerChange(key, newProducerStateChange(
+				CauseActivate, Illegal, Active, height))
 		}, func() {
 			producer.state = Illegal
 			s.IllegalProducers[key] = producer
@@ -771,6 +928,45 @@ func (s *State) processTransactions(txs []*types.Transaction, height uint32) {
 			}
 		}
 	}
+
+	// Lift the penalty of producers whose appeal reaches the activation
+	// height.
+	for key, activationHeight := range s.ProducerAppeals {
+		if height >= activationHeight {
+			s.liftProducerPenalty(key, activationHeight, height)
+		}
+	}
+}
+
+// getVoteDecayEndHeight returns the height until which the votes of the
+// producer activated from inactive state at the height are discounted.  The
+// votes are discounted for as long as the producer has been inactive if more
+// than VoteDecayInactiveRounds.
+func (s *State) getVoteDecayEndHeight(producer *Producer,
+	height uint32) uint32 {
+	if height < s.chainParams.VoteDecayHeight ||
+		s.chainParams.VoteDecayInactiveRounds == 0 ||
+		height < producer.inactiveSince {
+		return producer.voteDecayEndHeight
+	}
+
+	rounds := height - producer.inactiveSince
+	if rounds <= s.chainParams.VoteDecayInactiveRounds {
+		return producer.voteDecayEndHeight
+	}
+	return height + rounds
+}
+
+// getEffectiveVotes returns the votes of the producer counted in the next
+// arbiters election at the height.
+func (s *State) getEffectiveVotes(producer *Producer,
+	height uint32) common.Fixed64 {
+	if height < s.chainParams.VoteDecayHeight ||
+		height >= producer.voteDecayEndHeight {
+		return producer.votes
+	}
+	return common.Fixed64(math.Floor(float64(producer.votes) *
+		s.chainParams.VoteDecayRatio))
 }
 
 // processTransaction take a transaction and the height it has been packed into
@@ -782,10 +978,10 @@ func (s *State) processTransaction(tx *types.Transaction, height uint32) {
 		s.registerProducer(tx, height)
 
 	case types.UpdateProducer:
-		s.updateProducer(tx.Payload.(*payload.ProducerInfo), height)
+		s.updateProducer(tx, height)
 
 	case types.CancelProducer:
-		s.cancelProducer(tx.Payload.(*payload.ProcessProducer), height)
+		s.cancelProducer(tx, height)
 
 	case types.ActivateProducer:
 		s.activateProducer(tx.Payload.(*payload.ActivateProducer), height)
@@ -810,6 +1006,18 @@ func (s *State) processTransaction(tx *types.Transaction, height uint32) {
 
 	case types.UpdateVersion:
 		s.updateVersion(tx, height)
+
+	case types.RevokeVote:
+		s.processRevokeVotes(tx, height)
+
+	case types.CRCRewardAddress:
+		s.setCRCRewardAddress(tx, height)
+
+	case types.ProducerAppeal:
+		s.appealProducer(tx, height)
+
+	case types.RotateNodeKey:
+		s.rotateNodeKey(tx, height)
 	}
 
 	s.processCancelVotes(tx, height)
@@ -846,14 +1054,17 @@ func (s *State) registerProducer(tx *types.Transaction, height uint32) {
 		depositAmount:          amount,
 		depositHash:            *programHash,
 	}
+	change := newProducerStateChange(CauseRegister, Pending, Pending, height)
+	change.TxHash = tx.Hash()
 
 	s.history.Append(height, func() {
-		s.Nicknames[nickname] = struct{}{}
+		s.addNickname(nickname)
 		s.NodeOwnerKeys[nodeKey] = ownerKey
 		s.PendingProducers[ownerKey] = &producer
 		s.ProducerDepositMap[*programHash] = struct{}{}
+		s.addProducerChange(ownerKey, change)
 	}, func() {
-		delete(s.Nicknames, nickname)
+		s.removeNickname(nickname)
 		delete(s.NodeOwnerKeys, nodeKey)
 		delete(s.PendingProducers, ownerKey)
 		delete(s.ProducerDepositMap, *programHash)
@@ -861,21 +1072,30 @@ func (s *State) registerProducer(tx *types.Transaction, height uint32) {
 }
 
 // updateProducer handles the update producer transaction.
-func (s *State) updateProducer(info *payload.ProducerInfo, height uint32) {
+func (s *State) updateProducer(tx *types.Transaction, height uint32) {
+	info := tx.Payload.(*payload.ProducerInfo)
+	key := hex.EncodeToString(info.OwnerPublicKey)
 	producer := s.getProducer(info.OwnerPublicKey)
 	producerInfo := producer.info
+	change := newProducerInfoChange(producer.state, &producerInfo, info,
+		height, tx.Hash())
 	s.history.Append(height, func() {
 		s.updateProducerInfo(&producerInfo, info)
+		s.addProducerChange(key, change)
 	}, func() {
 		s.updateProducerInfo(info, &producerInfo)
 	})
 }
 
 // cancelProducer handles the cancel producer transaction.
-func (s *State) cancelProducer(payload *payload.ProcessProducer, height uint32) {
+func (s *State) cancelProducer(tx *types.Transaction, height uint32) {
+	payload := tx.Payload.(*payload.ProcessProducer)
 	key := hex.EncodeToString(payload.OwnerPublicKey)
 	producer := s.getProducer(payload.OwnerPublicKey)
 	isPending := producer.state == Pending
+	change := newProducerStateChange(CauseCancel, producer.state, Canceled,
+		height)
+	change.TxHash = tx.Hash()
 	s.history.Append(height, func() {
 		producer.state = Canceled
 		producer.cancelHeight = height
@@ -886,7 +1106,8 @@ func (s *State) cancelProducer(payload *payload.ProcessProducer, height uint32)
 		} else {
 			delete(s.ActivityProducers, key)
 		}
-		delete(s.Nicknames, producer.info.NickName)
+		s.removeNickname(producer.info.NickName)
+		s.addProducerChange(key, change)
 	}, func() {
 		producer.cancelHeight = 0
 		delete(s.CanceledProducers, key)
@@ -898,7 +1119,7 @@ func (s *State) cancelProducer(payload *payload.ProcessProducer, height uint32)
 			producer.state = Active
 			s.ActivityProducers[key] = producer
 		}
-		s.Nicknames[producer.info.NickName] = struct{}{}
+		s.addNickname(producer.info.NickName)
 	})
 }
 
@@ -922,27 +1143,9 @@ func (s *State) processVotes(tx *types.Transaction, height uint32) {
 	if tx.Version >= types.TxVersion09 {
 		// Votes to producers.
 		for i, output := range tx.Outputs {
-			if output.Type != types.OTVote {
-				continue
-			}
-			p, _ := output.Payload.(*outputpayload.VoteOutput)
-			if p.Version == outputpayload.VoteProducerVersion {
-				op := types.NewOutPoint(tx.Hash(), uint16(i))
-				s.Votes[op.ReferKey()] = output
-				s.processVoteOutput(output, height)
-			} else {
-				var exist bool
-				for _, content := range p.Contents {
-					if content.VoteType == outputpayload.Delegate {
-						exist = true
-						break
-					}
-				}
-				if exist {
-					op := types.NewOutPoint(tx.Hash(), uint16(i))
-					s.Votes[op.ReferKey()] = output
-					s.processVoteOutput(output, height)
-				}
+			if hook, ok := types.MatchOutputStateHook(
+				outputHookModule, output); ok {
+				hook.Process(s, tx, i, height)
 			}
 		}
 	}
@@ -1031,16 +1234,24 @@ func (s *State) getProducerByDepositHash(hash common.Uint168) *Producer {
 // program hash of transaction output.
 func (s *State) addProducerAssert(output *types.Output, height uint32) bool {
 	if producer := s.getProducerByDepositHash(output.ProgramHash); producer != nil {
-		s.history.Append(height, func() {
-			producer.depositAmount += output.Value
-		}, func() {
-			producer.depositAmount -= output.Value
-		})
+		s.appendAmountChange(height, producer, &producer.depositAmount,
+			output.Value, "deposit amount")
 		return true
 	}
 	return false
 }
 
+// appendAmountChange adds delta to the votes or deposit amount of the
+// producer, the change is refused and logged if the result overflows.
+func (s *State) appendAmountChange(height uint32, producer *Producer,
+	amount *common.Fixed64, delta common.Fixed64, name string) {
+	s.history.AppendAmountChange(height, amount, delta, func(err error) {
+		log.Errorf("refused to add %s to %s %s of producer %s, %s", delta,
+			name, *amount, hex.EncodeToString(producer.info.OwnerPublicKey),
+			err)
+	})
+}
+
 // processCancelVotes takes a transaction output with vote payload.
 func (s *State) processCancelVotes(tx *types.Transaction, height uint32) {
 	for _, input := range tx.Inputs {
@@ -1069,19 +1280,13 @@ func (s *State) processCancelVotes(tx *types.Transaction, height uint32) {
 // processVoteOutput takes a transaction output with vote payload.
 func (s *State) processVoteOutput(output *types.Output, height uint32) {
 	countByGross := func(producer *Producer) {
-		s.history.Append(height, func() {
-			producer.votes += output.Value
-		}, func() {
-			producer.votes -= output.Value
-		})
+		s.appendAmountChange(height, producer, &producer.votes,
+			output.Value, "votes")
 	}
 
 	countByVote := func(producer *Producer, vote common.Fixed64) {
-		s.history.Append(height, func() {
-			producer.votes += vote
-		}, func() {
-			producer.votes -= vote
-		})
+		s.appendAmountChange(height, producer, &producer.votes, vote,
+			"votes")
 	}
 
 	p := output.Payload.(*outputpayload.VoteOutput)
@@ -1105,22 +1310,36 @@ func (s *State) processVoteOutput(output *types.Output, height uint32) {
 	}
 }
 
-// processVoteCancel takes a previous vote output and decrease producers votes.
-func (s *State) processVoteCancel(output *types.Output, height uint32) {
-	subtractByGross := func(producer *Producer) {
+// processRevokeVotes takes a revoke vote transaction and subtracts the votes
+// of revoked vote outputs, the outputs are removed from votes so that they
+// will not be canceled again when spent.
+func (s *State) processRevokeVotes(tx *types.Transaction, height uint32) {
+	p := tx.Payload.(*payload.RevokeVote)
+	for _, v := range p.Votes {
+		referKey := types.NewOutPoint(v.TxID, v.Index).ReferKey()
+		output := s.Votes[referKey]
+		if output == nil {
+			continue
+		}
+		s.processVoteCancel(output, height)
 		s.history.Append(height, func() {
-			producer.votes -= output.Value
+			delete(s.Votes, referKey)
 		}, func() {
-			producer.votes += output.Value
+			s.Votes[referKey] = output
 		})
 	}
+}
+
+// processVoteCancel takes a previous vote output and decrease producers votes.
+func (s *State) processVoteCancel(output *types.Output, height uint32) {
+	subtractByGross := func(producer *Producer) {
+		s.appendAmountChange(height, producer, &producer.votes,
+			-output.Value, "votes")
+	}
 
 	subtractByVote := func(producer *Producer, vote common.Fixed64) {
-		s.history.Append(height, func() {
-			producer.votes -= vote
-		}, func() {
-			producer.votes += vote
-		})
+		s.appendAmountChange(height, producer, &producer.votes, -vote,
+			"votes")
 	}
 
 	p := output.Payload.(*outputpayload.VoteOutput)
@@ -1151,15 +1370,18 @@ func (s *State) returnDeposit(tx *types.Transaction, height uint32) {
 	}
 
 	returnAction := func(producer *Producer) {
+		key := hex.EncodeToString(producer.info.OwnerPublicKey)
+		change := newProducerStateChange(CauseReturnDeposit, Canceled,
+			Returned, height)
+		change.TxHash = tx.Hash()
+		if height >= s.chainParams.CRVotingStartHeight {
+			s.appendAmountChange(height, producer, &producer.depositAmount,
+				-inputValue, "deposit amount")
+		}
 		s.history.Append(height, func() {
-			if height >= s.chainParams.CRVotingStartHeight {
-				producer.depositAmount -= inputValue
-			}
 			producer.state = Returned
+			s.addProducerChange(key, change)
 		}, func() {
-			if height >= s.chainParams.CRVotingStartHeight {
-				producer.depositAmount += inputValue
-			}
 			producer.state = Canceled
 		})
 	}
@@ -1192,6 +1414,194 @@ func (s *State) updateVersion(tx *types.Transaction, height uint32) {
 	})
 }
 
+// setCRCRewardAddress takes a CRC reward address transaction and directs the
+// rewards of the CRC arbiter to the reward address.
+func (s *State) setCRCRewardAddress(tx *types.Transaction, height uint32) {
+	p, ok := tx.Payload.(*payload.CRCRewardAddress)
+	if !ok {
+		log.Error("tx payload cast failed, tx:", tx.Hash())
+		return
+	}
+	programHash, err := contract.PublicKeyToStandardProgramHash(
+		p.NodePublicKey)
+	if err != nil {
+		log.Error("invalid CRC arbiter public key, tx:", tx.Hash())
+		return
+	}
+
+	key := *programHash
+	rewardHash := p.RewardProgramHash
+	origin, exist := s.CRCRewardAddresses[key]
+	originHeight := s.CRCRewardAddressHeights[key]
+	s.history.Append(height, func() {
+		s.CRCRewardAddresses[key] = rewardHash
+		s.CRCRewardAddressHeights[key] = height
+	}, func() {
+		if exist {
+			s.CRCRewardAddresses[key] = origin
+			s.CRCRewardAddressHeights[key] = originHeight
+		} else {
+			delete(s.CRCRewardAddresses, key)
+			delete(s.CRCRewardAddressHeights, key)
+		}
+	})
+}
+
+// GetCRCRewardAddress returns the reward program hash of the CRC arbiter with
+// specified program hash, and if the reward address has been set.
+func (s *State) GetCRCRewardAddress(
+	programHash common.Uint168) (common.Uint168, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	rewardHash, ok := s.CRCRewardAddresses[programHash]
+	return rewardHash, ok
+}
+
+// GetCRCRewardAddressHeight returns the height of the last reward address
+// change of the CRC arbiter with specified program hash, and if the reward
+// address has been set.
+func (s *State) GetCRCRewardAddressHeight(
+	programHash common.Uint168) (uint32, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	height, ok := s.CRCRewardAddressHeights[programHash]
+	return height, ok
+}
+
+// appealProducer takes a producer appeal transaction and records the appeal
+// to be activated at the activation height.
+func (s *State) appealProducer(tx *types.Transaction, height uint32) {
+	p, ok := tx.Payload.(*payload.ProducerAppeal)
+	if !ok {
+		log.Error("tx payload cast failed, tx:", tx.Hash())
+		return
+	}
+
+	key := hex.EncodeToString(p.OwnerPublicKey)
+	activationHeight := p.ActivationHeight
+	s.history.Append(height, func() {
+		s.ProducerAppeals[key] = activationHeight
+	}, func() {
+		delete(s.ProducerAppeals, key)
+	})
+}
+
+// liftProducerPenalty clears the penalty of the producer with the appeal, and
+// sets the producer active if it is inactive or illegal.
+func (s *State) liftProducerPenalty(key string, activationHeight uint32,
+	height uint32) {
+	producer := s.getProducerByOwnerPublicKey(key)
+
+	// The state is checked when the change is executed, since other changes
+	// on the same height may have changed it.
+	var oriState ProducerState
+	var oriPenalty common.Fixed64
+	var activated bool
+	s.history.Append(height, func() {
+		delete(s.ProducerAppeals, key)
+		if producer == nil {
+			return
+		}
+		oriState, oriPenalty = producer.state, producer.penalty
+		producer.penalty = 0
+		switch oriState {
+		case Inactive:
+			delete(s.InactiveProducers, key)
+		case Illegal:
+			delete(s.IllegalProducers, key)
+		default:
+			return
+		}
+		activated = true
+		producer.state = Active
+		s.ActivityProducers[key] = producer
+		s.addProducerChange(key, newProducerStateChange(CauseAppeal,
+			oriState, Active, height))
+	}, func() {
+		s.ProducerAppeals[key] = activationHeight
+		if producer == nil {
+			return
+		}
+		producer.penalty = oriPenalty
+		if !activated {
+			return
+		}
+		activated = false
+		producer.state = oriState
+		delete(s.ActivityProducers, key)
+		if oriState == Inactive {
+			s.InactiveProducers[key] = producer
+		} else {
+			s.IllegalProducers[key] = producer
+		}
+	})
+}
+
+// rotateNodeKey takes a rotate node key transaction and replaces the node
+// public key of the producer.
+func (s *State) rotateNodeKey(tx *types.Transaction, height uint32) {
+	p, ok := tx.Payload.(*payload.RotateNodeKey)
+	if !ok {
+		log.Error("tx payload cast failed, tx:", tx.Hash())
+		return
+	}
+
+	key := hex.EncodeToString(p.OwnerPublicKey)
+	producer := s.getProducerByOwnerPublicKey(key)
+	if producer == nil {
+		log.Error("rotating node key of unknown producer, tx:", tx.Hash())
+		return
+	}
+	oldNodePublicKey := producer.info.NodePublicKey
+	oldNodeKey := hex.EncodeToString(oldNodePublicKey)
+	newNodeKey := hex.EncodeToString(p.NewNodePublicKey)
+	change := &ProducerChange{
+		Cause:            CauseRotateNodeKey,
+		OldState:         producer.state,
+		NewState:         producer.state,
+		OldNodePublicKey: oldNodePublicKey,
+		NewNodePublicKey: p.NewNodePublicKey,
+		Height:           height,
+		TxHash:           tx.Hash(),
+	}
+	oldRotationHeight := producer.nodeKeyRotationHeight
+	s.history.Append(height, func() {
+		producer.info.NodePublicKey = p.NewNodePublicKey
+		producer.nodeKeyRotationHeight = height
+		delete(s.NodeOwnerKeys, oldNodeKey)
+		s.NodeOwnerKeys[newNodeKey] = key
+		s.addProducerChange(key, change)
+	}, func() {
+		producer.info.NodePublicKey = oldNodePublicKey
+		producer.nodeKeyRotationHeight = oldRotationHeight
+		delete(s.NodeOwnerKeys, newNodeKey)
+		s.NodeOwnerKeys[oldNodeKey] = key
+	})
+}
+
+// GetLastNodeKeyRotation returns the height of the last node key rotation of
+// producer with specified owner public key, and if the node key has ever been
+// rotated.
+func (s *State) GetLastNodeKeyRotation(ownerPublicKey []byte) (uint32, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	producer := s.getProducerByOwnerPublicKey(
+		hex.EncodeToString(ownerPublicKey))
+	if producer == nil || producer.nodeKeyRotationHeight == 0 {
+		return 0, false
+	}
+	return producer.nodeKeyRotationHeight, true
+}
+
+// GetProducerAppeal returns the activation height of the appeal of producer
+// with specified owner public key, and if there is an appeal to be activated.
+func (s *State) GetProducerAppeal(ownerPublicKey []byte) (uint32, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	height, ok := s.ProducerAppeals[hex.EncodeToString(ownerPublicKey)]
+	return height, ok
+}
+
 // processEmergencyInactiveArbitrators change producer state according to
 // emergency inactive arbitrators
 func (s *State) processEmergencyInactiveArbitrators(
@@ -1285,14 +1695,16 @@ func (s *State) processIllegalEvidence(payloadData types.Payload,
 				s.IllegalProducers[key] = producer
 				producer.activateRequestHeight = math.MaxUint32
 				delete(s.ActivityProducers, key)
-				delete(s.Nicknames, producer.info.NickName)
+				s.removeNickname(producer.info.NickName)
+				s.addProducerChange(key, newProducerStateChange(
+					CauseIllegalEvidence, Active, Illegal, height))
 			}, func() {
 				producer.state = Active
 				producer.illegalHeight = 0
 				s.ActivityProducers[key] = producer
 				producer.activateRequestHeight = math.MaxUint32
 				delete(s.IllegalProducers, key)
-				s.Nicknames[producer.info.NickName] = struct{}{}
+				s.addNickname(producer.info.NickName)
 			})
 			continue
 		}
@@ -1303,13 +1715,15 @@ func (s *State) processIllegalEvidence(payloadData types.Payload,
 				producer.illegalHeight = height
 				s.IllegalProducers[key] = producer
 				delete(s.CanceledProducers, key)
-				delete(s.Nicknames, producer.info.NickName)
+				s.removeNickname(producer.info.NickName)
+				s.addProducerChange(key, newProducerStateChange(
+					CauseIllegalEvidence, Canceled, Illegal, height))
 			}, func() {
 				producer.state = Canceled
 				producer.illegalHeight = 0
 				s.CanceledProducers[key] = producer
 				delete(s.IllegalProducers, key)
-				s.Nicknames[producer.info.NickName] = struct{}{}
+				s.addNickname(producer.info.NickName)
 			})
 			continue
 		}
@@ -1336,6 +1750,13 @@ func (s *State) ProcessSpecialTxPayload(p types.Payload, height uint32) {
 // setInactiveProducer set active producer to inactive state
 func (s *State) setInactiveProducer(producer *Producer, key string,
 	height uint32, emergency bool) {
+	cause := CauseInactivity
+	if emergency {
+		cause = CauseEmergencyInactive
+	}
+	s.addProducerChange(key, newProducerStateChange(cause, producer.state,
+		Inactive, height))
+
 	producer.inactiveSince = height
 	producer.activateRequestHeight = math.MaxUint32
 	producer.state = Inactive
@@ -1459,6 +1880,7 @@ func (s *State) tryUpdateInactivity(key string, producer *Producer,
 func (s *State) RollbackTo(height uint32) error {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
+	s.removeProducerHistories(height)
 	return s.history.RollbackTo(height)
 }
 
@@ -1489,5 +1911,7 @@ func NewState(chainParams *config.Params, getArbiters func() [][]byte,
 		StateKeyFrame:            NewStateKeyFrame(),
 		votesCacheKeys:           make(map[uint32][]string),
 		votesCache:               make(map[string]*types.Output),
+		foldedNicknames:          make(map[string]int),
+		producerHistories:        utils.NewMemHistoryIndex(),
 	}
 }
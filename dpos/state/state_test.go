@@ -8,6 +8,7 @@ package state
 import (
 	"crypto/rand"
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/elastos/Elastos.ELA/common"
@@ -609,6 +610,70 @@ func TestState_Rollback(t *testing.T) {
 	}
 }
 
+func TestState_GetProducerHistory(t *testing.T) {
+	state := NewState(&config.DefaultParams, nil, nil)
+
+	info := &payload.ProducerInfo{
+		OwnerPublicKey: randomOwnerPublicKey(),
+		NodePublicKey:  make([]byte, 33),
+		NickName:       "Producer",
+		Url:            "producer.org",
+	}
+	rand.Read(info.NodePublicKey)
+	registerTx := mockRegisterProducerTx(info)
+	state.ProcessBlock(mockBlock(1, registerTx), nil)
+	for i := uint32(2); i <= 6; i++ {
+		state.ProcessBlock(mockBlock(i), nil)
+	}
+
+	update := *info
+	update.NickName = "Updated"
+	update.Url = "updated.org"
+	updateTx := mockUpdateProducerTx(&update)
+	state.ProcessBlock(mockBlock(7, updateTx), nil)
+
+	cancelTx := mockCancelProducerTx(info.OwnerPublicKey)
+	state.ProcessBlock(mockBlock(8, cancelTx), nil)
+
+	history, err := state.GetProducerHistory(info.OwnerPublicKey)
+	assert.NoError(t, err)
+	if !assert.Equal(t, 4, len(history)) {
+		t.FailNow()
+	}
+	assert.Equal(t, CauseRegister, history[0].Cause)
+	assert.Equal(t, registerTx.Hash(), history[0].TxHash)
+	assert.Equal(t, uint32(1), history[0].Height)
+
+	assert.Equal(t, CauseConfirmed, history[1].Cause)
+	assert.Equal(t, Pending, history[1].OldState)
+	assert.Equal(t, Active, history[1].NewState)
+	assert.Equal(t, uint32(6), history[1].Height)
+
+	assert.Equal(t, CauseUpdate, history[2].Cause)
+	assert.Equal(t, "Producer", history[2].OldNickName)
+	assert.Equal(t, "Updated", history[2].NewNickName)
+	assert.Equal(t, "producer.org", history[2].OldUrl)
+	assert.Equal(t, "updated.org", history[2].NewUrl)
+	assert.Equal(t, updateTx.Hash(), history[2].TxHash)
+
+	assert.Equal(t, CauseCancel, history[3].Cause)
+	assert.Equal(t, Active, history[3].OldState)
+	assert.Equal(t, Canceled, history[3].NewState)
+	assert.Equal(t, cancelTx.Hash(), history[3].TxHash)
+
+	// Query by node public key should get the same history.
+	history, err = state.GetProducerHistory(info.NodePublicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(history))
+
+	// Rollback should remove the changes after the height.
+	assert.NoError(t, state.RollbackTo(6))
+	history, err = state.GetProducerHistory(info.OwnerPublicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(history))
+	assert.Equal(t, CauseConfirmed, history[1].Cause)
+}
+
 func TestState_GetHistory(t *testing.T) {
 	state := NewState(&config.DefaultParams, nil, nil)
 
@@ -817,6 +882,43 @@ func TestState_NicknameExists(t *testing.T) {
 	}
 }
 
+func TestState_FoldedNicknameExists(t *testing.T) {
+	state := NewState(&config.DefaultParams, nil, nil)
+
+	// Register producers with nicknames duplicated after folded.
+	for i, nickname := range []string{"Straße", "STRASSE"} {
+		p := &payload.ProducerInfo{
+			OwnerPublicKey: randomOwnerPublicKey(),
+			NodePublicKey:  make([]byte, 33),
+			NickName:       nickname,
+		}
+		rand.Read(p.NodePublicKey)
+		state.ProcessBlock(mockBlock(uint32(i+1), mockRegisterProducerTx(p)),
+			nil)
+	}
+
+	assert.True(t, state.FoldedNicknameExists("strasse"))
+	assert.True(t, state.FoldedNicknameExists("ｓｔｒａｓｓｅ"))
+	assert.False(t, state.NormalizedNicknameExists("ｓｔｒａｓｓｅ"))
+	assert.False(t, state.FoldedNicknameExists("strasse 1"))
+
+	// near-duplicate nicknames registered before are both kept.
+	assert.Equal(t, [][]string{{"STRASSE", "Straße"}},
+		config.FoldedNicknameCollisions(state.Nicknames))
+
+	// the folded nickname is in use until both producers are rolled back.
+	assert.NoError(t, state.RollbackTo(1))
+	assert.True(t, state.FoldedNicknameExists("strasse"))
+	assert.NoError(t, state.RollbackTo(0))
+	assert.False(t, state.FoldedNicknameExists("strasse"))
+
+	// the index is rebuilt from the nicknames of a replaced key frame.
+	state.StateKeyFrame = NewStateKeyFrame()
+	state.Nicknames["Straße"] = struct{}{}
+	state.rebuildFoldedNicknames()
+	assert.True(t, state.FoldedNicknameExists("STRASSE"))
+}
+
 func TestState_ProducerExists(t *testing.T) {
 	state := NewState(&config.DefaultParams, nil, nil)
 
@@ -923,6 +1025,122 @@ func TestState_IsDPOSTransaction(t *testing.T) {
 	}
 }
 
+func TestState_ProcessRevokeVote(t *testing.T) {
+	state := NewState(&config.DefaultParams, nil, nil)
+
+	producer := &payload.ProducerInfo{
+		OwnerPublicKey: randomOwnerPublicKey(),
+		NodePublicKey:  make([]byte, 33),
+		NickName:       "Producer",
+	}
+	rand.Read(producer.NodePublicKey)
+	state.ProcessBlock(mockBlock(1, mockRegisterProducerTx(producer)), nil)
+	for i := uint32(2); i < 10; i++ {
+		state.ProcessBlock(mockBlock(i), nil)
+	}
+
+	voteTx := mockVoteTx([][]byte{producer.OwnerPublicKey})
+	state.ProcessBlock(mockBlock(10, voteTx), nil)
+	referKey := types.NewOutPoint(voteTx.Hash(), 0).ReferKey()
+	assert.NotNil(t, state.GetVoteOutput(referKey))
+	assert.Equal(t, common.Fixed64(100),
+		state.getProducer(producer.NodePublicKey).votes)
+
+	// Revoke the votes without spending the vote output.
+	revokeTx := &types.Transaction{
+		TxType: types.RevokeVote,
+		Payload: &payload.RevokeVote{
+			Votes: []payload.RevokedVote{{TxID: voteTx.Hash(), Index: 0}},
+		},
+	}
+	assert.True(t, state.IsDPOSTransaction(revokeTx))
+	state.ProcessBlock(mockBlock(11, revokeTx), nil)
+	assert.Nil(t, state.GetVoteOutput(referKey))
+	assert.Equal(t, common.Fixed64(0),
+		state.getProducer(producer.NodePublicKey).votes)
+
+	// Spend the revoked vote output will not cancel the votes again.
+	state.ProcessBlock(mockBlock(12, mockCancelVoteTx(voteTx)), nil)
+	assert.Equal(t, common.Fixed64(0),
+		state.getProducer(producer.NodePublicKey).votes)
+
+	// Rollback the revoke vote transaction.
+	assert.NoError(t, state.RollbackTo(10))
+	assert.NotNil(t, state.GetVoteOutput(referKey))
+	assert.Equal(t, common.Fixed64(100),
+		state.getProducer(producer.NodePublicKey).votes)
+}
+
+func TestState_VotesOverflow(t *testing.T) {
+	state := NewState(&config.DefaultParams, nil, nil)
+
+	producer := &payload.ProducerInfo{
+		OwnerPublicKey: randomOwnerPublicKey(),
+		NodePublicKey:  make([]byte, 33),
+		NickName:       "Producer",
+	}
+	rand.Read(producer.NodePublicKey)
+	state.ProcessBlock(mockBlock(1, mockRegisterProducerTx(producer)), nil)
+	for i := uint32(2); i < 10; i++ {
+		state.ProcessBlock(mockBlock(i), nil)
+	}
+
+	// Votes overflowing the producer votes are refused.
+	maxVotes := common.Fixed64(math.MaxInt64 - 50)
+	state.getProducer(producer.NodePublicKey).votes = maxVotes
+	voteTx := mockVoteTx([][]byte{producer.OwnerPublicKey})
+	state.ProcessBlock(mockBlock(10, voteTx), nil)
+	assert.Equal(t, maxVotes, state.getProducer(producer.NodePublicKey).votes)
+
+	// Rollback of the refused votes changes nothing.
+	assert.NoError(t, state.RollbackTo(9))
+	assert.Equal(t, maxVotes, state.getProducer(producer.NodePublicKey).votes)
+
+	// Votes are counted after the producer votes decreased.
+	state.getProducer(producer.NodePublicKey).votes = 0
+	state.ProcessBlock(mockBlock(10, voteTx), nil)
+	assert.Equal(t, common.Fixed64(100),
+		state.getProducer(producer.NodePublicKey).votes)
+	assert.NoError(t, state.RollbackTo(9))
+	assert.Equal(t, common.Fixed64(0),
+		state.getProducer(producer.NodePublicKey).votes)
+}
+
+func TestState_GetVoteDetails(t *testing.T) {
+	state := NewState(&config.DefaultParams, nil, nil)
+
+	producer := &payload.ProducerInfo{
+		OwnerPublicKey: randomOwnerPublicKey(),
+		NodePublicKey:  make([]byte, 33),
+		NickName:       "Producer",
+	}
+	rand.Read(producer.NodePublicKey)
+	state.ProcessBlock(mockBlock(1, mockRegisterProducerTx(producer)), nil)
+	assert.Equal(t, 0, len(state.GetVoteDetails(producer.NodePublicKey)))
+
+	voteTx1 := mockVoteTx([][]byte{producer.OwnerPublicKey})
+	voteTx2 := mockNewVoteTx([][]byte{producer.NodePublicKey})
+	state.ProcessBlock(mockBlock(2, voteTx1, voteTx2), nil)
+
+	details := state.GetVoteDetails(producer.OwnerPublicKey)
+	assert.Equal(t, 2, len(details))
+	votes := make(map[common.Uint256]common.Fixed64)
+	for _, d := range details {
+		votes[d.OutPoint.TxID] = d.Votes
+	}
+	assert.Equal(t, common.Fixed64(100), votes[voteTx1.Hash()])
+	assert.Equal(t, common.Fixed64(10), votes[voteTx2.Hash()])
+
+	// Spent vote outputs are not included.
+	state.ProcessBlock(mockBlock(3, mockCancelVoteTx(voteTx1)), nil)
+	details = state.GetVoteDetails(producer.NodePublicKey)
+	assert.Equal(t, 1, len(details))
+	assert.Equal(t, voteTx2.Hash(), details[0].OutPoint.TxID)
+
+	// Unknown producer.
+	assert.Nil(t, state.GetVoteDetails(randomOwnerPublicKey()))
+}
+
 func TestState_InactiveProducer_Normal(t *testing.T) {
 	arbitrators := &ArbitratorsMock{}
 	state := NewState(&config.DefaultParams, arbitrators.GetArbitrators, nil)
@@ -1370,3 +1588,171 @@ func TestState_ProcessBlock_DepositAndReturnDeposit(t *testing.T) {
 	state.history.Commit(height)
 	assert.Equal(t, common.Fixed64(100), candidate.depositAmount)
 }
+
+func TestState_ProcessProducerAppeal(t *testing.T) {
+	state := NewState(&config.DefaultParams, nil, nil)
+
+	info := &payload.ProducerInfo{
+		OwnerPublicKey: randomOwnerPublicKey(),
+		NodePublicKey:  make([]byte, 33),
+		NickName:       "Producer",
+	}
+	rand.Read(info.NodePublicKey)
+	state.ProcessBlock(mockBlock(1, mockRegisterProducerTx(info)), nil)
+	for i := uint32(2); i <= 6; i++ {
+		state.ProcessBlock(mockBlock(i), nil)
+	}
+
+	// Set the producer inactive with penalty.
+	state.ProcessBlock(mockBlock(7,
+		mockInactiveArbitratorsTx(info.NodePublicKey)), nil)
+	producer := state.GetProducer(info.OwnerPublicKey)
+	if !assert.Equal(t, Inactive, producer.State()) {
+		t.FailNow()
+	}
+	penalty := producer.Penalty()
+	assert.NotEqual(t, common.Fixed64(0), penalty)
+
+	// The appeal is recorded until the activation height.
+	state.ProcessBlock(mockBlock(8, &types.Transaction{
+		TxType: types.ProducerAppeal,
+		Payload: &payload.ProducerAppeal{
+			OwnerPublicKey:   info.OwnerPublicKey,
+			ActivationHeight: 10,
+		},
+	}), nil)
+	state.ProcessBlock(mockBlock(9), nil)
+	height, ok := state.GetProducerAppeal(info.OwnerPublicKey)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(10), height)
+	assert.Equal(t, Inactive, producer.State())
+
+	// The penalty is lifted at the activation height.
+	state.ProcessBlock(mockBlock(10), nil)
+	_, ok = state.GetProducerAppeal(info.OwnerPublicKey)
+	assert.False(t, ok)
+	assert.Equal(t, Active, producer.State())
+	assert.Equal(t, common.Fixed64(0), producer.Penalty())
+	assert.True(t, state.IsActiveProducer(info.OwnerPublicKey))
+	history, err := state.GetProducerHistory(info.OwnerPublicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, CauseAppeal, history[len(history)-1].Cause)
+	assert.Equal(t, Inactive, history[len(history)-1].OldState)
+
+	// Rollback should restore the penalty and the appeal.
+	assert.NoError(t, state.RollbackTo(9))
+	producer = state.GetProducer(info.OwnerPublicKey)
+	assert.Equal(t, Inactive, producer.State())
+	assert.Equal(t, penalty, producer.Penalty())
+	_, ok = state.GetProducerAppeal(info.OwnerPublicKey)
+	assert.True(t, ok)
+}
+
+func TestState_ProcessRotateNodeKey(t *testing.T) {
+	state := NewState(&config.DefaultParams, nil, nil)
+
+	info := &payload.ProducerInfo{
+		OwnerPublicKey: randomOwnerPublicKey(),
+		NodePublicKey:  make([]byte, 33),
+		NickName:       "Producer",
+	}
+	rand.Read(info.NodePublicKey)
+	state.ProcessBlock(mockBlock(1, mockRegisterProducerTx(info)), nil)
+	_, ok := state.GetLastNodeKeyRotation(info.OwnerPublicKey)
+	assert.False(t, ok)
+
+	newNodePublicKey := make([]byte, 33)
+	rand.Read(newNodePublicKey)
+	state.ProcessBlock(mockBlock(2, &types.Transaction{
+		TxType: types.RotateNodeKey,
+		Payload: &payload.RotateNodeKey{
+			OwnerPublicKey:   info.OwnerPublicKey,
+			NodePublicKey:    info.NodePublicKey,
+			NewNodePublicKey: newNodePublicKey,
+		},
+	}), nil)
+
+	// The producer is found by the new node key only.
+	producer := state.GetProducer(newNodePublicKey)
+	if !assert.NotNil(t, producer) {
+		t.FailNow()
+	}
+	assert.Equal(t, info.OwnerPublicKey, producer.OwnerPublicKey())
+	assert.Equal(t, newNodePublicKey, producer.NodePublicKey())
+	assert.True(t, state.ProducerNodePublicKeyExists(newNodePublicKey))
+	assert.False(t, state.ProducerNodePublicKeyExists(info.NodePublicKey))
+	height, ok := state.GetLastNodeKeyRotation(info.OwnerPublicKey)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(2), height)
+	history, err := state.GetProducerHistory(info.OwnerPublicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, CauseRotateNodeKey, history[len(history)-1].Cause)
+	assert.Equal(t, info.NodePublicKey,
+		history[len(history)-1].OldNodePublicKey)
+
+	// Rollback should restore the old node key.
+	assert.NoError(t, state.RollbackTo(1))
+	producer = state.GetProducer(info.NodePublicKey)
+	if !assert.NotNil(t, producer) {
+		t.FailNow()
+	}
+	assert.Equal(t, info.NodePublicKey, producer.NodePublicKey())
+	assert.False(t, state.ProducerNodePublicKeyExists(newNodePublicKey))
+	_, ok = state.GetLastNodeKeyRotation(info.OwnerPublicKey)
+	assert.False(t, ok)
+}
+
+func TestState_VoteDecay(t *testing.T) {
+	params := config.DefaultParams
+	params.VoteDecayHeight = 0
+	params.VoteDecayInactiveRounds = 10
+	params.VoteDecayRatio = 0.5
+	state := NewState(&params, nil, nil)
+
+	info := &payload.ProducerInfo{
+		OwnerPublicKey: randomOwnerPublicKey(),
+		NodePublicKey:  make([]byte, 33),
+		NickName:       "Producer",
+	}
+	rand.Read(info.NodePublicKey)
+	state.ProcessBlock(mockBlock(1, mockRegisterProducerTx(info)), nil)
+	for height := uint32(2); height <= 6; height++ {
+		state.ProcessBlock(mockBlock(height), nil)
+	}
+	producer := state.getProducer(info.OwnerPublicKey)
+	if !assert.Equal(t, Active, producer.State()) {
+		t.FailNow()
+	}
+	producer.votes = 100
+
+	// set inactive at height 7 and activate at height 25
+	state.setInactiveProducer(producer,
+		common.BytesToHexString(info.OwnerPublicKey), 7, false)
+	for height := uint32(7); height < 20; height++ {
+		state.ProcessBlock(mockBlock(height), nil)
+	}
+	state.ProcessBlock(mockBlock(20,
+		mockActivateProducerTx(info.NodePublicKey)), nil)
+	for height := uint32(21); height <= 25; height++ {
+		state.ProcessBlock(mockBlock(height), nil)
+	}
+	assert.Equal(t, Active, producer.State())
+
+	// votes are discounted for 18 rounds the producer has been inactive
+	assert.Equal(t, uint32(43), producer.VoteDecayEndHeight())
+	assert.Equal(t, common.Fixed64(50), state.getEffectiveVotes(producer, 26))
+	assert.Equal(t, common.Fixed64(50), state.getEffectiveVotes(producer, 42))
+	assert.Equal(t, common.Fixed64(100), state.getEffectiveVotes(producer, 43))
+
+	// rollback the activation
+	assert.NoError(t, state.RollbackTo(24))
+	assert.Equal(t, Inactive, producer.State())
+	assert.Equal(t, uint32(0), producer.VoteDecayEndHeight())
+	assert.Equal(t, common.Fixed64(100), state.getEffectiveVotes(producer, 26))
+
+	// no discount if not inactive for more than VoteDecayInactiveRounds
+	producer.inactiveSince = 20
+	state.ProcessBlock(mockBlock(25), nil)
+	assert.Equal(t, Active, producer.State())
+	assert.Equal(t, uint32(0), producer.VoteDecayEndHeight())
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package state
+
+import (
+	"encoding/hex"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+)
+
+// VoteDetail represents the votes to a producer from a vote output.
+type VoteDetail struct {
+	OutPoint    types.OutPoint
+	ProgramHash common.Uint168
+	Votes       common.Fixed64
+}
+
+// GetVoteDetails returns the votes from all unspent vote outputs to the
+// producer with specified node public key or owner public key.
+func (s *State) GetVoteDetails(publicKey []byte) []*VoteDetail {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	key := s.getProducerKey(publicKey)
+	if s.getProducerByOwnerPublicKey(key) == nil {
+		return nil
+	}
+
+	var details []*VoteDetail
+	for referKey, output := range s.Votes {
+		if output == nil {
+			continue
+		}
+		var votes common.Fixed64
+		p := output.Payload.(*outputpayload.VoteOutput)
+		for _, content := range p.Contents {
+			if content.VoteType != outputpayload.Delegate {
+				continue
+			}
+			for _, cv := range content.CandidateVotes {
+				if s.getProducerKey(cv.Candidate) != key {
+					continue
+				}
+				if p.Version == outputpayload.VoteProducerVersion {
+					votes += output.Value
+				} else {
+					votes += cv.Votes
+				}
+			}
+		}
+		if votes == 0 {
+			continue
+		}
+
+		buf, err := hex.DecodeString(referKey)
+		if err != nil {
+			continue
+		}
+		op, err := types.OutPointFromBytes(buf)
+		if err != nil {
+			continue
+		}
+		details = append(details, &VoteDetail{
+			OutPoint:    *op,
+			ProgramHash: output.ProgramHash,
+			Votes:       votes,
+		})
+	}
+	return details
+}
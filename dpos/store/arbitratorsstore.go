@@ -38,6 +38,19 @@ func (s *DposStore) GetCheckPoint(height uint32) (*state.CheckPoint, error) {
 	return nil, errors.New("can't find check point")
 }
 
+// GetCheckPointRange returns the heights of the earliest and the latest
+// arbiters checkpoints saved.
+func (s *DposStore) GetCheckPointRange() (uint32, uint32, error) {
+	heights, err := s.GetHeightsDesc()
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(heights) == 0 {
+		return 0, 0, errors.New("can't find check point")
+	}
+	return heights[len(heights)-1], heights[0], nil
+}
+
 func (s *DposStore) SaveArbitersState(point *state.CheckPoint) (err error) {
 	batch := s.db.NewBatch()
 
@@ -53,6 +66,122 @@ func (s *DposStore) SaveArbitersState(point *state.CheckPoint) (err error) {
 
 	if err = batch.Commit(); err != nil {
 		log.Warn("[SaveArbitersState] batch commit err: ", err)
+		return
+	}
+
+	if err = s.CompactCheckPoints(); err != nil {
+		log.Warn("[SaveArbitersState] CompactCheckPoints err: ", err)
 	}
 	return
 }
+
+// CompactCheckPoints removes the arbiters checkpoints out of the retention
+// policy, the latest CheckPointRetainCount checkpoints and the archived ones
+// are kept.
+func (s *DposStore) CompactCheckPoints() error {
+	retainCount := s.chainParams.CheckPointRetainCount
+	if retainCount == 0 {
+		return nil
+	}
+
+	heights, err := s.GetHeightsDesc()
+	if err != nil {
+		return err
+	}
+	if uint32(len(heights)) <= retainCount {
+		return nil
+	}
+
+	batch := s.db.NewBatch()
+	reserved := make([]uint32, 0, len(heights))
+	reserved = append(reserved, heights[:retainCount]...)
+	removed := make([]uint32, 0, len(heights)-int(retainCount))
+	for _, h := range heights[retainCount:] {
+		if s.isArchivedCheckPoint(h) {
+			reserved = append(reserved, h)
+			continue
+		}
+
+		key, err := s.getKey(h, DPOSSingleCheckPoint)
+		if err != nil {
+			return err
+		}
+		if err = batch.Delete(key); err != nil {
+			return err
+		}
+		removed = append(removed, h)
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	if err = s.putHeights(batch, reserved); err != nil {
+		return err
+	}
+	if err = batch.Commit(); err != nil {
+		return err
+	}
+
+	s.removeFlatCheckPoints(removed)
+	return nil
+}
+
+// removeFlatCheckPoints removes files of the checkpoints at the given heights,
+// it should be called after the heights committed, so that the heights never
+// refer to a removed checkpoint.  A file failed to be removed is left as is,
+// since it's no longer referred by the heights.
+func (s *DposStore) removeFlatCheckPoints(heights []uint32) {
+	for _, h := range heights {
+		if err := s.removeFlatCheckPoint(h); err != nil {
+			log.Warn("[removeFlatCheckPoints] remove check point file err: ",
+				err)
+		}
+	}
+}
+
+// isArchivedCheckPoint returns if the checkpoint of the given height should be
+// kept as the archive of its span.
+func (s *DposStore) isArchivedCheckPoint(height uint32) bool {
+	span := s.chainParams.CheckPointArchiveSpan
+	if span == 0 {
+		return false
+	}
+	return (height/state.CheckPointInterval)%span == 0
+}
+
+// RollbackTo removes all arbiters checkpoints saved after the given height.
+func (s *DposStore) RollbackTo(height uint32) error {
+	heights, err := s.getHeights()
+	if err != nil {
+		return err
+	}
+
+	batch := s.db.NewBatch()
+	reserved := make([]uint32, 0, len(heights))
+	removed := make([]uint32, 0, len(heights))
+	for _, h := range heights {
+		if h <= height {
+			reserved = append(reserved, h)
+			continue
+		}
+
+		key, err := s.getKey(h, DPOSSingleCheckPoint)
+		if err != nil {
+			return err
+		}
+		if err = batch.Delete(key); err != nil {
+			return err
+		}
+		removed = append(removed, h)
+	}
+
+	if err = s.putHeights(batch, reserved); err != nil {
+		return err
+	}
+	if err = batch.Commit(); err != nil {
+		return err
+	}
+
+	s.removeFlatCheckPoints(removed)
+	return nil
+}
@@ -76,6 +76,67 @@ func TestArbitratorsStore_GetCheckPoint(t *testing.T) {
 	assert.True(t, checkPointsEqual(secondPoint, actual))
 }
 
+func TestArbitratorsStore_RollbackTo(t *testing.T) {
+	thirdPoint := generateCheckPoint(30)
+	arbitratorsStore.SaveArbitersState(thirdPoint)
+
+	assert.NoError(t, arbitratorsStore.RollbackTo(20))
+	heights, err := arbitratorsStore.GetHeightsDesc()
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{20, 10}, heights)
+
+	// the latest check point should be the second one after rollback
+	actual, err := arbitratorsStore.GetCheckPoint(31 + state.CheckPointInterval)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(20), actual.Height)
+
+	// a missing check point file should not stop the rollback
+	assert.NoError(t, arbitratorsStore.removeFlatCheckPoint(20))
+	assert.NoError(t, arbitratorsStore.RollbackTo(10))
+	heights, err = arbitratorsStore.GetHeightsDesc()
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{10}, heights)
+}
+
+func TestArbitratorsStore_CompactCheckPoints(t *testing.T) {
+	assert.NoError(t, arbitratorsStore.RollbackTo(0))
+	_, _, err := arbitratorsStore.GetCheckPointRange()
+	assert.Error(t, err)
+
+	params := config.DefaultParams
+	params.CheckPointRetainCount = 3
+	params.CheckPointArchiveSpan = 4
+	arbitratorsStore.chainParams = &params
+	defer func() {
+		arbitratorsStore.chainParams = &config.DefaultParams
+	}()
+
+	for i := uint32(1); i <= 10; i++ {
+		assert.NoError(t, arbitratorsStore.SaveArbitersState(
+			generateCheckPoint(i*state.CheckPointInterval)))
+	}
+
+	// the latest 3 check points and the archived ones should be kept
+	heights, err := arbitratorsStore.GetHeightsDesc()
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{10 * state.CheckPointInterval,
+		9 * state.CheckPointInterval, 8 * state.CheckPointInterval,
+		4 * state.CheckPointInterval}, heights)
+
+	first, last, err := arbitratorsStore.GetCheckPointRange()
+	assert.NoError(t, err)
+	assert.Equal(t, 4*state.CheckPointInterval, first)
+	assert.Equal(t, 10*state.CheckPointInterval, last)
+
+	_, err = arbitratorsStore.getFlatCheckPoint(5 * state.CheckPointInterval)
+	assert.Error(t, err)
+	actual, err := arbitratorsStore.GetCheckPoint(8 * state.CheckPointInterval)
+	assert.NoError(t, err)
+	assert.Equal(t, 4*state.CheckPointInterval, actual.Height)
+
+	assert.NoError(t, arbitratorsStore.RollbackTo(0))
+}
+
 func TestArbitratorsStore_Close(t *testing.T) {
 	arbitratorsStore.deleteTable(ProposalEventTable)
 	arbitratorsStore.deleteTable(ConsensusEventTable)
@@ -0,0 +1,60 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package store
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/dpos/p2p/msg"
+)
+
+// ConsensusRound is the in-flight consensus round at the height, including
+// the proposals, collected votes and view offset.
+type ConsensusRound struct {
+	Height uint32
+	Status msg.ConsensusStatus
+}
+
+func (r *ConsensusRound) Serialize(w io.Writer) error {
+	if err := common.WriteUint32(w, r.Height); err != nil {
+		return err
+	}
+	return r.Status.Serialize(w)
+}
+
+func (r *ConsensusRound) Deserialize(reader io.Reader) (err error) {
+	if r.Height, err = common.ReadUint32(reader); err != nil {
+		return err
+	}
+	return r.Status.Deserialize(reader)
+}
+
+func (s *DposStore) SaveConsensusRound(round *ConsensusRound) error {
+	buf := new(bytes.Buffer)
+	if err := round.Serialize(buf); err != nil {
+		return err
+	}
+	return s.db.Put([]byte{byte(DPOSConsensusRound)}, buf.Bytes())
+}
+
+func (s *DposStore) GetConsensusRound() (*ConsensusRound, error) {
+	data, err := s.db.Get([]byte{byte(DPOSConsensusRound)})
+	if err != nil {
+		return nil, err
+	}
+
+	round := &ConsensusRound{}
+	if err := round.Deserialize(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return round, nil
+}
+
+func (s *DposStore) ClearConsensusRound() error {
+	return s.db.Delete([]byte{byte(DPOSConsensusRound)})
+}
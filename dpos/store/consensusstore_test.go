@@ -0,0 +1,71 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/dpos/p2p/msg"
+	"github.com/elastos/Elastos.ELA/utils/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDposStore_ConsensusRound(t *testing.T) {
+	store, err := NewDposStore(test.DataPath, &config.DefaultParams)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer store.Close()
+
+	_, err = store.GetConsensusRound()
+	assert.Error(t, err)
+
+	proposal := payload.DPOSProposal{
+		Sponsor:    randomPkBytes(),
+		BlockHash:  common.Uint256{1},
+		Sign:       []byte{1, 2, 3},
+		ViewOffset: 2,
+	}
+	vote := payload.DPOSProposalVote{
+		ProposalHash: proposal.Hash(),
+		Signer:       randomPkBytes(),
+		Accept:       true,
+		Sign:         []byte{4, 5, 6},
+	}
+	round := &ConsensusRound{
+		Height: 100,
+		Status: msg.ConsensusStatus{
+			ConsensusStatus:  1,
+			ViewOffset:       2,
+			ViewStartTime:    time.Unix(1000, 0),
+			AcceptVotes:      []payload.DPOSProposalVote{vote},
+			RejectedVotes:    []payload.DPOSProposalVote{},
+			PendingProposals: []payload.DPOSProposal{proposal},
+			PendingVotes:     []payload.DPOSProposalVote{},
+		},
+	}
+	assert.NoError(t, store.SaveConsensusRound(round))
+
+	saved, err := store.GetConsensusRound()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(100), saved.Height)
+	assert.Equal(t, uint32(1), saved.Status.ConsensusStatus)
+	assert.Equal(t, uint32(2), saved.Status.ViewOffset)
+	assert.True(t, round.Status.ViewStartTime.Equal(saved.Status.ViewStartTime))
+	assert.Equal(t, 1, len(saved.Status.AcceptVotes))
+	assert.Equal(t, vote.Hash(), saved.Status.AcceptVotes[0].Hash())
+	assert.Equal(t, 1, len(saved.Status.PendingProposals))
+	assert.Equal(t, proposal.Hash(), saved.Status.PendingProposals[0].Hash())
+
+	assert.NoError(t, store.ClearConsensusRound())
+	_, err = store.GetConsensusRound()
+	assert.Error(t, err)
+}
@@ -12,4 +12,5 @@ const (
 	// DPOS
 	DPOSCheckPointHeights  DataEntryPrefix = 0x10
 	DPOSSingleCheckPoint   DataEntryPrefix = 0x11
+	DPOSConsensusRound     DataEntryPrefix = 0x12
 )
@@ -60,7 +60,10 @@ func (s *DposStore) appendHeights(batch Batch, height uint32) error {
 	if err != nil {
 		return err
 	}
-	heights = append(heights, height)
+	return s.putHeights(batch, append(heights, height))
+}
+
+func (s *DposStore) putHeights(batch Batch, heights []uint32) error {
 	heightSet := make(map[uint32]interface{})
 	for _, v := range heights {
 		heightSet[v] = nil
@@ -68,12 +71,12 @@ func (s *DposStore) appendHeights(batch Batch, height uint32) error {
 
 	key := []byte{byte(DPOSCheckPointHeights)}
 	buf := new(bytes.Buffer)
-	if err = common.WriteVarUint(buf, uint64(len(heightSet))); err != nil {
+	if err := common.WriteVarUint(buf, uint64(len(heightSet))); err != nil {
 		return err
 	}
 
 	for h := range heightSet {
-		if err = common.WriteUint32(buf, h); err != nil {
+		if err := common.WriteUint32(buf, h); err != nil {
 			return err
 		}
 	}
@@ -127,6 +130,15 @@ func (s *DposStore) saveFlatCheckPoint(point *state.CheckPoint) error {
 	return file.Close()
 }
 
+func (s *DposStore) removeFlatCheckPoint(height uint32) error {
+	fileName := filepath.Join(s.dataDir, "dpos",
+		strconv.FormatUint(uint64(height), 10)+flatCheckPointExtension)
+	if err := os.Remove(fileName); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 func (s *DposStore) getFlatCheckPoint(height uint32) (*state.CheckPoint,
 	error) {
 	fileName := filepath.Join(s.dataDir, "dpos",
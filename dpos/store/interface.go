@@ -51,9 +51,18 @@ type IEventRecord interface {
 	UpdateConsensusEvent(event interface{}) error
 }
 
+// IConsensusRecord persists the in-flight consensus round, so an arbiter can
+// continue the round after restart.
+type IConsensusRecord interface {
+	SaveConsensusRound(round *ConsensusRound) error
+	GetConsensusRound() (*ConsensusRound, error)
+	ClearConsensusRound() error
+}
+
 // IDposStore provides func for dpos
 type IDposStore interface {
 	IDBOperator
 	IEventRecord
+	IConsensusRecord
 	state.IArbitratorsRecord
 }
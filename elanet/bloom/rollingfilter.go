@@ -0,0 +1,201 @@
+// Copyright (c) 2017-2019 Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package bloom
+
+import (
+	"errors"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+// maxRollingFilterSize is the maximum size in bytes of a generation of the
+// rolling filter.
+const maxRollingFilterSize = 8 * 1024 * 1024
+
+// RollingFilter is a bloom filter remembers the recently added elements, it
+// keeps two generations of bloom filters, the older generation is dropped
+// once the current one is full.  So at least the latest elements count of
+// elements are remembered, and the false positive rate is about twice of the
+// given one.
+type RollingFilter struct {
+	mtx       sync.Mutex
+	elements  uint32
+	hashFuncs uint32
+	tweak     uint32
+	count     uint32
+	current   []byte
+	previous  []byte
+}
+
+// NewRollingFilter creates a rolling filter which remembers at least the
+// latest elements count of elements.  The tweak parameter is a random value
+// added to the seed value.  The false positive rate is adjusted to the valid
+// range like NewFilter.
+func NewRollingFilter(elements, tweak uint32, fprate float64) *RollingFilter {
+	if elements == 0 {
+		elements = 1
+	}
+	if fprate > 1.0 {
+		fprate = 1.0
+	}
+	if fprate < 1e-9 {
+		fprate = 1e-9
+	}
+
+	// Equivalent to m = -(n*ln(p) / ln(2)^2), where m is in bits.
+	dataLen := uint64(-1*float64(elements)*math.Log(fprate)/ln2Squared) / 8
+	if dataLen > maxRollingFilterSize {
+		dataLen = maxRollingFilterSize
+	}
+	if dataLen == 0 {
+		dataLen = 1
+	}
+
+	// Equivalent to k = (m/n) * ln(2)
+	hashFuncs := uint32(float64(dataLen*8) / float64(elements) * math.Ln2)
+	hashFuncs = minUint32(hashFuncs, MaxFilterLoadHashFuncs)
+	if hashFuncs == 0 {
+		hashFuncs = 1
+	}
+
+	return &RollingFilter{
+		elements:  elements,
+		hashFuncs: hashFuncs,
+		tweak:     tweak,
+		current:   make([]byte, dataLen),
+		previous:  make([]byte, dataLen),
+	}
+}
+
+// hash returns the bit offset in the filter of the data for the hash
+// function.
+func (f *RollingFilter) hash(hashNum uint32, data []byte) uint32 {
+	mm := MurmurHash3(hashNum*0xfba4c795+f.tweak, data)
+	return mm % (uint32(len(f.current)) << 3)
+}
+
+// contains returns if the data might be in the filter data.
+func (f *RollingFilter) contains(filter []byte, data []byte) bool {
+	for i := uint32(0); i < f.hashFuncs; i++ {
+		idx := f.hash(i, data)
+		if filter[idx>>3]&(1<<(idx&7)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Add adds the data to the filter, the oldest generation is dropped if the
+// current generation is full.
+//
+// This function is safe for concurrent access.
+func (f *RollingFilter) Add(data []byte) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	if f.contains(f.current, data) {
+		return
+	}
+	if f.count >= f.elements {
+		f.previous, f.current = f.current, f.previous
+		for i := range f.current {
+			f.current[i] = 0
+		}
+		f.count = 0
+	}
+	for i := uint32(0); i < f.hashFuncs; i++ {
+		idx := f.hash(i, data)
+		f.current[idx>>3] |= 1 << (idx & 7)
+	}
+	f.count++
+}
+
+// Contains returns true if the data might have been added recently and false
+// if it definitely has not.
+//
+// This function is safe for concurrent access.
+func (f *RollingFilter) Contains(data []byte) bool {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.contains(f.current, data) || f.contains(f.previous, data)
+}
+
+// Reset removes all the elements from the filter.
+//
+// This function is safe for concurrent access.
+func (f *RollingFilter) Reset() {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for i := range f.current {
+		f.current[i] = 0
+		f.previous[i] = 0
+	}
+	f.count = 0
+}
+
+// Serialize writes the filter to the writer.
+//
+// This function is safe for concurrent access.
+func (f *RollingFilter) Serialize(w io.Writer) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	for _, v := range []uint32{f.elements, f.hashFuncs, f.tweak, f.count} {
+		if err := common.WriteUint32(w, v); err != nil {
+			return err
+		}
+	}
+	if err := common.WriteVarBytes(w, f.current); err != nil {
+		return err
+	}
+	return common.WriteVarBytes(w, f.previous)
+}
+
+// Deserialize reads the filter from the reader, the filter should be created
+// with the same elements count and false positive rate, otherwise an error
+// is returned and the filter is not changed.
+//
+// This function is safe for concurrent access.
+func (f *RollingFilter) Deserialize(r io.Reader) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	var values [4]uint32
+	for i := range values {
+		v, err := common.ReadUint32(r)
+		if err != nil {
+			return err
+		}
+		values[i] = v
+	}
+	elements, hashFuncs, tweak, count := values[0], values[1], values[2],
+		values[3]
+	if elements != f.elements || hashFuncs != f.hashFuncs {
+		return errors.New("rolling filter parameters mismatch")
+	}
+
+	size := uint32(len(f.current))
+	current, err := common.ReadVarBytes(r, size, "current filter")
+	if err != nil {
+		return err
+	}
+	previous, err := common.ReadVarBytes(r, size, "previous filter")
+	if err != nil {
+		return err
+	}
+	if uint32(len(current)) != size || uint32(len(previous)) != size {
+		return errors.New("rolling filter size mismatch")
+	}
+
+	f.tweak = tweak
+	f.count = count
+	f.current = current
+	f.previous = previous
+	return nil
+}
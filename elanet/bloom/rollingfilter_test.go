@@ -0,0 +1,69 @@
+// Copyright (c) 2017-2019 Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package bloom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func rollingFilterData(i uint32) []byte {
+	data := make([]byte, 32)
+	binary.LittleEndian.PutUint32(data, i)
+	return data
+}
+
+func TestRollingFilter(t *testing.T) {
+	const elements = 100
+	f := NewRollingFilter(elements, 0, 1e-6)
+
+	// The latest elements should be always remembered.
+	for i := uint32(0); i < elements*5; i++ {
+		f.Add(rollingFilterData(i))
+		for j := uint32(0); j < elements && j <= i; j++ {
+			if !f.Contains(rollingFilterData(i - j)) {
+				t.Fatalf("element %d should be remembered after %d added",
+					i-j, i)
+			}
+		}
+	}
+
+	// The elements older than two generations should be dropped.
+	var remembered int
+	for i := uint32(0); i < elements*2; i++ {
+		if f.Contains(rollingFilterData(i)) {
+			remembered++
+		}
+	}
+	assert.Equal(t, 0, remembered)
+
+	f.Reset()
+	assert.False(t, f.Contains(rollingFilterData(elements*5-1)))
+}
+
+func TestRollingFilter_Serialize(t *testing.T) {
+	f1 := NewRollingFilter(100, 7, 1e-6)
+	for i := uint32(0); i < 150; i++ {
+		f1.Add(rollingFilterData(i))
+	}
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, f1.Serialize(buf))
+	data := buf.Bytes()
+
+	f2 := NewRollingFilter(100, 0, 1e-6)
+	assert.NoError(t, f2.Deserialize(bytes.NewReader(data)))
+	for i := uint32(50); i < 150; i++ {
+		assert.True(t, f2.Contains(rollingFilterData(i)))
+	}
+
+	// Filters with different parameters can not be deserialized.
+	f3 := NewRollingFilter(200, 0, 1e-6)
+	assert.Error(t, f3.Deserialize(bytes.NewReader(data)))
+}
@@ -28,4 +28,8 @@ type Config struct {
 	BlockMemPool *mempool.BlockPool
 
 	MaxPeers int
+
+	// RelayFilterPath is the file to persist the recently relayed
+	// transactions across restarts, empty means not persisted.
+	RelayFilterPath string
 }
@@ -6,6 +6,7 @@
 package netsync
 
 import (
+	"container/list"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -15,12 +16,16 @@ import (
 	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/types"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/elanet/bloom"
 	"github.com/elastos/Elastos.ELA/elanet/pact"
 	"github.com/elastos/Elastos.ELA/elanet/peer"
 	"github.com/elastos/Elastos.ELA/events"
 	"github.com/elastos/Elastos.ELA/mempool"
 	"github.com/elastos/Elastos.ELA/p2p"
 	"github.com/elastos/Elastos.ELA/p2p/msg"
+	"github.com/elastos/Elastos.ELA/utils/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -35,6 +40,11 @@ const (
 	// maxRequestedTxns is the maximum number of requested transactions
 	// hashes to store in memory.
 	maxRequestedTxns = msg.MaxInvPerMsg
+
+	// minInFlightBlocks is the minimum number of blocks that should be
+	// in the request queue for headers-first mode before requesting
+	// more.
+	minInFlightBlocks = 10
 )
 
 // zeroHash is the zero value hash (all zeros).  It is defined as a convenience.
@@ -53,6 +63,13 @@ type blockMsg struct {
 	reply chan struct{}
 }
 
+// headersMsg packages a headers message and the peer it came from together
+// so the block handler has access to that information.
+type headersMsg struct {
+	headers *msg.Headers
+	peer    *peer.Peer
+}
+
 // invMsg packages a bitcoin inv message and the peer it came from together
 // so the block handler has access to that information.
 type invMsg struct {
@@ -94,6 +111,13 @@ type pauseMsg struct {
 	unpause <-chan struct{}
 }
 
+// headerNode is used as a node in a list of headers that are linked together
+// between checkpoints.
+type headerNode struct {
+	height uint32
+	hash   *common.Uint256
+}
+
 // peerSyncState stores additional information that the SyncManager tracks
 // about a peer.
 type peerSyncState struct {
@@ -121,6 +145,11 @@ type SyncManager struct {
 	wg           sync.WaitGroup
 	quit         chan struct{}
 
+	// relayedTxns remembers the recently relayed transactions, it's
+	// persisted to relayFilterPath on stop.
+	relayedTxns     *bloom.RollingFilter
+	relayFilterPath string
+
 	// These fields should only be accessed from the blockHandler thread
 	rejectedTxns             map[common.Uint256]struct{}
 	requestedTxns            map[common.Uint256]struct{}
@@ -129,6 +158,42 @@ type SyncManager struct {
 	syncPeer                 *peer.Peer
 	syncHeight               uint32
 	peerStates               map[*peer.Peer]*peerSyncState
+
+	// The following fields are used for headers-first mode.
+	headersFirstMode bool
+	headerList       *list.List
+	startHeader      *list.Element
+	nextCheckpoint   *config.Checkpoint
+}
+
+// resetHeaderState sets the headers-first mode state to values appropriate for
+// syncing from a new peer.
+func (sm *SyncManager) resetHeaderState(newestHash *common.Uint256, newestHeight uint32) {
+	sm.headersFirstMode = false
+	sm.headerList.Init()
+	sm.startHeader = nil
+
+	// When there is a next checkpoint, add an entry for the latest known
+	// block into the header pool.  This allows the next downloaded header
+	// to prove it links to the chain properly.
+	if sm.nextCheckpoint != nil {
+		node := headerNode{height: newestHeight, hash: newestHash}
+		sm.headerList.PushBack(&node)
+	}
+}
+
+// findNextHeaderCheckpoint returns the latest checkpoint if the height is
+// before it and headers-first mode is enabled, otherwise nil is returned.
+func (sm *SyncManager) findNextHeaderCheckpoint(height uint32) *config.Checkpoint {
+	if !sm.chainParams.HeadersFirst {
+		return nil
+	}
+
+	checkpoint := sm.chain.LatestCheckpoint()
+	if checkpoint == nil || height >= checkpoint.Height {
+		return nil
+	}
+	return checkpoint
 }
 
 // startSync will choose the best peer among the available candidate peers to
@@ -189,7 +254,24 @@ func (sm *SyncManager) startSync() {
 
 		sm.syncPeer = bestPeer
 		sm.syncHeight = bestPeer.Height()
-		bestPeer.PushGetBlocksMsg(locator, &zeroHash)
+
+		// When the current height is less than the latest checkpoint and
+		// the peer supports headers, download the headers to the
+		// checkpoint first, then fetch the blocks they describe.
+		sm.nextCheckpoint = sm.findNextHeaderCheckpoint(bestHeight)
+		if sm.nextCheckpoint != nil &&
+			bestPeer.Height() >= sm.nextCheckpoint.Height &&
+			bestPeer.Services()&pact.SFNodeHeaders == pact.SFNodeHeaders {
+			bestHash := sm.chain.GetCurrentBlockHash()
+			sm.resetHeaderState(&bestHash, bestHeight)
+			sm.headersFirstMode = true
+			bestPeer.PushGetHeadersMsg(locator, &sm.nextCheckpoint.Hash)
+			log.Infof("Downloading headers for blocks %d to %d from "+
+				"peer %s", bestHeight+1, sm.nextCheckpoint.Height,
+				bestPeer.Addr())
+		} else {
+			bestPeer.PushGetBlocksMsg(locator, &zeroHash)
+		}
 	} else {
 		log.Warnf("No sync peer candidates available")
 	}
@@ -267,9 +349,14 @@ func (sm *SyncManager) handleDonePeerMsg(peer *peer.Peer) {
 	}
 	// Attempt to find a new peer to sync from if the quitting peer is the
 	// sync peer.  Also, reset the headers-first state if in headers-first
-	// mode so
+	// mode so the headers will be downloaded again from the new sync peer.
 	if sm.syncPeer == peer {
 		sm.syncPeer = nil
+		if sm.headersFirstMode {
+			sm.headersFirstMode = false
+			sm.headerList.Init()
+			sm.startHeader = nil
+		}
 		sm.startSync()
 	}
 }
@@ -325,6 +412,14 @@ func (sm *SyncManager) handleTxMsg(tmsg *txMsg) {
 		return
 	}
 
+	// Do not relay the transaction again if it has been relayed recently,
+	// typically before a short restart, peers should already know it.
+	if sm.relayedTxns.Contains(txHash[:]) {
+		log.Debugf("Not relaying recently relayed transaction %v", txHash)
+		return
+	}
+	sm.relayedTxns.Add(txHash[:])
+
 	iv := msg.NewInvVect(msg.InvTypeTx, &txHash)
 	sm.peerNotifier.RelayInventory(iv, tmsg.tx)
 }
@@ -404,8 +499,14 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 	// handling, etc.
 	log.Debugf("Receive block %s at height %d", blockHash,
 		bmsg.block.Block.Height)
+	ctx := tracing.StartBlock(blockHash,
+		attribute.Int64("block.height", int64(bmsg.block.Block.Height)),
+		attribute.String("peer", peer.String()))
+	_, span := tracing.Start(ctx, "netsync.AddDposBlock")
 	_, isOrphan, err := sm.blockMemPool.AddDposBlock(bmsg.block)
+	tracing.End(span, err)
 	if err != nil {
+		tracing.EndBlock(blockHash, err)
 		reason := fmt.Sprintf("Rejected block %v from %s: %v", blockHash,
 			peer, err)
 		log.Info(reason)
@@ -414,6 +515,13 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 		return
 	}
 
+	// Blocks are fetched by the verified headers in headers-first mode, so
+	// there is no need to request the parents of orphans.
+	if sm.headersFirstMode && peer == sm.syncPeer {
+		sm.processHeaderBlocks(peer, state)
+		return
+	}
+
 	if sm.syncPeer != nil && sm.chain.BestChain.Height >= sm.syncHeight {
 		sm.syncPeer = nil
 	}
@@ -440,6 +548,227 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 	}
 }
 
+// processHeaderBlocks removes the processed blocks from the header list in
+// headers-first mode, and requests more blocks if the in flight blocks are
+// running low.  Once all of the blocks to the checkpoint have been processed,
+// it switches back to the normal mode to sync the rest of the chain.
+func (sm *SyncManager) processHeaderBlocks(peer *peer.Peer, state *peerSyncState) {
+	// Blocks may be processed as orphans and connected later, so remove all
+	// of the headers not higher than the best height.
+	bestHeight := sm.chain.GetHeight()
+	for e := sm.headerList.Front(); e != nil &&
+		e.Value.(*headerNode).height <= bestHeight; e = sm.headerList.Front() {
+		if sm.startHeader == e {
+			sm.startHeader = e.Next()
+		}
+		sm.headerList.Remove(e)
+	}
+
+	if sm.headerList.Len() == 0 {
+		log.Infof("Finished downloading blocks to checkpoint at height %d,"+
+			" switching to normal mode", sm.nextCheckpoint.Height)
+		sm.headersFirstMode = false
+		sm.startHeader = nil
+		sm.nextCheckpoint = nil
+		sm.chain.ResetVerifiedHeaders()
+
+		locator, err := sm.chain.LatestBlockLocator()
+		if err != nil {
+			log.Errorf("Failed to get block locator for the "+
+				"latest block: %v", err)
+			return
+		}
+		peer.PushGetBlocksMsg(locator, &zeroHash)
+		return
+	}
+
+	if len(state.requestedConfirmedBlocks) < minInFlightBlocks {
+		sm.fetchHeaderBlocks()
+	}
+}
+
+// fetchHeaderBlocks creates and sends a request to the syncPeer for the next
+// list of blocks to be downloaded based on the current list of headers.
+func (sm *SyncManager) fetchHeaderBlocks() {
+	// Nothing to do if there is no start header.
+	if sm.startHeader == nil {
+		return
+	}
+
+	state, exists := sm.peerStates[sm.syncPeer]
+	if !exists {
+		return
+	}
+
+	// Build up a getdata request for the list of blocks the headers
+	// describe.  Blocks are requested with confirms, blocks before DPOS
+	// are responded without confirms.
+	gdmsg := msg.NewGetData()
+	numRequested := 0
+	for e := sm.startHeader; e != nil; e = e.Next() {
+		node := e.Value.(*headerNode)
+		sm.startHeader = e.Next()
+
+		iv := msg.NewInvVect(msg.InvTypeBlock, node.hash)
+		haveInv, err := sm.haveInventory(iv)
+		if err != nil {
+			log.Warnf("Unexpected failure when checking for "+
+				"existing inventory during header block "+
+				"fetch: %v", err)
+		}
+		if haveInv {
+			continue
+		}
+
+		sm.requestedConfirmedBlocks[*node.hash] = struct{}{}
+		sm.limitMap(sm.requestedConfirmedBlocks, maxRequestedBlocks)
+		state.requestedConfirmedBlocks[*node.hash] = struct{}{}
+		gdmsg.AddInvVect(msg.NewInvVect(msg.InvTypeConfirmedBlock,
+			node.hash))
+		numRequested++
+		if numRequested >= msg.MaxInvPerMsg {
+			break
+		}
+	}
+	if len(gdmsg.InvList) > 0 {
+		sm.syncPeer.QueueMessage(gdmsg, nil)
+	}
+}
+
+// handleHeadersMsg handles block header messages from all peers.  Headers are
+// requested when performing a headers-first sync.
+func (sm *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
+	peer := hmsg.peer
+	if _, exists := sm.peerStates[peer]; !exists {
+		log.Warnf("Received headers message from unknown peer %s", peer)
+		return
+	}
+
+	// The remote peer is misbehaving if we didn't request headers.
+	headers := hmsg.headers.Headers
+	numHeaders := len(headers)
+	if !sm.headersFirstMode || peer != sm.syncPeer {
+		log.Warnf("Got %d unrequested headers from %s -- "+
+			"disconnecting", numHeaders, peer)
+		peer.Disconnect()
+		return
+	}
+
+	// Nothing to do for an empty headers message.
+	if numHeaders == 0 {
+		return
+	}
+
+	blockHeaders := make([]*types.Header, 0, numHeaders)
+	for _, h := range headers {
+		header, ok := h.(*types.Header)
+		if !ok {
+			log.Warnf("Received invalid header type from peer %s", peer)
+			peer.Disconnect()
+			return
+		}
+		blockHeaders = append(blockHeaders, header)
+	}
+
+	// Check the aux pows of all the headers in parallel first, they dominate
+	// the time of header validation, the results are cached for the sanity
+	// checks below.
+	if err := sm.chain.CheckAuxPows(blockHeaders); err != nil {
+		log.Warnf("Received invalid block headers from peer %s: %v "+
+			"-- disconnecting", peer, err)
+		peer.Disconnect()
+		return
+	}
+
+	// Process all of the received headers ensuring each one connects to the
+	// previous and that checkpoints match.
+	receivedCheckpoint := false
+	var finalHash *common.Uint256
+	for _, header := range blockHeaders {
+		blockHash := header.Hash()
+		finalHash = &blockHash
+
+		// Ensure there is a previous header to compare against.
+		prevNodeEl := sm.headerList.Back()
+		if prevNodeEl == nil {
+			log.Warnf("Header list does not contain a previous " +
+				"element as expected -- disconnecting peer")
+			peer.Disconnect()
+			return
+		}
+
+		// Ensure the header properly connects to the previous one and
+		// passes the sanity checks, then add it to the list of headers.
+		prevNode := prevNodeEl.Value.(*headerNode)
+		if !prevNode.hash.IsEqual(header.Previous) ||
+			prevNode.height+1 != header.Height {
+			log.Warnf("Received block header that does not "+
+				"properly connect to the chain from peer %s "+
+				"-- disconnecting", peer)
+			peer.Disconnect()
+			return
+		}
+		if err := sm.chain.CheckHeaderSanity(header); err != nil {
+			log.Warnf("Received invalid block header %s from peer "+
+				"%s: %v -- disconnecting", blockHash, peer, err)
+			peer.Disconnect()
+			return
+		}
+		node := headerNode{height: header.Height, hash: &blockHash}
+		sm.headerList.PushBack(&node)
+
+		// Verify the header at the next checkpoint height matches.
+		if node.height == sm.nextCheckpoint.Height {
+			if !node.hash.IsEqual(sm.nextCheckpoint.Hash) {
+				log.Warnf("Block header at height %d/hash %s "+
+					"from peer %s does NOT match expected "+
+					"checkpoint hash of %s -- disconnecting",
+					node.height, node.hash, peer,
+					sm.nextCheckpoint.Hash)
+				peer.Disconnect()
+				return
+			}
+			log.Infof("Verified downloaded block header against "+
+				"checkpoint at height %d/hash %s", node.height,
+				node.hash)
+			receivedCheckpoint = true
+			break
+		}
+	}
+
+	// When the checkpoint is reached, switch to fetching the blocks for all
+	// of the headers.
+	if receivedCheckpoint {
+		// Remove the first node which is the latest known block.
+		sm.headerList.Remove(sm.headerList.Front())
+		hashes := make([]common.Uint256, 0, sm.headerList.Len())
+		for e := sm.headerList.Front(); e != nil; e = e.Next() {
+			hashes = append(hashes, *e.Value.(*headerNode).hash)
+		}
+		startHeight := sm.headerList.Front().Value.(*headerNode).height
+		if err := sm.chain.SetVerifiedHeaders(startHeight,
+			hashes); err != nil {
+			log.Warnf("Failed to set verified headers: %v", err)
+		}
+
+		log.Infof("Received %v block headers: Fetching blocks",
+			sm.headerList.Len())
+		sm.startHeader = sm.headerList.Front()
+		sm.fetchHeaderBlocks()
+		return
+	}
+
+	// The checkpoint is not reached, so request the next batch of headers
+	// starting from the latest known header and ending with the checkpoint.
+	locator := []*common.Uint256{finalHash}
+	err := peer.PushGetHeadersMsg(locator, &sm.nextCheckpoint.Hash)
+	if err != nil {
+		log.Warnf("Failed to send getheaders message to "+
+			"peer %s: %v", peer.Addr(), err)
+		return
+	}
+}
+
 // haveInventory returns whether or not the inventory represented by the passed
 // inventory vector is known.  This includes checking all of the various places
 // inventory can be when it is in different states such as blocks that are part
@@ -503,6 +832,12 @@ func (sm *SyncManager) handleInvMsg(imsg *invMsg) {
 	// Finally, attempt to detect potential stalls due to long side chains
 	// we already have and request more blocks to prevent them.
 	for _, iv := range invVects {
+		// Ignore block inventories in headers-first mode, the blocks are
+		// fetched by the verified headers.
+		if sm.headersFirstMode && iv.Type != msg.InvTypeTx {
+			continue
+		}
+
 		// Ignore unsupported inventory types.
 		switch iv.Type {
 		case msg.InvTypeBlock:
@@ -531,6 +866,13 @@ func (sm *SyncManager) handleInvMsg(imsg *invMsg) {
 				if _, exists := sm.rejectedTxns[iv.Hash]; exists {
 					continue
 				}
+
+				// Skip the transaction if it has been relayed
+				// recently, it has probably been packed or will
+				// be packed by peers.
+				if sm.relayedTxns.Contains(iv.Hash[:]) {
+					continue
+				}
 			}
 
 			// Add it to the request queue.
@@ -670,6 +1012,9 @@ out:
 			case *invMsg:
 				sm.handleInvMsg(msg)
 
+			case *headersMsg:
+				sm.handleHeadersMsg(msg)
+
 			case *donePeerMsg:
 				sm.handleDonePeerMsg(msg.peer)
 
@@ -863,6 +1208,18 @@ func (sm *SyncManager) QueueInv(inv *msg.Inv, peer *peer.Peer) {
 	sm.msgChan <- &invMsg{inv: inv, peer: peer}
 }
 
+// QueueHeaders adds the passed headers message and peer to the block handling
+// queue.
+func (sm *SyncManager) QueueHeaders(headers *msg.Headers, peer *peer.Peer) {
+	// No channel handling here because peers do not need to block on
+	// headers messages.
+	if atomic.LoadInt32(&sm.shutdown) != 0 {
+		return
+	}
+
+	sm.msgChan <- &headersMsg{headers: headers, peer: peer}
+}
+
 // DonePeer informs the blockmanager that a peer has disconnected.
 func (sm *SyncManager) DonePeer(peer *peer.Peer) {
 	// Ignore if we are shutting down.
@@ -896,6 +1253,10 @@ func (sm *SyncManager) Stop() error {
 	log.Infof("Sync manager shutting down")
 	close(sm.quit)
 	sm.wg.Wait()
+
+	if err := saveRelayFilter(sm.relayFilterPath, sm.relayedTxns); err != nil {
+		log.Warnf("Save relay filter failed: %v", err)
+	}
 	return nil
 }
 
@@ -938,8 +1299,11 @@ func New(config *Config) *SyncManager {
 		requestedBlocks:          make(map[common.Uint256]struct{}),
 		requestedConfirmedBlocks: make(map[common.Uint256]struct{}),
 		peerStates:               make(map[*peer.Peer]*peerSyncState),
+		headerList:               list.New(),
 		msgChan:                  make(chan interface{}, config.MaxPeers*3),
 		quit:                     make(chan struct{}),
+		relayedTxns:              newRelayFilter(config.RelayFilterPath),
+		relayFilterPath:          config.RelayFilterPath,
 	}
 
 	events.Subscribe(sm.handleBlockchainEvents)
@@ -0,0 +1,103 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package netsync
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"os"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/elanet/bloom"
+)
+
+const (
+	// maxRelayedTxns is the number of recently relayed transactions at least
+	// remembered by the relay filter.
+	maxRelayedTxns = 50000
+
+	// relayFilterFPRate is the false positive rate of the relay filter, a
+	// false positive transaction is neither requested nor relayed.
+	relayFilterFPRate = 1e-6
+
+	// maxRelayFilterAge is the max age of the persisted relay filter to be
+	// loaded on start, the mempools of peers have probably changed a lot
+	// after a longer restart.
+	maxRelayFilterAge = 10 * time.Minute
+)
+
+// newRelayFilter creates the filter of recently relayed transactions, and
+// loads the persisted one if it is not too old.
+func newRelayFilter(path string) *bloom.RollingFilter {
+	// The tweak is random so that false positives differ between nodes.
+	var tweak [4]byte
+	rand.Read(tweak[:])
+	filter := bloom.NewRollingFilter(maxRelayedTxns,
+		binary.LittleEndian.Uint32(tweak[:]), relayFilterFPRate)
+	if path == "" {
+		return filter
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warnf("Open relay filter failed: %v", err)
+		}
+		return filter
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	timestamp, err := common.ReadUint64(r)
+	if err != nil {
+		log.Warnf("Read relay filter failed: %v", err)
+		return filter
+	}
+	age := time.Since(time.Unix(int64(timestamp), 0))
+	if age > maxRelayFilterAge {
+		log.Infof("Ignore relay filter saved %s ago", age)
+		return filter
+	}
+	if err := filter.Deserialize(r); err != nil {
+		log.Warnf("Read relay filter failed: %v", err)
+		filter.Reset()
+		return filter
+	}
+	log.Infof("Loaded relay filter saved %s ago", age)
+	return filter
+}
+
+// saveRelayFilter persists the filter of recently relayed transactions, so
+// the node does not request and relay them again after a short restart.
+func saveRelayFilter(path string, filter *bloom.RollingFilter) error {
+	if path == "" {
+		return nil
+	}
+
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	err = common.WriteUint64(w, uint64(time.Now().Unix()))
+	if err == nil {
+		err = filter.Serialize(w)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -47,6 +47,10 @@ const (
 
 	// SFNodeBloom is a flag used to indicate a peer supports bloom filtering.
 	SFNodeBloom
+
+	// SFNodeHeaders is a flag used to indicate a peer supports the getheaders
+	// and headers messages.
+	SFNodeHeaders
 )
 
 // Map of service flags back to their constant names for pretty printing.
@@ -54,6 +58,7 @@ var sfStrings = map[ServiceFlag]string{
 	SFNodeNetwork: "SFNodeNetwork",
 	SFTxFiltering: "SFTxFiltering",
 	SFNodeBloom:   "SFNodeBloom",
+	SFNodeHeaders: "SFNodeHeaders",
 }
 
 // orderedSFStrings is an ordered list of service flags from highest to
@@ -62,6 +67,7 @@ var orderedSFStrings = []ServiceFlag{
 	SFNodeNetwork,
 	SFTxFiltering,
 	SFNodeBloom,
+	SFNodeHeaders,
 }
 
 // String returns the ServiceFlag in human-readable form.
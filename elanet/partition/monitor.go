@@ -0,0 +1,228 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package partition
+
+import (
+	"expvar"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultMaxBlocksBehind is the default number of blocks the local tip
+	// can fall behind peers before reporting.
+	defaultMaxBlocksBehind = 10
+
+	// defaultMinClusterPeers is the default minimum number of peers to form
+	// a cluster counted in split detection.
+	defaultMinClusterPeers = 2
+
+	// defaultCheckInterval is the default duration between checks.
+	defaultCheckInterval = time.Minute
+)
+
+// Gauges published by expvar, they are served under /debug/vars of the
+// profile server.
+var (
+	blocksBehindGauge   = expvar.NewInt("ela_partition_blocks_behind")
+	bestPeerHeightGauge = expvar.NewInt("ela_partition_best_peer_height")
+	clustersGauge       = expvar.NewInt("ela_partition_clusters")
+	splitGauge          = expvar.NewInt("ela_partition_split")
+)
+
+// Cluster is a group of peers with close heights.
+type Cluster struct {
+	MinHeight uint32 `json:"minheight"`
+	MaxHeight uint32 `json:"maxheight"`
+	Peers     int    `json:"peers"`
+}
+
+// State is the result of a partition check.
+type State struct {
+	// Behind indicates the local tip is more than MaxBlocksBehind blocks
+	// lower than the best peer height.
+	Behind bool `json:"behind"`
+
+	// Split indicates connected peers disagree across two or more clusters
+	// which have at least MinClusterPeers peers.
+	Split bool `json:"split"`
+
+	LocalHeight    uint32    `json:"localheight"`
+	BestPeerHeight uint32    `json:"bestpeerheight"`
+	BlocksBehind   uint32    `json:"blocksbehind"`
+	Peers          int       `json:"peers"`
+	Clusters       []Cluster `json:"clusters"`
+	CheckTime      int64     `json:"checktime"`
+}
+
+// Config defines the parameters to create a Monitor.
+type Config struct {
+	// MaxBlocksBehind is the number of blocks the local tip can fall behind
+	// peers, and the max height difference of peers in one cluster.
+	MaxBlocksBehind uint32
+
+	// MinClusterPeers is the minimum number of peers to form a cluster
+	// counted in split detection and best peer height.
+	MinClusterPeers int
+
+	// CheckInterval is the duration between checks.
+	CheckInterval time.Duration
+
+	// BestHeight returns the height of local tip.
+	BestHeight func() uint32
+
+	// PeerHeights returns the best known heights of connected peers.
+	PeerHeights func() []uint32
+}
+
+// Monitor checks the heights of connected peers periodically, and reports
+// when the local tip falls behind peers or peers split into clusters.
+type Monitor struct {
+	cfg  Config
+	quit chan struct{}
+
+	mtx   sync.RWMutex
+	state *State
+}
+
+// Start starts to check peers periodically.
+func (m *Monitor) Start() {
+	go m.checkHandler()
+}
+
+// Stop stops checking peers.
+func (m *Monitor) Stop() {
+	close(m.quit)
+}
+
+// State returns the result of the last check, nil if not checked yet.
+func (m *Monitor) State() *State {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	if m.state == nil {
+		return nil
+	}
+	state := *m.state
+	state.Clusters = append([]Cluster{}, m.state.Clusters...)
+	return &state
+}
+
+func (m *Monitor) checkHandler() {
+	ticker := time.NewTicker(m.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.check()
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+func (m *Monitor) check() {
+	state := Detect(m.cfg.BestHeight(), m.cfg.PeerHeights(),
+		m.cfg.MaxBlocksBehind, m.cfg.MinClusterPeers)
+	state.CheckTime = time.Now().Unix()
+
+	m.mtx.Lock()
+	last := m.state
+	m.state = state
+	m.mtx.Unlock()
+
+	blocksBehindGauge.Set(int64(state.BlocksBehind))
+	bestPeerHeightGauge.Set(int64(state.BestPeerHeight))
+	clustersGauge.Set(int64(len(state.Clusters)))
+	if state.Split {
+		splitGauge.Set(1)
+	} else {
+		splitGauge.Set(0)
+	}
+
+	wasBehind := last != nil && last.Behind
+	wasSplit := last != nil && last.Split
+	if state.Behind && !wasBehind {
+		log.Warnf("local height %d is %d blocks behind peers at %d",
+			state.LocalHeight, state.BlocksBehind, state.BestPeerHeight)
+	} else if !state.Behind && wasBehind {
+		log.Infof("local height %d caught up with peers",
+			state.LocalHeight)
+	}
+	if state.Split && !wasSplit {
+		log.Warnf("peers split into clusters %+v, network may be "+
+			"partitioned", state.Clusters)
+	} else if !state.Split && wasSplit {
+		log.Info("peers are no longer split")
+	}
+}
+
+// Detect groups peer heights into clusters and checks whether the local tip
+// is behind peers or peers split into clusters.  Heights of peers in the
+// same cluster differ by no more than maxBehind between neighbours.
+func Detect(localHeight uint32, peerHeights []uint32, maxBehind uint32,
+	minClusterPeers int) *State {
+	state := &State{
+		LocalHeight: localHeight,
+		Peers:       len(peerHeights),
+		Clusters:    make([]Cluster, 0),
+	}
+	if len(peerHeights) == 0 {
+		return state
+	}
+
+	heights := append([]uint32{}, peerHeights...)
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+
+	cluster := Cluster{MinHeight: heights[0], MaxHeight: heights[0], Peers: 1}
+	for _, h := range heights[1:] {
+		if h-cluster.MaxHeight > maxBehind {
+			state.Clusters = append(state.Clusters, cluster)
+			cluster = Cluster{MinHeight: h, MaxHeight: h}
+		}
+		cluster.MaxHeight = h
+		cluster.Peers++
+	}
+	state.Clusters = append(state.Clusters, cluster)
+
+	// Use the highest cluster with enough peers as the best peer height, so
+	// a single peer announcing a wrong height will not trigger alerts.
+	major := 0
+	for _, c := range state.Clusters {
+		if c.Peers < minClusterPeers {
+			continue
+		}
+		major++
+		state.BestPeerHeight = c.MaxHeight
+	}
+	if major == 0 {
+		state.BestPeerHeight = heights[len(heights)-1]
+	}
+	state.Split = major >= 2
+
+	if state.BestPeerHeight > localHeight {
+		state.BlocksBehind = state.BestPeerHeight - localHeight
+	}
+	state.Behind = state.BlocksBehind > maxBehind
+	return state
+}
+
+// New creates a Monitor with the given configuration, zero values of
+// MaxBlocksBehind, MinClusterPeers and CheckInterval are replaced by
+// defaults.
+func New(cfg *Config) *Monitor {
+	m := &Monitor{cfg: *cfg, quit: make(chan struct{})}
+	if m.cfg.MaxBlocksBehind == 0 {
+		m.cfg.MaxBlocksBehind = defaultMaxBlocksBehind
+	}
+	if m.cfg.MinClusterPeers == 0 {
+		m.cfg.MinClusterPeers = defaultMinClusterPeers
+	}
+	if m.cfg.CheckInterval == 0 {
+		m.cfg.CheckInterval = defaultCheckInterval
+	}
+	return m
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package partition
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	// No peers.
+	state := Detect(100, nil, 10, 2)
+	assert.False(t, state.Behind)
+	assert.False(t, state.Split)
+	assert.Equal(t, 0, len(state.Clusters))
+
+	// Peers agree with local tip.
+	state = Detect(100, []uint32{100, 99, 101, 100}, 10, 2)
+	assert.False(t, state.Behind)
+	assert.False(t, state.Split)
+	assert.Equal(t, []Cluster{{MinHeight: 99, MaxHeight: 101, Peers: 4}},
+		state.Clusters)
+	assert.Equal(t, uint32(101), state.BestPeerHeight)
+	assert.Equal(t, uint32(1), state.BlocksBehind)
+
+	// Local tip falls behind peers.
+	state = Detect(100, []uint32{120, 121, 122}, 10, 2)
+	assert.True(t, state.Behind)
+	assert.False(t, state.Split)
+	assert.Equal(t, uint32(22), state.BlocksBehind)
+
+	// A single peer announcing a wrong height should be ignored.
+	state = Detect(100, []uint32{100, 101, 5000}, 10, 2)
+	assert.False(t, state.Behind)
+	assert.False(t, state.Split)
+	assert.Equal(t, 2, len(state.Clusters))
+	assert.Equal(t, uint32(101), state.BestPeerHeight)
+
+	// Peers split into two clusters.
+	state = Detect(100, []uint32{100, 101, 150, 152, 151}, 10, 2)
+	assert.True(t, state.Split)
+	assert.True(t, state.Behind)
+	assert.Equal(t, []Cluster{
+		{MinHeight: 100, MaxHeight: 101, Peers: 2},
+		{MinHeight: 150, MaxHeight: 152, Peers: 3},
+	}, state.Clusters)
+	assert.Equal(t, uint32(152), state.BestPeerHeight)
+}
+
+func TestMonitor_State(t *testing.T) {
+	heights := []uint32{100, 101}
+	m := New(&Config{
+		BestHeight:  func() uint32 { return 100 },
+		PeerHeights: func() []uint32 { return heights },
+	})
+	assert.Nil(t, m.State())
+
+	m.check()
+	state := m.State()
+	assert.False(t, state.Behind)
+
+	heights = []uint32{200, 201}
+	m.check()
+	assert.True(t, m.State().Behind)
+
+	// Returned state should not be affected by later checks.
+	assert.False(t, state.Behind)
+}
@@ -78,6 +78,13 @@ type Listeners struct {
 	// message.
 	OnGetBlocks func(p *Peer, msg *msg.GetBlocks)
 
+	// OnGetHeaders is invoked when a peer receives a getheaders
+	// message.
+	OnGetHeaders func(p *Peer, msg *msg.GetHeaders)
+
+	// OnHeaders is invoked when a peer receives a headers message.
+	OnHeaders func(p *Peer, msg *msg.Headers)
+
 	// OnFilterAdd is invoked when a peer receives a filteradd message.
 	OnFilterAdd func(p *Peer, msg *msg.FilterAdd)
 
@@ -110,6 +117,9 @@ type Peer struct {
 	prevGetBlocksMtx   sync.Mutex
 	prevGetBlocksBegin *common.Uint256
 	prevGetBlocksStop  *common.Uint256
+	prevGetHdrsMtx     sync.Mutex
+	prevGetHdrsBegin   *common.Uint256
+	prevGetHdrsStop    *common.Uint256
 
 	stallControl  chan peer.StallControlMsg
 	outputInvChan chan *msg.InvVect
@@ -188,6 +198,44 @@ func (p *Peer) PushGetBlocksMsg(locator []*common.Uint256, stopHash *common.Uint
 	return nil
 }
 
+// PushGetHeadersMsg sends a getheaders message for the provided block locator
+// and stop hash.  It will ignore back-to-back duplicate requests.
+//
+// This function is safe for concurrent access.
+func (p *Peer) PushGetHeadersMsg(locator []*common.Uint256, stopHash *common.Uint256) error {
+	// Extract the begin hash from the block locator, if one was specified,
+	// to use for filtering duplicate getheaders requests.
+	var beginHash *common.Uint256
+	if len(locator) > 0 {
+		beginHash = locator[0]
+	}
+
+	// Filter duplicate getheaders requests.
+	p.prevGetHdrsMtx.Lock()
+	isDuplicate := p.prevGetHdrsStop != nil && p.prevGetHdrsBegin != nil &&
+		beginHash != nil && stopHash.IsEqual(*p.prevGetHdrsStop) &&
+		beginHash.IsEqual(*p.prevGetHdrsBegin)
+	p.prevGetHdrsMtx.Unlock()
+
+	if isDuplicate {
+		log.Debugf("Filtering duplicate [getheaders] with begin hash %v",
+			beginHash)
+		return nil
+	}
+
+	// Construct the getheaders request and queue it to be sent.
+	msg := msg.NewGetHeaders(locator, *stopHash)
+	p.QueueMessage(msg, nil)
+
+	// Update the previous getheaders request information for filtering
+	// duplicates.
+	p.prevGetHdrsMtx.Lock()
+	p.prevGetHdrsBegin = beginHash
+	p.prevGetHdrsStop = stopHash
+	p.prevGetHdrsMtx.Unlock()
+	return nil
+}
+
 // PushRejectMsg sends a reject message for the provided command, reject code,
 // reject reason, and hash.  The hash will only be used when the command is a tx
 // or block and should be nil in other cases.  The wait parameter will cause the
@@ -241,6 +289,13 @@ func (p *Peer) maybeAddDeadline(pendingResponses map[string]time.Time, msgCmd st
 		// Expects an inv message.
 		pendingResponses[p2p.CmdInv] = deadline
 
+	case p2p.CmdGetHeaders:
+		// Expects a headers message.  Use a longer deadline since it
+		// can take a while for the remote peer to load all of the
+		// headers.
+		deadline = time.Now().Add(stallResponseTimeout * 3)
+		pendingResponses[p2p.CmdHeaders] = deadline
+
 	case p2p.CmdGetData:
 		// Expects all block, merkleblock, tx, notfound or daddr message.
 		pendingResponses[p2p.CmdBlock] = deadline
@@ -534,6 +589,12 @@ func New(orgPeer server.IPeer, listeners *Listeners) *Peer {
 		case *msg.GetBlocks:
 			listeners.OnGetBlocks(p, m)
 
+		case *msg.GetHeaders:
+			listeners.OnGetHeaders(p, m)
+
+		case *msg.Headers:
+			listeners.OnHeaders(p, m)
+
 		case *msg.FilterAdd:
 			listeners.OnFilterAdd(p, m)
 
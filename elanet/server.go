@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"sync/atomic"
 	"time"
 
@@ -33,10 +34,15 @@ import (
 const (
 	// defaultServices describes the default services that are supported by
 	// the server.
-	defaultServices = pact.SFNodeNetwork | pact.SFTxFiltering | pact.SFNodeBloom
+	defaultServices = pact.SFNodeNetwork | pact.SFTxFiltering |
+		pact.SFNodeBloom | pact.SFNodeHeaders
 
 	// maxNonNodePeers defines the maximum count of accepting non-node peers.
 	maxNonNodePeers = 100
+
+	// relayFilterFile is the file name in the data dir to persist recently
+	// relayed transactions.
+	relayFilterFile = "relayfilter.dat"
 )
 
 // naFilter defines a network address filter for the main chain server, for now
@@ -419,6 +425,40 @@ func (sp *serverPeer) OnGetBlocks(_ *peer.Peer, m *msg.GetBlocks) {
 	}
 }
 
+// OnGetHeaders is invoked when a peer receives a getheaders
+// message.
+func (sp *serverPeer) OnGetHeaders(_ *peer.Peer, m *msg.GetHeaders) {
+	// Ignore getheaders requests if not in sync.
+	if !sp.server.syncManager.IsCurrent() {
+		return
+	}
+
+	// Find the most recent known block in the best chain based on the block
+	// locator and fetch all of the headers after it until either
+	// msg.MaxBlockHeadersPerMsg have been fetched or the provided stop
+	// hash is encountered.
+	//
+	// Use the block after the genesis block if no other blocks in the
+	// provided locator are known.  This does mean the client will start
+	// over with the genesis block if unknown block locators are provided.
+	chain := sp.server.chain
+	headers := chain.LocateHeaders(m.Locator, &m.HashStop,
+		msg.MaxBlockHeadersPerMsg)
+
+	// Send found headers to the requesting peer.
+	headersMsg := msg.NewHeaders(nil)
+	for _, header := range headers {
+		headersMsg.AddHeader(header)
+	}
+	sp.QueueMessage(headersMsg, nil)
+}
+
+// OnHeaders is invoked when a peer receives a headers message.  The
+// message is passed down to the sync manager.
+func (sp *serverPeer) OnHeaders(_ *peer.Peer, m *msg.Headers) {
+	sp.server.syncManager.QueueHeaders(m, sp.Peer)
+}
+
 // enforceTxFilterFlag disconnects the peer if the server is not configured to
 // allow tx filters.  Additionally, if the peer has negotiated to a protocol
 // version  that is high enough to observe the bloom filter service support bit,
@@ -866,6 +906,8 @@ func (s *server) handlePeerMsg(peers map[svr.IPeer]*serverPeer, p interface{}) {
 			OnNotFound:     sp.OnNotFound,
 			OnGetData:      sp.OnGetData,
 			OnGetBlocks:    sp.OnGetBlocks,
+			OnGetHeaders:   sp.OnGetHeaders,
+			OnHeaders:      sp.OnHeaders,
 			OnFilterAdd:    sp.OnFilterAdd,
 			OnFilterClear:  sp.OnFilterClear,
 			OnFilterLoad:   sp.OnFilterLoad,
@@ -965,8 +1007,21 @@ func NewServer(dataDir string, cfg *Config) (*server, error) {
 		func() uint64 { return uint64(cfg.Chain.GetHeight()) },
 	)
 	svrCfg.DataDir = dataDir
+	if params.MaxPeers > 0 {
+		svrCfg.MaxPeers = params.MaxPeers
+	}
 	svrCfg.NAFilter = &naFilter{}
+	svrCfg.Upnp = params.NAT.UPnP
+	svrCfg.NATPMP = params.NAT.NATPMP
+	svrCfg.NATGateway = params.NAT.Gateway
 	svrCfg.PermanentPeers = cfg.PermanentPeers
+	if len(params.PeerAllowList) > 0 {
+		allowList, err := svr.ParseIPNets(params.PeerAllowList)
+		if err != nil {
+			return nil, err
+		}
+		svrCfg.AllowList = allowList
+	}
 
 	s := server{
 		chain:        cfg.Chain,
@@ -989,12 +1044,13 @@ func NewServer(dataDir string, cfg *Config) (*server, error) {
 	s.IServer = p2pServer
 
 	s.syncManager = netsync.New(&netsync.Config{
-		PeerNotifier: &s,
-		Chain:        cfg.Chain,
-		ChainParams:  cfg.ChainParams,
-		TxMemPool:    cfg.TxMemPool,
-		BlockMemPool: cfg.BlockMemPool,
-		MaxPeers:     svrCfg.MaxPeers,
+		PeerNotifier:    &s,
+		Chain:           cfg.Chain,
+		ChainParams:     cfg.ChainParams,
+		TxMemPool:       cfg.TxMemPool,
+		BlockMemPool:    cfg.BlockMemPool,
+		MaxPeers:        svrCfg.MaxPeers,
+		RelayFilterPath: filepath.Join(dataDir, relayFilterFile),
 	})
 
 	return &s, nil
@@ -1024,6 +1080,14 @@ func makeEmptyMessage(cmd string) (p2p.Message, error) {
 	case p2p.CmdGetBlocks:
 		message = &msg.GetBlocks{}
 
+	case p2p.CmdGetHeaders:
+		message = &msg.GetHeaders{}
+
+	case p2p.CmdHeaders:
+		message = msg.NewHeaders(func() common.Serializable {
+			return &types.Header{}
+		})
+
 	case p2p.CmdFilterAdd:
 		message = &msg.FilterAdd{}
 
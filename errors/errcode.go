@@ -32,6 +32,10 @@ const (
 	ErrTransactionPoolSize      ErrCode = 45024
 	ErrCRProcessing             ErrCode = 45025
 	ErrTransactionHeightVersion ErrCode = 45026
+	ErrTransactionNonStandard   ErrCode = 45027
+	ErrTransactionHighFee       ErrCode = 45028
+	ErrCRRegistrationClosed     ErrCode = 45029
+	ErrSpecialTxConflict        ErrCode = 45030
 
 	SessionExpired       ErrCode = 41001
 	IllegalDataFormat    ErrCode = 41003
@@ -70,6 +74,10 @@ var ErrMap = map[ErrCode]string{
 	ErrTransactionPoolSize:      "Error transactions size of transaction pool",
 	ErrCRProcessing:             "Error CR processing",
 	ErrTransactionHeightVersion: "Error height version of transaction",
+	ErrTransactionNonStandard:   "Error non-standard transaction",
+	ErrTransactionHighFee:       "Error absurdly high transaction fee",
+	ErrCRRegistrationClosed:     "Error CR registration closed",
+	ErrSpecialTxConflict:        "Error special transaction conflict",
 	ErrInvalidInput:             "INTERNAL ERROR, ErrInvalidInput",
 	ErrInvalidOutput:            "INTERNAL ERROR, ErrInvalidOutput",
 	ErrAssetPrecision:           "INTERNAL ERROR, ErrAssetPrecision",
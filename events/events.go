@@ -52,6 +52,14 @@ const (
 
 	// ETIllegalEvidence indicates a illegal block received.
 	ETIllegalBlockEvidence
+
+	// ETPipelinedBlockReceived indicates a new block was received on top of
+	// a block which is still waiting for confirm.
+	ETPipelinedBlockReceived
+
+	// ETUnderstaffedModeChanged indicates the DPoS arbiters entered or left
+	// the understaffed mode, the data is the understaffed period.
+	ETUnderstaffedModeChanged
 )
 
 // notificationTypeStrings is a map of notification types back to their constant
@@ -64,6 +72,9 @@ var notificationTypeStrings = map[EventType]string{
 	ETNewBlockReceived:    "ETNewBlockReceived",
 	ETConfirmAccepted:     "ETConfirmAccepted",
 	ETDirectPeersChanged:  "ETDirectPeersChanged",
+
+	ETPipelinedBlockReceived:  "ETPipelinedBlockReceived",
+	ETUnderstaffedModeChanged: "ETUnderstaffedModeChanged",
 }
 
 // String returns the EventType in human-readable form.
@@ -13,13 +13,17 @@ import (
 
 	"github.com/elastos/Elastos.ELA/common/log"
 	crstate "github.com/elastos/Elastos.ELA/cr/state"
+	"github.com/elastos/Elastos.ELA/dpos/alert"
 	"github.com/elastos/Elastos.ELA/dpos/state"
 	"github.com/elastos/Elastos.ELA/elanet"
 	"github.com/elastos/Elastos.ELA/elanet/netsync"
+	"github.com/elastos/Elastos.ELA/elanet/partition"
 	"github.com/elastos/Elastos.ELA/elanet/peer"
 	"github.com/elastos/Elastos.ELA/elanet/routes"
 	"github.com/elastos/Elastos.ELA/p2p/addrmgr"
 	"github.com/elastos/Elastos.ELA/p2p/connmgr"
+	"github.com/elastos/Elastos.ELA/p2p/nat"
+	"github.com/elastos/Elastos.ELA/plugin"
 	"github.com/elastos/Elastos.ELA/utils/elalog"
 
 	"github.com/urfave/cli"
@@ -166,6 +170,9 @@ func newProgress(w io.Writer) *progress {
 var (
 	logger *log.Logger
 	pgBar  *progress
+
+	// levelLoggers holds the sub loggers following the PrintLevel setting.
+	levelLoggers []*logWrapper
 )
 
 // The default amount of logging is none.
@@ -185,6 +192,12 @@ func setupLog(c *cli.Context, s *settings) {
 	elanlog := wrap(logger, s.Config().PrintLevel)
 	statlog := wrap(logger, s.Config().PrintLevel)
 	crstatlog := wrap(logger, s.Config().PrintLevel)
+	alertlog := wrap(logger, s.Config().PrintLevel)
+	partlog := wrap(logger, s.Config().PrintLevel)
+	pluglog := wrap(logger, s.Config().PrintLevel)
+	natlog := wrap(logger, s.Config().PrintLevel)
+	levelLoggers = []*logWrapper{synclog, peerlog, routlog, elanlog, statlog,
+		crstatlog, alertlog, partlog, pluglog, natlog}
 
 	addrmgr.UseLogger(admrlog)
 	connmgr.UseLogger(cmgrlog)
@@ -194,4 +207,16 @@ func setupLog(c *cli.Context, s *settings) {
 	elanet.UseLogger(elanlog)
 	state.UseLogger(statlog)
 	crstate.UseLogger(crstatlog)
+	alert.UseLogger(alertlog)
+	partition.UseLogger(partlog)
+	plugin.UseLogger(pluglog)
+	nat.UseLogger(natlog)
+}
+
+// setLogLevel changes the print level of node logger and sub loggers.
+func setLogLevel(level elalog.Level) {
+	log.SetPrintLevel(uint8(level))
+	for _, l := range levelLoggers {
+		l.SetLevel(level)
+	}
 }
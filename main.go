@@ -15,23 +15,31 @@ import (
 	"strconv"
 	"time"
 
+	elaaccount "github.com/elastos/Elastos.ELA/account"
 	"github.com/elastos/Elastos.ELA/blockchain"
 	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
 	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/common/log"
+	"github.com/elastos/Elastos.ELA/core/electionhistory"
+	"github.com/elastos/Elastos.ELA/core/rankhistory"
+	"github.com/elastos/Elastos.ELA/core/roundreward"
 	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/cr/draft"
 	crstate "github.com/elastos/Elastos.ELA/cr/state"
 	"github.com/elastos/Elastos.ELA/dpos"
 	"github.com/elastos/Elastos.ELA/dpos/account"
+	"github.com/elastos/Elastos.ELA/dpos/alert"
 	dlog "github.com/elastos/Elastos.ELA/dpos/log"
 	"github.com/elastos/Elastos.ELA/dpos/state"
 	"github.com/elastos/Elastos.ELA/dpos/store"
 	"github.com/elastos/Elastos.ELA/elanet"
+	"github.com/elastos/Elastos.ELA/elanet/partition"
 	"github.com/elastos/Elastos.ELA/elanet/routes"
 	"github.com/elastos/Elastos.ELA/mempool"
 	"github.com/elastos/Elastos.ELA/p2p"
 	"github.com/elastos/Elastos.ELA/p2p/msg"
+	"github.com/elastos/Elastos.ELA/plugin"
 	"github.com/elastos/Elastos.ELA/pow"
 	"github.com/elastos/Elastos.ELA/servers"
 	"github.com/elastos/Elastos.ELA/servers/httpjsonrpc"
@@ -41,6 +49,7 @@ import (
 	"github.com/elastos/Elastos.ELA/utils"
 	"github.com/elastos/Elastos.ELA/utils/elalog"
 	"github.com/elastos/Elastos.ELA/utils/signal"
+	"github.com/elastos/Elastos.ELA/utils/tracing"
 	"github.com/elastos/Elastos.ELA/wallet"
 
 	"github.com/urfave/cli"
@@ -80,6 +89,7 @@ func setupNode() *cli.App {
 		cmdcom.AccountPasswordFlag,
 		cmdcom.TestNetFlag,
 		cmdcom.RegTestFlag,
+		cmdcom.ChainParamsFlag,
 		cmdcom.InfoPortFlag,
 		cmdcom.RestPortFlag,
 		cmdcom.WsPortFlag,
@@ -123,11 +133,26 @@ func startNode(c *cli.Context, st *settings) {
 
 	var act account.Account
 	if st.Config().DPoSConfiguration.EnableArbiter {
-		password, err := cmdcom.GetFlagPassword(c)
+		var err error
+		if signer := st.Config().DPoSConfiguration.RemoteSigner; signer.Enable {
+			act, err = openRemoteSigner(&signer)
+		} else {
+			var password []byte
+			password, err = cmdcom.GetFlagPassword(c)
+			if err != nil {
+				printErrorAndExit(err)
+			}
+			act, err = account.Open(password)
+		}
 		if err != nil {
 			printErrorAndExit(err)
 		}
-		act, err = account.Open(password)
+	}
+
+	var identity account.Account
+	if idCfg := st.Config().NodeIdentity; idCfg.Enable {
+		var err error
+		identity, err = openNodeIdentity(c, &idCfg, act)
 		if err != nil {
 			printErrorAndExit(err)
 		}
@@ -136,6 +161,19 @@ func startNode(c *cli.Context, st *settings) {
 	log.Infof("Node version: %s", Version)
 	log.Info(GoVersion)
 
+	if traceCfg := st.Config().Tracing; traceCfg.Enable {
+		shutdown, err := tracing.Init(&tracing.Config{
+			Exporter:    traceCfg.Exporter,
+			Endpoint:    traceCfg.Endpoint,
+			Insecure:    traceCfg.Insecure,
+			SampleRatio: traceCfg.SampleRatio,
+		})
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		defer shutdown()
+	}
+
 	var interrupt = signal.NewInterrupt()
 
 	// fixme remove singleton Ledger
@@ -143,7 +181,8 @@ func startNode(c *cli.Context, st *settings) {
 
 	// Initializes the foundation address
 	blockchain.FoundationAddress = st.Params().Foundation
-	chainStore, err := blockchain.NewChainStore(dataDir, st.Params().GenesisBlock)
+	chainStore, err := blockchain.NewChainStore(dataDir, st.Params().DBEngine,
+		st.Params().GenesisBlock)
 	if err != nil {
 		printErrorAndExit(err)
 	}
@@ -180,9 +219,13 @@ func startNode(c *cli.Context, st *settings) {
 	if err != nil {
 		printErrorAndExit(err)
 	}
+	arbiters.State.SetProducerHistoryIndex(
+		chainStore.NewHistoryIndex(blockchain.IXProducerHistory))
 	ledger.Arbitrators = arbiters // fixme
 
 	committee := crstate.NewCommittee(st.Params())
+	committee.GetState().SetCandidateHistoryIndex(
+		chainStore.NewHistoryIndex(blockchain.IXCandidateHistory))
 	ledger.Committee = committee
 
 	chain, err := blockchain.New(chainStore, st.Params(), arbiters.State,
@@ -195,6 +238,12 @@ func startNode(c *cli.Context, st *settings) {
 		printErrorAndExit(err)
 	}
 	pgBar.Stop()
+	if height := st.Config().HaltHeight; height > 0 {
+		chain.SetHaltHeight(height)
+	}
+	if st.Config().CheckInvariants {
+		chain.EnableInvariantChecks()
+	}
 	ledger.Blockchain = chain // fixme
 	blockMemPool.Chain = chain
 	arbiters.RegisterFunction(chain.GetHeight,
@@ -278,6 +327,9 @@ func startNode(c *cli.Context, st *settings) {
 	servers.Server = server
 	servers.Arbiters = arbiters
 	servers.Wallet = wal
+	servers.ConfigReloader = func() error {
+		return reloadConfig(st, server, txMemPool)
+	}
 	servers.Pow = pow.NewService(&pow.Config{
 		PayToAddr:   st.Config().PowConfiguration.PayToAddr,
 		MinerInfo:   st.Config().PowConfiguration.MinerInfo,
@@ -299,12 +351,108 @@ func startNode(c *cli.Context, st *settings) {
 	}
 	pgBar.Stop()
 
+	// Start plugins compiled in by build tags before blocks are connected.
+	if err := plugin.Start(&plugin.Context{
+		Chain:     chain,
+		TxMemPool: txMemPool,
+		Params:    st.Params(),
+		DataDir:   filepath.Join(dataDir, pluginsPath),
+	}); err != nil {
+		printErrorAndExit(err)
+	}
+	defer plugin.Stop()
+
 	log.Info("Start the P2P networks")
 	server.Start()
 	defer server.Stop()
 
+	partitionCfg := st.Config().PartitionMonitor
+	partitionMonitor := partition.New(&partition.Config{
+		MaxBlocksBehind: partitionCfg.MaxBlocksBehind,
+		MinClusterPeers: partitionCfg.MinClusterPeers,
+		CheckInterval: time.Duration(partitionCfg.CheckInterval) *
+			time.Second,
+		BestHeight: chain.GetHeight,
+		PeerHeights: func() []uint32 {
+			peers := server.ConnectedPeers()
+			heights := make([]uint32, 0, len(peers))
+			for _, p := range peers {
+				heights = append(heights, p.ToPeer().Height())
+			}
+			return heights
+		},
+	})
+	servers.Partition = partitionMonitor
+	servers.NodeIdentity = identity
+	partitionMonitor.Start()
+	defer partitionMonitor.Stop()
+
+	if draftCfg := st.Config().DraftData; draftCfg.Enable {
+		draftStore, err := draft.New(filepath.Join(dataDir, draftPath),
+			draftCfg.MaxSize)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		defer draftStore.Close()
+		servers.DraftStore = draftStore
+	}
+
+	if rankCfg := st.Config().RankHistory; rankCfg.Enable {
+		rankStore, err := newRankHistory(
+			filepath.Join(dataDir, rankHistoryPath), rankCfg.Interval,
+			arbiters.State, committee.GetState())
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		rankStore.Start()
+		defer rankStore.Stop()
+		servers.RankHistory = rankStore
+	}
+
+	if st.Config().RoundReward.Enable {
+		rewardStore, err := roundreward.New(&roundreward.Config{
+			Path: filepath.Join(dataDir, roundRewardPath),
+			RoundReward: func() (map[common.Uint168]common.Fixed64,
+				common.Fixed64) {
+				return arbiters.GetArbitersRoundReward(),
+					arbiters.GetFinalRoundChange()
+			},
+		})
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		rewardStore.Start()
+		defer rewardStore.Stop()
+		servers.RoundReward = rewardStore
+	}
+
+	if st.Config().ElectionHistory.Enable {
+		electionStore, err := electionhistory.New(&electionhistory.Config{
+			Path:   filepath.Join(dataDir, electionHistoryPath),
+			Listen: arbiters.SetElectionListener,
+		})
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		electionStore.Start()
+		defer electionStore.Stop()
+		servers.ElectionHistory = electionStore
+	}
+
+	// Reload non-consensus settings on SIGHUP.
+	signal.NewReload(func() {
+		if err := reloadConfig(st, server, txMemPool); err != nil {
+			log.Error("reload config failed, ", err)
+		}
+	})
+
 	log.Info("Start services")
 	if st.Config().EnableRPC {
+		auditCfg := st.Config().RpcConfiguration.AuditLog
+		if auditCfg.Enable {
+			httpjsonrpc.EnableAuditLog(filepath.Join(flagDataDir,
+				rpcAuditLogPath), auditCfg.MaxPerLogSize, auditCfg.MaxLogsSize)
+		}
 		go httpjsonrpc.StartRPCServer()
 	}
 	if st.Config().HttpRestStart {
@@ -317,6 +465,17 @@ func startNode(c *cli.Context, st *settings) {
 		go httpnodeinfo.StartServer()
 	}
 
+	if st.Config().DPoSConfiguration.ProducerAlert.Enable {
+		monitor, err := newProducerAlert(
+			&st.Config().DPoSConfiguration.ProducerAlert, arbiters.State,
+			arbiters.GetArbitersCount, server)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		monitor.Start()
+		defer monitor.Stop()
+	}
+
 	go printSyncState(chain, server)
 
 	waitForSyncFinish(server, interrupt.C)
@@ -337,6 +496,140 @@ func printErrorAndExit(err error) {
 	os.Exit(-1)
 }
 
+// openNodeIdentity opens the key to sign the getnodestate attestations, the
+// arbiter account is reused if no keystore is specified.
+func openNodeIdentity(c *cli.Context, cfg *config.NodeIdentity,
+	arbiter account.Account) (account.Account, error) {
+	if cfg.Keystore == "" && arbiter != nil {
+		return arbiter, nil
+	}
+	path := cfg.Keystore
+	if path == "" {
+		path = elaaccount.KeystoreFileName
+	}
+	password, err := cmdcom.GetFlagPassword(c)
+	if err != nil {
+		return nil, err
+	}
+	client, err := elaaccount.Open(path, password)
+	if err != nil {
+		return nil, err
+	}
+	return account.New(client.GetMainAccount()), nil
+}
+
+func openRemoteSigner(cfg *config.RemoteSigner) (account.Account, error) {
+	publicKey, err := common.HexStringToBytes(cfg.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := account.NewRemote(account.RemoteSignerConfig{
+		Sockets:   cfg.Sockets,
+		PublicKey: publicKey,
+		AuthToken: []byte(cfg.AuthToken),
+		Timeout:   cfg.Timeout * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+	go printSignerMetrics(signer)
+	return signer, nil
+}
+
+func printSignerMetrics(signer *account.RemoteAccount) {
+	statlog := elalog.NewBackend(logger.Writer()).Logger("SIGN",
+		elalog.LevelInfo)
+
+	ticker := time.NewTicker(printStateInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, m := range signer.Metrics() {
+			var avg time.Duration
+			if m.Requests > 0 {
+				avg = m.TotalLatency / time.Duration(m.Requests)
+			}
+			statlog.Infof("%s requests %d failures %d avg latency %s "+
+				"max latency %s %s", m.Socket, m.Requests, m.Failures, avg,
+				m.MaxLatency, m.LastError)
+		}
+	}
+}
+
+func newProducerAlert(cfg *config.ProducerAlert, dposState *state.State,
+	arbitersCount func() int, server elanet.Server) (*alert.Monitor, error) {
+	publicKeys := make([][]byte, 0, len(cfg.PublicKeys))
+	for _, pk := range cfg.PublicKeys {
+		publicKey, err := common.HexStringToBytes(pk)
+		if err != nil {
+			return nil, err
+		}
+		publicKeys = append(publicKeys, publicKey)
+	}
+
+	hooks := make([]alert.Hook, 0, len(cfg.WebHooks)+len(cfg.Commands))
+	for _, url := range cfg.WebHooks {
+		hooks = append(hooks, alert.NewWebHook(url))
+	}
+	for _, command := range cfg.Commands {
+		hook, err := alert.NewCommandHook(command)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+
+	return alert.New(&alert.Config{
+		PublicKeys:     publicKeys,
+		MaxMissedSlots: cfg.MissedSlots,
+		Hooks:          hooks,
+		GetProducerStatus: func(publicKey []byte) (*alert.ProducerStatus,
+			bool) {
+			producer := dposState.GetProducer(publicKey)
+			if producer == nil {
+				return nil, false
+			}
+			return &alert.ProducerStatus{
+				State:                  producer.State(),
+				InactiveCountingHeight: producer.InactiveCountingHeight(),
+			}, true
+		},
+		GetArbitersCount: arbitersCount,
+		IsCurrent:        server.IsCurrent,
+	}), nil
+}
+
+func newRankHistory(path string, interval uint32, dposState *state.State,
+	crState *crstate.State) (*rankhistory.Store, error) {
+	return rankhistory.New(&rankhistory.Config{
+		Path:     path,
+		Interval: interval,
+		Producers: func() []rankhistory.Item {
+			producers := dposState.GetActiveProducers()
+			items := make([]rankhistory.Item, 0, len(producers))
+			for _, p := range producers {
+				items = append(items, rankhistory.Item{
+					Key:   p.OwnerPublicKey(),
+					Votes: p.Votes(),
+				})
+			}
+			return items
+		},
+		Candidates: func() []rankhistory.Item {
+			candidates := crState.GetCandidates(crstate.Active)
+			items := make([]rankhistory.Item, 0, len(candidates))
+			for _, c := range candidates {
+				cid := c.Info().CID
+				items = append(items, rankhistory.Item{
+					Key:   cid.Bytes(),
+					Votes: c.Votes(),
+				})
+			}
+			return items
+		},
+	})
+}
+
 func waitForSyncFinish(server elanet.Server, interrupt <-chan struct{}) {
 	ticker := time.NewTicker(time.Second * 5)
 	defer ticker.Stop()
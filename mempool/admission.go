@@ -0,0 +1,155 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package mempool
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/elastos/Elastos.ELA/blockchain"
+	. "github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/log"
+	. "github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	. "github.com/elastos/Elastos.ELA/errors"
+)
+
+// The admission stages of the transaction pool in order. The stages before
+// stageSanity are cheap static checks against the transaction and the pool,
+// they run before the UTXO lookups and the signature verification so that a
+// flood of invalid transactions is rejected at the cost of a few map lookups.
+const (
+	stageDuplicate = "duplicate"
+	stageCoinbase  = "coinbase"
+	stageStandard  = "standard"
+	stageFeeFloor  = "feefloor"
+	stageConflict  = "conflict"
+	stageSanity    = "sanity"
+	stageContext   = "context"
+	stageTxPool    = "txpool"
+	stagePoolSize  = "poolsize"
+)
+
+// AcceptanceChecks lists the checks performed in order by the transaction pool
+// before accepting a transaction.
+var AcceptanceChecks = []string{stageDuplicate, stageCoinbase, stageStandard,
+	stageFeeFloor, stageConflict, stageSanity, stageContext, stageTxPool,
+	stagePoolSize}
+
+// checkFeeFloor checks if the transaction is able to pay the minimum fee. The
+// fee is paid by the referenced outputs, so a transaction without inputs pays
+// nothing unless it's fee free. If all the referenced outputs belong to the
+// transactions in pool, the fee is checked exactly without UTXO lookups.
+func (mp *TxPool) checkFeeFloor(tx *Transaction) error {
+	minFee := mp.chainParams.MinTransactionFee
+	if minFee <= 0 || blockchain.IsFeeFree(tx) {
+		return nil
+	}
+	if len(tx.Inputs) == 0 {
+		return errors.New("transaction has no inputs to pay the fee")
+	}
+
+	var inputValue Fixed64
+	for _, input := range tx.Inputs {
+		prev, ok := mp.txnList[input.Previous.TxID]
+		if !ok || int(input.Previous.Index) >= len(prev.Outputs) {
+			// Leave the outputs not in pool to the context check.
+			return nil
+		}
+		inputValue += prev.Outputs[input.Previous.Index].Value
+	}
+	var outputValue Fixed64
+	for _, output := range tx.Outputs {
+		outputValue += output.Value
+	}
+	if inputValue < minFee+outputValue {
+		return fmt.Errorf("transaction fee %s is lower than %s",
+			inputValue-outputValue, minFee)
+	}
+	return nil
+}
+
+// checkSpecialConflict checks if the special payload of the transaction
+// conflicts with a transaction in pool. Nothing is occupied by the check, the
+// conflicts are checked again by verifyTransactionWithTxnPool.
+func (mp *TxPool) checkSpecialConflict(tx *Transaction) ErrCode {
+	if slot, ok := conflictSlot(tx); ok {
+		if _, ok := mp.conflictSlots[slot]; ok {
+			return ErrSpecialTxConflict
+		}
+	}
+
+	switch tx.TxType {
+	case IllegalProposalEvidence, IllegalVoteEvidence, IllegalBlockEvidence,
+		IllegalSidechainEvidence, InactiveArbitrators:
+		illegalData, ok := tx.Payload.(payload.DPOSIllegalData)
+		if !ok {
+			return ErrProducerProcessing
+		}
+		if _, ok := mp.specialTxList[illegalData.Hash()]; ok {
+			return ErrProducerProcessing
+		}
+	case CRNicknameCommit:
+		p, ok := tx.Payload.(*payload.CRNicknameCommit)
+		if !ok {
+			return ErrCRProcessing
+		}
+		if _, ok := mp.specialTxList[p.Commitment]; ok {
+			return ErrCRProcessing
+		}
+	}
+	return Success
+}
+
+// prefilter runs the cheap admission stages, returns the stage rejecting the
+// transaction and the error code.
+func (mp *TxPool) prefilter(tx *Transaction) (string, ErrCode) {
+	// Don't accept the transaction if it already exists in the pool.  This
+	// applies to orphan transactions as well.  This check is intended to
+	// be a quick check to weed out duplicates.
+	if _, ok := mp.txnList[tx.Hash()]; ok {
+		return stageDuplicate, ErrTransactionDuplicate
+	}
+
+	if tx.IsCoinBaseTx() {
+		log.Warnf("coinbase tx %s cannot be added into transaction pool", tx.Hash())
+		return stageCoinbase, ErrIneffectiveCoinbase
+	}
+
+	if err := checkTransactionStandard(tx, &mp.txPolicy); err != nil {
+		log.Warnf("[TxPool checkTransactionStandard] %s, %s", err, tx.Hash())
+		return stageStandard, ErrTransactionNonStandard
+	}
+
+	if err := checkVoteStandard(tx, &mp.chainParams.VotePolicy); err != nil {
+		log.Warnf("[TxPool checkVoteStandard] %s, %s", err, tx.Hash())
+		return stageStandard, ErrTransactionNonStandard
+	}
+
+	if err := mp.checkFeeFloor(tx); err != nil {
+		log.Warnf("[TxPool checkFeeFloor] %s, %s", err, tx.Hash())
+		return stageFeeFloor, ErrTransactionBalance
+	}
+
+	if errCode := mp.checkSpecialConflict(tx); errCode != Success {
+		log.Warnf("[TxPool checkSpecialConflict] %s, %s", errCode, tx.Hash())
+		return stageConflict, errCode
+	}
+
+	return "", Success
+}
+
+// RejectionCounts returns the count of transactions rejected by each
+// admission stage since the node started.
+func (mp *TxPool) RejectionCounts() map[string]uint64 {
+	mp.RLock()
+	defer mp.RUnlock()
+	counts := make(map[string]uint64, len(AcceptanceChecks))
+	for _, stage := range AcceptanceChecks {
+		counts[stage] = mp.rejections[stage]
+	}
+	return counts
+}
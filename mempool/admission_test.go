@@ -0,0 +1,151 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package mempool
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTransferTx(nonce string, inputs []*types.Input,
+	values ...common.Fixed64) *types.Transaction {
+	tx := &types.Transaction{
+		TxType:  types.TransferAsset,
+		Payload: &payload.TransferAsset{},
+		Attributes: []*types.Attribute{{
+			Usage: types.Nonce,
+			Data:  []byte(nonce),
+		}},
+		Inputs: inputs,
+	}
+	for _, value := range values {
+		tx.Outputs = append(tx.Outputs, &types.Output{
+			AssetID: config.ELAAssetID,
+			Value:   value,
+		})
+	}
+	return tx
+}
+
+func addToPool(pool *TxPool, tx *types.Transaction) {
+	size := tx.GetSize()
+	pool.txnList[tx.Hash()] = tx
+	pool.txnDescs[tx.Hash()] = &TxDesc{Tx: tx, Size: size}
+	pool.txnListSize += size
+}
+
+func TestTxPool_Prefilter(t *testing.T) {
+	pool := NewTxPool(&config.DefaultParams)
+	minFee := config.DefaultParams.MinTransactionFee
+
+	parent := newTransferTx("parent", []*types.Input{{}}, 1000, 2000)
+	addToPool(pool, parent)
+	stage, code := pool.prefilter(parent)
+	assert.Equal(t, stageDuplicate, stage)
+	assert.Equal(t, errors.ErrTransactionDuplicate, code)
+
+	coinbase := &types.Transaction{
+		TxType:  types.CoinBase,
+		Payload: &payload.CoinBase{},
+	}
+	stage, code = pool.prefilter(coinbase)
+	assert.Equal(t, stageCoinbase, stage)
+	assert.Equal(t, errors.ErrIneffectiveCoinbase, code)
+
+	// a transaction without inputs pays no fee.
+	stage, code = pool.prefilter(newTransferTx("noinputs", nil, 1000))
+	assert.Equal(t, stageFeeFloor, stage)
+	assert.Equal(t, errors.ErrTransactionBalance, code)
+
+	// the fee of a transaction spending outputs in pool is known.
+	inputs := []*types.Input{
+		{Previous: *types.NewOutPoint(parent.Hash(), 0)},
+		{Previous: *types.NewOutPoint(parent.Hash(), 1)},
+	}
+	stage, code = pool.prefilter(newTransferTx("lowfee", inputs,
+		3000-minFee+1))
+	assert.Equal(t, stageFeeFloor, stage)
+	assert.Equal(t, errors.ErrTransactionBalance, code)
+	_, code = pool.prefilter(newTransferTx("enoughfee", inputs, 3000-minFee))
+	assert.Equal(t, errors.Success, code)
+
+	// outputs not in pool are left to the context check.
+	unknown := []*types.Input{
+		{Previous: *types.NewOutPoint(parent.Hash(), 0)},
+		{Previous: *types.NewOutPoint(common.Uint256{1}, 0)},
+	}
+	_, code = pool.prefilter(newTransferTx("unknown", unknown, 1000000))
+	assert.Equal(t, errors.Success, code)
+}
+
+func TestTxPool_PrefilterConflict(t *testing.T) {
+	pool := NewTxPool(&config.DefaultParams)
+
+	sideChainPow := func(signature byte) *types.Transaction {
+		return &types.Transaction{
+			TxType: types.SideChainPow,
+			Payload: &payload.SideChainPow{
+				SideBlockHash: common.Uint256{1},
+				BlockHeight:   100,
+				Signature:     []byte{signature},
+			},
+		}
+	}
+	tx1 := sideChainPow(1)
+	_, code := pool.prefilter(tx1)
+	assert.Equal(t, errors.Success, code)
+	slot, _ := conflictSlot(tx1)
+	pool.conflictSlots[slot] = tx1.Hash()
+	stage, code := pool.prefilter(sideChainPow(2))
+	assert.Equal(t, stageConflict, stage)
+	assert.Equal(t, errors.ErrSpecialTxConflict, code)
+
+	evidence := &types.Transaction{
+		TxType:  types.IllegalProposalEvidence,
+		Payload: &payload.DPOSIllegalProposals{},
+	}
+	_, code = pool.prefilter(evidence)
+	assert.Equal(t, errors.Success, code)
+	pool.specialTxList[evidence.Payload.(payload.DPOSIllegalData).Hash()] =
+		struct{}{}
+	stage, code = pool.prefilter(evidence)
+	assert.Equal(t, stageConflict, stage)
+	assert.Equal(t, errors.ErrProducerProcessing, code)
+
+	// nothing is occupied by the prefilter.
+	assert.Equal(t, 0, len(pool.tempConflictSlots))
+	assert.Equal(t, 0, len(pool.tempSpecialTxList))
+}
+
+func TestTxPool_RejectionCounts(t *testing.T) {
+	pool := NewTxPool(&config.DefaultParams)
+	counts := pool.RejectionCounts()
+	assert.Equal(t, len(AcceptanceChecks), len(counts))
+	for _, stage := range AcceptanceChecks {
+		assert.Equal(t, uint64(0), counts[stage])
+	}
+
+	tx := newTransferTx("rejected", nil, 1000)
+	pool.recordAcceptance(tx, stageFeeFloor, errors.ErrTransactionBalance)
+	pool.recordAcceptance(tx, stageFeeFloor, errors.ErrTransactionBalance)
+	pool.recordAcceptance(tx, stageDuplicate, errors.ErrTransactionDuplicate)
+	pool.recordAcceptance(tx, "", errors.Success)
+	counts = pool.RejectionCounts()
+	assert.Equal(t, uint64(2), counts[stageFeeFloor])
+	assert.Equal(t, uint64(1), counts[stageDuplicate])
+	assert.Equal(t, uint64(0), counts[stageContext])
+
+	// rejections by TestPackageAccept are not counted.
+	pool.TestPackageAccept([]*types.Transaction{tx})
+	assert.Equal(t, uint64(2), pool.RejectionCounts()[stageFeeFloor])
+}
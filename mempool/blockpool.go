@@ -75,6 +75,12 @@ func (bm *BlockPool) appendBlock(dposBlock *types.DposBlock) (bool, bool, error)
 		log.Info("[AppendBlock] check block sanity failed, ", err)
 		return false, false, err
 	}
+	// A pipelined block is on top of a block waiting for confirm, its
+	// context will be checked after the previous block is confirmed.
+	var pipelined bool
+	if block.Height == bm.Chain.GetHeight()+2 {
+		_, pipelined = bm.blocks[block.Header.Previous]
+	}
 	if block.Height == bm.Chain.GetHeight()+1 {
 		prevNode, exist := bm.Chain.LookupNodeInIndex(&block.Header.Previous)
 		if !exist {
@@ -101,6 +107,8 @@ func (bm *BlockPool) appendBlock(dposBlock *types.DposBlock) (bool, bool, error)
 		events.Notify(events.ETBlockAccepted, block)
 		if block.Height == blockchain.DefaultLedger.Blockchain.GetHeight()+1 {
 			events.Notify(events.ETNewBlockReceived, dposBlock)
+		} else if pipelined {
+			events.Notify(events.ETPipelinedBlockReceived, block)
 		}
 		return inMainChain, isOrphan, nil
 	}
@@ -205,10 +213,37 @@ func (bm *BlockPool) confirmBlock(hash common.Uint256) (bool, bool, error) {
 	} else {
 		return false, false, errors.New("already processed block")
 	}
+	bm.promotePipelinedBlocks(hash)
 
 	return true, false, nil
 }
 
+// promotePipelinedBlocks checks the context of the blocks received on top of
+// the block before it was confirmed, and notifies them as new blocks, so that
+// the consensus of the next height can start without waiting for them to be
+// relayed again.
+func (bm *BlockPool) promotePipelinedBlocks(hash common.Uint256) {
+	prevNode, exist := bm.Chain.LookupNodeInIndex(&hash)
+	if !exist || !prevNode.InMainChain {
+		return
+	}
+	for blockHash, block := range bm.blocks {
+		if !block.Header.Previous.IsEqual(hash) {
+			continue
+		}
+		if _, ok := bm.confirms[blockHash]; ok {
+			continue
+		}
+		if err := bm.Chain.CheckBlockContext(block, prevNode); err != nil {
+			log.Info("[promotePipelinedBlocks] check block context failed, ", err)
+			continue
+		}
+		events.Notify(events.ETNewBlockReceived, &types.DposBlock{
+			Block: block,
+		})
+	}
+}
+
 func (bm *BlockPool) AddToBlockMap(block *types.Block) {
 	bm.Lock()
 	defer bm.Unlock()
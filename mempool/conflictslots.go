@@ -0,0 +1,142 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package mempool
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	. "github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/log"
+	. "github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+)
+
+const (
+	// slotActivateProducer is the prefix of the conflict slot occupied by an
+	// activate producer transaction, keyed by the node public key.
+	slotActivateProducer = "activateproducer:"
+
+	// slotInactiveArbitrators is the prefix of the conflict slot occupied by
+	// an inactive arbitrators transaction, keyed by the inactive event.
+	slotInactiveArbitrators = "inactivearbitrators:"
+
+	// slotSideChainPow is the prefix of the conflict slot occupied by a side
+	// chain pow transaction, keyed by the side chain block hash.
+	slotSideChainPow = "sidechainpow:"
+)
+
+// conflictSlot returns the conflict slot of the special transaction. Only one
+// transaction of each slot can be in the pool at the same time, the later
+// ones are rejected at relay instead of at block validation. Returns false if
+// the transaction does not occupy any slot.
+func conflictSlot(txn *Transaction) (string, bool) {
+	switch txn.TxType {
+	case ActivateProducer:
+		p, ok := txn.Payload.(*payload.ActivateProducer)
+		if !ok {
+			return "", false
+		}
+		return slotActivateProducer + BytesToHexString(p.NodePublicKey), true
+	case InactiveArbitrators:
+		p, ok := txn.Payload.(*payload.InactiveArbitrators)
+		if !ok {
+			return "", false
+		}
+		return slotInactiveArbitrators + inactiveEventHash(p).String(), true
+	case SideChainPow:
+		p, ok := txn.Payload.(*payload.SideChainPow)
+		if !ok {
+			return "", false
+		}
+		return slotSideChainPow + p.SideBlockHash.String(), true
+	}
+	return "", false
+}
+
+// inactiveEventHash returns the hash identifying an inactive arbitrators
+// event, transactions from different sponsors of the same event share the
+// hash.
+func inactiveEventHash(p *payload.InactiveArbitrators) Uint256 {
+	arbiters := make([][]byte, len(p.Arbitrators))
+	copy(arbiters, p.Arbitrators)
+	sort.Slice(arbiters, func(i, j int) bool {
+		return bytes.Compare(arbiters[i], arbiters[j]) < 0
+	})
+
+	buf := new(bytes.Buffer)
+	WriteUint32(buf, p.BlockHeight)
+	for _, a := range arbiters {
+		WriteVarBytes(buf, a)
+	}
+	return Sha256D(buf.Bytes())
+}
+
+// verifyConflictSlot checks if the slot of the transaction is occupied by
+// another transaction in pool, and occupies the slot if not.
+func (mp *TxPool) verifyConflictSlot(txn *Transaction) error {
+	slot, ok := conflictSlot(txn)
+	if !ok {
+		return nil
+	}
+	if hash, ok := mp.conflictSlots[slot]; ok {
+		return fmt.Errorf("conflict slot %s is occupied by tx %s", slot,
+			hash.String())
+	}
+	if _, ok := mp.tempConflictSlots[slot]; ok {
+		return fmt.Errorf("duplicate conflict slot %s in tx", slot)
+	}
+	mp.tempConflictSlots[slot] = txn.Hash()
+
+	return nil
+}
+
+// releaseConflictSlot releases the slot occupied by the transaction.
+func (mp *TxPool) releaseConflictSlot(txn *Transaction) {
+	slot, ok := conflictSlot(txn)
+	if !ok {
+		return
+	}
+	if hash, ok := mp.conflictSlots[slot]; ok && hash.IsEqual(txn.Hash()) {
+		delete(mp.conflictSlots, slot)
+	}
+}
+
+// cleanConflictSlot removes the transaction in pool which occupies the same
+// slot with the transaction in block, returns the count of removed
+// transactions.
+func (mp *TxPool) cleanConflictSlot(blockTx *Transaction) int {
+	slot, ok := conflictSlot(blockTx)
+	if !ok {
+		return 0
+	}
+	hash, ok := mp.conflictSlots[slot]
+	if !ok {
+		return 0
+	}
+	delete(mp.conflictSlots, slot)
+	if hash.IsEqual(blockTx.Hash()) {
+		return 0
+	}
+	tx, ok := mp.txnList[hash]
+	if !ok {
+		return 0
+	}
+
+	log.Debugf("conflict slot %s taken by block transaction %s, delete "+
+		"transaction %s in the transaction pool", slot, blockTx.Hash(), hash)
+	mp.doRemoveTransaction(hash, tx.GetSize())
+	for _, input := range tx.Inputs {
+		mp.delInputUTXOList(input)
+	}
+	if tx.IsInactiveArbitrators() {
+		illegalData := tx.Payload.(payload.DPOSIllegalData)
+		illegalHash := illegalData.Hash()
+		mp.delSpecialTx(&illegalHash)
+	}
+	return 1
+}
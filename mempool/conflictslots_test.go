@@ -0,0 +1,102 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package mempool
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConflictSlot(t *testing.T) {
+	arbiters := [][]byte{{1}, {2}, {3}}
+	tx1 := &types.Transaction{
+		TxType: types.InactiveArbitrators,
+		Payload: &payload.InactiveArbitrators{
+			Sponsor:     []byte{1},
+			Arbitrators: arbiters,
+			BlockHeight: 100,
+		},
+	}
+	tx2 := &types.Transaction{
+		TxType: types.InactiveArbitrators,
+		Payload: &payload.InactiveArbitrators{
+			Sponsor:     []byte{2},
+			Arbitrators: [][]byte{{3}, {1}, {2}},
+			BlockHeight: 100,
+		},
+	}
+	tx3 := &types.Transaction{
+		TxType: types.InactiveArbitrators,
+		Payload: &payload.InactiveArbitrators{
+			Sponsor:     []byte{1},
+			Arbitrators: arbiters,
+			BlockHeight: 101,
+		},
+	}
+
+	// different sponsors of the same event share the slot
+	slot1, ok := conflictSlot(tx1)
+	assert.True(t, ok)
+	slot2, ok := conflictSlot(tx2)
+	assert.True(t, ok)
+	assert.Equal(t, slot1, slot2)
+	slot3, ok := conflictSlot(tx3)
+	assert.True(t, ok)
+	assert.NotEqual(t, slot1, slot3)
+
+	_, ok = conflictSlot(&types.Transaction{TxType: types.TransferAsset})
+	assert.False(t, ok)
+}
+
+func TestTxPool_VerifyConflictSlot(t *testing.T) {
+	pool := NewTxPool(&config.DefaultParams)
+
+	sideBlockHash := common.Uint256{1}
+	tx1 := &types.Transaction{
+		TxType: types.SideChainPow,
+		Payload: &payload.SideChainPow{
+			SideBlockHash: sideBlockHash,
+			BlockHeight:   100,
+			Signature:     []byte{1},
+		},
+	}
+	tx2 := &types.Transaction{
+		TxType: types.SideChainPow,
+		Payload: &payload.SideChainPow{
+			SideBlockHash: sideBlockHash,
+			BlockHeight:   100,
+			Signature:     []byte{2},
+		},
+	}
+
+	assert.NoError(t, pool.verifyConflictSlot(tx1))
+	pool.commitTemp()
+	pool.clearTemp()
+	pool.txnList[tx1.Hash()] = tx1
+
+	// the later one is rejected no matter which one arrived first
+	assert.Error(t, pool.verifyConflictSlot(tx2))
+	pool.clearTemp()
+
+	// the slot is released after the transaction removed from pool
+	pool.doRemoveTransaction(tx1.Hash(), tx1.GetSize())
+	assert.NoError(t, pool.verifyConflictSlot(tx2))
+	pool.commitTemp()
+	pool.clearTemp()
+	pool.txnList[tx2.Hash()] = tx2
+
+	// the transaction in pool is removed if a block takes the slot
+	assert.Equal(t, 1, pool.cleanConflictSlot(tx1))
+	_, ok := pool.txnList[tx2.Hash()]
+	assert.False(t, ok)
+	assert.Equal(t, 0, len(pool.conflictSlots))
+}
@@ -22,12 +22,15 @@ func extractRejectCode(err error) (msg.RejectCode, bool) {
 
 	var code msg.RejectCode
 	switch errCode {
-	case errors.ErrTransactionDuplicate:
+	case errors.ErrTransactionDuplicate, errors.ErrSpecialTxConflict:
 		code = msg.RejectDuplicate
 
 	case errors.ErrTransactionBalance:
 		code = msg.RejectInsufficientFee
 
+	case errors.ErrTransactionNonStandard:
+		code = msg.RejectNonstandard
+
 	default:
 		return msg.RejectInvalid, false
 	}
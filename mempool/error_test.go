@@ -18,6 +18,10 @@ func TestErrToRejectErr(t *testing.T) {
 	assert.Equal(t, msg.RejectDuplicate, code)
 	t.Log(reason)
 
+	code, reason = ErrToRejectErr(errors.ErrSpecialTxConflict)
+	assert.Equal(t, msg.RejectDuplicate, code)
+	t.Log(reason)
+
 	code, reason = ErrToRejectErr(errors.ErrTransactionBalance)
 	assert.Equal(t, msg.RejectInsufficientFee, code)
 	t.Log(reason)
@@ -0,0 +1,100 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package mempool
+
+import (
+	"fmt"
+
+	"github.com/elastos/Elastos.ELA/common/config"
+	. "github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+)
+
+// checkTransactionStandard checks if the transaction is standard by the given
+// policy. Non-standard transactions are still valid by the consensus rules,
+// they are only refused by the transaction pool and will not be relayed.
+func checkTransactionStandard(tx *Transaction, policy *config.TxPolicy) error {
+	// Evidences and inactive arbitrators transactions are produced by the
+	// arbiters and must be relayed regardless of their size.
+	if tx.IsIllegalTypeTx() || tx.IsInactiveArbitrators() {
+		return nil
+	}
+
+	if policy.MaxTxSize > 0 {
+		if size := tx.GetSize(); size > policy.MaxTxSize {
+			return fmt.Errorf("transaction size %d exceeds the limit %d",
+				size, policy.MaxTxSize)
+		}
+	}
+
+	if policy.MaxInputs > 0 && len(tx.Inputs) > policy.MaxInputs {
+		return fmt.Errorf("transaction inputs count %d exceeds the limit %d",
+			len(tx.Inputs), policy.MaxInputs)
+	}
+
+	if policy.MaxOutputs > 0 && len(tx.Outputs) > policy.MaxOutputs {
+		return fmt.Errorf("transaction outputs count %d exceeds the limit %d",
+			len(tx.Outputs), policy.MaxOutputs)
+	}
+
+	for i, p := range tx.Programs {
+		if policy.MaxProgramCodeSize > 0 &&
+			len(p.Code) > policy.MaxProgramCodeSize {
+			return fmt.Errorf("program %d code size %d exceeds the limit %d",
+				i, len(p.Code), policy.MaxProgramCodeSize)
+		}
+		if policy.MaxProgramParameterSize > 0 &&
+			len(p.Parameter) > policy.MaxProgramParameterSize {
+			return fmt.Errorf("program %d parameter size %d exceeds the"+
+				" limit %d", i, len(p.Parameter),
+				policy.MaxProgramParameterSize)
+		}
+	}
+
+	return checkMemoStandard(tx, policy)
+}
+
+// checkMemoStandard checks if the transaction has at most one memo attribute
+// and the memo is a standard memo within the size limit.
+func checkMemoStandard(tx *Transaction, policy *config.TxPolicy) error {
+	var memos int
+	for _, attr := range tx.Attributes {
+		if attr.Usage != Memo {
+			continue
+		}
+		if memos++; memos > 1 {
+			return fmt.Errorf("transaction has more than one memo")
+		}
+		if policy.MaxMemoSize > 0 && len(attr.Data) > policy.MaxMemoSize {
+			return fmt.Errorf("memo size %d exceeds the limit %d",
+				len(attr.Data), policy.MaxMemoSize)
+		}
+		if _, err := ParseMemo(attr.Data); err != nil {
+			return fmt.Errorf("non-standard memo, %s", err)
+		}
+	}
+	return nil
+}
+
+// checkVoteStandard checks if the vote outputs of the transaction are allowed
+// by the vote policy. The policy is applied by the consensus rules only since
+// VotePolicyHeight, but the transaction pool applies it at any height to stop
+// relaying tiny votes before that.
+func checkVoteStandard(tx *Transaction, policy *config.VotePolicy) error {
+	for i, output := range tx.Outputs {
+		if output.Type != OTVote {
+			continue
+		}
+		vote, ok := output.Payload.(*outputpayload.VoteOutput)
+		if !ok {
+			continue
+		}
+		if err := policy.CheckVoteOutput(vote, output.Value); err != nil {
+			return fmt.Errorf("output %d, %s", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package mempool
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/core/contract/program"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckTransactionStandard(t *testing.T) {
+	policy := &config.TxPolicy{
+		MaxTxSize:               1000,
+		MaxInputs:               2,
+		MaxOutputs:              2,
+		MaxProgramCodeSize:      35,
+		MaxProgramParameterSize: 65,
+	}
+	newTx := func() *types.Transaction {
+		return &types.Transaction{
+			TxType:  types.TransferAsset,
+			Payload: &payload.TransferAsset{},
+			Inputs:  []*types.Input{{}, {}},
+			Outputs: []*types.Output{{}, {}},
+			Programs: []*program.Program{{
+				Code:      make([]byte, 35),
+				Parameter: make([]byte, 65),
+			}},
+		}
+	}
+	assert.NoError(t, checkTransactionStandard(newTx(), policy))
+
+	tx := newTx()
+	tx.Inputs = append(tx.Inputs, &types.Input{})
+	assert.Error(t, checkTransactionStandard(tx, policy))
+
+	tx = newTx()
+	tx.Outputs = append(tx.Outputs, &types.Output{})
+	assert.Error(t, checkTransactionStandard(tx, policy))
+
+	tx = newTx()
+	tx.Programs[0].Code = make([]byte, 36)
+	assert.Error(t, checkTransactionStandard(tx, policy))
+
+	tx = newTx()
+	tx.Programs[0].Parameter = make([]byte, 66)
+	assert.Error(t, checkTransactionStandard(tx, policy))
+
+	tx = newTx()
+	tx.Attributes = []*types.Attribute{{
+		Usage: types.Memo,
+		Data:  make([]byte, 1000),
+	}}
+	assert.Error(t, checkTransactionStandard(tx, policy))
+
+	// Zero value means no limit.
+	assert.NoError(t, checkTransactionStandard(tx, &config.TxPolicy{}))
+}
+
+func TestCheckMemoStandard(t *testing.T) {
+	policy := &config.TxPolicy{MaxMemoSize: 20}
+	newTx := func(memos ...string) *types.Transaction {
+		tx := &types.Transaction{TxType: types.TransferAsset}
+		for _, m := range memos {
+			tx.Attributes = append(tx.Attributes, &types.Attribute{
+				Usage: types.Memo,
+				Data:  []byte(m),
+			})
+		}
+		return tx
+	}
+	assert.NoError(t, checkMemoStandard(newTx(), policy))
+	assert.NoError(t, checkMemoStandard(newTx("type:id,msg:10001"), policy))
+	assert.NoError(t, checkMemoStandard(newTx("free text"), policy))
+
+	assert.Error(t, checkMemoStandard(newTx("type:id,msg:100 01"), policy))
+	assert.Error(t, checkMemoStandard(newTx("type:text,msg:too long memo"),
+		policy))
+	assert.Error(t, checkMemoStandard(newTx("a", "b"), policy))
+}
+
+func TestCheckVoteStandard(t *testing.T) {
+	policy := &config.VotePolicy{MinVoteAmount: 100}
+	newTx := func(votes common.Fixed64) *types.Transaction {
+		return &types.Transaction{
+			TxType: types.TransferAsset,
+			Outputs: []*types.Output{
+				{Value: 1000},
+				{
+					Value: 1000,
+					Type:  types.OTVote,
+					Payload: &outputpayload.VoteOutput{
+						Version: outputpayload.VoteProducerAndCRVersion,
+						Contents: []outputpayload.VoteContent{{
+							VoteType: outputpayload.Delegate,
+							CandidateVotes: []outputpayload.CandidateVotes{
+								{Candidate: []byte{1}, Votes: votes},
+							},
+						}},
+					},
+				},
+			},
+		}
+	}
+	assert.NoError(t, checkVoteStandard(newTx(100), policy))
+	assert.Error(t, checkVoteStandard(newTx(1), policy))
+}
+
+func TestTxPool_SetTxPolicy(t *testing.T) {
+	params := config.DefaultParams
+	pool := NewTxPool(&params)
+	assert.Equal(t, params.TxPolicy, pool.TxPolicy())
+
+	policy := config.TxPolicy{MaxTxSize: 1000, MaxInputs: 10}
+	pool.SetTxPolicy(policy)
+	assert.Equal(t, policy, pool.TxPolicy())
+
+	// Chain params should not be changed.
+	assert.Equal(t, config.DefaultParams.TxPolicy, params.TxPolicy)
+}
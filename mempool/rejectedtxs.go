@@ -0,0 +1,95 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package mempool
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	. "github.com/elastos/Elastos.ELA/common"
+	. "github.com/elastos/Elastos.ELA/errors"
+)
+
+// maxRejectedTxs is the maximum number of recently rejected transactions kept
+// by the transaction pool.
+const maxRejectedTxs = 1000
+
+// RejectedTx records why and when a transaction was rejected by the
+// transaction pool.
+type RejectedTx struct {
+	TxID   Uint256
+	Code   ErrCode
+	Reason string
+	Time   time.Time
+}
+
+// rejectedTxCache keeps the recently rejected transactions, limited to a
+// maximum number of transactions with eviction for the least recently
+// rejected transaction when the limit is exceeded.
+type rejectedTxCache struct {
+	sync.Mutex
+	txs     map[Uint256]*list.Element // nearly O(1) lookups
+	entries *list.List                // O(1) insert, update, delete
+	limit   int
+}
+
+// add records the rejected transaction as the most recently rejected one.
+func (c *rejectedTxCache) add(tx *RejectedTx) {
+	c.Lock()
+	defer c.Unlock()
+
+	if node, ok := c.txs[tx.TxID]; ok {
+		node.Value = tx
+		c.entries.MoveToFront(node)
+		return
+	}
+
+	if len(c.txs)+1 > c.limit {
+		node := c.entries.Back()
+		delete(c.txs, node.Value.(*RejectedTx).TxID)
+
+		// Reuse the list node of the evicted transaction.
+		node.Value = tx
+		c.entries.MoveToFront(node)
+		c.txs[tx.TxID] = node
+		return
+	}
+
+	c.txs[tx.TxID] = c.entries.PushFront(tx)
+}
+
+// remove removes the transaction, it's called when a transaction rejected
+// before is accepted.
+func (c *rejectedTxCache) remove(hash Uint256) {
+	c.Lock()
+	defer c.Unlock()
+
+	if node, ok := c.txs[hash]; ok {
+		delete(c.txs, hash)
+		c.entries.Remove(node)
+	}
+}
+
+func (c *rejectedTxCache) get(hash Uint256) (*RejectedTx, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	node, ok := c.txs[hash]
+	if !ok {
+		return nil, false
+	}
+	tx := *node.Value.(*RejectedTx)
+	return &tx, true
+}
+
+func newRejectedTxCache(limit int) *rejectedTxCache {
+	return &rejectedTxCache{
+		txs:     make(map[Uint256]*list.Element),
+		entries: list.New(),
+		limit:   limit,
+	}
+}
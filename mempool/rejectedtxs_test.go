@@ -0,0 +1,62 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package mempool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRejectedTxCache(t *testing.T) {
+	cache := newRejectedTxCache(3)
+	hashes := make([]common.Uint256, 4)
+	for i := range hashes {
+		hashes[i] = common.Uint256{byte(i + 1)}
+	}
+
+	for i := 0; i < 3; i++ {
+		cache.add(&RejectedTx{
+			TxID:   hashes[i],
+			Code:   errors.ErrDoubleSpend,
+			Reason: errors.ErrDoubleSpend.Error(),
+			Time:   time.Now(),
+		})
+	}
+	for i := 0; i < 3; i++ {
+		tx, ok := cache.get(hashes[i])
+		assert.True(t, ok)
+		assert.Equal(t, hashes[i], tx.TxID)
+		assert.Equal(t, errors.ErrDoubleSpend, tx.Code)
+	}
+
+	// rejecting the first transaction again makes it the most recent one,
+	// so the second one is evicted.
+	cache.add(&RejectedTx{TxID: hashes[0], Code: errors.ErrTransactionBalance})
+	cache.add(&RejectedTx{TxID: hashes[3], Code: errors.ErrInvalidInput})
+	_, ok := cache.get(hashes[1])
+	assert.False(t, ok)
+	tx, ok := cache.get(hashes[0])
+	assert.True(t, ok)
+	assert.Equal(t, errors.ErrTransactionBalance, tx.Code)
+	_, ok = cache.get(hashes[3])
+	assert.True(t, ok)
+
+	// modifying the returned copy does not change the cache.
+	tx.Code = errors.Success
+	tx, _ = cache.get(hashes[0])
+	assert.Equal(t, errors.ErrTransactionBalance, tx.Code)
+
+	cache.remove(hashes[0])
+	_, ok = cache.get(hashes[0])
+	assert.False(t, ok)
+	assert.Equal(t, 2, len(cache.txs))
+	assert.Equal(t, 2, cache.entries.Len())
+}
@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/elastos/Elastos.ELA/blockchain"
 	. "github.com/elastos/Elastos.ELA/common"
@@ -26,20 +27,39 @@ import (
 	"github.com/elastos/Elastos.ELA/vm"
 )
 
+// TxDesc is a descriptor containing a transaction in the transaction pool
+// along with additional metadata.
+type TxDesc struct {
+	// Tx is the transaction associated with the entry.
+	Tx *Transaction
+
+	// Added is the time when the entry was added to the pool.
+	Added time.Time
+
+	// Height is the best block height when the entry was added to the pool.
+	Height uint32
+
+	// Size is the serialized size of the transaction.
+	Size int
+}
+
 type TxPool struct {
 	chainParams *config.Params
 
 	sync.RWMutex
 	txnList           map[Uint256]*Transaction // transaction which have been verifyed will put into this map
+	txnDescs          map[Uint256]*TxDesc      // descriptors of transactions in txnList
 	inputUTXOList     map[string]*Transaction  // transaction which pass the verify will add the UTXO to this map
 	sidechainTxList   map[Uint256]*Transaction // sidechain tx pool
 	ownerPublicKeys   map[string]struct{}
 	nodePublicKeys    map[string]struct{}
 	codes             map[string]struct{}
 	crCIDs            map[Uint168]struct{}
-	specialTxList     map[Uint256]struct{} // specialTxList holds the payload hashes of all illegal transactions and inactive arbitrators transactions
+	specialTxList     map[Uint256]struct{} // specialTxList holds the payload hashes of all illegal transactions and inactive arbitrators transactions, and the commitments of CR nickname commit transactions
 	producerNicknames map[string]struct{}
 	crNicknames       map[string]struct{}
+	revokedVotes      map[string]*Transaction // revokedVotes holds the refer keys of vote outputs revoked by transactions in pool
+	conflictSlots     map[string]Uint256      // conflictSlots holds the conflict slots of special transactions and the hashes of the transactions occupying them
 
 	tempInputUTXOList   map[string]*Transaction
 	tempSidechainTxList map[Uint256]*Transaction
@@ -51,7 +71,19 @@ type TxPool struct {
 
 	tempProducerNicknames map[string]struct{}
 	tempCrNicknames       map[string]struct{}
+	tempRevokedVotes      map[string]*Transaction
+	tempConflictSlots     map[string]Uint256
 	txnListSize           int
+
+	// txPolicy holds the standardness rules of transactions, it's copied from
+	// chain params and can be changed by SetTxPolicy without restarting.
+	txPolicy config.TxPolicy
+
+	// rejectedTxs holds the recently rejected transactions with the reasons.
+	rejectedTxs *rejectedTxCache
+
+	// rejections holds the count of rejected transactions by admission stage.
+	rejections map[string]uint64
 }
 
 //append transaction to txnpool when check ok.
@@ -59,7 +91,8 @@ type TxPool struct {
 func (mp *TxPool) AppendToTxPool(tx *Transaction) error {
 	mp.Lock()
 	defer mp.Unlock()
-	code := mp.appendToTxPool(tx)
+	stage, code := mp.appendToTxPool(tx)
+	mp.recordAcceptance(tx, stage, code)
 	if code != Success {
 		return code
 	}
@@ -68,56 +101,82 @@ func (mp *TxPool) AppendToTxPool(tx *Transaction) error {
 	return nil
 }
 
-func (mp *TxPool) appendToTxPool(tx *Transaction) ErrCode {
-	txHash := tx.Hash()
-
-	// Don't accept the transaction if it already exists in the pool.  This
-	// applies to orphan transactions as well.  This check is intended to
-	// be a quick check to weed out duplicates.
-	if _, ok := mp.txnList[txHash]; ok {
-		return ErrTransactionDuplicate
+// recordAcceptance records the transaction into the rejected transactions and
+// counts the rejection of the stage if it's rejected, or removes it from the
+// rejected transactions if accepted.
+func (mp *TxPool) recordAcceptance(tx *Transaction, stage string,
+	code ErrCode) {
+	if code != Success {
+		mp.rejections[stage]++
 	}
+	switch code {
+	case Success:
+		mp.rejectedTxs.remove(tx.Hash())
+	case ErrTransactionDuplicate:
+		// The transaction is in pool already.
+	default:
+		mp.rejectedTxs.add(&RejectedTx{
+			TxID:   tx.Hash(),
+			Code:   code,
+			Reason: code.Error(),
+			Time:   time.Now(),
+		})
+	}
+}
+
+// GetRejectedTx returns why and when the transaction was rejected if it's
+// rejected by the transaction pool recently.
+func (mp *TxPool) GetRejectedTx(hash Uint256) (*RejectedTx, bool) {
+	return mp.rejectedTxs.get(hash)
+}
 
-	if tx.IsCoinBaseTx() {
-		log.Warnf("coinbase tx %s cannot be added into transaction pool", tx.Hash())
-		return ErrIneffectiveCoinbase
+func (mp *TxPool) appendToTxPool(tx *Transaction) (string, ErrCode) {
+	if stage, errCode := mp.prefilter(tx); errCode != Success {
+		return stage, errCode
 	}
 
 	chain := blockchain.DefaultLedger.Blockchain
 	bestHeight := blockchain.DefaultLedger.Blockchain.GetHeight()
 	if errCode := chain.CheckTransactionSanity(bestHeight+1, tx); errCode != Success {
 		log.Warn("[TxPool CheckTransactionSanity] failed", tx.Hash())
-		return errCode
+		return stageSanity, errCode
 	}
 	references, err := chain.UTXOCache.GetTxReference(tx)
 	if err != nil {
 		log.Warn("[CheckTransactionContext] get transaction reference failed")
-		return ErrUnknownReferredTx
+		return stageContext, ErrUnknownReferredTx
 	}
 	if errCode := chain.CheckTransactionContext(bestHeight+1, tx, references); errCode != Success {
 		log.Warn("[TxPool CheckTransactionContext] failed", tx.Hash())
-		return errCode
+		return stageContext, errCode
 	}
 	//verify transaction by pool with lock
 	defer mp.clearTemp()
 	if errCode := mp.verifyTransactionWithTxnPool(tx, references); errCode != Success {
 		log.Warn("[TxPool verifyTransactionWithTxnPool] failed", tx.Hash())
-		return errCode
+		return stageTxPool, errCode
 	}
 
 	size := tx.GetSize()
 	if mp.txnListSize+size > pact.MaxTxPoolSize {
 		log.Warn("TxPool check transactions size failed", tx.Hash())
-		return ErrTransactionPoolSize
+		return stagePoolSize, ErrTransactionPoolSize
 	}
 
 	mp.commitTemp()
 
 	// Add the transaction to mem pool
+	txHash := tx.Hash()
 	mp.txnList[txHash] = tx
+	mp.txnDescs[txHash] = &TxDesc{
+		Tx:     tx,
+		Added:  time.Now(),
+		Height: bestHeight,
+		Size:   size,
+	}
 	mp.txnListSize += size
 
-	return Success
+	return "", Success
 }
 
 // HaveTransaction returns if a transaction is in transaction pool by the given
@@ -142,6 +201,29 @@ func (mp *TxPool) GetTxsInPool() []*Transaction {
 	return txs
 }
 
+// TxDescs returns a slice of descriptors for all the transactions in the pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) TxDescs() []*TxDesc {
+	mp.RLock()
+	descs := make([]*TxDesc, 0, len(mp.txnDescs))
+	for _, desc := range mp.txnDescs {
+		descs = append(descs, desc)
+	}
+	mp.RUnlock()
+	return descs
+}
+
+// GetTxPoolSize returns the total serialized size of all transactions in the
+// pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) GetTxPoolSize() int {
+	mp.RLock()
+	defer mp.RUnlock()
+	return mp.txnListSize
+}
+
 //clean the trasaction Pool with committed block.
 func (mp *TxPool) CleanSubmittedTransactions(block *Block) {
 	mp.Lock()
@@ -160,6 +242,8 @@ func (mp *TxPool) cleanTransactions(blockTxs []*Transaction) {
 			continue
 		}
 
+		deleteCount += mp.cleanConflictSlot(blockTx)
+
 		if blockTx.IsIllegalTypeTx() || blockTx.IsInactiveArbitrators() {
 			illegalData, ok := blockTx.Payload.(payload.DPOSIllegalData)
 			if !ok {
@@ -180,13 +264,12 @@ func (mp *TxPool) cleanTransactions(blockTxs []*Transaction) {
 			}
 			continue
 		} else if blockTx.IsActivateProducerTx() {
-			apPayload, ok := blockTx.Payload.(*payload.ActivateProducer)
+			_, ok := blockTx.Payload.(*payload.ActivateProducer)
 			if !ok {
 				log.Error("activate producer payload cast failed, tx:",
 					blockTx.Hash())
 				continue
 			}
-			mp.delNodePublicKey(BytesToHexString(apPayload.NodePublicKey))
 			if _, ok := mp.txnList[blockTx.Hash()]; ok {
 				mp.doRemoveTransaction(blockTx.Hash(), blockTx.GetSize())
 				deleteCount++
@@ -194,6 +277,8 @@ func (mp *TxPool) cleanTransactions(blockTxs []*Transaction) {
 			continue
 		}
 
+		deleteCount += mp.cleanRevokeVoteConflicts(blockTx)
+
 		inputUtxos, err := blockchain.DefaultLedger.Blockchain.UTXOCache.GetTxReference(blockTx)
 		if err != nil {
 			log.Infof("Transaction=%s not exist when deleting, %s.",
@@ -289,6 +374,37 @@ func (mp *TxPool) cleanTransactions(blockTxs []*Transaction) {
 					mp.delCRCID(unrcPayload.CID)
 				case ReturnCRDepositCoin:
 					mp.delCode(BytesToHexString(tx.Programs[0].Code))
+				case RevokeVote:
+					mp.delRevokedVotes(tx)
+				case CRNicknameCommit:
+					commitPayload, ok := tx.Payload.(*payload.CRNicknameCommit)
+					if !ok {
+						log.Error("CR nickname commit payload cast failed, tx:", tx.Hash())
+						continue
+					}
+					mp.delSpecialTx(&commitPayload.Commitment)
+				case ProducerAppeal:
+					appealPayload, ok := tx.Payload.(*payload.ProducerAppeal)
+					if !ok {
+						log.Error("producer appeal payload cast failed, tx:", tx.Hash())
+						continue
+					}
+					mp.delOwnerPublicKey(BytesToHexString(appealPayload.OwnerPublicKey))
+				case RotateNodeKey:
+					rotatePayload, ok := tx.Payload.(*payload.RotateNodeKey)
+					if !ok {
+						log.Error("rotate node key payload cast failed, tx:", tx.Hash())
+						continue
+					}
+					mp.delOwnerPublicKey(BytesToHexString(rotatePayload.OwnerPublicKey))
+					mp.delNodePublicKey(BytesToHexString(rotatePayload.NewNodePublicKey))
+				case CRCRewardAddress:
+					rewardPayload, ok := tx.Payload.(*payload.CRCRewardAddress)
+					if !ok {
+						log.Error("CRC reward address payload cast failed, tx:", tx.Hash())
+						continue
+					}
+					mp.delNodePublicKey(BytesToHexString(rewardPayload.NodePublicKey))
 				}
 
 				deleteCount++
@@ -355,6 +471,16 @@ func (mp *TxPool) cleanVoteAndUpdateProducer(ownerPublicKey []byte) error {
 				mp.delOwnerPublicKey(BytesToHexString(upPayload.OwnerPublicKey))
 				mp.delNodePublicKey(BytesToHexString(upPayload.NodePublicKey))
 			}
+		} else if txn.TxType == RotateNodeKey {
+			rnPayload, ok := txn.Payload.(*payload.RotateNodeKey)
+			if !ok {
+				return errors.New("invalid rotate node key payload")
+			}
+			if bytes.Equal(rnPayload.OwnerPublicKey, ownerPublicKey) {
+				mp.removeTransaction(txn)
+				mp.delOwnerPublicKey(BytesToHexString(rnPayload.OwnerPublicKey))
+				mp.delNodePublicKey(BytesToHexString(rnPayload.NewNodePublicKey))
+			}
 		}
 	}
 
@@ -406,6 +532,12 @@ func (mp *TxPool) GetTransaction(hash Uint256) *Transaction {
 //verify transaction with txnpool
 func (mp *TxPool) verifyTransactionWithTxnPool(
 	txn *Transaction, references map[*Input]*Output) ErrCode {
+	// check if the special transaction conflicts with another one in pool
+	if err := mp.verifyConflictSlot(txn); err != nil {
+		log.Warn(err)
+		return ErrSpecialTxConflict
+	}
+
 	if txn.IsSideChainPowTx() {
 		// check and replace the duplicate sidechainpow tx
 		mp.replaceDuplicateSideChainPowTx(txn)
@@ -423,6 +555,12 @@ func (mp *TxPool) verifyTransactionWithTxnPool(
 		return ErrDoubleSpend
 	}
 
+	// check if the transaction conflicts with revoked votes in pool
+	if err := mp.verifyRevokedVotes(txn); err != nil {
+		log.Warn(err)
+		return ErrDoubleSpend
+	}
+
 	if errCode := mp.verifyProducerRelatedTx(txn); errCode != Success {
 		return errCode
 	}
@@ -466,21 +604,48 @@ func (mp *TxPool) verifyProducerRelatedTx(txn *Transaction) ErrCode {
 			return ErrProducerProcessing
 		}
 	case ActivateProducer:
-		p, ok := txn.Payload.(*payload.ActivateProducer)
+		_, ok := txn.Payload.(*payload.ActivateProducer)
 		if !ok {
 			log.Error("activate producer payload cast failed, tx:", txn.Hash())
 			return ErrProducerProcessing
 		}
-		if err := mp.verifyDuplicateNode(BytesToHexString(p.NodePublicKey)); err != nil {
-			log.Warn(err)
-			return ErrProducerNodeProcessing
-		}
 	case ReturnDepositCoin:
 		err := mp.verifyDuplicateCode(BytesToHexString(txn.Programs[0].Code))
 		if err != nil {
 			log.Warn(err)
 			return ErrProducerProcessing
 		}
+	case ProducerAppeal:
+		p, ok := txn.Payload.(*payload.ProducerAppeal)
+		if !ok {
+			log.Error("producer appeal payload cast failed, tx:", txn.Hash())
+			return ErrProducerProcessing
+		}
+		if err := mp.verifyDuplicateOwner(BytesToHexString(p.OwnerPublicKey)); err != nil {
+			log.Warn(err)
+			return ErrProducerProcessing
+		}
+	case RotateNodeKey:
+		p, ok := txn.Payload.(*payload.RotateNodeKey)
+		if !ok {
+			log.Error("rotate node key payload cast failed, tx:", txn.Hash())
+			return ErrProducerProcessing
+		}
+		if err := mp.verifyDuplicateOwnerAndNode(BytesToHexString(p.OwnerPublicKey),
+			BytesToHexString(p.NewNodePublicKey)); err != nil {
+			log.Warn(err)
+			return ErrProducerProcessing
+		}
+	case CRCRewardAddress:
+		p, ok := txn.Payload.(*payload.CRCRewardAddress)
+		if !ok {
+			log.Error("CRC reward address payload cast failed, tx:", txn.Hash())
+			return ErrProducerProcessing
+		}
+		if err := mp.verifyDuplicateNode(BytesToHexString(p.NodePublicKey)); err != nil {
+			log.Warn(err)
+			return ErrProducerNodeProcessing
+		}
 	case IllegalProposalEvidence, IllegalVoteEvidence, IllegalBlockEvidence,
 		IllegalSidechainEvidence, InactiveArbitrators:
 		illegalData, ok := txn.Payload.(payload.DPOSIllegalData)
@@ -537,6 +702,16 @@ func (mp *TxPool) verifyCRRelatedTx(txn *Transaction) ErrCode {
 			log.Warn(err)
 			return ErrCRProcessing
 		}
+	case CRNicknameCommit:
+		p, ok := txn.Payload.(*payload.CRNicknameCommit)
+		if !ok {
+			log.Error("CR nickname commit payload cast failed, tx:", txn.Hash())
+			return ErrCRProcessing
+		}
+		if err := mp.verifyDuplicateSpecialTx(&p.Commitment); err != nil {
+			log.Warn(err)
+			return ErrCRProcessing
+		}
 	}
 
 	return Success
@@ -559,6 +734,10 @@ func (mp *TxPool) removeTransaction(tx *Transaction) {
 	for UTXOTxInput := range reference {
 		mp.delInputUTXOList(UTXOTxInput)
 	}
+
+	if tx.IsRevokeVoteTx() {
+		mp.delRevokedVotes(tx)
+	}
 }
 
 //check and add to utxo list pool
@@ -583,6 +762,94 @@ func (mp *TxPool) verifyDoubleSpend(txn *Transaction) error {
 	return nil
 }
 
+// verifyRevokedVotes checks that the transaction does not spend a vote output
+// revoked in pool, and a revoke vote transaction does not revoke a vote output
+// that has been revoked or spent in pool, then adds the revoked votes.
+func (mp *TxPool) verifyRevokedVotes(txn *Transaction) error {
+	for _, input := range txn.Inputs {
+		if tx, ok := mp.revokedVotes[input.ReferKey()]; ok {
+			return fmt.Errorf("spent revoked vote detected, transaction "+
+				"hash: %s, input: %s, index: %d", tx.Hash(),
+				input.Previous.TxID, input.Previous.Index)
+		}
+	}
+
+	if !txn.IsRevokeVoteTx() {
+		return nil
+	}
+	p, ok := txn.Payload.(*payload.RevokeVote)
+	if !ok {
+		return errors.New("convert the payload of revoke vote tx failed")
+	}
+	for _, v := range p.Votes {
+		referKey := NewOutPoint(v.TxID, v.Index).ReferKey()
+		if tx, ok := mp.revokedVotes[referKey]; ok {
+			return fmt.Errorf("duplicate revoked vote detected, "+
+				"transaction hash: %s, vote: %s, index: %d",
+				tx.Hash(), v.TxID, v.Index)
+		}
+		if tx, ok := mp.inputUTXOList[referKey]; ok {
+			return fmt.Errorf("revoked vote spent in pool, "+
+				"transaction hash: %s, vote: %s, index: %d",
+				tx.Hash(), v.TxID, v.Index)
+		}
+		mp.tempRevokedVotes[referKey] = txn
+	}
+
+	return nil
+}
+
+// cleanRevokeVoteConflicts removes revoke vote transactions in pool which
+// revoke the same vote outputs revoked or spent by the block transaction, and
+// returns the count of removed transactions.
+func (mp *TxPool) cleanRevokeVoteConflicts(blockTx *Transaction) int {
+	referKeys := make([]string, 0, len(blockTx.Inputs))
+	for _, input := range blockTx.Inputs {
+		referKeys = append(referKeys, input.ReferKey())
+	}
+	if p, ok := blockTx.Payload.(*payload.RevokeVote); ok {
+		for _, v := range p.Votes {
+			referKeys = append(referKeys,
+				NewOutPoint(v.TxID, v.Index).ReferKey())
+		}
+	}
+
+	deleteCount := 0
+	for _, referKey := range referKeys {
+		tx, ok := mp.revokedVotes[referKey]
+		if !ok || tx.Hash() == blockTx.Hash() {
+			continue
+		}
+		log.Debugf("revoked vote conflict detected when adding a new block. "+
+			"Delete transaction in the transaction pool. block transaction "+
+			"hash: %s, transaction hash: %s", blockTx.Hash(), tx.Hash())
+		mp.doRemoveTransaction(tx.Hash(), tx.GetSize())
+		for _, input := range tx.Inputs {
+			mp.delInputUTXOList(input)
+		}
+		mp.delRevokedVotes(tx)
+		deleteCount++
+	}
+	return deleteCount
+}
+
+// TxPolicy returns the standardness rules of transactions currently applied
+// by the transaction pool.
+func (mp *TxPool) TxPolicy() config.TxPolicy {
+	mp.RLock()
+	policy := mp.txPolicy
+	mp.RUnlock()
+	return policy
+}
+
+// SetTxPolicy changes the standardness rules of transactions, transactions
+// already in pool will not be checked again.
+func (mp *TxPool) SetTxPolicy(policy config.TxPolicy) {
+	mp.Lock()
+	mp.txPolicy = policy
+	mp.Unlock()
+}
+
 func (mp *TxPool) IsDuplicateSidechainTx(sidechainTxHash Uint256) bool {
 	mp.RLock()
 	_, ok := mp.sidechainTxList[sidechainTxHash]
@@ -590,6 +857,15 @@ func (mp *TxPool) IsDuplicateSidechainTx(sidechainTxHash Uint256) bool {
 	return ok
 }
 
+// GetSidechainTxWithdraw returns the withdraw transaction in pool which
+// included the given side chain transaction, nil if not found.
+func (mp *TxPool) GetSidechainTxWithdraw(sidechainTxHash Uint256) *Transaction {
+	mp.RLock()
+	txn := mp.sidechainTxList[sidechainTxHash]
+	mp.RUnlock()
+	return txn
+}
+
 //check and add to sidechain tx pool
 func (mp *TxPool) verifyDuplicateSidechainTx(txn *Transaction) error {
 	withPayload, ok := txn.Payload.(*payload.WithdrawFromSideChain)
@@ -618,13 +894,14 @@ func (mp *TxPool) verifyDuplicateProducer(ownerPublicKey string,
 	if ok {
 		return errors.New("this producer node in being processed")
 	}
-	_, ok = mp.producerNicknames[nickName]
+	nicknameKey := mp.nicknameKey(nickName)
+	_, ok = mp.producerNicknames[nicknameKey]
 	if ok {
 		return errors.New("this producer nickName in being processed")
 	}
 	mp.addOwnerPublicKey(ownerPublicKey)
 	mp.addNodePublicKey(nodePublicKey)
-	mp.addProducerNickname(nickName)
+	mp.addProducerNickname(nicknameKey)
 	return nil
 }
 
@@ -638,6 +915,21 @@ func (mp *TxPool) verifyDuplicateOwner(ownerPublicKey string) error {
 	return nil
 }
 
+func (mp *TxPool) verifyDuplicateOwnerAndNode(ownerPublicKey string,
+	nodePublicKey string) error {
+	_, ok := mp.ownerPublicKeys[ownerPublicKey]
+	if ok {
+		return errors.New("this producer in being processed")
+	}
+	_, ok = mp.nodePublicKeys[nodePublicKey]
+	if ok {
+		return errors.New("this producer node in being processed")
+	}
+	mp.addOwnerPublicKey(ownerPublicKey)
+	mp.addNodePublicKey(nodePublicKey)
+	return nil
+}
+
 func (mp *TxPool) addOwnerPublicKey(publicKey string) {
 	mp.tempOwnerPublicKeys[publicKey] = struct{}{}
 }
@@ -688,11 +980,12 @@ func (mp *TxPool) verifyDuplicateCRAndNickname(cid Uint168,
 	if err != nil {
 		return err
 	}
-	_, ok := mp.crNicknames[nickname]
+	nicknameKey := mp.nicknameKey(nickname)
+	_, ok := mp.crNicknames[nicknameKey]
 	if ok {
 		return errors.New("this CR nickname in being processed")
 	}
-	mp.addCrNickName(nickname)
+	mp.addCrNickName(nicknameKey)
 	return nil
 }
 
@@ -711,7 +1004,8 @@ func (mp *TxPool) verifyDuplicateCRAndProducer(cid Uint168, code []byte, crNickn
 	if ok {
 		return errors.New("this CR in being processed")
 	}
-	_, ok = mp.crNicknames[crNickname]
+	nicknameKey := mp.nicknameKey(crNickname)
+	_, ok = mp.crNicknames[nicknameKey]
 	if ok {
 		return errors.New("this CR crNickname in being processed")
 	}
@@ -736,7 +1030,7 @@ func (mp *TxPool) verifyDuplicateCRAndProducer(cid Uint168, code []byte, crNickn
 	}
 
 	mp.addCRCID(cid)
-	mp.addCrNickName(crNickname)
+	mp.addCrNickName(nicknameKey)
 
 	return nil
 }
@@ -753,16 +1047,33 @@ func (mp *TxPool) addProducerNickname(key string) {
 	mp.tempProducerNicknames[key] = struct{}{}
 }
 
-func (mp *TxPool) delProducerNickname(key string) {
-	delete(mp.producerNicknames, key)
+// delProducerNickname removes the nickname in both original and folded forms,
+// since the key may be added before NicknameFoldHeight and removed after it.
+func (mp *TxPool) delProducerNickname(nickname string) {
+	delete(mp.producerNicknames, nickname)
+	delete(mp.producerNicknames, config.FoldNickname(nickname))
 }
 
 func (mp *TxPool) addCrNickName(key string) {
 	mp.tempCrNicknames[key] = struct{}{}
 }
 
-func (mp *TxPool) delCrNickname(key string) {
-	delete(mp.crNicknames, key)
+// delCrNickname removes the nickname in both original and folded forms, since
+// the key may be added before NicknameFoldHeight and removed after it.
+func (mp *TxPool) delCrNickname(nickname string) {
+	delete(mp.crNicknames, nickname)
+	delete(mp.crNicknames, config.FoldNickname(nickname))
+}
+
+// nicknameKey returns the key to detect duplicated nicknames in pool, the
+// nicknames are compared after folded since NicknameFoldHeight, the same as
+// the nickname check of block chain.
+func (mp *TxPool) nicknameKey(nickname string) string {
+	height := blockchain.DefaultLedger.Blockchain.GetHeight() + 1
+	if height < mp.chainParams.NicknameFoldHeight {
+		return nickname
+	}
+	return config.FoldNickname(nickname)
 }
 
 func (mp *TxPool) delPublicKeyByCode(code []byte) {
@@ -907,9 +1218,20 @@ func (mp *TxPool) delSidechainTx(hash Uint256) {
 	delete(mp.sidechainTxList, hash)
 }
 
+func (mp *TxPool) delRevokedVotes(txn *Transaction) {
+	p, ok := txn.Payload.(*payload.RevokeVote)
+	if !ok {
+		return
+	}
+	for _, v := range p.Votes {
+		delete(mp.revokedVotes, NewOutPoint(v.TxID, v.Index).ReferKey())
+	}
+}
+
 func (mp *TxPool) MaybeAcceptTransaction(tx *Transaction) error {
 	mp.Lock()
-	code := mp.appendToTxPool(tx)
+	stage, code := mp.appendToTxPool(tx)
+	mp.recordAcceptance(tx, stage, code)
 	mp.Unlock()
 	if code != Success {
 		return code
@@ -917,6 +1239,25 @@ func (mp *TxPool) MaybeAcceptTransaction(tx *Transaction) error {
 	return nil
 }
 
+// TestPackageAccept checks whether the transactions of a package can be
+// accepted by the pool in order, each transaction is checked against the pool
+// and the transactions ahead of it in the package. The pool is left unchanged
+// and the check results are returned in the order of the package.
+func (mp *TxPool) TestPackageAccept(txs []*Transaction) []ErrCode {
+	mp.Lock()
+	defer mp.Unlock()
+
+	state := mp.saveState()
+	defer mp.restoreState(state)
+
+	codes := make([]ErrCode, 0, len(txs))
+	for _, tx := range txs {
+		_, code := mp.appendToTxPool(tx)
+		codes = append(codes, code)
+	}
+	return codes
+}
+
 func (mp *TxPool) RemoveTransaction(txn *Transaction) {
 	mp.Lock()
 	txHash := txn.Hash()
@@ -937,10 +1278,128 @@ func (mp *TxPool) RemoveTransaction(txn *Transaction) {
 }
 
 func (mp *TxPool) doRemoveTransaction(hash Uint256, txSize int) {
+	if tx, ok := mp.txnList[hash]; ok {
+		mp.releaseConflictSlot(tx)
+	}
 	delete(mp.txnList, hash)
+	delete(mp.txnDescs, hash)
 	mp.txnListSize -= txSize
 }
 
+// poolState holds copies of the pool indexes, it's used to restore the pool
+// after testing transactions.
+type poolState struct {
+	txnList           map[Uint256]*Transaction
+	txnDescs          map[Uint256]*TxDesc
+	inputUTXOList     map[string]*Transaction
+	sidechainTxList   map[Uint256]*Transaction
+	ownerPublicKeys   map[string]struct{}
+	nodePublicKeys    map[string]struct{}
+	codes             map[string]struct{}
+	crCIDs            map[Uint168]struct{}
+	specialTxList     map[Uint256]struct{}
+	producerNicknames map[string]struct{}
+	crNicknames       map[string]struct{}
+	revokedVotes      map[string]*Transaction
+	conflictSlots     map[string]Uint256
+	txnListSize       int
+}
+
+func (mp *TxPool) saveState() *poolState {
+	state := &poolState{
+		txnList:           make(map[Uint256]*Transaction, len(mp.txnList)),
+		txnDescs:          make(map[Uint256]*TxDesc, len(mp.txnDescs)),
+		sidechainTxList:   make(map[Uint256]*Transaction, len(mp.sidechainTxList)),
+		inputUTXOList:     copyTxMap(mp.inputUTXOList),
+		ownerPublicKeys:   copySet(mp.ownerPublicKeys),
+		nodePublicKeys:    copySet(mp.nodePublicKeys),
+		codes:             copySet(mp.codes),
+		crCIDs:            make(map[Uint168]struct{}, len(mp.crCIDs)),
+		specialTxList:     make(map[Uint256]struct{}, len(mp.specialTxList)),
+		producerNicknames: copySet(mp.producerNicknames),
+		crNicknames:       copySet(mp.crNicknames),
+		revokedVotes:      copyTxMap(mp.revokedVotes),
+		conflictSlots:     make(map[string]Uint256, len(mp.conflictSlots)),
+		txnListSize:       mp.txnListSize,
+	}
+	for k, v := range mp.txnList {
+		state.txnList[k] = v
+	}
+	for k, v := range mp.txnDescs {
+		state.txnDescs[k] = v
+	}
+	for k, v := range mp.sidechainTxList {
+		state.sidechainTxList[k] = v
+	}
+	for k := range mp.crCIDs {
+		state.crCIDs[k] = struct{}{}
+	}
+	for k := range mp.specialTxList {
+		state.specialTxList[k] = struct{}{}
+	}
+	for k, v := range mp.conflictSlots {
+		state.conflictSlots[k] = v
+	}
+	return state
+}
+
+// Flush removes all transactions from the pool and returns the number of
+// removed transactions.
+func (mp *TxPool) Flush() int {
+	mp.Lock()
+	defer mp.Unlock()
+	count := len(mp.txnList)
+	mp.restoreState(&poolState{
+		txnList:           make(map[Uint256]*Transaction),
+		txnDescs:          make(map[Uint256]*TxDesc),
+		inputUTXOList:     make(map[string]*Transaction),
+		sidechainTxList:   make(map[Uint256]*Transaction),
+		ownerPublicKeys:   make(map[string]struct{}),
+		nodePublicKeys:    make(map[string]struct{}),
+		codes:             make(map[string]struct{}),
+		crCIDs:            make(map[Uint168]struct{}),
+		specialTxList:     make(map[Uint256]struct{}),
+		producerNicknames: make(map[string]struct{}),
+		crNicknames:       make(map[string]struct{}),
+		revokedVotes:      make(map[string]*Transaction),
+		conflictSlots:     make(map[string]Uint256),
+	})
+	return count
+}
+
+func (mp *TxPool) restoreState(state *poolState) {
+	mp.txnList = state.txnList
+	mp.txnDescs = state.txnDescs
+	mp.inputUTXOList = state.inputUTXOList
+	mp.sidechainTxList = state.sidechainTxList
+	mp.ownerPublicKeys = state.ownerPublicKeys
+	mp.nodePublicKeys = state.nodePublicKeys
+	mp.codes = state.codes
+	mp.crCIDs = state.crCIDs
+	mp.specialTxList = state.specialTxList
+	mp.producerNicknames = state.producerNicknames
+	mp.crNicknames = state.crNicknames
+	mp.revokedVotes = state.revokedVotes
+	mp.conflictSlots = state.conflictSlots
+	mp.txnListSize = state.txnListSize
+}
+
+func copyTxMap(src map[string]*Transaction) map[string]*Transaction {
+	dst := make(map[string]*Transaction, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+func copySet(src map[string]struct{}) map[string]struct{} {
+	dst := make(map[string]struct{}, len(src))
+	for k := range src {
+		dst[k] = struct{}{}
+	}
+	return dst
+}
+
 func (mp *TxPool) clearTemp() {
 	mp.tempInputUTXOList = make(map[string]*Transaction)
 	mp.tempSidechainTxList = make(map[Uint256]*Transaction)
@@ -951,6 +1410,8 @@ func (mp *TxPool) clearTemp() {
 	mp.tempSpecialTxList = make(map[Uint256]struct{})
 	mp.tempProducerNicknames = make(map[string]struct{})
 	mp.tempCrNicknames = make(map[string]struct{})
+	mp.tempRevokedVotes = make(map[string]*Transaction)
+	mp.tempConflictSlots = make(map[string]Uint256)
 }
 
 func (mp *TxPool) commitTemp() {
@@ -981,29 +1442,43 @@ func (mp *TxPool) commitTemp() {
 	for k, v := range mp.tempCrNicknames {
 		mp.crNicknames[k] = v
 	}
+	for k, v := range mp.tempRevokedVotes {
+		mp.revokedVotes[k] = v
+	}
+	for k, v := range mp.tempConflictSlots {
+		mp.conflictSlots[k] = v
+	}
 }
 
 func NewTxPool(params *config.Params) *TxPool {
 	return &TxPool{
-		chainParams:         params,
-		inputUTXOList:       make(map[string]*Transaction),
-		txnList:             make(map[Uint256]*Transaction),
-		sidechainTxList:     make(map[Uint256]*Transaction),
-		ownerPublicKeys:     make(map[string]struct{}),
-		nodePublicKeys:      make(map[string]struct{}),
-		codes:               make(map[string]struct{}),
-		crCIDs:              make(map[Uint168]struct{}),
-		specialTxList:       make(map[Uint256]struct{}),
-		producerNicknames:   make(map[string]struct{}),
-		crNicknames:         make(map[string]struct{}),
-		tempInputUTXOList:   make(map[string]*Transaction),
-		tempSidechainTxList: make(map[Uint256]*Transaction),
-		tempOwnerPublicKeys: make(map[string]struct{}),
-		tempNodePublicKeys:  make(map[string]struct{}),
-		tempCodes:           make(map[string]struct{}),
-		tempCrCIDs:          make(map[Uint168]struct{}),
-		tempSpecialTxList:   make(map[Uint256]struct{}),
+		chainParams:           params,
+		txPolicy:              params.TxPolicy,
+		inputUTXOList:         make(map[string]*Transaction),
+		txnList:               make(map[Uint256]*Transaction),
+		txnDescs:              make(map[Uint256]*TxDesc),
+		sidechainTxList:       make(map[Uint256]*Transaction),
+		ownerPublicKeys:       make(map[string]struct{}),
+		nodePublicKeys:        make(map[string]struct{}),
+		codes:                 make(map[string]struct{}),
+		crCIDs:                make(map[Uint168]struct{}),
+		specialTxList:         make(map[Uint256]struct{}),
+		producerNicknames:     make(map[string]struct{}),
+		crNicknames:           make(map[string]struct{}),
+		revokedVotes:          make(map[string]*Transaction),
+		conflictSlots:         make(map[string]Uint256),
+		tempInputUTXOList:     make(map[string]*Transaction),
+		tempSidechainTxList:   make(map[Uint256]*Transaction),
+		tempOwnerPublicKeys:   make(map[string]struct{}),
+		tempNodePublicKeys:    make(map[string]struct{}),
+		tempCodes:             make(map[string]struct{}),
+		tempCrCIDs:            make(map[Uint168]struct{}),
+		tempSpecialTxList:     make(map[Uint256]struct{}),
 		tempProducerNicknames: make(map[string]struct{}),
 		tempCrNicknames:       make(map[string]struct{}),
+		tempRevokedVotes:      make(map[string]*Transaction),
+		tempConflictSlots:     make(map[string]Uint256),
+		rejectedTxs:           newRejectedTxCache(maxRejectedTxs),
+		rejections:            make(map[string]uint64),
 	}
 }
@@ -40,7 +40,8 @@ func TestTxPoolInit(t *testing.T) {
 
 	params := &config.DefaultParams
 	blockchain.FoundationAddress = params.Foundation
-	chainStore, err := blockchain.NewChainStore(test.DataPath, params.GenesisBlock)
+	chainStore, err := blockchain.NewChainStore(test.DataPath,
+		blockchain.LevelDBEngine, params.GenesisBlock)
 	if err != nil {
 		t.Fatal("open LedgerStore err:", err)
 		os.Exit(1)
@@ -746,6 +747,101 @@ func TestTxPool_CleanSubmittedTransactions(t *testing.T) {
 	//}
 }
 
+func TestTxPool_TxDescs(t *testing.T) {
+	pool := NewTxPool(&config.DefaultParams)
+
+	tx := new(types.Transaction)
+	tx.TxType = types.TransferAsset
+	tx.Payload = &payload.TransferAsset{}
+	tx.Attributes = []*types.Attribute{{
+		Usage: types.Nonce,
+		Data:  []byte("txdescs"),
+	}}
+	size := tx.GetSize()
+
+	pool.txnList[tx.Hash()] = tx
+	pool.txnDescs[tx.Hash()] = &TxDesc{Tx: tx, Height: 10, Size: size}
+	pool.txnListSize += size
+
+	descs := pool.TxDescs()
+	assert.Equal(t, 1, len(descs))
+	assert.Equal(t, tx.Hash(), descs[0].Tx.Hash())
+	assert.Equal(t, uint32(10), descs[0].Height)
+	assert.Equal(t, size, pool.GetTxPoolSize())
+
+	pool.doRemoveTransaction(tx.Hash(), size)
+	assert.Equal(t, 0, len(pool.TxDescs()))
+	assert.Equal(t, 0, pool.GetTxPoolSize())
+}
+
+func TestTxPool_Flush(t *testing.T) {
+	pool := NewTxPool(&config.DefaultParams)
+
+	tx := new(types.Transaction)
+	tx.TxType = types.TransferAsset
+	tx.Payload = &payload.TransferAsset{}
+	tx.Attributes = []*types.Attribute{{
+		Usage: types.Nonce,
+		Data:  []byte("flush"),
+	}}
+	size := tx.GetSize()
+
+	pool.txnList[tx.Hash()] = tx
+	pool.txnDescs[tx.Hash()] = &TxDesc{Tx: tx, Height: 10, Size: size}
+	pool.txnListSize += size
+	pool.ownerPublicKeys["owner"] = struct{}{}
+
+	assert.Equal(t, 1, pool.Flush())
+	assert.Equal(t, 0, len(pool.GetTxsInPool()))
+	assert.Equal(t, 0, pool.GetTxPoolSize())
+	assert.NoError(t, pool.verifyDuplicateOwner("owner"))
+	assert.Equal(t, 0, pool.Flush())
+}
+
+func TestTxPool_TestPackageAccept(t *testing.T) {
+	pool := NewTxPool(&config.DefaultParams)
+
+	tx := new(types.Transaction)
+	tx.TxType = types.TransferAsset
+	tx.Payload = &payload.TransferAsset{}
+	tx.Attributes = []*types.Attribute{{
+		Usage: types.Nonce,
+		Data:  []byte("package"),
+	}}
+	size := tx.GetSize()
+	pool.txnList[tx.Hash()] = tx
+	pool.txnDescs[tx.Hash()] = &TxDesc{Tx: tx, Size: size}
+	pool.txnListSize += size
+	pool.ownerPublicKeys["owner"] = struct{}{}
+
+	coinbase := new(types.Transaction)
+	coinbase.TxType = types.CoinBase
+	coinbase.Payload = &payload.CoinBase{}
+
+	codes := pool.TestPackageAccept([]*types.Transaction{coinbase, tx})
+	assert.Equal(t, []errors.ErrCode{errors.ErrIneffectiveCoinbase,
+		errors.ErrTransactionDuplicate}, codes)
+
+	// the pool should be left unchanged
+	assert.Equal(t, 1, len(pool.txnList))
+	assert.Equal(t, size, pool.GetTxPoolSize())
+	_, ok := pool.ownerPublicKeys["owner"]
+	assert.True(t, ok)
+
+	// changes made while testing should be reverted
+	state := pool.saveState()
+	pool.doRemoveTransaction(tx.Hash(), size)
+	delete(pool.ownerPublicKeys, "owner")
+	pool.codes["code"] = struct{}{}
+	pool.restoreState(state)
+	assert.Equal(t, tx, pool.GetTransaction(tx.Hash()))
+	assert.Equal(t, size, pool.GetTxPoolSize())
+	_, ok = pool.ownerPublicKeys["owner"]
+	assert.True(t, ok)
+	_, ok = pool.codes["code"]
+	assert.False(t, ok)
+}
+
 func TestTxPool_End(t *testing.T) {
 	blockchain.DefaultLedger.Store.Close()
 	blockchain.DefaultLedger = initialLedger
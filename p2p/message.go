@@ -43,6 +43,8 @@ const (
 	CmdGetAddr     = "getaddr"
 	CmdAddr        = "addr"
 	CmdGetBlocks   = "getblocks"
+	CmdGetHeaders  = "getheaders"
+	CmdHeaders     = "headers"
 	CmdInv         = "inv"
 	CmdGetData     = "getdata"
 	CmdNotFound    = "notfound"
@@ -88,63 +90,79 @@ type MakeEmptyMessage func(command string) (Message, error)
 // ReadMessage reads, validates, and parse the Message from r for the
 // provided magic.
 func ReadMessage(r net.Conn, magic uint32, makeEmptyMessage MakeEmptyMessage) (Message, error) {
+	_, msg, err := ReadMessageN(r, magic, makeEmptyMessage)
+	return msg, err
+}
+
+// ReadMessageN is the same as ReadMessage except it also returns the number
+// of bytes read.
+func ReadMessageN(r net.Conn, magic uint32, makeEmptyMessage MakeEmptyMessage) (int, Message, error) {
 	// Set read deadline
 	err := r.SetReadDeadline(time.Now().Add(ReadMessageTimeOut))
 	if err != nil {
-		return nil, fmt.Errorf("set read deadline failed %s", err.Error())
+		return 0, nil, fmt.Errorf("set read deadline failed %s", err.Error())
 	}
 
 	// Read message header
 	var headerBytes [HeaderSize]byte
-	if _, err := io.ReadFull(r, headerBytes[:]); err != nil {
-		return nil, err
+	n, err := io.ReadFull(r, headerBytes[:])
+	if err != nil {
+		return n, nil, err
 	}
 
 	// Deserialize message header
 	var hdr Header
 	if err := hdr.Deserialize(headerBytes[:]); err != nil {
-		return nil, ErrInvalidHeader
+		return n, nil, ErrInvalidHeader
 	}
 
 	// Check for messages from wrong network
 	if hdr.Magic != magic {
-		return nil, ErrUnmatchedMagic
+		return n, nil, ErrUnmatchedMagic
 	}
 
 	// Create struct of appropriate message type based on the command.
 	msg, err := makeEmptyMessage(hdr.GetCMD())
 	if err != nil {
-		return nil, err
+		return n, nil, err
 	}
 
 	// Check for message length
 	if hdr.Length > msg.MaxLength() {
-		return nil, ErrMsgSizeExceeded
+		return n, nil, ErrMsgSizeExceeded
 	}
 
 	// Read payload
 	payload := make([]byte, hdr.Length)
-	_, err = io.ReadFull(r, payload[:])
+	read, err := io.ReadFull(r, payload[:])
+	n += read
 	if err != nil {
-		return nil, err
+		return n, nil, err
 	}
 
 	// Verify checksum
 	if err := hdr.Verify(payload); err != nil {
-		return nil, ErrInvalidPayload
+		return n, nil, ErrInvalidPayload
 	}
 
 	// Deserialize message
 	if err := msg.Deserialize(bytes.NewBuffer(payload)); err != nil {
-		return nil, fmt.Errorf("deserialize message %s failed %s", msg.CMD(), err.Error())
+		return n, nil, fmt.Errorf("deserialize message %s failed %s", msg.CMD(), err.Error())
 	}
 
-	return msg, nil
+	return n, msg, nil
 }
 
 // WriteMessage writes a Message to w including the necessary header
 // information.
 func WriteMessage(w net.Conn, magic uint32, msg Message, getDposBlock func(msg Message) (*types.DposBlock, bool)) error {
+	_, err := WriteMessageN(w, magic, msg, getDposBlock)
+	return err
+}
+
+// WriteMessageN is the same as WriteMessage except it also returns the number
+// of bytes written.
+func WriteMessageN(w net.Conn, magic uint32, msg Message, getDposBlock func(msg Message) (*types.DposBlock, bool)) (int, error) {
 	// Serialize message
 	var payload []byte
 	mtx.Lock()
@@ -161,7 +179,7 @@ func WriteMessage(w net.Conn, magic uint32, msg Message, getDposBlock func(msg M
 			buf := new(bytes.Buffer)
 			if err := dposBlock.Serialize(buf); err != nil {
 				mtx.Unlock()
-				return fmt.Errorf("write message serialize message failed %s", err.Error())
+				return 0, fmt.Errorf("write message serialize message failed %s", err.Error())
 			}
 			if len(blockHashesCache) >= BlocksCacheSize {
 				delete(blocksCache[blockHashesCache[0]], blockConfirmsCache[0])
@@ -185,36 +203,37 @@ func WriteMessage(w net.Conn, magic uint32, msg Message, getDposBlock func(msg M
 	if payload == nil {
 		buf := new(bytes.Buffer)
 		if err := msg.Serialize(buf); err != nil {
-			return fmt.Errorf("serialize message failed %s", err.Error())
+			return 0, fmt.Errorf("serialize message failed %s", err.Error())
 		}
 		payload = buf.Bytes()
 	}
 
 	// Enforce maximum overall message payload.
 	if len(payload) > MaxMessagePayload {
-		return ErrMsgSizeExceeded
+		return 0, ErrMsgSizeExceeded
 	}
 
 	// Create message header
 	hdr, err := BuildHeader(magic, msg.CMD(), payload).Serialize()
 	if err != nil {
-		return fmt.Errorf("serialize message header failed %s", err.Error())
+		return 0, fmt.Errorf("serialize message header failed %s", err.Error())
 	}
 
 	// Set write deadline
 	err = w.SetWriteDeadline(time.Now().Add(WriteMessageTimeOut))
 	if err != nil {
-		return fmt.Errorf("set write deadline failed %s", err.Error())
+		return 0, fmt.Errorf("set write deadline failed %s", err.Error())
 	}
 
 	// Write header
-	if _, err = w.Write(hdr); err != nil {
-		return err
+	n, err := w.Write(hdr)
+	if err != nil {
+		return n, err
 	}
 
 	// Write payload
-	_, err = w.Write(payload)
-	return err
+	written, err := w.Write(payload)
+	return n + written, err
 }
 
 func init() {
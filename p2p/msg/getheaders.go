@@ -0,0 +1,29 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package msg
+
+import (
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/p2p"
+)
+
+// Ensure GetHeaders implement p2p.Message interface.
+var _ p2p.Message = (*GetHeaders)(nil)
+
+// GetHeaders requests a headers message with the block headers after the
+// first known block in the locator until the stop hash is reached, or up to
+// MaxBlockHeadersPerMsg headers.  It shares the same payload with GetBlocks.
+type GetHeaders struct {
+	GetBlocks
+}
+
+func NewGetHeaders(locator []*common.Uint256, hashStop common.Uint256) *GetHeaders {
+	return &GetHeaders{GetBlocks{Locator: locator, HashStop: hashStop}}
+}
+
+func (msg *GetHeaders) CMD() string {
+	return p2p.CmdGetHeaders
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package msg
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/elanet/pact"
+	"github.com/elastos/Elastos.ELA/p2p"
+)
+
+// MaxBlockHeadersPerMsg is the maximum number of block headers that can be in
+// a single headers message.
+const MaxBlockHeadersPerMsg = 2000
+
+// Ensure Headers implement p2p.Message interface.
+var _ p2p.Message = (*Headers)(nil)
+
+// Headers delivers the block headers in response to a getheaders message.
+type Headers struct {
+	Headers []common.Serializable
+
+	// newHeader creates an empty header to deserialize into.
+	newHeader func() common.Serializable
+}
+
+// NewHeaders returns a headers message, newHeader is used to create the empty
+// headers when deserializing the message.
+func NewHeaders(newHeader func() common.Serializable) *Headers {
+	return &Headers{newHeader: newHeader}
+}
+
+// AddHeader adds a new block header to the message.
+func (msg *Headers) AddHeader(header common.Serializable) error {
+	if len(msg.Headers)+1 > MaxBlockHeadersPerMsg {
+		str := fmt.Sprintf("too many block headers in message [max %v]",
+			MaxBlockHeadersPerMsg)
+		return common.FuncError("Headers.AddHeader", str)
+	}
+
+	msg.Headers = append(msg.Headers, header)
+	return nil
+}
+
+func (msg *Headers) CMD() string {
+	return p2p.CmdHeaders
+}
+
+func (msg *Headers) MaxLength() uint32 {
+	return pact.MaxBlockSize
+}
+
+func (msg *Headers) Serialize(w io.Writer) error {
+	count := len(msg.Headers)
+	if count > MaxBlockHeadersPerMsg {
+		str := fmt.Sprintf("too many block headers for message "+
+			"[count %v, max %v]", count, MaxBlockHeadersPerMsg)
+		return common.FuncError("Headers.Serialize", str)
+	}
+
+	if err := common.WriteVarUint(w, uint64(count)); err != nil {
+		return err
+	}
+
+	for _, header := range msg.Headers {
+		if err := header.Serialize(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (msg *Headers) Deserialize(r io.Reader) error {
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return err
+	}
+
+	// Limit to max block headers per message.
+	if count > MaxBlockHeadersPerMsg {
+		str := fmt.Sprintf("too many block headers for message "+
+			"[count %v, max %v]", count, MaxBlockHeadersPerMsg)
+		return common.FuncError("Headers.Deserialize", str)
+	}
+
+	msg.Headers = make([]common.Serializable, 0, count)
+	for i := uint64(0); i < count; i++ {
+		header := msg.newHeader()
+		if err := header.Deserialize(r); err != nil {
+			return err
+		}
+		msg.Headers = append(msg.Headers, header)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package nat
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// pmpPort is the port the NAT-PMP gateway listens on.
+	pmpPort = 5351
+
+	// pmpVersion is the version of NAT-PMP protocol.
+	pmpVersion = 0
+
+	// The op codes of requests, the op codes of responses are the request
+	// op codes plus 128.
+	pmpOpExternalAddress = 0
+	pmpOpMapUDP          = 1
+	pmpOpMapTCP          = 2
+
+	// pmpRetries is the max times a request is sent before timeout, the
+	// wait time starts from pmpInitialTimeout and doubles on each retry.
+	pmpRetries        = 4
+	pmpInitialTimeout = 250 * time.Millisecond
+)
+
+// pmpNAT implements the NAT interface by NAT-PMP (RFC 6886).
+type pmpNAT struct {
+	gateway *net.UDPAddr
+}
+
+// DiscoverPMP returns a NAT by NAT-PMP through the gateway, the default
+// gateway of the system is used if the gateway is empty.  The gateway is
+// verified by requesting the external address.
+func DiscoverPMP(gateway string) (NAT, error) {
+	var ip net.IP
+	if gateway != "" {
+		if ip = net.ParseIP(gateway); ip == nil {
+			return nil, fmt.Errorf("invalid NAT-PMP gateway %s", gateway)
+		}
+	} else {
+		var err error
+		if ip, err = defaultGateway(); err != nil {
+			return nil, err
+		}
+	}
+
+	nat := &pmpNAT{gateway: &net.UDPAddr{IP: ip, Port: pmpPort}}
+	if _, err := nat.GetExternalAddress(); err != nil {
+		return nil, fmt.Errorf("NAT-PMP discovery failed, %s", err)
+	}
+	return nat, nil
+}
+
+// request sends the request to the gateway and returns the response with
+// the result code checked.
+func (n *pmpNAT) request(msg []byte, op byte, size int) ([]byte, error) {
+	conn, err := net.DialUDP("udp", nil, n.gateway)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 16)
+	timeout := pmpInitialTimeout
+	for i := 0; i < pmpRetries; i++ {
+		if _, err = conn.Write(msg); err != nil {
+			return nil, err
+		}
+		if err = conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+			return nil, err
+		}
+		timeout *= 2
+
+		var length int
+		length, err = conn.Read(buf)
+		if err != nil {
+			if e, ok := err.(net.Error); ok && e.Timeout() {
+				continue
+			}
+			return nil, err
+		}
+		if length < size || buf[0] != pmpVersion || buf[1] != op+128 {
+			err = errors.New("unexpected NAT-PMP response")
+			continue
+		}
+		if code := binary.BigEndian.Uint16(buf[2:4]); code != 0 {
+			return nil, fmt.Errorf("NAT-PMP result code %d", code)
+		}
+		return buf[:size], nil
+	}
+	return nil, err
+}
+
+// GetExternalAddress implements the NAT interface by requesting the external
+// address from the NAT-PMP gateway.
+func (n *pmpNAT) GetExternalAddress() (net.IP, error) {
+	resp, err := n.request([]byte{pmpVersion, pmpOpExternalAddress},
+		pmpOpExternalAddress, 12)
+	if err != nil {
+		return nil, err
+	}
+	return net.IPv4(resp[8], resp[9], resp[10], resp[11]), nil
+}
+
+// mapPort requests a mapping of the internal port to the external port for
+// lifetime seconds, a zero lifetime deletes the mapping.
+func (n *pmpNAT) mapPort(protocol string, externalPort, internalPort int,
+	lifetime int) (int, error) {
+	var op byte
+	switch protocol {
+	case "tcp":
+		op = pmpOpMapTCP
+	case "udp":
+		op = pmpOpMapUDP
+	default:
+		return 0, fmt.Errorf("unknown protocol %s", protocol)
+	}
+
+	msg := make([]byte, 12)
+	msg[0] = pmpVersion
+	msg[1] = op
+	binary.BigEndian.PutUint16(msg[4:6], uint16(internalPort))
+	binary.BigEndian.PutUint16(msg[6:8], uint16(externalPort))
+	binary.BigEndian.PutUint32(msg[8:12], uint32(lifetime))
+
+	resp, err := n.request(msg, op, 16)
+	if err != nil {
+		return 0, err
+	}
+	return int(binary.BigEndian.Uint16(resp[10:12])), nil
+}
+
+// AddPortMapping implements the NAT interface by requesting a port mapping
+// from the NAT-PMP gateway, the description is not supported by NAT-PMP.
+func (n *pmpNAT) AddPortMapping(protocol string, externalPort,
+	internalPort int, description string, timeout int) (int, error) {
+	return n.mapPort(protocol, externalPort, internalPort, timeout)
+}
+
+// DeletePortMapping implements the NAT interface by requesting the mapping
+// with zero lifetime from the NAT-PMP gateway.
+func (n *pmpNAT) DeletePortMapping(protocol string, externalPort,
+	internalPort int) error {
+	_, err := n.mapPort(protocol, 0, internalPort, 0)
+	return err
+}
+
+// defaultGateway returns the IPv4 default gateway by the routing table of
+// the system, only available on Linux.
+func defaultGateway() (net.IP, error) {
+	file, err := os.Open("/proc/net/route")
+	if err != nil {
+		return nil, errors.New("default gateway not found, the NAT-PMP" +
+			" gateway should be specified")
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// Fields are Iface, Destination, Gateway and so on in hex of
+		// little endian.
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[1] != "00000000" {
+			continue
+		}
+		gateway, err := strconv.ParseUint(fields[2], 16, 32)
+		if err != nil || gateway == 0 {
+			continue
+		}
+		ip := make(net.IP, 4)
+		binary.LittleEndian.PutUint32(ip, uint32(gateway))
+		return ip, nil
+	}
+	return nil, errors.New("default gateway not found, the NAT-PMP" +
+		" gateway should be specified")
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package nat
+
+import (
+	"encoding/binary"
+	"net"
+	"sync"
+	"testing"
+)
+
+// fakeGateway is a NAT-PMP gateway listening on localhost for testing.
+type fakeGateway struct {
+	conn       *net.UDPConn
+	externalIP net.IP
+	resultCode uint16
+
+	// mappings records the lifetime of requested mappings by internal port.
+	mtx      sync.Mutex
+	mappings map[uint16]uint32
+}
+
+// serve answers count requests from the connection.
+func (g *fakeGateway) serve(count int) {
+	buf := make([]byte, 16)
+	for i := 0; i < count; i++ {
+		n, addr, err := g.conn.ReadFromUDP(buf)
+		if err != nil || n < 2 {
+			return
+		}
+		op := buf[1]
+		resp := make([]byte, 16)
+		resp[1] = op + 128
+		binary.BigEndian.PutUint16(resp[2:4], g.resultCode)
+		switch op {
+		case pmpOpExternalAddress:
+			copy(resp[8:12], g.externalIP.To4())
+			resp = resp[:12]
+		case pmpOpMapTCP, pmpOpMapUDP:
+			internal := binary.BigEndian.Uint16(buf[4:6])
+			external := binary.BigEndian.Uint16(buf[6:8])
+			lifetime := binary.BigEndian.Uint32(buf[8:12])
+			g.mtx.Lock()
+			g.mappings[internal] = lifetime
+			g.mtx.Unlock()
+
+			// Map to the next port to ensure the external port returned
+			// by the gateway is used.
+			if external != 0 {
+				external++
+			}
+			binary.BigEndian.PutUint16(resp[8:10], internal)
+			binary.BigEndian.PutUint16(resp[10:12], external)
+			binary.BigEndian.PutUint32(resp[12:16], lifetime)
+		}
+		g.conn.WriteToUDP(resp, addr)
+	}
+}
+
+// lifetime returns the lifetime of the mapping requested for the port.
+func (g *fakeGateway) lifetime(port uint16) (uint32, bool) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	lifetime, ok := g.mappings[port]
+	return lifetime, ok
+}
+
+func newFakeGateway(t *testing.T) *fakeGateway {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP: %v", err)
+	}
+	return &fakeGateway{
+		conn:       conn,
+		externalIP: net.IPv4(203, 0, 113, 7),
+		mappings:   make(map[uint16]uint32),
+	}
+}
+
+// TestPMPNAT ensures the NAT-PMP requests and responses are encoded and
+// decoded as expected.
+func TestPMPNAT(t *testing.T) {
+	gateway := newFakeGateway(t)
+	defer gateway.conn.Close()
+	go gateway.serve(3)
+
+	nat := &pmpNAT{gateway: gateway.conn.LocalAddr().(*net.UDPAddr)}
+	ip, err := nat.GetExternalAddress()
+	if err != nil {
+		t.Fatalf("GetExternalAddress: %v", err)
+	}
+	if !ip.Equal(gateway.externalIP) {
+		t.Errorf("GetExternalAddress: got %v, want %v", ip,
+			gateway.externalIP)
+	}
+
+	port, err := nat.AddPortMapping("tcp", 20338, 20338, "", 1200)
+	if err != nil {
+		t.Fatalf("AddPortMapping: %v", err)
+	}
+	if port != 20339 {
+		t.Errorf("AddPortMapping: got external port %d, want %d", port,
+			20339)
+	}
+	if lifetime, _ := gateway.lifetime(20338); lifetime != 1200 {
+		t.Errorf("AddPortMapping: got lifetime %d, want %d", lifetime,
+			1200)
+	}
+
+	if err := nat.DeletePortMapping("tcp", 20338, 20338); err != nil {
+		t.Fatalf("DeletePortMapping: %v", err)
+	}
+	if lifetime, ok := gateway.lifetime(20338); !ok || lifetime != 0 {
+		t.Errorf("DeletePortMapping: got lifetime %d, want 0", lifetime)
+	}
+
+	if _, err := nat.AddPortMapping("sctp", 20338, 20338, "", 1200); err == nil {
+		t.Errorf("AddPortMapping: expected error on unknown protocol")
+	}
+}
+
+// TestPMPNATResultCode ensures a non-zero result code from the gateway is
+// returned as error.
+func TestPMPNATResultCode(t *testing.T) {
+	gateway := newFakeGateway(t)
+	defer gateway.conn.Close()
+	gateway.resultCode = 2
+	go gateway.serve(1)
+
+	nat := &pmpNAT{gateway: gateway.conn.LocalAddr().(*net.UDPAddr)}
+	if _, err := nat.GetExternalAddress(); err == nil {
+		t.Errorf("GetExternalAddress: expected error on result code")
+	}
+}
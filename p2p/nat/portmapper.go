@@ -0,0 +1,241 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package nat
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// MethodUPnP and MethodNATPMP are the names of NAT traversal methods.
+	MethodUPnP   = "upnp"
+	MethodNATPMP = "natpmp"
+
+	// mappingLifetime is the lifetime in seconds of the port mapping
+	// requested from the gateway.
+	mappingLifetime = 20 * 60
+
+	// renewInterval is the interval to renew the port mapping before the
+	// lifetime expires.
+	renewInterval = 15 * time.Minute
+
+	// retryInterval is the interval to discover the gateway and map the
+	// port again after failed.
+	retryInterval = 5 * time.Minute
+)
+
+// Config is the parameters to create a PortMapper instance.
+type Config struct {
+	// UPnP indicates whether or not to map the port by UPnP.
+	UPnP bool
+
+	// NATPMP indicates whether or not to map the port by NAT-PMP, it's tried
+	// after UPnP if both enabled.
+	NATPMP bool
+
+	// Gateway is the NAT-PMP gateway, the default gateway of the system is
+	// used if empty.
+	Gateway string
+
+	// Port is the local TCP port to be mapped.
+	Port uint16
+
+	// Description is the description of the port mapping.
+	Description string
+
+	// OnMapped will be invoked with the external address when the port is
+	// mapped or the external address changed.
+	OnMapped func(ip net.IP, port uint16)
+}
+
+// Status represents the state of the port mapping.
+type Status struct {
+	// Method is the NAT traversal method in use, empty if no gateway found.
+	Method string
+
+	// InternalPort is the local port to be mapped.
+	InternalPort uint16
+
+	// ExternalIP and ExternalPort are the external address of the mapped
+	// port.
+	ExternalIP   net.IP
+	ExternalPort uint16
+
+	// Mapped indicates whether or not the port is mapped currently.
+	Mapped bool
+
+	// LastError is the error of the last discovery or mapping, empty if
+	// succeeded.
+	LastError string
+
+	// LastUpdate is the time of the last discovery or mapping.
+	LastUpdate time.Time
+}
+
+// PortMapper maps a local port on the NAT gateway by UPnP or NAT-PMP, and
+// renews the mapping periodically.
+type PortMapper struct {
+	cfg      Config
+	discover func() (NAT, string, error)
+
+	nat    NAT
+	mtx    sync.RWMutex
+	status Status
+	quit   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// discoverGateway discovers the NAT gateway by the enabled methods in order.
+func (m *PortMapper) discoverGateway() (NAT, string, error) {
+	var err error
+	if m.cfg.UPnP {
+		var nat NAT
+		if nat, err = DiscoverUPnP(); err == nil {
+			return nat, MethodUPnP, nil
+		}
+		log.Debugf("UPnP discovery failed: %v", err)
+	}
+	if m.cfg.NATPMP {
+		var nat NAT
+		if nat, err = DiscoverPMP(m.cfg.Gateway); err == nil {
+			return nat, MethodNATPMP, nil
+		}
+		log.Debugf("NAT-PMP discovery failed: %v", err)
+	}
+	if err == nil {
+		err = errors.New("no NAT traversal method enabled")
+	}
+	return nil, "", err
+}
+
+// mapPort adds or renews the port mapping and updates the external address.
+func (m *PortMapper) mapPort(method string) error {
+	port := int(m.cfg.Port)
+	externalPort, err := m.nat.AddPortMapping("tcp", port, port,
+		m.cfg.Description, mappingLifetime)
+	if err != nil {
+		return err
+	}
+	ip, err := m.nat.GetExternalAddress()
+	if err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	changed := !m.status.Mapped || !m.status.ExternalIP.Equal(ip) ||
+		m.status.ExternalPort != uint16(externalPort)
+	m.status.Method = method
+	m.status.ExternalIP = ip
+	m.status.ExternalPort = uint16(externalPort)
+	m.status.Mapped = true
+	m.status.LastError = ""
+	m.status.LastUpdate = time.Now()
+	m.mtx.Unlock()
+
+	if changed {
+		log.Infof("Port %d mapped to %s via %s", port,
+			net.JoinHostPort(ip.String(), strconv.Itoa(externalPort)), method)
+		if m.cfg.OnMapped != nil {
+			m.cfg.OnMapped(ip, uint16(externalPort))
+		}
+	}
+	return nil
+}
+
+// setError marks the port as not mapped with the error.
+func (m *PortMapper) setError(err error) {
+	m.mtx.Lock()
+	m.status.Mapped = false
+	m.status.LastError = err.Error()
+	m.status.LastUpdate = time.Now()
+	m.mtx.Unlock()
+}
+
+// mapHandler discovers the gateway and maps the port, then renews the mapping
+// periodically until quit.  It must be run as a goroutine.
+func (m *PortMapper) mapHandler() {
+	var method string
+	timer := time.NewTimer(0)
+out:
+	for {
+		select {
+		case <-timer.C:
+			if m.nat == nil {
+				nat, name, err := m.discover()
+				if err != nil {
+					log.Warnf("Can't discover NAT gateway: %v", err)
+					m.setError(err)
+					timer.Reset(retryInterval)
+					continue
+				}
+				m.nat, method = nat, name
+			}
+
+			if err := m.mapPort(method); err != nil {
+				log.Warnf("Can't map port %d via %s: %v", m.cfg.Port,
+					method, err)
+				m.setError(err)
+
+				// Discover again on the next try since the gateway may be
+				// changed.
+				m.nat = nil
+				timer.Reset(retryInterval)
+				continue
+			}
+			timer.Reset(renewInterval)
+
+		case <-m.quit:
+			break out
+		}
+	}
+	timer.Stop()
+
+	if m.nat != nil {
+		port := int(m.cfg.Port)
+		if err := m.nat.DeletePortMapping("tcp", port, port); err != nil {
+			log.Warnf("Unable to remove port mapping: %v", err)
+		} else {
+			log.Debugf("Successfully removed port mapping")
+		}
+	}
+	m.wg.Done()
+}
+
+// Start begins to map the port in background.
+func (m *PortMapper) Start() {
+	m.wg.Add(1)
+	go m.mapHandler()
+}
+
+// Stop stops renewing and removes the port mapping.
+func (m *PortMapper) Stop() {
+	close(m.quit)
+	m.wg.Wait()
+}
+
+// Status returns the current state of the port mapping.
+func (m *PortMapper) Status() Status {
+	m.mtx.RLock()
+	status := m.status
+	m.mtx.RUnlock()
+	return status
+}
+
+// NewPortMapper creates a PortMapper instance by the config, Start should be
+// called to begin mapping.
+func NewPortMapper(cfg *Config) *PortMapper {
+	m := PortMapper{
+		cfg:    *cfg,
+		status: Status{InternalPort: cfg.Port},
+		quit:   make(chan struct{}),
+	}
+	m.discover = m.discoverGateway
+	return &m
+}
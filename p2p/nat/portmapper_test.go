@@ -0,0 +1,111 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package nat
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mockNAT is a NAT recording the port mappings for testing.
+type mockNAT struct {
+	sync.Mutex
+	ip      net.IP
+	mapped  map[int]int
+	mapErr  error
+	deleted bool
+}
+
+func (n *mockNAT) GetExternalAddress() (net.IP, error) {
+	return n.ip, nil
+}
+
+func (n *mockNAT) AddPortMapping(protocol string, externalPort,
+	internalPort int, description string, timeout int) (int, error) {
+	n.Lock()
+	defer n.Unlock()
+	if n.mapErr != nil {
+		return 0, n.mapErr
+	}
+	n.mapped[internalPort] = externalPort
+	return externalPort, nil
+}
+
+func (n *mockNAT) DeletePortMapping(protocol string, externalPort,
+	internalPort int) error {
+	n.Lock()
+	defer n.Unlock()
+	delete(n.mapped, internalPort)
+	n.deleted = true
+	return nil
+}
+
+// TestPortMapper ensures the port is mapped on start, the status and the
+// callback report the external address, and the mapping is removed on stop.
+func TestPortMapper(t *testing.T) {
+	nat := &mockNAT{ip: net.IPv4(203, 0, 113, 7), mapped: make(map[int]int)}
+	mappedChan := make(chan uint16, 1)
+	m := NewPortMapper(&Config{
+		Port: 20338,
+		OnMapped: func(ip net.IP, port uint16) {
+			mappedChan <- port
+		},
+	})
+	m.discover = func() (NAT, string, error) {
+		return nat, MethodNATPMP, nil
+	}
+
+	m.Start()
+	select {
+	case port := <-mappedChan:
+		if port != 20338 {
+			t.Errorf("OnMapped: got port %d, want %d", port, 20338)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("OnMapped: timeout")
+	}
+
+	status := m.Status()
+	if !status.Mapped || status.Method != MethodNATPMP ||
+		status.InternalPort != 20338 || status.ExternalPort != 20338 ||
+		!status.ExternalIP.Equal(nat.ip) || status.LastError != "" {
+		t.Errorf("Status: unexpected %+v", status)
+	}
+
+	m.Stop()
+	nat.Lock()
+	defer nat.Unlock()
+	if !nat.deleted || len(nat.mapped) != 0 {
+		t.Errorf("Stop: port mapping not removed")
+	}
+}
+
+// TestPortMapperError ensures the error of discovery is reported in the
+// status.
+func TestPortMapperError(t *testing.T) {
+	m := NewPortMapper(&Config{Port: 20338})
+	discovered := make(chan struct{}, 1)
+	m.discover = func() (NAT, string, error) {
+		discovered <- struct{}{}
+		return nil, "", errors.New("no gateway")
+	}
+
+	m.Start()
+	select {
+	case <-discovered:
+	case <-time.After(time.Second):
+		t.Fatalf("discover: timeout")
+	}
+	m.Stop()
+
+	status := m.Status()
+	if status.Mapped || status.LastError != "no gateway" {
+		t.Errorf("Status: unexpected %+v", status)
+	}
+}
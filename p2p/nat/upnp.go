@@ -4,7 +4,7 @@
 // license that can be found in the LICENSE file.
 //
 
-package server
+package nat
 
 // Just enough UPnP to be able to forward ports
 import (
@@ -13,7 +13,6 @@ import (
 	"errors"
 	"net"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -38,9 +37,9 @@ type upnpNAT struct {
 	ourIP      string
 }
 
-// Discover searches the local network for a UPnP router returning a NAT
+// DiscoverUPnP searches the local network for a UPnP router returning a NAT
 // for the network if so, nil if not.
-func Discover() (nat NAT, err error) {
+func DiscoverUPnP() (nat NAT, err error) {
 	ssdp, err := net.ResolveUDPAddr("udp4", "239.255.255.250:1900")
 	if err != nil {
 		return
@@ -72,7 +71,8 @@ func Discover() (nat NAT, err error) {
 			return
 		}
 		var n int
-		n, _, err = socket.ReadFromUDP(answerBytes)
+		var gateway *net.UDPAddr
+		n, gateway, err = socket.ReadFromUDP(answerBytes)
 		if err != nil {
 			continue
 			// socket.Close()
@@ -101,7 +101,7 @@ func Discover() (nat NAT, err error) {
 			return
 		}
 		var ourIP string
-		ourIP, err = getOurIP()
+		ourIP, err = getOurIP(gateway.IP)
 		if err != nil {
 			return
 		}
@@ -186,13 +186,15 @@ func getChildService(d *device, serviceType string) *service {
 	return nil
 }
 
-// getOurIP returns a best guess at what the local IP is.
-func getOurIP() (ip string, err error) {
-	hostname, err := os.Hostname()
+// getOurIP returns the local IP used to reach the gateway, which is the
+// internal client of port mappings.
+func getOurIP(gateway net.IP) (ip string, err error) {
+	conn, err := net.DialUDP("udp4", nil, &net.UDPAddr{IP: gateway, Port: 1})
 	if err != nil {
 		return
 	}
-	return net.LookupCNAME(hostname)
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP.String(), nil
 }
 
 // getServiceURL parses the xml description at the given root url to find the
@@ -204,7 +206,7 @@ func getServiceURL(rootURL string) (url string, err error) {
 	}
 	defer r.Body.Close()
 	if r.StatusCode >= 400 {
-		err = errors.New(string(r.StatusCode))
+		err = errors.New(r.Status)
 		return
 	}
 	var root root
@@ -0,0 +1,111 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package peer
+
+import (
+	"container/list"
+
+	"github.com/elastos/Elastos.ELA/p2p"
+	"github.com/elastos/Elastos.ELA/p2p/msg"
+)
+
+// sendLane indicates which lane of the send queue an outgoing message waits
+// in.
+type sendLane int
+
+const (
+	// priorityLane holds consensus critical messages, such as blocks,
+	// confirms, block inventories and control messages.  Messages in the
+	// priority lane are always sent before those in the bulk lane.
+	priorityLane sendLane = iota
+
+	// bulkLane holds transactions, transaction inventories and other messages
+	// can be delayed.
+	bulkLane
+
+	numLanes
+)
+
+const (
+	// maxPriorityPending is the max number of messages waiting in the
+	// priority lane.
+	maxPriorityPending = 1000
+
+	// maxBulkPending is the max number of messages waiting in the bulk lane,
+	// the oldest bulk messages are dropped on transaction floods.
+	maxBulkPending = 5000
+)
+
+// laneOf returns the lane of the outgoing message.
+func laneOf(m p2p.Message) sendLane {
+	switch m := m.(type) {
+	case *msg.Inv:
+		return invLane(m.InvList)
+	case *msg.GetData:
+		return invLane(m.InvList)
+	case *msg.NotFound:
+		return invLane(m.InvList)
+	}
+
+	switch m.CMD() {
+	case p2p.CmdTx, p2p.CmdMemPool, p2p.CmdMerkleBlock, p2p.CmdAddr,
+		p2p.CmdGetAddr:
+		return bulkLane
+	}
+	return priorityLane
+}
+
+// invLane returns the priority lane if any of the inventories is a block.
+func invLane(invList []*msg.InvVect) sendLane {
+	for _, iv := range invList {
+		switch iv.Type {
+		case msg.InvTypeBlock, msg.InvTypeConfirmedBlock:
+			return priorityLane
+		}
+	}
+	return bulkLane
+}
+
+// laneQueue holds the outgoing messages not passed to outHandler yet in
+// lanes.  Messages keep their order within a lane, and the priority lane is
+// drained before the bulk lane.
+//
+// This is not safe for concurrent access, it should be used by queueHandler
+// only.
+type laneQueue struct {
+	lanes  [numLanes]*list.List
+	limits [numLanes]int
+}
+
+// push adds the message to the back of its lane.  If the lane is full, the
+// oldest message of the lane is removed and returned.
+func (q *laneQueue) push(m outMsg) (dropped *outMsg) {
+	lane := laneOf(m.msg)
+	pending := q.lanes[lane]
+	if pending.Len() >= q.limits[lane] {
+		oldest := pending.Remove(pending.Front()).(outMsg)
+		dropped = &oldest
+	}
+	pending.PushBack(m)
+	return dropped
+}
+
+// pop removes and returns the next message to send.
+func (q *laneQueue) pop() (outMsg, bool) {
+	for _, pending := range q.lanes {
+		if next := pending.Front(); next != nil {
+			return pending.Remove(next).(outMsg), true
+		}
+	}
+	return outMsg{}, false
+}
+
+func newLaneQueue() *laneQueue {
+	return &laneQueue{
+		lanes:  [numLanes]*list.List{list.New(), list.New()},
+		limits: [numLanes]int{maxPriorityPending, maxBulkPending},
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package peer
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/p2p"
+	"github.com/elastos/Elastos.ELA/p2p/msg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLaneOf(t *testing.T) {
+	txInv := msg.NewInv()
+	txInv.AddInvVect(msg.NewInvVect(msg.InvTypeTx, &common.Uint256{1}))
+	assert.Equal(t, bulkLane, laneOf(txInv))
+
+	blockInv := msg.NewInv()
+	blockInv.AddInvVect(msg.NewInvVect(msg.InvTypeTx, &common.Uint256{1}))
+	blockInv.AddInvVect(msg.NewInvVect(msg.InvTypeConfirmedBlock,
+		&common.Uint256{2}))
+	assert.Equal(t, priorityLane, laneOf(blockInv))
+
+	getData := msg.NewGetData()
+	getData.AddInvVect(msg.NewInvVect(msg.InvTypeBlock, &common.Uint256{1}))
+	assert.Equal(t, priorityLane, laneOf(getData))
+
+	assert.Equal(t, bulkLane, laneOf(msg.NewTx(&common.Uint256{})))
+	assert.Equal(t, bulkLane, laneOf(msg.NewGetAddr()))
+	assert.Equal(t, priorityLane, laneOf(msg.NewBlock(&common.Uint256{})))
+	assert.Equal(t, priorityLane, laneOf(msg.NewPing(1)))
+	assert.Equal(t, priorityLane, laneOf(&msg.VerAck{}))
+}
+
+func TestLaneQueue(t *testing.T) {
+	q := newLaneQueue()
+	q.limits[bulkLane] = 2
+
+	newTx := func(b byte) outMsg {
+		return outMsg{msg: msg.NewTx(&common.Uint256{b})}
+	}
+	ping := outMsg{msg: msg.NewPing(1)}
+	block := outMsg{msg: msg.NewBlock(&common.Uint256{})}
+
+	assert.Nil(t, q.push(newTx(1)))
+	assert.Nil(t, q.push(ping))
+	assert.Nil(t, q.push(newTx(2)))
+	assert.Nil(t, q.push(block))
+
+	// the bulk lane is full, the oldest transaction is dropped.
+	dropped := q.push(newTx(3))
+	assert.NotNil(t, dropped)
+	assert.Equal(t, newTx(1).msg, dropped.msg)
+
+	// priority messages are sent first in order.
+	var cmds []string
+	var txs []p2p.Message
+	for m, ok := q.pop(); ok; m, ok = q.pop() {
+		cmds = append(cmds, m.msg.CMD())
+		if m.msg.CMD() == p2p.CmdTx {
+			txs = append(txs, m.msg)
+		}
+	}
+	assert.Equal(t, []string{p2p.CmdPing, p2p.CmdBlock, p2p.CmdTx,
+		p2p.CmdTx}, cmds)
+	assert.Equal(t, []p2p.Message{newTx(2).msg, newTx(3).msg}, txs)
+}
@@ -6,7 +6,6 @@
 package peer
 
 import (
-	"container/list"
 	"errors"
 	"fmt"
 	"io"
@@ -91,6 +90,10 @@ type StatsSnap struct {
 	LastBlock      uint32
 	LastPingTime   time.Time
 	LastPingMicros int64
+	BytesSent      uint64
+	BytesRecv      uint64
+	MsgBytesSent   map[string]uint64
+	MsgBytesRecv   map[string]uint64
 }
 
 // MessageFunc is a message handler in peer's configuration
@@ -152,10 +155,12 @@ type HostToNetAddrFunc func(host string, port uint16, services uint64) (*p2p.Net
 
 type Peer struct {
 	// The following variables must only be used atomically.
-	lastRecv   int64
-	lastSend   int64
-	connected  int32
-	disconnect int32
+	bytesReceived uint64
+	bytesSent     uint64
+	lastRecv      int64
+	lastSend      int64
+	connected     int32
+	disconnect    int32
 
 	conn net.Conn
 
@@ -183,8 +188,10 @@ type Peer struct {
 	timeConnected  time.Time
 	startingHeight uint32
 	height         uint32
-	lastPingTime   time.Time // Time we sent last ping.
-	lastPingMicros int64     // Time for last ping to return.
+	lastPingTime   time.Time         // Time we sent last ping.
+	lastPingMicros int64             // Time for last ping to return.
+	msgBytesSent   map[string]uint64 // Bytes sent by message command.
+	msgBytesRecv   map[string]uint64 // Bytes received by message command.
 
 	stallControl  chan StallControlMsg
 	outputQueue   chan outMsg
@@ -260,12 +267,25 @@ func (p *Peer) StatsSnapshot() *StatsSnap {
 		LastBlock:      p.height,
 		LastPingMicros: p.lastPingMicros,
 		LastPingTime:   p.lastPingTime,
+		BytesSent:      atomic.LoadUint64(&p.bytesSent),
+		BytesRecv:      atomic.LoadUint64(&p.bytesReceived),
+		MsgBytesSent:   copyMsgBytes(p.msgBytesSent),
+		MsgBytesRecv:   copyMsgBytes(p.msgBytesRecv),
 	}
 
 	p.statsMtx.RUnlock()
 	return statsSnap
 }
 
+// copyMsgBytes returns a copy of the bytes by message command map.
+func copyMsgBytes(src map[string]uint64) map[string]uint64 {
+	dst := make(map[string]uint64, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
 // ID returns the peer id.
 //
 // This function is safe for concurrent access.
@@ -527,7 +547,13 @@ func (p *Peer) makeEmptyMessage(cmd string) (p2p.Message, error) {
 }
 
 func (p *Peer) readMessage() (p2p.Message, error) {
-	msg, err := p2p.ReadMessage(p.conn, p.cfg.Magic, p.makeEmptyMessage)
+	n, msg, err := p2p.ReadMessageN(p.conn, p.cfg.Magic, p.makeEmptyMessage)
+	atomic.AddUint64(&p.bytesReceived, uint64(n))
+	if msg != nil {
+		p.statsMtx.Lock()
+		p.msgBytesRecv[msg.CMD()] += uint64(n)
+		p.statsMtx.Unlock()
+	}
 	// Use closures to log expensive operations so they are only run when
 	// the logging level requires it.
 	log.Debugf("%v", newLogClosure(func() string {
@@ -564,7 +590,7 @@ func (p *Peer) writeMessage(m p2p.Message) error {
 	}))
 
 	// Write the message to the peer.
-	return p2p.WriteMessage(p.conn, p.cfg.Magic, m,
+	n, err := p2p.WriteMessageN(p.conn, p.cfg.Magic, m,
 		func(message p2p.Message) (*types.DposBlock, bool) {
 			msgBlock, ok := message.(*msg.Block)
 			if !ok {
@@ -573,6 +599,13 @@ func (p *Peer) writeMessage(m p2p.Message) error {
 			dposBlock, ok := msgBlock.Serializable.(*types.DposBlock)
 			return dposBlock, ok
 		})
+	if n > 0 {
+		atomic.AddUint64(&p.bytesSent, uint64(n))
+		p.statsMtx.Lock()
+		p.msgBytesSent[m.CMD()] += uint64(n)
+		p.statsMtx.Unlock()
+	}
+	return err
 }
 
 // shouldHandleIOError returns whether or not the passed error, which is
@@ -736,9 +769,11 @@ out:
 // queueHandler handles the queuing of outgoing data for the peer. This runs as
 // a muxer for various sources of input so we can ensure that server and peer
 // handlers will not block on us sending a message.  That data is then passed on
-// to outHandler to be actually written.
+// to outHandler to be actually written.  Pending messages wait in the lanes
+// of their priorities, so consensus critical messages are not delayed by
+// transaction floods.
 func (p *Peer) queueHandler() {
-	pendingMsgs := list.New()
+	pendingMsgs := newLaneQueue()
 
 	// We keep the waiting flag so that we know if we have a message queued
 	// to the outHandler or not.  We could use the presence of a head of
@@ -750,11 +785,15 @@ func (p *Peer) queueHandler() {
 	waiting := false
 
 	// To avoid duplication below.
-	queuePacket := func(msg outMsg, list *list.List, waiting bool) bool {
+	queuePacket := func(msg outMsg, pending *laneQueue, waiting bool) bool {
 		if !waiting {
 			p.sendQueue <- msg
-		} else {
-			list.PushBack(msg)
+		} else if dropped := pending.push(msg); dropped != nil {
+			log.Debugf("Send queue of %s is full, dropped %s message", p,
+				dropped.msg.CMD())
+			if dropped.doneChan != nil {
+				dropped.doneChan <- struct{}{}
+			}
 		}
 		// we are always waiting now.
 		return true
@@ -770,16 +809,15 @@ out:
 		case <-p.sendDoneQueue:
 			// No longer waiting if there are no more messages
 			// in the pending messages queue.
-			next := pendingMsgs.Front()
-			if next == nil {
+			next, ok := pendingMsgs.pop()
+			if !ok {
 				waiting = false
 				continue
 			}
 
 			// Notify the outHandler about the next item to
 			// asynchronously send.
-			val := pendingMsgs.Remove(next)
-			p.sendQueue <- val.(outMsg)
+			p.sendQueue <- next
 
 		case <-p.quit:
 			break out
@@ -788,9 +826,7 @@ out:
 
 	// Drain any wait channels before we go away so we don't leave something
 	// waiting for us.
-	for e := pendingMsgs.Front(); e != nil; e = pendingMsgs.Front() {
-		val := pendingMsgs.Remove(e)
-		msg := val.(outMsg)
+	for msg, ok := pendingMsgs.pop(); ok; msg, ok = pendingMsgs.pop() {
 		if msg.doneChan != nil {
 			msg.doneChan <- struct{}{}
 		}
@@ -1135,6 +1171,8 @@ func newPeerBase(origCfg *Config, inbound bool) *Peer {
 		cfg:             cfg, // Copy so caller can't mutate.
 		services:        cfg.Services,
 		protocolVersion: cfg.ProtocolVersion,
+		msgBytesSent:    make(map[string]uint64),
+		msgBytesRecv:    make(map[string]uint64),
 	}
 	p.AddMessageFunc(cfg.MessageFunc)
 	return &p
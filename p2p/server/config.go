@@ -6,8 +6,10 @@
 package server
 
 import (
+	"fmt"
 	"net"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/elastos/Elastos.ELA/p2p"
@@ -50,6 +52,14 @@ type Config struct {
 	// Use UPnP to map our listening port outside of NAT
 	Upnp bool
 
+	// Use NAT-PMP to map our listening port outside of NAT, it's tried after
+	// UPnP if both enabled.
+	NATPMP bool
+
+	// NATGateway is the NAT-PMP gateway, the default gateway of the system is
+	// used if empty.
+	NATGateway string
+
 	// DefaultPort defines the default peer-to-peer port for the network.
 	DefaultPort uint16
 
@@ -77,6 +87,11 @@ type Config struct {
 	// IP networks or IPs that will not be banned. (eg. 192.168.1.0/24 or ::1)
 	Whitelists []*net.IPNet
 
+	// AllowList restricts the inbound and outbound peers to the IP networks
+	// or IPs, all other connections are rejected and DNS seeding is disabled.
+	// No restriction if empty.
+	AllowList []*net.IPNet
+
 	// TargetOutbound is the number of outbound network connections to maintain.
 	// Defaults to 8.
 	TargetOutbound int
@@ -143,6 +158,59 @@ func (cfg *Config) inWhitelist(addr net.Addr) bool {
 	return false
 }
 
+// inAllowList returns whether the IP address is allowed to connect by the
+// allow list, all IP addresses are allowed if the allow list is empty.
+func (cfg *Config) inAllowList(addr net.Addr) bool {
+	if len(cfg.AllowList) == 0 {
+		return true
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		log.Warnf("Unable to SplitHostPort on '%s': %v", addr, err)
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		log.Warnf("Unable to parse IP '%s'", addr)
+		return false
+	}
+
+	for _, ipnet := range cfg.AllowList {
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseIPNets parses the IP networks in CIDR notation or IPs into IP networks.
+func ParseIPNets(addrs []string) ([]*net.IPNet, error) {
+	ipnets := make([]*net.IPNet, 0, len(addrs))
+	for _, addr := range addrs {
+		if !strings.Contains(addr, "/") {
+			ip := net.ParseIP(addr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %s", addr)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			ipnets = append(ipnets, &net.IPNet{IP: ip,
+				Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipnet, err := net.ParseCIDR(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP network %s", addr)
+		}
+		ipnets = append(ipnets, ipnet)
+	}
+	return ipnets, nil
+}
+
 func dialTimeout(addr net.Addr) (net.Conn, error) {
 	return net.DialTimeout(addr.Network(), addr.String(), defaultConnectTimeout)
 }
@@ -0,0 +1,51 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package server
+
+import (
+	"testing"
+)
+
+// TestConfigInAllowList ensures the allow list parsed from IPs and IP networks
+// allows the expected addresses only.
+func TestConfigInAllowList(t *testing.T) {
+	cfg := &Config{}
+	if !cfg.inAllowList(simpleAddr{net: "tcp", addr: "8.8.8.8:20338"}) {
+		t.Fatal("all addresses should be allowed by empty allow list")
+	}
+
+	if _, err := ParseIPNets([]string{"10.0.0.256"}); err == nil {
+		t.Fatal("invalid IP address should not be parsed")
+	}
+	if _, err := ParseIPNets([]string{"10.0.0.0/33"}); err == nil {
+		t.Fatal("invalid IP network should not be parsed")
+	}
+
+	allowList, err := ParseIPNets([]string{"10.0.0.0/8", "192.168.1.10",
+		"::1"})
+	if err != nil {
+		t.Fatalf("ParseIPNets failed: %v", err)
+	}
+	cfg.AllowList = allowList
+
+	tests := []struct {
+		addr    string
+		allowed bool
+	}{
+		{"10.1.2.3:20338", true},
+		{"192.168.1.10:20338", true},
+		{"192.168.1.11:20338", false},
+		{"[::1]:20338", true},
+		{"8.8.8.8:20338", false},
+	}
+	for _, test := range tests {
+		addr := simpleAddr{net: "tcp", addr: test.addr}
+		if allowed := cfg.inAllowList(addr); allowed != test.allowed {
+			t.Errorf("inAllowList(%s) = %v, want %v", test.addr, allowed,
+				test.allowed)
+		}
+	}
+}
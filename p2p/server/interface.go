@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/elastos/Elastos.ELA/p2p"
+	"github.com/elastos/Elastos.ELA/p2p/nat"
 	"github.com/elastos/Elastos.ELA/p2p/peer"
 )
 
@@ -92,9 +93,16 @@ type IServer interface {
 	// peers.
 	PersistentPeers() []IPeer
 
+	// SetMaxPeers changes the max number of inbound and outbound peers.
+	SetMaxPeers(max int)
+
 	// BroadcastMessage sends the provided message to all currently
 	// connected peers.
 	BroadcastMessage(msg p2p.Message, exclPeers ...*serverPeer)
+
+	// NATStatus returns the state of the port mapping on the NAT gateway,
+	// nil if the NAT traversal is not enabled.
+	NATStatus() *nat.Status
 }
 
 // NewServer return a server instance that implement the IServer interface.
@@ -72,7 +72,11 @@ func (s *seed) GetAddress() (net.Addr, error) {
 		}
 
 		addrString := addrmgr.NetAddressKey(addr.NetAddress())
-		return addrStringToNetAddr(addrString)
+		netAddr, err := addrStringToNetAddr(addrString)
+		if err != nil || !s.cfg.inAllowList(netAddr) {
+			continue
+		}
+		return netAddr, nil
 	}
 
 	// Trigger DNS seeding if their are no valid address.
@@ -157,6 +161,12 @@ func (s *seed) discover(host string, addrChan chan []*p2p.NetAddress,
 
 // FromDNS uses DNS seeding to populate the address manager with peers.
 func (s *seed) fromDNS() {
+	// Seeds are connected by the peer-to-peer protocol, do not connect them
+	// in the allow list mode.
+	if len(s.cfg.AllowList) > 0 {
+		return
+	}
+
 	for _, host := range s.cfg.DNSSeeds {
 		// Do not seeding a DNS if a previous request is not finished.
 		if _, ok := s.seeding.LoadOrStore(host, host); ok {
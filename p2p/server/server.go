@@ -22,6 +22,7 @@ import (
 	"github.com/elastos/Elastos.ELA/p2p/addrmgr"
 	"github.com/elastos/Elastos.ELA/p2p/connmgr"
 	"github.com/elastos/Elastos.ELA/p2p/msg"
+	"github.com/elastos/Elastos.ELA/p2p/nat"
 	"github.com/elastos/Elastos.ELA/p2p/peer"
 )
 
@@ -127,7 +128,7 @@ type server struct {
 	broadcast   chan broadcastMsg
 	wg          sync.WaitGroup
 	quit        chan struct{}
-	nat         NAT
+	portMapper  *nat.PortMapper
 }
 
 // IPeer extends the peer to maintain state shared by the server.
@@ -590,6 +591,11 @@ type removeNodeMsg struct {
 	reply chan error
 }
 
+type setMaxPeersMsg struct {
+	max   int
+	reply chan struct{}
+}
+
 // handleQuery is the central handler for all queries and commands from other
 // goroutines related to peer state.
 func (s *server) handleQuery(state *peerState, querymsg interface{}) {
@@ -613,6 +619,13 @@ func (s *server) handleQuery(state *peerState, querymsg interface{}) {
 		})
 		msg.reply <- peers
 
+	case setMaxPeersMsg:
+		// MaxPeers is only accessed by the peer handler, so it is safe to
+		// change it here.  Connected peers exceeding the new limit are kept
+		// until they are disconnected.
+		s.cfg.MaxPeers = msg.max
+		msg.reply <- struct{}{}
+
 	case connectNodeMsg:
 		// TODO: duplicate oneshots?
 		// Limit max number of total peers.
@@ -774,6 +787,13 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 // instance, associates it with the connection, and starts a goroutine to wait
 // for disconnection.
 func (s *server) inboundPeerConnected(conn net.Conn) {
+	if !s.cfg.inAllowList(conn.RemoteAddr()) {
+		log.Infof("Rejected inbound peer %s not in allow list",
+			conn.RemoteAddr())
+		conn.Close()
+		return
+	}
+
 	sp := newServerPeer(s, false)
 	sp.isWhitelisted = s.cfg.inWhitelist(conn.RemoteAddr())
 	sp.Peer = peer.NewInboundPeer(newPeerConfig(sp))
@@ -939,9 +959,8 @@ func (s *server) Start() {
 	s.wg.Add(1)
 	go s.peerHandler()
 
-	if s.nat != nil {
-		s.wg.Add(1)
-		go s.upnpUpdateThread()
+	if s.portMapper != nil {
+		s.portMapper.Start()
 	}
 }
 
@@ -956,11 +975,26 @@ func (s *server) Stop() error {
 
 	log.Warnf("server shutting down")
 
+	// Remove the port mapping on the NAT gateway.
+	if s.portMapper != nil {
+		s.portMapper.Stop()
+	}
+
 	// Signal the remaining goroutines to quit.
 	close(s.quit)
 	return nil
 }
 
+// NATStatus returns the state of the port mapping on the NAT gateway, nil if
+// the NAT traversal is not enabled.
+func (s *server) NATStatus() *nat.Status {
+	if s.portMapper == nil {
+		return nil
+	}
+	status := s.portMapper.Status()
+	return &status
+}
+
 // WaitForShutdown blocks until the main listener and peer handlers are stopped.
 func (s *server) WaitForShutdown() {
 	s.wg.Wait()
@@ -1050,54 +1084,6 @@ func parseListeners(addrs []string) ([]net.Addr, error) {
 	return netAddrs, nil
 }
 
-func (s *server) upnpUpdateThread() {
-	// Go off immediately to prevent code duplication, thereafter we renew
-	// lease every 15 minutes.
-	timer := time.NewTimer(0 * time.Second)
-	lport := s.cfg.DefaultPort
-	first := true
-out:
-	for {
-		select {
-		case <-timer.C:
-			// XXX this assumes timeout is in seconds.
-			listenPort, err := s.nat.AddPortMapping("tcp", int(lport), int(lport),
-				"listen port", 20*60)
-			if err != nil {
-				log.Warnf("can't add UPnP port mapping: %v", err)
-			}
-			if first && err == nil {
-				externalip, err := s.nat.GetExternalAddress()
-				if err != nil {
-					log.Warnf("UPnP can't get external address: %v", err)
-					continue out
-				}
-				na := p2p.NewNetAddressIPPort(externalip, uint16(listenPort),
-					s.cfg.Services)
-				err = s.addrManager.AddLocalAddress(na, addrmgr.UpnpPrio)
-				if err != nil {
-					// XXX DeletePortMapping?
-				}
-				log.Warnf("Successfully bound via UPnP to %s", addrmgr.NetAddressKey(na))
-				first = false
-			}
-			timer.Reset(time.Minute * 15)
-		case <-s.quit:
-			break out
-		}
-	}
-
-	timer.Stop()
-
-	if err := s.nat.DeletePortMapping("tcp", int(lport), int(lport)); err != nil {
-		log.Warnf("unable to remove UPnP port mapping: %v", err)
-	} else {
-		log.Debugf("successfully disestablished UPnP port mapping")
-	}
-
-	s.wg.Done()
-}
-
 // Connect adds the provided address as a new outbound peer.  The permanent flag
 // indicates whether or not to make the peer persistent and reconnect if the
 // connection is lost.  Attempting to connect to an already existing peer will
@@ -1210,6 +1196,16 @@ func (s *server) PersistentPeers() []IPeer {
 	return peers
 }
 
+// SetMaxPeers changes the max number of inbound and outbound peers.
+//
+// This function is safe for concurrent access and is part of the
+// IServer interface implementation.
+func (s *server) SetMaxPeers(max int) {
+	replyChan := make(chan struct{})
+	s.query <- setMaxPeersMsg{max: max, reply: replyChan}
+	<-replyChan
+}
+
 // NewServer returns a new server instance by the given config.
 // Use start to begin accepting connections from peers.
 func newServer(origCfg *Config) (*server, error) {
@@ -1228,10 +1224,10 @@ func newServer(origCfg *Config) (*server, error) {
 	amgr := addrmgr.New(dataDir, cfg.NAFilter)
 
 	var listeners []net.Listener
-	var nat NAT
+	var portMapper *nat.PortMapper
 	if !cfg.DisableListen {
 		var err error
-		listeners, nat, err = initListeners(amgr, cfg)
+		listeners, portMapper, err = initListeners(amgr, cfg)
 		if err != nil {
 			return nil, err
 		}
@@ -1248,7 +1244,7 @@ func newServer(origCfg *Config) (*server, error) {
 		query:       make(chan interface{}),
 		broadcast:   make(chan broadcastMsg, cfg.MaxPeers),
 		quit:        make(chan struct{}),
-		nat:         nat,
+		portMapper:  portMapper,
 	}
 
 	// Create the DNS seeds provider.
@@ -1264,7 +1260,7 @@ func newServer(origCfg *Config) (*server, error) {
 		OnAccept:       s.inboundPeerConnected,
 		RetryDuration:  connectionRetryInterval,
 		TargetOutbound: uint32(targetOutbound),
-		Dial:           dialTimeout,
+		Dial:           s.dial,
 		OnConnection:   s.outboundPeerConnected,
 		GetNewAddress:  seeds.GetAddress,
 	})
@@ -1276,10 +1272,19 @@ func newServer(origCfg *Config) (*server, error) {
 	return &s, nil
 }
 
+// dial connects to the address if it is allowed by the allow list.
+func (s *server) dial(addr net.Addr) (net.Conn, error) {
+	if !s.cfg.inAllowList(addr) {
+		return nil, fmt.Errorf("peer %s not in allow list", addr)
+	}
+	return dialTimeout(addr)
+}
+
 // initListeners initializes the configured net listeners and adds any bound
-// addresses to the address manager. Returns the listeners and a NAT interface,
-// which is non-nil if UPnP is in use.
-func initListeners(amgr *addrmgr.AddrManager, cfg Config) ([]net.Listener, NAT, error) {
+// addresses to the address manager. Returns the listeners and a port mapper,
+// which is non-nil if UPnP or NAT-PMP is in use.
+func initListeners(amgr *addrmgr.AddrManager, cfg Config) ([]net.Listener,
+	*nat.PortMapper, error) {
 	// Listen for TCP connections at the configured addresses
 	netAddrs, err := parseListeners(cfg.ListenAddrs)
 	if err != nil {
@@ -1296,7 +1301,7 @@ func initListeners(amgr *addrmgr.AddrManager, cfg Config) ([]net.Listener, NAT,
 		listeners = append(listeners, listener)
 	}
 
-	var nat NAT
+	var portMapper *nat.PortMapper
 	if len(cfg.ExternalIPs) != 0 {
 		for _, sip := range cfg.ExternalIPs {
 			eport := cfg.DefaultPort
@@ -1324,13 +1329,22 @@ func initListeners(amgr *addrmgr.AddrManager, cfg Config) ([]net.Listener, NAT,
 			}
 		}
 	} else {
-		if cfg.Upnp {
-			var err error
-			nat, err = Discover()
-			if err != nil {
-				log.Warnf("Can't discover upnp: %v", err)
-			}
-			// nil nat here is fine, just means no upnp on network.
+		if cfg.Upnp || cfg.NATPMP {
+			portMapper = nat.NewPortMapper(&nat.Config{
+				UPnP:        cfg.Upnp,
+				NATPMP:      cfg.NATPMP,
+				Gateway:     cfg.NATGateway,
+				Port:        cfg.DefaultPort,
+				Description: "listen port",
+				OnMapped: func(ip net.IP, port uint16) {
+					na := p2p.NewNetAddressIPPort(ip, port, cfg.Services)
+					err := amgr.AddLocalAddress(na, addrmgr.UpnpPrio)
+					if err != nil {
+						log.Warnf("Skipping mapped address %s: %v",
+							addrmgr.NetAddressKey(na), err)
+					}
+				},
+			})
 		}
 
 		// Add bound addresses to address manager to be advertised to peers.
@@ -1343,7 +1357,7 @@ func initListeners(amgr *addrmgr.AddrManager, cfg Config) ([]net.Listener, NAT,
 		}
 	}
 
-	return listeners, nat, nil
+	return listeners, portMapper, nil
 }
 
 // addrStringToNetAddr takes an address in the form of 'host:port' and returns
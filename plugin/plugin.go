@@ -0,0 +1,255 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+/*
+Package plugin lets integrators observe the blocks and transactions processed
+by the node without forking it, for example to build custom indexes.
+
+A plugin is a package implementing the Plugin interface, which registers
+itself in init by calling Register.  Plugins are compiled into the node by a
+file of the main package with a build tag, so they are not included in the
+default build:
+
+	// +build myindex
+
+	package main
+
+	import _ "example.com/myindex"
+
+Then build the node with "go build -tags myindex".  All compiled plugins are
+started after the block chain is initialized, and each of them receives the
+events in order on its own goroutine, so a slow plugin does not delay the
+others.  Events are never dropped, a plugin falling behind more than
+QueueSize events slows down the node until it catches up.
+*/
+package plugin
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA/blockchain"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/events"
+	"github.com/elastos/Elastos.ELA/mempool"
+)
+
+// QueueSize is the max number of events waiting to be handled by a plugin.
+const QueueSize = 1024
+
+// Context holds the node components a plugin can access.  Plugins should
+// only read from them.
+type Context struct {
+	Chain     *blockchain.BlockChain
+	TxMemPool *mempool.TxPool
+	Params    *config.Params
+
+	// DataDir is the directory for the plugin to keep its own data, it is
+	// not created by the node.
+	DataDir string
+}
+
+// Plugin observes the blocks and transactions processed by the node.  The
+// transactions are passed with decoded payloads, which can be asserted to
+// the payload types by TxType.  Methods are called from a goroutine of the
+// plugin, they should not modify the blocks or transactions.
+type Plugin interface {
+	// Name returns the unique name of the plugin.
+	Name() string
+
+	// Start is called when the node starts, returning an error stops the
+	// node.
+	Start(ctx *Context) error
+
+	// Stop is called when the node stops.
+	Stop()
+
+	// OnBlockConnected is called when a block is connected to the main
+	// chain.
+	OnBlockConnected(block *types.Block)
+
+	// OnBlockDisconnected is called when a block is disconnected from the
+	// main chain by a reorganization.
+	OnBlockDisconnected(block *types.Block)
+
+	// OnTransactionAccepted is called when a transaction is accepted into
+	// the mem pool.
+	OnTransactionAccepted(tx *types.Transaction)
+}
+
+var registry struct {
+	mtx        sync.Mutex
+	plugins    map[string]Plugin
+	runners    []*runner
+	subscribed bool
+}
+
+// Register registers a plugin, it panics if a plugin with the same name has
+// been registered.  It should be called in init of the plugin package.
+func Register(p Plugin) {
+	registry.mtx.Lock()
+	defer registry.mtx.Unlock()
+	if registry.plugins == nil {
+		registry.plugins = make(map[string]Plugin)
+	}
+	if _, ok := registry.plugins[p.Name()]; ok {
+		panic(fmt.Sprintf("plugin %s registered twice", p.Name()))
+	}
+	registry.plugins[p.Name()] = p
+}
+
+// Registered returns the names of registered plugins in order.
+func Registered() []string {
+	registry.mtx.Lock()
+	defer registry.mtx.Unlock()
+	names := make([]string, 0, len(registry.plugins))
+	for name := range registry.plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Start starts the registered plugins and subscribes the chain events for
+// them.  The data directory of each plugin is named after the plugin under
+// ctx.DataDir.
+func Start(ctx *Context) error {
+	registry.mtx.Lock()
+	defer registry.mtx.Unlock()
+	if len(registry.runners) > 0 {
+		return fmt.Errorf("plugins already started")
+	}
+
+	names := make([]string, 0, len(registry.plugins))
+	for name := range registry.plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := registry.plugins[name]
+		pluginCtx := *ctx
+		pluginCtx.DataDir = filepath.Join(ctx.DataDir, name)
+		if err := p.Start(&pluginCtx); err != nil {
+			stopRunners()
+			return fmt.Errorf("start plugin %s failed, %s", name, err)
+		}
+		r := newRunner(p)
+		go r.run()
+		registry.runners = append(registry.runners, r)
+		log.Infof("plugin %s started", name)
+	}
+
+	if len(registry.runners) > 0 && !registry.subscribed {
+		events.Subscribe(dispatch)
+		registry.subscribed = true
+	}
+	return nil
+}
+
+// Stop stops the started plugins.
+func Stop() {
+	registry.mtx.Lock()
+	defer registry.mtx.Unlock()
+	stopRunners()
+}
+
+func stopRunners() {
+	for _, r := range registry.runners {
+		r.stop()
+		log.Infof("plugin %s stopped", r.plugin.Name())
+	}
+	registry.runners = nil
+}
+
+func dispatch(e *events.Event) {
+	switch e.Type {
+	case events.ETBlockConnected, events.ETBlockDisconnected,
+		events.ETTransactionAccepted:
+	default:
+		return
+	}
+
+	// Release the lock before pushing, so that a plugin falling behind does
+	// not block Stop.
+	registry.mtx.Lock()
+	runners := registry.runners
+	registry.mtx.Unlock()
+	for _, r := range runners {
+		r.push(e)
+	}
+}
+
+// runner delivers events to a plugin on its own goroutine.
+type runner struct {
+	plugin Plugin
+	queue  chan *events.Event
+	quit   chan struct{}
+	done   chan struct{}
+}
+
+func newRunner(p Plugin) *runner {
+	return &runner{
+		plugin: p,
+		queue:  make(chan *events.Event, QueueSize),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+func (r *runner) push(e *events.Event) {
+	select {
+	case r.queue <- e:
+		return
+	default:
+	}
+
+	log.Warnf("plugin %s falls behind %d events", r.plugin.Name(),
+		QueueSize)
+	select {
+	case r.queue <- e:
+	case <-r.quit:
+	}
+}
+
+func (r *runner) run() {
+	defer close(r.done)
+	for {
+		select {
+		case e := <-r.queue:
+			r.handle(e)
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+// handle delivers an event to the plugin, a panic of the plugin is recovered
+// so that it will not crash the node.
+func (r *runner) handle(e *events.Event) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Errorf("plugin %s panic on %s, %v", r.plugin.Name(),
+				e.Type, err)
+		}
+	}()
+
+	switch e.Type {
+	case events.ETBlockConnected:
+		r.plugin.OnBlockConnected(e.Data.(*types.Block))
+	case events.ETBlockDisconnected:
+		r.plugin.OnBlockDisconnected(e.Data.(*types.Block))
+	case events.ETTransactionAccepted:
+		r.plugin.OnTransactionAccepted(e.Data.(*types.Transaction))
+	}
+}
+
+func (r *runner) stop() {
+	close(r.quit)
+	<-r.done
+	r.plugin.Stop()
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package plugin
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/events"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPlugin struct {
+	name     string
+	startErr error
+	dataDir  string
+	stopped  bool
+
+	mtx      sync.Mutex
+	received []string
+}
+
+func (p *testPlugin) Name() string { return p.name }
+
+func (p *testPlugin) Start(ctx *Context) error {
+	p.dataDir = ctx.DataDir
+	return p.startErr
+}
+
+func (p *testPlugin) Stop() { p.stopped = true }
+
+func (p *testPlugin) OnBlockConnected(block *types.Block) {
+	p.record("connected")
+}
+
+func (p *testPlugin) OnBlockDisconnected(block *types.Block) {
+	p.record("disconnected")
+}
+
+func (p *testPlugin) OnTransactionAccepted(tx *types.Transaction) {
+	if tx.TxType == types.TransferAsset {
+		panic("test panic")
+	}
+	p.record("transaction")
+}
+
+func (p *testPlugin) record(event string) {
+	p.mtx.Lock()
+	p.received = append(p.received, event)
+	p.mtx.Unlock()
+}
+
+func (p *testPlugin) events() []string {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return append([]string{}, p.received...)
+}
+
+func resetRegistry() {
+	Stop()
+	registry.mtx.Lock()
+	registry.plugins = nil
+	registry.mtx.Unlock()
+}
+
+func TestRegister(t *testing.T) {
+	defer resetRegistry()
+
+	Register(&testPlugin{name: "b"})
+	Register(&testPlugin{name: "a"})
+	assert.Equal(t, []string{"a", "b"}, Registered())
+	assert.Panics(t, func() { Register(&testPlugin{name: "a"}) })
+}
+
+func TestStart(t *testing.T) {
+	defer resetRegistry()
+
+	p := &testPlugin{name: "index"}
+	Register(p)
+	assert.NoError(t, Start(&Context{DataDir: "plugins"}))
+	assert.Equal(t, filepath.Join("plugins", "index"), p.dataDir)
+	assert.Error(t, Start(&Context{}))
+
+	events.Notify(events.ETBlockConnected, &types.Block{})
+	events.Notify(events.ETBlockAccepted, &types.Block{})
+	events.Notify(events.ETTransactionAccepted,
+		&types.Transaction{TxType: types.TransferAsset})
+	events.Notify(events.ETTransactionAccepted,
+		&types.Transaction{TxType: types.RegisterProducer})
+	events.Notify(events.ETBlockDisconnected, &types.Block{})
+
+	// The panic on the transfer transaction is recovered, and events are
+	// received in order.
+	expected := []string{"connected", "transaction", "disconnected"}
+	for i := 0; i < 100 && len(p.events()) < len(expected); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, expected, p.events())
+
+	Stop()
+	assert.True(t, p.stopped)
+	events.Notify(events.ETBlockConnected, &types.Block{})
+	assert.Equal(t, expected, p.events())
+}
+
+func TestStartFailed(t *testing.T) {
+	defer resetRegistry()
+
+	a := &testPlugin{name: "a"}
+	b := &testPlugin{name: "b", startErr: errors.New("start error")}
+	Register(a)
+	Register(b)
+	assert.Error(t, Start(&Context{}))
+	assert.True(t, a.stopped)
+
+	registry.mtx.Lock()
+	assert.Equal(t, 0, len(registry.runners))
+	registry.mtx.Unlock()
+}
@@ -115,6 +115,10 @@ func (pow *Service) CreateCoinbaseTx(minerAddr string) (*types.Transaction, erro
 	if err != nil {
 		return nil, err
 	}
+	if !pow.chainParams.IsRewardAddressAllowed(*minerProgramHash) {
+		return nil, fmt.Errorf("miner address %s is not in the reward "+
+			"whitelist", minerAddr)
+	}
 
 	currentHeight := pow.chain.GetHeight() + 1
 	tx := &types.Transaction{
@@ -162,10 +166,13 @@ func (pow *Service) CreateCoinbaseTx(minerAddr string) (*types.Transaction, erro
 }
 
 func (pow *Service) AssignCoinbaseTxRewards(block *types.Block, totalReward common.Fixed64) error {
+	policy := pow.chainParams.GetRewardPolicy(block.Height)
 	// main version >= H2
 	if block.Height >= pow.chainParams.PublicDPOSHeight {
-		rewardCyberRepublic := common.Fixed64(math.Ceil(float64(totalReward) * 0.3))
-		rewardDposArbiter := common.Fixed64(math.Ceil(float64(totalReward) * 0.35))
+		rewardCyberRepublic := common.Fixed64(math.Ceil(float64(totalReward) *
+			policy.FoundationRewardRatio))
+		rewardDposArbiter := common.Fixed64(math.Ceil(float64(totalReward) *
+			policy.DPoSRewardRatio))
 		rewardMergeMiner := common.Fixed64(totalReward) - rewardCyberRepublic - rewardDposArbiter
 
 		if rewards := pow.arbiters.GetArbitersRoundReward(); len(rewards) > 0 {
@@ -186,8 +193,10 @@ func (pow *Service) AssignCoinbaseTxRewards(block *types.Block, totalReward comm
 
 	// version [0, H2)
 	// PoW miners and DPoS are each equally allocated 35%. The remaining 30% goes to the Cyber Republic fund
-	rewardCyberRepublic := common.Fixed64(float64(totalReward) * 0.3)
-	rewardMergeMiner := common.Fixed64(float64(totalReward) * 0.35)
+	rewardCyberRepublic := common.Fixed64(float64(totalReward) *
+		policy.FoundationRewardRatio)
+	rewardMergeMiner := common.Fixed64(float64(totalReward) *
+		(1 - policy.FoundationRewardRatio - policy.DPoSRewardRatio))
 	rewardDposArbiter := common.Fixed64(totalReward) - rewardCyberRepublic - rewardMergeMiner
 	block.Transactions[0].Outputs[0].Value = rewardCyberRepublic
 	block.Transactions[0].Outputs[1].Value = rewardMergeMiner
@@ -217,6 +226,9 @@ func (pow *Service) distributeDPOSReward(coinBaseTx *types.Transaction,
 func (pow *Service) GenerateBlock(minerAddr string) (*types.Block, error) {
 	bestChain := pow.chain.BestChain
 	nextBlockHeight := bestChain.Height + 1
+	if err := pow.chain.CheckHalted(nextBlockHeight); err != nil {
+		return nil, err
+	}
 	coinBaseTx, err := pow.CreateCoinbaseTx(minerAddr)
 	if err != nil {
 		return nil, err
@@ -238,19 +250,60 @@ func (pow *Service) GenerateBlock(minerAddr string) (*types.Block, error) {
 	}
 
 	msgBlock.Transactions = append(msgBlock.Transactions, coinBaseTx)
-	totalTxsSize := coinBaseTx.GetSize()
-	txCount := 1
-	totalTxFee := common.Fixed64(0)
-	txs := pow.txMemPool.GetTxsInPool()
-	isHighPriority := func(tx *types.Transaction) bool {
-		if tx.IsIllegalTypeTx() || tx.IsInactiveArbitrators() ||
-			tx.IsSideChainPowTx() || tx.IsUpdateVersion() ||
-			tx.IsActivateProducerTx() {
-			return true
-		}
-		return false
+	selection := pow.selectTransactions(nextBlockHeight, coinBaseTx.GetSize())
+	msgBlock.Transactions = append(msgBlock.Transactions, selection.txs...)
+
+	totalReward := selection.totalFee + pow.chainParams.RewardPerBlock
+	pow.AssignCoinbaseTxRewards(msgBlock, totalReward)
+
+	txHash := make([]common.Uint256, 0, len(msgBlock.Transactions))
+	for _, tx := range msgBlock.Transactions {
+		txHash = append(txHash, tx.Hash())
 	}
+	txRoot, _ := crypto.ComputeRoot(txHash)
+	msgBlock.Header.MerkleRoot = txRoot
 
+	msgBlock.Header.Bits, err = pow.chain.CalcNextRequiredDifficulty(bestChain, time.Now())
+	log.Info("difficulty: ", msgBlock.Header.Bits)
+
+	return msgBlock, err
+}
+
+// ExcludedTx is a transaction in the memory pool not selected into the next
+// block, Reason explains why it's excluded.
+type ExcludedTx struct {
+	Tx     *types.Transaction
+	Reason string
+}
+
+// txSelection is the result of selecting transactions of the memory pool
+// into the next block.
+type txSelection struct {
+	txs      []*types.Transaction
+	totalFee common.Fixed64
+	excluded []ExcludedTx
+}
+
+// isHighPriority returns if the transaction should be selected before
+// transactions ordered by fee.
+func isHighPriority(tx *types.Transaction) bool {
+	if tx.IsIllegalTypeTx() || tx.IsInactiveArbitrators() ||
+		tx.IsSideChainPowTx() || tx.IsUpdateVersion() ||
+		tx.IsActivateProducerTx() {
+		return true
+	}
+	return false
+}
+
+// selectTransactions selects transactions of the memory pool into the block
+// of the height in priority and fee order, baseSize is the size of
+// transactions already in the block.
+func (pow *Service) selectTransactions(height uint32,
+	baseSize int) *txSelection {
+	selection := &txSelection{}
+	totalTxsSize := baseSize
+	txCount := 1
+	txs := pow.txMemPool.GetTxsInPool()
 	sort.Slice(txs, func(i, j int) bool {
 		if isHighPriority(txs[i]) {
 			return true
@@ -261,49 +314,89 @@ func (pow *Service) GenerateBlock(minerAddr string) (*types.Block, error) {
 		return txs[i].FeePerKB > txs[j].FeePerKB
 	})
 
-	for _, tx := range txs {
+	exclude := func(txs []*types.Transaction, reason string) {
+		for _, tx := range txs {
+			selection.excluded = append(selection.excluded,
+				ExcludedTx{Tx: tx, Reason: reason})
+		}
+	}
+	for i, tx := range txs {
 		size := totalTxsSize + tx.GetSize()
 		if size > int(pact.MaxBlockSize) {
+			exclude(txs[i:i+1], "exceeds the max block size")
 			continue
 		}
 		totalTxsSize = size
 		if txCount >= maxTxPerBlock {
 			log.Warn("txCount reached max MaxTxPerBlock")
+			exclude(txs[i:], "reached the max transactions per block")
 			break
 		}
 
-		if !blockchain.IsFinalizedTransaction(tx, nextBlockHeight) {
+		if !blockchain.IsFinalizedTransaction(tx, height) {
+			exclude(txs[i:i+1], "not finalized")
 			continue
 		}
 		references, err := pow.chain.UTXOCache.GetTxReference(tx)
 		if err != nil {
 			log.Warn("check transaction context failed, get transaction reference failed")
+			exclude(txs[i:], "get transaction reference failed, "+
+				err.Error())
 			break
 		}
-		errCode := pow.chain.CheckTransactionContext(nextBlockHeight, tx, references)
+		errCode := pow.chain.CheckTransactionContext(height, tx, references)
 		if errCode != elaerr.Success {
 			log.Warn("check transaction context failed, wrong transaction:", tx.Hash().String())
+			exclude(txs[i:i+1], "check transaction context failed, "+
+				errCode.Error())
 			continue
 		}
-		msgBlock.Transactions = append(msgBlock.Transactions, tx)
-		totalTxFee += tx.Fee
+		selection.txs = append(selection.txs, tx)
+		selection.totalFee += tx.Fee
 		txCount++
 	}
+	return selection
+}
 
-	totalReward := totalTxFee + pow.chainParams.RewardPerBlock
-	pow.AssignCoinbaseTxRewards(msgBlock, totalReward)
+// BlockPreview is the block the node would generate next, with transactions
+// of the memory pool excluded from the block.
+type BlockPreview struct {
+	Block    *types.Block
+	TotalFee common.Fixed64
+	Excluded []ExcludedTx
+}
 
-	txHash := make([]common.Uint256, 0, len(msgBlock.Transactions))
-	for _, tx := range msgBlock.Transactions {
-		txHash = append(txHash, tx.Hash())
+// PreviewBlock assembles the next block paying to the address without
+// mining or submitting it, so the transactions selected from the memory pool
+// can be inspected.
+func (pow *Service) PreviewBlock(minerAddr string) (*BlockPreview, error) {
+	nextBlockHeight := pow.chain.BestChain.Height + 1
+	coinBaseTx, err := pow.CreateCoinbaseTx(minerAddr)
+	if err != nil {
+		return nil, err
 	}
-	txRoot, _ := crypto.ComputeRoot(txHash)
-	msgBlock.Header.MerkleRoot = txRoot
 
-	msgBlock.Header.Bits, err = pow.chain.CalcNextRequiredDifficulty(bestChain, time.Now())
-	log.Info("difficulty: ", msgBlock.Header.Bits)
+	block := &types.Block{
+		Header: types.Header{
+			Previous:  *pow.chain.BestChain.Hash,
+			Timestamp: uint32(pow.chain.MedianAdjustedTime().Unix()),
+			Bits:      pow.chainParams.PowLimitBits,
+			Height:    nextBlockHeight,
+		},
+		Transactions: []*types.Transaction{coinBaseTx},
+	}
+	selection := pow.selectTransactions(nextBlockHeight, coinBaseTx.GetSize())
+	block.Transactions = append(block.Transactions, selection.txs...)
+	totalReward := selection.totalFee + pow.chainParams.RewardPerBlock
+	if err := pow.AssignCoinbaseTxRewards(block, totalReward); err != nil {
+		return nil, err
+	}
 
-	return msgBlock, err
+	return &BlockPreview{
+		Block:    block,
+		TotalFee: selection.totalFee,
+		Excluded: selection.excluded,
+	}, nil
 }
 
 func (pow *Service) CreateAuxBlock(payToAddr string) (*types.Block, error) {
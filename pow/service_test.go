@@ -33,7 +33,8 @@ func TestService_Init(t *testing.T) {
 
 	params := &config.DefaultParams
 	chainStore, err := blockchain.NewChainStore(filepath.Join(
-		test.DataPath, "service"), config.DefaultParams.GenesisBlock)
+		test.DataPath, "service"), blockchain.LevelDBEngine,
+		config.DefaultParams.GenesisBlock)
 	if err != nil {
 		t.Error(err)
 	}
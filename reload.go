@@ -0,0 +1,102 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/common/log"
+	"github.com/elastos/Elastos.ELA/elanet"
+	"github.com/elastos/Elastos.ELA/mempool"
+	"github.com/elastos/Elastos.ELA/servers/httpjsonrpc"
+	"github.com/elastos/Elastos.ELA/utils/elalog"
+)
+
+// reloadMtx makes sure only one reload is in progress at a time.
+var reloadMtx sync.Mutex
+
+// reloadConfig reloads the non-consensus settings from the config file and
+// applies them to the running services.  All settings are validated before
+// applying, so an invalid config file leaves the running node unchanged.
+//
+// The reloadable settings are PrintLevel, RpcConfiguration, MaxPeers and
+// TxPolicy, other settings take effect after restarting.
+func reloadConfig(st *settings, server elanet.Server,
+	txPool *mempool.TxPool) error {
+	reloadMtx.Lock()
+	defer reloadMtx.Unlock()
+
+	conf, err := st.loadConfigFile(st.context.String("conf"))
+	if err != nil {
+		return err
+	}
+
+	if conf.PrintLevel > elalog.LevelOff {
+		return fmt.Errorf("invalid PrintLevel %d", conf.PrintLevel)
+	}
+	if conf.MaxPeers < 0 {
+		return fmt.Errorf("invalid MaxPeers %d", conf.MaxPeers)
+	}
+	policy, err := reloadTxPolicy(&conf.TxPolicy)
+	if err != nil {
+		return err
+	}
+
+	// Settings from command line take precedence over the config file.
+	rpcCfg := conf.RpcConfiguration
+	current := st.Config().RpcConfiguration
+	if st.context.IsSet(cmdcom.RPCUserFlag.Name) {
+		rpcCfg.User = current.User
+	}
+	if st.context.IsSet(cmdcom.RPCPasswordFlag.Name) {
+		rpcCfg.Pass = current.Pass
+	}
+	if st.context.IsSet(cmdcom.RPCAllowedIPsFlag.Name) {
+		rpcCfg.WhiteIPList = current.WhiteIPList
+	}
+
+	setLogLevel(conf.PrintLevel)
+	httpjsonrpc.SetRpcConfiguration(rpcCfg)
+	if conf.MaxPeers > 0 {
+		server.SetMaxPeers(conf.MaxPeers)
+	}
+	txPool.SetTxPolicy(policy)
+
+	log.Infof("Config reloaded, PrintLevel %d, MaxPeers %d, TxPolicy %+v",
+		conf.PrintLevel, conf.MaxPeers, policy)
+	return nil
+}
+
+// reloadTxPolicy returns the transaction policy by overriding the default
+// policy with the non-zero values of the config, the same as loading config
+// on start.
+func reloadTxPolicy(cfg *config.TxPolicyConfig) (config.TxPolicy, error) {
+	policy := config.DefaultParams.TxPolicy
+	values := []struct {
+		value  int
+		target *int
+	}{
+		{cfg.MaxTxSize, &policy.MaxTxSize},
+		{cfg.MaxInputs, &policy.MaxInputs},
+		{cfg.MaxOutputs, &policy.MaxOutputs},
+		{cfg.MaxProgramCodeSize, &policy.MaxProgramCodeSize},
+		{cfg.MaxProgramParameterSize, &policy.MaxProgramParameterSize},
+		{cfg.MaxMemoSize, &policy.MaxMemoSize},
+	}
+	for _, v := range values {
+		if v.value < 0 {
+			return policy, errors.New("TxPolicy values can not be negative")
+		}
+		if v.value > 0 {
+			*v.target = v.value
+		}
+	}
+	return policy, nil
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+// Package sdk provides address derivation, transaction building and signing
+// for light wallets. The package does not depend on node components such as
+// database and p2p network, and the exported API only uses types supported
+// by gomobile, so it can be bound to mobile platforms by
+//
+//	gomobile bind github.com/elastos/Elastos.ELA/sdk
+package sdk
+
+import (
+	"errors"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+// KeyPair is a pair of private key and compressed public key.
+type KeyPair struct {
+	PrivateKey []byte
+	PublicKey  []byte
+}
+
+// NewKeyPair generates a random key pair.
+func NewKeyPair() (*KeyPair, error) {
+	privateKey, publicKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	pk, err := publicKey.EncodePoint(true)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{PrivateKey: privateKey, PublicKey: pk}, nil
+}
+
+// GetPublicKey returns the compressed public key of the private key.
+func GetPublicKey(privateKey []byte) ([]byte, error) {
+	if len(privateKey) != 32 {
+		return nil, errors.New("invalid private key")
+	}
+	return crypto.NewPubKey(privateKey).EncodePoint(true)
+}
+
+// GetStandardRedeemScript returns the standard redeem script of the public
+// key, which is used as the program code to spend from the standard address.
+func GetStandardRedeemScript(publicKey []byte) ([]byte, error) {
+	pk, err := crypto.DecodePoint(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return contract.CreateStandardRedeemScript(pk)
+}
+
+// GetMultiSignRedeemScript returns the redeem script of the multi-sign
+// address which requires m signatures of the public keys.
+func GetMultiSignRedeemScript(m int, publicKeys *PublicKeyList) ([]byte, error) {
+	var pks []*crypto.PublicKey
+	for _, publicKey := range publicKeys.keys {
+		pk, err := crypto.DecodePoint(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		pks = append(pks, pk)
+	}
+	return contract.CreateMultiSigRedeemScript(m, pks)
+}
+
+// GetStandardAddress returns the standard address of the public key.
+func GetStandardAddress(publicKey []byte) (string, error) {
+	programHash, err := contract.PublicKeyToStandardProgramHash(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return programHash.ToAddress()
+}
+
+// GetMultiSignAddress returns the address which requires m signatures of the
+// public keys.
+func GetMultiSignAddress(m int, publicKeys *PublicKeyList) (string, error) {
+	code, err := GetMultiSignRedeemScript(m, publicKeys)
+	if err != nil {
+		return "", err
+	}
+	return GetAddressByCode(code)
+}
+
+// GetDepositAddress returns the deposit address of the public key, which is
+// used to register producer.
+func GetDepositAddress(publicKey []byte) (string, error) {
+	programHash, err := contract.PublicKeyToDepositProgramHash(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return programHash.ToAddress()
+}
+
+// GetCID returns the CID of the public key, which is used to register CR.
+func GetCID(publicKey []byte) (string, error) {
+	hashes, err := derive(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return hashes.CID.ToAddress()
+}
+
+// GetDID returns the DID of the public key.
+func GetDID(publicKey []byte) (string, error) {
+	hashes, err := derive(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return hashes.DID.ToAddress()
+}
+
+// GetAddressByCode returns the address of the redeem script.
+func GetAddressByCode(code []byte) (string, error) {
+	var prefix contract.PrefixType
+	switch {
+	case contract.IsStandard(code):
+		prefix = contract.PrefixStandard
+	case contract.IsMultiSig(code):
+		prefix = contract.PrefixMultiSig
+	default:
+		return "", errors.New("unsupported redeem script")
+	}
+	ct := &contract.Contract{Code: code, Prefix: prefix}
+	return ct.ToProgramHash().ToAddress()
+}
+
+// IsValidAddress returns if the address is a valid address.
+func IsValidAddress(address string) bool {
+	_, err := common.Uint168FromAddress(address)
+	return err == nil
+}
+
+func derive(publicKey []byte) (*contract.DerivedProgramHashes, error) {
+	code, err := GetStandardRedeemScript(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return contract.DeriveProgramHashes(code)
+}
+
+// PublicKeyList is a list of public keys, it is used instead of [][]byte
+// which is not supported by gomobile.
+type PublicKeyList struct {
+	keys [][]byte
+}
+
+// NewPublicKeyList creates an empty public key list.
+func NewPublicKeyList() *PublicKeyList {
+	return &PublicKeyList{}
+}
+
+// Add appends the public key to the list.
+func (l *PublicKeyList) Add(publicKey []byte) {
+	l.keys = append(l.keys, publicKey)
+}
+
+// Len returns the number of public keys in the list.
+func (l *PublicKeyList) Len() int {
+	return len(l.keys)
+}
+
+// Get returns the public key at the index.
+func (l *PublicKeyList) Get(index int) ([]byte, error) {
+	if index < 0 || index >= len(l.keys) {
+		return nil, errors.New("index out of range")
+	}
+	return l.keys[index], nil
+}
+
+// GetProgramHash returns the program hash of the address, CID program hash is
+// used as candidate to vote CR candidates.
+func GetProgramHash(address string) ([]byte, error) {
+	programHash, err := common.Uint168FromAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return programHash.Bytes(), nil
+}
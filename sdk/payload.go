@@ -0,0 +1,212 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package sdk
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+// Payload is the payload of a transaction with the transaction type, use
+// Transaction.SetPayload to set it to a transaction.
+type Payload struct {
+	txType  types.TxType
+	version byte
+	payload types.Payload
+}
+
+// NewRegisterProducerPayload creates the payload to register producer, the
+// payload is signed by the owner private key.
+func NewRegisterProducerPayload(ownerPrivateKey []byte, nodePublicKey []byte,
+	nickName string, url string, location int64,
+	netAddress string) (*Payload, error) {
+	return newProducerInfoPayload(types.RegisterProducer, ownerPrivateKey,
+		nodePublicKey, nickName, url, location, netAddress)
+}
+
+// NewUpdateProducerPayload creates the payload to update producer, the
+// payload is signed by the owner private key.
+func NewUpdateProducerPayload(ownerPrivateKey []byte, nodePublicKey []byte,
+	nickName string, url string, location int64,
+	netAddress string) (*Payload, error) {
+	return newProducerInfoPayload(types.UpdateProducer, ownerPrivateKey,
+		nodePublicKey, nickName, url, location, netAddress)
+}
+
+func newProducerInfoPayload(txType types.TxType, ownerPrivateKey []byte,
+	nodePublicKey []byte, nickName string, url string, location int64,
+	netAddress string) (*Payload, error) {
+	ownerPublicKey, err := GetPublicKey(ownerPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := crypto.DecodePoint(nodePublicKey); err != nil {
+		return nil, errors.New("invalid node public key")
+	}
+	if location < 0 {
+		return nil, errors.New("invalid location")
+	}
+
+	p := &payload.ProducerInfo{
+		OwnerPublicKey: ownerPublicKey,
+		NodePublicKey:  nodePublicKey,
+		NickName:       nickName,
+		Url:            url,
+		Location:       uint64(location),
+		NetAddress:     netAddress,
+	}
+	buf := new(bytes.Buffer)
+	if err := p.SerializeUnsigned(buf, payload.ProducerInfoVersion); err != nil {
+		return nil, err
+	}
+	if p.Signature, err = crypto.Sign(ownerPrivateKey, buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return &Payload{txType: txType, version: payload.ProducerInfoVersion,
+		payload: p}, nil
+}
+
+// NewCancelProducerPayload creates the payload to cancel producer, the
+// payload is signed by the owner private key.
+func NewCancelProducerPayload(ownerPrivateKey []byte) (*Payload, error) {
+	ownerPublicKey, err := GetPublicKey(ownerPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &payload.ProcessProducer{OwnerPublicKey: ownerPublicKey}
+	buf := new(bytes.Buffer)
+	if err := p.SerializeUnsigned(buf, payload.ProcessProducerVersion); err != nil {
+		return nil, err
+	}
+	if p.Signature, err = crypto.Sign(ownerPrivateKey, buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return &Payload{txType: types.CancelProducer,
+		version: payload.ProcessProducerVersion, payload: p}, nil
+}
+
+// NewActivateProducerPayload creates the payload to activate producer, the
+// payload is signed by the node private key.
+func NewActivateProducerPayload(nodePrivateKey []byte) (*Payload, error) {
+	nodePublicKey, err := GetPublicKey(nodePrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &payload.ActivateProducer{NodePublicKey: nodePublicKey}
+	buf := new(bytes.Buffer)
+	if err := p.SerializeUnsigned(buf, payload.ActivateProducerVersion); err != nil {
+		return nil, err
+	}
+	if p.Signature, err = crypto.Sign(nodePrivateKey, buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return &Payload{txType: types.ActivateProducer,
+		version: payload.ActivateProducerVersion, payload: p}, nil
+}
+
+// NewReturnDepositCoinPayload creates the payload to return the deposit of
+// a canceled producer.
+func NewReturnDepositCoinPayload() *Payload {
+	return &Payload{txType: types.ReturnDepositCoin,
+		version: payload.ReturnDepositCoinVersion,
+		payload: &payload.ReturnDepositCoin{}}
+}
+
+// NewRegisterCRPayload creates the payload to register CR, the payload is
+// signed by the private key.
+func NewRegisterCRPayload(privateKey []byte, nickName string, url string,
+	location int64) (*Payload, error) {
+	return newCRInfoPayload(types.RegisterCR, privateKey, nickName, url,
+		location)
+}
+
+// NewUpdateCRPayload creates the payload to update CR, the payload is signed
+// by the private key.
+func NewUpdateCRPayload(privateKey []byte, nickName string, url string,
+	location int64) (*Payload, error) {
+	return newCRInfoPayload(types.UpdateCR, privateKey, nickName, url,
+		location)
+}
+
+func newCRInfoPayload(txType types.TxType, privateKey []byte,
+	nickName string, url string, location int64) (*Payload, error) {
+	code, cid, err := crCodeAndCID(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if location < 0 {
+		return nil, errors.New("invalid location")
+	}
+
+	p := &payload.CRInfo{
+		Code:     code,
+		CID:      *cid,
+		NickName: nickName,
+		Url:      url,
+		Location: uint64(location),
+	}
+	buf := new(bytes.Buffer)
+	if err := p.SerializeUnsigned(buf, payload.CRInfoVersion); err != nil {
+		return nil, err
+	}
+	if p.Signature, err = crypto.Sign(privateKey, buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return &Payload{txType: txType, version: payload.CRInfoVersion,
+		payload: p}, nil
+}
+
+// NewUnregisterCRPayload creates the payload to unregister CR, the payload
+// is signed by the private key.
+func NewUnregisterCRPayload(privateKey []byte) (*Payload, error) {
+	_, cid, err := crCodeAndCID(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &payload.UnregisterCR{CID: *cid}
+	buf := new(bytes.Buffer)
+	if err := p.SerializeUnsigned(buf, payload.UnregisterCRVersion); err != nil {
+		return nil, err
+	}
+	if p.Signature, err = crypto.Sign(privateKey, buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return &Payload{txType: types.UnregisterCR,
+		version: payload.UnregisterCRVersion, payload: p}, nil
+}
+
+// NewReturnCRDepositCoinPayload creates the payload to return the deposit
+// of an unregistered CR.
+func NewReturnCRDepositCoinPayload() *Payload {
+	return &Payload{txType: types.ReturnCRDepositCoin,
+		version: payload.ReturnDepositCoinVersion,
+		payload: &payload.ReturnDepositCoin{}}
+}
+
+func crCodeAndCID(privateKey []byte) ([]byte, *common.Uint168, error) {
+	publicKey, err := GetPublicKey(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	code, err := GetStandardRedeemScript(publicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	ct, err := contract.CreateCRIDContractByCode(code)
+	if err != nil {
+		return nil, nil, err
+	}
+	return code, ct.ToProgramHash(), nil
+}
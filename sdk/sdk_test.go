@@ -0,0 +1,137 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package sdk
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/crypto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddress(t *testing.T) {
+	publicKey, _ := common.HexStringToBytes(
+		"022c9652d3ad5cc065aa9147dc2ad022f80001e8ed233de20f352950d351d472b7")
+
+	address, err := GetDepositAddress(publicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "DZnbidYSfk5dgz5bqg8QT6AyGefa8BvePQ", address)
+
+	address, err = GetCID(publicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "iY82cT1BnjSiaC7qbt7MN7mcayDVHESyqB", address)
+
+	address, err = GetDID(publicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "ibKfYYpJDpBkZcocHsAAuUxfc6rYum8u66", address)
+
+	address, err = GetStandardAddress(publicKey)
+	assert.NoError(t, err)
+	assert.True(t, IsValidAddress(address))
+	code, err := GetStandardRedeemScript(publicKey)
+	assert.NoError(t, err)
+	codeAddress, err := GetAddressByCode(code)
+	assert.NoError(t, err)
+	assert.Equal(t, address, codeAddress)
+
+	assert.False(t, IsValidAddress("invalid address"))
+}
+
+func TestTransaction_Sign(t *testing.T) {
+	kp1, _ := NewKeyPair()
+	kp2, _ := NewKeyPair()
+	kp3, _ := NewKeyPair()
+	publicKeys := NewPublicKeyList()
+	publicKeys.Add(kp1.PublicKey)
+	publicKeys.Add(kp2.PublicKey)
+	publicKeys.Add(kp3.PublicKey)
+	multiSignCode, err := GetMultiSignRedeemScript(2, publicKeys)
+	assert.NoError(t, err)
+	standardCode, err := GetStandardRedeemScript(kp1.PublicKey)
+	assert.NoError(t, err)
+	address, err := GetStandardAddress(kp1.PublicKey)
+	assert.NoError(t, err)
+
+	tx := NewTransaction()
+	assert.NoError(t, tx.AddInput("0000000000000000000000000000000000000000"+
+		"000000000000000000000001", 1, 0xffffffff))
+	assert.NoError(t, tx.AddOutput(address, "1.5", 0))
+	assert.Error(t, tx.AddOutput("invalid address", "1", 0))
+	assert.Error(t, tx.AddOutput(address, "invalid amount", 0))
+	tx.AddProgram(standardCode)
+	tx.AddProgram(multiSignCode)
+
+	signed, err := tx.IsSigned()
+	assert.NoError(t, err)
+	assert.False(t, signed)
+
+	// key pair 1 signs both programs.
+	assert.NoError(t, tx.Sign(kp1.PrivateKey))
+	signed, _ = tx.IsSigned()
+	assert.False(t, signed)
+	assert.Error(t, tx.Sign(kp1.PrivateKey))
+
+	// key pair 2 completes the multi-sign program.
+	assert.NoError(t, tx.Sign(kp2.PrivateKey))
+	signed, _ = tx.IsSigned()
+	assert.True(t, signed)
+
+	data, err := tx.Serialize()
+	assert.NoError(t, err)
+	tx2, err := DeserializeTransaction(data)
+	assert.NoError(t, err)
+	assert.Equal(t, tx.Hash(), tx2.Hash())
+	assert.Equal(t, common.Fixed64(150000000), tx2.txn.Outputs[0].Value)
+
+	unsigned, _ := tx.SerializeUnsigned()
+	pk, _ := crypto.DecodePoint(kp1.PublicKey)
+	assert.NoError(t, crypto.Verify(*pk, unsigned,
+		tx2.txn.Programs[0].Parameter[1:]))
+	assert.Equal(t, 2*crypto.SignatureScriptLength,
+		len(tx2.txn.Programs[1].Parameter))
+
+	// sign with a key not related to any program.
+	kp4, _ := NewKeyPair()
+	assert.Error(t, tx.Sign(kp4.PrivateKey))
+}
+
+func TestPayload(t *testing.T) {
+	owner, _ := NewKeyPair()
+	node, _ := NewKeyPair()
+
+	p, err := NewRegisterProducerPayload(owner.PrivateKey, node.PublicKey,
+		"nickname", "http://www.elastos.org", 86, "127.0.0.1:20338")
+	assert.NoError(t, err)
+	assert.Equal(t, types.RegisterProducer, p.txType)
+	info := p.payload.(*payload.ProducerInfo)
+	buf := new(bytes.Buffer)
+	info.SerializeUnsigned(buf, payload.ProducerInfoVersion)
+	pk, _ := crypto.DecodePoint(owner.PublicKey)
+	assert.NoError(t, crypto.Verify(*pk, buf.Bytes(), info.Signature))
+
+	_, err = NewRegisterProducerPayload(owner.PrivateKey, []byte{1},
+		"nickname", "", 0, "")
+	assert.Error(t, err)
+
+	p, err = NewRegisterCRPayload(owner.PrivateKey, "nickname", "", 0)
+	assert.NoError(t, err)
+	crInfo := p.payload.(*payload.CRInfo)
+	cid, _ := GetCID(owner.PublicKey)
+	crInfoCID, _ := crInfo.CID.ToAddress()
+	assert.Equal(t, cid, crInfoCID)
+	buf = new(bytes.Buffer)
+	crInfo.SerializeUnsigned(buf, payload.CRInfoVersion)
+	assert.NoError(t, crypto.Verify(*pk, buf.Bytes(), crInfo.Signature))
+
+	tx := NewTransaction()
+	tx.SetPayload(NewReturnDepositCoinPayload())
+	assert.Equal(t, types.ReturnDepositCoin, tx.txn.TxType)
+}
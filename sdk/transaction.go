@@ -0,0 +1,301 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package sdk
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math"
+	"math/rand"
+	"strconv"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+	pg "github.com/elastos/Elastos.ELA/core/contract/program"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+// elaAssetID is the asset ID of ELA coin.
+var elaAssetID, _ = common.Uint256FromHexString(
+	"b037db964a231458d2d6ffd5ea18944c4f90e63d547c5d3b9874df66a4ead0a3")
+
+// Transaction is a transaction being built or signed.
+type Transaction struct {
+	txn *types.Transaction
+}
+
+// NewTransaction creates a transfer asset transaction with a random nonce,
+// use SetPayload to change the type of the transaction.
+func NewTransaction() *Transaction {
+	nonce := types.NewAttribute(types.Nonce,
+		[]byte(strconv.FormatInt(rand.Int63(), 10)))
+	return &Transaction{txn: &types.Transaction{
+		Version:    types.TxVersion09,
+		TxType:     types.TransferAsset,
+		Payload:    &payload.TransferAsset{},
+		Attributes: []*types.Attribute{&nonce},
+	}}
+}
+
+// DeserializeTransaction creates a transaction from the serialized data.
+func DeserializeTransaction(data []byte) (*Transaction, error) {
+	var txn types.Transaction
+	if err := txn.Deserialize(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return &Transaction{txn: &txn}, nil
+}
+
+// SetPayload sets the type and payload of the transaction.
+func (t *Transaction) SetPayload(p *Payload) {
+	t.txn.TxType = p.txType
+	t.txn.PayloadVersion = p.version
+	t.txn.Payload = p.payload
+}
+
+// SetLockTime sets the lock time of the transaction in block height.
+func (t *Transaction) SetLockTime(lockTime int64) error {
+	if lockTime < 0 || lockTime > math.MaxUint32 {
+		return errors.New("invalid lock time")
+	}
+	t.txn.LockTime = uint32(lockTime)
+	return nil
+}
+
+// AddInput adds an input which references the output at index of the
+// transaction, the transaction ID is in the format returned by RPC.
+func (t *Transaction) AddInput(txID string, index int, sequence int64) error {
+	id, err := hex.DecodeString(txID)
+	if err != nil {
+		return err
+	}
+	hash, err := common.Uint256FromBytes(common.BytesReverse(id))
+	if err != nil {
+		return err
+	}
+	if index < 0 || index > math.MaxUint16 {
+		return errors.New("invalid output index")
+	}
+	if sequence < 0 || sequence > math.MaxUint32 {
+		return errors.New("invalid sequence")
+	}
+
+	t.txn.Inputs = append(t.txn.Inputs, &types.Input{
+		Previous: types.OutPoint{TxID: *hash, Index: uint16(index)},
+		Sequence: uint32(sequence),
+	})
+	return nil
+}
+
+// AddOutput adds an output which transfers amount of ELA to the address,
+// the amount is in ELA such as "1.5" and the output can not be spent until
+// the outputLock height.
+func (t *Transaction) AddOutput(address string, amount string,
+	outputLock int64) error {
+	output, err := newOutput(address, amount, outputLock)
+	if err != nil {
+		return err
+	}
+	t.txn.Outputs = append(t.txn.Outputs, output)
+	return nil
+}
+
+// AddVoteOutput adds an output which transfers amount of ELA to the address
+// with the votes.
+func (t *Transaction) AddVoteOutput(address string, amount string,
+	vote *VoteOutput) error {
+	output, err := newOutput(address, amount, 0)
+	if err != nil {
+		return err
+	}
+	output.Type = types.OTVote
+	output.Payload = &vote.output
+	t.txn.Outputs = append(t.txn.Outputs, output)
+	return nil
+}
+
+// AddProgram adds a program with the redeem script of the address to spend
+// from, the program will be filled with signatures by Sign.
+func (t *Transaction) AddProgram(code []byte) {
+	t.txn.Programs = append(t.txn.Programs, &pg.Program{Code: code})
+}
+
+// Sign signs all programs which can be signed by the private key, an error
+// will be returned if none of the programs can be signed.
+func (t *Transaction) Sign(privateKey []byte) error {
+	publicKey, err := GetPublicKey(privateKey)
+	if err != nil {
+		return err
+	}
+	data, err := t.SerializeUnsigned()
+	if err != nil {
+		return err
+	}
+
+	signed := false
+	for _, program := range t.txn.Programs {
+		switch {
+		case contract.IsStandard(program.Code):
+			if !bytes.Equal(program.Code[1:34], publicKey) {
+				continue
+			}
+			signature, err := crypto.Sign(privateKey, data)
+			if err != nil {
+				return err
+			}
+			program.Parameter = append([]byte{byte(len(signature))},
+				signature...)
+			signed = true
+
+		case contract.IsMultiSig(program.Code):
+			publicKeys, err := crypto.ParseMultisigScript(program.Code)
+			if err != nil {
+				return err
+			}
+			for i, pk := range publicKeys {
+				if !bytes.Equal(pk[1:], publicKey) {
+					continue
+				}
+				signature, err := crypto.Sign(privateKey, data)
+				if err != nil {
+					return err
+				}
+				param, err := crypto.AppendSignature(i, signature, data,
+					program.Code, program.Parameter)
+				if err != nil {
+					return err
+				}
+				program.Parameter = param
+				signed = true
+				break
+			}
+		}
+	}
+	if !signed {
+		return errors.New("no program can be signed by the private key")
+	}
+	return nil
+}
+
+// IsSigned returns if all programs of the transaction are fully signed.
+func (t *Transaction) IsSigned() (bool, error) {
+	if len(t.txn.Programs) == 0 {
+		return false, nil
+	}
+	for _, program := range t.txn.Programs {
+		haveSign, needSign, err := crypto.GetSignStatus(program.Code,
+			program.Parameter)
+		if err != nil {
+			return false, err
+		}
+		if haveSign < needSign {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Hash returns the transaction ID in the format returned by RPC.
+func (t *Transaction) Hash() string {
+	hash := t.txn.Hash()
+	return common.BytesToHexString(common.BytesReverse(hash.Bytes()))
+}
+
+// Serialize returns the serialized transaction, which can be sent by the
+// sendrawtransaction RPC in hex string.
+func (t *Transaction) Serialize() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := t.txn.Serialize(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SerializeUnsigned returns the serialized transaction without programs,
+// which is the data to be signed.
+func (t *Transaction) SerializeUnsigned() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := t.txn.SerializeUnsigned(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func newOutput(address string, amount string,
+	outputLock int64) (*types.Output, error) {
+	programHash, err := common.Uint168FromAddress(address)
+	if err != nil {
+		return nil, errors.New("invalid address " + address)
+	}
+	value, err := common.StringToFixed64(amount)
+	if err != nil {
+		return nil, errors.New("invalid amount " + amount)
+	}
+	if outputLock < 0 || outputLock > math.MaxUint32 {
+		return nil, errors.New("invalid output lock")
+	}
+
+	return &types.Output{
+		AssetID:     *elaAssetID,
+		Value:       *value,
+		OutputLock:  uint32(outputLock),
+		ProgramHash: *programHash,
+		Type:        types.OTNone,
+		Payload:     &outputpayload.DefaultOutput{},
+	}, nil
+}
+
+// VoteOutput is the votes of an output.
+type VoteOutput struct {
+	output outputpayload.VoteOutput
+}
+
+// NewVoteOutput creates an empty vote output.
+func NewVoteOutput() *VoteOutput {
+	return &VoteOutput{output: outputpayload.VoteOutput{
+		Version: outputpayload.VoteProducerAndCRVersion,
+	}}
+}
+
+// AddContent adds the vote content to the vote output.
+func (v *VoteOutput) AddContent(content *VoteContent) {
+	v.output.Contents = append(v.output.Contents, content.content)
+}
+
+// VoteContent is the votes of candidates with the same vote type.
+type VoteContent struct {
+	content outputpayload.VoteContent
+}
+
+// NewProducerVoteContent creates vote content to vote producers.
+func NewProducerVoteContent() *VoteContent {
+	return &VoteContent{content: outputpayload.VoteContent{
+		VoteType: outputpayload.Delegate,
+	}}
+}
+
+// NewCRVoteContent creates vote content to vote CR candidates.
+func NewCRVoteContent() *VoteContent {
+	return &VoteContent{content: outputpayload.VoteContent{
+		VoteType: outputpayload.CRC,
+	}}
+}
+
+// AddCandidate adds votes in ELA to the candidate, the candidate is the
+// owner public key of a producer or the CID program hash of a CR candidate.
+func (c *VoteContent) AddCandidate(candidate []byte, votes string) error {
+	value, err := common.StringToFixed64(votes)
+	if err != nil {
+		return errors.New("invalid votes " + votes)
+	}
+	c.content.CandidateVotes = append(c.content.CandidateVotes,
+		outputpayload.CandidateVotes{Candidate: candidate, Votes: *value})
+	return nil
+}
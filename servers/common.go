@@ -6,10 +6,8 @@
 package servers
 
 import (
-	"github.com/elastos/Elastos.ELA/common"
 	. "github.com/elastos/Elastos.ELA/core/types"
-	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
-	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/elanet/partition"
 )
 
 const TlsPort = 443
@@ -19,6 +17,20 @@ type AttributeInfo struct {
 	Data  string         `json:"data"`
 }
 
+// MemoInfo is a standard memo of a transaction.
+type MemoInfo struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// MemoRecordInfo is a standard memo of a transaction paying to an address in
+// the wallet.
+type MemoRecordInfo struct {
+	TxID   string `json:"txid"`
+	Height uint32 `json:"height"`
+	MemoInfo
+}
+
 type InputInfo struct {
 	TxID     string `json:"txid"`
 	VOut     uint16 `json:"vout"`
@@ -37,23 +49,6 @@ type OutputInfo struct {
 
 type OutputPayloadInfo interface{}
 
-type DefaultOutputInfo struct{}
-
-type CandidateVotes struct {
-	Candidate string `json:"candidate"`
-	Votes     string `json:"votes"`
-}
-
-type VoteContentInfo struct {
-	VoteType       outputpayload.VoteType `json:"votetype"`
-	CandidatesInfo []CandidateVotes       `json:"candidates"`
-}
-
-type VoteOutputInfo struct {
-	Version  byte              `json:"version"`
-	Contents []VoteContentInfo `json:"contents"`
-}
-
 type ProgramInfo struct {
 	Code      string `json:"code"`
 	Parameter string `json:"parameter"`
@@ -73,6 +68,7 @@ type TransactionInfo struct {
 	Outputs        []OutputInfo       `json:"vout"`
 	LockTime       uint32             `json:"locktime"`
 	Programs       []ProgramInfo      `json:"programs"`
+	Memo           *MemoInfo          `json:"memo,omitempty"`
 }
 
 type TransactionContextInfo struct {
@@ -83,6 +79,99 @@ type TransactionContextInfo struct {
 	BlockTime     uint32 `json:"blocktime"`
 }
 
+// TransactionReceiptInfo is the block and confirmation info of a transaction,
+// block fields are empty if the transaction is still in the mempool.
+type TransactionReceiptInfo struct {
+	TxID            string `json:"txid"`
+	BlockHash       string `json:"blockhash"`
+	BlockHeight     uint32 `json:"blockheight"`
+	Confirmations   uint32 `json:"confirmations"`
+	Irreversible    bool   `json:"irreversible"`
+	ConfirmArbiters uint32 `json:"confirmarbiters"`
+}
+
+type MemPoolTxInfo struct {
+	Size     uint32   `json:"size"`
+	Fee      string   `json:"fee"`
+	FeePerKB string   `json:"feeperkb"`
+	Time     int64    `json:"time"`
+	Height   uint32   `json:"height"`
+	Depends  []string `json:"depends"`
+}
+
+type FeeHistogramInfo struct {
+	FeeRateFrom string `json:"feeratefrom"`
+	FeeRateTo   string `json:"feerateto"`
+	Count       int    `json:"count"`
+	Bytes       int    `json:"bytes"`
+}
+
+type MemPoolInfo struct {
+	Size         int                `json:"size"`
+	Bytes        int                `json:"bytes"`
+	MaxBytes     int                `json:"maxbytes"`
+	FeeHistogram []FeeHistogramInfo `json:"feehistogram"`
+	Rejections   map[string]uint64  `json:"rejections"`
+}
+
+type TxPolicyInfo struct {
+	MaxTxSize               int    `json:"maxtxsize"`
+	MaxInputs               int    `json:"maxinputs"`
+	MaxOutputs              int    `json:"maxoutputs"`
+	MaxProgramCodeSize      int    `json:"maxprogramcodesize"`
+	MaxProgramParameterSize int    `json:"maxprogramparametersize"`
+	MaxMemoSize             int    `json:"maxmemosize"`
+	MinTxFee                string `json:"mintxfee"`
+	MaxMemPoolBytes         int    `json:"maxmempoolbytes"`
+}
+
+type SendRawTransactionInfo struct {
+	TxID     string   `json:"txid"`
+	Size     uint32   `json:"size"`
+	Fee      string   `json:"fee"`
+	FeePerKB string   `json:"feeperkb"`
+	Checks   []string `json:"checks"`
+}
+
+type MempoolAcceptInfo struct {
+	TxID    string `json:"txid"`
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+	Size    uint32 `json:"size"`
+	Fee     string `json:"fee,omitempty"`
+}
+
+type PackageAcceptInfo struct {
+	Allowed      bool                `json:"allowed"`
+	Transactions []MempoolAcceptInfo `json:"transactions"`
+}
+
+type ChainTipInfo struct {
+	Height    uint32 `json:"height"`
+	Hash      string `json:"hash"`
+	BranchLen uint32 `json:"branchlen"`
+	ChainWork string `json:"chainwork"`
+	Status    string `json:"status"`
+	Confirmed bool   `json:"confirmed"`
+}
+
+type RegistrationStatusInfo struct {
+	ID            string `json:"id"`
+	Type          string `json:"type"`
+	State         string `json:"state"`
+	Votes         string `json:"votes"`
+	Rank          uint32 `json:"rank"`
+	DepositAmount string `json:"depositamount"`
+	Penalty       string `json:"penalty"`
+}
+
+type SidechainTxStatusInfo struct {
+	Status        string `json:"status"`
+	WithdrawTxID  string `json:"withdrawtxid"`
+	Height        uint32 `json:"height"`
+	Confirmations uint32 `json:"confirmations"`
+}
+
 type BlockInfo struct {
 	Hash              string        `json:"hash"`
 	Confirmations     uint32        `json:"confirmations"`
@@ -106,6 +195,37 @@ type BlockInfo struct {
 	MinerInfo         string        `json:"minerinfo"`
 }
 
+type AuxPowSummary struct {
+	ParentHash         string `json:"parenthash"`
+	ParentBlockHash    string `json:"parentblockhash"`
+	ParentVersion      uint32 `json:"parentversion"`
+	ParentTime         uint32 `json:"parenttime"`
+	ParentBits         uint32 `json:"parentbits"`
+	ParentNonce        uint32 `json:"parentnonce"`
+	ParentCoinbaseTx   string `json:"parentcoinbasetx"`
+	AuxMerkleIndex     int    `json:"auxmerkleindex"`
+	AuxMerkleBranchLen int    `json:"auxmerklebranchlen"`
+	ParMerkleIndex     int    `json:"parmerkleindex"`
+}
+
+type BlockHeaderInfo struct {
+	Hash              string        `json:"hash"`
+	Confirmations     uint32        `json:"confirmations"`
+	Height            uint32        `json:"height"`
+	Version           uint32        `json:"version"`
+	VersionHex        string        `json:"versionhex"`
+	VersionBits       []uint32      `json:"versionbits"`
+	MerkleRoot        string        `json:"merkleroot"`
+	Time              uint32        `json:"time"`
+	Nonce             uint32        `json:"nonce"`
+	Bits              uint32        `json:"bits"`
+	Difficulty        string        `json:"difficulty"`
+	PreviousBlockHash string        `json:"previousblockhash"`
+	NextBlockHash     string        `json:"nextblockhash"`
+	AuxPow            AuxPowSummary `json:"auxpow"`
+	Confirmed         bool          `json:"confirmed"`
+}
+
 type VoteInfo struct {
 	Signer string `json:"signer"`
 	Accept bool   `json:"accept"`
@@ -128,97 +248,76 @@ type ServerInfo struct {
 	RestPort  uint16      `json:"restport"`  // The RESTful service port
 	WSPort    uint16      `json:"wsport"`    // The webservcie port
 	Neighbors []*PeerInfo `json:"neighbors"` // The connected neighbor peers.
-}
-
-type PeerInfo struct {
-	NetAddress     string `json:"netaddress"`
-	Services       string `json:"services"`
-	RelayTx        bool   `json:"relaytx"`
-	LastSend       string `json:"lastsend"`
-	LastRecv       string `json:"lastrecv"`
-	ConnTime       string `json:"conntime"`
-	TimeOffset     int64  `json:"timeoffset"`
-	Version        uint32 `json:"version"`
-	Inbound        bool   `json:"inbound"`
-	StartingHeight uint32 `json:"startingheight"`
-	LastBlock      uint32 `json:"lastblock"`
-	LastPingTime   string `json:"lastpingtime"`
-	LastPingMicros int64  `json:"lastpingmicros"`
-}
 
-type ArbitratorGroupInfo struct {
-	OnDutyArbitratorIndex int      `json:"ondutyarbitratorindex"`
-	Arbitrators           []string `json:"arbitrators"`
-}
+	// Partition is the result of the last network partition check.
+	Partition *partition.State `json:"partition,omitempty"`
 
-type PayloadInfo interface{}
-
-type CoinbaseInfo struct {
-	CoinbaseData string `json:"coinbasedata"`
-}
-
-type RegisterAssetInfo struct {
-	Asset      payload.Asset `json:"asset"`
-	Amount     string        `json:"amount"`
-	Controller string        `json:"controller"`
-}
+	// NAT and DPoSNAT are the port mapping states of the node port and the
+	// DPoS port, they are omitted if the NAT traversal is not enabled.
+	NAT     *NATInfo `json:"nat,omitempty"`
+	DPoSNAT *NATInfo `json:"dposnat,omitempty"`
 
-type SideChainPowInfo struct {
-	BlockHeight     uint32 `json:"blockheight"`
-	SideBlockHash   string `json:"sideblockhash"`
-	SideGenesisHash string `json:"sidegenesishash"`
-	Signature       string `json:"signature"`
+	// Attestation is the signature of the node identity key over the node
+	// state, it is omitted if the node identity is not enabled.
+	Attestation *NodeAttestation `json:"attestation,omitempty"`
 }
 
-type TransferCrossChainAssetInfo struct {
-	CrossChainAddresses []string         `json:"crosschainaddresses"`
-	OutputIndexes       []uint64         `json:"outputindexes"`
-	CrossChainAmounts   []common.Fixed64 `json:"crosschainamounts"`
+// NATInfo is the state of a port mapping on the NAT gateway.
+type NATInfo struct {
+	Method       string `json:"method"`
+	InternalPort uint16 `json:"internalport"`
+	ExternalIP   string `json:"externalip"`
+	ExternalPort uint16 `json:"externalport"`
+	Mapped       bool   `json:"mapped"`
+	LastError    string `json:"lasterror"`
 }
 
-type WithdrawFromSideChainInfo struct {
-	BlockHeight                uint32   `json:"blockheight"`
-	GenesisBlockAddress        string   `json:"genesisblockaddress"`
-	SideChainTransactionHashes []string `json:"sidechaintransactionhashes"`
+// NodeAttestation is the signature of the node identity key over the compile
+// version, height, version and services of the node state.
+type NodeAttestation struct {
+	PublicKey string `json:"publickey"`
+	Timestamp int64  `json:"timestamp"`
+	Nonce     string `json:"nonce,omitempty"`
+	Signature string `json:"signature"`
 }
 
-type ProducerInfo struct {
-	OwnerPublicKey string `json:"ownerpublickey"`
-	NodePublicKey  string `json:"nodepublickey"`
-	NickName       string `json:"nickname"`
-	Url            string `json:"url"`
-	Location       uint64 `json:"location"`
-	NetAddress     string `json:"netaddress"`
-	Signature      string `json:"signature"`
+type PeerInfo struct {
+	NetAddress      string            `json:"netaddress"`
+	Services        string            `json:"services"`
+	RelayTx         bool              `json:"relaytx"`
+	LastSend        string            `json:"lastsend"`
+	LastRecv        string            `json:"lastrecv"`
+	ConnTime        string            `json:"conntime"`
+	TimeOffset      int64             `json:"timeoffset"`
+	Version         uint32            `json:"version"`
+	Inbound         bool              `json:"inbound"`
+	StartingHeight  uint32            `json:"startingheight"`
+	LastBlock       uint32            `json:"lastblock"`
+	LastPingTime    string            `json:"lastpingtime"`
+	LastPingMicros  int64             `json:"lastpingmicros"`
+	ConnDuration    int64             `json:"connduration"`
+	BytesSent       uint64            `json:"bytessent"`
+	BytesRecv       uint64            `json:"bytesrecv"`
+	BytesSentPerMsg map[string]uint64 `json:"bytessentpermsg"`
+	BytesRecvPerMsg map[string]uint64 `json:"bytesrecvpermsg"`
 }
 
-type CancelProducerInfo struct {
-	OwnerPublicKey string `json:"ownerpublickey"`
-	Signature      string `json:"signature"`
+type ArbitratorGroupInfo struct {
+	OnDutyArbitratorIndex int      `json:"ondutyarbitratorindex"`
+	Arbitrators           []string `json:"arbitrators"`
 }
 
-type ActivateProducerInfo struct {
-	NodePublicKey string `json:"nodepublickey"`
-	Signature     string `json:"signature"`
-}
+type PayloadInfo interface{}
 
 type UTXOInfo struct {
-	TxType        byte   `json:"txtype"`
-	TxID          string `json:"txid"`
-	AssetID       string `json:"assetid"`
-	VOut          uint32 `json:"vout"`
-	Address       string `json:"address"`
-	Amount        string `json:"amount"`
-	OutputLock    uint32 `json:"outputlock"`
-	Confirmations uint32 `json:"confirmations"`
+	TxType        byte      `json:"txtype"`
+	TxID          string    `json:"txid"`
+	AssetID       string    `json:"assetid"`
+	VOut          uint32    `json:"vout"`
+	Address       string    `json:"address"`
+	Amount        string    `json:"amount"`
+	OutputLock    uint32    `json:"outputlock"`
+	Confirmations uint32    `json:"confirmations"`
+	Memo          *MemoInfo `json:"memo,omitempty"`
 }
 
-type SidechainIllegalDataInfo struct {
-	IllegalType         uint8    `json:"illegaltype"`
-	Height              uint32   `json:"height"`
-	IllegalSigner       string   `json:"illegalsigner"`
-	Evidence            string   `json:"evidence"`
-	CompareEvidence     string   `json:"compareevidence"`
-	GenesisBlockAddress string   `json:"genesisblockaddress"`
-	Signs               []string `json:"signs"`
-}
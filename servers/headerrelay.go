@@ -0,0 +1,216 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package servers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/crypto"
+	. "github.com/elastos/Elastos.ELA/errors"
+)
+
+// HeaderRelay is a main chain block header with the summary of its DPoS
+// confirm, which is relayed to side chain SPV clients in a compact binary
+// format:
+//   <header with aux pow><has confirm uint8>[confirm summary]
+type HeaderRelay struct {
+	Header  types.Header
+	Confirm *ConfirmSummary
+}
+
+// ConfirmSummary is the DPoS confirm of a block without the signature of the
+// proposal and the rejected votes.  The proposal hash can be recovered from
+// the sponsor, the block hash and the view offset, and each sign is the
+// signature of the accepting vote of the signer at the same index:
+//   <sponsor varbytes><view offset uint32><count varuint>[<signer varbytes><sign varbytes>]
+type ConfirmSummary struct {
+	Sponsor    []byte
+	ViewOffset uint32
+	Signers    [][]byte
+	Signs      [][]byte
+}
+
+// NewConfirmSummary returns the summary of the accepting votes of confirm.
+func NewConfirmSummary(confirm *payload.Confirm) *ConfirmSummary {
+	summary := &ConfirmSummary{
+		Sponsor:    confirm.Proposal.Sponsor,
+		ViewOffset: confirm.Proposal.ViewOffset,
+	}
+	for _, vote := range confirm.Votes {
+		if !vote.Accept {
+			continue
+		}
+		summary.Signers = append(summary.Signers, vote.Signer)
+		summary.Signs = append(summary.Signs, vote.Sign)
+	}
+	return summary
+}
+
+func (c *ConfirmSummary) Serialize(w io.Writer) error {
+	if err := common.WriteVarBytes(w, c.Sponsor); err != nil {
+		return err
+	}
+	if err := common.WriteUint32(w, c.ViewOffset); err != nil {
+		return err
+	}
+	if err := common.WriteVarUint(w, uint64(len(c.Signers))); err != nil {
+		return err
+	}
+	for i, signer := range c.Signers {
+		if err := common.WriteVarBytes(w, signer); err != nil {
+			return err
+		}
+		if err := common.WriteVarBytes(w, c.Signs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ConfirmSummary) Deserialize(r io.Reader) error {
+	var err error
+	c.Sponsor, err = common.ReadVarBytes(r, crypto.NegativeBigLength,
+		"sponsor")
+	if err != nil {
+		return err
+	}
+	if c.ViewOffset, err = common.ReadUint32(r); err != nil {
+		return err
+	}
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return err
+	}
+	c.Signers = make([][]byte, 0, count)
+	c.Signs = make([][]byte, 0, count)
+	for i := uint64(0); i < count; i++ {
+		signer, err := common.ReadVarBytes(r, crypto.NegativeBigLength,
+			"signer")
+		if err != nil {
+			return err
+		}
+		sign, err := common.ReadVarBytes(r, crypto.SignatureLength, "sign")
+		if err != nil {
+			return err
+		}
+		c.Signers = append(c.Signers, signer)
+		c.Signs = append(c.Signs, sign)
+	}
+	return nil
+}
+
+func (h *HeaderRelay) Serialize(w io.Writer) error {
+	if err := h.Header.Serialize(w); err != nil {
+		return err
+	}
+	if h.Confirm == nil {
+		return common.WriteUint8(w, 0)
+	}
+	if err := common.WriteUint8(w, 1); err != nil {
+		return err
+	}
+	return h.Confirm.Serialize(w)
+}
+
+func (h *HeaderRelay) Deserialize(r io.Reader) error {
+	if err := h.Header.Deserialize(r); err != nil {
+		return err
+	}
+	hasConfirm, err := common.ReadUint8(r)
+	if err != nil {
+		return err
+	}
+	if hasConfirm == 0 {
+		h.Confirm = nil
+		return nil
+	}
+	h.Confirm = new(ConfirmSummary)
+	return h.Confirm.Deserialize(r)
+}
+
+// NewHeaderRelay returns the header relay of the main chain block.
+func NewHeaderRelay(block *types.Block) *HeaderRelay {
+	relay := &HeaderRelay{Header: block.Header}
+	if confirm, err := Store.GetConfirm(block.Hash()); err == nil {
+		relay.Confirm = NewConfirmSummary(confirm)
+	}
+	return relay
+}
+
+// getHeaderRelays returns the header relays from the start height to the end
+// height inclusively, serialized with the count in front.
+func getHeaderRelays(start, end uint32) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := common.WriteVarUint(buf, uint64(end-start+1)); err != nil {
+		return nil, err
+	}
+	for height := start; height <= end; height++ {
+		hash, err := Chain.GetBlockHash(height)
+		if err != nil {
+			return nil, err
+		}
+		block, err := Chain.GetBlockByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		if err := NewHeaderRelay(block).Serialize(buf); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// GetHeaderRelays returns count consecutive header relays from the start
+// height in hex, the headers above the best height are omitted.  It's used
+// by side chain SPV clients to backfill the headers missed.
+func GetHeaderRelays(param Params) map[string]interface{} {
+	start, ok := param.Uint("start")
+	if !ok {
+		return ResponsePack(InvalidParams, "start parameter should be a positive integer")
+	}
+	count, ok := param.Uint("count")
+	if !ok || count == 0 || count > maxBlocksRange() {
+		return ResponsePack(InvalidParams, fmt.Sprintf("count parameter "+
+			"should be between 1 and %d", maxBlocksRange()))
+	}
+
+	bestHeight := Chain.GetHeight()
+	if start > bestHeight {
+		return ResponsePack(UnknownBlock, "start height is above the best height")
+	}
+	end := start + count - 1
+	if end > bestHeight || end < start {
+		end = bestHeight
+	}
+
+	data, err := getHeaderRelays(start, end)
+	if err != nil {
+		return ResponsePack(UnknownBlock, err.Error())
+	}
+	return ResponsePack(Success, common.BytesToHexString(data))
+}
+
+// EncodeHeaderRelay returns the header relay of the block in hex, in the same
+// format as GetHeaderRelays with a single header.
+func EncodeHeaderRelay(block *types.Block) (string, error) {
+	if block == nil {
+		return "", errors.New("block is nil")
+	}
+	buf := new(bytes.Buffer)
+	if err := common.WriteVarUint(buf, 1); err != nil {
+		return "", err
+	}
+	if err := NewHeaderRelay(block).Serialize(buf); err != nil {
+		return "", err
+	}
+	return common.BytesToHexString(buf.Bytes()), nil
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package httpjsonrpc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/common/log"
+	elaErr "github.com/elastos/Elastos.ELA/errors"
+	"github.com/elastos/Elastos.ELA/utils/elalog"
+)
+
+const (
+	// auditPayloadHashSize is the number of leading bytes of the request
+	// body hash recorded in the audit log.
+	auditPayloadHashSize = 8
+
+	defaultAuditPerLogSize = 20 * elalog.MBSize
+	defaultAuditLogsSize   = 5 * elalog.GBSize
+)
+
+// auditedMethods are the state-affecting methods recorded in the audit log.
+var auditedMethods = map[string]struct{}{
+	"sendrawtransaction":         {},
+	"signrawtransactionwithkey":  {},
+	"submitauxblock":             {},
+	"submitsidechainillegaldata": {},
+	"submitdraftdata":            {},
+	"setloglevel":                {},
+	"togglemining":               {},
+	"discretemining":             {},
+	"flushmempool":               {},
+	"reloadconfig":               {},
+	"sethaltheight":              {},
+}
+
+// auditRecord is a line of the audit log.
+type auditRecord struct {
+	Time        string         `json:"time"`
+	IP          string         `json:"ip"`
+	Method      string         `json:"method"`
+	PayloadHash string         `json:"payloadhash"`
+	Code        elaErr.ErrCode `json:"code"`
+}
+
+// auditLog holds the io.Writer of the audit log, nil if disabled.
+var auditLog atomic.Value
+
+// EnableAuditLog appends records of state-affecting RPC calls to the log
+// files under path, a new file is created when the current one reaches
+// maxPerLogSizeMb and the oldest file is removed when the files reach
+// maxLogsSizeMb in total.
+func EnableAuditLog(path string, maxPerLogSizeMb, maxLogsSizeMb int64) {
+	perLogSize := int64(defaultAuditPerLogSize)
+	logsSize := int64(defaultAuditLogsSize)
+	if maxPerLogSizeMb > 0 {
+		perLogSize = maxPerLogSizeMb * elalog.MBSize
+	}
+	if maxLogsSizeMb > 0 {
+		logsSize = maxLogsSizeMb * elalog.MBSize
+	}
+	if logsSize < perLogSize {
+		logsSize = perLogSize
+	}
+
+	var w io.Writer = elalog.NewFileWriter(path, perLogSize, logsSize)
+	auditLog.Store(w)
+}
+
+// audit records the call of method to the audit log if the method is
+// state-affecting, body is the request body and code is the response code.
+func audit(r *http.Request, method string, body []byte, code elaErr.ErrCode) {
+	w, ok := auditLog.Load().(io.Writer)
+	if !ok {
+		return
+	}
+	if _, ok := auditedMethods[method]; !ok {
+		return
+	}
+
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	hash := sha256.Sum256(body)
+	data, err := json.Marshal(auditRecord{
+		Time:        time.Now().UTC().Format(time.RFC3339Nano),
+		IP:          ip,
+		Method:      method,
+		PayloadHash: hex.EncodeToString(hash[:auditPayloadHashSize]),
+		Code:        code,
+	})
+	if err != nil {
+		log.Warn("[audit] marshal audit record error: ", err)
+		return
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		log.Warn("[audit] write audit record error: ", err)
+	}
+}
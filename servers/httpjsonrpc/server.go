@@ -15,6 +15,7 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/elastos/Elastos.ELA/common/config"
@@ -43,32 +44,63 @@ const (
 	MaxRPCRead = 1024 * 1024 * 8
 )
 
+// rpcConfig holds the RPC configuration currently applied, it can be changed
+// by SetRpcConfiguration without restarting the RPC server.
+var rpcConfig atomic.Value
+
+// SetRpcConfiguration changes the user, password and white IP list of the RPC
+// server, the change applies to the following requests.
+func SetRpcConfiguration(cfg config.RpcConfiguration) {
+	rpcConfig.Store(cfg)
+}
+
+func rpcConfiguration() config.RpcConfiguration {
+	if cfg, ok := rpcConfig.Load().(config.RpcConfiguration); ok {
+		return cfg
+	}
+	return config.Parameters.RpcConfiguration
+}
+
 func StartRPCServer() {
 	mainMux = make(map[string]func(Params) map[string]interface{})
 
 	mainMux["setloglevel"] = SetLogLevel
 	mainMux["getinfo"] = GetInfo
 	mainMux["getblock"] = GetBlockByHash
+	mainMux["getblockheader"] = GetBlockHeader
+	mainMux["getblockheaders"] = GetBlockHeaders
 	mainMux["getconfirmbyheight"] = GetConfirmByHeight
 	mainMux["getconfirmbyhash"] = GetConfirmByHash
 	mainMux["getcurrentheight"] = GetBlockHeight
 	mainMux["getblockhash"] = GetBlockHash
 	mainMux["getconnectioncount"] = GetConnectionCount
 	mainMux["getrawmempool"] = GetTransactionPool
+	mainMux["getmempoolinfo"] = GetMemPoolInfo
+	mainMux["gettxpolicy"] = GetTxPolicy
+	mainMux["flushmempool"] = FlushMemPool
+	mainMux["getrejectedtx"] = GetRejectedTx
 	mainMux["getrawtransaction"] = GetRawTransaction
+	mainMux["gettransactionreceipt"] = GetTransactionReceipt
 	mainMux["getneighbors"] = GetNeighbors
+	mainMux["getpeerinfo"] = GetPeerInfo
 	mainMux["getnodestate"] = GetNodeState
 	mainMux["sendrawtransaction"] = SendRawTransaction
+	mainMux["testmempoolaccept"] = TestMempoolAccept
 	mainMux["getarbitratorgroupbyheight"] = GetArbitratorGroupByHeight
 	mainMux["getbestblockhash"] = GetBestBlockHash
 	mainMux["getblockcount"] = GetBlockCount
 	mainMux["getblockbyheight"] = GetBlockByHeight
+	mainMux["getblocksrange"] = GetBlocksRange
+	mainMux["getheaderrelays"] = GetHeaderRelays
+	mainMux["getchaintips"] = GetChainTips
 	mainMux["getexistwithdrawtransactions"] = GetExistWithdrawTransactions
+	mainMux["getsidechaintxstatus"] = GetSidechainTxStatus
 	mainMux["getreceivedbyaddress"] = GetReceivedByAddress
 	// wallet interfaces
 	mainMux["getamountbyinputs"] = GetAmountByInputs
 	mainMux["getutxosbyamount"] = GetUTXOsByAmount
 	mainMux["listunspent"] = ListUnspent
+	mainMux["listmemos"] = ListMemos
 	mainMux["createrawtransaction"] = CreateRawTransaction
 	mainMux["decoderawtransaction"] = DecodeRawTransaction
 	mainMux["signrawtransactionwithkey"] = SignRawTransactionWithKey
@@ -78,14 +110,20 @@ func StartRPCServer() {
 	mainMux["createauxblock"] = CreateAuxBlock
 	// mining interfaces
 	mainMux["getmininginfo"] = GetMiningInfo
+	mainMux["getminingcandidate"] = GetMiningCandidate
 	mainMux["togglemining"] = ToggleMining
 	mainMux["discretemining"] = DiscreteMining
 	//cr interfaces
 	mainMux["listcrcandidates"] = ListCRCandidates
+	mainMux["getcrcandidatehistory"] = GetCRCandidateHistory
 	mainMux["listcurrentcrs"] = ListCurrentCRs
+	mainMux["getcrregistrationwindow"] = GetCRRegistrationWindow
 	// vote interfaces
 	mainMux["listproducers"] = ListProducers
 	mainMux["producerstatus"] = ProducerStatus
+	mainMux["getregistrationstatus"] = GetRegistrationStatus
+	mainMux["getproducerhistory"] = GetProducerHistory
+	mainMux["listvotes"] = ListVotes
 	mainMux["votestatus"] = VoteStatus
 	// for cross-chain arbiter
 	mainMux["submitsidechainillegaldata"] = SubmitSidechainIllegalData
@@ -94,7 +132,27 @@ func StartRPCServer() {
 	mainMux["estimatesmartfee"] = EstimateSmartFee
 	mainMux["getdepositcoin"] = GetDepositCoin
 	mainMux["getcrdepositcoin"] = GetCRDepositCoin
+	mainMux["getcrdeposits"] = GetCRDeposits
+	mainMux["getderivedaddresses"] = GetDerivedAddresses
+	mainMux["validateaddress"] = ValidateAddress
+	mainMux["convertaddress"] = ConvertAddress
 	mainMux["getarbitersinfo"] = GetArbitersInfo
+	mainMux["getunderstaffedstatus"] = GetUnderstaffedStatus
+	mainMux["getcrosschaindutyschedule"] = GetCrossChainDutySchedule
+	mainMux["getstatehashes"] = GetStateHashes
+	mainMux["getkeyframediff"] = GetKeyFrameDiff
+	mainMux["submitdraftdata"] = SubmitDraftData
+	mainMux["getdraftdata"] = GetDraftData
+	mainMux["getrankhistory"] = GetRankHistory
+	mainMux["getroundrewards"] = GetRoundRewards
+	mainMux["getelectionresult"] = GetElectionResult
+	mainMux["getproducerrank"] = GetProducerRank
+	mainMux["getcrcandidaterank"] = GetCRCandidateRank
+	// admin interfaces
+	mainMux["getrpcstats"] = GetRPCStats
+	mainMux["reloadconfig"] = ReloadConfig
+	mainMux["sethaltheight"] = SetHaltHeight
+	mainMux["gethaltheight"] = GetHaltHeight
 
 	rpcServeMux := http.NewServeMux()
 	server := http.Server{
@@ -103,11 +161,16 @@ func StartRPCServer() {
 		WriteTimeout: IOTimeout,
 	}
 	rpcServeMux.HandleFunc("/", Handle)
-	l, err := net.Listen("tcp4", ":"+strconv.Itoa(config.Parameters.HttpJsonPort))
+	useTLS := config.Parameters.HttpTLS.EnableRPC
+	l, err := Listen("tcp4", ":"+strconv.Itoa(config.Parameters.HttpJsonPort),
+		useTLS)
 	if err != nil {
 		log.Fatal("Create listener error: ", err.Error())
 		return
 	}
+	if useTLS {
+		log.Info("TLS listen port is ", config.Parameters.HttpJsonPort)
+	}
 	err = server.Serve(l)
 	if err != nil {
 		log.Fatal("ListenAndServe error: ", err.Error())
@@ -187,7 +250,12 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Debug("RPC method:", requestMethod)
 
+	start := time.Now()
 	response := method(params)
+	code, _ := response["Error"].(elaErr.ErrCode)
+	stats.record(requestMethod, time.Now().Sub(start), code)
+	audit(r, requestMethod, body, code)
+
 	var data []byte
 	if response["Error"] != elaErr.ErrCode(0) {
 		data, _ = json.Marshal(map[string]interface{}{
@@ -232,7 +300,7 @@ func clientAllowed(r *http.Request) bool {
 		return true
 	}
 
-	for _, cfgIp := range config.Parameters.RpcConfiguration.WhiteIPList {
+	for _, cfgIp := range rpcConfiguration().WhiteIPList {
 		//WhiteIPList have 0.0.0.0  allow all ip in
 		if cfgIp == "0.0.0.0" {
 			return true
@@ -246,8 +314,8 @@ func clientAllowed(r *http.Request) bool {
 }
 
 func checkAuth(r *http.Request) bool {
-	if (config.Parameters.RpcConfiguration.User == config.Parameters.RpcConfiguration.Pass) &&
-		(len(config.Parameters.RpcConfiguration.User) == 0) {
+	cfg := rpcConfiguration()
+	if (cfg.User == cfg.Pass) && (len(cfg.User) == 0) {
 		return true
 	}
 	authHeader := r.Header["Authorization"]
@@ -257,7 +325,7 @@ func checkAuth(r *http.Request) bool {
 
 	authSha256 := sha256.Sum256([]byte(authHeader[0]))
 
-	login := config.Parameters.RpcConfiguration.User + ":" + config.Parameters.RpcConfiguration.Pass
+	login := cfg.User + ":" + cfg.Pass
 	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
 	cfgAuthSha256 := sha256.Sum256([]byte(auth))
 
@@ -295,26 +363,84 @@ func convertParams(method string, params []interface{}) Params {
 		return FromArray(params, "height")
 	case "getblock":
 		return FromArray(params, "blockhash", "verbosity")
+	case "getblockheader":
+		return FromArray(params, "blockhash", "verbosity")
+	case "getblockheaders":
+		return FromArray(params, "locator", "stophash", "count", "verbosity")
 	case "setloglevel":
 		return FromArray(params, "level")
 	case "getrawtransaction":
 		return FromArray(params, "txid", "verbose")
+	case "gettransactionreceipt":
+		return FromArray(params, "txid")
+	case "getrejectedtx":
+		return FromArray(params, "txid")
+	case "getrawmempool":
+		return FromArray(params, "verbose")
 	case "getarbitratorgroupbyheight":
 		return FromArray(params, "height")
 	case "togglemining":
 		return FromArray(params, "mining")
 	case "discretemining":
 		return FromArray(params, "count")
+	case "getminingcandidate":
+		return FromArray(params, "paytoaddress")
+	case "sethaltheight":
+		return FromArray(params, "height")
 	case "sendrawtransaction":
-		return FromArray(params, "data")
+		return FromArray(params, "data", "verbose", "allowhighfee")
+	case "testmempoolaccept":
+		return FromArray(params, "rawtxs")
 	case "listunspent":
 		return FromArray(params, "addresses")
+	case "listmemos":
+		return FromArray(params, "address", "start", "end")
 	case "getreceivedbyaddress":
 		return FromArray(params, "address")
 	case "getblockbyheight":
 		return FromArray(params, "height")
+	case "getblocksrange":
+		return FromArray(params, "start", "count", "verbosity")
+	case "getheaderrelays":
+		return FromArray(params, "start", "count")
+	case "getkeyframediff":
+		return FromArray(params, "from", "to", "module")
 	case "estimatesmartfee":
 		return FromArray(params, "confirmations")
+	case "getderivedaddresses":
+		return FromArray(params, "publickey")
+	case "validateaddress":
+		return FromArray(params, "address")
+	case "convertaddress":
+		return FromArray(params, "address")
+	case "getcrcandidatehistory":
+		return FromArray(params, "cid")
+	case "getproducerhistory":
+		return FromArray(params, "publickey")
+	case "getregistrationstatus":
+		return FromArray(params, "ids")
+	case "submitdraftdata":
+		return FromArray(params, "drafthash", "data")
+	case "getdraftdata":
+		return FromArray(params, "drafthash")
+	case "getrankhistory":
+		return FromArray(params, "publickey", "start", "end")
+	case "getroundrewards":
+		return FromArray(params, "height")
+	case "getelectionresult":
+		return FromArray(params, "height")
+	case "getproducerrank":
+		return FromArray(params, "publickey")
+	case "getcrcandidaterank":
+		return FromArray(params, "cid")
+	case "getcrdeposits":
+		return FromArray(params, "state", "address")
+	case "getnodestate":
+		return FromArray(params, "nonce")
+	case "getrpcstats":
+		return FromArray(params, "reset")
+	case "getneighbors":
+		return FromArray(params, "verbose")
 	default:
 		return Params{}
 	}
@@ -0,0 +1,153 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package httpjsonrpc
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	elaErr "github.com/elastos/Elastos.ELA/errors"
+	. "github.com/elastos/Elastos.ELA/servers"
+)
+
+// maxLatencySamples is the maximum number of latency samples kept for each
+// method to calculate percentiles.
+const maxLatencySamples = 1000
+
+// RPCMethodStats is the statistics of a JSON-RPC method.
+type RPCMethodStats struct {
+	Method     string                    `json:"method"`
+	Calls      uint64                    `json:"calls"`
+	Errors     uint64                    `json:"errors"`
+	ErrorRate  float64                   `json:"errorrate"`
+	P50        float64                   `json:"p50"`
+	P95        float64                   `json:"p95"`
+	MaxLatency float64                   `json:"maxlatency"`
+	ErrorCodes map[elaErr.ErrCode]uint64 `json:"errorcodes"`
+}
+
+// methodStats records calls of a JSON-RPC method.
+type methodStats struct {
+	calls      uint64
+	errors     uint64
+	maxLatency time.Duration
+	errorCodes map[elaErr.ErrCode]uint64
+
+	// samples is a ring buffer of the latest latencies.
+	samples []time.Duration
+	next    int
+}
+
+func (s *methodStats) record(latency time.Duration, code elaErr.ErrCode) {
+	s.calls++
+	if latency > s.maxLatency {
+		s.maxLatency = latency
+	}
+	if code != elaErr.Success {
+		s.errors++
+		s.errorCodes[code]++
+	}
+
+	if len(s.samples) < maxLatencySamples {
+		s.samples = append(s.samples, latency)
+		return
+	}
+	s.samples[s.next] = latency
+	s.next = (s.next + 1) % maxLatencySamples
+}
+
+func (s *methodStats) stats(method string) RPCMethodStats {
+	samples := make([]time.Duration, len(s.samples))
+	copy(samples, s.samples)
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i] < samples[j]
+	})
+
+	codes := make(map[elaErr.ErrCode]uint64, len(s.errorCodes))
+	for k, v := range s.errorCodes {
+		codes[k] = v
+	}
+
+	return RPCMethodStats{
+		Method:     method,
+		Calls:      s.calls,
+		Errors:     s.errors,
+		ErrorRate:  float64(s.errors) / float64(s.calls),
+		P50:        milliseconds(percentile(samples, 50)),
+		P95:        milliseconds(percentile(samples, 95)),
+		MaxLatency: milliseconds(s.maxLatency),
+		ErrorCodes: codes,
+	}
+}
+
+// rpcStats records calls of all JSON-RPC methods.
+type rpcStats struct {
+	mtx     sync.Mutex
+	methods map[string]*methodStats
+}
+
+func (r *rpcStats) record(method string, latency time.Duration,
+	code elaErr.ErrCode) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	s, ok := r.methods[method]
+	if !ok {
+		s = &methodStats{errorCodes: make(map[elaErr.ErrCode]uint64)}
+		r.methods[method] = s
+	}
+	s.record(latency, code)
+}
+
+// snapshot returns statistics of all methods sorted by method name.
+func (r *rpcStats) snapshot() []RPCMethodStats {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	result := make([]RPCMethodStats, 0, len(r.methods))
+	for method, s := range r.methods {
+		result = append(result, s.stats(method))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Method < result[j].Method
+	})
+	return result
+}
+
+func (r *rpcStats) reset() {
+	r.mtx.Lock()
+	r.methods = make(map[string]*methodStats)
+	r.mtx.Unlock()
+}
+
+// percentile returns the p-th percentile of the sorted samples.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := (len(sorted)*p+99)/100 - 1
+	if index < 0 {
+		index = 0
+	}
+	return sorted[index]
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+var stats = &rpcStats{methods: make(map[string]*methodStats)}
+
+// GetRPCStats returns the call statistics of JSON-RPC methods, statistics will
+// be cleared after returned if reset is true.
+func GetRPCStats(param Params) map[string]interface{} {
+	result := stats.snapshot()
+	if reset, _ := param.Bool("reset"); reset {
+		stats.reset()
+	}
+	return ResponsePack(elaErr.Success, result)
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package httprestful
+
+import "strings"
+
+// openAPIVersion is the version of the OpenAPI specification the document
+// follows.
+const openAPIVersion = "3.0.3"
+
+// v2APIVersion is the version of the v2 API described by the document.
+const v2APIVersion = "2.0.0"
+
+type object = map[string]interface{}
+
+func schemaRef(name string) object {
+	return object{"$ref": "#/components/schemas/" + name}
+}
+
+// openAPIPath converts the route path to the OpenAPI path template.
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func openAPIParam(p v2Param) object {
+	return object{
+		"name":        p.Name,
+		"in":          p.In,
+		"required":    p.In == "path",
+		"description": p.Description,
+		"schema":      object{"type": p.Type},
+	}
+}
+
+// openAPIOperation returns the operation object of the route.
+func openAPIOperation(route *v2Route) object {
+	params := make([]object, 0, len(route.Params)+2)
+	for _, p := range route.Params {
+		params = append(params, openAPIParam(p))
+	}
+
+	response := "Response"
+	if route.Paged {
+		response = "Page"
+		params = append(params,
+			openAPIParam(queryParam("offset", "integer",
+				"the number of items to skip, default 0")),
+			openAPIParam(queryParam("limit", "integer",
+				"the max number of items to return, default 100, at most "+
+					"1000")))
+	}
+
+	operation := object{
+		"operationId": route.Name,
+		"summary":     route.Summary,
+		"parameters":  params,
+		"responses": object{
+			"200": object{
+				"description": "Success",
+				"content": object{"application/json": object{
+					"schema": schemaRef(response)}},
+			},
+			"default": object{
+				"description": "Error",
+				"content": object{"application/json": object{
+					"schema": schemaRef("Error")}},
+			},
+		},
+	}
+
+	if len(route.Body) > 0 {
+		properties := object{}
+		for _, p := range route.Body {
+			properties[p.Name] = object{"type": p.Type,
+				"description": p.Description}
+		}
+		operation["requestBody"] = object{
+			"required": true,
+			"content": object{"application/json": object{
+				"schema": object{
+					"type":       "object",
+					"properties": properties,
+					"required":   []string{route.Body[0].Name},
+				},
+			}},
+		}
+	}
+	return operation
+}
+
+// openAPIDocument generates the OpenAPI document of the routes.
+func openAPIDocument(routes []v2Route) object {
+	paths := object{}
+	for i := range routes {
+		route := &routes[i]
+		path := openAPIPath(route.Path)
+		item, ok := paths[path].(object)
+		if !ok {
+			item = object{}
+			paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = openAPIOperation(route)
+	}
+
+	return object{
+		"openapi": openAPIVersion,
+		"info": object{
+			"title":   "Elastos ELA node REST API",
+			"version": v2APIVersion,
+		},
+		"paths": paths,
+		"components": object{"schemas": object{
+			"Response": object{
+				"type": "object",
+				"properties": object{
+					"data": object{"description": "the result"},
+				},
+			},
+			"Page": object{
+				"type": "object",
+				"properties": object{
+					"data": object{"type": "array", "items": object{}},
+					"pagination": object{
+						"type": "object",
+						"properties": object{
+							"offset": object{"type": "integer"},
+							"limit":  object{"type": "integer"},
+							"total":  object{"type": "integer"},
+						},
+					},
+				},
+			},
+			"Error": object{
+				"type": "object",
+				"properties": object{
+					"error": object{
+						"type": "object",
+						"properties": object{
+							"code":    object{"type": "integer"},
+							"message": object{"type": "string"},
+						},
+					},
+				},
+			},
+		}},
+	}
+}
@@ -7,7 +7,6 @@ package httprestful
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"io/ioutil"
 	"net"
@@ -73,6 +72,7 @@ func InitRestServer() ApiServer {
 	rt.initializeMethod()
 	rt.initGetHandler()
 	rt.initPostHandler()
+	rt.initV2Handler()
 	return rt
 }
 
@@ -81,21 +81,19 @@ func (rt *restServer) Start() {
 		log.Fatal("Not configure HttpRestPort port ")
 	}
 
-	if config.Parameters.HttpRestPort%1000 == servers.TlsPort {
-		var err error
-		rt.listener, err = rt.initTlsListen()
-		if err != nil {
-			log.Error("Https Cert: ", err.Error())
-		}
-	} else {
-		var err error
-		rt.listener, err = net.Listen("tcp", ":"+strconv.Itoa(config.Parameters.HttpRestPort))
-		if err != nil {
-			log.Fatal("net.Listen: ", err.Error())
-		}
+	useTLS := config.Parameters.HttpTLS.EnableRest ||
+		config.Parameters.HttpRestPort%1000 == servers.TlsPort
+	var err error
+	rt.listener, err = servers.Listen("tcp",
+		":"+strconv.Itoa(config.Parameters.HttpRestPort), useTLS)
+	if err != nil {
+		log.Fatal("net.Listen: ", err.Error())
+	}
+	if useTLS {
+		log.Info("TLS listen port is ", config.Parameters.HttpRestPort)
 	}
 	rt.server = &http.Server{Handler: rt.router}
-	err := rt.server.Serve(rt.listener)
+	err = rt.server.Serve(rt.listener)
 
 	if err != nil {
 		log.Fatal("ListenAndServe: ", err.Error())
@@ -303,28 +301,3 @@ func (rt *restServer) Restart(cmd servers.Params) map[string]interface{} {
 
 	return servers.ResponsePack(Success, "")
 }
-
-func (rt *restServer) initTlsListen() (net.Listener, error) {
-
-	CertPath := config.Parameters.RestCertPath
-	KeyPath := config.Parameters.RestKeyPath
-
-	// load cert
-	cert, err := tls.LoadX509KeyPair(CertPath, KeyPath)
-	if err != nil {
-		log.Error("load keys fail", err)
-		return nil, err
-	}
-
-	tlsConfig := &tls.Config{
-		Certificates: []tls.Certificate{cert},
-	}
-
-	log.Info("TLS listen port is ", strconv.Itoa(config.Parameters.HttpRestPort))
-	listener, err := tls.Listen("tcp", ":"+strconv.Itoa(config.Parameters.HttpRestPort), tlsConfig)
-	if err != nil {
-		log.Error(err)
-		return nil, err
-	}
-	return listener, nil
-}
@@ -0,0 +1,446 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package httprestful
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/elastos/Elastos.ELA/common/log"
+	. "github.com/elastos/Elastos.ELA/errors"
+	"github.com/elastos/Elastos.ELA/servers"
+)
+
+// The v2 API names resources by plural nouns under /api/v2, reports errors by
+// HTTP status codes with an error object, and pages lists by the offset and
+// limit query parameters.  The OpenAPI document generated from the routes is
+// served at ApiV2OpenAPI.
+
+const (
+	// ApiV2OpenAPI is the path of the OpenAPI document of the v2 API.
+	ApiV2OpenAPI = "/api/v2/openapi.json"
+
+	// defaultPageLimit and maxPageLimit are the default and the max number
+	// of items returned in a page of a list.
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+)
+
+// v2Param describes a path or query parameter of a v2 route.
+type v2Param struct {
+	// Name is the name of the parameter in the path or query.
+	Name string
+
+	// Arg is the name of the parameter passed to the handler.
+	Arg string
+
+	// In is the location of the parameter, "path" or "query".
+	In string
+
+	// Type is the JSON schema type of the parameter, "string", "integer" or
+	// "boolean".
+	Type string
+
+	// Description describes the parameter in the OpenAPI document.
+	Description string
+}
+
+// v2Route describes an operation of the v2 API.
+type v2Route struct {
+	Method  string
+	Path    string
+	Name    string
+	Summary string
+	Params  []v2Param
+
+	// Body lists the fields of the JSON request body, the fields are passed
+	// to the handler as is.
+	Body []v2Param
+
+	// Paged indicates the result is a list paged by offset and limit.
+	Paged bool
+
+	Handler func(servers.Params) map[string]interface{}
+}
+
+// v2Error is the error object of a failed v2 request.
+type v2Error struct {
+	Code    ErrCode `json:"code"`
+	Message string  `json:"message"`
+}
+
+// v2Pagination describes the page of a list returned.
+type v2Pagination struct {
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+	Total  int `json:"total"`
+}
+
+// v2Response is the response body of the v2 API, Data is set on success and
+// Error is set on failure.
+type v2Response struct {
+	Data       interface{}   `json:"data,omitempty"`
+	Pagination *v2Pagination `json:"pagination,omitempty"`
+	Error      *v2Error      `json:"error,omitempty"`
+}
+
+func pathParam(name, arg, description string) v2Param {
+	return v2Param{Name: name, Arg: arg, In: "path", Type: "string",
+		Description: description}
+}
+
+func queryParam(name, typ, description string) v2Param {
+	return v2Param{Name: name, Arg: name, In: "query", Type: typ,
+		Description: description}
+}
+
+var (
+	heightParam  = pathParam("height", "height", "the height of the block")
+	addressParam = pathParam("address", "addr", "the address")
+	assetParam   = pathParam("assetid", "assetid",
+		"the asset ID in reversed hex")
+	verbosityParam = queryParam("verbosity", "integer",
+		"0 for the serialized hex, 1 for the JSON object, 2 for the JSON "+
+			"object with transactions, default 1")
+	confirmVerbosityParam = queryParam("verbosity", "integer",
+		"0 for the serialized hex, 1 for the JSON object, default 1")
+)
+
+// v2Routes lists the operations of the v2 API.
+var v2Routes = []v2Route{
+	{
+		Method: "GET", Path: "/api/v2/node/state", Name: "getNodeState",
+		Summary: "Returns the state of the node and connected peers",
+		Params: []v2Param{queryParam("nonce", "string",
+			"hex string to be signed in the attestation")},
+		Handler: servers.GetNodeState,
+	},
+	{
+		Method: "GET", Path: "/api/v2/node/connections",
+		Name:    "getConnectionCount",
+		Summary: "Returns the number of connected peers",
+		Handler: servers.GetConnectionCount,
+	},
+	{
+		Method: "GET", Path: "/api/v2/chain/height", Name: "getBestHeight",
+		Summary: "Returns the height of the best block",
+		Handler: servers.GetBlockHeight,
+	},
+	{
+		Method: "GET", Path: "/api/v2/chain/besthash", Name: "getBestHash",
+		Summary: "Returns the hash of the best block",
+		Handler: servers.GetBestBlockHash,
+	},
+	{
+		Method: "GET", Path: "/api/v2/blocks/height/:height",
+		Name:    "getBlockByHeight",
+		Summary: "Returns the block at the height",
+		Params:  []v2Param{heightParam, verbosityParam},
+		Handler: servers.GetBlockByHeight,
+	},
+	{
+		Method: "GET", Path: "/api/v2/blocks/height/:height/hash",
+		Name:    "getBlockHash",
+		Summary: "Returns the hash of the block at the height",
+		Params:  []v2Param{heightParam},
+		Handler: servers.GetBlockHash,
+	},
+	{
+		Method: "GET", Path: "/api/v2/blocks/height/:height/transactions",
+		Name:    "getBlockTransactions",
+		Summary: "Returns the transaction hashes of the block at the height",
+		Params:  []v2Param{heightParam},
+		Paged:   true,
+		Handler: getBlockTransactions,
+	},
+	{
+		Method: "GET", Path: "/api/v2/blocks/height/:height/confirm",
+		Name:    "getConfirmByHeight",
+		Summary: "Returns the DPoS confirm of the block at the height",
+		Params:  []v2Param{heightParam, confirmVerbosityParam},
+		Handler: servers.GetConfirmByHeight,
+	},
+	{
+		Method: "GET", Path: "/api/v2/blocks/:hash", Name: "getBlockByHash",
+		Summary: "Returns the block by hash",
+		Params: []v2Param{pathParam("hash", "blockhash",
+			"the block hash in reversed hex"), verbosityParam},
+		Handler: servers.GetBlockByHash,
+	},
+	{
+		Method: "GET", Path: "/api/v2/blocks/:hash/confirm",
+		Name:    "getConfirmByHash",
+		Summary: "Returns the DPoS confirm of the block by hash",
+		Params: []v2Param{pathParam("hash", "blockhash",
+			"the block hash in reversed hex"), confirmVerbosityParam},
+		Handler: servers.GetConfirmByHash,
+	},
+	{
+		Method: "GET", Path: "/api/v2/transactions/:hash",
+		Name:    "getTransaction",
+		Summary: "Returns the transaction by hash",
+		Params: []v2Param{pathParam("hash", "hash",
+			"the transaction hash in reversed hex")},
+		Handler: servers.GetTransactionByHash,
+	},
+	{
+		Method: "POST", Path: "/api/v2/transactions",
+		Name:    "sendTransaction",
+		Summary: "Sends a serialized transaction to the network",
+		Body: []v2Param{
+			{Name: "data", Type: "string",
+				Description: "the serialized transaction in hex"},
+			{Name: "allowhighfee", Type: "boolean",
+				Description: "allow the fee higher than MaxTxFee"},
+			{Name: "verbose", Type: "boolean",
+				Description: "return the hash, size and fee of the " +
+					"transaction instead of the hash only"},
+		},
+		Handler: servers.SendRawTransaction,
+	},
+	{
+		Method: "GET", Path: "/api/v2/mempool/transactions",
+		Name:    "getMempoolTransactions",
+		Summary: "Returns the transactions in the transaction pool",
+		Params: []v2Param{queryParam("verbose", "boolean",
+			"include the fee, size and dependencies of the transactions")},
+		Paged:   true,
+		Handler: servers.GetTransactionPool,
+	},
+	{
+		Method: "GET", Path: "/api/v2/assets/:hash", Name: "getAsset",
+		Summary: "Returns the asset by ID",
+		Params: []v2Param{pathParam("hash", "hash",
+			"the asset ID in reversed hex")},
+		Handler: servers.GetAssetByHash,
+	},
+	{
+		Method: "GET", Path: "/api/v2/addresses/:address/balance",
+		Name:    "getBalance",
+		Summary: "Returns the ELA balance of the address",
+		Params:  []v2Param{addressParam},
+		Handler: servers.GetBalanceByAddr,
+	},
+	{
+		Method: "GET", Path: "/api/v2/addresses/:address/balances/:assetid",
+		Name:    "getAssetBalance",
+		Summary: "Returns the balance of the asset of the address",
+		Params:  []v2Param{addressParam, assetParam},
+		Handler: servers.GetBalanceByAsset,
+	},
+	{
+		Method: "GET", Path: "/api/v2/addresses/:address/utxos",
+		Name:    "getUTXOs",
+		Summary: "Returns the unspent outputs of the address grouped by asset",
+		Params:  []v2Param{addressParam},
+		Handler: servers.GetUnspends,
+	},
+	{
+		Method: "GET", Path: "/api/v2/addresses/:address/utxos/:assetid",
+		Name:    "getAssetUTXOs",
+		Summary: "Returns the unspent outputs of the asset of the address",
+		Params:  []v2Param{addressParam, assetParam},
+		Paged:   true,
+		Handler: servers.GetUnspendOutput,
+	},
+}
+
+// getBlockTransactions returns the transaction hashes of the block at the
+// height as a list.
+func getBlockTransactions(param servers.Params) map[string]interface{} {
+	height, ok := param.Uint("height")
+	if !ok {
+		return servers.ResponsePack(InvalidParams,
+			"height parameter should be a positive integer")
+	}
+	hash, err := servers.Chain.GetBlockHash(height)
+	if err != nil {
+		return servers.ResponsePack(UnknownBlock, "")
+	}
+	block, err := servers.Chain.GetBlockByHash(hash)
+	if err != nil {
+		return servers.ResponsePack(UnknownBlock, "")
+	}
+	hashes := make([]string, 0, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		hashes = append(hashes, servers.ToReversedString(tx.Hash()))
+	}
+	return servers.ResponsePack(Success, hashes)
+}
+
+// v2Status returns the HTTP status code of the error code.
+func v2Status(code ErrCode) int {
+	switch code {
+	case Success:
+		return http.StatusOK
+	case UnknownTransaction, UnknownAsset, UnknownBlock:
+		return http.StatusNotFound
+	case InvalidMethod:
+		return http.StatusMethodNotAllowed
+	case Error, InternalError, PowServiceNotStarted:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// page returns the items of the list in the range of offset and limit from
+// the query parameters.
+func page(r *http.Request, list interface{}) (interface{}, *v2Pagination,
+	error) {
+	pagination := v2Pagination{Limit: defaultPageLimit}
+	query := r.URL.Query()
+	if s := query.Get("offset"); s != "" {
+		offset, err := strconv.Atoi(s)
+		if err != nil || offset < 0 {
+			return nil, nil, fmt.Errorf("offset should be a non-negative" +
+				" integer")
+		}
+		pagination.Offset = offset
+	}
+	if s := query.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit <= 0 || limit > maxPageLimit {
+			return nil, nil, fmt.Errorf("limit should be an integer "+
+				"between 1 and %d", maxPageLimit)
+		}
+		pagination.Limit = limit
+	}
+
+	value := reflect.ValueOf(list)
+	if value.Kind() != reflect.Slice {
+		return list, nil, nil
+	}
+	pagination.Total = value.Len()
+	start := pagination.Offset
+	if start > pagination.Total {
+		start = pagination.Total
+	}
+	end := start + pagination.Limit
+	if end > pagination.Total {
+		end = pagination.Total
+	}
+	items := reflect.MakeSlice(value.Type(), 0, end-start)
+	items = reflect.AppendSlice(items, value.Slice(start, end))
+	return items.Interface(), &pagination, nil
+}
+
+// v2Params collects the handler parameters of the route from the request.
+func v2Params(r *http.Request, route *v2Route) (servers.Params, error) {
+	req := make(servers.Params)
+	if len(route.Body) > 0 {
+		body, err := ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, fmt.Errorf("invalid JSON body, %s", err)
+		}
+	}
+
+	query := r.URL.Query()
+	for _, p := range route.Params {
+		var value string
+		if p.In == "path" {
+			value = getParam(r, p.Name)
+		} else if value = query.Get(p.Name); value == "" {
+			continue
+		}
+		if p.Type == "boolean" {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s should be a boolean", p.Name)
+			}
+			req[p.Arg] = b
+			continue
+		}
+		req[p.Arg] = value
+	}
+	return req, nil
+}
+
+// handleV2 returns the HTTP handler of the route.
+func (rt *restServer) handleV2(route v2Route) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := v2Params(r, &route)
+		if err != nil {
+			rt.respondV2(w, IllegalDataFormat, err.Error(), nil)
+			return
+		}
+
+		resp := route.Handler(req)
+		code := resp["Error"].(ErrCode)
+		if code != Success {
+			message, _ := resp["Result"].(string)
+			rt.respondV2(w, code, message, nil)
+			return
+		}
+
+		data := resp["Result"]
+		var pagination *v2Pagination
+		if route.Paged {
+			data, pagination, err = page(r, data)
+			if err != nil {
+				rt.respondV2(w, InvalidParams, err.Error(), nil)
+				return
+			}
+		}
+		rt.respondV2(w, Success, data, pagination)
+	}
+}
+
+// respondV2 writes the data on success, or the error object with the
+// message on failure.
+func (rt *restServer) respondV2(w http.ResponseWriter, code ErrCode,
+	data interface{}, pagination *v2Pagination) {
+	resp := v2Response{Data: data, Pagination: pagination}
+	if code != Success {
+		message, _ := data.(string)
+		if message == "" {
+			message = ErrMap[code]
+		}
+		resp = v2Response{Error: &v2Error{Code: code, Message: message}}
+	}
+	body, err := json.Marshal(resp)
+	if err != nil {
+		log.Errorf("HTTP Handle - json.Marshal: %v", err)
+		code = InternalError
+		body, _ = json.Marshal(v2Response{Error: &v2Error{Code: code,
+			Message: ErrMap[code]}})
+	}
+	w.Header().Add("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("content-type", "application/json;charset=utf-8")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(v2Status(code))
+	w.Write(body)
+}
+
+// initV2Handler registers the routes of the v2 API and the OpenAPI document.
+func (rt *restServer) initV2Handler() {
+	for _, route := range v2Routes {
+		rt.router.add(route.Method, route.Path, rt.handleV2(route))
+		if route.Method == "POST" {
+			rt.router.Options(route.Path,
+				func(w http.ResponseWriter, r *http.Request) {
+					rt.write(w, []byte{})
+				})
+		}
+	}
+
+	document, err := json.Marshal(openAPIDocument(v2Routes))
+	if err != nil {
+		log.Errorf("HTTP Handle - json.Marshal OpenAPI: %v", err)
+		return
+	}
+	rt.router.Get(ApiV2OpenAPI, func(w http.ResponseWriter, r *http.Request) {
+		rt.write(w, document)
+	})
+}
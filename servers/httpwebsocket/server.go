@@ -7,7 +7,6 @@ package httpwebsocket
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"net"
 	"net/http"
@@ -29,6 +28,10 @@ import (
 const (
 	// sessionTimeout is the duration of inactivity before we time out a session.
 	sessionTimeout = time.Minute
+
+	// maxFinalitySearchDepth is the maximum number of blocks to search back
+	// from the best block to find the latest irreversible block.
+	maxFinalitySearchDepth = 100
 )
 
 var instance *Server
@@ -42,6 +45,20 @@ var (
 
 type Handler func(servers.Params) map[string]interface{}
 
+// FinalityInfo is the latest irreversible block, which has been confirmed by
+// DPoS arbiters.
+type FinalityInfo struct {
+	Height    uint32 `json:"height"`
+	BlockHash string `json:"blockhash"`
+}
+
+// blockNotification is the block info pushed to clients with the
+// irreversible flag.
+type blockNotification struct {
+	servers.BlockInfo
+	Irreversible bool `json:"irreversible"`
+}
+
 type Server struct {
 	sync.RWMutex
 	*http.Server
@@ -51,6 +68,7 @@ type Server struct {
 	connCount int64
 	sessions  *sessions
 	handlers  map[string]Handler
+	finality  atomic.Value
 }
 
 func Start() {
@@ -58,6 +76,8 @@ func Start() {
 		switch e.Type {
 		case events.ETBlockConnected:
 			SendBlock2WSclient(e.Data)
+			SendFinality2Client(e.Data)
+			SendHeaderRelay2Client(e.Data)
 
 		case events.ETTransactionAccepted:
 			SendTx2Client(e.Data)
@@ -75,24 +95,22 @@ func (s *Server) Start() {
 	s.initMethods()
 	s.Upgrader.CheckOrigin = func(r *http.Request) bool { return true }
 
-	if config.Parameters.HttpWsPort%1000 == servers.TlsPort {
-		var err error
-		s.Listener, err = s.initTlsListen()
-		if err != nil {
-			log.Error("Https Cert: ", err.Error())
-		}
-	} else {
-		var err error
-		s.Listener, err = net.Listen("tcp", ":"+strconv.Itoa(config.Parameters.HttpWsPort))
-		if err != nil {
-			log.Fatal("net.Listen: ", err.Error())
-		}
+	useTLS := config.Parameters.HttpTLS.EnableWs ||
+		config.Parameters.HttpWsPort%1000 == servers.TlsPort
+	var err error
+	s.Listener, err = servers.Listen("tcp",
+		":"+strconv.Itoa(config.Parameters.HttpWsPort), useTLS)
+	if err != nil {
+		log.Fatal("net.Listen: ", err.Error())
+	}
+	if useTLS {
+		log.Info("TLS listen port is ", config.Parameters.HttpWsPort)
 	}
 	var done = make(chan bool)
 	go s.sessionHandler(done)
 
 	s.Server = &http.Server{Handler: http.HandlerFunc(s.Handler)}
-	err := s.Serve(s.Listener)
+	err = s.Serve(s.Listener)
 
 	done <- true
 	if err != nil {
@@ -102,16 +120,19 @@ func (s *Server) Start() {
 
 func (s *Server) initMethods() {
 	s.handlers = map[string]Handler{
-		"getconnectioncount": servers.GetConnectionCount,
-		"getblockbyheight":   servers.GetBlockByHeight,
-		"getblockbyhash":     servers.GetBlockByHash,
-		"getblockheight":     servers.GetBlockHeight,
-		"gettransaction":     servers.GetTransactionByHash,
-		"getasset":           servers.GetAssetByHash,
-		"getunspendoutput":   servers.GetUnspendOutput,
-		"sendrawtransaction": servers.SendRawTransaction,
-		"heartbeat":          s.heartBeat,
-		"getsessioncount":    s.getSessionCount,
+		"getconnectioncount":   servers.GetConnectionCount,
+		"getblockbyheight":     servers.GetBlockByHeight,
+		"getblockbyhash":       servers.GetBlockByHash,
+		"getblockheight":       servers.GetBlockHeight,
+		"gettransaction":       servers.GetTransactionByHash,
+		"getasset":             servers.GetAssetByHash,
+		"getunspendoutput":     servers.GetUnspendOutput,
+		"sendrawtransaction":   servers.SendRawTransaction,
+		"heartbeat":            s.heartBeat,
+		"getsessioncount":      s.getSessionCount,
+		"subscribefinality":    s.subscribeFinality,
+		"getheaderrelays":      servers.GetHeaderRelays,
+		"subscribeheaderrelay": s.subscribeHeaderRelay,
 	}
 }
 
@@ -123,6 +144,45 @@ func (s *Server) getSessionCount(cmd servers.Params) map[string]interface{} {
 	return servers.ResponsePack(errors.Success, s.sessions.Count())
 }
 
+// subscribeFinality returns the latest irreversible block, the session will
+// be pushed with the new irreversible blocks later.
+func (s *Server) subscribeFinality(cmd servers.Params) map[string]interface{} {
+	return servers.ResponsePack(errors.Success, s.latestFinality())
+}
+
+// subscribeHeaderRelay returns the best height, the session will be pushed
+// with the header relays of new blocks later.  Headers missed before
+// subscribing can be backfilled by getheaderrelays.
+func (s *Server) subscribeHeaderRelay(cmd servers.Params) map[string]interface{} {
+	return servers.ResponsePack(errors.Success, servers.Chain.GetHeight())
+}
+
+// latestFinality returns the latest irreversible block, it searches back from
+// the best block if no irreversible block has been connected since started.
+func (s *Server) latestFinality() *FinalityInfo {
+	if info, ok := s.finality.Load().(*FinalityInfo); ok {
+		return info
+	}
+
+	height := servers.Store.GetHeight()
+	for i := 0; i < maxFinalitySearchDepth && height > 0; i++ {
+		hash, err := servers.Chain.GetBlockHash(height)
+		if err != nil {
+			break
+		}
+		if _, err := servers.Store.GetConfirm(hash); err == nil {
+			info := &FinalityInfo{
+				Height:    height,
+				BlockHash: servers.ToReversedString(hash),
+			}
+			s.finality.Store(info)
+			return info
+		}
+		height--
+	}
+	return &FinalityInfo{}
+}
+
 func (s *Server) Stop() {
 	s.Shutdown(context.Background())
 	log.Info("Close websocket ")
@@ -207,6 +267,10 @@ func (s *Server) IsValidMsg(action string, reqMsg map[string]interface{}) bool {
 		}
 	case "sendrawtransaction":
 		_, valid = reqMsg["data"]
+	case "getheaderrelays":
+		_, ok1 := reqMsg["start"]
+		_, ok2 := reqMsg["count"]
+		valid = ok1 && ok2
 	}
 	return valid
 }
@@ -238,6 +302,13 @@ func (s *Server) handle(ss *session, bysMsg []byte, r *http.Request) bool {
 		return true
 	}
 
+	if action == "subscribefinality" {
+		ss.SubscribeFinality()
+	}
+	if action == "subscribeheaderrelay" {
+		ss.SubscribeHeaderRelay()
+	}
+
 	resp := handler(req)
 	resp["Action"] = action
 
@@ -282,12 +353,84 @@ func SendBlock2WSclient(v interface{}) {
 	}
 }
 
+// SendFinality2Client pushes the block to clients subscribed finality if the
+// block is irreversible.
+func SendFinality2Client(v interface{}) {
+	block, ok := v.(*types.Block)
+	if !ok || !isIrreversible(block) {
+		return
+	}
+
+	info := &FinalityInfo{
+		Height:    block.Height,
+		BlockHash: servers.ToReversedString(block.Hash()),
+	}
+	instance.finality.Store(info)
+
+	go func() {
+		resp := servers.ResponsePack(errors.Success, info)
+		resp["Action"] = "sendfinality"
+
+		data, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("Websocket SendFinality2Client:", err)
+			return
+		}
+
+		instance.sessions.Foreach(func(v *session) {
+			if v.IsFinalitySubscriber() {
+				v.Send(data)
+			}
+		})
+	}()
+}
+
+// SendHeaderRelay2Client pushes the header relay of the block to clients
+// subscribed header relays.
+func SendHeaderRelay2Client(v interface{}) {
+	block, ok := v.(*types.Block)
+	if !ok {
+		return
+	}
+
+	go func() {
+		relay, err := servers.EncodeHeaderRelay(block)
+		if err != nil {
+			log.Error("Websocket SendHeaderRelay2Client:", err)
+			return
+		}
+		resp := servers.ResponsePack(errors.Success, relay)
+		resp["Action"] = "sendheaderrelay"
+
+		data, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("Websocket SendHeaderRelay2Client:", err)
+			return
+		}
+
+		instance.sessions.Foreach(func(v *session) {
+			if v.IsHeaderRelaySubscriber() {
+				v.Send(data)
+			}
+		})
+	}()
+}
+
+// isIrreversible returns if the block has been confirmed by DPoS arbiters.
+func isIrreversible(block *types.Block) bool {
+	_, err := servers.Store.GetConfirm(block.Hash())
+	return err == nil
+}
+
 func (s *Server) PushResult(action string, v interface{}) {
 	var result interface{}
 	switch action {
 	case "sendblock", "sendrawblock":
 		if block, ok := v.(*types.Block); ok {
-			result = servers.GetBlockInfo(block, true)
+			result = &blockNotification{
+				BlockInfo:    servers.GetBlockInfo(block, true),
+				Irreversible: isIrreversible(block),
+			}
 		}
 		//case "sendrawblock":
 		//	if block, ok := v.(*Block); ok {
@@ -321,28 +464,3 @@ func (s *Server) PushResult(action string, v interface{}) {
 		v.Send(data)
 	})
 }
-
-func (s *Server) initTlsListen() (net.Listener, error) {
-
-	CertPath := config.Parameters.RestCertPath
-	KeyPath := config.Parameters.RestKeyPath
-
-	// load cert
-	cert, err := tls.LoadX509KeyPair(CertPath, KeyPath)
-	if err != nil {
-		log.Error("load keys fail", err)
-		return nil, err
-	}
-
-	tlsConfig := &tls.Config{
-		Certificates: []tls.Certificate{cert},
-	}
-
-	log.Info("TLS listen port is ", strconv.Itoa(config.Parameters.HttpWsPort))
-	listener, err := tls.Listen("tcp", ":"+strconv.Itoa(config.Parameters.HttpWsPort), tlsConfig)
-	if err != nil {
-		log.Error(err)
-		return nil, err
-	}
-	return listener, nil
-}
@@ -18,6 +18,13 @@ type session struct {
 	id         int64
 	conn       *websocket.Conn
 	lastActive time.Time
+
+	// finality indicates the session subscribed new irreversible blocks.
+	finality bool
+
+	// headerRelay indicates the session subscribed header relays of new
+	// blocks.
+	headerRelay bool
 }
 
 func (s *session) Send(data []byte) error {
@@ -31,6 +38,38 @@ func (s *session) Send(data []byte) error {
 	return err
 }
 
+// SubscribeFinality marks the session to be pushed with new irreversible
+// blocks.
+func (s *session) SubscribeFinality() {
+	s.mtx.Lock()
+	s.finality = true
+	s.mtx.Unlock()
+}
+
+// IsFinalitySubscriber returns if the session subscribed new irreversible
+// blocks.
+func (s *session) IsFinalitySubscriber() bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.finality
+}
+
+// SubscribeHeaderRelay marks the session to be pushed with header relays of
+// new blocks.
+func (s *session) SubscribeHeaderRelay() {
+	s.mtx.Lock()
+	s.headerRelay = true
+	s.mtx.Unlock()
+}
+
+// IsHeaderRelaySubscriber returns if the session subscribed header relays of
+// new blocks.
+func (s *session) IsHeaderRelaySubscriber() bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.headerRelay
+}
+
 type sessions struct {
 	sync.Map
 }
@@ -11,7 +11,9 @@ import (
 	"encoding/hex"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/elastos/Elastos.ELA/account"
 	aux "github.com/elastos/Elastos.ELA/auxpow"
@@ -21,17 +23,25 @@ import (
 	"github.com/elastos/Elastos.ELA/common/log"
 	"github.com/elastos/Elastos.ELA/core/contract"
 	pg "github.com/elastos/Elastos.ELA/core/contract/program"
+	"github.com/elastos/Elastos.ELA/core/electionhistory"
+	"github.com/elastos/Elastos.ELA/core/rankhistory"
+	"github.com/elastos/Elastos.ELA/core/roundreward"
 	. "github.com/elastos/Elastos.ELA/core/types"
 	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/cr/draft"
 	crstate "github.com/elastos/Elastos.ELA/cr/state"
+	"github.com/elastos/Elastos.ELA/crypto"
 	"github.com/elastos/Elastos.ELA/dpos"
+	daccount "github.com/elastos/Elastos.ELA/dpos/account"
 	"github.com/elastos/Elastos.ELA/dpos/state"
 	"github.com/elastos/Elastos.ELA/elanet"
 	"github.com/elastos/Elastos.ELA/elanet/pact"
+	"github.com/elastos/Elastos.ELA/elanet/partition"
 	. "github.com/elastos/Elastos.ELA/errors"
 	"github.com/elastos/Elastos.ELA/mempool"
 	"github.com/elastos/Elastos.ELA/p2p/msg"
+	"github.com/elastos/Elastos.ELA/p2p/nat"
 	"github.com/elastos/Elastos.ELA/pow"
 	"github.com/elastos/Elastos.ELA/wallet"
 
@@ -50,7 +60,23 @@ var (
 	Arbiter     *dpos.Arbitrator
 	Arbiters    state.Arbitrators
 	Wallet      *wallet.Wallet
+	Partition   *partition.Monitor
+	DraftStore  *draft.Store
+	RankHistory *rankhistory.Store
+	RoundReward *roundreward.Store
 	emptyHash   = common.Uint168{}
+
+	// ElectionHistory is the store of the next arbiters elections, it is nil
+	// if the election history service is not enabled.
+	ElectionHistory *electionhistory.Store
+
+	// NodeIdentity is the key to sign the getnodestate attestations, it is
+	// nil if the node identity is not enabled.
+	NodeIdentity daccount.Account
+
+	// ConfigReloader reloads the non-consensus settings from config file and
+	// applies them to the running services.
+	ConfigReloader func() error
 )
 
 func ToReversedString(hash common.Uint256) string {
@@ -79,7 +105,7 @@ func GetTransactionInfo(tx *Transaction) *TransactionInfo {
 		outputs[i].AssetID = ToReversedString(v.AssetID)
 		outputs[i].OutputLock = v.OutputLock
 		outputs[i].OutputType = uint32(v.Type)
-		outputs[i].OutputPayload = getOutputPayloadInfo(v.Payload)
+		outputs[i].OutputPayload = v.Payload
 	}
 
 	attributes := make([]AttributeInfo, len(tx.Attributes))
@@ -105,15 +131,30 @@ func GetTransactionInfo(tx *Transaction) *TransactionInfo {
 		Version:        tx.Version,
 		TxType:         tx.TxType,
 		PayloadVersion: tx.PayloadVersion,
-		Payload:        getPayloadInfo(tx.Payload),
+		Payload:        tx.Payload,
 		Attributes:     attributes,
 		Inputs:         inputs,
 		Outputs:        outputs,
 		LockTime:       tx.LockTime,
 		Programs:       programs,
+		Memo:           getMemoInfo(tx),
 	}
 }
 
+// getMemoInfo returns the standard memo of the transaction, nil if the
+// transaction has no memo or the memo is not standard.
+func getMemoInfo(tx *Transaction) *MemoInfo {
+	data, ok := tx.GetMemo()
+	if !ok {
+		return nil
+	}
+	memo, err := ParseMemo(data)
+	if err != nil {
+		return nil
+	}
+	return &MemoInfo{Type: string(memo.Type), Message: memo.Message}
+}
+
 func GetTransactionContextInfo(header *Header, tx *Transaction) *TransactionContextInfo {
 	var blockHash string
 	var confirmations uint32
@@ -184,7 +225,61 @@ func GetRawTransaction(param Params) map[string]interface{} {
 	}
 }
 
+// GetTransactionReceipt returns the block and confirmation info of a
+// transaction, a transaction is irreversible if the block contains it has
+// been confirmed by DPoS arbiters.
+func GetTransactionReceipt(param Params) map[string]interface{} {
+	str, ok := param.String("txid")
+	if !ok {
+		return ResponsePack(InvalidParams, "txid not found")
+	}
+
+	hex, err := FromReversedString(str)
+	if err != nil {
+		return ResponsePack(InvalidParams, "invalid txid")
+	}
+	var hash common.Uint256
+	err = hash.Deserialize(bytes.NewReader(hex))
+	if err != nil {
+		return ResponsePack(InvalidParams, "invalid txid")
+	}
+
+	_, height, err := Store.GetTransaction(hash)
+	if err != nil {
+		if TxMemPool.GetTransaction(hash) == nil {
+			return ResponsePack(UnknownTransaction,
+				"cannot find transaction in blockchain and transactionpool")
+		}
+		return ResponsePack(Success, &TransactionReceiptInfo{TxID: str})
+	}
+
+	blockHash, err := Chain.GetBlockHash(height)
+	if err != nil {
+		return ResponsePack(UnknownBlock, "")
+	}
+	receipt := &TransactionReceiptInfo{
+		TxID:          str,
+		BlockHash:     ToReversedString(blockHash),
+		BlockHeight:   height,
+		Confirmations: Store.GetHeight() - height + 1,
+	}
+	if confirm, err := Store.GetConfirm(blockHash); err == nil {
+		receipt.Irreversible = true
+		for _, vote := range confirm.Votes {
+			if vote.Accept {
+				receipt.ConfirmArbiters++
+			}
+		}
+	}
+
+	return ResponsePack(Success, receipt)
+}
+
 func GetNeighbors(param Params) map[string]interface{} {
+	if verbose, _ := param.Bool("verbose"); verbose {
+		return ResponsePack(Success, peerInfos())
+	}
+
 	peers := Server.ConnectedPeers()
 	neighborAddrs := make([]string, 0, len(peers))
 	for _, peer := range peers {
@@ -193,28 +288,54 @@ func GetNeighbors(param Params) map[string]interface{} {
 	return ResponsePack(Success, neighborAddrs)
 }
 
-func GetNodeState(param Params) map[string]interface{} {
+func GetPeerInfo(param Params) map[string]interface{} {
+	return ResponsePack(Success, peerInfos())
+}
+
+// peerInfos returns the states and bandwidth stats of connected peers.
+func peerInfos() []*PeerInfo {
 	peers := Server.ConnectedPeers()
 	states := make([]*PeerInfo, 0, len(peers))
 	for _, peer := range peers {
 		snap := peer.ToPeer().StatsSnapshot()
 		states = append(states, &PeerInfo{
-			NetAddress:     snap.Addr,
-			Services:       pact.ServiceFlag(snap.Services).String(),
-			RelayTx:        snap.RelayTx != 0,
-			LastSend:       snap.LastSend.String(),
-			LastRecv:       snap.LastRecv.String(),
-			ConnTime:       snap.ConnTime.String(),
-			TimeOffset:     snap.TimeOffset,
-			Version:        snap.Version,
-			Inbound:        snap.Inbound,
-			StartingHeight: snap.StartingHeight,
-			LastBlock:      snap.LastBlock,
-			LastPingTime:   snap.LastPingTime.String(),
-			LastPingMicros: snap.LastPingMicros,
+			NetAddress:      snap.Addr,
+			Services:        pact.ServiceFlag(snap.Services).String(),
+			RelayTx:         snap.RelayTx != 0,
+			LastSend:        snap.LastSend.String(),
+			LastRecv:        snap.LastRecv.String(),
+			ConnTime:        snap.ConnTime.String(),
+			TimeOffset:      snap.TimeOffset,
+			Version:         snap.Version,
+			Inbound:         snap.Inbound,
+			StartingHeight:  snap.StartingHeight,
+			LastBlock:       snap.LastBlock,
+			LastPingTime:    snap.LastPingTime.String(),
+			LastPingMicros:  snap.LastPingMicros,
+			ConnDuration:    int64(time.Since(snap.ConnTime).Seconds()),
+			BytesSent:       snap.BytesSent,
+			BytesRecv:       snap.BytesRecv,
+			BytesSentPerMsg: snap.MsgBytesSent,
+			BytesRecvPerMsg: snap.MsgBytesRecv,
 		})
 	}
-	return ResponsePack(Success, ServerInfo{
+	return states
+}
+
+func GetNodeState(param Params) map[string]interface{} {
+	var nonce []byte
+	if nonceStr, ok := param.String("nonce"); ok {
+		var err error
+		nonce, err = common.HexStringToBytes(nonceStr)
+		if err != nil || len(nonce) > maxAttestationNonceSize {
+			return ResponsePack(InvalidParams, fmt.Sprintf(
+				"nonce must be a hex string of at most %d bytes",
+				maxAttestationNonceSize))
+		}
+	}
+
+	states := peerInfos()
+	info := ServerInfo{
 		Compile:   Compile,
 		Height:    Chain.GetHeight(),
 		Version:   pact.DPOSStartVersion,
@@ -224,7 +345,82 @@ func GetNodeState(param Params) map[string]interface{} {
 		RestPort:  uint16(Config.HttpRestPort),
 		WSPort:    uint16(Config.HttpWsPort),
 		Neighbors: states,
-	})
+		Partition: partitionState(),
+		NAT:       natInfo(Server.NATStatus()),
+	}
+	if Arbiter != nil {
+		info.DPoSNAT = natInfo(Arbiter.NATStatus())
+	}
+	if NodeIdentity != nil {
+		attestation, err := attestNodeState(&info, nonce)
+		if err != nil {
+			return ResponsePack(InternalError, err.Error())
+		}
+		info.Attestation = attestation
+	}
+	return ResponsePack(Success, info)
+}
+
+// maxAttestationNonceSize is the max size of the nonce in bytes to be signed
+// in the getnodestate attestation.
+const maxAttestationNonceSize = 64
+
+// attestNodeState signs the height, version and services of the node state
+// with the node identity key.
+func attestNodeState(info *ServerInfo, nonce []byte) (*NodeAttestation,
+	error) {
+	timestamp := time.Now().Unix()
+	buf := new(bytes.Buffer)
+	if err := common.WriteVarString(buf, info.Compile); err != nil {
+		return nil, err
+	}
+	if err := common.WriteElements(buf, info.Height, info.Version); err != nil {
+		return nil, err
+	}
+	if err := common.WriteVarString(buf, info.Services); err != nil {
+		return nil, err
+	}
+	if err := common.WriteUint64(buf, uint64(timestamp)); err != nil {
+		return nil, err
+	}
+	if err := common.WriteVarBytes(buf, nonce); err != nil {
+		return nil, err
+	}
+
+	signature := NodeIdentity.Sign(buf.Bytes())
+	if signature == nil {
+		return nil, fmt.Errorf("sign node state failed")
+	}
+	return &NodeAttestation{
+		PublicKey: common.BytesToHexString(NodeIdentity.PublicKeyBytes()),
+		Timestamp: timestamp,
+		Nonce:     common.BytesToHexString(nonce),
+		Signature: common.BytesToHexString(signature),
+	}, nil
+}
+
+func partitionState() *partition.State {
+	if Partition == nil {
+		return nil
+	}
+	return Partition.State()
+}
+
+func natInfo(status *nat.Status) *NATInfo {
+	if status == nil {
+		return nil
+	}
+	info := &NATInfo{
+		Method:       status.Method,
+		InternalPort: status.InternalPort,
+		ExternalPort: status.ExternalPort,
+		Mapped:       status.Mapped,
+		LastError:    status.LastError,
+	}
+	if status.ExternalIP != nil {
+		info.ExternalIP = status.ExternalIP.String()
+	}
+	return info
 }
 
 func SetLogLevel(param Params) map[string]interface{} {
@@ -237,6 +433,42 @@ func SetLogLevel(param Params) map[string]interface{} {
 	return ResponsePack(Success, fmt.Sprint("log level has been set to ", level))
 }
 
+// SetHaltHeight halts the block chain at the height as an emergency response
+// to critical consensus bugs, a zero height releases the chain.
+func SetHaltHeight(param Params) map[string]interface{} {
+	height, ok := param.Uint("height")
+	if !ok {
+		return ResponsePack(InvalidParams, "height not found")
+	}
+	if height > 0 && height < Chain.GetHeight() {
+		return ResponsePack(InvalidParams, fmt.Sprintf("halt height %d is"+
+			" lower than current height %d", height, Chain.GetHeight()))
+	}
+
+	Chain.SetHaltHeight(height)
+	if height == 0 {
+		return ResponsePack(Success, "block chain released")
+	}
+	return ResponsePack(Success, fmt.Sprint("block chain halted at height ",
+		height))
+}
+
+// GetHaltHeight returns the height the block chain halted at, zero means the
+// chain is not halted.
+func GetHaltHeight(param Params) map[string]interface{} {
+	return ResponsePack(Success, Chain.HaltHeight())
+}
+
+func ReloadConfig(param Params) map[string]interface{} {
+	if ConfigReloader == nil {
+		return ResponsePack(InternalError, "config reload not supported")
+	}
+	if err := ConfigReloader(); err != nil {
+		return ResponsePack(Error, err.Error())
+	}
+	return ResponsePack(Success, "config has been reloaded")
+}
+
 func CreateAuxBlock(param Params) map[string]interface{} {
 	payToAddr, ok := param.String("paytoaddress")
 	if !ok {
@@ -396,6 +628,478 @@ func GetArbitersInfo(params Params) map[string]interface{} {
 	return ResponsePack(Success, result)
 }
 
+func GetUnderstaffedStatus(params Params) map[string]interface{} {
+	type periodInfo struct {
+		EnterHeight uint32 `json:"enterheight"`
+		ExitHeight  uint32 `json:"exitheight"`
+	}
+	type understaffedStatus struct {
+		Understaffed bool         `json:"understaffed"`
+		Periods      []periodInfo `json:"periods"`
+	}
+
+	result := &understaffedStatus{
+		Understaffed: Arbiters.IsUnderstaffedMode(),
+		Periods:      make([]periodInfo, 0),
+	}
+	for _, p := range Arbiters.GetUnderstaffedPeriods() {
+		result.Periods = append(result.Periods, periodInfo{
+			EnterHeight: p.EnterHeight,
+			ExitHeight:  p.ExitHeight,
+		})
+	}
+	return ResponsePack(Success, result)
+}
+
+// maxDutyScheduleRounds indicates the max count of slots can be queried by
+// getcrosschaindutyschedule.
+const maxDutyScheduleRounds = 1000
+
+func GetCrossChainDutySchedule(param Params) map[string]interface{} {
+	type dutySlot struct {
+		Height  uint32 `json:"height"`
+		Arbiter string `json:"arbiter"`
+	}
+
+	rounds, ok := param.Uint("rounds")
+	if !ok || rounds == 0 || rounds > maxDutyScheduleRounds {
+		return ResponsePack(InvalidParams, fmt.Sprintf("rounds should "+
+			"be between 1 and %d", maxDutyScheduleRounds))
+	}
+
+	height := Chain.GetHeight()
+	schedule := Arbiters.GetCrossChainDutySchedule(int(rounds))
+	result := make([]dutySlot, 0, len(schedule))
+	for i, arbiter := range schedule {
+		result = append(result, dutySlot{
+			Height:  height + uint32(i),
+			Arbiter: common.BytesToHexString(arbiter),
+		})
+	}
+	return ResponsePack(Success, result)
+}
+
+// GetStateHashes returns the hashes of DPoS key frame, DPoS state key frame
+// and CR state key frame, nodes agree on the state if they return the same
+// hashes at the same height.
+func GetStateHashes(param Params) map[string]interface{} {
+	type stateHashes struct {
+		Height            uint32 `json:"height"`
+		DPoSKeyFrame      string `json:"dposkeyframe"`
+		DPoSStateKeyFrame string `json:"dposstatekeyframe"`
+		CRStateHeight     uint32 `json:"crstateheight"`
+		CRStateKeyFrame   string `json:"crstatekeyframe"`
+	}
+
+	dposStateHash, err := Arbiters.GetStateKeyFrameHash()
+	if err != nil {
+		return ResponsePack(InternalError, err.Error())
+	}
+	crHeight, crHash := Chain.GetCRCommittee().GetStateKeyFrameHash()
+	return ResponsePack(Success, stateHashes{
+		Height:            Chain.GetHeight(),
+		DPoSKeyFrame:      Arbiters.GetKeyFrameHash().String(),
+		DPoSStateKeyFrame: dposStateHash.String(),
+		CRStateHeight:     crHeight,
+		CRStateKeyFrame:   crHash.String(),
+	})
+}
+
+type FieldChangeInfo struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type ItemChangeInfo struct {
+	Key     string            `json:"key"`
+	Changes []FieldChangeInfo `json:"changes"`
+}
+
+type RewardChangeInfo struct {
+	Address string `json:"address"`
+	Old     string `json:"old"`
+	New     string `json:"new"`
+}
+
+type KeyFrameDiffInfo struct {
+	Module       string           `json:"module"`
+	FromHeight   uint32           `json:"fromheight"`
+	ToHeight     uint32           `json:"toheight"`
+	Added        []string         `json:"added"`
+	Removed      []string         `json:"removed"`
+	Changed      []ItemChangeInfo `json:"changed"`
+	AddedVotes   []string         `json:"addedvotes"`
+	RemovedVotes []string         `json:"removedvotes"`
+
+	// DPoS only
+	AddedArbiters        []string           `json:"addedarbiters,omitempty"`
+	RemovedArbiters      []string           `json:"removedarbiters,omitempty"`
+	RewardChanges        []RewardChangeInfo `json:"rewardchanges,omitempty"`
+	OldTotalVotesInRound string             `json:"oldtotalvotesinround,omitempty"`
+	NewTotalVotesInRound string             `json:"newtotalvotesinround,omitempty"`
+
+	// CR only
+	AddedMembers   []string         `json:"addedmembers,omitempty"`
+	RemovedMembers []string         `json:"removedmembers,omitempty"`
+	ChangedMembers []ItemChangeInfo `json:"changedmembers,omitempty"`
+}
+
+func dposKeyFrameDiffInfo(diff *state.KeyFrameDiff) *KeyFrameDiffInfo {
+	info := &KeyFrameDiffInfo{
+		Module:               "dpos",
+		FromHeight:           diff.FromHeight,
+		ToHeight:             diff.ToHeight,
+		Added:                diff.AddedProducers,
+		Removed:              diff.RemovedProducers,
+		AddedVotes:           diff.AddedVotes,
+		RemovedVotes:         diff.RemovedVotes,
+		AddedArbiters:        diff.AddedArbiters,
+		RemovedArbiters:      diff.RemovedArbiters,
+		OldTotalVotesInRound: diff.OldTotalVotesInRound.String(),
+		NewTotalVotesInRound: diff.NewTotalVotesInRound.String(),
+	}
+	for _, p := range diff.ChangedProducers {
+		item := ItemChangeInfo{Key: p.OwnerPublicKey}
+		for _, c := range p.Changes {
+			item.Changes = append(item.Changes, FieldChangeInfo(c))
+		}
+		info.Changed = append(info.Changed, item)
+	}
+	for _, r := range diff.RewardChanges {
+		address, _ := r.ProgramHash.ToAddress()
+		info.RewardChanges = append(info.RewardChanges, RewardChangeInfo{
+			Address: address,
+			Old:     r.Old.String(),
+			New:     r.New.String(),
+		})
+	}
+	return info
+}
+
+func crKeyFrameDiffInfo(diff *crstate.KeyFrameDiff) *KeyFrameDiffInfo {
+	addresses := func(cids []common.Uint168) []string {
+		result := make([]string, 0, len(cids))
+		for _, cid := range cids {
+			address, _ := cid.ToAddress()
+			result = append(result, address)
+		}
+		return result
+	}
+	items := func(diffs []crstate.CandidateDiff) []ItemChangeInfo {
+		result := make([]ItemChangeInfo, 0, len(diffs))
+		for _, d := range diffs {
+			address, _ := d.CID.ToAddress()
+			item := ItemChangeInfo{Key: address}
+			for _, c := range d.Changes {
+				item.Changes = append(item.Changes, FieldChangeInfo(c))
+			}
+			result = append(result, item)
+		}
+		return result
+	}
+	return &KeyFrameDiffInfo{
+		Module:         "cr",
+		FromHeight:     diff.FromHeight,
+		ToHeight:       diff.ToHeight,
+		Added:          addresses(diff.AddedCandidates),
+		Removed:        addresses(diff.RemovedCandidates),
+		Changed:        items(diff.ChangedCandidates),
+		AddedVotes:     diff.AddedVotes,
+		RemovedVotes:   diff.RemovedVotes,
+		AddedMembers:   addresses(diff.AddedMembers),
+		RemovedMembers: addresses(diff.RemovedMembers),
+		ChangedMembers: items(diff.ChangedMembers),
+	}
+}
+
+// GetKeyFrameDiff returns what changed in DPoS or CR state between the
+// checkpoints of two heights, heights not lower than the latest saved
+// checkpoint are resolved to the current state.
+func GetKeyFrameDiff(param Params) map[string]interface{} {
+	from, ok := param.Uint("from")
+	if !ok {
+		return ResponsePack(InvalidParams, "from parameter should be a positive integer")
+	}
+	to, ok := param.Uint("to")
+	if !ok {
+		return ResponsePack(InvalidParams, "to parameter should be a positive integer")
+	}
+	module, ok := param.String("module")
+	if !ok {
+		module = "dpos"
+	}
+
+	switch module {
+	case "dpos":
+		diff, err := Arbiters.DiffKeyFrames(from, to)
+		if err != nil {
+			return ResponsePack(InvalidParams, err.Error())
+		}
+		return ResponsePack(Success, dposKeyFrameDiffInfo(diff))
+	case "cr":
+		diff, err := Chain.GetCRCommittee().DiffKeyFrames(from, to)
+		if err != nil {
+			return ResponsePack(InvalidParams, err.Error())
+		}
+		return ResponsePack(Success, crKeyFrameDiffInfo(diff))
+	default:
+		return ResponsePack(InvalidParams, "module should be dpos or cr")
+	}
+}
+
+// draftHashParam parses the draft hash in reversed hex string from params.
+func draftHashParam(param Params) (*common.Uint256, bool) {
+	str, ok := param.String("drafthash")
+	if !ok {
+		return nil, false
+	}
+	bytes, err := FromReversedString(str)
+	if err != nil {
+		return nil, false
+	}
+	hash, err := common.Uint256FromBytes(bytes)
+	return hash, err == nil
+}
+
+// SubmitDraftData stores the draft data of a proposal after verifying it
+// matches the draft hash.
+func SubmitDraftData(param Params) map[string]interface{} {
+	if DraftStore == nil {
+		return ResponsePack(InternalError, "draft data service disabled")
+	}
+	hash, ok := draftHashParam(param)
+	if !ok {
+		return ResponsePack(InvalidParams, "need a valid param called drafthash")
+	}
+	str, ok := param.String("data")
+	if !ok {
+		return ResponsePack(InvalidParams, "need a param called data")
+	}
+	data, err := common.HexStringToBytes(str)
+	if err != nil {
+		return ResponsePack(InvalidParams, "hex string to bytes error")
+	}
+	if err := DraftStore.Put(*hash, data); err != nil {
+		return ResponsePack(InvalidParams, err.Error())
+	}
+	return ResponsePack(Success, ToReversedString(*hash))
+}
+
+// GetDraftData returns the draft data of the draft hash.
+func GetDraftData(param Params) map[string]interface{} {
+	if DraftStore == nil {
+		return ResponsePack(InternalError, "draft data service disabled")
+	}
+	hash, ok := draftHashParam(param)
+	if !ok {
+		return ResponsePack(InvalidParams, "need a valid param called drafthash")
+	}
+	data, err := DraftStore.Get(*hash)
+	if err == draft.ErrNotFound {
+		return ResponsePack(InvalidParams, err.Error())
+	}
+	if err != nil {
+		return ResponsePack(InternalError, err.Error())
+	}
+	return ResponsePack(Success, common.BytesToHexString(data))
+}
+
+// maxRankRecords indicates the max count of rankings returned by
+// getrankhistory at once.
+const maxRankRecords = 1000
+
+type RankRecordInfo struct {
+	Height uint32 `json:"height"`
+	Rank   uint32 `json:"rank"`
+	Votes  string `json:"votes"`
+	Total  uint32 `json:"total"`
+}
+
+// GetRankHistory returns the ranks by votes of a producer or CR candidate
+// recorded in a height range.
+func GetRankHistory(param Params) map[string]interface{} {
+	if RankHistory == nil {
+		return ResponsePack(InternalError, "rank history service disabled")
+	}
+	start, ok := param.Uint("start")
+	if !ok {
+		return ResponsePack(InvalidParams, "start parameter should be a positive integer")
+	}
+	end, ok := param.Uint("end")
+	if !ok {
+		end = Chain.GetHeight()
+	}
+	if end < start {
+		return ResponsePack(InvalidParams, "end should not be lower than start")
+	}
+	if (end-start)/RankHistory.Interval() >= maxRankRecords {
+		return ResponsePack(InvalidParams, fmt.Sprintf("too many rankings "+
+			"in the height range, the max count is %d", maxRankRecords))
+	}
+
+	var kind rankhistory.Kind
+	var key []byte
+	if str, ok := param.String("publickey"); ok {
+		publicKey, err := common.HexStringToBytes(str)
+		if err != nil {
+			return ResponsePack(InvalidParams, "invalid publickey")
+		}
+		kind, key = rankhistory.Producer, publicKey
+	} else if str, ok := param.String("cid"); ok {
+		cid, err := common.Uint168FromAddress(str)
+		if err != nil {
+			return ResponsePack(InvalidParams, "invalid cid")
+		}
+		kind, key = rankhistory.Candidate, cid.Bytes()
+	} else {
+		return ResponsePack(InvalidParams, "need a param called publickey or cid")
+	}
+
+	records, err := RankHistory.History(kind, key, start, end)
+	if err != nil {
+		return ResponsePack(InternalError, err.Error())
+	}
+	result := make([]RankRecordInfo, 0, len(records))
+	for _, r := range records {
+		result = append(result, RankRecordInfo{
+			Height: r.Height,
+			Rank:   r.Rank,
+			Votes:  r.Votes.String(),
+			Total:  r.Total,
+		})
+	}
+	return ResponsePack(Success, result)
+}
+
+type RewardInfo struct {
+	Address string `json:"address"`
+	Amount  string `json:"amount"`
+}
+
+type RoundRewardInfo struct {
+	Height  uint32       `json:"height"`
+	Rewards []RewardInfo `json:"rewards"`
+	Change  string       `json:"change"`
+	Total   string       `json:"total"`
+}
+
+// GetRoundRewards returns how the DPoS reward of a round is split by the
+// coinbase of the block at the height.
+func GetRoundRewards(param Params) map[string]interface{} {
+	if RoundReward == nil {
+		return ResponsePack(InternalError, "round reward service disabled")
+	}
+	height, ok := param.Uint("height")
+	if !ok {
+		return ResponsePack(InvalidParams, "height parameter should be a positive integer")
+	}
+
+	reward, err := RoundReward.Get(height)
+	if err != nil {
+		return ResponsePack(UnknownBlock, "no round reward distributed at the height")
+	}
+	result := RoundRewardInfo{
+		Height:  reward.Height,
+		Rewards: make([]RewardInfo, 0, len(reward.Rewards)),
+		Change:  reward.Change.String(),
+		Total:   reward.Total().String(),
+	}
+	for _, r := range reward.Rewards {
+		address, err := r.ProgramHash.ToAddress()
+		if err != nil {
+			return ResponsePack(InternalError, err.Error())
+		}
+		result.Rewards = append(result.Rewards, RewardInfo{
+			Address: address,
+			Amount:  r.Amount.String(),
+		})
+	}
+	return ResponsePack(Success, result)
+}
+
+type ElectedProducerInfo struct {
+	Rank           uint32 `json:"rank"`
+	OwnerPublicKey string `json:"ownerpublickey"`
+	NodePublicKey  string `json:"nodepublickey"`
+	Votes          string `json:"votes"`
+	EffectiveVotes string `json:"effectivevotes"`
+	Elected        string `json:"elected"`
+}
+
+type ElectionResultInfo struct {
+	Round       uint32                `json:"round"`
+	Height      uint32                `json:"height"`
+	Mode        string                `json:"mode"`
+	CRCArbiters []string              `json:"crcarbiters"`
+	Arbiters    []string              `json:"arbiters"`
+	Candidates  []string              `json:"candidates"`
+	Producers   []ElectedProducerInfo `json:"producers"`
+}
+
+// GetElectionResult returns the result of a next arbiters election by the
+// round, or the latest election at or below the height, the latest election
+// is returned if neither is specified.
+func GetElectionResult(param Params) map[string]interface{} {
+	if ElectionHistory == nil {
+		return ResponsePack(InternalError, "election history service disabled")
+	}
+
+	var election *electionhistory.Election
+	var err error
+	if round, ok := param.Uint("round"); ok {
+		election, err = ElectionHistory.GetByRound(round)
+	} else if height, ok := param.Uint("height"); ok {
+		election, err = ElectionHistory.GetByHeight(height)
+	} else {
+		election, err = ElectionHistory.GetByHeight(Chain.GetHeight())
+	}
+	if err != nil {
+		return ResponsePack(UnknownBlock, "no election recorded")
+	}
+
+	keys := func(keys [][]byte) []string {
+		result := make([]string, 0, len(keys))
+		for _, key := range keys {
+			result = append(result, common.BytesToHexString(key))
+		}
+		return result
+	}
+	elected := make(map[string]string)
+	for _, key := range election.Arbiters {
+		elected[common.BytesToHexString(key)] = "arbiter"
+	}
+	for _, key := range election.Candidates {
+		elected[common.BytesToHexString(key)] = "candidate"
+	}
+
+	result := ElectionResultInfo{
+		Round:       election.Round,
+		Height:      election.Height,
+		Mode:        election.Mode.String(),
+		CRCArbiters: keys(election.CRCArbiters),
+		Arbiters:    keys(election.Arbiters),
+		Candidates:  keys(election.Candidates),
+		Producers:   make([]ElectedProducerInfo, 0, len(election.Producers)),
+	}
+	for i, p := range election.Producers {
+		nodePublicKey := common.BytesToHexString(p.NodePublicKey)
+		status, ok := elected[nodePublicKey]
+		if !ok {
+			status = "none"
+		}
+		result.Producers = append(result.Producers, ElectedProducerInfo{
+			Rank:           uint32(i + 1),
+			OwnerPublicKey: common.BytesToHexString(p.OwnerPublicKey),
+			NodePublicKey:  nodePublicKey,
+			Votes:          p.Votes.String(),
+			EffectiveVotes: p.EffectiveVotes.String(),
+			Elected:        status,
+		})
+	}
+	return ResponsePack(Success, result)
+}
+
 func GetInfo(param Params) map[string]interface{} {
 	RetVal := struct {
 		Version       uint32 `json:"version"`
@@ -454,6 +1158,79 @@ func GetMiningInfo(param Params) map[string]interface{} {
 	return ResponsePack(Success, miningInfo)
 }
 
+type MiningCandidateTxInfo struct {
+	TxID     string `json:"txid"`
+	Type     string `json:"type"`
+	Size     int    `json:"size"`
+	Fee      string `json:"fee"`
+	FeePerKB string `json:"feeperkb"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+type MiningCandidateInfo struct {
+	Height            uint32                  `json:"height"`
+	PreviousBlockHash string                  `json:"previousblockhash"`
+	Size              int                     `json:"size"`
+	TotalFee          string                  `json:"totalfee"`
+	CoinBaseValue     string                  `json:"coinbasevalue"`
+	PayloadMix        map[string]int          `json:"payloadmix"`
+	Transactions      []MiningCandidateTxInfo `json:"transactions"`
+	Excluded          []MiningCandidateTxInfo `json:"excluded"`
+}
+
+func newMiningCandidateTxInfo(tx *Transaction,
+	reason string) MiningCandidateTxInfo {
+	return MiningCandidateTxInfo{
+		TxID:     ToReversedString(tx.Hash()),
+		Type:     tx.TxType.Name(),
+		Size:     tx.GetSize(),
+		Fee:      tx.Fee.String(),
+		FeePerKB: tx.FeePerKB.String(),
+		Reason:   reason,
+	}
+}
+
+// GetMiningCandidate returns the transactions, fees and payload mix of the
+// block the node would generate next, and the transactions of the memory pool
+// excluded from the block with the reasons.
+func GetMiningCandidate(param Params) map[string]interface{} {
+	payToAddr, ok := param.String("paytoaddress")
+	if !ok {
+		payToAddr = Pow.PayToAddr
+	}
+	if payToAddr == "" {
+		return ResponsePack(InvalidParams, "parameter paytoaddress not found")
+	}
+
+	preview, err := Pow.PreviewBlock(payToAddr)
+	if err != nil {
+		return ResponsePack(InternalError, "preview block failed, "+
+			err.Error())
+	}
+
+	block := preview.Block
+	info := MiningCandidateInfo{
+		Height:            block.Height,
+		PreviousBlockHash: ToReversedString(block.Previous),
+		Size:              block.GetSize(),
+		TotalFee:          preview.TotalFee.String(),
+		CoinBaseValue:     block.Transactions[0].Outputs[1].Value.String(),
+		PayloadMix:        make(map[string]int),
+		Transactions:      make([]MiningCandidateTxInfo, 0),
+		Excluded:          make([]MiningCandidateTxInfo, 0),
+	}
+	for _, tx := range block.Transactions[1:] {
+		info.PayloadMix[tx.TxType.Name()]++
+		info.Transactions = append(info.Transactions,
+			newMiningCandidateTxInfo(tx, ""))
+	}
+	for _, e := range preview.Excluded {
+		info.Excluded = append(info.Excluded,
+			newMiningCandidateTxInfo(e.Tx, e.Reason))
+	}
+	return ResponsePack(Success, &info)
+}
+
 func ToggleMining(param Params) map[string]interface{} {
 	mining, ok := param.Bool("mining")
 	if !ok {
@@ -496,16 +1273,151 @@ func DiscreteMining(param Params) map[string]interface{} {
 	return ResponsePack(Success, ret)
 }
 
+// FlushMemPool removes all transactions from the memory pool and returns the
+// number of removed transactions, it's used to control the environment of
+// test networks and not available on the main net.
+func FlushMemPool(param Params) map[string]interface{} {
+	if ChainParams.Magic == config.DefaultParams.Magic {
+		return ResponsePack(InvalidMethod,
+			"flushmempool is not available on the main net")
+	}
+	return ResponsePack(Success, TxMemPool.Flush())
+}
+
 func GetConnectionCount(param Params) map[string]interface{} {
 	return ResponsePack(Success, Server.ConnectedCount())
 }
 
+// feeHistogramBounds defines the lower bounds of fee rate (sela per KB) of
+// each bucket in the mempool fee histogram.
+var feeHistogramBounds = []common.Fixed64{0, 100, 1000, 5000, 10000, 20000,
+	50000, 100000, 1000000}
+
 func GetTransactionPool(param Params) map[string]interface{} {
-	txs := make([]*TransactionContextInfo, 0)
-	for _, tx := range TxMemPool.GetTxsInPool() {
-		txs = append(txs, GetTransactionContextInfo(nil, tx))
+	verbose, _ := param.Bool("verbose")
+	if !verbose {
+		txs := make([]*TransactionContextInfo, 0)
+		for _, tx := range TxMemPool.GetTxsInPool() {
+			txs = append(txs, GetTransactionContextInfo(nil, tx))
+		}
+		return ResponsePack(Success, txs)
+	}
+
+	descs := TxMemPool.TxDescs()
+	inPool := make(map[common.Uint256]struct{}, len(descs))
+	for _, desc := range descs {
+		inPool[desc.Tx.Hash()] = struct{}{}
+	}
+
+	result := make(map[string]*MemPoolTxInfo, len(descs))
+	for _, desc := range descs {
+		depends := make([]string, 0)
+		dependSet := make(map[common.Uint256]struct{})
+		for _, input := range desc.Tx.Inputs {
+			txID := input.Previous.TxID
+			if _, ok := inPool[txID]; !ok {
+				continue
+			}
+			if _, ok := dependSet[txID]; ok {
+				continue
+			}
+			dependSet[txID] = struct{}{}
+			depends = append(depends, ToReversedString(txID))
+		}
+		sort.Strings(depends)
+
+		result[ToReversedString(desc.Tx.Hash())] = &MemPoolTxInfo{
+			Size:     uint32(desc.Size),
+			Fee:      desc.Tx.Fee.String(),
+			FeePerKB: desc.Tx.FeePerKB.String(),
+			Time:     desc.Added.Unix(),
+			Height:   desc.Height,
+			Depends:  depends,
+		}
 	}
-	return ResponsePack(Success, txs)
+	return ResponsePack(Success, result)
+}
+
+func GetMemPoolInfo(param Params) map[string]interface{} {
+	histogram := make([]FeeHistogramInfo, len(feeHistogramBounds))
+	for i, from := range feeHistogramBounds {
+		histogram[i].FeeRateFrom = from.String()
+		if i+1 < len(feeHistogramBounds) {
+			histogram[i].FeeRateTo = feeHistogramBounds[i+1].String()
+		}
+	}
+
+	descs := TxMemPool.TxDescs()
+	for _, desc := range descs {
+		index := sort.Search(len(feeHistogramBounds), func(i int) bool {
+			return feeHistogramBounds[i] > desc.Tx.FeePerKB
+		}) - 1
+		if index < 0 {
+			index = 0
+		}
+		histogram[index].Count++
+		histogram[index].Bytes += desc.Size
+	}
+
+	return ResponsePack(Success, &MemPoolInfo{
+		Size:         len(descs),
+		Bytes:        TxMemPool.GetTxPoolSize(),
+		MaxBytes:     pact.MaxTxPoolSize,
+		FeeHistogram: histogram,
+		Rejections:   TxMemPool.RejectionCounts(),
+	})
+}
+
+type RejectedTxInfo struct {
+	TxID   string `json:"txid"`
+	Code   int    `json:"code"`
+	Reason string `json:"reason"`
+	Time   int64  `json:"time"`
+}
+
+// GetRejectedTx returns why and when a transaction was rejected by the
+// transaction pool, only the recently rejected transactions are kept.
+func GetRejectedTx(param Params) map[string]interface{} {
+	str, ok := param.String("txid")
+	if !ok {
+		return ResponsePack(InvalidParams, "txid not found")
+	}
+
+	hex, err := FromReversedString(str)
+	if err != nil {
+		return ResponsePack(InvalidParams, "invalid txid")
+	}
+	var hash common.Uint256
+	err = hash.Deserialize(bytes.NewReader(hex))
+	if err != nil {
+		return ResponsePack(InvalidParams, "invalid txid")
+	}
+
+	rejected, ok := TxMemPool.GetRejectedTx(hash)
+	if !ok {
+		return ResponsePack(UnknownTransaction,
+			"transaction not rejected recently")
+	}
+	return ResponsePack(Success, &RejectedTxInfo{
+		TxID:   ToReversedString(rejected.TxID),
+		Code:   int(rejected.Code),
+		Reason: rejected.Reason,
+		Time:   rejected.Time.Unix(),
+	})
+}
+
+func GetTxPolicy(param Params) map[string]interface{} {
+	policy := TxMemPool.TxPolicy()
+	return ResponsePack(Success, &TxPolicyInfo{
+		MaxTxSize:               policy.MaxTxSize,
+		MaxInputs:               policy.MaxInputs,
+		MaxOutputs:              policy.MaxOutputs,
+		MaxProgramCodeSize:      policy.MaxProgramCodeSize,
+		MaxProgramParameterSize: policy.MaxProgramParameterSize,
+		MaxMemoSize:             policy.MaxMemoSize,
+		MinTxFee:                ChainParams.MinTransactionFee.String(),
+		MaxMemPoolBytes:         pact.MaxTxPoolSize,
+	})
 }
 
 func GetBlockInfo(block *Block, verbose bool) BlockInfo {
@@ -626,6 +1538,155 @@ func GetBlockByHash(param Params) map[string]interface{} {
 	return ResponsePack(error, result)
 }
 
+func GetAuxPowSummary(auxPow *aux.AuxPow) AuxPowSummary {
+	return AuxPowSummary{
+		ParentHash:         ToReversedString(auxPow.ParentHash),
+		ParentBlockHash:    ToReversedString(auxPow.ParBlockHeader.Hash()),
+		ParentVersion:      auxPow.ParBlockHeader.Version,
+		ParentTime:         auxPow.ParBlockHeader.Timestamp,
+		ParentBits:         auxPow.ParBlockHeader.Bits,
+		ParentNonce:        auxPow.ParBlockHeader.Nonce,
+		ParentCoinbaseTx:   ToReversedString(auxPow.ParCoinbaseTx.Hash()),
+		AuxMerkleIndex:     auxPow.AuxMerkleIndex,
+		AuxMerkleBranchLen: len(auxPow.AuxMerkleBranch),
+		ParMerkleIndex:     auxPow.ParMerkleIndex,
+	}
+}
+
+func GetBlockHeaderInfo(header *Header) BlockHeaderInfo {
+	var versionBytes [4]byte
+	binary.BigEndian.PutUint32(versionBytes[:], header.Version)
+
+	versionBits := make([]uint32, 0)
+	for bit := uint32(0); bit < 32; bit++ {
+		if header.Version&(1<<bit) != 0 {
+			versionBits = append(versionBits, bit)
+		}
+	}
+
+	nextBlockHash, _ := Chain.GetBlockHash(header.Height + 1)
+
+	hash := header.Hash()
+	_, err := Store.GetConfirm(hash)
+
+	return BlockHeaderInfo{
+		Hash:              ToReversedString(hash),
+		Confirmations:     Chain.GetHeight() - header.Height + 1,
+		Height:            header.Height,
+		Version:           header.Version,
+		VersionHex:        common.BytesToHexString(versionBytes[:]),
+		VersionBits:       versionBits,
+		MerkleRoot:        ToReversedString(header.MerkleRoot),
+		Time:              header.Timestamp,
+		Nonce:             header.Nonce,
+		Bits:              header.Bits,
+		Difficulty:        Chain.CalcCurrentDifficulty(header.Bits),
+		PreviousBlockHash: ToReversedString(header.Previous),
+		NextBlockHash:     ToReversedString(nextBlockHash),
+		AuxPow:            GetAuxPowSummary(&header.AuxPow),
+		Confirmed:         err == nil,
+	}
+}
+
+// hashFromReversedString parses the hash in the reversed hex string.
+func hashFromReversedString(str string) (*common.Uint256, error) {
+	hashBytes, err := FromReversedString(str)
+	if err != nil {
+		return nil, err
+	}
+	return common.Uint256FromBytes(hashBytes)
+}
+
+func getBlockHeader(header *Header, verbose uint32) interface{} {
+	if verbose == 0 {
+		w := new(bytes.Buffer)
+		header.Serialize(w)
+		return common.BytesToHexString(w.Bytes())
+	}
+	return GetBlockHeaderInfo(header)
+}
+
+// GetBlockHeader returns the header of a block without transactions, for
+// light clients that do not need full blocks.
+func GetBlockHeader(param Params) map[string]interface{} {
+	str, ok := param.String("blockhash")
+	if !ok {
+		return ResponsePack(InvalidParams, "block hash not found")
+	}
+
+	var hash common.Uint256
+	hashBytes, err := FromReversedString(str)
+	if err != nil {
+		return ResponsePack(InvalidParams, "invalid block hash")
+	}
+	if err := hash.Deserialize(bytes.NewReader(hashBytes)); err != nil {
+		return ResponsePack(InvalidParams, "invalid block hash")
+	}
+
+	verbosity, ok := param.Uint("verbosity")
+	if !ok {
+		verbosity = 1
+	}
+
+	header, err := Chain.GetHeader(hash)
+	if err != nil {
+		return ResponsePack(UnknownBlock, "")
+	}
+
+	return ResponsePack(Success, getBlockHeader(header, verbosity))
+}
+
+// GetBlockHeaders returns the headers of the blocks after the first known
+// block in the locator, until the stop hash or up to count headers.
+func GetBlockHeaders(param Params) map[string]interface{} {
+	locatorStrs, ok := param.ArrayString("locator")
+	if !ok || len(locatorStrs) == 0 ||
+		len(locatorStrs) > msg.MaxBlockLocatorsPerMsg {
+		return ResponsePack(InvalidParams, fmt.Sprintf("locator parameter "+
+			"should be an array of 1 to %d block hashes",
+			msg.MaxBlockLocatorsPerMsg))
+	}
+	locator := make([]*common.Uint256, 0, len(locatorStrs))
+	for _, str := range locatorStrs {
+		hash, err := hashFromReversedString(str)
+		if err != nil {
+			return ResponsePack(InvalidParams, "invalid locator hash "+str)
+		}
+		locator = append(locator, hash)
+	}
+
+	stopHash := &common.EmptyHash
+	if str, ok := param.String("stophash"); ok && str != "" {
+		hash, err := hashFromReversedString(str)
+		if err != nil {
+			return ResponsePack(InvalidParams, "invalid stop hash")
+		}
+		stopHash = hash
+	}
+
+	count, ok := param.Uint("count")
+	if !ok {
+		count = msg.MaxBlockHeadersPerMsg
+	}
+	if count == 0 || count > msg.MaxBlockHeadersPerMsg {
+		return ResponsePack(InvalidParams, fmt.Sprintf("count parameter "+
+			"should be between 1 and %d", msg.MaxBlockHeadersPerMsg))
+	}
+
+	verbosity, ok := param.Uint("verbosity")
+	if !ok {
+		verbosity = 1
+	}
+
+	headers := Chain.LocateHeaders(locator, stopHash, count)
+	result := make([]interface{}, 0, len(headers))
+	for _, header := range headers {
+		result = append(result, getBlockHeader(header, verbosity))
+	}
+
+	return ResponsePack(Success, result)
+}
+
 func GetConfirmByHeight(param Params) map[string]interface{} {
 	height, ok := param.Uint("height")
 	if !ok {
@@ -685,11 +1746,105 @@ func SendRawTransaction(param Params) map[string]interface{} {
 		return ResponsePack(InvalidTransaction, err.Error())
 	}
 
+	allowHighFee, _ := param.Bool("allowhighfee")
+	if !allowHighFee {
+		if err := checkAbsurdFee(&txn); err != nil {
+			return ResponsePack(ErrTransactionHighFee, err.Error())
+		}
+	}
+
 	if err := VerifyAndSendTx(&txn); err != nil {
 		return ResponsePack(err.(ErrCode), err.Error())
 	}
 
-	return ResponsePack(Success, ToReversedString(txn.Hash()))
+	verbose, _ := param.Bool("verbose")
+	if !verbose {
+		return ResponsePack(Success, ToReversedString(txn.Hash()))
+	}
+
+	// Fee and FeePerKB are calculated while checking transaction context.
+	return ResponsePack(Success, &SendRawTransactionInfo{
+		TxID:     ToReversedString(txn.Hash()),
+		Size:     uint32(txn.GetSize()),
+		Fee:      txn.Fee.String(),
+		FeePerKB: txn.FeePerKB.String(),
+		Checks:   mempool.AcceptanceChecks,
+	})
+}
+
+// maxRawTxFeePerKB indicates the max fee rate (sela per KB) of a raw
+// transaction accepted by sendrawtransaction without allowhighfee.
+const maxRawTxFeePerKB common.Fixed64 = 100000000
+
+// checkAbsurdFee returns an error if the ELA fee rate of the transaction is
+// higher than maxRawTxFeePerKB, which is usually caused by a wrong change
+// output.
+func checkAbsurdFee(tx *Transaction) error {
+	references, err := Chain.UTXOCache.GetTxReference(tx)
+	if err != nil {
+		// Leave unknown references to be rejected by transaction pool.
+		return nil
+	}
+	size := tx.GetSize()
+	if size == 0 {
+		return nil
+	}
+	fee := blockchain.GetTxFee(tx, config.ELAAssetID, references)
+	if feePerKB := fee * 1000 / common.Fixed64(size); feePerKB > maxRawTxFeePerKB {
+		return fmt.Errorf("fee rate %s per KB is higher than %s, use "+
+			"allowhighfee to send it anyway", feePerKB, maxRawTxFeePerKB)
+	}
+	return nil
+}
+
+// maxPackageCount indicates the max count of transactions can be tested by
+// testmempoolaccept at once.
+const maxPackageCount = 25
+
+// TestMempoolAccept checks whether a package of raw transactions would be
+// accepted by the transaction pool in order, without adding them to the pool
+// or relaying them. The package is allowed only if all of the transactions
+// are allowed.
+func TestMempoolAccept(param Params) map[string]interface{} {
+	rawTxs, ok := param.ArrayString("rawtxs")
+	if !ok || len(rawTxs) == 0 || len(rawTxs) > maxPackageCount {
+		return ResponsePack(InvalidParams, fmt.Sprintf("rawtxs should be "+
+			"an array of 1 to %d raw transactions", maxPackageCount))
+	}
+
+	txs := make([]*Transaction, 0, len(rawTxs))
+	for _, rawTx := range rawTxs {
+		bys, err := common.HexStringToBytes(rawTx)
+		if err != nil {
+			return ResponsePack(InvalidParams, "hex string to bytes error")
+		}
+		var txn Transaction
+		if err := txn.Deserialize(bytes.NewReader(bys)); err != nil {
+			return ResponsePack(InvalidTransaction, err.Error())
+		}
+		txs = append(txs, &txn)
+	}
+
+	codes := TxMemPool.TestPackageAccept(txs)
+	result := PackageAcceptInfo{
+		Allowed:      true,
+		Transactions: make([]MempoolAcceptInfo, 0, len(txs)),
+	}
+	for i, txn := range txs {
+		info := MempoolAcceptInfo{
+			TxID:    ToReversedString(txn.Hash()),
+			Allowed: codes[i] == Success,
+			Size:    uint32(txn.GetSize()),
+		}
+		if info.Allowed {
+			info.Fee = txn.Fee.String()
+		} else {
+			info.Reason = codes[i].Error()
+			result.Allowed = false
+		}
+		result.Transactions = append(result.Transactions, info)
+	}
+	return ResponsePack(Success, result)
 }
 
 func GetBlockHeight(param Params) map[string]interface{} {
@@ -773,6 +1928,80 @@ func GetBlockByHeight(param Params) map[string]interface{} {
 	return ResponsePack(errCode, result)
 }
 
+// GetChainTips returns the tips of the main chain, the side chains and the
+// verified headers chain known by the node.
+func GetChainTips(param Params) map[string]interface{} {
+	tips := Chain.GetChainTips()
+	result := make([]ChainTipInfo, 0, len(tips))
+	for _, tip := range tips {
+		info := ChainTipInfo{
+			Height:    tip.Height,
+			Hash:      ToReversedString(tip.Hash),
+			BranchLen: tip.BranchLen,
+			Status:    string(tip.Status),
+			Confirmed: tip.Confirmed,
+		}
+		if tip.WorkSum != nil {
+			info.ChainWork = tip.WorkSum.Text(16)
+		}
+		result = append(result, info)
+	}
+	return ResponsePack(Success, result)
+}
+
+// defaultMaxBlocksRange is the max number of blocks returned by
+// getblocksrange if MaxBlocksRange is not configured.
+const defaultMaxBlocksRange = 100
+
+func maxBlocksRange() uint32 {
+	if Config != nil && Config.RpcConfiguration.MaxBlocksRange > 0 {
+		return Config.RpcConfiguration.MaxBlocksRange
+	}
+	return defaultMaxBlocksRange
+}
+
+// GetBlocksRange returns count consecutive blocks from the start height, the
+// blocks above the best height are omitted. Verbosity is the same as getblock.
+func GetBlocksRange(param Params) map[string]interface{} {
+	start, ok := param.Uint("start")
+	if !ok {
+		return ResponsePack(InvalidParams, "start parameter should be a positive integer")
+	}
+	count, ok := param.Uint("count")
+	if !ok || count == 0 || count > maxBlocksRange() {
+		return ResponsePack(InvalidParams, fmt.Sprintf("count parameter "+
+			"should be between 1 and %d", maxBlocksRange()))
+	}
+	verbosity, ok := param.Uint("verbosity")
+	if !ok {
+		verbosity = 1
+	}
+
+	bestHeight := Chain.GetHeight()
+	if start > bestHeight {
+		return ResponsePack(UnknownBlock, "start height is above the best height")
+	}
+	end := start + count - 1
+	if end > bestHeight || end < start {
+		end = bestHeight
+	}
+
+	blocks := make([]interface{}, 0, end-start+1)
+	for height := start; height <= end; height++ {
+		hash, err := Chain.GetBlockHash(height)
+		if err != nil {
+			return ResponsePack(UnknownBlock, err.Error())
+		}
+		block, errCode := getBlock(hash, verbosity)
+		if errCode != Success {
+			return ResponsePack(errCode, block)
+		}
+		blocks = append(blocks, block)
+	}
+
+	return ResponsePack(Success, blocks)
+}
+
 func GetArbitratorGroupByHeight(param Params) map[string]interface{} {
 	height, ok := param.Uint("height")
 	if !ok {
@@ -902,6 +2131,23 @@ func GetReceivedByAddress(param Params) map[string]interface{} {
 	return ResponsePack(Success, balance.String())
 }
 
+// parseOutPoint parses the out point in format of "txid:vout".
+func parseOutPoint(str string) (*OutPoint, error) {
+	parts := strings.Split(str, ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("out point should be txid:vout")
+	}
+	txID, err := hashFromReversedString(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	index, err := strconv.ParseUint(parts[1], 10, 16)
+	if err != nil {
+		return nil, err
+	}
+	return &OutPoint{TxID: *txID, Index: uint16(index)}, nil
+}
+
 func GetUTXOsByAmount(param Params) map[string]interface{} {
 	bestHeight := Chain.GetHeight()
 
@@ -931,11 +2177,25 @@ func GetUTXOsByAmount(param Params) map[string]interface{} {
 			return ResponsePack(InvalidParams, "invalid utxotype")
 		}
 	}
+	excludes := make(map[OutPoint]struct{})
+	if outPoints, ok := param.ArrayString("excludes"); ok {
+		for _, str := range outPoints {
+			op, err := parseOutPoint(str)
+			if err != nil {
+				return ResponsePack(InvalidParams, "invalid exclude "+str)
+			}
+			excludes[*op] = struct{}{}
+		}
+	}
 	totalAmount := common.Fixed64(0)
 	for _, unspent := range unspent[config.ELAAssetID] {
 		if totalAmount >= *amount {
 			break
 		}
+		if _, ok := excludes[OutPoint{TxID: unspent.TxID,
+			Index: uint16(unspent.Index)}]; ok {
+			continue
+		}
 		tx, height, err := Store.GetTransaction(unspent.TxID)
 		if err != nil {
 			return ResponsePack(InternalError, "unknown transaction "+
@@ -949,7 +2209,8 @@ func GetUTXOsByAmount(param Params) map[string]interface{} {
 			tx.Outputs[unspent.Index].Type == OTVote {
 			continue
 		}
-		if tx.TxType == CoinBase && bestHeight-height < config.DefaultParams.CoinbaseMaturity {
+		if tx.TxType == CoinBase && bestHeight-height < ChainParams.
+			GetRewardPolicy(bestHeight).CoinbaseMaturity {
 			continue
 		}
 		totalAmount += unspent.Value
@@ -1050,12 +2311,52 @@ func ListUnspent(param Params) map[string]interface{} {
 				Address:       address,
 				OutputLock:    tx.Outputs[unspent.Index].OutputLock,
 				Confirmations: bestHeight - height + 1,
+				Memo:          getMemoInfo(tx),
 			})
 		}
 	}
 	return ResponsePack(Success, result)
 }
 
+// ListMemos returns the standard memos of transactions paying to the address
+// in the wallet, the parameters are address, start and end height, the
+// heights are optional.
+func ListMemos(param Params) map[string]interface{} {
+	address, ok := param.String("address")
+	if !ok {
+		return ResponsePack(InvalidParams, "address not found")
+	}
+	if _, err := common.Uint168FromAddress(address); err != nil {
+		return ResponsePack(InvalidParams, "invalid address")
+	}
+	start, _ := param.Uint("start")
+	end, ok := param.Uint("end")
+	if !ok {
+		end = Chain.GetHeight()
+	}
+	if start > end {
+		return ResponsePack(InvalidParams, "start is higher than end")
+	}
+
+	records := Wallet.ListMemos(address, start, end)
+	result := make([]MemoRecordInfo, 0, len(records))
+	for _, record := range records {
+		memo, err := ParseMemo(record.Memo)
+		if err != nil {
+			continue
+		}
+		result = append(result, MemoRecordInfo{
+			TxID:   ToReversedString(record.TxID),
+			Height: record.Height,
+			MemoInfo: MemoInfo{
+				Type:    string(memo.Type),
+				Message: memo.Message,
+			},
+		})
+	}
+	return ResponsePack(Success, result)
+}
+
 func CreateRawTransaction(param Params) map[string]interface{} {
 	inputsParam, ok := param.String("inputs")
 	if !ok {
@@ -1437,6 +2738,52 @@ func GetExistWithdrawTransactions(param Params) map[string]interface{} {
 	return ResponsePack(Success, resultTxHashes)
 }
 
+func GetSidechainTxStatus(param Params) map[string]interface{} {
+	txHash, ok := param.String("txid")
+	if !ok {
+		return ResponsePack(InvalidParams, "txid not found")
+	}
+	txHashBytes, err := common.HexStringToBytes(txHash)
+	if err != nil {
+		return ResponsePack(InvalidParams, "")
+	}
+	hash, err := common.Uint256FromBytes(txHashBytes)
+	if err != nil {
+		return ResponsePack(InvalidParams, "")
+	}
+
+	// Withdraw transaction has been included on main chain.
+	if Store.IsSidechainTxHashDuplicate(*hash) {
+		status := &SidechainTxStatusInfo{Status: "confirmed"}
+		withdrawHash, err := Store.GetSidechainTxWithdraw(*hash)
+		if err != nil {
+			return ResponsePack(InternalError, err.Error())
+		}
+		// The withdraw transaction hash is not recorded by earlier versions.
+		if withdrawHash == nil {
+			return ResponsePack(Success, status)
+		}
+		_, height, err := Store.GetTransaction(*withdrawHash)
+		if err != nil {
+			return ResponsePack(UnknownTransaction, "")
+		}
+		status.WithdrawTxID = ToReversedString(*withdrawHash)
+		status.Height = height
+		status.Confirmations = Store.GetHeight() - height + 1
+		return ResponsePack(Success, status)
+	}
+
+	// Withdraw transaction is waiting in transaction pool.
+	if txn := TxMemPool.GetSidechainTxWithdraw(*hash); txn != nil {
+		return ResponsePack(Success, &SidechainTxStatusInfo{
+			Status:       "pending",
+			WithdrawTxID: ToReversedString(txn.Hash()),
+		})
+	}
+
+	return ResponsePack(Success, &SidechainTxStatusInfo{Status: "unknown"})
+}
+
 //single producer info
 type producerInfo struct {
 	OwnerPublicKey string `json:"ownerpublickey"`
@@ -1484,6 +2831,18 @@ type crCandidatesInfo struct {
 	TotalCounts          uint64            `json:"totalcounts"`
 }
 
+//single cr candidate info change
+type crCandidateInfoChange struct {
+	OldNickName string `json:"oldnickname"`
+	NewNickName string `json:"newnickname"`
+	OldUrl      string `json:"oldurl"`
+	NewUrl      string `json:"newurl"`
+	OldLocation uint64 `json:"oldlocation"`
+	NewLocation uint64 `json:"newlocation"`
+	Height      uint32 `json:"height"`
+	TxID        string `json:"txid"`
+}
+
 //single cr member info
 type crMemberInfo struct {
 	Code             string         `json:"code"`
@@ -1675,6 +3034,43 @@ func ListCRCandidates(param Params) map[string]interface{} {
 	return ResponsePack(Success, result)
 }
 
+//list the info changes of cr candidate according to (cid)
+func GetCRCandidateHistory(param Params) map[string]interface{} {
+	cid, ok := param.String("cid")
+	if !ok {
+		return ResponsePack(InvalidParams, "need a param called cid")
+	}
+	programHash, err := common.Uint168FromAddress(cid)
+	if err != nil {
+		return ResponsePack(InvalidParams, "invalid cid to programHash")
+	}
+
+	crState := Chain.GetCRCommittee().GetState()
+	if crState.GetCandidateByCID(*programHash) == nil {
+		return ResponsePack(InvalidParams, "can not find CR candidate")
+	}
+
+	history, err := crState.GetCandidateHistory(*programHash)
+	if err != nil {
+		return ResponsePack(InternalError, err.Error())
+	}
+	changes := make([]crCandidateInfoChange, 0)
+	for _, c := range history {
+		changes = append(changes, crCandidateInfoChange{
+			OldNickName: c.OldNickName,
+			NewNickName: c.NewNickName,
+			OldUrl:      c.OldUrl,
+			NewUrl:      c.NewUrl,
+			OldLocation: c.OldLocation,
+			NewLocation: c.NewLocation,
+			Height:      c.Height,
+			TxID:        ToReversedString(c.TxHash),
+		})
+	}
+
+	return ResponsePack(Success, changes)
+}
+
 //list current crs according to (state)
 func ListCurrentCRs(param Params) map[string]interface{} {
 
@@ -1724,6 +3120,33 @@ func ListCurrentCRs(param Params) map[string]interface{} {
 	return ResponsePack(Success, result)
 }
 
+// GetCRRegistrationWindow returns if RegisterCR transactions are accepted in
+// the next block, and the registration windows of current and next election.
+func GetCRRegistrationWindow(param Params) map[string]interface{} {
+	type registrationWindow struct {
+		Height          uint32 `json:"height"`
+		Open            bool   `json:"open"`
+		StartHeight     uint32 `json:"startheight"`
+		EndHeight       uint32 `json:"endheight"`
+		NextStartHeight uint32 `json:"nextstartheight"`
+		NextEndHeight   uint32 `json:"nextendheight"`
+	}
+
+	committee := Chain.GetCRCommittee()
+	height := Chain.GetHeight() + 1
+	w := committee.GetRegistrationWindow()
+	open := height < ChainParams.CRRegistrationWindowHeight ||
+		committee.IsInRegistrationPeriod(height)
+	return ResponsePack(Success, registrationWindow{
+		Height:          height,
+		Open:            open,
+		StartHeight:     w.StartHeight,
+		EndHeight:       w.EndHeight,
+		NextStartHeight: w.NextStartHeight,
+		NextEndHeight:   w.NextEndHeight,
+	})
+}
+
 func ProducerStatus(param Params) map[string]interface{} {
 	publicKey, ok := param.String("publickey")
 	if !ok {
@@ -1743,6 +3166,288 @@ func ProducerStatus(param Params) map[string]interface{} {
 	return ResponsePack(Success, producer.State().String())
 }
 
+// maxRegistrationStatusCount indicates the max count of ids can be queried by
+// getregistrationstatus at once.
+const maxRegistrationStatusCount = 100
+
+// GetRegistrationStatus returns the registration state, votes, rank, deposit
+// and penalty of each of the given producer owner public keys or CR candidate
+// CIDs. Rank starts from 1 and is 0 if the producer or candidate is neither
+// pending nor active.
+func GetRegistrationStatus(param Params) map[string]interface{} {
+	ids, ok := param.ArrayString("ids")
+	if !ok || len(ids) == 0 || len(ids) > maxRegistrationStatusCount {
+		return ResponsePack(InvalidParams, fmt.Sprintf("ids should be "+
+			"an array of 1 to %d owner public keys or CIDs",
+			maxRegistrationStatusCount))
+	}
+
+	producers := Chain.GetState().GetProducers()
+	sort.Slice(producers, func(i, j int) bool {
+		if producers[i].Votes() == producers[j].Votes() {
+			return bytes.Compare(producers[i].NodePublicKey(),
+				producers[j].NodePublicKey()) < 0
+		}
+		return producers[i].Votes() > producers[j].Votes()
+	})
+	producerRanks := make(map[*state.Producer]uint32, len(producers))
+	for i, p := range producers {
+		producerRanks[p] = uint32(i + 1)
+	}
+
+	crState := Chain.GetCRCommittee().GetState()
+	candidates := crState.GetCandidates(crstate.Pending)
+	candidates = append(candidates, crState.GetCandidates(crstate.Active)...)
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Votes() == candidates[j].Votes() {
+			iCRInfo := candidates[i].Info()
+			jCRInfo := candidates[j].Info()
+			return iCRInfo.GetCodeHash().Compare(jCRInfo.GetCodeHash()) < 0
+		}
+		return candidates[i].Votes() > candidates[j].Votes()
+	})
+	candidateRanks := make(map[*crstate.Candidate]uint32, len(candidates))
+	for i, c := range candidates {
+		candidateRanks[c] = uint32(i + 1)
+	}
+
+	result := make([]RegistrationStatusInfo, 0, len(ids))
+	for _, id := range ids {
+		info := RegistrationStatusInfo{
+			ID:    id,
+			Type:  "unknown",
+			State: "Unregistered",
+		}
+		if pk, err := common.HexStringToBytes(id); err == nil {
+			if p := Chain.GetState().GetProducer(pk); p != nil &&
+				bytes.Equal(p.OwnerPublicKey(), pk) {
+				info.Type = "producer"
+				info.State = p.State().String()
+				info.Votes = p.Votes().String()
+				info.Rank = producerRanks[p]
+				info.DepositAmount = p.DepositAmount().String()
+				info.Penalty = p.Penalty().String()
+			}
+		} else if cid, err := common.Uint168FromAddress(id); err == nil {
+			if c := crState.GetCandidateByCID(*cid); c != nil {
+				info.Type = "crcandidate"
+				info.State = c.State().String()
+				info.Votes = c.Votes().String()
+				info.Rank = candidateRanks[c]
+				info.DepositAmount = c.DepositAmount().String()
+				info.Penalty = c.Penalty().String()
+			}
+		}
+		result = append(result, info)
+	}
+
+	return ResponsePack(Success, result)
+}
+
+type producerChangeInfo struct {
+	Cause            string `json:"cause"`
+	OldState         string `json:"oldstate"`
+	NewState         string `json:"newstate"`
+	OldNodePublicKey string `json:"oldnodepublickey"`
+	NewNodePublicKey string `json:"newnodepublickey"`
+	OldNickName      string `json:"oldnickname"`
+	NewNickName      string `json:"newnickname"`
+	OldUrl           string `json:"oldurl"`
+	NewUrl           string `json:"newurl"`
+	OldLocation      uint64 `json:"oldlocation"`
+	NewLocation      uint64 `json:"newlocation"`
+	OldNetAddress    string `json:"oldnetaddress"`
+	NewNetAddress    string `json:"newnetaddress"`
+	Height           uint32 `json:"height"`
+	TxID             string `json:"txid"`
+}
+
+func GetProducerHistory(param Params) map[string]interface{} {
+	publicKey, ok := param.String("publickey")
+	if !ok {
+		return ResponsePack(InvalidParams, "public key not found")
+	}
+	publicKeyBytes, err := common.HexStringToBytes(publicKey)
+	if err != nil {
+		return ResponsePack(InvalidParams, "invalid public key")
+	}
+	if _, err = contract.PublicKeyToStandardProgramHash(publicKeyBytes); err != nil {
+		return ResponsePack(InvalidParams, "invalid public key bytes")
+	}
+	if Chain.GetState().GetProducer(publicKeyBytes) == nil {
+		return ResponsePack(InvalidParams, "unknown producer public key")
+	}
+
+	history, err := Chain.GetState().GetProducerHistory(publicKeyBytes)
+	if err != nil {
+		return ResponsePack(InternalError, err.Error())
+	}
+	changes := make([]producerChangeInfo, 0)
+	for _, c := range history {
+		var txID string
+		if !c.TxHash.IsEqual(common.EmptyHash) {
+			txID = ToReversedString(c.TxHash)
+		}
+		changes = append(changes, producerChangeInfo{
+			Cause:            c.Cause.String(),
+			OldState:         c.OldState.String(),
+			NewState:         c.NewState.String(),
+			OldNodePublicKey: common.BytesToHexString(c.OldNodePublicKey),
+			NewNodePublicKey: common.BytesToHexString(c.NewNodePublicKey),
+			OldNickName:      c.OldNickName,
+			NewNickName:      c.NewNickName,
+			OldUrl:           c.OldUrl,
+			NewUrl:           c.NewUrl,
+			OldLocation:      c.OldLocation,
+			NewLocation:      c.NewLocation,
+			OldNetAddress:    c.OldNetAddress,
+			NewNetAddress:    c.NewNetAddress,
+			Height:           c.Height,
+			TxID:             txID,
+		})
+	}
+
+	return ResponsePack(Success, changes)
+}
+
+//single vote output of a voter
+type voteOutPointInfo struct {
+	TxID  string `json:"txid"`
+	Index uint16 `json:"index"`
+	Votes string `json:"votes"`
+}
+
+//single voter info include the votes of each vote output
+type voterInfo struct {
+	Address   string             `json:"address"`
+	Votes     string             `json:"votes"`
+	OutPoints []voteOutPointInfo `json:"outpoints"`
+	Index     uint64             `json:"index"`
+}
+
+//a group voter info include TotalVotes and voter count
+type votersInfo struct {
+	VoterInfoSlice []voterInfo `json:"votersinfo"`
+	TotalVotes     string      `json:"totalvotes"`
+	TotalCounts    uint64      `json:"totalcounts"`
+}
+
+//list voters of a producer or CR candidate according to (start and limit)
+func ListVotes(param Params) map[string]interface{} {
+	start, _ := param.Int("start")
+	limit, ok := param.Int("limit")
+	if !ok {
+		limit = -1
+	}
+
+	type voteDetail struct {
+		outPoint    OutPoint
+		programHash common.Uint168
+		votes       common.Fixed64
+	}
+	var details []voteDetail
+	if publicKey, ok := param.String("publickey"); ok {
+		publicKeyBytes, err := common.HexStringToBytes(publicKey)
+		if err != nil {
+			return ResponsePack(InvalidParams, "invalid public key")
+		}
+		if Chain.GetState().GetProducer(publicKeyBytes) == nil {
+			return ResponsePack(InvalidParams, "unknown producer public key")
+		}
+		for _, d := range Chain.GetState().GetVoteDetails(publicKeyBytes) {
+			details = append(details,
+				voteDetail{d.OutPoint, d.ProgramHash, d.Votes})
+		}
+	} else if cid, ok := param.String("cid"); ok {
+		programHash, err := common.Uint168FromAddress(cid)
+		if err != nil {
+			return ResponsePack(InvalidParams, "invalid cid to programHash")
+		}
+		crState := Chain.GetCRCommittee().GetState()
+		if crState.GetCandidateByCID(*programHash) == nil {
+			return ResponsePack(InvalidParams, "can not find CR candidate")
+		}
+		for _, d := range crState.GetVoteDetails(*programHash) {
+			details = append(details,
+				voteDetail{d.OutPoint, d.ProgramHash, d.Votes})
+		}
+	} else {
+		return ResponsePack(InvalidParams, "need a param called publickey or cid")
+	}
+
+	// aggregate votes by voter
+	voters := make(map[common.Uint168]*voterInfo)
+	voterVotes := make(map[common.Uint168]common.Fixed64)
+	var totalVotes common.Fixed64
+	for _, d := range details {
+		voter, ok := voters[d.programHash]
+		if !ok {
+			address, err := d.programHash.ToAddress()
+			if err != nil {
+				continue
+			}
+			voter = &voterInfo{Address: address}
+			voters[d.programHash] = voter
+		}
+		voter.OutPoints = append(voter.OutPoints, voteOutPointInfo{
+			TxID:  ToReversedString(d.outPoint.TxID),
+			Index: d.outPoint.Index,
+			Votes: d.votes.String(),
+		})
+		voterVotes[d.programHash] += d.votes
+		totalVotes += d.votes
+	}
+
+	programHashes := make([]common.Uint168, 0, len(voters))
+	for k := range voters {
+		programHashes = append(programHashes, k)
+	}
+	sort.Slice(programHashes, func(i, j int) bool {
+		vi, vj := voterVotes[programHashes[i]], voterVotes[programHashes[j]]
+		if vi == vj {
+			return programHashes[i].Compare(programHashes[j]) < 0
+		}
+		return vi > vj
+	})
+
+	var voterInfoSlice []voterInfo
+	for i, programHash := range programHashes {
+		voter := voters[programHash]
+		sort.Slice(voter.OutPoints, func(i, j int) bool {
+			if voter.OutPoints[i].TxID == voter.OutPoints[j].TxID {
+				return voter.OutPoints[i].Index < voter.OutPoints[j].Index
+			}
+			return voter.OutPoints[i].TxID < voter.OutPoints[j].TxID
+		})
+		voter.Votes = voterVotes[programHash].String()
+		voter.Index = uint64(i)
+		voterInfoSlice = append(voterInfoSlice, *voter)
+	}
+
+	count := int64(len(voterInfoSlice))
+	if limit < 0 {
+		limit = count
+	}
+	var rsVoterInfoSlice []voterInfo
+	if start < count {
+		end := start
+		if start+limit <= count {
+			end = start + limit
+		} else {
+			end = count
+		}
+		rsVoterInfoSlice = append(rsVoterInfoSlice, voterInfoSlice[start:end]...)
+	}
+
+	result := &votersInfo{
+		VoterInfoSlice: rsVoterInfoSlice,
+		TotalVotes:     totalVotes.String(),
+		TotalCounts:    uint64(count),
+	}
+
+	return ResponsePack(Success, result)
+}
+
 func VoteStatus(param Params) map[string]interface{} {
 	address, ok := param.String("address")
 	if !ok {
@@ -1804,6 +3509,123 @@ func VoteStatus(param Params) map[string]interface{} {
 	})
 }
 
+func GetDerivedAddresses(param Params) map[string]interface{} {
+	var code []byte
+	if pk, ok := param.String("publickey"); ok {
+		pkBytes, err := common.HexStringToBytes(pk)
+		if err != nil {
+			return ResponsePack(InvalidParams, "invalid publickey")
+		}
+		pubKey, err := crypto.DecodePoint(pkBytes)
+		if err != nil {
+			return ResponsePack(InvalidParams, "invalid publickey")
+		}
+		code, err = contract.CreateStandardRedeemScript(pubKey)
+		if err != nil {
+			return ResponsePack(InvalidParams, "invalid publickey to code")
+		}
+	} else if c, ok := param.String("code"); ok {
+		var err error
+		code, err = common.HexStringToBytes(c)
+		if err != nil {
+			return ResponsePack(InvalidParams, "invalid code")
+		}
+	} else {
+		return ResponsePack(InvalidParams, "need a param called publickey or code")
+	}
+
+	hashes, err := contract.DeriveProgramHashes(code)
+	if err != nil {
+		return ResponsePack(InvalidParams, err.Error())
+	}
+
+	type derivedAddresses struct {
+		DPoSDepositAddress string `json:"dposdepositaddress"`
+		CRDepositAddress   string `json:"crdepositaddress"`
+		CID                string `json:"cid"`
+		DID                string `json:"did"`
+	}
+	var result derivedAddresses
+	if hashes.DPoSDeposit != nil {
+		result.DPoSDepositAddress, _ = hashes.DPoSDeposit.ToAddress()
+	}
+	result.CRDepositAddress, _ = hashes.CRDeposit.ToAddress()
+	result.CID, _ = hashes.CID.ToAddress()
+	result.DID, _ = hashes.DID.ToAddress()
+
+	return ResponsePack(Success, result)
+}
+
+// AddressInfo is the result of validateaddress and convertaddress.
+type AddressInfo struct {
+	IsValid     bool   `json:"isvalid"`
+	Address     string `json:"address"`
+	Type        string `json:"type,omitempty"`
+	ProgramHash string `json:"programhash,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// addressInfo returns the address info of the program hash, the address is
+// invalid if the prefix of the program hash is unknown.
+func addressInfo(programHash common.Uint168) AddressInfo {
+	address, _ := programHash.ToAddress()
+	info := AddressInfo{
+		IsValid:     true,
+		Address:     address,
+		Type:        contract.GetPrefixType(programHash).Name(),
+		ProgramHash: programHash.String(),
+	}
+	if info.Type == "unknown" {
+		info.IsValid = false
+		info.Error = "unknown address prefix"
+	}
+	return info
+}
+
+func ValidateAddress(param Params) map[string]interface{} {
+	address, ok := param.String("address")
+	if !ok {
+		return ResponsePack(InvalidParams, "need a param called address")
+	}
+
+	programHash, err := common.Uint168FromAddress(address)
+	if err != nil {
+		return ResponsePack(Success, AddressInfo{
+			Address: address,
+			Error:   err.Error(),
+		})
+	}
+	return ResponsePack(Success, addressInfo(*programHash))
+}
+
+func ConvertAddress(param Params) map[string]interface{} {
+	var programHash *common.Uint168
+	if address, ok := param.String("address"); ok {
+		var err error
+		programHash, err = common.Uint168FromAddress(address)
+		if err != nil {
+			return ResponsePack(InvalidParams, "invalid address, "+err.Error())
+		}
+	} else if hash, ok := param.String("programhash"); ok {
+		hashBytes, err := common.HexStringToBytes(hash)
+		if err != nil {
+			return ResponsePack(InvalidParams, "invalid programhash")
+		}
+		programHash, err = common.Uint168FromBytes(hashBytes)
+		if err != nil {
+			return ResponsePack(InvalidParams, "invalid programhash")
+		}
+	} else {
+		return ResponsePack(InvalidParams, "need a param called address or programhash")
+	}
+
+	info := addressInfo(*programHash)
+	if !info.IsValid {
+		return ResponsePack(InvalidParams, info.Error)
+	}
+	return ResponsePack(Success, info)
+}
+
 func GetDepositCoin(param Params) map[string]interface{} {
 	pk, ok := param.String("ownerpublickey")
 	if !ok {
@@ -1883,6 +3705,89 @@ func GetCRDepositCoin(param Params) map[string]interface{} {
 	})
 }
 
+type CRDepositInfo struct {
+	DepositAddress string `json:"depositaddress"`
+	CID            string `json:"cid"`
+	State          string `json:"state"`
+	Amount         string `json:"amount"`
+	Penalty        string `json:"penalty"`
+}
+
+type CRDepositsInfo struct {
+	Total    string          `json:"total"`
+	Deposits []CRDepositInfo `json:"deposits"`
+}
+
+// GetCRDeposits returns the deposit amount of CR candidates by state or by
+// deposit address, so that the aggregate deposit pool can be reconciled
+// against the balances of the deposit addresses.
+func GetCRDeposits(param Params) map[string]interface{} {
+	crState := Chain.GetCRCommittee().GetState()
+
+	var candidates []*crstate.Candidate
+	var total common.Fixed64
+	if address, ok := param.String("address"); ok {
+		programHash, err := common.Uint168FromAddress(address)
+		if err != nil {
+			return ResponsePack(InvalidParams, "invalid deposit address")
+		}
+		candidate := crState.GetCandidateByDepositHash(*programHash)
+		if candidate == nil {
+			return ResponsePack(InvalidParams, "can not find CR candidate")
+		}
+		candidates = append(candidates, candidate)
+		total = candidate.DepositAmount()
+	} else {
+		s, _ := param.String("state")
+		var states []crstate.CandidateState
+		switch strings.ToLower(s) {
+		case "", "all":
+			states = []crstate.CandidateState{crstate.Pending,
+				crstate.Active, crstate.Canceled, crstate.Returned}
+		case "pending":
+			states = []crstate.CandidateState{crstate.Pending}
+		case "active":
+			states = []crstate.CandidateState{crstate.Active}
+		case "canceled":
+			states = []crstate.CandidateState{crstate.Canceled}
+		case "returned":
+			states = []crstate.CandidateState{crstate.Returned}
+		default:
+			return ResponsePack(InvalidParams, "invalid state")
+		}
+		for _, state := range states {
+			candidates = append(candidates, crState.GetCandidates(state)...)
+			total += crState.GetTotalDeposits(state)
+		}
+	}
+
+	result := CRDepositsInfo{
+		Total:    total.String(),
+		Deposits: make([]CRDepositInfo, 0, len(candidates)),
+	}
+	for _, c := range candidates {
+		depositHash := c.DepositHash()
+		depositAddress, err := depositHash.ToAddress()
+		if err != nil {
+			return ResponsePack(InternalError, err.Error())
+		}
+		cid := c.Info().CID
+		cidAddress, _ := cid.ToAddress()
+		result.Deposits = append(result.Deposits, CRDepositInfo{
+			DepositAddress: depositAddress,
+			CID:            cidAddress,
+			State:          c.State().String(),
+			Amount:         c.DepositAmount().String(),
+			Penalty:        c.Penalty().String(),
+		})
+	}
+	sort.Slice(result.Deposits, func(i, j int) bool {
+		return result.Deposits[i].DepositAddress <
+			result.Deposits[j].DepositAddress
+	})
+	return ResponsePack(Success, result)
+}
+
 func EstimateSmartFee(param Params) map[string]interface{} {
 	confirm, ok := param.Int("confirmations")
 	if !ok {
@@ -1925,104 +3830,6 @@ func DecodeRawTransaction(param Params) map[string]interface{} {
 	return ResponsePack(Success, GetTransactionInfo(&txn))
 }
 
-func getPayloadInfo(p Payload) PayloadInfo {
-	switch object := p.(type) {
-	case *payload.CoinBase:
-		obj := new(CoinbaseInfo)
-		obj.CoinbaseData = string(object.Content)
-		return obj
-	case *payload.RegisterAsset:
-		obj := new(RegisterAssetInfo)
-		obj.Asset = object.Asset
-		obj.Amount = object.Amount.String()
-		obj.Controller = common.BytesToHexString(common.BytesReverse(object.Controller.Bytes()))
-		return obj
-	case *payload.SideChainPow:
-		obj := new(SideChainPowInfo)
-		obj.BlockHeight = object.BlockHeight
-		obj.SideBlockHash = object.SideBlockHash.String()
-		obj.SideGenesisHash = object.SideGenesisHash.String()
-		obj.Signature = common.BytesToHexString(object.Signature)
-		return obj
-	case *payload.WithdrawFromSideChain:
-		obj := new(WithdrawFromSideChainInfo)
-		obj.BlockHeight = object.BlockHeight
-		obj.GenesisBlockAddress = object.GenesisBlockAddress
-		for _, hash := range object.SideChainTransactionHashes {
-			obj.SideChainTransactionHashes = append(obj.SideChainTransactionHashes, hash.String())
-		}
-		return obj
-	case *payload.TransferCrossChainAsset:
-		obj := new(TransferCrossChainAssetInfo)
-		obj.CrossChainAddresses = object.CrossChainAddresses
-		obj.OutputIndexes = object.OutputIndexes
-		obj.CrossChainAmounts = object.CrossChainAmounts
-		return obj
-	case *payload.TransferAsset:
-	case *payload.Record:
-	case *payload.ProducerInfo:
-		obj := new(ProducerInfo)
-		obj.OwnerPublicKey = common.BytesToHexString(object.OwnerPublicKey)
-		obj.NodePublicKey = common.BytesToHexString(object.NodePublicKey)
-		obj.NickName = object.NickName
-		obj.Url = object.Url
-		obj.Location = object.Location
-		obj.NetAddress = object.NetAddress
-		obj.Signature = common.BytesToHexString(object.Signature)
-		return obj
-	case *payload.ProcessProducer:
-		obj := new(CancelProducerInfo)
-		obj.OwnerPublicKey = common.BytesToHexString(object.OwnerPublicKey)
-		obj.Signature = common.BytesToHexString(object.Signature)
-		return obj
-	case *payload.ActivateProducer:
-		obj := new(ActivateProducerInfo)
-		obj.NodePublicKey = common.BytesToHexString(object.NodePublicKey)
-		obj.Signature = common.BytesToHexString(object.Signature)
-		return obj
-	}
-	return nil
-}
-
-func getOutputPayloadInfo(op OutputPayload) OutputPayloadInfo {
-	switch object := op.(type) {
-	case *outputpayload.DefaultOutput:
-		obj := new(DefaultOutputInfo)
-		return obj
-	case *outputpayload.VoteOutput:
-		obj := new(VoteOutputInfo)
-		obj.Version = object.Version
-		for _, content := range object.Contents {
-			var contentInfo VoteContentInfo
-			contentInfo.VoteType = content.VoteType
-			switch contentInfo.VoteType {
-			case outputpayload.Delegate:
-				for _, cv := range content.CandidateVotes {
-					contentInfo.CandidatesInfo = append(contentInfo.CandidatesInfo,
-						CandidateVotes{
-							Candidate: common.BytesToHexString(cv.Candidate),
-							Votes:     cv.Votes.String(),
-						})
-				}
-			case outputpayload.CRC:
-				for _, cv := range content.CandidateVotes {
-					c, _ := common.Uint168FromBytes(cv.Candidate)
-					addr, _ := c.ToAddress()
-					contentInfo.CandidatesInfo = append(contentInfo.CandidatesInfo,
-						CandidateVotes{
-							Candidate: addr,
-							Votes:     cv.Votes.String(),
-						})
-				}
-			}
-			obj.Contents = append(obj.Contents, contentInfo)
-		}
-		return obj
-	}
-
-	return nil
-}
-
 func VerifyAndSendTx(tx *Transaction) error {
 	// if transaction is verified unsuccessfully then will not put it into transaction pool
 	if err := TxMemPool.AppendToTxPool(tx); err != nil {
@@ -0,0 +1,209 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package servers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA/common"
+	crstate "github.com/elastos/Elastos.ELA/cr/state"
+	. "github.com/elastos/Elastos.ELA/errors"
+)
+
+// rankItem is a producer or CR candidate ranked by votes, keys are the
+// lookup keys of the item.
+type rankItem struct {
+	keys  []string
+	votes common.Fixed64
+}
+
+// rankIndex keeps producers or CR candidates sorted in the election order,
+// the index is rebuilt only when the best block changed so that dashboards
+// polling per block do not sort the items on each request.
+type rankIndex struct {
+	// load returns the items sorted in the election order.
+	load func() []rankItem
+
+	mtx       sync.Mutex
+	best      common.Uint256
+	items     []rankItem
+	positions map[string]int
+	total     common.Fixed64
+}
+
+// refresh rebuilds the index if the best block changed, the caller must hold
+// the mutex.
+func (r *rankIndex) refresh(best common.Uint256) {
+	if r.positions != nil && r.best.IsEqual(best) {
+		return
+	}
+	r.best = best
+	r.items = r.load()
+	r.positions = make(map[string]int, len(r.items))
+	r.total = 0
+	for i, item := range r.items {
+		for _, key := range item.keys {
+			r.positions[key] = i
+		}
+		r.total += item.votes
+	}
+}
+
+// rank returns the rank of the item with the key in the index built at the
+// best block, threshold is the count of items to be elected.
+func (r *rankIndex) rank(best common.Uint256, key string,
+	threshold int) (*RankInfo, bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.refresh(best)
+	index, ok := r.positions[key]
+	if !ok {
+		return nil, false
+	}
+
+	item := r.items[index]
+	info := &RankInfo{
+		Rank:      uint32(index + 1),
+		Total:     uint32(len(r.items)),
+		Votes:     item.votes.String(),
+		VoteShare: "0",
+		Threshold: uint32(threshold),
+		Elected:   index < threshold,
+	}
+	if r.total > 0 {
+		info.VoteShare = fmt.Sprintf("%.4f",
+			float64(item.votes)*100/float64(r.total))
+	}
+
+	// the boundary is the first one out of the threshold for elected items,
+	// and the last one within the threshold for the others.
+	boundary := threshold
+	if !info.Elected {
+		boundary = threshold - 1
+	}
+	var boundaryVotes common.Fixed64
+	if boundary >= 0 && boundary < len(r.items) {
+		boundaryVotes = r.items[boundary].votes
+	}
+	info.ThresholdVotes = boundaryVotes.String()
+	info.Distance = (item.votes - boundaryVotes).String()
+	return info, true
+}
+
+var (
+	producerRanks  = &rankIndex{load: loadProducerRanks}
+	candidateRanks = &rankIndex{load: loadCandidateRanks}
+)
+
+// loadProducerRanks returns the active producers sorted in the same order as
+// the arbiters election.
+func loadProducerRanks() []rankItem {
+	producers := Chain.GetState().GetActiveProducers()
+	sort.Slice(producers, func(i, j int) bool {
+		if producers[i].Votes() == producers[j].Votes() {
+			return bytes.Compare(producers[i].NodePublicKey(),
+				producers[j].NodePublicKey()) < 0
+		}
+		return producers[i].Votes() > producers[j].Votes()
+	})
+
+	items := make([]rankItem, 0, len(producers))
+	for _, p := range producers {
+		items = append(items, rankItem{
+			keys: []string{hex.EncodeToString(p.OwnerPublicKey()),
+				hex.EncodeToString(p.NodePublicKey())},
+			votes: p.Votes(),
+		})
+	}
+	return items
+}
+
+// loadCandidateRanks returns the active CR candidates sorted in the same
+// order as the CR committee election.
+func loadCandidateRanks() []rankItem {
+	candidates := Chain.GetCRCommittee().GetState().GetCandidates(
+		crstate.Active)
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Votes() != candidates[j].Votes() {
+			return candidates[i].Votes() > candidates[j].Votes()
+		}
+		if candidates[i].RegisterHeight() != candidates[j].RegisterHeight() {
+			return candidates[i].RegisterHeight() <
+				candidates[j].RegisterHeight()
+		}
+		iCRInfo := candidates[i].Info()
+		jCRInfo := candidates[j].Info()
+		return iCRInfo.GetCodeHash().Compare(jCRInfo.GetCodeHash()) < 0
+	})
+
+	items := make([]rankItem, 0, len(candidates))
+	for _, c := range candidates {
+		cid := c.Info().CID
+		items = append(items, rankItem{
+			keys:  []string{cid.String()},
+			votes: c.Votes(),
+		})
+	}
+	return items
+}
+
+type RankInfo struct {
+	Rank           uint32 `json:"rank"`
+	Total          uint32 `json:"total"`
+	Votes          string `json:"votes"`
+	VoteShare      string `json:"voteshare"`
+	Threshold      uint32 `json:"threshold"`
+	ThresholdVotes string `json:"thresholdvotes"`
+	Distance       string `json:"distance"`
+	Elected        bool   `json:"elected"`
+}
+
+// GetProducerRank returns the current rank of an active producer in the
+// arbiters election, with the votes share and the distance to the election
+// threshold.
+func GetProducerRank(param Params) map[string]interface{} {
+	publicKey, ok := param.String("publickey")
+	if !ok {
+		return ResponsePack(InvalidParams, "need a param called publickey")
+	}
+	pk, err := common.HexStringToBytes(publicKey)
+	if err != nil {
+		return ResponsePack(InvalidParams, "invalid publickey")
+	}
+
+	info, ok := producerRanks.rank(Chain.GetCurrentBlockHash(),
+		hex.EncodeToString(pk), ChainParams.GeneralArbiters)
+	if !ok {
+		return ResponsePack(InvalidParams, "can not find active producer")
+	}
+	return ResponsePack(Success, info)
+}
+
+// GetCRCandidateRank returns the current rank of an active CR candidate in
+// the CR committee election, with the votes share and the distance to the
+// election threshold.
+func GetCRCandidateRank(param Params) map[string]interface{} {
+	str, ok := param.String("cid")
+	if !ok {
+		return ResponsePack(InvalidParams, "need a param called cid")
+	}
+	cid, err := common.Uint168FromAddress(str)
+	if err != nil {
+		return ResponsePack(InvalidParams, "invalid cid")
+	}
+
+	info, ok := candidateRanks.rank(Chain.GetCurrentBlockHash(), cid.String(),
+		int(ChainParams.CRMemberCount))
+	if !ok {
+		return ResponsePack(InvalidParams, "can not find active CR candidate")
+	}
+	return ResponsePack(Success, info)
+}
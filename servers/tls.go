@@ -0,0 +1,106 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package servers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+
+	"github.com/elastos/Elastos.ELA/common/config"
+)
+
+// tlsVersions maps the TLS version names in config to their values.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsCipherSuites maps the cipher suite names in config to their values, only
+// suites with forward secrecy and AEAD are allowed.  Cipher suites are not
+// configurable in TLS 1.3.
+var tlsCipherSuites = map[string]uint16{
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256": tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384": tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305":  tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256":   tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384":   tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305":    tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+}
+
+// NewTLSConfig creates the TLS config of the servers from HttpTLS, the
+// RestCertPath and RestKeyPath are used if the cert paths are not set.
+func NewTLSConfig(cfg *config.Configuration) (*tls.Config, error) {
+	certPath, keyPath := cfg.HttpTLS.CertPath, cfg.HttpTLS.KeyPath
+	if certPath == "" {
+		certPath, keyPath = cfg.RestCertPath, cfg.RestKeyPath
+	}
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("load cert failed, %s", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if v := cfg.HttpTLS.MinVersion; v != "" {
+		version, ok := tlsVersions[v]
+		if !ok {
+			return nil, fmt.Errorf("unknown TLS version %s", v)
+		}
+		tlsConfig.MinVersion = version
+	}
+	for _, name := range cfg.HttpTLS.CipherSuites {
+		suite, ok := tlsCipherSuites[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported cipher suite %s", name)
+		}
+		tlsConfig.CipherSuites = append(tlsConfig.CipherSuites, suite)
+	}
+
+	if cfg.HttpTLS.ClientCAPath != "" {
+		pem, err := ioutil.ReadFile(cfg.HttpTLS.ClientCAPath)
+		if err != nil {
+			return nil, fmt.Errorf("load client CA failed, %s", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificate found in client CA")
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+// Listen announces on the local network address, and wraps the listener with
+// TLS if useTLS is true.
+func Listen(network, address string, useTLS bool) (net.Listener, error) {
+	var tlsConfig *tls.Config
+	if useTLS {
+		var err error
+		tlsConfig, err = NewTLSConfig(config.Parameters)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	return listener, nil
+}
@@ -35,9 +35,30 @@ const (
 	// logPath indicates the path storing the node log.
 	nodeLogPath = "logs/node"
 
+	// rpcAuditLogPath indicates the path storing the RPC audit log.
+	rpcAuditLogPath = "logs/rpcaudit"
+
 	// checkpointPath indicates the path storing the checkpoint data
 	checkpointPath = "checkpoints"
 
+	// draftPath indicates the path storing the proposal draft data.
+	draftPath = "draft"
+
+	// rankHistoryPath indicates the path storing the rank history of
+	// producers and CR candidates.
+	rankHistoryPath = "rankhistory"
+
+	// roundRewardPath indicates the path storing the round rewards of
+	// arbiters.
+	roundRewardPath = "roundreward"
+
+	// electionHistoryPath indicates the path storing the results of the
+	// next arbiters elections.
+	electionHistoryPath = "electionhistory"
+
+	// pluginsPath indicates the path storing the data of plugins.
+	pluginsPath = "plugins"
+
 	// cmdValueSplitter defines the splitter to split raw string into a
 	// string array
 	cmdValueSplitter = ","
@@ -259,6 +280,17 @@ func (s *settings) initNetSetting() (err error) {
 		pact.MaxBlockSize = 2000000
 	}
 
+	chainParamsFile := s.conf.ChainParamsFile
+	if s.context.IsSet(cmdcom.ChainParamsFlag.Name) {
+		chainParamsFile = s.context.String(cmdcom.ChainParamsFlag.Name)
+	}
+	if chainParamsFile != "" {
+		if s.params, err = loadChainParams(chainParamsFile,
+			s.params); err != nil {
+			return
+		}
+	}
+
 	if s.conf.MaxBlockSize > 0 {
 		pact.MaxBlockSize = s.conf.MaxBlockSize
 	}
@@ -307,6 +339,36 @@ func newSettings() *settings {
 		ConfigPath:   "PermanentPeers",
 		ParamName:    "PermanentPeers"})
 
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: 0,
+		ConfigPath:   "MaxPeers",
+		ParamName:    "MaxPeers"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: []string{},
+		ConfigPath:   "PeerAllowList",
+		ParamName:    "PeerAllowList"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: false,
+		ConfigPath:   "NAT.UPnP",
+		ParamName:    "NAT.UPnP"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: false,
+		ConfigPath:   "NAT.NATPMP",
+		ParamName:    "NAT.NATPMP"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: "",
+		ConfigPath:   "NAT.Gateway",
+		ParamName:    "NAT.Gateway"})
+
 	result.Add(&settingItem{
 		Flag:         cmdcom.DnsSeedFlag,
 		DefaultValue: []string{},
@@ -339,6 +401,30 @@ func newSettings() *settings {
 		ConfigPath:   "MinCrossChainTxFee",
 		ParamName:    "MinCrossChainTxFee"})
 
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: uint32(0),
+		ConfigPath:   "CoinbaseMaturity",
+		ParamName:    "CoinbaseMaturity"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: float64(0),
+		ConfigPath:   "FoundationRewardRatio",
+		ParamName:    "FoundationRewardRatio"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: float64(0),
+		ConfigPath:   "DPoSRewardRatio",
+		ParamName:    "DPoSRewardRatio"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: []config.RewardPolicy(nil),
+		ConfigPath:   "RewardPolicies",
+		ParamName:    "RewardPolicies"})
+
 	result.Add(&settingItem{
 		Flag:         nil,
 		DefaultValue: "",
@@ -406,6 +492,138 @@ func newSettings() *settings {
 		ConfigPath:   "CRVotingStartHeight",
 		ParamName:    "CRVotingStartHeight"})
 
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: uint32(0),
+		ConfigPath:   "NamePolicyHeight",
+		ParamName:    "NamePolicyHeight"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: uint32(0),
+		ConfigPath:   "NicknameFoldHeight",
+		ParamName:    "NicknameFoldHeight"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: uint32(0),
+		ConfigPath:   "ProducerInfoStakeHeight",
+		ParamName:    "ProducerInfoStakeHeight"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: uint32(0),
+		ConfigPath:   "RevokeVoteHeight",
+		ParamName:    "RevokeVoteHeight"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: uint32(0),
+		ConfigPath:   "UnderstaffedRecoveryHeight",
+		ParamName:    "UnderstaffedRecoveryHeight"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: uint32(0),
+		ConfigPath:   "SideChainTxProofHeight",
+		ParamName:    "SideChainTxProofHeight"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: uint32(0),
+		ConfigPath:   "VotePolicyHeight",
+		ParamName:    "VotePolicyHeight"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: uint32(0),
+		ConfigPath:   "VoteDecayHeight",
+		ParamName:    "VoteDecayHeight"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: uint32(0),
+		ConfigPath:   "NodeKeyRotationHeight",
+		ParamName:    "NodeKeyRotationHeight"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: uint32(0),
+		ConfigPath:   "CRCandidateTieBreakHeight",
+		ParamName:    "CRCandidateTieBreakHeight"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: uint32(0),
+		ConfigPath:   "CRRegistrationWindowHeight",
+		ParamName:    "CRRegistrationWindowHeight"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: 0,
+		ConfigPath:   "NamePolicy.MaxNicknameLength",
+		ParamName:    "NamePolicy.MaxNicknameLength"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: 0,
+		ConfigPath:   "NamePolicy.MaxURLLength",
+		ParamName:    "NamePolicy.MaxURLLength"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: []string{},
+		ConfigPath:   "NamePolicy.URLSchemes",
+		ParamName:    "NamePolicy.URLSchemes"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: common.Fixed64(0),
+		ConfigPath:   "VotePolicy.MinVoteAmount",
+		ParamName:    "VotePolicy.MinVoteAmount"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: 0,
+		ConfigPath:   "VotePolicy.MaxCandidatesPerContent",
+		ParamName:    "VotePolicy.MaxCandidatesPerContent"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: 0,
+		ConfigPath:   "TxPolicy.MaxTxSize",
+		ParamName:    "TxPolicy.MaxTxSize"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: 0,
+		ConfigPath:   "TxPolicy.MaxInputs",
+		ParamName:    "TxPolicy.MaxInputs"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: 0,
+		ConfigPath:   "TxPolicy.MaxOutputs",
+		ParamName:    "TxPolicy.MaxOutputs"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: 0,
+		ConfigPath:   "TxPolicy.MaxProgramCodeSize",
+		ParamName:    "TxPolicy.MaxProgramCodeSize"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: 0,
+		ConfigPath:   "TxPolicy.MaxProgramParameterSize",
+		ParamName:    "TxPolicy.MaxProgramParameterSize"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: 0,
+		ConfigPath:   "TxPolicy.MaxMemoSize",
+		ParamName:    "TxPolicy.MaxMemoSize"})
+
 	result.Add(&settingItem{
 		Flag:         cmdcom.CheckRewardHeightFlag,
 		DefaultValue: uint32(0),
@@ -452,6 +670,69 @@ func newSettings() *settings {
 		ConfigPath:   "EnableUtxoDB",
 		ParamName:    "EnableUtxoDB"})
 
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: "leveldb",
+		ConfigPath:   "DBEngine",
+		ParamName:    "DBEngine"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: false,
+		ConfigPath:   "HeadersFirst",
+		ParamName:    "HeadersFirst"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: []config.CheckpointConfig{},
+		ConfigSetter: func(path string, params *config.Params,
+			conf *config.Configuration) error {
+			checkpoints := make([]config.Checkpoint, 0, len(conf.Checkpoints))
+			for _, c := range conf.Checkpoints {
+				hashBytes, err := common.HexStringToBytes(c.Hash)
+				if err != nil || len(hashBytes) != common.UINT256SIZE {
+					return fmt.Errorf("invalid checkpoint hash %s", c.Hash)
+				}
+				hash, err := common.Uint256FromBytes(
+					common.BytesReverse(hashBytes))
+				if err != nil {
+					return err
+				}
+				if len(checkpoints) > 0 &&
+					checkpoints[len(checkpoints)-1].Height >= c.Height {
+					return errors.New("checkpoints must be ordered by height")
+				}
+				checkpoints = append(checkpoints, config.Checkpoint{
+					Height: c.Height,
+					Hash:   *hash,
+				})
+			}
+			params.Checkpoints = checkpoints
+			return nil
+		},
+		ConfigPath: "Checkpoints",
+		ParamName:  "Checkpoints"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: []string{},
+		ConfigSetter: func(path string, params *config.Params,
+			conf *config.Configuration) error {
+			whitelist := make([]common.Uint168, 0, len(conf.RewardWhitelist))
+			for _, addr := range conf.RewardWhitelist {
+				programHash, err := common.Uint168FromAddress(addr)
+				if err != nil {
+					return fmt.Errorf("invalid reward whitelist address %s",
+						addr)
+				}
+				whitelist = append(whitelist, *programHash)
+			}
+			params.RewardWhitelist = whitelist
+			return nil
+		},
+		ConfigPath: "RewardWhitelist",
+		ParamName:  "RewardWhitelist"})
+
 	result.Add(&settingItem{
 		Flag:         cmdcom.AutoMiningFlag,
 		DefaultValue: false,
@@ -609,6 +890,18 @@ func newSettings() *settings {
 		ConfigPath:   "DPoSConfiguration.PreConnectOffset",
 		ParamName:    "PreConnectOffset"})
 
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: uint32(0),
+		ConfigPath:   "DPoSConfiguration.CheckPointRetainCount",
+		ParamName:    "CheckPointRetainCount"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: uint32(0),
+		ConfigPath:   "DPoSConfiguration.CheckPointArchiveSpan",
+		ParamName:    "CheckPointArchiveSpan"})
+
 	result.Add(&settingItem{
 		Flag:         cmdcom.CandidatesCountFlag,
 		DefaultValue: 0,
@@ -633,6 +926,24 @@ func newSettings() *settings {
 		ConfigPath:   "DPoSConfiguration.MaxInactiveRounds",
 		ParamName:    "MaxInactiveRounds"})
 
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: uint32(0),
+		ConfigPath:   "DPoSConfiguration.VoteDecayInactiveRounds",
+		ParamName:    "VoteDecayInactiveRounds"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: float64(0),
+		ConfigPath:   "DPoSConfiguration.VoteDecayRatio",
+		ParamName:    "VoteDecayRatio"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: uint32(0),
+		ConfigPath:   "DPoSConfiguration.NodeKeyRotationCooldown",
+		ParamName:    "NodeKeyRotationCooldown"})
+
 	result.Add(&settingItem{
 		Flag:         nil,
 		DefaultValue: common.Fixed64(0),
@@ -665,12 +976,36 @@ func newSettings() *settings {
 		ConfigPath:   "CRConfiguration.VotingPeriod",
 		ParamName:    "CRVotingPeriod"})
 
+	result.Add(&settingItem{
+		Flag:         cmdcom.CRNominationPeriodFlag,
+		DefaultValue: uint32(0),
+		ConfigPath:   "CRConfiguration.NominationPeriod",
+		ParamName:    "CRNominationPeriod"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: 0,
+		ConfigPath:   "CRConfiguration.VotesCacheSize",
+		ParamName:    "CRVotesCacheSize"})
+
 	result.Add(&settingItem{
 		Flag:         cmdcom.RegisterCRByDIDHeightFlag,
 		DefaultValue: uint32(0),
 		ConfigPath:   "CRConfiguration.RegisterCRByDIDHeight",
 		ParamName:    "RegisterCRByDIDHeight"})
 
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: uint32(0),
+		ConfigPath:   "CRConfiguration.NicknameCommitHeight",
+		ParamName:    "CRNicknameCommitHeight"})
+
+	result.Add(&settingItem{
+		Flag:         nil,
+		DefaultValue: uint32(0),
+		ConfigPath:   "CRConfiguration.NicknameCommitExpiry",
+		ParamName:    "CRNicknameCommitExpiry"})
+
 	return result
 }
 
@@ -711,6 +1046,30 @@ func (s *settings) loadConfigFile(path string) (*config.Configuration, error) {
 	return &cfgFile.Configuration, nil
 }
 
+// loadChainParams overrides the parameters of the active network by the chain
+// parameters file, and prints the differences.
+func loadChainParams(path string, params *config.Params) (*config.Params,
+	error) {
+	file, err := config.LoadChainParamsFile(path)
+	if err != nil {
+		return nil, err
+	}
+	result, diffs, err := file.Apply(params)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.ValidatePrivateNet(result); err != nil {
+		return nil, fmt.Errorf("invalid chain params file %s, %s", path, err)
+	}
+
+	fmt.Printf("Chain params loaded from %s, %d changes against the "+
+		"active network:\n", path, len(diffs))
+	for _, d := range diffs {
+		fmt.Println("  " + d)
+	}
+	return result, nil
+}
+
 // checkHost check the host or IP address is valid and available.
 func checkHost(host string) error {
 	// Empty host check.
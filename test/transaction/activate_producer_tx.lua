@@ -15,8 +15,7 @@ print(addr)
 print(pubkey)
 
 -- deposit params
---local node_publickey =
---"032895050b7de1a9cf43416e6e5310f8e909249dcd9c4166159b04a343f7f141b5"
+--local node_publickey = fixtures.keypair("node").publickey
 
 local node_publickey = getNodePublicKey()
 if node_publickey == ""
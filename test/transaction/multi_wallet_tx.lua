@@ -0,0 +1,72 @@
+-- Copyright (c) 2017-2019 The Elastos Foundation
+-- Use of this source code is governed by an MIT
+-- license that can be found in the LICENSE file.
+-- 
+
+local m = require("api")
+
+-- client: wallet name under the wallets directory, password
+local alice = client.open("alice", "123")
+local bob = client.open("bob", "123")
+
+-- account
+local alice_addr = alice:get_address()
+local bob_addr = bob:get_address()
+print("alice:", alice_addr)
+print("bob:", bob_addr)
+
+-- asset_id
+local asset_id = m.get_asset_id()
+
+-- amount, fee
+local amount = getAmount()
+local fee = getFee()
+
+if amount == 0
+then
+	amount = 1.0
+end
+
+if fee == 0
+then
+	fee = 0.1
+end
+
+print("amount:", amount)
+print("fee:", fee)
+
+-- payload
+local ta = transferasset.new()
+
+-- transaction: version, txType, payloadVersion, payload, locktime
+local tx = transaction.new(9, 0x02, 0, ta, 0)
+
+-- input: from, amount + fee
+local charge = tx:appendenough(alice_addr, (amount + fee) * 100000000)
+print(charge)
+
+-- outputpayload
+local default_output = defaultoutput.new()
+
+-- output: asset_id, value, recipient, output_paload_type, output_paload
+local charge_output = output.new(asset_id, charge, alice_addr, 0, default_output)
+local recipient_output = output.new(asset_id, amount * 100000000, bob_addr, 0, default_output)
+tx:appendtxout(charge_output)
+tx:appendtxout(recipient_output)
+
+-- sign by alice
+tx:sign(alice)
+print(tx:get())
+
+-- send
+local hash = tx:hash()
+local res = m.send_tx(tx)
+
+print("sending " .. hash)
+
+if (res ~= hash)
+then
+	print(res)
+else
+	print("tx send success")
+end
@@ -21,8 +21,8 @@ local asset_id = m.get_asset_id()
 --local amount = 5000
 --local fee = 0.001
 -- deposit params
---local deposit_address = "DgauQGNDXYkn3xvVhHHDy4utmoQUjCdJgM"
---local cr_publickey = "039d419986f5c2bf6f2a6f59f0b6e111735b66570fb22107a038bca3e1005d1920"
+--local deposit_address = fixtures.keypair("cr").crdepositaddress
+--local cr_publickey = fixtures.keypair("cr").publickey
 --local nick_name = "ela_test"
 --local url = "ela_test.org"
 --local location = "112211"
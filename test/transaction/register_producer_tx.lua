@@ -22,9 +22,9 @@ local asset_id = m.get_asset_id()
 --local fee = 0.001
 
 -- deposit params
---local deposit_address = "DVgnDnVfPVuPa2y2E4JitaWjWgRGJDuyrD"
---local own_publickey = "034f3a7d2f33ac7f4e30876080d359ce5f314c9eabddbaaca637676377f655e16c"
---local node_publickey = "029628ed890a25295a91817669dd9e35b4d792d51503c7eb4190b01a26e1a48fba"
+--local deposit_address = fixtures.keypair("owner").depositaddress
+--local own_publickey = fixtures.keypair("owner").publickey
+--local node_publickey = fixtures.keypair("node").publickey
 --local nick_name = "ela_test"
 --local url = "ela_test.org"
 --local location = "112211"
@@ -93,12 +93,13 @@ print("url:", url)
 print("location:", location)
 
 
--- register producer payload: publickey, nickname, url, local, host, wallet
-local rp_payload = registerproducer.new(own_publickey, node_publickey, nick_name, url, location, host_address, wallet)
+-- register producer payload: publickey, nickname, url, local, host, wallet, payloadVersion
+local payload_version = payloadversions.producerinfo
+local rp_payload = registerproducer.new(own_publickey, node_publickey, nick_name, url, location, host_address, wallet, payload_version)
 print(rp_payload:get())
 
 -- transaction: version, txType, payloadVersion, payload, locktime
-local tx = transaction.new(9, 0x09, 0, rp_payload, 0)
+local tx = transaction.new(9, 0x09, payload_version, rp_payload, 0)
 print(tx:get())
 
 -- input: from, amount + fee
@@ -21,8 +21,8 @@ local assetID = m.get_asset_id()
 -- amount, fee
 --local amount = 0.199
 --local fee = 0.001
---local recipient = "EJMzC16Eorq9CuFCGtyMrq4Jmgw9jYCHQR"
---local deposit_addr = "DgauQGNDXYkn3xvVhHHDy4utmoQUjCdJgM"
+--local recipient = fixtures.keypair("cr").address
+--local deposit_addr = fixtures.keypair("cr").crdepositaddress
 
 local amount = getAmount()
 local fee = getFee()
@@ -21,8 +21,8 @@ local assetID = m.get_asset_id()
 -- amount, fee
 --local amount = 0.199
 --local fee = 0.001
---local recipient = "EJMzC16Eorq9CuFCGtyMrq4Jmgw9jYCHQR"
---local deposit_addr = "DVgnDnVfPVuPa2y2E4JitaWjWgRGJDuyrD"
+--local recipient = fixtures.keypair("owner").address
+--local deposit_addr = fixtures.keypair("owner").depositaddress
 
 local amount = getAmount()
 local fee = getFee()
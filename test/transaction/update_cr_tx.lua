@@ -19,8 +19,8 @@ local asset_id = m.get_asset_id()
 
 
 -- deposit params
---local deposit_address = "DW1jxCSjnrCrtkyvbkGcUp4aPvjacXBpAM"
---local cr_publickey = "036db5984e709d2e0ec62fd974283e9a18e7b87e8403cc784baf1f61f775926535"
+--local deposit_address = fixtures.keypair("cr").crdepositaddress
+--local cr_publickey = fixtures.keypair("cr").publickey
 --local nick_name = "ela_test11"
 --local url = "ela_test.org11"
 --local location = "00112211"
@@ -21,8 +21,8 @@ local asset_id = m.get_asset_id()
 local fee = 0.001
 
 -- deposit params
---local own_publickey = "034f3a7d2f33ac7f4e30876080d359ce5f314c9eabddbaaca637676377f655e16c"
---local node_publickey = "03c77af162438d4b7140f8544ad6523b9734cca9c7a62476d54ed5d1bddc7a39c3"
+--local own_publickey = fixtures.keypair("owner").publickey
+--local node_publickey = fixtures.keypair("node").publickey
 --local nick_name = "ela_test1"
 --local url = "ela_test1.org"
 --local location = "112212"
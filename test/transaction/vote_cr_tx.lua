@@ -22,7 +22,7 @@ local asset_id = m.get_asset_id()
 --local amount = 0.2
 --local fee = 0.001
 -- candidate need to be code
---local vote_candidates = {'21039d419986f5c2bf6f2a6f59f0b6e111735b66570fb22107a038bca3e1005d1920ac'}
+--local vote_candidates = {fixtures.keypair("cr").code}
 --local vote_candidate_votes = {'0.1'}
 
 local vote_type = 1
@@ -21,7 +21,7 @@ local asset_id = m.get_asset_id()
 -- amount, fee, recipent
 --local amount = 0.2
 --local fee = 0.001
---local vote_candidates = {'034f3a7d2f33ac7f4e30876080d359ce5f314c9eabddbaaca637676377f655e16c'}
+--local vote_candidates = {fixtures.keypair("owner").publickey}
 --local vote_candidate_votes = {'1.0'}
 
 local vote_type = 0
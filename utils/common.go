@@ -6,6 +6,7 @@
 package utils
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"net"
@@ -147,3 +148,31 @@ func DeserializeStringSet(r io.Reader) (vmap map[string]struct{}, err error) {
 	}
 	return
 }
+
+// keyFrameVersionMarker prefixes versioned key frames. Legacy key frames start
+// with the var uint count of their first map, which can never begin with this
+// byte, so the two encodings can be told apart by the first byte.
+const keyFrameVersionMarker = 0xff
+
+// WriteKeyFrameVersion writes the version header of a key frame.
+func WriteKeyFrameVersion(w io.Writer, version byte) error {
+	_, err := w.Write([]byte{keyFrameVersionMarker, version})
+	return err
+}
+
+// ReadKeyFrameVersion reads the version header of a key frame. Key frames
+// written before the header was introduced are reported as version 0, and the
+// returned reader must be used to read the remaining data of the key frame.
+func ReadKeyFrameVersion(r io.Reader) (byte, io.Reader, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, r, err
+	}
+	if b[0] != keyFrameVersionMarker {
+		return 0, io.MultiReader(bytes.NewReader(b[:]), r), nil
+	}
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, r, err
+	}
+	return b[0], r, nil
+}
@@ -5,7 +5,11 @@
 
 package utils
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
 
 // change holds a change and it's rollback function.
 type change struct {
@@ -115,6 +119,29 @@ func (h *History) Append(height uint32, execute func(), rollback func()) {
 	h.cachedChanges.append(execute, rollback)
 }
 
+// AppendAmountChange adds a change adding delta to the amount and it's
+// rollback into history.  The addition is checked, if the result is out of
+// range the amount is left unchanged and refused is called with the error,
+// the rollback of a refused change does nothing.
+func (h *History) AppendAmountChange(height uint32, amount *common.Fixed64,
+	delta common.Fixed64, refused func(err error)) {
+	var applied bool
+	h.Append(height, func() {
+		result, err := amount.CheckedAdd(delta)
+		if err != nil {
+			applied = false
+			refused(err)
+			return
+		}
+		*amount, applied = result, true
+	}, func() {
+		if applied {
+			*amount -= delta
+			applied = false
+		}
+	})
+}
+
 // Commit saves the pending changes into state.
 func (h *History) Commit(height uint32) {
 	// if there are temporary changes, just Commit them and return.
@@ -0,0 +1,119 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package utils
+
+import (
+	"sort"
+	"sync"
+)
+
+// HistoryRecord is a record of a key written into a HistoryIndex.
+type HistoryRecord struct {
+	Key   []byte
+	Value []byte
+}
+
+// HistoryIndex keeps records of keys by the height they happened, so audit
+// logs growing with the chain can be kept out of the memory state, and
+// survive restarting from a checkpoint.
+type HistoryIndex interface {
+	// PutRecords writes the records happened on the height, records written
+	// on the same height before are replaced, so processing a block again
+	// writes the same records.
+	PutRecords(height uint32, records []HistoryRecord) error
+
+	// GetRecords returns values of the records of the key, ordered by the
+	// height they happened.
+	GetRecords(key []byte) ([][]byte, error)
+
+	// RollbackTo removes the records happened above the height.
+	RollbackTo(height uint32) error
+}
+
+// heightRecord is a record value with the height it happened.
+type heightRecord struct {
+	height uint32
+	value  []byte
+}
+
+// memHistoryIndex is a HistoryIndex kept in memory.
+type memHistoryIndex struct {
+	mtx     sync.RWMutex
+	records map[string][]heightRecord
+
+	// keys holds keys of the records by the height they happened.
+	keys map[uint32][]string
+}
+
+func (m *memHistoryIndex) PutRecords(height uint32,
+	records []HistoryRecord) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.removeRecords(height)
+	for _, r := range records {
+		key := string(r.Key)
+		list := append(m.records[key], heightRecord{height, r.Value})
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].height < list[j].height
+		})
+		m.records[key] = list
+		m.keys[height] = append(m.keys[height], key)
+	}
+	return nil
+}
+
+func (m *memHistoryIndex) GetRecords(key []byte) ([][]byte, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	list := m.records[string(key)]
+	values := make([][]byte, 0, len(list))
+	for _, r := range list {
+		values = append(values, r.value)
+	}
+	return values, nil
+}
+
+func (m *memHistoryIndex) RollbackTo(height uint32) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for h := range m.keys {
+		if h > height {
+			m.removeRecords(h)
+		}
+	}
+	return nil
+}
+
+// removeRecords removes the records happened on the height.
+func (m *memHistoryIndex) removeRecords(height uint32) {
+	for _, key := range m.keys[height] {
+		list := m.records[key]
+		kept := list[:0]
+		for _, r := range list {
+			if r.height != height {
+				kept = append(kept, r)
+			}
+		}
+		if len(kept) == 0 {
+			delete(m.records, key)
+		} else {
+			m.records[key] = kept
+		}
+	}
+	delete(m.keys, height)
+}
+
+// NewMemHistoryIndex creates a HistoryIndex kept in memory, which is lost
+// when the process exits.
+func NewMemHistoryIndex() HistoryIndex {
+	return &memHistoryIndex{
+		records: make(map[string][]heightRecord),
+		keys:    make(map[uint32][]string),
+	}
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package signal
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// NewReload listens for the SIGHUP signal and calls onReload each time the
+// signal is received.
+func NewReload(onReload func()) {
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGHUP)
+
+		for range signals {
+			onReload()
+		}
+	}()
+}
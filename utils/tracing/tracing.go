@@ -0,0 +1,214 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA/common"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// tracerName is the instrumentation name of the spans created by node.
+	tracerName = "github.com/elastos/Elastos.ELA"
+
+	// serviceName is the service name reported to the exporter.
+	serviceName = "ela"
+
+	// maxPendingBlocks is the maximum count of block traces not finished,
+	// blocks waiting for confirmation or parents may never be processed, the
+	// oldest trace is finished when exceeded.
+	maxPendingBlocks = 64
+)
+
+const (
+	// ExporterOTLP exports spans to an OTLP collector over HTTP.
+	ExporterOTLP = "otlp"
+
+	// ExporterStdout prints spans to the standard output.
+	ExporterStdout = "stdout"
+)
+
+// Config defines the parameters of tracing.
+type Config struct {
+	// Exporter is the exporter of spans, it's otlp or stdout.
+	Exporter string
+
+	// Endpoint is the host and port of the OTLP collector.
+	Endpoint string
+
+	// Insecure disables the TLS of the connection to the OTLP collector.
+	Insecure bool
+
+	// SampleRatio is the ratio of block traces to be sampled, in the range of
+	// (0, 1], zero means all sampled.
+	SampleRatio float64
+}
+
+// blockTrace is the root span of processing a block.
+type blockTrace struct {
+	ctx  context.Context
+	span trace.Span
+}
+
+var (
+	enabled bool
+	tracer  = otel.Tracer(tracerName)
+
+	mtx    sync.Mutex
+	blocks = make(map[common.Uint256]*blockTrace)
+	order  []common.Uint256
+)
+
+// Init sets up the global tracer provider with the exporter, the returned
+// function flushes the pending spans and shuts down the exporter.
+func Init(cfg *Config) (func(), error) {
+	ratio := cfg.SampleRatio
+	if ratio == 0 {
+		ratio = 1
+	}
+	if ratio < 0 || ratio > 1 {
+		return nil, fmt.Errorf("invalid sample ratio %v", ratio)
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(context.Background(), opts...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		err = fmt.Errorf("unknown tracing exporter %s", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(
+			sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	enabled = true
+
+	return func() {
+		provider.Shutdown(context.Background())
+	}, nil
+}
+
+// Start creates a span as the child of the span in the context.
+func Start(ctx context.Context, name string,
+	attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartBlock creates the root span of processing the block if not created
+// yet, and returns the context of the root span.  The root span is finished
+// by EndBlock.
+func StartBlock(hash common.Uint256,
+	attrs ...attribute.KeyValue) context.Context {
+	if !enabled {
+		return context.Background()
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if t, ok := blocks[hash]; ok {
+		return t.ctx
+	}
+
+	if len(order) >= maxPendingBlocks {
+		endBlock(order[0], errors.New("block trace evicted"))
+	}
+	attrs = append(attrs, attribute.String("block.hash", hash.String()))
+	ctx, span := Start(context.Background(), "block", attrs...)
+	blocks[hash] = &blockTrace{ctx: ctx, span: span}
+	order = append(order, hash)
+	return ctx
+}
+
+// BlockContext returns the context of the root span of processing the block,
+// spans created from the returned context will be roots if the block is not
+// traced.
+func BlockContext(hash common.Uint256) context.Context {
+	if !enabled {
+		return context.Background()
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if t, ok := blocks[hash]; ok {
+		return t.ctx
+	}
+	return context.Background()
+}
+
+// StartBlockSpan creates a span as the child of the root span of processing
+// the block.
+func StartBlockSpan(hash common.Uint256, name string,
+	attrs ...attribute.KeyValue) trace.Span {
+	_, span := Start(BlockContext(hash), name, attrs...)
+	return span
+}
+
+// EndBlock finishes the root span of processing the block, the error is
+// recorded to the span if not nil.
+func EndBlock(hash common.Uint256, err error) {
+	if !enabled {
+		return
+	}
+
+	mtx.Lock()
+	endBlock(hash, err)
+	mtx.Unlock()
+}
+
+// endBlock finishes the root span of processing the block, the caller must
+// hold the mutex.
+func endBlock(hash common.Uint256, err error) {
+	t, ok := blocks[hash]
+	if !ok {
+		return
+	}
+	delete(blocks, hash)
+	for i, h := range order {
+		if h.IsEqual(hash) {
+			order = append(order[:i], order[i+1:]...)
+			break
+		}
+	}
+	End(t.span, err)
+}
+
+// End finishes the span, the error is recorded to the span if not nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
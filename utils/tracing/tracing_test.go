@@ -0,0 +1,40 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockTraces(t *testing.T) {
+	enabled = true
+	defer func() { enabled = false }()
+
+	hash := common.Uint256{1}
+	ctx := StartBlock(hash)
+	assert.Equal(t, ctx, StartBlock(hash))
+	assert.Equal(t, ctx, BlockContext(hash))
+	assert.Equal(t, 1, len(order))
+
+	EndBlock(hash, nil)
+	assert.Equal(t, context.Background(), BlockContext(hash))
+	assert.Equal(t, 0, len(blocks))
+	assert.Equal(t, 0, len(order))
+
+	// the oldest block trace is evicted if exceeded
+	for i := 0; i <= maxPendingBlocks; i++ {
+		StartBlock(common.Uint256{byte(i)})
+	}
+	assert.Equal(t, maxPendingBlocks, len(blocks))
+	assert.Equal(t, maxPendingBlocks, len(order))
+	assert.Equal(t, context.Background(), BlockContext(common.Uint256{0}))
+	assert.Equal(t, common.Uint256{1}, order[0])
+}
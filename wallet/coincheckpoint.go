@@ -23,6 +23,7 @@ type CoinsCheckPoint struct {
 	height     uint32
 	coins      map[types.OutPoint]*Coin
 	ownedCoins OwnedCoins
+	ownedMemos OwnedMemos
 
 	sync.RWMutex
 }
@@ -54,7 +55,11 @@ func (ccp *CoinsCheckPoint) Serialize(w io.Writer) error {
 		}
 	}
 
-	return ccp.ownedCoins.Serialize(w)
+	if err := ccp.ownedCoins.Serialize(w); err != nil {
+		return err
+	}
+
+	return ccp.ownedMemos.Serialize(w)
 }
 
 func (ccp *CoinsCheckPoint) Deserialize(r io.Reader) error {
@@ -80,7 +85,16 @@ func (ccp *CoinsCheckPoint) Deserialize(r io.Reader) error {
 		ccp.coins[op] = coin
 	}
 
-	return ccp.ownedCoins.Deserialize(r)
+	if err := ccp.ownedCoins.Deserialize(r); err != nil {
+		return err
+	}
+
+	// checkpoints saved before memos indexed end here.
+	err = ccp.ownedMemos.Deserialize(r)
+	if err == io.EOF {
+		return nil
+	}
+	return err
 }
 
 func (ccp *CoinsCheckPoint) Key() string {
@@ -155,6 +169,39 @@ func (ccp *CoinsCheckPoint) OnBlockSaved(block *types.DposBlock) {
 				Height:    block.Height,
 			})
 		}
+
+		ccp.appendMemo(tx, block.Height)
+	}
+}
+
+// appendMemo records the standard memo of the transaction for each address
+// in the wallet paid by the transaction.
+func (ccp *CoinsCheckPoint) appendMemo(tx *types.Transaction, height uint32) {
+	memo, ok := tx.GetMemo()
+	if !ok {
+		return
+	}
+	if _, err := types.ParseMemo(memo); err != nil {
+		return
+	}
+
+	owners := make(map[string]struct{})
+	for _, output := range tx.Outputs {
+		addr, err := output.ProgramHash.ToAddress()
+		if err != nil {
+			continue
+		}
+		if _, ok := owners[addr]; ok {
+			continue
+		}
+		if _, exist := GetWalletAccount(addr); exist {
+			owners[addr] = struct{}{}
+			ccp.ownedMemos.append(addr, &MemoRecord{
+				TxID:   tx.Hash(),
+				Height: height,
+				Memo:   memo,
+			})
+		}
 	}
 }
 
@@ -166,6 +213,7 @@ func (ccp *CoinsCheckPoint) OnRollbackTo(height uint32) error {
 	if height >= bestHeight {
 		return nil
 	}
+	ccp.ownedMemos.rollbackTo(height)
 	for i := bestHeight; i == height; i-- {
 		hash, err := Chain.GetBlockHash(height)
 		if err != nil {
@@ -253,6 +301,16 @@ func (ccp *CoinsCheckPoint) ListCoins(owner string) map[types.OutPoint]*Coin {
 	return coins
 }
 
+// ListMemos returns the memo records of transactions paying to the owner from
+// the start height to the end height inclusively.
+func (ccp *CoinsCheckPoint) ListMemos(owner string, start,
+	end uint32) []*MemoRecord {
+	ccp.RLock()
+	defer ccp.RUnlock()
+
+	return ccp.ownedMemos.list(owner, start, end)
+}
+
 func (ccp *CoinsCheckPoint) AppendCoin(owner string, op *types.OutPoint, coin *Coin) {
 	ccp.Lock()
 	defer ccp.Unlock()
@@ -282,5 +340,6 @@ func NewCoinCheckPoint() *CoinsCheckPoint {
 		height:     0,
 		coins:      make(map[types.OutPoint]*Coin, 0),
 		ownedCoins: NewOwnedCoins(),
+		ownedMemos: NewOwnedMemos(),
 	}
 }
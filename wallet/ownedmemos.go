@@ -0,0 +1,132 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package wallet
+
+import (
+	"io"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+// maxMemoSize is the max size of a memo read from checkpoint.
+const maxMemoSize = 1024
+
+// MemoRecord is the standard memo of a transaction paying to an address in
+// the wallet.
+type MemoRecord struct {
+	TxID   common.Uint256
+	Height uint32
+	Memo   []byte
+}
+
+func (mr *MemoRecord) Serialize(w io.Writer) error {
+	if err := mr.TxID.Serialize(w); err != nil {
+		return err
+	}
+	if err := common.WriteUint32(w, mr.Height); err != nil {
+		return err
+	}
+	return common.WriteVarBytes(w, mr.Memo)
+}
+
+func (mr *MemoRecord) Deserialize(r io.Reader) error {
+	if err := mr.TxID.Deserialize(r); err != nil {
+		return err
+	}
+	height, err := common.ReadUint32(r)
+	if err != nil {
+		return err
+	}
+	mr.Height = height
+	mr.Memo, err = common.ReadVarBytes(r, maxMemoSize, "memo")
+	return err
+}
+
+// OwnedMemos stores the memo records of owners in order of height, so that
+// deposits to a shared address can be credited by memos.
+type OwnedMemos map[string][]*MemoRecord
+
+func (om OwnedMemos) Serialize(w io.Writer) error {
+	if err := common.WriteUint32(w, uint32(len(om))); err != nil {
+		return err
+	}
+	for owner, records := range om {
+		if err := common.WriteVarString(w, owner); err != nil {
+			return err
+		}
+		if err := common.WriteUint32(w, uint32(len(records))); err != nil {
+			return err
+		}
+		for _, record := range records {
+			if err := record.Serialize(w); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (om OwnedMemos) Deserialize(r io.Reader) error {
+	count, err := common.ReadUint32(r)
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i < count; i++ {
+		owner, err := common.ReadVarString(r)
+		if err != nil {
+			return err
+		}
+		n, err := common.ReadUint32(r)
+		if err != nil {
+			return err
+		}
+		records := make([]*MemoRecord, 0, n)
+		for j := uint32(0); j < n; j++ {
+			record := new(MemoRecord)
+			if err := record.Deserialize(r); err != nil {
+				return err
+			}
+			records = append(records, record)
+		}
+		om[owner] = records
+	}
+	return nil
+}
+
+func (om OwnedMemos) append(owner string, record *MemoRecord) {
+	om[owner] = append(om[owner], record)
+}
+
+// rollbackTo removes the memo records higher than the height.
+func (om OwnedMemos) rollbackTo(height uint32) {
+	for owner, records := range om {
+		i := len(records)
+		for i > 0 && records[i-1].Height > height {
+			i--
+		}
+		if i == 0 {
+			delete(om, owner)
+		} else {
+			om[owner] = records[:i]
+		}
+	}
+}
+
+// list returns the memo records of the owner from the start height to the
+// end height inclusively.
+func (om OwnedMemos) list(owner string, start, end uint32) []*MemoRecord {
+	var records []*MemoRecord
+	for _, record := range om[owner] {
+		if record.Height >= start && record.Height <= end {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+func NewOwnedMemos() OwnedMemos {
+	return make(OwnedMemos)
+}
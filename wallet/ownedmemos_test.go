@@ -0,0 +1,76 @@
+// Copyright (c) 2017-2019 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package wallet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOwnedMemos(t *testing.T) {
+	om := NewOwnedMemos()
+	for i := uint32(1); i <= 3; i++ {
+		om.append("owner1", &MemoRecord{
+			TxID:   common.Uint256{byte(i)},
+			Height: i * 10,
+			Memo:   []byte("type:id,msg:1"),
+		})
+	}
+	om.append("owner2", &MemoRecord{Height: 30, Memo: []byte("memo")})
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, om.Serialize(buf))
+	om2 := NewOwnedMemos()
+	assert.NoError(t, om2.Deserialize(buf))
+	assert.Equal(t, om, om2)
+
+	records := om.list("owner1", 15, 30)
+	assert.Equal(t, 2, len(records))
+	assert.Equal(t, uint32(20), records[0].Height)
+	assert.Equal(t, 0, len(om.list("owner3", 0, 100)))
+
+	om.rollbackTo(20)
+	assert.Equal(t, 2, len(om.list("owner1", 0, 100)))
+	_, ok := om["owner2"]
+	assert.False(t, ok)
+}
+
+func TestCoinsCheckPoint_AppendMemo(t *testing.T) {
+	addr := "EYGv9wNyEMtVHAkGJvdkFLb7FJneRWdbEu"
+	programHash, _ := common.Uint168FromAddress(addr)
+	SetWalletAccount(&AddressInfo{address: addr})
+
+	newTx := func(memo string) *types.Transaction {
+		return &types.Transaction{
+			Attributes: []*types.Attribute{{Usage: types.Memo,
+				Data: []byte(memo)}},
+			Outputs: []*types.Output{
+				{ProgramHash: *programHash},
+				{ProgramHash: *programHash},
+			},
+		}
+	}
+	c := NewCoinCheckPoint()
+	c.appendMemo(newTx("type:id,msg:10001"), 10)
+	c.appendMemo(newTx("type:unknown,msg:10001"), 11)
+	records := c.ListMemos(addr, 0, 100)
+	assert.Equal(t, 1, len(records))
+	assert.Equal(t, []byte("type:id,msg:10001"), records[0].Memo)
+
+	// checkpoints saved without memos can be loaded.
+	buf := new(bytes.Buffer)
+	assert.NoError(t, common.WriteUint32(buf, 10))
+	assert.NoError(t, common.WriteUint32(buf, 0))
+	assert.NoError(t, NewOwnedCoins().Serialize(buf))
+	c2 := NewCoinCheckPoint()
+	assert.NoError(t, c2.Deserialize(buf))
+	assert.Equal(t, uint32(10), c2.height)
+}